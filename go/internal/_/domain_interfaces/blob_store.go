@@ -43,6 +43,12 @@ type (
 		MakeBlobWriter(FormatHash) (BlobWriter, error)
 	}
 
+	// TypedBlobWriterFactory is implemented by blob stores that choose where
+	// to write a blob by the type of its object, without the `!`.
+	TypedBlobWriterFactory interface {
+		MakeTypedBlobWriter(FormatHash, string) (BlobWriter, error)
+	}
+
 	BlobAccess interface {
 		HasBlob(MarklId) bool
 		BlobReaderFactory
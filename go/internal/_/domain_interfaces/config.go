@@ -32,6 +32,8 @@ type (
 	CLIConfigProvider interface {
 		GetVerbose() bool
 		GetQuiet() bool
+		// a structured_log.Verbosity, from -v and -vv
+		GetLogVerbosity() int
 		GetTodo() bool
 		IsDryRun() bool
 	}
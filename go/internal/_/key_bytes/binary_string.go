@@ -21,6 +21,7 @@ func _() {
 	_ = x[Comment-107]
 	_ = x[Tai-84]
 	_ = x[Type-116]
+	_ = x[Provenance-118]
 	_ = x[SigParentMetadataParentObjectId-77]
 	_ = x[DigestMetadataParentObjectId-115]
 	_ = x[DigestMetadataWithoutTai-110]
@@ -31,7 +32,7 @@ func _() {
 	_ = x[CacheTags2-121]
 }
 
-const _Binary_name = "UnknownBlobDescriptionContentLengthTagGenreCacheTagImplicitObjectIdSigParentMetadataParentObjectIdRepoPubKeySigilTaiCacheDormantCommentDigestMetadataDigestMetadataWithoutTaiRepoSigDigestMetadataParentObjectIdTypeCacheTagsCacheTags2"
+const _Binary_name = "UnknownBlobDescriptionContentLengthTagGenreCacheTagImplicitObjectIdSigParentMetadataParentObjectIdRepoPubKeySigilTaiCacheDormantCommentDigestMetadataDigestMetadataWithoutTaiRepoSigDigestMetadataParentObjectIdTypeProvenanceCacheTagsCacheTags2"
 
 var _Binary_map = map[Binary]string{
 	0:   _Binary_name[0:7],
@@ -53,8 +54,9 @@ var _Binary_map = map[Binary]string{
 	113: _Binary_name[173:180],
 	115: _Binary_name[180:208],
 	116: _Binary_name[208:212],
-	120: _Binary_name[212:221],
-	121: _Binary_name[221:231],
+	118: _Binary_name[212:222],
+	120: _Binary_name[222:231],
+	121: _Binary_name[231:241],
 }
 
 func (i Binary) String() string {
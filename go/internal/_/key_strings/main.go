@@ -5,16 +5,17 @@ import (
 )
 
 var (
-	Blob, _        = catgut.MakeFromString("Blob")
-	Comment, _     = catgut.MakeFromString("Comment")
-	Description, _ = catgut.MakeFromString("Description")
-	Genre, _       = catgut.MakeFromString("Genre")
-	ObjectId, _    = catgut.MakeFromString("ObjectId")
-	Sigil, _       = catgut.MakeFromString("Sigil")
-	Tag, _         = catgut.MakeFromString("Tag")
-	Tai, _         = catgut.MakeFromString("Tai")
-	Type, _        = catgut.MakeFromString("Type")
-	TypeLock, _    = catgut.MakeFromString("TypeLock")
-	ZZRepoPub, _   = catgut.MakeFromString("RepoPub")
-	ZZSigMother, _ = catgut.MakeFromString("SigMother")
+	Blob        = catgut.Intern("Blob")
+	Comment     = catgut.Intern("Comment")
+	Description = catgut.Intern("Description")
+	Genre       = catgut.Intern("Genre")
+	ObjectId    = catgut.Intern("ObjectId")
+	Provenance  = catgut.Intern("Provenance")
+	Sigil       = catgut.Intern("Sigil")
+	Tag         = catgut.Intern("Tag")
+	Tai         = catgut.Intern("Tai")
+	Type        = catgut.Intern("Type")
+	TypeLock    = catgut.Intern("TypeLock")
+	ZZRepoPub   = catgut.Intern("RepoPub")
+	ZZSigMother = catgut.Intern("SigMother")
 )
@@ -0,0 +1,371 @@
+package fs_watcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// Options configures a Watcher. Zero durations fall back to the defaults.
+type Options struct {
+	Root string
+
+	// PollInterval is how often the tree is scanned for changes.
+	PollInterval time.Duration
+
+	// Debounce is how long the tree has to stay unchanged before pending
+	// changes are emitted, so that a burst of saves becomes one batch.
+	Debounce time.Duration
+
+	// MaxBatchDelay bounds how long changes stay pending while edits keep
+	// arriving: a batch is emitted once its first change is this old.
+	MaxBatchDelay time.Duration
+
+	// Exclude reports whether a slash-separated path relative to Root is
+	// skipped. Excluded directories are not descended into.
+	Exclude func(rel string, isDir bool) bool
+}
+
+const (
+	DefaultPollInterval  = 500 * time.Millisecond
+	DefaultDebounce      = time.Second
+	DefaultMaxBatchDelay = 30 * time.Second
+)
+
+// Batch is one debounced set of changes, as paths relative to the root.
+type Batch struct {
+	Changed []string
+	Removed []string
+}
+
+func (batch Batch) IsEmpty() bool {
+	return len(batch.Changed) == 0 && len(batch.Removed) == 0
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+	mode    fs.FileMode
+}
+
+type fileState struct {
+	fileStat
+
+	// digest is the sha256 of the file's content, computed only once its
+	// stat changed, and empty until then
+	digest string
+}
+
+// Watcher detects changes to the files under a root by scanning it
+// periodically and comparing modification times, sizes, and modes. A file
+// whose stat changed but whose content did not, like one rewritten by the
+// checkin it triggered, is not reported. Hidden files and directories are
+// never watched. Polling keeps it dependency-free and portable at the cost of
+// latency bounded by PollInterval.
+type Watcher struct {
+	options Options
+
+	files map[string]fileState
+
+	changed      map[string]struct{}
+	removed      map[string]struct{}
+	firstPending time.Time
+	lastPending  time.Time
+}
+
+// Make scans the root once, so that only changes made afterwards are
+// reported.
+func Make(options Options) (watcher *Watcher, err error) {
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+
+	if options.Debounce <= 0 {
+		options.Debounce = DefaultDebounce
+	}
+
+	if options.MaxBatchDelay <= 0 {
+		options.MaxBatchDelay = DefaultMaxBatchDelay
+	}
+
+	watcher = &Watcher{
+		options: options,
+		changed: make(map[string]struct{}),
+		removed: make(map[string]struct{}),
+	}
+
+	var stats map[string]fileStat
+
+	if stats, err = watcher.scan(); err != nil {
+		err = errors.Wrap(err)
+		return watcher, err
+	}
+
+	watcher.files = make(map[string]fileState, len(stats))
+
+	for rel, stat := range stats {
+		watcher.files[rel] = fileState{fileStat: stat}
+	}
+
+	return watcher, err
+}
+
+// Watch polls until ctx is done, calling emit with every batch. An error
+// from emit stops the watch. Changes still pending when ctx is done are
+// dropped.
+func (watcher *Watcher) Watch(
+	ctx context.Context,
+	emit func(Batch) error,
+) (err error) {
+	ticker := time.NewTicker(watcher.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return err
+
+		case now := <-ticker.C:
+			var batch Batch
+			var ready bool
+
+			if batch, ready, err = watcher.Poll(now); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			if !ready {
+				continue
+			}
+
+			if err = emit(batch); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+		}
+	}
+}
+
+// Poll rescans the tree, records what changed since the last scan, and
+// returns the pending batch once it is ready: when nothing changed for
+// Debounce, or when its first change is older than MaxBatchDelay.
+func (watcher *Watcher) Poll(
+	now time.Time,
+) (batch Batch, ready bool, err error) {
+	var stats map[string]fileStat
+
+	if stats, err = watcher.scan(); err != nil {
+		err = errors.Wrap(err)
+		return batch, ready, err
+	}
+
+	files := make(map[string]fileState, len(stats))
+	changedAny := false
+
+	for rel, stat := range stats {
+		previous, existed := watcher.files[rel]
+
+		if existed && previous.fileStat == stat {
+			files[rel] = previous
+			continue
+		}
+
+		state := fileState{fileStat: stat}
+
+		if state.digest, err = watcher.digest(rel); err != nil {
+			if errors.IsNotExist(err) {
+				// removed since the scan, which the next poll reports
+				if existed {
+					files[rel] = previous
+				}
+
+				err = nil
+				continue
+			}
+
+			err = errors.Wrap(err)
+			return batch, ready, err
+		}
+
+		files[rel] = state
+
+		if existed && previous.digest == state.digest {
+			continue
+		}
+
+		watcher.changed[rel] = struct{}{}
+		delete(watcher.removed, rel)
+		changedAny = true
+	}
+
+	for rel := range watcher.files {
+		if _, ok := files[rel]; ok {
+			continue
+		}
+
+		if _, ok := stats[rel]; ok {
+			continue
+		}
+
+		watcher.removed[rel] = struct{}{}
+		delete(watcher.changed, rel)
+		changedAny = true
+	}
+
+	watcher.files = files
+
+	if changedAny {
+		if watcher.firstPending.IsZero() {
+			watcher.firstPending = now
+		}
+
+		watcher.lastPending = now
+	}
+
+	if watcher.firstPending.IsZero() {
+		return batch, ready, err
+	}
+
+	if now.Sub(watcher.lastPending) < watcher.options.Debounce &&
+		now.Sub(watcher.firstPending) < watcher.options.MaxBatchDelay {
+		return batch, ready, err
+	}
+
+	batch = watcher.takePending()
+	ready = !batch.IsEmpty()
+
+	return batch, ready, err
+}
+
+func (watcher *Watcher) takePending() (batch Batch) {
+	for rel := range watcher.changed {
+		batch.Changed = append(batch.Changed, rel)
+	}
+
+	for rel := range watcher.removed {
+		batch.Removed = append(batch.Removed, rel)
+	}
+
+	slices.Sort(batch.Changed)
+	slices.Sort(batch.Removed)
+
+	clear(watcher.changed)
+	clear(watcher.removed)
+	watcher.firstPending = time.Time{}
+	watcher.lastPending = time.Time{}
+
+	return batch
+}
+
+func (watcher *Watcher) digest(rel string) (digest string, err error) {
+	var file *os.File
+
+	if file, err = os.Open(
+		filepath.Join(watcher.options.Root, filepath.FromSlash(rel)),
+	); err != nil {
+		return digest, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	hash := sha256.New()
+
+	if _, err = io.Copy(hash, file); err != nil {
+		err = errors.Wrap(err)
+		return digest, err
+	}
+
+	digest = string(hash.Sum(nil))
+
+	return digest, err
+}
+
+func (watcher *Watcher) scan() (files map[string]fileStat, err error) {
+	files = make(map[string]fileStat)
+	root := watcher.options.Root
+
+	if err = filepath.WalkDir(
+		root,
+		func(path string, dirEntry fs.DirEntry, in error) (err error) {
+			if in != nil {
+				// files can disappear between listing a directory and
+				// reading them
+				if errors.IsNotExist(in) {
+					return err
+				}
+
+				err = errors.Wrap(in)
+				return err
+			}
+
+			if path == root {
+				return err
+			}
+
+			isDir := dirEntry.IsDir()
+
+			if strings.HasPrefix(dirEntry.Name(), ".") {
+				if isDir {
+					err = filepath.SkipDir
+				}
+
+				return err
+			}
+
+			var rel string
+
+			if rel, err = filepath.Rel(root, path); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			rel = filepath.ToSlash(rel)
+
+			if watcher.options.Exclude != nil &&
+				watcher.options.Exclude(rel, isDir) {
+				if isDir {
+					err = filepath.SkipDir
+				}
+
+				return err
+			}
+
+			if isDir {
+				return err
+			}
+
+			var info fs.FileInfo
+
+			if info, err = dirEntry.Info(); err != nil {
+				if errors.IsNotExist(err) {
+					err = nil
+				} else {
+					err = errors.Wrap(err)
+				}
+
+				return err
+			}
+
+			files[rel] = fileStat{
+				modTime: info.ModTime(),
+				size:    info.Size(),
+				mode:    info.Mode(),
+			}
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return files, err
+	}
+
+	return files, err
+}
@@ -0,0 +1,137 @@
+//go:build test && debug
+
+package fs_watcher
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, root, rel, contents string) {
+	t.Helper()
+
+	path := filepath.Join(root, rel)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherDebouncesAndBatches(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "one/uno.zettel", "uno")
+	writeFile(t, root, "gone.md", "gone")
+
+	watcher, err := Make(Options{
+		Root:          root,
+		Debounce:      time.Second,
+		MaxBatchDelay: time.Minute,
+		Exclude: func(rel string, isDir bool) bool {
+			return filepath.Ext(rel) == ".log"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+
+	if _, ready, err := watcher.Poll(start); err != nil || ready {
+		t.Fatalf("expected no batch before any change, got ready %t, err %v", ready, err)
+	}
+
+	writeFile(t, root, "one/uno.zettel", "uno changed")
+	writeFile(t, root, "one/dos.md", "dos")
+	writeFile(t, root, "skip.log", "excluded")
+	writeFile(t, root, ".hidden", "hidden")
+
+	if err := os.Remove(filepath.Join(root, "gone.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ready, err := watcher.Poll(start); err != nil || ready {
+		t.Fatalf("expected the batch to wait for the debounce, got ready %t, err %v", ready, err)
+	}
+
+	batch, ready, err := watcher.Poll(start.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ready {
+		t.Fatal("expected a batch after the debounce")
+	}
+
+	if expected := []string{"one/dos.md", "one/uno.zettel"}; !slices.Equal(batch.Changed, expected) {
+		t.Errorf("expected changed %q, got %q", expected, batch.Changed)
+	}
+
+	if expected := []string{"gone.md"}; !slices.Equal(batch.Removed, expected) {
+		t.Errorf("expected removed %q, got %q", expected, batch.Removed)
+	}
+
+	if _, ready, err := watcher.Poll(start.Add(2 * time.Second)); err != nil || ready {
+		t.Fatalf("expected an emitted batch not to repeat, got ready %t, err %v", ready, err)
+	}
+
+	// rewriting a file with the same content, as a checkin refreshing its
+	// checkout does, is not a change
+	rewritten := filepath.Join(root, "one/uno.zettel")
+	writeFile(t, root, "one/uno.zettel", "uno changed")
+
+	later := time.Now().Add(time.Hour)
+
+	if err := os.Chtimes(rewritten, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ready, err := watcher.Poll(start.Add(4 * time.Second)); err != nil || ready {
+		t.Fatalf("expected an identical rewrite not to be reported, got ready %t, err %v", ready, err)
+	}
+}
+
+func TestWatcherMaxBatchDelay(t *testing.T) {
+	root := t.TempDir()
+
+	watcher, err := Make(Options{
+		Root:          root,
+		Debounce:      time.Second,
+		MaxBatchDelay: 3 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+
+	// a change every half second never lets the debounce expire, and each
+	// grows the file so that it is detected whatever the mtime resolution
+	for i := range 6 {
+		writeFile(t, root, "busy.md", strings.Repeat("a", i+1))
+
+		now := start.Add(time.Duration(i) * 500 * time.Millisecond)
+
+		if _, ready, err := watcher.Poll(now); err != nil || ready {
+			t.Fatalf("poll %d: expected no batch yet, got ready %t, err %v", i, ready, err)
+		}
+	}
+
+	writeFile(t, root, "busy.md", "final")
+
+	batch, ready, err := watcher.Poll(start.Add(3 * time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ready || !slices.Equal(batch.Changed, []string{"busy.md"}) {
+		t.Fatalf("expected busy.md after the max batch delay, got ready %t, batch %v", ready, batch)
+	}
+}
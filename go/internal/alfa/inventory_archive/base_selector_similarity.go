@@ -0,0 +1,130 @@
+package inventory_archive
+
+import (
+	"math"
+)
+
+// SimilaritySelector assigns each blob the most similar other blob as its
+// delta base, judged by the MinHash sketches in BlobMetadata.Signature.
+// Unlike LSHBandingSelector it compares every pair of blobs that share at
+// least one sketch position, through an inverted index of (position, value)
+// pairs, so no similar pair is missed for falling outside a band. Pairs
+// estimated below MinSimilarity are not worth a delta attempt and are left
+// as full entries.
+type SimilaritySelector struct {
+	MinBlobSize   uint64
+	MaxBlobSize   uint64
+	MinSimilarity float64
+}
+
+var _ BaseSelector = &SimilaritySelector{}
+
+func (s *SimilaritySelector) SelectBases(
+	blobs BlobSet,
+	assignments DeltaAssignments,
+) {
+	n := blobs.Len()
+	if n < 2 {
+		return
+	}
+
+	type eligible struct {
+		originalIndex int
+		signature     []uint32
+		size          uint64
+	}
+
+	var pool []eligible
+	sigLen := -1
+
+	for i := range n {
+		meta := blobs.At(i)
+
+		if meta.Size < s.MinBlobSize || meta.Size > s.MaxBlobSize {
+			continue
+		}
+
+		if len(meta.Signature) == 0 {
+			continue
+		}
+
+		// Sketches of different lengths are not comparable; the first
+		// sketch decides the length.
+		if sigLen == -1 {
+			sigLen = len(meta.Signature)
+		} else if len(meta.Signature) != sigLen {
+			continue
+		}
+
+		pool = append(pool, eligible{
+			originalIndex: i,
+			signature:     meta.Signature,
+			size:          meta.Size,
+		})
+	}
+
+	if len(pool) < 2 {
+		return
+	}
+
+	type postingKey struct {
+		position int
+		value    uint32
+	}
+
+	postings := make(map[postingKey][]int)
+
+	for poolIdx, e := range pool {
+		for position, value := range e.signature {
+			if value == math.MaxUint32 {
+				continue
+			}
+
+			key := postingKey{position: position, value: value}
+			postings[key] = append(postings[key], poolIdx)
+		}
+	}
+
+	for poolIdx, e := range pool {
+		candidates := make(map[int]bool)
+
+		for position, value := range e.signature {
+			if value == math.MaxUint32 {
+				continue
+			}
+
+			key := postingKey{position: position, value: value}
+
+			for _, otherIdx := range postings[key] {
+				if otherIdx != poolIdx {
+					candidates[otherIdx] = true
+				}
+			}
+		}
+
+		bestIdx := -1
+		bestSim := 0.0
+
+		for candIdx := range candidates {
+			sim := MinHashJaccard(e.signature, pool[candIdx].signature)
+
+			if sim > bestSim || (sim == bestSim && candIdx < bestIdx) {
+				bestSim = sim
+				bestIdx = candIdx
+			}
+		}
+
+		if bestIdx < 0 || bestSim < s.MinSimilarity {
+			continue
+		}
+
+		// As in LSHBandingSelector, only the smaller blob (or the lower pool
+		// index for equal sizes) is assigned, preventing mutual-delta cycles.
+		if e.size > pool[bestIdx].size ||
+			(e.size == pool[bestIdx].size && poolIdx > bestIdx) {
+			continue
+		}
+
+		assignments.Assign(e.originalIndex, pool[bestIdx].originalIndex)
+	}
+}
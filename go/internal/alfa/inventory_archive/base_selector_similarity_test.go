@@ -0,0 +1,88 @@
+//go:build test && debug
+
+package inventory_archive
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSimilaritySelectorPairsSimilarBlobsAcrossSizes(t *testing.T) {
+	computer := &ShingleMinHashComputer{ShingleSize: 8, K: 64}
+
+	original := make([]byte, 2048)
+	for i := range original {
+		original[i] = byte(i*i + i/7)
+	}
+
+	// Twice the size of original, so size grouping would not pair them.
+	extended := append(bytes.Clone(original), original...)
+
+	unrelated := make([]byte, 2048)
+	for i := range unrelated {
+		unrelated[i] = byte(i*31 + i/3 + 128)
+	}
+
+	var sigs [][]uint32
+
+	for _, data := range [][]byte{original, extended, unrelated} {
+		sig, err := computer.ComputeSignature(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sigs = append(sigs, sig)
+	}
+
+	blobs := &testBlobSet{
+		blobs: []BlobMetadata{
+			{Size: uint64(len(original)), Signature: sigs[0]},
+			{Size: uint64(len(extended)), Signature: sigs[1]},
+			{Size: uint64(len(unrelated)), Signature: sigs[2]},
+		},
+	}
+
+	selector := &SimilaritySelector{
+		MinBlobSize:   100,
+		MaxBlobSize:   10000,
+		MinSimilarity: 0.3,
+	}
+
+	assignments := newTestAssignments()
+	selector.SelectBases(blobs, assignments)
+
+	if base, ok := assignments.assignments[0]; !ok || base != 1 {
+		t.Errorf("expected blob 0 to use blob 1 as its base, got %v", assignments.assignments)
+	}
+
+	if _, ok := assignments.assignments[1]; ok {
+		t.Error("the larger blob should not be a delta of the smaller one")
+	}
+
+	if _, ok := assignments.assignments[2]; ok {
+		t.Error("expected the unrelated blob to stay a full entry")
+	}
+}
+
+func TestSimilaritySelectorBreaksEqualSizeCycles(t *testing.T) {
+	sig := make([]uint32, 64)
+	for i := range sig {
+		sig[i] = uint32(i)
+	}
+
+	blobs := &testBlobSet{
+		blobs: []BlobMetadata{
+			{Size: 1024, Signature: sig},
+			{Size: 1024, Signature: sig},
+		},
+	}
+
+	selector := &SimilaritySelector{MinBlobSize: 1, MaxBlobSize: 10000}
+
+	assignments := newTestAssignments()
+	selector.SelectBases(blobs, assignments)
+
+	if len(assignments.assignments) != 1 || assignments.assignments[0] != 1 {
+		t.Errorf("expected only blob 0 assigned to blob 1, got %v", assignments.assignments)
+	}
+}
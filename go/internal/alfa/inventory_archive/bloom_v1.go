@@ -0,0 +1,216 @@
+package inventory_archive
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const (
+	BloomFileMagicV1               = "MIAB"
+	BloomFileVersionV1      uint16 = 1
+	bloomFileHeaderSizeV1          = 4 + 2 + 1 + 8 // magic + version + hash_count + word_count
+	bloomBitsPerEntryV1            = 10
+	bloomHashCountV1        uint8  = 7
+	bloomMinHashSizeV1             = 16
+	bloomMaxHashCountV1     uint8  = 32
+	bloomFileChecksumSizeV1        = sha256.Size
+)
+
+// BloomFilterV1 answers whether a hash may be in an archive, with no false
+// negatives and about 1% false positives at 10 bits per entry. Archive hashes
+// are digests, so their bytes are used as the filter's hashes directly.
+type BloomFilterV1 struct {
+	hashCount uint8
+	words     []uint64
+}
+
+// MakeBloomFilterV1 returns an empty filter sized for entryCount hashes.
+func MakeBloomFilterV1(entryCount int) *BloomFilterV1 {
+	bitCount := max(entryCount*bloomBitsPerEntryV1, 64)
+
+	return &BloomFilterV1{
+		hashCount: bloomHashCountV1,
+		words:     make([]uint64, (bitCount+63)/64),
+	}
+}
+
+// bloomHashesV1 splits the tail of a digest into the two hashes that the
+// filter's bit positions are derived from. ok is false for hashes too short
+// to split, which every filter may contain.
+func bloomHashesV1(hash []byte) (first, second uint64, ok bool) {
+	if len(hash) < bloomMinHashSizeV1 {
+		return 0, 0, false
+	}
+
+	tail := hash[len(hash)-bloomMinHashSizeV1:]
+	first = binary.BigEndian.Uint64(tail[:8])
+	second = binary.BigEndian.Uint64(tail[8:]) | 1
+
+	return first, second, true
+}
+
+func (filter *BloomFilterV1) Add(hash []byte) {
+	first, second, ok := bloomHashesV1(hash)
+	if !ok {
+		return
+	}
+
+	bitCount := uint64(len(filter.words)) * 64
+
+	for i := range uint64(filter.hashCount) {
+		bit := (first + i*second) % bitCount
+		filter.words[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// MayContain is false only when hash was never added. It does not allocate.
+func (filter *BloomFilterV1) MayContain(hash []byte) bool {
+	first, second, ok := bloomHashesV1(hash)
+	if !ok {
+		return true
+	}
+
+	bitCount := uint64(len(filter.words)) * 64
+
+	for i := range uint64(filter.hashCount) {
+		bit := (first + i*second) % bitCount
+
+		if filter.words[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MakeBloomFilterV1ForIndex returns a filter holding the hashes of entries.
+func MakeBloomFilterV1ForIndex(entries []IndexEntryV1) *BloomFilterV1 {
+	filter := MakeBloomFilterV1(len(entries))
+
+	for _, entry := range entries {
+		filter.Add(entry.Hash)
+	}
+
+	return filter
+}
+
+func WriteBloomFilterV1(
+	w io.Writer,
+	filter *BloomFilterV1,
+) (err error) {
+	buf := make([]byte, 0, bloomFileHeaderSizeV1+len(filter.words)*8)
+
+	buf = append(buf, BloomFileMagicV1...)
+	buf = binary.BigEndian.AppendUint16(buf, BloomFileVersionV1)
+	buf = append(buf, filter.hashCount)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(filter.words)))
+
+	for _, word := range filter.words {
+		buf = binary.BigEndian.AppendUint64(buf, word)
+	}
+
+	checksum := sha256.Sum256(buf)
+
+	if _, err = w.Write(append(buf, checksum[:]...)); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return nil
+}
+
+// ReadBloomFilterV1 reads a filter written by WriteBloomFilterV1 from the
+// totalSize bytes of r, failing on a checksum mismatch rather than returning
+// a filter that could report false negatives.
+func ReadBloomFilterV1(
+	r io.Reader,
+	totalSize int64,
+) (filter *BloomFilterV1, err error) {
+	if totalSize < bloomFileHeaderSizeV1+bloomFileChecksumSizeV1 {
+		err = errors.Errorf("bloom filter file too small: %d bytes", totalSize)
+		return nil, err
+	}
+
+	header := make([]byte, bloomFileHeaderSizeV1)
+
+	if _, err = io.ReadFull(r, header); err != nil {
+		err = wrapTruncated(err, "reading bloom filter header")
+		return nil, err
+	}
+
+	if magic := string(header[:4]); magic != BloomFileMagicV1 {
+		err = errors.Errorf("invalid bloom filter magic: %q", magic)
+		return nil, err
+	}
+
+	if version := binary.BigEndian.Uint16(header[4:6]); version != BloomFileVersionV1 {
+		err = errors.Errorf("unsupported bloom filter version: %d", version)
+		return nil, err
+	}
+
+	filter = &BloomFilterV1{hashCount: header[6]}
+
+	if filter.hashCount == 0 || filter.hashCount > bloomMaxHashCountV1 {
+		err = errors.Wrap(ErrCorrupt{
+			File:  "bloom filter",
+			Field: "hash count",
+			Value: uint64(filter.hashCount),
+			Limit: uint64(bloomMaxHashCountV1),
+		})
+
+		return nil, err
+	}
+
+	wordCount := binary.BigEndian.Uint64(header[7:])
+
+	if err = checkEntryCount(
+		"bloom filter",
+		wordCount,
+		8,
+		bloomFileHeaderSizeV1,
+		bloomFileChecksumSizeV1,
+		totalSize,
+	); err != nil {
+		return nil, err
+	}
+
+	if wordCount == 0 {
+		err = errors.Errorf("bloom filter has no bits")
+		return nil, err
+	}
+
+	body := make([]byte, wordCount*8+bloomFileChecksumSizeV1)
+
+	if _, err = io.ReadFull(r, body); err != nil {
+		err = wrapTruncated(err, "reading bloom filter bits")
+		return nil, err
+	}
+
+	bits, storedChecksum := body[:wordCount*8], body[wordCount*8:]
+
+	hasher := sha256.New()
+	hasher.Write(header)
+	hasher.Write(bits)
+
+	if computed := hasher.Sum(nil); !bytes.Equal(computed, storedChecksum) {
+		err = errors.Errorf(
+			"bloom filter checksum mismatch: stored %x, computed %x",
+			storedChecksum,
+			computed,
+		)
+
+		return nil, err
+	}
+
+	filter.words = make([]uint64, wordCount)
+
+	for i := range filter.words {
+		filter.words[i] = binary.BigEndian.Uint64(bits[i*8:])
+	}
+
+	return filter, nil
+}
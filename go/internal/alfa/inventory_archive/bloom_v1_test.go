@@ -0,0 +1,98 @@
+//go:build test && debug
+
+package inventory_archive
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func TestBloomFilterV1NoFalseNegatives(t *testing.T) {
+	entries := makeTestIndexV1Entries(5000)
+	filter := MakeBloomFilterV1ForIndex(entries)
+
+	for i, entry := range entries {
+		if !filter.MayContain(entry.Hash) {
+			t.Fatalf("entry %d: false negative", i)
+		}
+	}
+
+	var falsePositives int
+
+	for i := range 10000 {
+		missing := sha256.Sum256([]byte(fmt.Sprintf("missing-%d", i)))
+
+		if filter.MayContain(missing[:]) {
+			falsePositives++
+		}
+	}
+
+	// about 1% are expected
+	if falsePositives > 300 {
+		t.Errorf("expected about 100 false positives, got %d", falsePositives)
+	}
+
+	if !filter.MayContain([]byte{0x01}) {
+		t.Error("expected hashes too short to split to be reported as present")
+	}
+}
+
+func TestBloomFilterV1RoundTrip(t *testing.T) {
+	entries := makeTestIndexV1Entries(100)
+	filter := MakeBloomFilterV1ForIndex(entries)
+
+	var buf bytes.Buffer
+
+	if err := WriteBloomFilterV1(&buf, filter); err != nil {
+		t.Fatalf("WriteBloomFilterV1: %v", err)
+	}
+
+	data := buf.Bytes()
+
+	read, err := ReadBloomFilterV1(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ReadBloomFilterV1: %v", err)
+	}
+
+	if read.hashCount != filter.hashCount ||
+		len(read.words) != len(filter.words) {
+		t.Fatalf("expected the filter's shape to round trip")
+	}
+
+	for i := range filter.words {
+		if read.words[i] != filter.words[i] {
+			t.Fatalf("word %d: %x != %x", i, read.words[i], filter.words[i])
+		}
+	}
+}
+
+func TestBloomFilterV1RejectsCorruption(t *testing.T) {
+	filter := MakeBloomFilterV1ForIndex(makeTestIndexV1Entries(100))
+
+	var buf bytes.Buffer
+
+	if err := WriteBloomFilterV1(&buf, filter); err != nil {
+		t.Fatalf("WriteBloomFilterV1: %v", err)
+	}
+
+	flipped := bytes.Clone(buf.Bytes())
+	flipped[bloomFileHeaderSizeV1] ^= 0xff
+
+	if _, err := ReadBloomFilterV1(
+		bytes.NewReader(flipped),
+		int64(len(flipped)),
+	); err == nil {
+		t.Error("expected a flipped bit to fail the checksum")
+	}
+
+	truncated := buf.Bytes()[:buf.Len()-1]
+
+	if _, err := ReadBloomFilterV1(
+		bytes.NewReader(truncated),
+		int64(len(truncated)),
+	); err == nil {
+		t.Error("expected a truncated filter to fail")
+	}
+}
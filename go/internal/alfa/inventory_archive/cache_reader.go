@@ -49,7 +49,7 @@ func (cr *CacheReader) readHeader() (err error) {
 	magic := make([]byte, 4)
 
 	if _, err = cr.reader.ReadAt(magic, 0); err != nil {
-		err = errors.Wrapf(err, "reading magic")
+		err = wrapTruncated(err, "reading magic")
 		return err
 	}
 
@@ -66,7 +66,7 @@ func (cr *CacheReader) readHeader() (err error) {
 	versionBuf := make([]byte, 2)
 
 	if _, err = cr.reader.ReadAt(versionBuf, 4); err != nil {
-		err = errors.Wrapf(err, "reading version")
+		err = wrapTruncated(err, "reading version")
 		return err
 	}
 
@@ -85,7 +85,7 @@ func (cr *CacheReader) readHeader() (err error) {
 	hashFormatIdLenBuf := make([]byte, 1)
 
 	if _, err = cr.reader.ReadAt(hashFormatIdLenBuf, 6); err != nil {
-		err = errors.Wrapf(err, "reading hash format id length")
+		err = wrapTruncated(err, "reading hash format id length")
 		return err
 	}
 
@@ -95,7 +95,7 @@ func (cr *CacheReader) readHeader() (err error) {
 	hashFormatIdBytes := make([]byte, hashFormatIdLen)
 
 	if _, err = cr.reader.ReadAt(hashFormatIdBytes, 7); err != nil {
-		err = errors.Wrapf(err, "reading hash format id")
+		err = wrapTruncated(err, "reading hash format id")
 		return err
 	}
 
@@ -118,7 +118,7 @@ func (cr *CacheReader) readHeader() (err error) {
 		entryCountBuf,
 		entryCountOffset,
 	); err != nil {
-		err = errors.Wrapf(err, "reading entry count")
+		err = wrapTruncated(err, "reading entry count")
 		return err
 	}
 
@@ -132,6 +132,18 @@ func (cr *CacheReader) readHeader() (err error) {
 			8, // entry_count
 	)
 
+	// entries are followed by the checksum
+	if err = checkEntryCount(
+		"cache",
+		cr.entryCount,
+		cr.entrySize(),
+		cr.entriesStart,
+		int64(cr.hashSize),
+		cr.totalSize,
+	); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -156,7 +168,7 @@ func (cr *CacheReader) readEntryAt(index uint64) (
 	entryBuf := make([]byte, cr.entrySize())
 
 	if _, err = cr.reader.ReadAt(entryBuf, offset); err != nil {
-		err = errors.Wrapf(err, "reading entry %d", index)
+		err = wrapTruncated(err, "reading entry %d", index)
 		return entry, err
 	}
 
@@ -188,7 +200,7 @@ func (cr *CacheReader) ReadAllEntries() (entries []CacheEntry, err error) {
 	for i := range cr.entryCount {
 		entries[i], err = cr.readEntryAt(i)
 		if err != nil {
-			err = errors.Wrapf(err, "reading entry %d", i)
+			err = wrapTruncated(err, "reading entry %d", i)
 			return nil, err
 		}
 	}
@@ -213,7 +225,7 @@ func (cr *CacheReader) Validate() (err error) {
 		storedChecksum,
 		checksumOffset,
 	); err != nil {
-		err = errors.Wrapf(err, "reading stored checksum")
+		err = wrapTruncated(err, "reading stored checksum")
 		return err
 	}
 
@@ -226,7 +238,7 @@ func (cr *CacheReader) Validate() (err error) {
 	contentBuf := make([]byte, checksumOffset)
 
 	if _, err = cr.reader.ReadAt(contentBuf, 0); err != nil {
-		err = errors.Wrapf(err, "reading content for hashing")
+		err = wrapTruncated(err, "reading content for hashing")
 		return err
 	}
 
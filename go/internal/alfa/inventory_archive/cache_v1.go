@@ -215,19 +215,10 @@ type CacheReaderV1 struct {
 func NewCacheReaderV1(
 	r io.ReaderAt,
 	totalSize int64,
-	hashFormatId string,
 ) (cr *CacheReaderV1, err error) {
-	hashSize, err := hashSizeForFormat(hashFormatId)
-	if err != nil {
-		err = errors.Wrap(err)
-		return nil, err
-	}
-
 	cr = &CacheReaderV1{
-		reader:       r,
-		totalSize:    totalSize,
-		hashFormatId: hashFormatId,
-		hashSize:     hashSize,
+		reader:    r,
+		totalSize: totalSize,
 	}
 
 	if err = cr.readHeader(); err != nil {
@@ -243,7 +234,7 @@ func (cr *CacheReaderV1) readHeader() (err error) {
 	magic := make([]byte, 4)
 
 	if _, err = cr.reader.ReadAt(magic, 0); err != nil {
-		err = errors.Wrapf(err, "reading magic")
+		err = wrapTruncated(err, "reading magic")
 		return err
 	}
 
@@ -260,7 +251,7 @@ func (cr *CacheReaderV1) readHeader() (err error) {
 	versionBuf := make([]byte, 2)
 
 	if _, err = cr.reader.ReadAt(versionBuf, 4); err != nil {
-		err = errors.Wrapf(err, "reading version")
+		err = wrapTruncated(err, "reading version")
 		return err
 	}
 
@@ -279,7 +270,7 @@ func (cr *CacheReaderV1) readHeader() (err error) {
 	hashFormatIdLenBuf := make([]byte, 1)
 
 	if _, err = cr.reader.ReadAt(hashFormatIdLenBuf, 6); err != nil {
-		err = errors.Wrapf(err, "reading hash format id length")
+		err = wrapTruncated(err, "reading hash format id length")
 		return err
 	}
 
@@ -289,18 +280,16 @@ func (cr *CacheReaderV1) readHeader() (err error) {
 	hashFormatIdBytes := make([]byte, hashFormatIdLen)
 
 	if _, err = cr.reader.ReadAt(hashFormatIdBytes, 7); err != nil {
-		err = errors.Wrapf(err, "reading hash format id")
+		err = wrapTruncated(err, "reading hash format id")
 		return err
 	}
 
-	fileHashFormatId := string(hashFormatIdBytes)
+	// the file names its own hash format, so archives written before a repo
+	// changed its default hash stay readable
+	cr.hashFormatId = string(hashFormatIdBytes)
 
-	if fileHashFormatId != cr.hashFormatId {
-		err = errors.Errorf(
-			"hash format id mismatch: file has %q, expected %q",
-			fileHashFormatId,
-			cr.hashFormatId,
-		)
+	if cr.hashSize, err = hashSizeForFormat(cr.hashFormatId); err != nil {
+		err = errors.Wrap(err)
 		return err
 	}
 
@@ -312,7 +301,7 @@ func (cr *CacheReaderV1) readHeader() (err error) {
 		entryCountBuf,
 		entryCountOffset,
 	); err != nil {
-		err = errors.Wrapf(err, "reading entry count")
+		err = wrapTruncated(err, "reading entry count")
 		return err
 	}
 
@@ -326,6 +315,18 @@ func (cr *CacheReaderV1) readHeader() (err error) {
 			8, // entry_count
 	)
 
+	// entries are followed by the checksum
+	if err = checkEntryCount(
+		"cache",
+		cr.entryCount,
+		cr.entrySize(),
+		cr.entriesStart,
+		int64(cr.hashSize),
+		cr.totalSize,
+	); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -350,7 +351,7 @@ func (cr *CacheReaderV1) readEntryAt(index uint64) (
 	entryBuf := make([]byte, cr.entrySize())
 
 	if _, err = cr.reader.ReadAt(entryBuf, offset); err != nil {
-		err = errors.Wrapf(err, "reading entry %d", index)
+		err = wrapTruncated(err, "reading entry %d", index)
 		return entry, err
 	}
 
@@ -390,7 +391,7 @@ func (cr *CacheReaderV1) ReadAllEntries() (entries []CacheEntryV1, err error) {
 	for i := range cr.entryCount {
 		entries[i], err = cr.readEntryAt(i)
 		if err != nil {
-			err = errors.Wrapf(err, "reading entry %d", i)
+			err = wrapTruncated(err, "reading entry %d", i)
 			return nil, err
 		}
 	}
@@ -415,7 +416,7 @@ func (cr *CacheReaderV1) Validate() (err error) {
 		storedChecksum,
 		checksumOffset,
 	); err != nil {
-		err = errors.Wrapf(err, "reading stored checksum")
+		err = wrapTruncated(err, "reading stored checksum")
 		return err
 	}
 
@@ -428,7 +429,7 @@ func (cr *CacheReaderV1) Validate() (err error) {
 	contentBuf := make([]byte, checksumOffset)
 
 	if _, err = cr.reader.ReadAt(contentBuf, 0); err != nil {
-		err = errors.Wrapf(err, "reading content for hashing")
+		err = wrapTruncated(err, "reading content for hashing")
 		return err
 	}
 
@@ -67,7 +67,6 @@ func TestCacheV1RoundTrip(t *testing.T) {
 	reader, err := NewCacheReaderV1(
 		bytes.NewReader(data),
 		int64(len(data)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewCacheReaderV1: %v", err)
@@ -163,7 +162,6 @@ func TestCacheV1ToMap(t *testing.T) {
 	reader, err := NewCacheReaderV1(
 		bytes.NewReader(data),
 		int64(len(data)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewCacheReaderV1: %v", err)
@@ -257,7 +255,6 @@ func TestCacheV1Empty(t *testing.T) {
 	reader, err := NewCacheReaderV1(
 		bytes.NewReader(data),
 		int64(len(data)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewCacheReaderV1: %v", err)
@@ -299,7 +296,6 @@ func TestCacheV1ValidateChecksum(t *testing.T) {
 	reader, err := NewCacheReaderV1(
 		bytes.NewReader(data),
 		int64(len(data)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewCacheReaderV1: %v", err)
@@ -318,7 +314,6 @@ func TestCacheV1ValidateChecksum(t *testing.T) {
 	reader, err = NewCacheReaderV1(
 		bytes.NewReader(corrupted),
 		int64(len(corrupted)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewCacheReaderV1 on corrupted data: %v", err)
@@ -344,3 +339,35 @@ func TestCacheV1WriterRejectsUnsortedEntries(t *testing.T) {
 		t.Fatal("WriteCacheV1 should reject unsorted entries")
 	}
 }
+
+func TestCacheV1ReaderUsesFileHashFormat(t *testing.T) {
+	var buf bytes.Buffer
+
+	if _, err := WriteCacheV1(
+		&buf,
+		"blake2b256",
+		makeTestCacheV1Entries(3),
+	); err != nil {
+		t.Fatalf("WriteCacheV1: %v", err)
+	}
+
+	data := buf.Bytes()
+
+	reader, err := NewCacheReaderV1(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewCacheReaderV1: %v", err)
+	}
+
+	if reader.HashFormatId() != "blake2b256" {
+		t.Errorf("expected blake2b256, got %q", reader.HashFormatId())
+	}
+
+	entries, err := reader.ReadAllEntries()
+	if err != nil {
+		t.Fatalf("ReadAllEntries: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Errorf("expected 3 entries, got %d", len(entries))
+	}
+}
@@ -0,0 +1,222 @@
+package inventory_archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"sort"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// A v2 data file ends with:
+//
+//	index         a v1 index (see WriteIndexV1) of the file's entries
+//	index_offset  8 bytes, offset of the index from the start of the file
+//	index_size    8 bytes
+//	entry_count   8 bytes
+//	checksum      hash_size bytes, over everything before it
+const trailerSizeV2 = 8 + 8 + 8
+
+// IndexEntriesForDataEntriesV1 builds the hash-sorted index of an archive
+// from the entries written to it, resolving each delta's base hash to the
+// offset of the base entry.
+func IndexEntriesForDataEntriesV1(entries []DataEntryV1) []IndexEntryV1 {
+	offsetsByHash := make(map[string]uint64, len(entries))
+
+	for _, entry := range entries {
+		offsetsByHash[hex.EncodeToString(entry.Hash)] = entry.Offset
+	}
+
+	indexEntries := make([]IndexEntryV1, len(entries))
+
+	for i, entry := range entries {
+		var baseOffset uint64
+
+		if entry.EntryType == EntryTypeDelta {
+			baseOffset = offsetsByHash[hex.EncodeToString(entry.BaseHash)]
+		}
+
+		indexEntries[i] = IndexEntryV1{
+			Hash:       entry.Hash,
+			PackOffset: entry.Offset,
+			StoredSize: entry.StoredSize,
+			EntryType:  entry.EntryType,
+			BaseOffset: baseOffset,
+		}
+	}
+
+	sort.Slice(indexEntries, func(i, j int) bool {
+		return bytes.Compare(indexEntries[i].Hash, indexEntries[j].Hash) < 0
+	})
+
+	return indexEntries
+}
+
+// writeFooterIndexV2 writes the index and the trailer fields that locate it;
+// Close follows them with the entry count and checksum.
+func (dw *DataWriterV1) writeFooterIndexV2() (err error) {
+	indexOffset := dw.offset
+
+	counter := &countingWriter{writer: dw.multiWriter}
+
+	if _, err = WriteIndexV1(
+		counter,
+		dw.hashFormatId,
+		IndexEntriesForDataEntriesV1(dw.entries),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	dw.offset += counter.count
+
+	// index_offset: 8 bytes
+	if err = binary.Write(
+		dw.multiWriter,
+		binary.BigEndian,
+		indexOffset,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// index_size: 8 bytes
+	if err = binary.Write(
+		dw.multiWriter,
+		binary.BigEndian,
+		counter.count,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	dw.offset += 8 + 8
+
+	return nil
+}
+
+func (dr *DataReaderV1) Version() uint16 {
+	return dr.version
+}
+
+// entriesEnd returns the offset at which the entries of the data file end.
+func (dr *DataReaderV1) entriesEnd() (end int64, err error) {
+	if dr.version == DataFileVersionV2 {
+		var indexOffset uint64
+
+		if indexOffset, _, err = dr.readTrailerV2(); err != nil {
+			return end, err
+		}
+
+		end = int64(indexOffset)
+
+		return end, err
+	}
+
+	totalSize, err := dr.reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		err = errors.Wrapf(err, "seeking to end")
+		return end, err
+	}
+
+	end = totalSize - int64(8+dr.hashSize)
+
+	return end, err
+}
+
+func (dr *DataReaderV1) readTrailerV2() (
+	indexOffset, indexSize uint64,
+	err error,
+) {
+	totalSize, err := dr.reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		err = errors.Wrapf(err, "seeking to end")
+		return indexOffset, indexSize, err
+	}
+
+	trailerOffset := totalSize - int64(trailerSizeV2+dr.hashSize)
+
+	if trailerOffset < dr.dataStart {
+		err = errors.Errorf("file too small for v2 trailer: %d bytes", totalSize)
+		return indexOffset, indexSize, err
+	}
+
+	if _, err = dr.reader.Seek(trailerOffset, io.SeekStart); err != nil {
+		err = errors.Wrapf(err, "seeking to v2 trailer")
+		return indexOffset, indexSize, err
+	}
+
+	if err = binary.Read(
+		dr.reader,
+		binary.BigEndian,
+		&indexOffset,
+	); err != nil {
+		err = wrapTruncated(err, "reading index offset")
+		return indexOffset, indexSize, err
+	}
+
+	if err = binary.Read(
+		dr.reader,
+		binary.BigEndian,
+		&indexSize,
+	); err != nil {
+		err = wrapTruncated(err, "reading index size")
+		return indexOffset, indexSize, err
+	}
+
+	if indexOffset < uint64(dr.dataStart) ||
+		indexSize > uint64(trailerOffset) ||
+		indexOffset+indexSize != uint64(trailerOffset) {
+		err = errors.Errorf(
+			"v2 trailer does not match file: index at %d (%d bytes), trailer at %d",
+			indexOffset,
+			indexSize,
+			trailerOffset,
+		)
+
+		return indexOffset, indexSize, err
+	}
+
+	return indexOffset, indexSize, err
+}
+
+// FooterIndex reads the index embedded in a v2 data file.
+func (dr *DataReaderV1) FooterIndex() (ir *IndexReaderV1, err error) {
+	if dr.version != DataFileVersionV2 {
+		err = errors.Errorf(
+			"data file version %d has no footer index",
+			dr.version,
+		)
+
+		return ir, err
+	}
+
+	indexOffset, indexSize, err := dr.readTrailerV2()
+	if err != nil {
+		return ir, err
+	}
+
+	if _, err = dr.reader.Seek(int64(indexOffset), io.SeekStart); err != nil {
+		err = errors.Wrapf(err, "seeking to footer index")
+		return ir, err
+	}
+
+	index := make([]byte, indexSize)
+
+	if _, err = io.ReadFull(dr.reader, index); err != nil {
+		err = wrapTruncated(err, "reading footer index")
+		return ir, err
+	}
+
+	if ir, err = NewIndexReaderV1(
+		bytes.NewReader(index),
+		int64(indexSize),
+	); err != nil {
+		err = wrapTruncated(err, "reading footer index")
+		return ir, err
+	}
+
+	return ir, err
+}
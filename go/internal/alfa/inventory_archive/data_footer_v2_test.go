@@ -0,0 +1,167 @@
+//go:build test && debug
+
+package inventory_archive
+
+import (
+	"bytes"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestV2RoundTripWithFooterIndex(t *testing.T) {
+	var buf bytes.Buffer
+
+	writer, err := NewDataWriterV2(
+		&buf,
+		"sha256",
+		compression_type.Params{Type: compression_type.CompressionTypeZstd},
+		FlagHasDeltas,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewDataWriterV2: %v", err)
+	}
+
+	fullData := []byte("the base content for delta")
+	fullHash := sha256Hash(fullData)
+
+	otherData := []byte("another full entry")
+	otherHash := sha256Hash(otherData)
+
+	deltaPayload := []byte("raw delta bytes here")
+	deltaHash := sha256Hash([]byte("reconstructed content"))
+
+	if err := writer.WriteFullEntry(fullHash, fullData); err != nil {
+		t.Fatalf("WriteFullEntry: %v", err)
+	}
+
+	if err := writer.WriteFullEntry(otherHash, otherData); err != nil {
+		t.Fatalf("WriteFullEntry: %v", err)
+	}
+
+	if err := writer.WriteDeltaEntry(
+		deltaHash,
+		DeltaAlgorithmByteBsdiff,
+		fullHash,
+		uint64(len("reconstructed content")),
+		deltaPayload,
+	); err != nil {
+		t.Fatalf("WriteDeltaEntry: %v", err)
+	}
+
+	_, writtenEntries, err := writer.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reader, err := NewDataReaderV1(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("NewDataReaderV1: %v", err)
+	}
+
+	if reader.Version() != DataFileVersionV2 {
+		t.Fatalf("expected version %d, got %d", DataFileVersionV2, reader.Version())
+	}
+
+	if err := reader.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	readEntries, err := reader.ReadAllEntries()
+	if err != nil {
+		t.Fatalf("ReadAllEntries: %v", err)
+	}
+
+	if len(readEntries) != len(writtenEntries) {
+		t.Fatalf(
+			"expected %d entries, got %d",
+			len(writtenEntries),
+			len(readEntries),
+		)
+	}
+
+	if !bytes.Equal(readEntries[1].Data, otherData) {
+		t.Errorf("entry 1: data mismatch")
+	}
+
+	index, err := reader.FooterIndex()
+	if err != nil {
+		t.Fatalf("FooterIndex: %v", err)
+	}
+
+	if err := index.Validate(); err != nil {
+		t.Fatalf("footer index Validate: %v", err)
+	}
+
+	if index.EntryCount() != uint64(len(writtenEntries)) {
+		t.Fatalf(
+			"expected %d index entries, got %d",
+			len(writtenEntries),
+			index.EntryCount(),
+		)
+	}
+
+	for _, written := range writtenEntries {
+		offset, storedSize, entryType, baseOffset, found, err := index.LookupHash(
+			written.Hash,
+		)
+		if err != nil {
+			t.Fatalf("LookupHash: %v", err)
+		}
+
+		if !found {
+			t.Fatalf("hash %x missing from footer index", written.Hash)
+		}
+
+		if offset != written.Offset || storedSize != written.StoredSize ||
+			entryType != written.EntryType {
+			t.Errorf("footer index entry for %x does not match", written.Hash)
+		}
+
+		if entryType == EntryTypeDelta && baseOffset != writtenEntries[0].Offset {
+			t.Errorf(
+				"delta base offset: expected %d, got %d",
+				writtenEntries[0].Offset,
+				baseOffset,
+			)
+		}
+
+		entry, err := reader.ReadEntryAt(offset)
+		if err != nil {
+			t.Fatalf("ReadEntryAt(%d): %v", offset, err)
+		}
+
+		if !bytes.Equal(entry.Hash, written.Hash) {
+			t.Errorf("entry at %d: hash mismatch", offset)
+		}
+	}
+}
+
+func TestV1HasNoFooterIndex(t *testing.T) {
+	var buf bytes.Buffer
+
+	writer, err := NewDataWriterV1(
+		&buf,
+		"sha256",
+		compression_type.CompressionTypeNone,
+		0,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewDataWriterV1: %v", err)
+	}
+
+	if _, _, err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reader, err := NewDataReaderV1(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("NewDataReaderV1: %v", err)
+	}
+
+	if _, err := reader.FooterIndex(); err == nil {
+		t.Fatal("expected an error reading the footer index of a v1 file")
+	}
+}
@@ -17,6 +17,7 @@ type DataReader struct {
 	encryption      interfaces.IOWrapper
 	hashSize        int
 	dataStart       int64
+	totalSize       int64
 }
 
 func NewDataReader(
@@ -33,6 +34,10 @@ func NewDataReader(
 		return nil, err
 	}
 
+	if dr.totalSize, err = readerSize(r); err != nil {
+		return nil, err
+	}
+
 	return dr, nil
 }
 
@@ -41,7 +46,7 @@ func (dr *DataReader) readHeader() (err error) {
 	magic := make([]byte, 4)
 
 	if _, err = io.ReadFull(dr.reader, magic); err != nil {
-		err = errors.Wrapf(err, "reading magic")
+		err = wrapTruncated(err, "reading magic")
 		return err
 	}
 
@@ -62,7 +67,7 @@ func (dr *DataReader) readHeader() (err error) {
 		binary.BigEndian,
 		&version,
 	); err != nil {
-		err = errors.Wrapf(err, "reading version")
+		err = wrapTruncated(err, "reading version")
 		return err
 	}
 
@@ -79,7 +84,7 @@ func (dr *DataReader) readHeader() (err error) {
 	var hashFormatIdLen [1]byte
 
 	if _, err = io.ReadFull(dr.reader, hashFormatIdLen[:]); err != nil {
-		err = errors.Wrapf(err, "reading hash format id length")
+		err = wrapTruncated(err, "reading hash format id length")
 		return err
 	}
 
@@ -87,7 +92,7 @@ func (dr *DataReader) readHeader() (err error) {
 	hashFormatIdBytes := make([]byte, hashFormatIdLen[0])
 
 	if _, err = io.ReadFull(dr.reader, hashFormatIdBytes); err != nil {
-		err = errors.Wrapf(err, "reading hash format id")
+		err = wrapTruncated(err, "reading hash format id")
 		return err
 	}
 
@@ -103,7 +108,7 @@ func (dr *DataReader) readHeader() (err error) {
 	var compressionByte [1]byte
 
 	if _, err = io.ReadFull(dr.reader, compressionByte[:]); err != nil {
-		err = errors.Wrapf(err, "reading compression byte")
+		err = wrapTruncated(err, "reading compression byte")
 		return err
 	}
 
@@ -116,7 +121,7 @@ func (dr *DataReader) readHeader() (err error) {
 	// flags: 2 bytes
 	var flags uint16
 	if err = binary.Read(dr.reader, binary.BigEndian, &flags); err != nil {
-		err = errors.Wrapf(err, "reading flags")
+		err = wrapTruncated(err, "reading flags")
 		return err
 	}
 
@@ -159,7 +164,7 @@ func (dr *DataReader) ReadEntry() (entry DataEntry, err error) {
 		}
 
 		// io.ErrUnexpectedEOF means partial read = truncated file
-		err = errors.Wrapf(err, "reading entry hash")
+		err = wrapTruncated(err, "reading entry hash")
 		return entry, err
 	}
 
@@ -169,7 +174,7 @@ func (dr *DataReader) ReadEntry() (entry DataEntry, err error) {
 		binary.BigEndian,
 		&entry.LogicalSize,
 	); err != nil {
-		err = errors.Wrapf(err, "reading logical size")
+		err = wrapTruncated(err, "reading logical size")
 		return entry, err
 	}
 
@@ -179,15 +184,17 @@ func (dr *DataReader) ReadEntry() (entry DataEntry, err error) {
 		binary.BigEndian,
 		&entry.StoredSize,
 	); err != nil {
-		err = errors.Wrapf(err, "reading stored size")
+		err = wrapTruncated(err, "reading stored size")
 		return entry, err
 	}
 
 	// Read payload
-	storedData := make([]byte, entry.StoredSize)
-
-	if _, err = io.ReadFull(dr.reader, storedData); err != nil {
-		err = errors.Wrapf(err, "reading payload")
+	storedData, err := readBoundedPayload(
+		dr.reader,
+		dr.totalSize,
+		entry.StoredSize,
+	)
+	if err != nil {
 		return entry, err
 	}
 
@@ -322,7 +329,7 @@ func (dr *DataReader) Validate() (err error) {
 	storedChecksum := make([]byte, dr.hashSize)
 
 	if _, err = io.ReadFull(dr.reader, storedChecksum); err != nil {
-		err = errors.Wrapf(err, "reading stored checksum")
+		err = wrapTruncated(err, "reading stored checksum")
 		return err
 	}
 
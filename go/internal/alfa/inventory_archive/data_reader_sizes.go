@@ -0,0 +1,81 @@
+package inventory_archive
+
+import (
+	"encoding/binary"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// LogicalSizeAt reads the uncompressed size of the entry at offset from its
+// header, without reading its payload.
+func (dr *DataReader) LogicalSizeAt(
+	offset uint64,
+) (logicalSize uint64, err error) {
+	if _, err = dr.reader.Seek(
+		int64(offset)+int64(dr.hashSize),
+		io.SeekStart,
+	); err != nil {
+		err = errors.Wrapf(err, "seeking to offset %d", offset)
+		return logicalSize, err
+	}
+
+	if err = binary.Read(dr.reader, binary.BigEndian, &logicalSize); err != nil {
+		err = wrapTruncated(err, "reading logical size")
+		return logicalSize, err
+	}
+
+	return logicalSize, err
+}
+
+// LogicalSizeAt reads the uncompressed size of the full or delta entry at
+// offset from its header, without reading its payload or, for a delta entry,
+// resolving its base.
+func (dr *DataReaderV1) LogicalSizeAt(
+	offset uint64,
+) (logicalSize uint64, err error) {
+	if _, err = dr.reader.Seek(
+		int64(offset)+int64(dr.hashSize),
+		io.SeekStart,
+	); err != nil {
+		err = errors.Wrapf(err, "seeking to offset %d", offset)
+		return logicalSize, err
+	}
+
+	// entry_type and encoding
+	var typeAndEncoding [2]byte
+
+	if _, err = io.ReadFull(dr.reader, typeAndEncoding[:]); err != nil {
+		err = wrapTruncated(err, "reading entry type and encoding")
+		return logicalSize, err
+	}
+
+	switch typeAndEncoding[0] {
+	case EntryTypeFull:
+
+	case EntryTypeDelta:
+		// delta_algorithm and base_hash
+		if _, err = dr.reader.Seek(
+			1+int64(dr.hashSize),
+			io.SeekCurrent,
+		); err != nil {
+			err = errors.Wrapf(err, "skipping delta base at offset %d", offset)
+			return logicalSize, err
+		}
+
+	default:
+		err = errors.Errorf(
+			"entry at offset %d has unknown type %d",
+			offset,
+			typeAndEncoding[0],
+		)
+		return logicalSize, err
+	}
+
+	if err = binary.Read(dr.reader, binary.BigEndian, &logicalSize); err != nil {
+		err = wrapTruncated(err, "reading logical size")
+		return logicalSize, err
+	}
+
+	return logicalSize, err
+}
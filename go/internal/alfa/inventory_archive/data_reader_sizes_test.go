@@ -0,0 +1,98 @@
+//go:build test && debug
+
+package inventory_archive
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestLogicalSizeAt(t *testing.T) {
+	var buf bytes.Buffer
+
+	writer, err := NewDataWriter(&buf, "sha256", compression_type.CompressionTypeZstd, nil)
+	if err != nil {
+		t.Fatalf("NewDataWriter: %v", err)
+	}
+
+	data := []byte(strings.Repeat("v0 logical size ", 32))
+
+	if err := writer.WriteEntry(sha256Hash(data), data); err != nil {
+		t.Fatalf("WriteEntry: %v", err)
+	}
+
+	_, entries, err := writer.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reader, err := NewDataReader(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("NewDataReader: %v", err)
+	}
+
+	logicalSize, err := reader.LogicalSizeAt(entries[0].Offset)
+	if err != nil {
+		t.Fatalf("LogicalSizeAt: %v", err)
+	}
+
+	if logicalSize != uint64(len(data)) {
+		t.Errorf("expected size %d, got %d", len(data), logicalSize)
+	}
+}
+
+func TestV1LogicalSizeAt(t *testing.T) {
+	var buf bytes.Buffer
+
+	writer, err := NewDataWriterV1(
+		&buf,
+		"sha256",
+		compression_type.CompressionTypeZstd,
+		FlagHasDeltas,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewDataWriterV1: %v", err)
+	}
+
+	fullData := []byte(strings.Repeat("v1 full entry ", 32))
+	reconstructed := "reconstructed delta content"
+
+	if err := writer.WriteFullEntry(sha256Hash(fullData), fullData); err != nil {
+		t.Fatalf("WriteFullEntry: %v", err)
+	}
+
+	if err := writer.WriteDeltaEntry(
+		sha256Hash([]byte(reconstructed)),
+		DeltaAlgorithmByteBsdiff,
+		sha256Hash(fullData),
+		uint64(len(reconstructed)),
+		[]byte("raw delta bytes"),
+	); err != nil {
+		t.Fatalf("WriteDeltaEntry: %v", err)
+	}
+
+	_, entries, err := writer.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reader, err := NewDataReaderV1(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("NewDataReaderV1: %v", err)
+	}
+
+	for i, expected := range []int{len(fullData), len(reconstructed)} {
+		logicalSize, err := reader.LogicalSizeAt(entries[i].Offset)
+		if err != nil {
+			t.Fatalf("entry %d: LogicalSizeAt: %v", i, err)
+		}
+
+		if logicalSize != uint64(expected) {
+			t.Errorf("entry %d: expected size %d, got %d", i, expected, logicalSize)
+		}
+	}
+}
@@ -13,11 +13,24 @@ import (
 type DataReaderV1 struct {
 	reader          io.ReadSeeker
 	hashFormatId    string
+	version         uint16
 	compressionType compression_type.CompressionType
+	level           int
+	dictionaryId    uint32
+	dictionary      []byte
 	encryption      interfaces.IOWrapper
 	hashSize        int
 	flags           uint16
 	dataStart       int64
+	totalSize       int64
+
+	// reused for stored payloads, which are decoded into a new slice before
+	// the next read
+	scratch []byte
+
+	// reused for entry fields and payloads so that reads do not allocate
+	fieldBuffer   [8]byte
+	payloadReader bytes.Reader
 }
 
 func NewDataReaderV1(
@@ -34,15 +47,27 @@ func NewDataReaderV1(
 		return nil, err
 	}
 
+	if dr.totalSize, err = readerSize(r); err != nil {
+		return nil, err
+	}
+
 	return dr, nil
 }
 
+// Reset points the reader at r, another handle on the archive it was made
+// for, keeping the parsed header, dictionary and scratch buffer so that a
+// pooled reader reads the archive without parsing it again. A nil r drops the
+// handle before the reader is pooled.
+func (dr *DataReaderV1) Reset(r io.ReadSeeker) {
+	dr.reader = r
+}
+
 func (dr *DataReaderV1) readHeader() (err error) {
 	// magic: 4 bytes
 	magic := make([]byte, 4)
 
 	if _, err = io.ReadFull(dr.reader, magic); err != nil {
-		err = errors.Wrapf(err, "reading magic")
+		err = wrapTruncated(err, "reading magic")
 		return err
 	}
 
@@ -56,22 +81,21 @@ func (dr *DataReaderV1) readHeader() (err error) {
 	}
 
 	// version: 2 bytes uint16 BigEndian
-	var version uint16
-
 	if err = binary.Read(
 		dr.reader,
 		binary.BigEndian,
-		&version,
+		&dr.version,
 	); err != nil {
-		err = errors.Wrapf(err, "reading version")
+		err = wrapTruncated(err, "reading version")
 		return err
 	}
 
-	if version != DataFileVersionV1 {
+	if dr.version != DataFileVersionV1 && dr.version != DataFileVersionV2 {
 		err = errors.Errorf(
-			"unsupported version: got %d, want %d",
-			version,
+			"unsupported version: got %d, want %d or %d",
+			dr.version,
 			DataFileVersionV1,
+			DataFileVersionV2,
 		)
 		return err
 	}
@@ -80,7 +104,7 @@ func (dr *DataReaderV1) readHeader() (err error) {
 	var hashFormatIdLen [1]byte
 
 	if _, err = io.ReadFull(dr.reader, hashFormatIdLen[:]); err != nil {
-		err = errors.Wrapf(err, "reading hash format id length")
+		err = wrapTruncated(err, "reading hash format id length")
 		return err
 	}
 
@@ -88,7 +112,7 @@ func (dr *DataReaderV1) readHeader() (err error) {
 	hashFormatIdBytes := make([]byte, hashFormatIdLen[0])
 
 	if _, err = io.ReadFull(dr.reader, hashFormatIdBytes); err != nil {
-		err = errors.Wrapf(err, "reading hash format id")
+		err = wrapTruncated(err, "reading hash format id")
 		return err
 	}
 
@@ -104,7 +128,7 @@ func (dr *DataReaderV1) readHeader() (err error) {
 	var compressionByte [1]byte
 
 	if _, err = io.ReadFull(dr.reader, compressionByte[:]); err != nil {
-		err = errors.Wrapf(err, "reading default encoding byte")
+		err = wrapTruncated(err, "reading default encoding byte")
 		return err
 	}
 
@@ -120,7 +144,7 @@ func (dr *DataReaderV1) readHeader() (err error) {
 		binary.BigEndian,
 		&dr.flags,
 	); err != nil {
-		err = errors.Wrapf(err, "reading flags")
+		err = wrapTruncated(err, "reading flags")
 		return err
 	}
 
@@ -133,6 +157,75 @@ func (dr *DataReaderV1) readHeader() (err error) {
 			2, // flags
 	)
 
+	if dr.flags&FlagHasCompressionParamsV1 == 0 {
+		return nil
+	}
+
+	// compression_level: 1 byte
+	var levelByte [1]byte
+
+	if _, err = io.ReadFull(dr.reader, levelByte[:]); err != nil {
+		err = wrapTruncated(err, "reading compression level")
+		return err
+	}
+
+	dr.level = int(int8(levelByte[0]))
+
+	// dictionary_id: 4 bytes
+	if err = binary.Read(
+		dr.reader,
+		binary.BigEndian,
+		&dr.dictionaryId,
+	); err != nil {
+		err = wrapTruncated(err, "reading dictionary id")
+		return err
+	}
+
+	dr.dataStart += 1 + // compression_level
+		4 // dictionary_id
+
+	if dr.dictionaryId == 0 {
+		return nil
+	}
+
+	// dictionary_stored_size: 4 bytes
+	var storedSize uint32
+
+	if err = binary.Read(
+		dr.reader,
+		binary.BigEndian,
+		&storedSize,
+	); err != nil {
+		err = wrapTruncated(err, "reading dictionary size")
+		return err
+	}
+
+	if storedSize > dictionaryStoredSizeMax {
+		err = errors.Errorf(
+			"dictionary of %d bytes exceeds the maximum of %d",
+			storedSize,
+			dictionaryStoredSizeMax,
+		)
+
+		return err
+	}
+
+	// dictionary: variable
+	storedDictionary := make([]byte, storedSize)
+
+	if _, err = io.ReadFull(dr.reader, storedDictionary); err != nil {
+		err = wrapTruncated(err, "reading dictionary")
+		return err
+	}
+
+	dr.dataStart += 4 + // dictionary_stored_size
+		int64(storedSize) // dictionary
+
+	if err = dr.setDictionary(storedDictionary); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
 	return nil
 }
 
@@ -148,6 +241,54 @@ func (dr *DataReaderV1) Flags() uint16 {
 	return dr.flags
 }
 
+func (dr *DataReaderV1) CompressionLevel() int {
+	return dr.level
+}
+
+// DictionaryId returns the id of the dictionary that full entries were
+// compressed with, or 0 if the archive does not use one.
+func (dr *DataReaderV1) DictionaryId() uint32 {
+	return dr.dictionaryId
+}
+
+// setDictionary decrypts the dictionary embedded in the header and checks it
+// against the id the header names.
+func (dr *DataReaderV1) setDictionary(stored []byte) (err error) {
+	dictionary := stored
+
+	if dr.encryption != nil {
+		var decryptReader io.ReadCloser
+
+		if decryptReader, err = dr.encryption.WrapReader(
+			bytes.NewReader(stored),
+		); err != nil {
+			err = errors.Wrapf(err, "creating dictionary decryption reader")
+			return err
+		}
+
+		defer errors.DeferredCloser(&err, decryptReader)
+
+		if dictionary, err = io.ReadAll(decryptReader); err != nil {
+			err = errors.Wrapf(err, "decrypting dictionary")
+			return err
+		}
+	}
+
+	if id := compression_type.DictionaryId(dictionary); id != dr.dictionaryId {
+		err = errors.Errorf(
+			"dictionary mismatch: got %08x, archive uses %08x",
+			id,
+			dr.dictionaryId,
+		)
+
+		return err
+	}
+
+	dr.dictionary = dictionary
+
+	return nil
+}
+
 func (dr *DataReaderV1) ReadEntry() (entry DataEntryV1, err error) {
 	currentPos, err := dr.reader.Seek(0, io.SeekCurrent)
 	if err != nil {
@@ -165,36 +306,51 @@ func (dr *DataReaderV1) ReadEntry() (entry DataEntryV1, err error) {
 			return entry, io.EOF
 		}
 
-		err = errors.Wrapf(err, "reading entry hash")
+		err = wrapTruncated(err, "reading entry hash")
 		return entry, err
 	}
 
 	// entry_type
-	var entryTypeByte [1]byte
-
-	if _, err = io.ReadFull(dr.reader, entryTypeByte[:]); err != nil {
-		err = errors.Wrapf(err, "reading entry type")
+	if entry.EntryType, err = dr.readByte(); err != nil {
+		err = wrapTruncated(err, "reading entry type")
 		return entry, err
 	}
 
-	entry.EntryType = entryTypeByte[0]
+	if entry.EntryType == EntryTypePadding {
+		if err = dr.skipPadding(); err != nil {
+			return entry, err
+		}
 
-	// encoding
-	var encodingByte [1]byte
+		return dr.ReadEntry()
+	}
 
-	if _, err = io.ReadFull(dr.reader, encodingByte[:]); err != nil {
-		err = errors.Wrapf(err, "reading encoding")
+	// encoding
+	if entry.Encoding, err = dr.readByte(); err != nil {
+		err = wrapTruncated(err, "reading encoding")
 		return entry, err
 	}
 
-	entry.Encoding = encodingByte[0]
-
-	entryCompression, err := ByteToCompression(entry.Encoding)
+	ct, usesDictionary, err := EncodingToCompression(entry.Encoding)
 	if err != nil {
 		err = errors.Wrap(err)
 		return entry, err
 	}
 
+	entryCompression := compression_type.Params{Type: ct}
+
+	if usesDictionary {
+		if dr.dictionary == nil {
+			err = errors.Errorf(
+				"entry at offset %d needs a dictionary, but the header has none",
+				entry.Offset,
+			)
+
+			return entry, err
+		}
+
+		entryCompression.Dictionary = dr.dictionary
+	}
+
 	switch entry.EntryType {
 	case EntryTypeFull:
 		err = dr.readFullEntryBody(&entry, entryCompression)
@@ -213,38 +369,57 @@ func (dr *DataReaderV1) ReadEntry() (entry DataEntryV1, err error) {
 	return entry, nil
 }
 
-func (dr *DataReaderV1) readFullEntryBody(
-	entry *DataEntryV1,
-	entryCompression compression_type.CompressionType,
-) (err error) {
-	// logical_size
+// skipPadding skips the body of a padding entry, whose hash and entry type
+// were already read.
+func (dr *DataReaderV1) skipPadding() (err error) {
+	var paddingSize uint32
+
 	if err = binary.Read(
 		dr.reader,
 		binary.BigEndian,
-		&entry.LogicalSize,
+		&paddingSize,
 	); err != nil {
-		err = errors.Wrapf(err, "reading logical size")
+		err = wrapTruncated(err, "reading padding size")
+		return err
+	}
+
+	if _, err = dr.reader.Seek(int64(paddingSize), io.SeekCurrent); err != nil {
+		err = errors.Wrapf(err, "skipping padding")
+		return err
+	}
+
+	return nil
+}
+
+func (dr *DataReaderV1) readFullEntryBody(
+	entry *DataEntryV1,
+	entryCompression compression_type.Params,
+) (err error) {
+	// logical_size
+	if entry.LogicalSize, err = dr.readUint64(); err != nil {
+		err = wrapTruncated(err, "reading logical size")
 		return err
 	}
 
 	// stored_size
-	if err = binary.Read(
-		dr.reader,
-		binary.BigEndian,
-		&entry.StoredSize,
-	); err != nil {
-		err = errors.Wrapf(err, "reading stored size")
+	if entry.StoredSize, err = dr.readUint64(); err != nil {
+		err = wrapTruncated(err, "reading stored size")
 		return err
 	}
 
 	// payload
-	storedData := make([]byte, entry.StoredSize)
-
-	if _, err = io.ReadFull(dr.reader, storedData); err != nil {
-		err = errors.Wrapf(err, "reading payload")
+	storedData, err := readBoundedPayloadInto(
+		dr.reader,
+		dr.totalSize,
+		entry.StoredSize,
+		dr.scratch,
+	)
+	if err != nil {
 		return err
 	}
 
+	dr.scratch = storedData
+
 	// Decrypt if needed
 	dataToDecompress := storedData
 	if dr.encryption != nil {
@@ -265,15 +440,15 @@ func (dr *DataReaderV1) readFullEntryBody(
 	}
 
 	// Decompress
-	decompressReader, err := entryCompression.WrapReader(
-		bytes.NewReader(dataToDecompress),
-	)
+	dr.payloadReader.Reset(dataToDecompress)
+
+	decompressReader, err := entryCompression.WrapReader(&dr.payloadReader)
 	if err != nil {
 		err = errors.Wrapf(err, "creating decompression reader")
 		return err
 	}
 
-	entry.Data, err = io.ReadAll(decompressReader)
+	entry.Data, err = readAllSized(decompressReader, entry.LogicalSize)
 	if err != nil {
 		err = errors.Wrapf(err, "decompressing data")
 		return err
@@ -289,54 +464,47 @@ func (dr *DataReaderV1) readFullEntryBody(
 
 func (dr *DataReaderV1) readDeltaEntryBody(
 	entry *DataEntryV1,
-	entryCompression compression_type.CompressionType,
+	entryCompression compression_type.Params,
 ) (err error) {
 	// delta_algorithm
-	var deltaAlgByte [1]byte
-
-	if _, err = io.ReadFull(dr.reader, deltaAlgByte[:]); err != nil {
-		err = errors.Wrapf(err, "reading delta algorithm")
+	if entry.DeltaAlgorithm, err = dr.readByte(); err != nil {
+		err = wrapTruncated(err, "reading delta algorithm")
 		return err
 	}
 
-	entry.DeltaAlgorithm = deltaAlgByte[0]
-
 	// base_hash
 	entry.BaseHash = make([]byte, dr.hashSize)
 
 	if _, err = io.ReadFull(dr.reader, entry.BaseHash); err != nil {
-		err = errors.Wrapf(err, "reading base hash")
+		err = wrapTruncated(err, "reading base hash")
 		return err
 	}
 
 	// logical_size
-	if err = binary.Read(
-		dr.reader,
-		binary.BigEndian,
-		&entry.LogicalSize,
-	); err != nil {
-		err = errors.Wrapf(err, "reading logical size")
+	if entry.LogicalSize, err = dr.readUint64(); err != nil {
+		err = wrapTruncated(err, "reading logical size")
 		return err
 	}
 
 	// stored_size
-	if err = binary.Read(
-		dr.reader,
-		binary.BigEndian,
-		&entry.StoredSize,
-	); err != nil {
-		err = errors.Wrapf(err, "reading stored size")
+	if entry.StoredSize, err = dr.readUint64(); err != nil {
+		err = wrapTruncated(err, "reading stored size")
 		return err
 	}
 
 	// payload
-	storedData := make([]byte, entry.StoredSize)
-
-	if _, err = io.ReadFull(dr.reader, storedData); err != nil {
-		err = errors.Wrapf(err, "reading payload")
+	storedData, err := readBoundedPayloadInto(
+		dr.reader,
+		dr.totalSize,
+		entry.StoredSize,
+		dr.scratch,
+	)
+	if err != nil {
 		return err
 	}
 
+	dr.scratch = storedData
+
 	// Decrypt if needed
 	dataToDecompress := storedData
 	if dr.encryption != nil {
@@ -357,15 +525,16 @@ func (dr *DataReaderV1) readDeltaEntryBody(
 	}
 
 	// Decompress delta payload
-	decompressReader, err := entryCompression.WrapReader(
-		bytes.NewReader(dataToDecompress),
-	)
+	dr.payloadReader.Reset(dataToDecompress)
+
+	decompressReader, err := entryCompression.WrapReader(&dr.payloadReader)
 	if err != nil {
 		err = errors.Wrapf(err, "creating decompression reader for delta")
 		return err
 	}
 
-	entry.Data, err = io.ReadAll(decompressReader)
+	// the logical size is the target's, not the delta's
+	entry.Data, err = readAllSized(decompressReader, 0)
 	if err != nil {
 		err = errors.Wrapf(err, "decompressing delta payload")
 		return err
@@ -380,20 +549,12 @@ func (dr *DataReaderV1) readDeltaEntryBody(
 }
 
 func (dr *DataReaderV1) ReadAllEntries() (entries []DataEntryV1, err error) {
-	if _, err = dr.reader.Seek(dr.dataStart, io.SeekStart); err != nil {
-		err = errors.Wrapf(err, "seeking to data start")
-		return nil, err
-	}
-
-	totalSize, err := dr.reader.Seek(0, io.SeekEnd)
+	entriesEnd, err := dr.entriesEnd()
 	if err != nil {
-		err = errors.Wrapf(err, "seeking to end")
+		err = errors.Wrap(err)
 		return nil, err
 	}
 
-	footerSize := int64(8 + dr.hashSize)
-	entriesEnd := totalSize - footerSize
-
 	if _, err = dr.reader.Seek(dr.dataStart, io.SeekStart); err != nil {
 		err = errors.Wrapf(err, "seeking back to data start")
 		return nil, err
@@ -461,7 +622,7 @@ func (dr *DataReaderV1) Validate() (err error) {
 	storedChecksum := make([]byte, dr.hashSize)
 
 	if _, err = io.ReadFull(dr.reader, storedChecksum); err != nil {
-		err = errors.Wrapf(err, "reading stored checksum")
+		err = wrapTruncated(err, "reading stored checksum")
 		return err
 	}
 
@@ -494,3 +655,47 @@ func (dr *DataReaderV1) Validate() (err error) {
 
 	return nil
 }
+
+// readByte reads a one-byte entry field.
+func (dr *DataReaderV1) readByte() (value byte, err error) {
+	if _, err = io.ReadFull(dr.reader, dr.fieldBuffer[:1]); err != nil {
+		return value, err
+	}
+
+	return dr.fieldBuffer[0], err
+}
+
+// readUint64 reads a big-endian entry field like binary.Read, without
+// allocating a buffer for it.
+func (dr *DataReaderV1) readUint64() (value uint64, err error) {
+	if _, err = io.ReadFull(dr.reader, dr.fieldBuffer[:]); err != nil {
+		return value, err
+	}
+
+	return binary.BigEndian.Uint64(dr.fieldBuffer[:]), err
+}
+
+// dataPreallocateMaxBytes caps how much of an entry's logical size is
+// allocated up front, since a corrupt header could claim any size.
+const dataPreallocateMaxBytes = 64 << 20
+
+// readAllSized is io.ReadAll for a reader expected to yield sizeHint bytes,
+// which it reads into a single allocation instead of growing a slice.
+func readAllSized(reader io.Reader, sizeHint uint64) (data []byte, err error) {
+	if sizeHint == 0 {
+		return io.ReadAll(reader)
+	}
+
+	var buffer bytes.Buffer
+
+	// ReadFrom grows the buffer unless MinRead bytes are left after the data
+	buffer.Grow(int(min(sizeHint, dataPreallocateMaxBytes)) + bytes.MinRead)
+
+	if _, err = buffer.ReadFrom(reader); err != nil {
+		return data, err
+	}
+
+	data = buffer.Bytes()
+
+	return data, err
+}
@@ -0,0 +1,77 @@
+package inventory_archive
+
+import (
+	"encoding/binary"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+// PlainEntryPayloadAt locates the payload of the entry at offset when it is
+// stored as the blob's plain bytes: a full entry that is neither compressed
+// nor encrypted. A range of such a blob can then be read straight from the
+// data file at start without reading the rest of the entry. ok is false for
+// every other entry, which has to be read whole with ReadEntryAt.
+func (dr *DataReaderV1) PlainEntryPayloadAt(
+	offset uint64,
+) (start int64, size uint64, ok bool, err error) {
+	if dr.encryption != nil {
+		return start, size, false, err
+	}
+
+	if _, err = dr.reader.Seek(
+		int64(offset)+int64(dr.hashSize),
+		io.SeekStart,
+	); err != nil {
+		err = errors.Wrapf(err, "seeking to offset %d", offset)
+		return start, size, false, err
+	}
+
+	// entry_type and encoding
+	var typeAndEncoding [2]byte
+
+	if _, err = io.ReadFull(dr.reader, typeAndEncoding[:]); err != nil {
+		err = wrapTruncated(err, "reading entry type and encoding")
+		return start, size, false, err
+	}
+
+	if typeAndEncoding[0] != EntryTypeFull {
+		return start, size, false, err
+	}
+
+	ct, usesDictionary, err := EncodingToCompression(typeAndEncoding[1])
+	if err != nil {
+		err = errors.Wrap(err)
+		return start, size, false, err
+	}
+
+	if usesDictionary ||
+		(ct != compression_type.CompressionTypeNone &&
+			ct != compression_type.CompressionTypeEmpty) {
+		return start, size, false, err
+	}
+
+	var logicalSize, storedSize uint64
+
+	if err = binary.Read(dr.reader, binary.BigEndian, &logicalSize); err != nil {
+		err = wrapTruncated(err, "reading logical size")
+		return start, size, false, err
+	}
+
+	if err = binary.Read(dr.reader, binary.BigEndian, &storedSize); err != nil {
+		err = wrapTruncated(err, "reading stored size")
+		return start, size, false, err
+	}
+
+	if logicalSize != storedSize {
+		return start, size, false, err
+	}
+
+	if start, err = dr.reader.Seek(0, io.SeekCurrent); err != nil {
+		err = errors.Wrapf(err, "getting current position")
+		return start, size, false, err
+	}
+
+	return start, storedSize, true, err
+}
@@ -0,0 +1,69 @@
+//go:build test && debug
+
+package inventory_archive
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestV1PlainEntryPayloadAt(t *testing.T) {
+	data := []byte(strings.Repeat("plain entry payload ", 64))
+
+	for _, tc := range []struct {
+		ct      compression_type.CompressionType
+		isPlain bool
+	}{
+		{ct: compression_type.CompressionTypeNone, isPlain: true},
+		{ct: compression_type.CompressionTypeZstd, isPlain: false},
+	} {
+		t.Run(string(tc.ct), func(t *testing.T) {
+			var buf bytes.Buffer
+
+			writer, err := NewDataWriterV1(&buf, "sha256", tc.ct, 0, nil)
+			if err != nil {
+				t.Fatalf("NewDataWriterV1: %v", err)
+			}
+
+			if err := writer.WriteFullEntry(sha256Hash(data), data); err != nil {
+				t.Fatalf("WriteFullEntry: %v", err)
+			}
+
+			_, entries, err := writer.Close()
+			if err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			reader, err := NewDataReaderV1(bytes.NewReader(buf.Bytes()), nil)
+			if err != nil {
+				t.Fatalf("NewDataReaderV1: %v", err)
+			}
+
+			start, size, ok, err := reader.PlainEntryPayloadAt(entries[0].Offset)
+			if err != nil {
+				t.Fatalf("PlainEntryPayloadAt: %v", err)
+			}
+
+			if ok != tc.isPlain {
+				t.Fatalf("expected plain %t, got %t", tc.isPlain, ok)
+			}
+
+			if !ok {
+				return
+			}
+
+			if size != uint64(len(data)) {
+				t.Errorf("expected size %d, got %d", len(data), size)
+			}
+
+			payload := buf.Bytes()[start : start+int64(size)]
+
+			if !bytes.Equal(payload, data) {
+				t.Errorf("payload at %d does not match the entry's data", start)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ type DataWriter struct {
 	hashSize        int
 	entries         []DataEntry
 	offset          uint64
+	spoolDir        string
 }
 
 func NewDataWriter(
@@ -137,72 +138,69 @@ func (dw *DataWriter) writeHeader() (err error) {
 	return nil
 }
 
+// SetSpoolDir sets where payloads too large to spool in memory are staged
+// while their entries are written. It defaults to the system temp directory.
+func (dw *DataWriter) SetSpoolDir(dir string) {
+	dw.spoolDir = dir
+}
+
 func (dw *DataWriter) WriteEntry(
 	entryHash []byte,
 	data []byte,
+) (err error) {
+	return dw.WriteEntryFrom(entryHash, bytes.NewReader(data), uint64(len(data)))
+}
+
+// WriteEntryFrom writes an entry of size bytes read from reader, spooling the
+// compressed and encrypted payload until its stored size is known (see
+// SetSpoolDir) rather than holding the whole blob in memory.
+func (dw *DataWriter) WriteEntryFrom(
+	entryHash []byte,
+	reader io.Reader,
+	size uint64,
 ) (err error) {
 	entryOffset := dw.offset
 
-	// Write hash
-	if _, err = dw.multiWriter.Write(entryHash); err != nil {
+	storedSpool, logicalSize, _, err := stagePayload(
+		dw.spoolDir,
+		reader,
+		dw.compressionType,
+		dw.encryption,
+	)
+	if err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
-	// Write logical_size
-	logicalSize := uint64(len(data))
+	defer errors.DeferredCloser(&err, storedSpool)
 
-	if err = binary.Write(
-		dw.multiWriter,
-		binary.BigEndian,
-		logicalSize,
-	); err != nil {
-		err = errors.Wrap(err)
-		return err
-	}
-
-	// Compress data
-	var compressedBuf bytes.Buffer
+	if logicalSize != size {
+		err = errors.Errorf(
+			"entry size mismatch: expected %d bytes, read %d",
+			size,
+			logicalSize,
+		)
 
-	compressWriter, err := dw.compressionType.WrapWriter(&compressedBuf)
-	if err != nil {
-		err = errors.Wrap(err)
 		return err
 	}
 
-	if _, err = compressWriter.Write(data); err != nil {
+	// Write hash
+	if _, err = dw.multiWriter.Write(entryHash); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
-	if err = compressWriter.Close(); err != nil {
+	// Write logical_size
+	if err = binary.Write(
+		dw.multiWriter,
+		binary.BigEndian,
+		logicalSize,
+	); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
-	compressedData := compressedBuf.Bytes()
-
-	// Encrypt if configured
-	storedData := compressedData
-	if dw.encryption != nil {
-		var encryptedBuf bytes.Buffer
-		encryptWriter, encErr := dw.encryption.WrapWriter(&encryptedBuf)
-		if encErr != nil {
-			err = errors.Wrap(encErr)
-			return err
-		}
-		if _, encErr = encryptWriter.Write(compressedData); encErr != nil {
-			err = errors.Wrap(encErr)
-			return err
-		}
-		if encErr = encryptWriter.Close(); encErr != nil {
-			err = errors.Wrap(encErr)
-			return err
-		}
-		storedData = encryptedBuf.Bytes()
-	}
-
-	storedSize := uint64(len(storedData))
+	storedSize := storedSpool.Size()
 
 	// Write stored_size
 	if err = binary.Write(
@@ -215,7 +213,14 @@ func (dw *DataWriter) WriteEntry(
 	}
 
 	// Write payload
-	if _, err = dw.multiWriter.Write(storedData); err != nil {
+	var storedReader io.Reader
+
+	if storedReader, err = storedSpool.Reader(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if _, err = io.Copy(dw.multiWriter, storedReader); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"hash"
 	"io"
+	"math"
 
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
@@ -12,16 +13,22 @@ import (
 )
 
 type DataWriterV1 struct {
-	writer          io.Writer
-	hasher          hash.Hash
-	multiWriter     io.Writer
-	hashFormatId    string
-	compressionType compression_type.CompressionType
-	encryption      interfaces.IOWrapper
-	hashSize        int
-	flags           uint16
-	entries         []DataEntryV1
-	offset          uint64
+	writer       io.Writer
+	hasher       hash.Hash
+	multiWriter  io.Writer
+	hashFormatId string
+	version      uint16
+	compression  compression_type.Params
+	dictionaryId uint32
+	encryption   interfaces.IOWrapper
+	hashSize     int
+	flags        uint16
+	entries      []DataEntryV1
+	offset       uint64
+	spoolDir     string
+	alignment    uint64
+
+	minCompressionSavingsPercent int
 }
 
 func NewDataWriterV1(
@@ -30,6 +37,65 @@ func NewDataWriterV1(
 	ct compression_type.CompressionType,
 	flags uint16,
 	encryption interfaces.IOWrapper,
+) (dw *DataWriterV1, err error) {
+	return NewDataWriterV1WithCompression(
+		w,
+		hashFormatId,
+		compression_type.Params{Type: ct},
+		flags,
+		encryption,
+	)
+}
+
+// NewDataWriterV1WithCompression is NewDataWriterV1 with a zstd level and an
+// optional dictionary. When either is set, both are recorded in the header,
+// the dictionary embedded in it, and full entries are compressed with the
+// dictionary. Delta payloads never
+// use it, since they share little with the blobs the dictionary was trained
+// on.
+func NewDataWriterV1WithCompression(
+	w io.Writer,
+	hashFormatId string,
+	compression compression_type.Params,
+	flags uint16,
+	encryption interfaces.IOWrapper,
+) (dw *DataWriterV1, err error) {
+	return newDataWriterV1(
+		w,
+		DataFileVersionV1,
+		hashFormatId,
+		compression,
+		flags,
+		encryption,
+	)
+}
+
+// NewDataWriterV2 writes a v2 data file: a v1 data file whose Close appends
+// the archive's index as a footer, so that no separate index file is needed.
+func NewDataWriterV2(
+	w io.Writer,
+	hashFormatId string,
+	compression compression_type.Params,
+	flags uint16,
+	encryption interfaces.IOWrapper,
+) (dw *DataWriterV1, err error) {
+	return newDataWriterV1(
+		w,
+		DataFileVersionV2,
+		hashFormatId,
+		compression,
+		flags,
+		encryption,
+	)
+}
+
+func newDataWriterV1(
+	w io.Writer,
+	version uint16,
+	hashFormatId string,
+	compression compression_type.Params,
+	flags uint16,
+	encryption interfaces.IOWrapper,
 ) (dw *DataWriterV1, err error) {
 	hasher, err := newHashForFormat(hashFormatId)
 	if err != nil {
@@ -47,17 +113,35 @@ func NewDataWriterV1(
 		flags |= FlagHasEncryptionV1
 	}
 
+	if err = compression_type.ValidateZstdLevel(compression.Level); err != nil {
+		err = errors.Wrap(err)
+		return nil, err
+	}
+
+	var dictionaryId uint32
+
+	if compression.HasDictionary() {
+		dictionaryId = compression_type.DictionaryId(compression.Dictionary)
+	}
+
+	if compression.Level != compression_type.ZstdLevelDefault ||
+		dictionaryId != 0 {
+		flags |= FlagHasCompressionParamsV1
+	}
+
 	multiWriter := io.MultiWriter(w, hasher)
 
 	dw = &DataWriterV1{
-		writer:          w,
-		hasher:          hasher,
-		multiWriter:     multiWriter,
-		hashFormatId:    hashFormatId,
-		compressionType: ct,
-		encryption:      encryption,
-		hashSize:        hashSize,
-		flags:           flags,
+		writer:       w,
+		hasher:       hasher,
+		multiWriter:  multiWriter,
+		hashFormatId: hashFormatId,
+		version:      version,
+		compression:  compression,
+		dictionaryId: dictionaryId,
+		encryption:   encryption,
+		hashSize:     hashSize,
+		flags:        flags,
 	}
 
 	if err = dw.writeHeader(); err != nil {
@@ -79,7 +163,7 @@ func (dw *DataWriterV1) writeHeader() (err error) {
 	if err = binary.Write(
 		dw.multiWriter,
 		binary.BigEndian,
-		DataFileVersionV1,
+		dw.version,
 	); err != nil {
 		err = errors.Wrap(err)
 		return err
@@ -110,7 +194,7 @@ func (dw *DataWriterV1) writeHeader() (err error) {
 	}
 
 	// default_encoding: 1 byte
-	compressionByte, err := CompressionToByte(dw.compressionType)
+	compressionByte, err := CompressionToByte(dw.compression.Type)
 	if err != nil {
 		err = errors.Wrap(err)
 		return err
@@ -140,83 +224,318 @@ func (dw *DataWriterV1) writeHeader() (err error) {
 			2, // flags
 	)
 
+	if dw.flags&FlagHasCompressionParamsV1 == 0 {
+		return nil
+	}
+
+	// compression_level: 1 byte
+	if _, err = dw.multiWriter.Write(
+		[]byte{byte(int8(dw.compression.Level))},
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// dictionary_id: 4 bytes
+	if err = binary.Write(
+		dw.multiWriter,
+		binary.BigEndian,
+		dw.dictionaryId,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	dw.offset += 1 + // compression_level
+		4 // dictionary_id
+
+	if dw.dictionaryId == 0 {
+		return nil
+	}
+
+	var storedDictionary []byte
+
+	if storedDictionary, err = dw.encryptDictionary(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// dictionary_stored_size: 4 bytes
+	if err = binary.Write(
+		dw.multiWriter,
+		binary.BigEndian,
+		uint32(len(storedDictionary)),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// dictionary: variable
+	if _, err = dw.multiWriter.Write(storedDictionary); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	dw.offset += 4 + // dictionary_stored_size
+		uint64(len(storedDictionary)) // dictionary
+
 	return nil
 }
 
-func (dw *DataWriterV1) WriteFullEntry(
-	entryHash []byte,
-	data []byte,
-) (err error) {
-	entryOffset := dw.offset
+// encryptDictionary returns the dictionary as it is embedded in the header,
+// encrypted like entry payloads, since it is made of blob content.
+func (dw *DataWriterV1) encryptDictionary() (stored []byte, err error) {
+	if dw.encryption == nil {
+		return dw.compression.Dictionary, err
+	}
 
-	encodingByte, err := CompressionToByte(dw.compressionType)
-	if err != nil {
+	var buffer bytes.Buffer
+
+	var encryptWriter io.WriteCloser
+
+	if encryptWriter, err = dw.encryption.WrapWriter(&buffer); err != nil {
+		err = errors.Wrap(err)
+		return stored, err
+	}
+
+	if _, err = encryptWriter.Write(dw.compression.Dictionary); err != nil {
 		err = errors.Wrap(err)
+		return stored, err
+	}
+
+	if err = encryptWriter.Close(); err != nil {
+		err = errors.Wrap(err)
+		return stored, err
+	}
+
+	stored = buffer.Bytes()
+
+	return stored, err
+}
+
+// SetMinCompressionSavingsPercent sets how much smaller than its logical size,
+// in percent, a compressed full entry must be for the compressed form to be
+// kept. Entries that save less are stored uncompressed. The default of 0 only
+// discards compression that does not shrink the entry at all.
+func (dw *DataWriterV1) SetMinCompressionSavingsPercent(percent int) {
+	dw.minCompressionSavingsPercent = percent
+}
+
+// SetSpoolDir sets where payloads too large to spool in memory are staged
+// while their entries are written. It defaults to the system temp directory.
+func (dw *DataWriterV1) SetSpoolDir(dir string) {
+	dw.spoolDir = dir
+}
+
+// SetEntryAlignment makes every following entry start at a multiple of
+// alignment bytes into the file, writing a padding entry before each one that
+// would not. A blob that is unchanged between two packings then occupies the
+// same blocks of both archives, which backup tools that dedup fixed-size
+// blocks can take advantage of. Zero disables alignment.
+func (dw *DataWriterV1) SetEntryAlignment(alignment uint64) {
+	dw.alignment = alignment
+}
+
+// writePadding writes the padding entry that aligns the next entry, if any.
+// A gap too small to hold a padding entry is extended by another alignment.
+func (dw *DataWriterV1) writePadding() (err error) {
+	if dw.alignment == 0 || dw.offset%dw.alignment == 0 {
+		return nil
+	}
+
+	gap := dw.alignment - dw.offset%dw.alignment
+	headerSize := uint64(dw.hashSize) + // hash
+		1 + // entry_type
+		4 // padding_size
+
+	for gap < headerSize {
+		gap += dw.alignment
+	}
+
+	paddingSize := gap - headerSize
+
+	if paddingSize > math.MaxUint32 {
+		err = errors.Errorf("entry alignment too large: %d bytes", dw.alignment)
 		return err
 	}
 
 	// hash
-	if _, err = dw.multiWriter.Write(entryHash); err != nil {
+	if _, err = dw.multiWriter.Write(make([]byte, dw.hashSize)); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
 	// entry_type
-	if _, err = dw.multiWriter.Write([]byte{EntryTypeFull}); err != nil {
+	if _, err = dw.multiWriter.Write([]byte{EntryTypePadding}); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
-	// encoding
-	if _, err = dw.multiWriter.Write([]byte{encodingByte}); err != nil {
+	// padding_size
+	if err = binary.Write(
+		dw.multiWriter,
+		binary.BigEndian,
+		uint32(paddingSize),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// padding
+	if _, err = io.CopyN(
+		dw.multiWriter,
+		zeroReader{},
+		int64(paddingSize),
+	); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
-	// Compress data
-	logicalSize := uint64(len(data))
+	dw.offset += gap
 
-	var compressedBuf bytes.Buffer
+	return nil
+}
+
+type zeroReader struct{}
 
-	compressWriter, err := dw.compressionType.WrapWriter(&compressedBuf)
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func (dw *DataWriterV1) compressionSavesEnough(
+	logicalSize uint64,
+	compressedSize uint64,
+) bool {
+	if dw.compression.Type == compression_type.CompressionTypeNone ||
+		dw.compression.Type == compression_type.CompressionTypeEmpty {
+		return true
+	}
+
+	if compressedSize >= logicalSize {
+		return false
+	}
+
+	savings := logicalSize - compressedSize
+
+	return savings*100 > logicalSize*uint64(max(dw.minCompressionSavingsPercent, 0))
+}
+
+func (dw *DataWriterV1) WriteFullEntry(
+	entryHash []byte,
+	data []byte,
+) (err error) {
+	return dw.WriteFullEntryFrom(
+		entryHash,
+		bytes.NewReader(data),
+		uint64(len(data)),
+	)
+}
+
+// WriteFullEntryFrom writes a full entry of size bytes read from reader. The
+// payload is compressed and encrypted as it is read, and spooled in memory or,
+// when large, in a temp file (see SetSpoolDir) until its stored size is
+// known, so the blob never needs to be in memory as a whole.
+func (dw *DataWriterV1) WriteFullEntryFrom(
+	entryHash []byte,
+	reader io.Reader,
+	size uint64,
+) (err error) {
+	encodingByte, err := CompressionToByte(dw.compression.Type)
 	if err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
-	if _, err = compressWriter.Write(data); err != nil {
-		err = errors.Wrap(err)
-		return err
+	if dw.compression.HasDictionary() {
+		encodingByte |= EncodingFlagDictionary
 	}
 
-	if err = compressWriter.Close(); err != nil {
+	// Also spool the payload encrypted but uncompressed, so that it can be
+	// stored as is if compression gets discarded below.
+	var rawSpool *payloadSpool
+	var rawWriter io.WriteCloser
+
+	if encodingByte != CompressionByteNone {
+		rawSpool, rawWriter, err = makeEncryptingSpool(
+			dw.spoolDir,
+			dw.encryption,
+		)
+
+		defer errors.DeferredCloser(&err, rawSpool)
+
+		if err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		reader = io.TeeReader(reader, rawWriter)
+	}
+
+	storedSpool, logicalSize, compressedSize, err := stagePayload(
+		dw.spoolDir,
+		reader,
+		dw.compression,
+		dw.encryption,
+	)
+	if err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
-	compressedData := compressedBuf.Bytes()
+	defer errors.DeferredCloser(&err, storedSpool)
 
-	// Encrypt if configured
-	storedData := compressedData
-	if dw.encryption != nil {
-		var encryptedBuf bytes.Buffer
-		encryptWriter, encErr := dw.encryption.WrapWriter(&encryptedBuf)
-		if encErr != nil {
-			err = errors.Wrap(encErr)
+	if rawWriter != nil {
+		if err = rawWriter.Close(); err != nil {
+			err = errors.Wrap(err)
 			return err
 		}
-		if _, encErr = encryptWriter.Write(compressedData); encErr != nil {
-			err = errors.Wrap(encErr)
-			return err
-		}
-		if encErr = encryptWriter.Close(); encErr != nil {
-			err = errors.Wrap(encErr)
-			return err
-		}
-		storedData = encryptedBuf.Bytes()
 	}
 
-	storedSize := uint64(len(storedData))
+	if logicalSize != size {
+		err = errors.Errorf(
+			"entry size mismatch: expected %d bytes, read %d",
+			size,
+			logicalSize,
+		)
+
+		return err
+	}
+
+	// Trial-and-discard: already-compressed payloads (images, archives) do not
+	// shrink, so store them as is rather than paying for decompression on
+	// every read.
+	if rawSpool != nil &&
+		!dw.compressionSavesEnough(logicalSize, compressedSize) {
+		encodingByte = CompressionByteNone
+		storedSpool = rawSpool
+	}
+
+	if err = dw.writePadding(); err != nil {
+		return err
+	}
+
+	entryOffset := dw.offset
+
+	// hash
+	if _, err = dw.multiWriter.Write(entryHash); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// entry_type
+	if _, err = dw.multiWriter.Write([]byte{EntryTypeFull}); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// encoding
+	if _, err = dw.multiWriter.Write([]byte{encodingByte}); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	storedSize := storedSpool.Size()
 
 	// logical_size
 	if err = binary.Write(
@@ -239,7 +558,14 @@ func (dw *DataWriterV1) WriteFullEntry(
 	}
 
 	// payload
-	if _, err = dw.multiWriter.Write(storedData); err != nil {
+	var storedReader io.Reader
+
+	if storedReader, err = storedSpool.Reader(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if _, err = io.Copy(dw.multiWriter, storedReader); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
@@ -274,9 +600,13 @@ func (dw *DataWriterV1) WriteDeltaEntry(
 	logicalSize uint64,
 	deltaPayload []byte,
 ) (err error) {
+	if err = dw.writePadding(); err != nil {
+		return err
+	}
+
 	entryOffset := dw.offset
 
-	encodingByte, err := CompressionToByte(dw.compressionType)
+	encodingByte, err := CompressionToByte(dw.compression.Type)
 	if err != nil {
 		err = errors.Wrap(err)
 		return err
@@ -315,7 +645,10 @@ func (dw *DataWriterV1) WriteDeltaEntry(
 	// Compress delta payload
 	var compressedBuf bytes.Buffer
 
-	compressWriter, err := dw.compressionType.WrapWriter(&compressedBuf)
+	deltaCompression := dw.compression
+	deltaCompression.Dictionary = nil
+
+	compressWriter, err := deltaCompression.WrapWriter(&compressedBuf)
 	if err != nil {
 		err = errors.Wrap(err)
 		return err
@@ -416,6 +749,13 @@ func (dw *DataWriterV1) Close() (
 ) {
 	entryCount := uint64(len(dw.entries))
 
+	if dw.version == DataFileVersionV2 {
+		if err = dw.writeFooterIndexV2(); err != nil {
+			err = errors.Wrap(err)
+			return nil, nil, err
+		}
+	}
+
 	if err = binary.Write(
 		dw.multiWriter,
 		binary.BigEndian,
@@ -8,6 +8,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
+	"fmt"
 	"testing"
 
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
@@ -184,6 +185,8 @@ func TestV1RoundTripWithDelta(t *testing.T) {
 		t.Fatalf("WriteFullEntry: %v", err)
 	}
 
+	// the second entry ends 13 bytes short of the next boundary, too little
+	// for a padding entry
 	if err := writer.WriteDeltaEntry(
 		deltaHash,
 		DeltaAlgorithmByteBsdiff,
@@ -369,6 +372,272 @@ func TestV1HeaderFlags(t *testing.T) {
 	}
 }
 
+func TestV1RoundTripWithDictionary(t *testing.T) {
+	var samples [][]byte
+
+	for i := range 32 {
+		samples = append(samples, []byte(fmt.Sprintf(
+			"---\n! toml-tag-v1\n---\n\ndescription = 'tag number %d'\nhidden = false\n",
+			i,
+		)))
+	}
+
+	dictionary := compression_type.TrainDictionary(samples, 1024)
+
+	if len(dictionary) == 0 {
+		t.Fatal("expected a dictionary from similar samples")
+	}
+
+	compression := compression_type.Params{
+		Type:       compression_type.CompressionTypeZstd,
+		Level:      19,
+		Dictionary: dictionary,
+	}
+
+	var ageIdentity age.Identity
+
+	if err := ageIdentity.GenerateIfNecessary(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		encryption interfaces.IOWrapper
+	}{
+		{"plain", nil},
+		{"encrypted", &ageIdentity},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			writer, err := NewDataWriterV1WithCompression(
+				&buf,
+				"sha256",
+				compression,
+				0,
+				tc.encryption,
+			)
+			if err != nil {
+				t.Fatalf("NewDataWriterV1WithCompression: %v", err)
+			}
+
+			for _, sample := range samples {
+				if err := writer.WriteFullEntry(sha256Hash(sample), sample); err != nil {
+					t.Fatalf("WriteFullEntry: %v", err)
+				}
+			}
+
+			_, writtenEntries, err := writer.Close()
+			if err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			// the dictionary is embedded in the header, so nothing else is
+			// needed to read the archive
+			reader, err := NewDataReaderV1(
+				bytes.NewReader(buf.Bytes()),
+				tc.encryption,
+			)
+			if err != nil {
+				t.Fatalf("NewDataReaderV1: %v", err)
+			}
+
+			if reader.Flags()&FlagHasCompressionParamsV1 == 0 {
+				t.Error("expected FlagHasCompressionParamsV1 to be set")
+			}
+
+			if reader.CompressionLevel() != 19 {
+				t.Errorf("expected level 19, got %d", reader.CompressionLevel())
+			}
+
+			if reader.DictionaryId() != compression_type.DictionaryId(dictionary) {
+				t.Errorf(
+					"expected dictionary id %08x, got %08x",
+					compression_type.DictionaryId(dictionary),
+					reader.DictionaryId(),
+				)
+			}
+
+			entries, err := reader.ReadAllEntries()
+			if err != nil {
+				t.Fatalf("ReadAllEntries: %v", err)
+			}
+
+			if len(entries) != len(samples) {
+				t.Fatalf("expected %d entries, got %d", len(samples), len(entries))
+			}
+
+			for i, entry := range entries {
+				if entry.Encoding&EncodingFlagDictionary == 0 {
+					t.Errorf("entry %d: expected the dictionary encoding flag", i)
+				}
+
+				if entry.Offset != writtenEntries[i].Offset {
+					t.Errorf(
+						"entry %d: read at offset %d, written at %d",
+						i,
+						entry.Offset,
+						writtenEntries[i].Offset,
+					)
+				}
+
+				if !bytes.Equal(entry.Data, samples[i]) {
+					t.Errorf("entry %d: data mismatch", i)
+				}
+			}
+		})
+	}
+}
+
+func TestV1IncompressibleEntriesStoredUncompressed(t *testing.T) {
+	incompressible := make([]byte, 4096)
+
+	if _, err := rand.Read(incompressible); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+
+	compressible := bytes.Repeat([]byte("compressible text "), 256)
+
+	for _, tc := range []struct {
+		name                 string
+		minSavingsPercent    int
+		expectedIncompress   byte
+		expectedCompressible byte
+	}{
+		{"default", 0, CompressionByteNone, CompressionByteZstd},
+		{"threshold", 100, CompressionByteNone, CompressionByteNone},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			writer, err := NewDataWriterV1(
+				&buf,
+				"sha256",
+				compression_type.CompressionTypeZstd,
+				0,
+				nil,
+			)
+			if err != nil {
+				t.Fatalf("NewDataWriterV1: %v", err)
+			}
+
+			writer.SetMinCompressionSavingsPercent(tc.minSavingsPercent)
+
+			for _, data := range [][]byte{incompressible, compressible} {
+				if err := writer.WriteFullEntry(sha256Hash(data), data); err != nil {
+					t.Fatalf("WriteFullEntry: %v", err)
+				}
+			}
+
+			if _, _, err := writer.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			reader, err := NewDataReaderV1(bytes.NewReader(buf.Bytes()), nil)
+			if err != nil {
+				t.Fatalf("NewDataReaderV1: %v", err)
+			}
+
+			entries, err := reader.ReadAllEntries()
+			if err != nil {
+				t.Fatalf("ReadAllEntries: %v", err)
+			}
+
+			if entries[0].Encoding != tc.expectedIncompress {
+				t.Errorf(
+					"incompressible entry: expected encoding %d, got %d",
+					tc.expectedIncompress,
+					entries[0].Encoding,
+				)
+			}
+
+			if entries[0].StoredSize != uint64(len(incompressible)) {
+				t.Errorf(
+					"incompressible entry: expected stored size %d, got %d",
+					len(incompressible),
+					entries[0].StoredSize,
+				)
+			}
+
+			if entries[1].Encoding != tc.expectedCompressible {
+				t.Errorf(
+					"compressible entry: expected encoding %d, got %d",
+					tc.expectedCompressible,
+					entries[1].Encoding,
+				)
+			}
+
+			if !bytes.Equal(entries[0].Data, incompressible) ||
+				!bytes.Equal(entries[1].Data, compressible) {
+				t.Error("data mismatch")
+			}
+		})
+	}
+}
+
+func TestV1WriteFullEntryFromSpillsLargePayloads(t *testing.T) {
+	large := make([]byte, payloadSpoolMemoryLimit+1024)
+
+	if _, err := rand.Read(large); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	writer, err := NewDataWriterV1(
+		&buf,
+		"sha256",
+		compression_type.CompressionTypeZstd,
+		0,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewDataWriterV1: %v", err)
+	}
+
+	writer.SetSpoolDir(t.TempDir())
+
+	if err := writer.WriteFullEntryFrom(
+		sha256Hash(large),
+		bytes.NewReader(large),
+		uint64(len(large)),
+	); err != nil {
+		t.Fatalf("WriteFullEntryFrom: %v", err)
+	}
+
+	short := []byte("shorter than declared")
+
+	if err := writer.WriteFullEntryFrom(
+		sha256Hash(short),
+		bytes.NewReader(short),
+		uint64(len(short)+1),
+	); err == nil {
+		t.Fatal("expected an error for a payload shorter than its size")
+	}
+
+	if _, _, err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reader, err := NewDataReaderV1(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("NewDataReaderV1: %v", err)
+	}
+
+	entries, err := reader.ReadAllEntries()
+	if err != nil {
+		t.Fatalf("ReadAllEntries: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	if !bytes.Equal(entries[0].Data, large) {
+		t.Error("data mismatch")
+	}
+}
+
 func TestV1EncryptedRoundTrip(t *testing.T) {
 	var buf bytes.Buffer
 	hashFormatId := "sha256"
@@ -515,3 +784,109 @@ func TestV1PivyEncryptedRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestV2AlignedEntriesRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+
+	writer, err := NewDataWriterV2(
+		&buf,
+		"sha256",
+		compression_type.Params{Type: compression_type.CompressionTypeNone},
+		FlagHasDeltas,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewDataWriterV2: %v", err)
+	}
+
+	const alignment = 64
+
+	writer.SetEntryAlignment(alignment)
+
+	fullData := []byte("an aligned entry")
+	fullHash := sha256Hash(fullData)
+	deltaHash := sha256Hash([]byte("an aligned delta"))
+
+	if err := writer.WriteFullEntry(fullHash, fullData); err != nil {
+		t.Fatalf("WriteFullEntry: %v", err)
+	}
+
+	if err := writer.WriteFullEntry(
+		sha256Hash([]byte("x")),
+		[]byte("x"),
+	); err != nil {
+		t.Fatalf("WriteFullEntry: %v", err)
+	}
+
+	// the second entry ends 13 bytes short of the next boundary, too little
+	// for a padding entry
+	if err := writer.WriteDeltaEntry(
+		deltaHash,
+		DeltaAlgorithmByteBsdiff,
+		fullHash,
+		16,
+		[]byte("delta"),
+	); err != nil {
+		t.Fatalf("WriteDeltaEntry: %v", err)
+	}
+
+	_, writtenEntries, err := writer.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	for i, entry := range writtenEntries {
+		if entry.Offset%alignment != 0 {
+			t.Errorf("entry %d: offset %d is not aligned", i, entry.Offset)
+		}
+	}
+
+	reader, err := NewDataReaderV1(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("NewDataReaderV1: %v", err)
+	}
+
+	readEntries, err := reader.ReadAllEntries()
+	if err != nil {
+		t.Fatalf("ReadAllEntries: %v", err)
+	}
+
+	if len(readEntries) != len(writtenEntries) {
+		t.Fatalf(
+			"expected %d entries, got %d",
+			len(writtenEntries),
+			len(readEntries),
+		)
+	}
+
+	for i, entry := range readEntries {
+		if entry.Offset != writtenEntries[i].Offset {
+			t.Errorf(
+				"entry %d: read at offset %d, written at %d",
+				i,
+				entry.Offset,
+				writtenEntries[i].Offset,
+			)
+		}
+	}
+
+	if !bytes.Equal(readEntries[0].Data, fullData) {
+		t.Errorf("expected %q but got %q", fullData, readEntries[0].Data)
+	}
+
+	index, err := reader.FooterIndex()
+	if err != nil {
+		t.Fatalf("FooterIndex: %v", err)
+	}
+
+	if index.EntryCount() != uint64(len(writtenEntries)) {
+		t.Errorf(
+			"expected padding to be left out of the index, got %d entries",
+			index.EntryCount(),
+		)
+	}
+
+	if err := reader.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package inventory_archive
+
+import (
+	"fmt"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// ErrCorrupt reports a length or count field read from an archive file that
+// the file cannot hold: a corrupted or hostile file fails with it before the
+// field sizes an allocation.
+type ErrCorrupt struct {
+	File  string
+	Field string
+	Value uint64
+	Limit uint64
+}
+
+func (err ErrCorrupt) Error() string {
+	return fmt.Sprintf(
+		"corrupt %s file: %s is %d, but at most %d fits",
+		err.File,
+		err.Field,
+		err.Value,
+		err.Limit,
+	)
+}
+
+func (err ErrCorrupt) Is(target error) bool {
+	_, ok := target.(ErrCorrupt)
+	return ok
+}
+
+// checkEntryCount fails with ErrCorrupt unless entryCount records of
+// entrySize bytes, starting at entriesStart and followed by trailerSize bytes,
+// fit in a file of totalSize bytes.
+func checkEntryCount(
+	file string,
+	entryCount uint64,
+	entrySize, entriesStart, trailerSize, totalSize int64,
+) (err error) {
+	available := max(totalSize-entriesStart-trailerSize, 0)
+	limit := uint64(available) / uint64(entrySize)
+
+	if entryCount > limit {
+		err = errors.Wrap(ErrCorrupt{
+			File:  file,
+			Field: "entry count",
+			Value: entryCount,
+			Limit: limit,
+		})
+
+		return err
+	}
+
+	return err
+}
+
+// checkFanOutCount fails with ErrCorrupt when a fan-out count points past
+// the entries of the index, so that lookups only search existing entries.
+func checkFanOutCount(count, entryCount uint64) (err error) {
+	if count > entryCount {
+		err = errors.Wrap(ErrCorrupt{
+			File:  "index",
+			Field: "fan-out count",
+			Value: count,
+			Limit: entryCount,
+		})
+
+		return err
+	}
+
+	return err
+}
+
+// readBoundedPayload reads a payload of size bytes at the reader's position,
+// failing with ErrCorrupt rather than allocating when fewer than size bytes
+// are left before totalSize.
+func readBoundedPayload(
+	reader io.ReadSeeker,
+	totalSize int64,
+	size uint64,
+) (payload []byte, err error) {
+	return readBoundedPayloadInto(reader, totalSize, size, nil)
+}
+
+// readBoundedPayloadInto is readBoundedPayload reading into scratch when it
+// has the capacity, so that the payload is only valid until scratch is reused.
+func readBoundedPayloadInto(
+	reader io.ReadSeeker,
+	totalSize int64,
+	size uint64,
+	scratch []byte,
+) (payload []byte, err error) {
+	currentPos, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		err = errors.Wrapf(err, "getting current position")
+		return payload, err
+	}
+
+	remaining := uint64(max(totalSize-currentPos, 0))
+
+	if size > remaining {
+		err = errors.Wrap(ErrCorrupt{
+			File:  "data",
+			Field: "stored size",
+			Value: size,
+			Limit: remaining,
+		})
+
+		return payload, err
+	}
+
+	if uint64(cap(scratch)) >= size {
+		payload = scratch[:size]
+	} else {
+		payload = make([]byte, size)
+	}
+
+	if _, err = io.ReadFull(reader, payload); err != nil {
+		err = wrapTruncated(err, "reading payload")
+		return payload, err
+	}
+
+	return payload, err
+}
+
+// readerSize returns the size of reader without moving its position.
+func readerSize(reader io.ReadSeeker) (size int64, err error) {
+	currentPos, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		err = errors.Wrapf(err, "getting current position")
+		return size, err
+	}
+
+	if size, err = reader.Seek(0, io.SeekEnd); err != nil {
+		err = errors.Wrapf(err, "seeking to end")
+		return size, err
+	}
+
+	if _, err = reader.Seek(currentPos, io.SeekStart); err != nil {
+		err = errors.Wrapf(err, "seeking back to %d", currentPos)
+		return size, err
+	}
+
+	return size, err
+}
+
+// wrapTruncated wraps an error from reading an archive file. A file that ends
+// before a field is reported as io.ErrUnexpectedEOF, both because it is
+// corrupt rather than finished and because io.EOF must not be wrapped.
+func wrapTruncated(err error, format string, values ...any) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return errors.Wrapf(err, format, values...)
+}
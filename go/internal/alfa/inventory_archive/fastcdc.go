@@ -0,0 +1,114 @@
+package inventory_archive
+
+import (
+	"io"
+	"math/bits"
+)
+
+// FastCDCChunker splits a stream into content-defined chunks with FastCDC:
+// the Gear hash of GearCDCChunks, no cut point before the minimum size, a
+// stricter mask until the average size and a looser one after it
+// (normalized chunking), and a forced cut at the maximum size. The masks
+// test the hash's high bits, so a cut point depends on the 64 bytes before
+// it, and chunks after an edit realign with the unedited stream.
+type FastCDCChunker struct {
+	reader                    io.Reader
+	minSize, avgSize, maxSize int
+	maskStrict, maskLoose     uint64
+	buffer                    []byte
+	filled, consumed          int
+	eof                       bool
+}
+
+// MakeFastCDCChunker returns a chunker over reader. avgSize is rounded to a
+// power of two; minSize and maxSize bound every chunk but the last.
+func MakeFastCDCChunker(
+	reader io.Reader,
+	minSize, avgSize, maxSize int,
+) *FastCDCChunker {
+	avgSize = nextPowerOfTwo(avgSize)
+	maxSize = max(maxSize, avgSize)
+	minSize = min(max(minSize, 1), avgSize)
+
+	avgBits := bits.TrailingZeros64(uint64(avgSize))
+
+	return &FastCDCChunker{
+		reader:     reader,
+		minSize:    minSize,
+		avgSize:    avgSize,
+		maxSize:    maxSize,
+		maskStrict: fastCDCMask(avgBits + 2),
+		maskLoose:  fastCDCMask(max(avgBits-2, 1)),
+		buffer:     make([]byte, maxSize),
+	}
+}
+
+func fastCDCMask(ones int) uint64 {
+	ones = min(ones, 64)
+	return ^uint64(0) << (64 - ones)
+}
+
+// Next returns the next chunk, which is only valid until the following
+// call, or io.EOF once the stream is exhausted.
+func (chunker *FastCDCChunker) Next() (chunk []byte, err error) {
+	copy(chunker.buffer, chunker.buffer[chunker.consumed:chunker.filled])
+	chunker.filled -= chunker.consumed
+	chunker.consumed = 0
+
+	for !chunker.eof && chunker.filled < len(chunker.buffer) {
+		var n int
+
+		n, err = chunker.reader.Read(chunker.buffer[chunker.filled:])
+		chunker.filled += n
+
+		if err == io.EOF {
+			chunker.eof = true
+			err = nil
+		} else if err != nil {
+			return chunk, err
+		}
+	}
+
+	if chunker.filled == 0 {
+		err = io.EOF
+		return chunk, err
+	}
+
+	chunker.consumed = chunker.cutPoint(chunker.buffer[:chunker.filled])
+	chunk = chunker.buffer[:chunker.consumed]
+
+	return chunk, err
+}
+
+func (chunker *FastCDCChunker) cutPoint(data []byte) int {
+	size := len(data)
+
+	if size <= chunker.minSize {
+		return size
+	}
+
+	size = min(size, chunker.maxSize)
+	normal := min(size, chunker.avgSize)
+
+	var fingerprint uint64
+
+	i := chunker.minSize
+
+	for ; i < normal; i++ {
+		fingerprint = (fingerprint << 1) + gearTable[data[i]]
+
+		if fingerprint&chunker.maskStrict == 0 {
+			return i + 1
+		}
+	}
+
+	for ; i < size; i++ {
+		fingerprint = (fingerprint << 1) + gearTable[data[i]]
+
+		if fingerprint&chunker.maskLoose == 0 {
+			return i + 1
+		}
+	}
+
+	return size
+}
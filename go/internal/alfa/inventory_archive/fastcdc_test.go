@@ -0,0 +1,99 @@
+//go:build test && debug
+
+package inventory_archive
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"testing"
+)
+
+func makeFastCDCTestData(size int, seed uint64) []byte {
+	random := rand.New(rand.NewPCG(seed, seed))
+	data := make([]byte, size)
+
+	for i := range data {
+		data[i] = byte(random.Uint32())
+	}
+
+	return data
+}
+
+func fastCDCChunks(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+
+	chunker := MakeFastCDCChunker(bytes.NewReader(data), 256, 1024, 4096)
+
+	var chunks [][]byte
+
+	for {
+		chunk, err := chunker.Next()
+
+		if err == io.EOF {
+			return chunks
+		} else if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+
+		chunks = append(chunks, bytes.Clone(chunk))
+	}
+}
+
+func TestFastCDCChunksReassembleWithinBounds(t *testing.T) {
+	data := makeFastCDCTestData(256<<10, 1)
+	chunks := fastCDCChunks(t, data)
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+
+	for i, chunk := range chunks {
+		if len(chunk) > 4096 {
+			t.Errorf("chunk %d is %d bytes, over the maximum", i, len(chunk))
+		}
+
+		if i < len(chunks)-1 && len(chunk) < 256 {
+			t.Errorf("chunk %d is %d bytes, under the minimum", i, len(chunk))
+		}
+	}
+
+	if !bytes.Equal(bytes.Join(chunks, nil), data) {
+		t.Fatal("chunks do not reassemble the original data")
+	}
+}
+
+func TestFastCDCChunksRealignAfterInsertion(t *testing.T) {
+	data := makeFastCDCTestData(256<<10, 2)
+	edited := append(append(bytes.Clone(data[:1000]), "inserted"...), data[1000:]...)
+
+	original := make(map[string]struct{})
+
+	for _, chunk := range fastCDCChunks(t, data) {
+		original[string(chunk)] = struct{}{}
+	}
+
+	editedChunks := fastCDCChunks(t, edited)
+
+	var shared int
+
+	for _, chunk := range editedChunks {
+		if _, ok := original[string(chunk)]; ok {
+			shared++
+		}
+	}
+
+	if shared < len(editedChunks)-3 {
+		t.Errorf(
+			"expected all but the chunks around the insertion to be shared, %d of %d were",
+			shared,
+			len(editedChunks),
+		)
+	}
+}
+
+func TestFastCDCEmptyStream(t *testing.T) {
+	if chunks := fastCDCChunks(t, nil); len(chunks) != 0 {
+		t.Errorf("expected no chunks, got %d", len(chunks))
+	}
+}
@@ -54,7 +54,7 @@ func (ir *IndexReader) readHeader() (err error) {
 	magic := make([]byte, 4)
 
 	if _, err = ir.reader.ReadAt(magic, 0); err != nil {
-		err = errors.Wrapf(err, "reading magic")
+		err = wrapTruncated(err, "reading magic")
 		return err
 	}
 
@@ -71,7 +71,7 @@ func (ir *IndexReader) readHeader() (err error) {
 	versionBuf := make([]byte, 2)
 
 	if _, err = ir.reader.ReadAt(versionBuf, 4); err != nil {
-		err = errors.Wrapf(err, "reading version")
+		err = wrapTruncated(err, "reading version")
 		return err
 	}
 
@@ -90,7 +90,7 @@ func (ir *IndexReader) readHeader() (err error) {
 	hashFormatIdLenBuf := make([]byte, 1)
 
 	if _, err = ir.reader.ReadAt(hashFormatIdLenBuf, 6); err != nil {
-		err = errors.Wrapf(err, "reading hash format id length")
+		err = wrapTruncated(err, "reading hash format id length")
 		return err
 	}
 
@@ -100,7 +100,7 @@ func (ir *IndexReader) readHeader() (err error) {
 	hashFormatIdBytes := make([]byte, hashFormatIdLen)
 
 	if _, err = ir.reader.ReadAt(hashFormatIdBytes, 7); err != nil {
-		err = errors.Wrapf(err, "reading hash format id")
+		err = wrapTruncated(err, "reading hash format id")
 		return err
 	}
 
@@ -123,7 +123,7 @@ func (ir *IndexReader) readHeader() (err error) {
 		entryCountBuf,
 		entryCountOffset,
 	); err != nil {
-		err = errors.Wrapf(err, "reading entry count")
+		err = wrapTruncated(err, "reading entry count")
 		return err
 	}
 
@@ -137,7 +137,7 @@ func (ir *IndexReader) readFanOut() (err error) {
 	fanOutBuf := make([]byte, 256*8)
 
 	if _, err = ir.reader.ReadAt(fanOutBuf, headerSize); err != nil {
-		err = errors.Wrapf(err, "reading fan-out table")
+		err = wrapTruncated(err, "reading fan-out table")
 		return err
 	}
 
@@ -149,6 +149,18 @@ func (ir *IndexReader) readFanOut() (err error) {
 
 	ir.entriesStart = headerSize + 256*8
 
+	// entries are followed by the checksum
+	if err = checkEntryCount(
+		"index",
+		ir.entryCount,
+		ir.entrySize(),
+		ir.entriesStart,
+		int64(ir.hashSize),
+		ir.totalSize,
+	); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -186,7 +198,7 @@ func (ir *IndexReader) readEntryAt(index uint64) (
 	entryBuf := make([]byte, ir.entrySize())
 
 	if _, err = ir.reader.ReadAt(entryBuf, offset); err != nil {
-		err = errors.Wrapf(err, "reading entry %d", index)
+		err = wrapTruncated(err, "reading entry %d", index)
 		return entry, err
 	}
 
@@ -225,6 +237,10 @@ func (ir *IndexReader) LookupHash(hash []byte) (
 
 	hi := ir.fanOut[firstByte]
 
+	if err = checkFanOutCount(hi, ir.entryCount); err != nil {
+		return 0, 0, false, err
+	}
+
 	if lo >= hi {
 		return 0, 0, false, nil
 	}
@@ -259,7 +275,7 @@ func (ir *IndexReader) ReadAllEntries() (entries []IndexEntry, err error) {
 	for i := range ir.entryCount {
 		entries[i], err = ir.readEntryAt(i)
 		if err != nil {
-			err = errors.Wrapf(err, "reading entry %d", i)
+			err = wrapTruncated(err, "reading entry %d", i)
 			return nil, err
 		}
 	}
@@ -286,7 +302,7 @@ func (ir *IndexReader) Validate() (err error) {
 		storedChecksum,
 		checksumOffset,
 	); err != nil {
-		err = errors.Wrapf(err, "reading stored checksum")
+		err = wrapTruncated(err, "reading stored checksum")
 		return err
 	}
 
@@ -300,7 +316,7 @@ func (ir *IndexReader) Validate() (err error) {
 	contentBuf := make([]byte, checksumOffset)
 
 	if _, err = ir.reader.ReadAt(contentBuf, 0); err != nil {
-		err = errors.Wrapf(err, "reading content for hashing")
+		err = wrapTruncated(err, "reading content for hashing")
 		return err
 	}
 
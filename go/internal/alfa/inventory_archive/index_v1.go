@@ -231,19 +231,10 @@ type IndexReaderV1 struct {
 func NewIndexReaderV1(
 	r io.ReaderAt,
 	totalSize int64,
-	hashFormatId string,
 ) (ir *IndexReaderV1, err error) {
-	hashSize, err := hashSizeForFormat(hashFormatId)
-	if err != nil {
-		err = errors.Wrap(err)
-		return nil, err
-	}
-
 	ir = &IndexReaderV1{
-		reader:       r,
-		totalSize:    totalSize,
-		hashFormatId: hashFormatId,
-		hashSize:     hashSize,
+		reader:    r,
+		totalSize: totalSize,
 	}
 
 	if err = ir.readHeader(); err != nil {
@@ -264,7 +255,7 @@ func (ir *IndexReaderV1) readHeader() (err error) {
 	magic := make([]byte, 4)
 
 	if _, err = ir.reader.ReadAt(magic, 0); err != nil {
-		err = errors.Wrapf(err, "reading magic")
+		err = wrapTruncated(err, "reading magic")
 		return err
 	}
 
@@ -281,7 +272,7 @@ func (ir *IndexReaderV1) readHeader() (err error) {
 	versionBuf := make([]byte, 2)
 
 	if _, err = ir.reader.ReadAt(versionBuf, 4); err != nil {
-		err = errors.Wrapf(err, "reading version")
+		err = wrapTruncated(err, "reading version")
 		return err
 	}
 
@@ -300,7 +291,7 @@ func (ir *IndexReaderV1) readHeader() (err error) {
 	hashFormatIdLenBuf := make([]byte, 1)
 
 	if _, err = ir.reader.ReadAt(hashFormatIdLenBuf, 6); err != nil {
-		err = errors.Wrapf(err, "reading hash format id length")
+		err = wrapTruncated(err, "reading hash format id length")
 		return err
 	}
 
@@ -310,18 +301,16 @@ func (ir *IndexReaderV1) readHeader() (err error) {
 	hashFormatIdBytes := make([]byte, hashFormatIdLen)
 
 	if _, err = ir.reader.ReadAt(hashFormatIdBytes, 7); err != nil {
-		err = errors.Wrapf(err, "reading hash format id")
+		err = wrapTruncated(err, "reading hash format id")
 		return err
 	}
 
-	fileHashFormatId := string(hashFormatIdBytes)
+	// the file names its own hash format, so archives written before a repo
+	// changed its default hash stay readable
+	ir.hashFormatId = string(hashFormatIdBytes)
 
-	if fileHashFormatId != ir.hashFormatId {
-		err = errors.Errorf(
-			"hash format id mismatch: file has %q, expected %q",
-			fileHashFormatId,
-			ir.hashFormatId,
-		)
+	if ir.hashSize, err = hashSizeForFormat(ir.hashFormatId); err != nil {
+		err = errors.Wrap(err)
 		return err
 	}
 
@@ -333,7 +322,7 @@ func (ir *IndexReaderV1) readHeader() (err error) {
 		entryCountBuf,
 		entryCountOffset,
 	); err != nil {
-		err = errors.Wrapf(err, "reading entry count")
+		err = wrapTruncated(err, "reading entry count")
 		return err
 	}
 
@@ -347,7 +336,7 @@ func (ir *IndexReaderV1) readFanOut() (err error) {
 	fanOutBuf := make([]byte, 256*8)
 
 	if _, err = ir.reader.ReadAt(fanOutBuf, headerSize); err != nil {
-		err = errors.Wrapf(err, "reading fan-out table")
+		err = wrapTruncated(err, "reading fan-out table")
 		return err
 	}
 
@@ -359,6 +348,18 @@ func (ir *IndexReaderV1) readFanOut() (err error) {
 
 	ir.entriesStart = headerSize + 256*8
 
+	// entries are followed by the checksum
+	if err = checkEntryCount(
+		"index",
+		ir.entryCount,
+		ir.entrySize(),
+		ir.entriesStart,
+		int64(ir.hashSize),
+		ir.totalSize,
+	); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -396,7 +397,7 @@ func (ir *IndexReaderV1) readEntryAt(index uint64) (
 	entryBuf := make([]byte, ir.entrySize())
 
 	if _, err = ir.reader.ReadAt(entryBuf, offset); err != nil {
-		err = errors.Wrapf(err, "reading entry %d", index)
+		err = wrapTruncated(err, "reading entry %d", index)
 		return entry, err
 	}
 
@@ -448,6 +449,10 @@ func (ir *IndexReaderV1) LookupHash(hash []byte) (
 
 	hi := ir.fanOut[firstByte]
 
+	if err = checkFanOutCount(hi, ir.entryCount); err != nil {
+		return 0, 0, 0, 0, false, err
+	}
+
 	if lo >= hi {
 		return 0, 0, 0, 0, false, nil
 	}
@@ -482,7 +487,7 @@ func (ir *IndexReaderV1) ReadAllEntries() (entries []IndexEntryV1, err error) {
 	for i := range ir.entryCount {
 		entries[i], err = ir.readEntryAt(i)
 		if err != nil {
-			err = errors.Wrapf(err, "reading entry %d", i)
+			err = wrapTruncated(err, "reading entry %d", i)
 			return nil, err
 		}
 	}
@@ -509,7 +514,7 @@ func (ir *IndexReaderV1) Validate() (err error) {
 		storedChecksum,
 		checksumOffset,
 	); err != nil {
-		err = errors.Wrapf(err, "reading stored checksum")
+		err = wrapTruncated(err, "reading stored checksum")
 		return err
 	}
 
@@ -523,7 +528,7 @@ func (ir *IndexReaderV1) Validate() (err error) {
 	contentBuf := make([]byte, checksumOffset)
 
 	if _, err = ir.reader.ReadAt(contentBuf, 0); err != nil {
-		err = errors.Wrapf(err, "reading content for hashing")
+		err = wrapTruncated(err, "reading content for hashing")
 		return err
 	}
 
@@ -0,0 +1,101 @@
+package inventory_archive
+
+import (
+	"bytes"
+	"slices"
+
+	"code.linenisgreat.com/dodder/go/lib/delta/collections"
+)
+
+// indexLookupPageEntries is how many entries LookupHashes reads per ReadAt.
+const indexLookupPageEntries = 512
+
+// LookupHashes reports which of hashes are in the index, as a bitset of their
+// positions in hashes. Rather than a binary search per hash, it sorts the
+// hashes and merges them against the sorted entries in one forward pass,
+// reading entries a page at a time and skipping fan-out buckets that no hash
+// falls in, so that checking thousands of ids reads each page once.
+func (ir *IndexReaderV1) LookupHashes(
+	hashes [][]byte,
+) (found collections.Bitset, err error) {
+	found = collections.MakeBitset(len(hashes))
+
+	if ir.entryCount == 0 {
+		return found, nil
+	}
+
+	order := make([]int, len(hashes))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortFunc(order, func(a, b int) int {
+		return bytes.Compare(hashes[a], hashes[b])
+	})
+
+	entrySize := uint64(ir.entrySize())
+	page := make([]byte, indexLookupPageEntries*entrySize)
+
+	// entries [pageStart, pageEnd) are in page
+	var pageStart, pageEnd uint64
+
+	// the next entry to compare; hashes are visited in order, so entries
+	// before it sort before every remaining hash
+	var next uint64
+
+	for _, position := range order {
+		hash := hashes[position]
+
+		if len(hash) != ir.hashSize {
+			continue
+		}
+
+		firstByte := hash[0]
+
+		var lo uint64
+
+		if firstByte > 0 {
+			lo = ir.fanOut[firstByte-1]
+		}
+
+		hi := ir.fanOut[firstByte]
+
+		if err = checkFanOutCount(hi, ir.entryCount); err != nil {
+			return found, err
+		}
+
+		next = max(next, lo)
+
+		for next < hi {
+			if next < pageStart || next >= pageEnd {
+				count := min(indexLookupPageEntries, ir.entryCount-next)
+
+				if _, err = ir.reader.ReadAt(
+					page[:count*entrySize],
+					ir.entriesStart+int64(next*entrySize),
+				); err != nil {
+					err = wrapTruncated(err, "reading entries from %d", next)
+					return found, err
+				}
+
+				pageStart, pageEnd = next, next+count
+			}
+
+			offset := (next - pageStart) * entrySize
+			cmp := bytes.Compare(hash, page[offset:offset+uint64(ir.hashSize)])
+
+			if cmp == 0 {
+				// next stays, as the hash may be queried more than once
+				found.Add(position)
+				break
+			} else if cmp < 0 {
+				break
+			}
+
+			next++
+		}
+	}
+
+	return found, nil
+}
@@ -61,7 +61,6 @@ func TestIndexV1RoundTrip(t *testing.T) {
 	reader, err := NewIndexReaderV1(
 		bytes.NewReader(data),
 		int64(len(data)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewIndexReaderV1: %v", err)
@@ -153,7 +152,6 @@ func TestIndexV1Lookup(t *testing.T) {
 	reader, err := NewIndexReaderV1(
 		bytes.NewReader(data),
 		int64(len(data)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewIndexReaderV1: %v", err)
@@ -236,7 +234,6 @@ func TestIndexV1FanOut(t *testing.T) {
 	reader, err := NewIndexReaderV1(
 		bytes.NewReader(data),
 		int64(len(data)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewIndexReaderV1: %v", err)
@@ -296,7 +293,6 @@ func TestIndexV1Empty(t *testing.T) {
 	reader, err := NewIndexReaderV1(
 		bytes.NewReader(data),
 		int64(len(data)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewIndexReaderV1: %v", err)
@@ -343,7 +339,6 @@ func TestIndexV1ValidateChecksum(t *testing.T) {
 	reader, err := NewIndexReaderV1(
 		bytes.NewReader(data),
 		int64(len(data)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewIndexReaderV1: %v", err)
@@ -362,7 +357,6 @@ func TestIndexV1ValidateChecksum(t *testing.T) {
 	reader, err = NewIndexReaderV1(
 		bytes.NewReader(corrupted),
 		int64(len(corrupted)),
-		hashFormatId,
 	)
 	if err != nil {
 		t.Fatalf("NewIndexReaderV1 on corrupted data: %v", err)
@@ -388,3 +382,144 @@ func TestIndexV1WriterRejectsUnsortedEntries(t *testing.T) {
 		t.Fatal("WriteIndexV1 should reject unsorted entries")
 	}
 }
+
+func TestIndexV1ReaderUsesFileHashFormat(t *testing.T) {
+	var buf bytes.Buffer
+
+	if _, err := WriteIndexV1(
+		&buf,
+		"blake2b256",
+		makeTestIndexV1Entries(3),
+	); err != nil {
+		t.Fatalf("WriteIndexV1: %v", err)
+	}
+
+	data := buf.Bytes()
+
+	reader, err := NewIndexReaderV1(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewIndexReaderV1: %v", err)
+	}
+
+	if reader.HashFormatId() != "blake2b256" {
+		t.Errorf("expected blake2b256, got %q", reader.HashFormatId())
+	}
+
+	if err := reader.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func makeTestIndexV1Reader(
+	tb testing.TB,
+	entries []IndexEntryV1,
+) *IndexReaderV1 {
+	tb.Helper()
+
+	var buf bytes.Buffer
+
+	if _, err := WriteIndexV1(&buf, "sha256", entries); err != nil {
+		tb.Fatalf("WriteIndexV1: %v", err)
+	}
+
+	data := buf.Bytes()
+
+	reader, err := NewIndexReaderV1(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		tb.Fatalf("NewIndexReaderV1: %v", err)
+	}
+
+	return reader
+}
+
+// makeTestIndexV1Queries returns the hashes of every other entry, in reverse,
+// interleaved with hashes that are not in the index.
+func makeTestIndexV1Queries(entries []IndexEntryV1) (queries [][]byte) {
+	for i := len(entries) - 1; i >= 0; i -= 2 {
+		missing := sha256.Sum256([]byte(fmt.Sprintf("missing-%04d", i)))
+		queries = append(queries, entries[i].Hash, missing[:])
+	}
+
+	return queries
+}
+
+func TestIndexV1LookupHashes(t *testing.T) {
+	entries := makeTestIndexV1Entries(2000)
+	reader := makeTestIndexV1Reader(t, entries)
+
+	queries := makeTestIndexV1Queries(entries)
+	queries = append(queries, entries[0].Hash, entries[0].Hash, []byte{0x01})
+
+	found, err := reader.LookupHashes(queries)
+	if err != nil {
+		t.Fatalf("LookupHashes: %v", err)
+	}
+
+	for i, query := range queries {
+		var expected bool
+
+		if len(query) == sha256.Size {
+			_, _, _, _, expected, err = reader.LookupHash(query)
+			if err != nil {
+				t.Fatalf("LookupHash %d: %v", i, err)
+			}
+		}
+
+		if found.Get(i) != expected {
+			t.Errorf("query %d (%x): expected found %t", i, query, expected)
+		}
+	}
+
+	if expected := len(entries)/2 + 2; found.CountOn() != expected {
+		t.Errorf("expected %d hashes found, got %d", expected, found.CountOn())
+	}
+}
+
+func TestIndexV1LookupHashesEmpty(t *testing.T) {
+	reader := makeTestIndexV1Reader(t, nil)
+	missing := sha256.Sum256([]byte("missing"))
+
+	found, err := reader.LookupHashes([][]byte{missing[:]})
+	if err != nil {
+		t.Fatalf("LookupHashes: %v", err)
+	}
+
+	if found.CountOn() != 0 {
+		t.Errorf("expected nothing found in an empty index")
+	}
+}
+
+func benchmarkIndexV1Lookups(b *testing.B, batched bool) {
+	entries := makeTestIndexV1Entries(50000)
+	reader := makeTestIndexV1Reader(b, entries)
+	queries := makeTestIndexV1Queries(entries)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		if batched {
+			if _, err := reader.LookupHashes(queries); err != nil {
+				b.Fatalf("LookupHashes: %v", err)
+			}
+
+			continue
+		}
+
+		for _, query := range queries {
+			if _, _, _, _, _, err := reader.LookupHash(query); err != nil {
+				b.Fatalf("LookupHash: %v", err)
+			}
+		}
+	}
+}
+
+// BenchmarkIndexV1LookupHash and BenchmarkIndexV1LookupHashes compare bulk
+// membership tests of 50000 hashes against a 50000 entry index.
+func BenchmarkIndexV1LookupHash(b *testing.B) {
+	benchmarkIndexV1Lookups(b, false)
+}
+
+func BenchmarkIndexV1LookupHashes(b *testing.B) {
+	benchmarkIndexV1Lookups(b, true)
+}
@@ -0,0 +1,321 @@
+package inventory_archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const (
+	ManifestFileMagic            = "MIAM"
+	ManifestFileVersionV0 uint16 = 0
+	ManifestFileVersionV1 uint16 = 1
+	ManifestFileVersion          = ManifestFileVersionV1
+	ManifestFileName             = "MANIFEST"
+)
+
+// ArchiveTier is where an archive's data file lives.
+type ArchiveTier byte
+
+const (
+	// ArchiveTierHot archives are in the store's `archives` directory.
+	ArchiveTierHot = ArchiveTier(iota)
+	// ArchiveTierCold archives were demoted to the store's cold path.
+	ArchiveTierCold
+)
+
+// ManifestArchive records one live archive: the checksum naming its files,
+// the version of its data file, how many entries it holds, and the tier its
+// data file lives in.
+type ManifestArchive struct {
+	Checksum        []byte
+	DataFileVersion uint16
+	EntryCount      uint64
+	Tier            ArchiveTier
+}
+
+// Manifest lists the live archives of a store. Generation increases with
+// every change to the list, so that readers can tell whether another process
+// changed the store since they last looked.
+type Manifest struct {
+	Generation uint64
+	Archives   []ManifestArchive
+}
+
+// WriteManifest writes a manifest:
+//
+//	magic              4 bytes
+//	version            2 bytes
+//	hash_format_id_len 1 byte
+//	hash_format_id     variable
+//	generation         8 bytes
+//	archive_count      8 bytes
+//	archives           archive_count * (checksum, data_file_version 2 bytes,
+//	                   entry_count 8 bytes, tier 1 byte)
+//	checksum           hash_size bytes, over everything before it
+//
+// Version 0 manifests, which ReadManifest still reads, have no tier byte:
+// all their archives are hot.
+func WriteManifest(
+	w io.Writer,
+	hashFormatId string,
+	manifest Manifest,
+) (checksum []byte, err error) {
+	hasher, err := newHashForFormat(hashFormatId)
+	if err != nil {
+		err = errors.Wrap(err)
+		return nil, err
+	}
+
+	hashSize, err := hashSizeForFormat(hashFormatId)
+	if err != nil {
+		err = errors.Wrap(err)
+		return nil, err
+	}
+
+	hashFormatIdBytes := []byte(hashFormatId)
+
+	if len(hashFormatIdBytes) > 255 {
+		err = errors.Errorf(
+			"hash format id too long: %d bytes",
+			len(hashFormatIdBytes),
+		)
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(ManifestFileMagic)
+	binary.Write(&buf, binary.BigEndian, ManifestFileVersion)
+	buf.WriteByte(byte(len(hashFormatIdBytes)))
+	buf.Write(hashFormatIdBytes)
+	binary.Write(&buf, binary.BigEndian, manifest.Generation)
+	binary.Write(&buf, binary.BigEndian, uint64(len(manifest.Archives)))
+
+	for i, archive := range manifest.Archives {
+		if len(archive.Checksum) != hashSize {
+			err = errors.Errorf(
+				"archive %d: checksum length %d != expected %d",
+				i,
+				len(archive.Checksum),
+				hashSize,
+			)
+			return nil, err
+		}
+
+		buf.Write(archive.Checksum)
+		binary.Write(&buf, binary.BigEndian, archive.DataFileVersion)
+		binary.Write(&buf, binary.BigEndian, archive.EntryCount)
+		buf.WriteByte(byte(archive.Tier))
+	}
+
+	hasher.Write(buf.Bytes())
+	checksum = hasher.Sum(nil)
+	buf.Write(checksum)
+
+	if _, err = w.Write(buf.Bytes()); err != nil {
+		err = errors.Wrap(err)
+		return nil, err
+	}
+
+	return checksum, nil
+}
+
+// ReadManifest reads and verifies a manifest written by WriteManifest, in
+// the hash format named in its header.
+func ReadManifest(r io.Reader) (manifest Manifest, err error) {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		err = errors.Wrapf(err, "reading manifest")
+		return manifest, err
+	}
+
+	hashFormatId, err := peekManifestHashFormatId(contents)
+	if err != nil {
+		return manifest, err
+	}
+
+	hashSize, err := hashSizeForFormat(hashFormatId)
+	if err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	if len(contents) < hashSize {
+		err = errors.Errorf("manifest too small: %d bytes", len(contents))
+		return manifest, err
+	}
+
+	body := contents[:len(contents)-hashSize]
+	storedChecksum := contents[len(contents)-hashSize:]
+
+	hasher, err := newHashForFormat(hashFormatId)
+	if err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	hasher.Write(body)
+
+	if computed := hasher.Sum(nil); !bytes.Equal(computed, storedChecksum) {
+		err = errors.Errorf(
+			"manifest checksum mismatch: stored %x, computed %x",
+			storedChecksum,
+			computed,
+		)
+		return manifest, err
+	}
+
+	reader := bytes.NewReader(body)
+
+	// magic: 4 bytes
+	magic := make([]byte, 4)
+
+	if _, err = io.ReadFull(reader, magic); err != nil {
+		err = wrapTruncated(err, "reading magic")
+		return manifest, err
+	}
+
+	if string(magic) != ManifestFileMagic {
+		err = errors.Errorf(
+			"invalid magic: got %q, want %q",
+			string(magic),
+			ManifestFileMagic,
+		)
+		return manifest, err
+	}
+
+	// version: 2 bytes
+	var version uint16
+
+	if err = binary.Read(reader, binary.BigEndian, &version); err != nil {
+		err = wrapTruncated(err, "reading version")
+		return manifest, err
+	}
+
+	if version != ManifestFileVersionV0 && version != ManifestFileVersionV1 {
+		err = errors.Errorf(
+			"unsupported version: got %d, want %d",
+			version,
+			ManifestFileVersion,
+		)
+		return manifest, err
+	}
+
+	// hash_format_id_len + hash_format_id
+	hashFormatIdLen, err := reader.ReadByte()
+	if err != nil {
+		err = wrapTruncated(err, "reading hash format id length")
+		return manifest, err
+	}
+
+	hashFormatIdBytes := make([]byte, hashFormatIdLen)
+
+	if _, err = io.ReadFull(reader, hashFormatIdBytes); err != nil {
+		err = wrapTruncated(err, "reading hash format id")
+		return manifest, err
+	}
+
+	// generation: 8 bytes
+	if err = binary.Read(
+		reader,
+		binary.BigEndian,
+		&manifest.Generation,
+	); err != nil {
+		err = wrapTruncated(err, "reading generation")
+		return manifest, err
+	}
+
+	// archive_count: 8 bytes
+	var archiveCount uint64
+
+	if err = binary.Read(reader, binary.BigEndian, &archiveCount); err != nil {
+		err = wrapTruncated(err, "reading archive count")
+		return manifest, err
+	}
+
+	archiveSize := uint64(hashSize + 2 + 8)
+
+	if version >= ManifestFileVersionV1 {
+		archiveSize++
+	}
+
+	if uint64(reader.Len())%archiveSize != 0 ||
+		archiveCount != uint64(reader.Len())/archiveSize {
+		err = errors.Errorf(
+			"manifest archive count %d does not match its size",
+			archiveCount,
+		)
+		return manifest, err
+	}
+
+	manifest.Archives = make([]ManifestArchive, archiveCount)
+
+	for i := range manifest.Archives {
+		archive := &manifest.Archives[i]
+		archive.Checksum = make([]byte, hashSize)
+
+		if _, err = io.ReadFull(reader, archive.Checksum); err != nil {
+			err = wrapTruncated(err, "reading archive %d checksum", i)
+			return manifest, err
+		}
+
+		if err = binary.Read(
+			reader,
+			binary.BigEndian,
+			&archive.DataFileVersion,
+		); err != nil {
+			err = wrapTruncated(err, "reading archive %d data file version", i)
+			return manifest, err
+		}
+
+		if err = binary.Read(
+			reader,
+			binary.BigEndian,
+			&archive.EntryCount,
+		); err != nil {
+			err = wrapTruncated(err, "reading archive %d entry count", i)
+			return manifest, err
+		}
+
+		if version < ManifestFileVersionV1 {
+			continue
+		}
+
+		var tier byte
+
+		if tier, err = reader.ReadByte(); err != nil {
+			err = wrapTruncated(err, "reading archive %d tier", i)
+			return manifest, err
+		}
+
+		archive.Tier = ArchiveTier(tier)
+	}
+
+	return manifest, nil
+}
+
+// peekManifestHashFormatId reads the hash format id from a manifest's header,
+// which is needed before its checksum can be verified.
+func peekManifestHashFormatId(contents []byte) (hashFormatId string, err error) {
+	// magic, version and hash_format_id_len
+	const fixedHeaderSize = 4 + 2 + 1
+
+	if len(contents) < fixedHeaderSize {
+		err = errors.Wrapf(io.ErrUnexpectedEOF, "reading manifest header")
+		return hashFormatId, err
+	}
+
+	hashFormatIdEnd := fixedHeaderSize + int(contents[fixedHeaderSize-1])
+
+	if len(contents) < hashFormatIdEnd {
+		err = errors.Wrapf(io.ErrUnexpectedEOF, "reading hash format id")
+		return hashFormatId, err
+	}
+
+	hashFormatId = string(contents[fixedHeaderSize:hashFormatIdEnd])
+
+	return hashFormatId, err
+}
@@ -0,0 +1,138 @@
+//go:build test && debug
+
+package inventory_archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	manifest := Manifest{
+		Generation: 7,
+		Archives: []ManifestArchive{
+			{
+				Checksum:        sha256Hash([]byte("archive one")),
+				DataFileVersion: DataFileVersionV1,
+				EntryCount:      3,
+			},
+			{
+				Checksum:        sha256Hash([]byte("archive two")),
+				DataFileVersion: DataFileVersionV2,
+				EntryCount:      12,
+				Tier:            ArchiveTierCold,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	if _, err := WriteManifest(&buf, "sha256", manifest); err != nil {
+		t.Fatalf("WriteManifest: %v", err)
+	}
+
+	read, err := ReadManifest(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+
+	if read.Generation != manifest.Generation {
+		t.Errorf(
+			"generation: expected %d, got %d",
+			manifest.Generation,
+			read.Generation,
+		)
+	}
+
+	if len(read.Archives) != len(manifest.Archives) {
+		t.Fatalf(
+			"expected %d archives, got %d",
+			len(manifest.Archives),
+			len(read.Archives),
+		)
+	}
+
+	for i, archive := range manifest.Archives {
+		got := read.Archives[i]
+
+		if !bytes.Equal(got.Checksum, archive.Checksum) ||
+			got.DataFileVersion != archive.DataFileVersion ||
+			got.EntryCount != archive.EntryCount ||
+			got.Tier != archive.Tier {
+			t.Errorf("archive %d: expected %+v, got %+v", i, archive, got)
+		}
+	}
+}
+
+func TestManifestDetectsCorruption(t *testing.T) {
+	var buf bytes.Buffer
+
+	if _, err := WriteManifest(&buf, "sha256", Manifest{Generation: 1}); err != nil {
+		t.Fatalf("WriteManifest: %v", err)
+	}
+
+	corrupted := bytes.Clone(buf.Bytes())
+	corrupted[len(ManifestFileMagic)+2+1+len("sha256")] ^= 0xff
+
+	if _, err := ReadManifest(bytes.NewReader(corrupted)); err == nil {
+		t.Fatal("expected a checksum error for a corrupted manifest")
+	}
+}
+
+func TestManifestReadsItsOwnHashFormat(t *testing.T) {
+	var buf bytes.Buffer
+
+	manifest := Manifest{
+		Generation: 2,
+		Archives: []ManifestArchive{
+			{
+				Checksum:        bytes.Repeat([]byte{0xab}, 64),
+				DataFileVersion: DataFileVersionV2,
+				EntryCount:      3,
+			},
+		},
+	}
+
+	if _, err := WriteManifest(&buf, "sha512", manifest); err != nil {
+		t.Fatalf("WriteManifest: %v", err)
+	}
+
+	read, err := ReadManifest(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+
+	if read.Generation != 2 || len(read.Archives) != 1 ||
+		!bytes.Equal(read.Archives[0].Checksum, manifest.Archives[0].Checksum) {
+		t.Errorf("expected %+v, got %+v", manifest, read)
+	}
+}
+
+func TestManifestReadsVersionZero(t *testing.T) {
+	checksum := sha256Hash([]byte("archive one"))
+
+	var body bytes.Buffer
+
+	body.WriteString(ManifestFileMagic)
+	binary.Write(&body, binary.BigEndian, ManifestFileVersionV0)
+	body.WriteByte(byte(len("sha256")))
+	body.WriteString("sha256")
+	binary.Write(&body, binary.BigEndian, uint64(3))
+	binary.Write(&body, binary.BigEndian, uint64(1))
+	body.Write(checksum)
+	binary.Write(&body, binary.BigEndian, DataFileVersionV1)
+	binary.Write(&body, binary.BigEndian, uint64(5))
+	body.Write(sha256Hash(body.Bytes()))
+
+	read, err := ReadManifest(bytes.NewReader(body.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+
+	if read.Generation != 3 || len(read.Archives) != 1 ||
+		read.Archives[0].EntryCount != 5 ||
+		read.Archives[0].Tier != ArchiveTierHot {
+		t.Errorf("expected one hot archive at generation 3, got %+v", read)
+	}
+}
@@ -0,0 +1,176 @@
+package inventory_archive
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ohio"
+)
+
+// payloads up to this size are spooled in memory
+const payloadSpoolMemoryLimit = 4 * 1024 * 1024
+
+// payloadSpool holds an entry payload while it is being produced, since an
+// entry's stored size precedes its payload. Small payloads stay in memory and
+// larger ones spill to a temp file, so that writing an entry never needs the
+// whole blob in memory.
+type payloadSpool struct {
+	dir    string
+	memory bytes.Buffer
+	file   *os.File
+	size   uint64
+}
+
+func (spool *payloadSpool) Write(p []byte) (n int, err error) {
+	if spool.file == nil &&
+		uint64(spool.memory.Len()+len(p)) > payloadSpoolMemoryLimit {
+		if spool.file, err = os.CreateTemp(spool.dir, "entry-*.tmp"); err != nil {
+			err = errors.Wrapf(err, "creating entry spool in %q", spool.dir)
+			return n, err
+		}
+
+		if _, err = spool.memory.WriteTo(spool.file); err != nil {
+			err = errors.Wrap(err)
+			return n, err
+		}
+	}
+
+	if spool.file == nil {
+		n, err = spool.memory.Write(p)
+	} else {
+		n, err = spool.file.Write(p)
+	}
+
+	spool.size += uint64(n)
+
+	return n, err
+}
+
+func (spool *payloadSpool) Size() uint64 {
+	return spool.size
+}
+
+func (spool *payloadSpool) Reader() (reader io.Reader, err error) {
+	if spool.file == nil {
+		reader = bytes.NewReader(spool.memory.Bytes())
+		return reader, err
+	}
+
+	if _, err = spool.file.Seek(0, io.SeekStart); err != nil {
+		err = errors.Wrap(err)
+		return reader, err
+	}
+
+	reader = spool.file
+
+	return reader, err
+}
+
+func (spool *payloadSpool) Close() (err error) {
+	if spool.file == nil {
+		return err
+	}
+
+	path := spool.file.Name()
+
+	if err = spool.file.Close(); err != nil {
+		os.Remove(path)
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = os.Remove(path); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+type countingWriter struct {
+	writer io.Writer
+	count  uint64
+}
+
+func (writer *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = writer.writer.Write(p)
+	writer.count += uint64(n)
+	return n, err
+}
+
+// makeEncryptingSpool returns a new spool in dir and a writer that encrypts
+// into it, or writes to it as is without encryption. The writer must be closed
+// before the spool is read.
+func makeEncryptingSpool(
+	dir string,
+	encryption interfaces.IOWrapper,
+) (spool *payloadSpool, writer io.WriteCloser, err error) {
+	spool = &payloadSpool{dir: dir}
+	writer = ohio.NopWriteCloser(spool)
+
+	if encryption == nil {
+		return spool, writer, err
+	}
+
+	if writer, err = encryption.WrapWriter(spool); err != nil {
+		err = errors.Wrap(err)
+		return spool, writer, err
+	}
+
+	return spool, writer, err
+}
+
+// stagePayload compresses and then encrypts everything read from reader into
+// a new spool in dir. It returns the number of bytes read and the compressed
+// size before encryption.
+func stagePayload(
+	dir string,
+	reader io.Reader,
+	compression interfaces.WriteWrapper,
+	encryption interfaces.IOWrapper,
+) (spool *payloadSpool, logicalSize, compressedSize uint64, err error) {
+	var encryptWriter io.WriteCloser
+
+	spool, encryptWriter, err = makeEncryptingSpool(dir, encryption)
+
+	defer func() {
+		if err != nil {
+			spool.Close()
+		}
+	}()
+
+	if err != nil {
+		err = errors.Wrap(err)
+		return spool, 0, 0, err
+	}
+
+	compressedCounter := &countingWriter{writer: encryptWriter}
+
+	compressWriter, err := compression.WrapWriter(compressedCounter)
+	if err != nil {
+		err = errors.Wrap(err)
+		return spool, 0, 0, err
+	}
+
+	var read int64
+
+	if read, err = io.Copy(compressWriter, reader); err != nil {
+		err = errors.Wrap(err)
+		return spool, 0, 0, err
+	}
+
+	if err = compressWriter.Close(); err != nil {
+		err = errors.Wrap(err)
+		return spool, 0, 0, err
+	}
+
+	if err = encryptWriter.Close(); err != nil {
+		err = errors.Wrap(err)
+		return spool, 0, 0, err
+	}
+
+	return spool, uint64(read), compressedCounter.count, err
+}
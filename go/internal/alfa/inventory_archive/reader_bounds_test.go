@@ -0,0 +1,199 @@
+//go:build test && debug
+
+package inventory_archive
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func writeTestCacheV1(t testing.TB) []byte {
+	var buf bytes.Buffer
+
+	if _, err := WriteCacheV1(
+		&buf,
+		"sha256",
+		makeTestCacheV1Entries(4),
+	); err != nil {
+		t.Fatalf("WriteCacheV1: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func writeTestIndexV1(t testing.TB) []byte {
+	var buf bytes.Buffer
+
+	if _, err := WriteIndexV1(
+		&buf,
+		"sha256",
+		makeTestIndexV1Entries(4),
+	); err != nil {
+		t.Fatalf("WriteIndexV1: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func writeTestDataV1(t testing.TB) []byte {
+	var buf bytes.Buffer
+
+	writer, err := NewDataWriterV1(
+		&buf,
+		"sha256",
+		compression_type.CompressionTypeNone,
+		0,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewDataWriterV1: %v", err)
+	}
+
+	for _, data := range []string{"first entry", "second entry"} {
+		if err := writer.WriteFullEntry(
+			sha256Hash([]byte(data)),
+			[]byte(data),
+		); err != nil {
+			t.Fatalf("WriteFullEntry: %v", err)
+		}
+	}
+
+	if _, _, err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// overwriteUint64 returns a copy of data with a big-endian value at offset.
+func overwriteUint64(data []byte, offset int, value uint64) []byte {
+	corrupted := bytes.Clone(data)
+	binary.BigEndian.PutUint64(corrupted[offset:], value)
+	return corrupted
+}
+
+// entryCountOffset is the offset of the entry count in cache and index
+// headers with a sha256 hash format id.
+const entryCountOffset = 4 + 2 + 1 + len("sha256")
+
+func TestCacheReaderV1RejectsOversizedEntryCount(t *testing.T) {
+	data := overwriteUint64(writeTestCacheV1(t), entryCountOffset, 1<<40)
+
+	_, err := NewCacheReaderV1(bytes.NewReader(data), int64(len(data)))
+
+	if !errors.Is(err, ErrCorrupt{}) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestIndexReaderV1RejectsOversizedEntryCount(t *testing.T) {
+	data := overwriteUint64(writeTestIndexV1(t), entryCountOffset, 1<<40)
+
+	_, err := NewIndexReaderV1(bytes.NewReader(data), int64(len(data)))
+
+	if !errors.Is(err, ErrCorrupt{}) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestIndexReaderV1LookupRejectsOversizedFanOut(t *testing.T) {
+	// the last fan-out count covers hashes starting with 0xff
+	lastFanOutOffset := entryCountOffset + 8 + 255*8
+	data := overwriteUint64(writeTestIndexV1(t), lastFanOutOffset, 1<<40)
+
+	reader, err := NewIndexReaderV1(
+		bytes.NewReader(data),
+		int64(len(data)),
+	)
+	if err != nil {
+		t.Fatalf("NewIndexReaderV1: %v", err)
+	}
+
+	hash := bytes.Repeat([]byte{0xff}, sha256.Size)
+
+	if _, _, _, _, _, err = reader.LookupHash(hash); !errors.Is(err, ErrCorrupt{}) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestDataReaderV1RejectsOversizedStoredSize(t *testing.T) {
+	data := writeTestDataV1(t)
+
+	reader, err := NewDataReaderV1(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("NewDataReaderV1: %v", err)
+	}
+
+	// hash, entry type, encoding and logical size precede the stored size
+	storedSizeOffset := int(reader.dataStart) + sha256.Size + 1 + 1 + 8
+	data = overwriteUint64(data, storedSizeOffset, 1<<40)
+
+	if reader, err = NewDataReaderV1(bytes.NewReader(data), nil); err != nil {
+		t.Fatalf("NewDataReaderV1: %v", err)
+	}
+
+	if _, err = reader.ReadAllEntries(); !errors.Is(err, ErrCorrupt{}) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func FuzzCacheReaderV1(f *testing.F) {
+	f.Add(writeTestCacheV1(f))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		reader, err := NewCacheReaderV1(
+			bytes.NewReader(data),
+			int64(len(data)),
+		)
+		if err != nil {
+			return
+		}
+
+		reader.ReadAllEntries()
+		reader.Validate()
+	})
+}
+
+func FuzzIndexReaderV1(f *testing.F) {
+	f.Add(writeTestIndexV1(f), []byte{0})
+	f.Add([]byte{}, []byte{0})
+
+	f.Fuzz(func(t *testing.T, data []byte, hash []byte) {
+		reader, err := NewIndexReaderV1(
+			bytes.NewReader(data),
+			int64(len(data)),
+		)
+		if err != nil {
+			return
+		}
+
+		reader.ReadAllEntries()
+		reader.Validate()
+
+		if len(hash) > 0 {
+			reader.LookupHash(hash)
+		}
+	})
+}
+
+func FuzzDataReaderV1(f *testing.F) {
+	f.Add(writeTestDataV1(f))
+	f.Add([]byte(DataFileMagic))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		reader, err := NewDataReaderV1(bytes.NewReader(data), nil)
+		if err != nil {
+			return
+		}
+
+		reader.ReadAllEntries()
+		reader.Validate()
+		reader.FooterIndex()
+	})
+}
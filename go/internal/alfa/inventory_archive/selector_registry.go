@@ -5,10 +5,11 @@ import (
 )
 
 type BaseSelectorParams struct {
-	Bands       int
-	RowsPerBand int
-	MinBlobSize uint64
-	MaxBlobSize uint64
+	Bands         int
+	RowsPerBand   int
+	MinBlobSize   uint64
+	MaxBlobSize   uint64
+	MinSimilarity float64
 }
 
 var baseSelectors = map[string]func(BaseSelectorParams) BaseSelector{}
@@ -48,4 +49,15 @@ func init() {
 			}
 		},
 	)
+
+	RegisterBaseSelector(
+		"similarity",
+		func(params BaseSelectorParams) BaseSelector {
+			return &SimilaritySelector{
+				MinBlobSize:   params.MinBlobSize,
+				MaxBlobSize:   params.MaxBlobSize,
+				MinSimilarity: params.MinSimilarity,
+			}
+		},
+	)
 }
@@ -9,6 +9,7 @@ type SignatureComputerParams struct {
 	AvgChunkSize int
 	MinChunkSize int
 	MaxChunkSize int
+	ShingleSize  int
 }
 
 var signatureComputers = map[string]func(SignatureComputerParams) SignatureComputer{}
@@ -48,4 +49,14 @@ func init() {
 			}
 		},
 	)
+
+	RegisterSignatureComputer(
+		"shingle-minhash",
+		func(params SignatureComputerParams) SignatureComputer {
+			return &ShingleMinHashComputer{
+				ShingleSize: params.ShingleSize,
+				K:           params.SignatureLen,
+			}
+		},
+	)
 }
@@ -0,0 +1,115 @@
+package inventory_archive
+
+import (
+	"bufio"
+	"io"
+	"math"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// shingleRollingBase is the multiplier of the polynomial rolling hash over
+// each shingle window.
+const shingleRollingBase = 0x100000001B3
+
+// ShingleMinHashComputer computes a one-permutation MinHash sketch over the
+// overlapping ShingleSize-byte windows of blob content. Each shingle hash
+// selects one of K bins and only the minimum per bin is kept, so the sketch
+// costs a single hash per byte regardless of K and streams the content
+// without buffering it. Bins that no shingle landed in hold math.MaxUint32,
+// which MinHashJaccard ignores when both sketches leave them empty.
+type ShingleMinHashComputer struct {
+	ShingleSize int
+	K           int
+}
+
+var _ SignatureComputer = &ShingleMinHashComputer{}
+
+func (c *ShingleMinHashComputer) SignatureLen() int {
+	return c.K
+}
+
+func (c *ShingleMinHashComputer) ComputeSignature(
+	content io.Reader,
+) ([]uint32, error) {
+	if c.K <= 0 || c.ShingleSize <= 0 {
+		return nil, errors.Errorf(
+			"invalid shingle sketch parameters: k=%d, shingle size=%d",
+			c.K,
+			c.ShingleSize,
+		)
+	}
+
+	sig := make([]uint32, c.K)
+	for i := range sig {
+		sig[i] = math.MaxUint32
+	}
+
+	// outFactor removes the byte leaving the window: base^ShingleSize.
+	outFactor := uint64(1)
+	for range c.ShingleSize {
+		outFactor *= shingleRollingBase
+	}
+
+	reader := bufio.NewReader(content)
+	window := make([]byte, c.ShingleSize)
+
+	var hash uint64
+	var count int
+
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		slot := count % c.ShingleSize
+		hash = hash*shingleRollingBase + uint64(b) + 1
+
+		if count >= c.ShingleSize {
+			hash -= (uint64(window[slot]) + 1) * outFactor
+		}
+
+		window[slot] = b
+		count++
+
+		if count >= c.ShingleSize {
+			c.addShingle(sig, hash)
+		}
+	}
+
+	// Content shorter than one shingle is sketched as a single shingle.
+	if count > 0 && count < c.ShingleSize {
+		c.addShingle(sig, hash)
+	}
+
+	return sig, nil
+}
+
+func (c *ShingleMinHashComputer) addShingle(sig []uint32, hash uint64) {
+	mixed := splitmix64(hash)
+	bin := int(mixed % uint64(c.K))
+	value := uint32(mixed >> 32)
+
+	// math.MaxUint32 marks an empty bin.
+	if value == math.MaxUint32 {
+		value--
+	}
+
+	if value < sig[bin] {
+		sig[bin] = value
+	}
+}
+
+// splitmix64 is the finalizer of the SplitMix64 generator, used to spread
+// rolling hash values uniformly across bins.
+func splitmix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
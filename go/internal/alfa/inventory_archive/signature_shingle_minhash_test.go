@@ -0,0 +1,98 @@
+//go:build test && debug
+
+package inventory_archive
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestShingleMinHashComputerSimilarBlobs(t *testing.T) {
+	computer := &ShingleMinHashComputer{ShingleSize: 8, K: 128}
+
+	original := make([]byte, 4096)
+	for i := range original {
+		original[i] = byte(i*i + i/7)
+	}
+
+	edited := bytes.Clone(original)
+	for i := 2000; i < 2100; i++ {
+		edited[i] = byte(i * 13)
+	}
+
+	sigA, err := computer.ComputeSignature(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigB, err := computer.ComputeSignature(bytes.NewReader(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sigA) != computer.SignatureLen() {
+		t.Fatalf("expected %d positions, got %d", computer.SignatureLen(), len(sigA))
+	}
+
+	if similarity := MinHashJaccard(sigA, sigB); similarity < 0.7 {
+		t.Errorf("similar blobs have low similarity: %.3f", similarity)
+	}
+}
+
+func TestShingleMinHashComputerDissimilarBlobs(t *testing.T) {
+	computer := &ShingleMinHashComputer{ShingleSize: 8, K: 128}
+
+	blobA := make([]byte, 4096)
+	blobB := make([]byte, 4096)
+
+	for i := range blobA {
+		blobA[i] = byte(i*i + i/7)
+		blobB[i] = byte(i*31 + i/3 + 128)
+	}
+
+	sigA, err := computer.ComputeSignature(bytes.NewReader(blobA))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigB, err := computer.ComputeSignature(bytes.NewReader(blobB))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if similarity := MinHashJaccard(sigA, sigB); similarity > 0.2 {
+		t.Errorf("dissimilar blobs have high similarity: %.3f", similarity)
+	}
+}
+
+func TestShingleMinHashComputerShortContent(t *testing.T) {
+	computer := &ShingleMinHashComputer{ShingleSize: 8, K: 16}
+
+	sig, err := computer.ComputeSignature(bytes.NewReader([]byte("abc")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filled := 0
+	for _, value := range sig {
+		if value != math.MaxUint32 {
+			filled++
+		}
+	}
+
+	if filled != 1 {
+		t.Errorf("expected one shingle for short content, got %d", filled)
+	}
+
+	empty, err := computer.ComputeSignature(bytes.NewReader(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range empty {
+		if value != math.MaxUint32 {
+			t.Fatal("expected an empty sketch for empty content")
+		}
+	}
+}
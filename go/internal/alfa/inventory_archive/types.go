@@ -39,13 +39,41 @@ const (
 	DataFileExtensionV1  = ".inventory_archive-v1"
 	IndexFileExtensionV1 = ".inventory_archive_index-v1"
 	CacheFileNameV1      = "index_cache-v1"
+	BloomFileExtensionV1 = ".inventory_archive_bloom-v1"
 
 	EntryTypeFull  byte = 0x00
 	EntryTypeDelta byte = 0x01
+	// a run of zeros written so that the next entry starts aligned (see
+	// DataWriterV1.SetEntryAlignment): an all-zero hash, the entry type, the
+	// padding length (4 bytes) and the padding. Readers skip it, and it is
+	// never indexed.
+	EntryTypePadding byte = 0x02
 
 	FlagHasDeltas         uint16 = 1 << 0
 	FlagReservedCrossArch uint16 = 1 << 1
 	FlagHasEncryptionV1   uint16 = 1 << 2
+	// the header is followed by the zstd level (1 byte) and the id of the
+	// dictionary that entries may be compressed with (4 bytes, 0 for none).
+	// A dictionary is embedded right after its id, as its stored length (4
+	// bytes) and the dictionary, encrypted like entry payloads, so that an
+	// archive is readable on its own
+	FlagHasCompressionParamsV1 uint16 = 1 << 3
+
+	// set on an entry's encoding byte when its payload was compressed with
+	// the archive's dictionary; the low bits hold the compression byte
+	EncodingFlagDictionary byte = 1 << 7
+)
+
+// dictionaries are trained to at most 64 KiB by default; this only bounds
+// what a corrupt header can make a reader allocate
+const dictionaryStoredSizeMax = 16 << 20
+
+const (
+	// v2 data files share the v1 header and entry layout, and end with the
+	// archive's v1 index followed by a trailer locating it, so an archive is
+	// a single self-contained file
+	DataFileVersionV2   uint16 = 2
+	DataFileExtensionV2        = ".inventory_archive-v2"
 )
 
 type DataEntry struct {
@@ -138,6 +166,16 @@ func ByteToCompression(
 	return ct, err
 }
 
+// EncodingToCompression splits a v1 entry encoding byte into its
+// compression type and whether the payload needs the archive's dictionary.
+func EncodingToCompression(
+	encoding byte,
+) (ct compression_type.CompressionType, usesDictionary bool, err error) {
+	usesDictionary = encoding&EncodingFlagDictionary != 0
+	ct, err = ByteToCompression(encoding &^ EncodingFlagDictionary)
+	return ct, usesDictionary, err
+}
+
 type hashConstructor func() hash.Hash
 
 var hashConstructors = map[string]hashConstructor{
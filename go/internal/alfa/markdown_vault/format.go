@@ -0,0 +1,107 @@
+package markdown_vault
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	keyObjectId = "dodder-object-id"
+	keyBlobId   = "dodder-blob-id"
+	keyTai      = "dodder-tai"
+)
+
+const (
+	// LayoutType puts notes in a directory per type, like `md/one-uno.md`.
+	LayoutType = "type"
+
+	// LayoutTag puts notes in nested directories following the tag hierarchy
+	// of their first tag, like `project/2021/one-uno.md` for
+	// `project-2021`, and notes without tags at the top.
+	LayoutTag = "tag"
+
+	// LayoutFlat puts all notes at the top.
+	LayoutFlat = "flat"
+)
+
+// Format writes note as front matter followed by its body, so that ParseNote
+// reads the same fields back. Fields that are empty are left out.
+func (note Note) Format() string {
+	var builder strings.Builder
+
+	builder.WriteString("---\n")
+
+	writeField := func(key, value string) {
+		if value != "" {
+			builder.WriteString(key + ": " + quote(value) + "\n")
+		}
+	}
+
+	writeField(keyObjectId, note.ObjectId)
+	writeField("title", note.Title)
+
+	if len(note.Tags) > 0 {
+		quoted := make([]string, len(note.Tags))
+
+		for i, tag := range note.Tags {
+			quoted[i] = quote(tag)
+		}
+
+		builder.WriteString("tags: [" + strings.Join(quoted, ", ") + "]\n")
+	}
+
+	writeField("type", note.Type)
+
+	if len(note.Aliases) > 0 {
+		builder.WriteString("aliases:\n")
+
+		for _, alias := range note.Aliases {
+			builder.WriteString("  - " + quote(alias) + "\n")
+		}
+	}
+
+	writeField(keyBlobId, note.BlobId)
+	writeField(keyTai, note.Tai)
+
+	builder.WriteString("---\n")
+	builder.WriteString(note.Body)
+
+	return builder.String()
+}
+
+// quote leaves values that read back as they are unquoted, and double-quotes
+// the rest.
+func quote(value string) string {
+	if value == unquote(value) &&
+		!strings.ContainsAny(value, ":#[]{},&*!|>'\"%@`") &&
+		strings.TrimSpace(value) == value {
+		return value
+	}
+
+	return strconv.Quote(value)
+}
+
+// ExportPath returns the slash-separated path to write a note with the given
+// object id to, in one of the layouts. The slash in zettel ids becomes a `-`
+// in the file name.
+func (note Note) ExportPath(layout, objectId string) string {
+	name := strings.ReplaceAll(objectId, "/", "-") + ".md"
+
+	switch layout {
+	case LayoutType:
+		if tipe := strings.TrimPrefix(note.Type, "!"); tipe != "" {
+			return path.Join(tipe, name)
+		}
+
+	case LayoutTag:
+		if len(note.Tags) > 0 {
+			return path.Join(
+				path.Join(strings.Split(note.Tags[0], "-")...),
+				name,
+			)
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,280 @@
+package markdown_vault
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// Note is a markdown file in a vault, split into its front matter fields and
+// its body.
+type Note struct {
+	// Path is slash-separated and relative to the vault.
+	Path string
+
+	// Title is the front matter `title`, or the file name without its
+	// extension.
+	Title   string
+	Tags    []string
+	Type    string
+	Aliases []string
+	Body    string
+
+	// ObjectId and BlobId are the front matter `dodder-object-id` and
+	// `dodder-blob-id`, set on notes exported from dodder so that importing
+	// them updates the same objects, keeping the blobs of notes exported
+	// without a body.
+	ObjectId string
+	BlobId   string
+
+	// Tai is written to exported notes for reference, and is not read back.
+	Tai string
+}
+
+// Name is the file name without its extension, which is what wiki-links
+// refer to.
+func (note Note) Name() string {
+	return strings.TrimSuffix(path.Base(note.Path), path.Ext(note.Path))
+}
+
+// Walk returns the slash-separated paths of the markdown files under root,
+// sorted. Hidden files and directories, like `.obsidian` and `.trash`, are
+// skipped.
+func Walk(root string) (paths []string, err error) {
+	if err = filepath.WalkDir(
+		root,
+		func(fullPath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			name := entry.Name()
+
+			if fullPath != root && strings.HasPrefix(name, ".") {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if entry.IsDir() || !strings.EqualFold(filepath.Ext(name), ".md") {
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, fullPath)
+			if err != nil {
+				return err
+			}
+
+			paths = append(paths, filepath.ToSlash(rel))
+
+			return nil
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return paths, err
+	}
+
+	slices.Sort(paths)
+
+	return paths, err
+}
+
+// ReadNote reads and parses the note at the slash-separated path relative to
+// root.
+func ReadNote(root, notePath string) (note Note, err error) {
+	var content []byte
+
+	if content, err = os.ReadFile(
+		filepath.Join(root, filepath.FromSlash(notePath)),
+	); err != nil {
+		err = errors.Wrap(err)
+		return note, err
+	}
+
+	note = ParseNote(notePath, string(content))
+
+	return note, err
+}
+
+// ParseNote splits content into its front matter and body. Front matter is
+// the YAML between `---` lines at the start of the file, of which only flat
+// keys with scalar or list values are read: `title`, `tags` (or `tag`),
+// `type`, `aliases` (or `alias`), `dodder-object-id`, and `dodder-blob-id`.
+// Content without front matter is all body.
+func ParseNote(notePath, content string) (note Note) {
+	note.Path = notePath
+	note.Title = note.Name()
+
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	note.Body = content
+
+	if !strings.HasPrefix(content, "---\n") {
+		return note
+	}
+
+	frontMatter, body, ok := strings.Cut(content[len("---\n"):], "\n---")
+
+	if !ok {
+		return note
+	}
+
+	// the closing line may only be followed by its newline
+	if rest, found := strings.CutPrefix(body, "\n"); found {
+		body = rest
+	} else if body != "" {
+		return note
+	}
+
+	note.Body = body
+
+	for key, values := range parseFrontMatter(frontMatter) {
+		switch key {
+		case "title":
+			if len(values) > 0 && values[0] != "" {
+				note.Title = values[0]
+			}
+
+		case "tags", "tag":
+			for _, value := range values {
+				// tags may also be a space- or comma-separated string
+				note.Tags = append(
+					note.Tags,
+					strings.FieldsFunc(value, func(r rune) bool {
+						return r == ' ' || r == ','
+					})...,
+				)
+			}
+
+		case "type":
+			if len(values) > 0 {
+				note.Type = values[0]
+			}
+
+		case "aliases", "alias":
+			note.Aliases = append(note.Aliases, values...)
+
+		case keyObjectId:
+			if len(values) > 0 {
+				note.ObjectId = values[0]
+			}
+
+		case keyBlobId:
+			if len(values) > 0 {
+				note.BlobId = values[0]
+			}
+		}
+	}
+
+	return note
+}
+
+// parseFrontMatter reads `key: value`, `key: [a, b]`, and `key:` followed by
+// `- item` lines, lowercasing keys and unquoting values.
+func parseFrontMatter(frontMatter string) map[string][]string {
+	fields := make(map[string][]string)
+	var listKey string
+
+	for line := range strings.SplitSeq(frontMatter, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && listKey != "" {
+			fields[listKey] = append(fields[listKey], unquote(item))
+			continue
+		}
+
+		listKey = ""
+
+		if line != strings.TrimLeft(line, " \t") {
+			// nested values are not supported
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch {
+		case value == "":
+			listKey = key
+			fields[key] = nil
+
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			var values []string
+
+			for item := range strings.SplitSeq(value[1:len(value)-1], ",") {
+				if item = unquote(item); item != "" {
+					values = append(values, item)
+				}
+			}
+
+			fields[key] = values
+
+		default:
+			fields[key] = []string{unquote(value)}
+		}
+	}
+
+	return fields
+}
+
+// unquote removes quotes, interpreting the escapes in double-quoted values.
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+
+	if len(value) < 2 ||
+		(value[0] != '"' && value[0] != '\'') ||
+		value[len(value)-1] != value[0] {
+		return value
+	}
+
+	if value[0] == '"' {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+	}
+
+	return value[1 : len(value)-1]
+}
+
+// NormalizeTag turns a vault tag into a dodder tag: lowercased, with its
+// leading `#` removed and nested tag separators, spaces, and dots turned into
+// `-`, so that `#Project/Active` becomes `project-active`. Other characters
+// dodder tags cannot have are dropped, and ok is false if nothing is left.
+func NormalizeTag(value string) (tag string, ok bool) {
+	value = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(value), "#"))
+
+	var builder strings.Builder
+
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			builder.WriteRune(r)
+
+		case r == '/', r == ' ', r == '.':
+			builder.WriteRune('-')
+		}
+	}
+
+	tag = strings.Trim(builder.String(), "-")
+	ok = tag != ""
+
+	return tag, ok
+}
@@ -0,0 +1,194 @@
+//go:build test && debug
+
+package markdown_vault
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseNote(t *testing.T) {
+	note := ParseNote(
+		"Projects/Garden Plan.md",
+		"---\r\n"+
+			"title: \"Spring garden\"\r\n"+
+			"tags: [Project/Active, \"#garden\"]\r\n"+
+			"aliases:\r\n"+
+			"  - garden\r\n"+
+			"  - 'veg plot'\r\n"+
+			"type: recipe\r\n"+
+			"nested:\r\n"+
+			"  key: value\r\n"+
+			"---\r\n"+
+			"body with [[Other]]\r\n",
+	)
+
+	if note.Name() != "Garden Plan" {
+		t.Errorf("expected the name Garden Plan, got %q", note.Name())
+	}
+
+	if note.Title != "Spring garden" {
+		t.Errorf("expected the front matter title, got %q", note.Title)
+	}
+
+	if expected := []string{"Project/Active", "#garden"}; !slices.Equal(
+		note.Tags,
+		expected,
+	) {
+		t.Errorf("expected tags %q, got %q", expected, note.Tags)
+	}
+
+	if expected := []string{"garden", "veg plot"}; !slices.Equal(
+		note.Aliases,
+		expected,
+	) {
+		t.Errorf("expected aliases %q, got %q", expected, note.Aliases)
+	}
+
+	if note.Type != "recipe" {
+		t.Errorf("expected type recipe, got %q", note.Type)
+	}
+
+	if note.Body != "body with [[Other]]\n" {
+		t.Errorf("expected only the body, got %q", note.Body)
+	}
+}
+
+func TestParseNoteWithoutFrontMatter(t *testing.T) {
+	note := ParseNote("Inbox.md", "just text\n---\nmore\n")
+
+	if note.Title != "Inbox" || note.Body != "just text\n---\nmore\n" {
+		t.Errorf("expected the whole file as body, got %+v", note)
+	}
+
+	note = ParseNote("Tagged.md", "---\ntags: one, two three\n---\n")
+
+	if expected := []string{"one", "two", "three"}; !slices.Equal(
+		note.Tags,
+		expected,
+	) || note.Body != "" {
+		t.Errorf("expected tags %q and no body, got %+v", expected, note)
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	for value, expected := range map[string]string{
+		"#Project/Active": "project-active",
+		"v1.2":            "v1-2",
+		"snake_case":      "snake_case",
+		"émoji🎉ok":        "mojiok",
+		"-leading":        "leading",
+	} {
+		if tag, ok := NormalizeTag(value); !ok || tag != expected {
+			t.Errorf("expected %q for %q, got %q, %t", expected, value, tag, ok)
+		}
+	}
+
+	if tag, ok := NormalizeTag("#🎉"); ok {
+		t.Errorf("expected no tag, got %q", tag)
+	}
+}
+
+func TestReplaceWikiLinks(t *testing.T) {
+	var resolver Resolver
+
+	resolver.Add(
+		Note{Path: "Projects/Garden Plan.md", Aliases: []string{"veg plot"}},
+		"one/uno",
+	)
+
+	resolver.Add(Note{Path: "Archive/Garden Plan.md"}, "two/dos")
+
+	body := "see [[garden plan]], [[Archive/Garden Plan|the old one]],\n" +
+		"[[veg plot#Beds]], ![[photo.png]] and [[Missing]]\n" +
+		"```\n[[Garden Plan]]\n```\n"
+
+	replaced, unresolved := ReplaceWikiLinks(body, resolver.Resolve)
+
+	expected := "see [one/uno garden plan], [two/dos the old one],\n" +
+		"[one/uno veg plot#Beds], ![[photo.png]] and [[Missing]]\n" +
+		"```\n[[Garden Plan]]\n```\n"
+
+	if replaced != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, replaced)
+	}
+
+	if !slices.Equal(unresolved, []string{"Missing"}) {
+		t.Errorf("expected only Missing to be unresolved, got %q", unresolved)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+
+	for _, path := range []string{
+		"b.md",
+		"dir/a.MD",
+		"dir/image.png",
+		".obsidian/workspace.md",
+		".hidden.md",
+	} {
+		fullPath := filepath.Join(root, path)
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(fullPath, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"b.md", "dir/a.MD"}; !slices.Equal(paths, expected) {
+		t.Errorf("expected %q, got %q", expected, paths)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	note := Note{
+		Path:     "md/one-uno.md",
+		Title:    `wow: "the first"`,
+		Tags:     []string{"tag-3", "tag-4"},
+		Type:     "md",
+		ObjectId: "one/uno",
+		BlobId:   "blake2b256-abc",
+		Tai:      "2021-01-01T00:00:00Z",
+		Body:     "last time\n",
+	}
+
+	parsed := ParseNote(note.Path, note.Format())
+
+	if parsed.ObjectId != note.ObjectId ||
+		parsed.BlobId != note.BlobId ||
+		parsed.Title != note.Title ||
+		parsed.Type != note.Type ||
+		parsed.Body != note.Body ||
+		!slices.Equal(parsed.Tags, note.Tags) {
+		t.Errorf("expected %+v to read back, got %+v", note, parsed)
+	}
+}
+
+func TestExportPath(t *testing.T) {
+	note := Note{Type: "!md", Tags: []string{"project-2021"}}
+
+	for layout, expected := range map[string]string{
+		LayoutType: "md/one-uno.md",
+		LayoutTag:  "project/2021/one-uno.md",
+		LayoutFlat: "one-uno.md",
+	} {
+		if actual := note.ExportPath(layout, "one/uno"); actual != expected {
+			t.Errorf("expected %q for layout %s, got %q", expected, layout, actual)
+		}
+	}
+
+	if actual := (Note{}).ExportPath(LayoutTag, "one/uno"); actual != "one-uno.md" {
+		t.Errorf("expected untagged notes at the top, got %q", actual)
+	}
+}
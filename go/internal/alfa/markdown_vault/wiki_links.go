@@ -0,0 +1,127 @@
+package markdown_vault
+
+import (
+	"path"
+	"strings"
+)
+
+// Resolver maps wiki-link targets to object ids. A note can be linked to by
+// its name, its path without the extension, or one of its aliases, ignoring
+// case. When names collide, the note added first keeps the name, and the
+// others can only be linked to by path.
+type Resolver struct {
+	objectIds map[string]string
+}
+
+func (resolver *Resolver) Add(note Note, objectId string) {
+	if resolver.objectIds == nil {
+		resolver.objectIds = make(map[string]string)
+	}
+
+	keys := append(
+		[]string{
+			strings.TrimSuffix(note.Path, path.Ext(note.Path)),
+			note.Name(),
+		},
+		note.Aliases...,
+	)
+
+	for _, key := range keys {
+		key = strings.ToLower(key)
+
+		if _, ok := resolver.objectIds[key]; !ok {
+			resolver.objectIds[key] = objectId
+		}
+	}
+}
+
+// Resolve returns the object id of the note target refers to. Headings and
+// block references after `#` are ignored, as is a `.md` extension.
+func (resolver *Resolver) Resolve(target string) (objectId string, ok bool) {
+	target, _, _ = strings.Cut(target, "#")
+	target = strings.TrimSpace(target)
+
+	if strings.EqualFold(path.Ext(target), ".md") {
+		target = target[:len(target)-len(".md")]
+	}
+
+	objectId, ok = resolver.objectIds[strings.ToLower(target)]
+
+	return objectId, ok
+}
+
+// ReplaceWikiLinks rewrites `[[target]]` and `[[target|text]]` into object
+// links like `[one/uno text]`, keeping the link's text, or its target when it
+// has none. Links that do not resolve, embeds like `![[image.png]]`, and links
+// in fenced code blocks are left as they are, and the targets that did not
+// resolve are returned.
+func ReplaceWikiLinks(
+	body string,
+	resolve func(target string) (objectId string, ok bool),
+) (replaced string, unresolved []string) {
+	var builder strings.Builder
+	var inFence bool
+
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") ||
+			strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+
+		if inFence {
+			builder.WriteString(line)
+			continue
+		}
+
+		for {
+			open := strings.Index(line, "[[")
+
+			if open < 0 {
+				break
+			}
+
+			closing := strings.Index(line[open+2:], "]]")
+
+			if closing < 0 {
+				break
+			}
+
+			closing += open + 2
+			link := line[open+2 : closing]
+			embed := open > 0 && line[open-1] == '!'
+
+			target, text, hasText := strings.Cut(link, "|")
+
+			if !hasText {
+				text = target
+			}
+
+			objectId, ok := "", false
+
+			if !embed {
+				objectId, ok = resolve(target)
+			}
+
+			if !ok {
+				if !embed {
+					unresolved = append(unresolved, target)
+				}
+
+				builder.WriteString(line[:closing+2])
+				line = line[closing+2:]
+
+				continue
+			}
+
+			builder.WriteString(line[:open])
+			builder.WriteString("[" + objectId + " " + strings.TrimSpace(text) + "]")
+			line = line[closing+2:]
+		}
+
+		builder.WriteString(line)
+	}
+
+	replaced = builder.String()
+
+	return replaced, unresolved
+}
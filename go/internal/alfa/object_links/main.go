@@ -0,0 +1,164 @@
+package object_links
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Link is an object id in brackets, like `[one/uno]` or `[!md]`. Anything
+// after the first space up to the closing bracket, like a description, is
+// not part of the id. Markdown links, whose closing bracket is followed by a
+// parenthesis, and checkboxes are not links.
+//
+// Columns are in UTF-16 code units, as positions are in the language server
+// protocol.
+type Link struct {
+	ObjectId string
+	Line     int
+
+	// Start is the column of the opening bracket and End the column after
+	// the closing one.
+	Start, End int
+}
+
+func (link Link) Contains(line, character int) bool {
+	return line == link.Line && character >= link.Start && character < link.End
+}
+
+// Find returns the links in text in order.
+func Find(text string) (links []Link) {
+	for lineNumber, line := range strings.Split(text, "\n") {
+		links = append(links, findInLine(lineNumber, line)...)
+	}
+
+	return links
+}
+
+func findInLine(lineNumber int, line string) (links []Link) {
+	offset := 0
+
+	for {
+		open := strings.IndexByte(line[offset:], '[')
+
+		if open < 0 {
+			return links
+		}
+
+		open += offset
+		closing := strings.IndexAny(line[open+1:], "[]")
+
+		if closing < 0 {
+			return links
+		}
+
+		closing += open + 1
+
+		if line[closing] == '[' {
+			offset = closing
+			continue
+		}
+
+		offset = closing + 1
+
+		if strings.HasPrefix(line[offset:], "(") {
+			continue
+		}
+
+		objectId, _, _ := strings.Cut(line[open+1:closing], " ")
+
+		if !isObjectId(objectId) {
+			continue
+		}
+
+		links = append(links, Link{
+			ObjectId: objectId,
+			Line:     lineNumber,
+			Start:    utf16Len(line[:open]),
+			End:      utf16Len(line[:closing+1]),
+		})
+	}
+}
+
+func isObjectId(value string) bool {
+	return value != "" && !strings.ContainsAny(value, "\t\"") &&
+		value != "x" && value != "X"
+}
+
+// At returns the link containing the position, if any.
+func At(text string, line, character int) (link Link, ok bool) {
+	lines := strings.Split(text, "\n")
+
+	if line < 0 || line >= len(lines) {
+		return link, ok
+	}
+
+	for _, link = range findInLine(line, lines[line]) {
+		if link.Contains(line, character) {
+			ok = true
+			return link, ok
+		}
+	}
+
+	return Link{}, ok
+}
+
+// CompletionPrefixAt returns the partial object id typed between an unclosed
+// bracket and the position, and the column it starts at, for completing it.
+func CompletionPrefixAt(
+	text string,
+	line, character int,
+) (prefix string, start int, ok bool) {
+	lines := strings.Split(text, "\n")
+
+	if line < 0 || line >= len(lines) {
+		return prefix, start, ok
+	}
+
+	before := lines[line][:byteOffset(lines[line], character)]
+	open := strings.LastIndexByte(before, '[')
+
+	if open < 0 {
+		return prefix, start, ok
+	}
+
+	prefix = before[open+1:]
+
+	if strings.ContainsAny(prefix, " \t]\"") {
+		return "", start, ok
+	}
+
+	start = utf16Len(before[:open+1])
+	ok = true
+
+	return prefix, start, ok
+}
+
+func utf16Len(value string) (length int) {
+	for _, r := range value {
+		if r >= 0x10000 {
+			length += 2
+		} else {
+			length++
+		}
+	}
+
+	return length
+}
+
+// byteOffset converts a UTF-16 column to a byte offset into line, clamping it
+// to the line's length.
+func byteOffset(line string, character int) (offset int) {
+	for offset < len(line) && character > 0 {
+		r, size := utf8.DecodeRuneInString(line[offset:])
+
+		if r >= 0x10000 {
+			character -= 2
+		} else {
+			character--
+		}
+
+		offset += size
+	}
+
+	return offset
+}
@@ -0,0 +1,54 @@
+//go:build test && debug
+
+package object_links
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	text := "see [one/uno] and [!md \"markdown\"]\n" +
+		"- [ ] todo, [x] done, [a link](https://example.com)\n" +
+		"ünïcode [tag-3] [unclosed"
+
+	expected := []Link{
+		{ObjectId: "one/uno", Line: 0, Start: 4, End: 13},
+		{ObjectId: "!md", Line: 0, Start: 18, End: 34},
+		{ObjectId: "tag-3", Line: 2, Start: 8, End: 15},
+	}
+
+	if links := Find(text); !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %v, got %v", expected, links)
+	}
+}
+
+func TestAt(t *testing.T) {
+	text := "first line\nsee [one/uno] here"
+
+	if link, ok := At(text, 1, 6); !ok || link.ObjectId != "one/uno" {
+		t.Errorf("expected one/uno at 1:6, got %v, %t", link, ok)
+	}
+
+	if link, ok := At(text, 1, 13); ok {
+		t.Errorf("expected no link after the closing bracket, got %v", link)
+	}
+
+	if link, ok := At(text, 0, 0); ok {
+		t.Errorf("expected no link on the first line, got %v", link)
+	}
+}
+
+func TestCompletionPrefixAt(t *testing.T) {
+	text := "see [one/u\ndone [one/uno] and"
+
+	prefix, start, ok := CompletionPrefixAt(text, 0, 10)
+
+	if !ok || prefix != "one/u" || start != 5 {
+		t.Errorf("expected one/u at 5, got %q at %d, %t", prefix, start, ok)
+	}
+
+	if prefix, _, ok := CompletionPrefixAt(text, 1, 18); ok {
+		t.Errorf("expected no prefix after a closed link, got %q", prefix)
+	}
+}
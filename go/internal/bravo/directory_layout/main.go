@@ -30,19 +30,25 @@ type (
 		DirIndexObjects() string
 
 		DirCacheRepo(p ...string) string
+		DirCacheQueryResults() string
 
 		DirLostAndFound() string
 		DirObjectId() string
 
+		FileCacheBlobRefcounts() string
 		FileCacheDormant() string
 		FileCacheObjectId() string
 		FileConfig() string
 		FileConfigTags() string
 		FileConfigTypes() string
 		FileConfigRepos() string
+		FileTrustedRepoPubKeys() string
 		FileLock() string
+		FileAccessLock() string
 		FileTags() string
 		FileInventoryListLog() string
+		FileInventoryListCheckpoints() string
+		FileInventoryListOrphans() string
 		FileZettelIdLog() string
 
 		DirsGenesis() []string
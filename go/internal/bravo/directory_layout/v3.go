@@ -64,6 +64,10 @@ func (layout v3) MakePathBlobStore(
 		stringSliceJoin("blob_stores", targets)...)
 }
 
+func (layout v3) FileCacheBlobRefcounts() string {
+	return layout.MakeDirCache("blob_refcounts").String()
+}
+
 func (layout v3) FileCacheDormant() string {
 	return layout.MakeDirData("dormant").String()
 }
@@ -76,6 +80,10 @@ func (layout v3) FileLock() string {
 	return layout.xdg.GetDirState().MakePath("lock").String()
 }
 
+func (layout v3) FileAccessLock() string {
+	return layout.xdg.GetDirState().MakePath("access-lock").String()
+}
+
 func (layout v3) FileConfig() string {
 	return layout.MakeDirConfig("config-mutable").String()
 }
@@ -92,6 +100,10 @@ func (layout v3) FileConfigRepos() string {
 	return layout.MakeDirData("config-repos").String()
 }
 
+func (layout v3) FileTrustedRepoPubKeys() string {
+	return layout.MakeDirData("trusted_repo_pub_keys").String()
+}
+
 func (layout v3) DirDataIndex(p ...string) string {
 	return layout.MakeDirData(stringSliceJoin("index", p)...).String()
 }
@@ -101,6 +113,10 @@ func (layout v3) DirCacheRepo(p ...string) string {
 		stringSliceJoin("repo", p)...).String()
 }
 
+func (layout v3) DirCacheQueryResults() string {
+	return layout.MakeDirCache("query_results").String()
+}
+
 func (layout v3) DirLostAndFound() string {
 	return layout.MakeDirCache("lost_and_found").String()
 }
@@ -131,6 +147,14 @@ func (layout v3) FileInventoryListLog() string {
 	return layout.MakeDirData("inventory_lists_log").String()
 }
 
+func (layout v3) FileInventoryListCheckpoints() string {
+	return layout.MakeDirData("inventory_lists_checkpoints").String()
+}
+
+func (layout v3) FileInventoryListOrphans() string {
+	return layout.MakeDirData("inventory_lists_orphans").String()
+}
+
 func (layout v3) FileZettelIdLog() string {
 	return layout.MakeDirData("zettel_id_log").String()
 }
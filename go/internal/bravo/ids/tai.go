@@ -363,3 +363,49 @@ func (id Tai) ToSeq() doddish.Seq {
 		},
 	}
 }
+
+// TaiOrTimeValue is a flag value that accepts a tai (`1660007128.5`), an
+// RFC3339 timestamp, or a local date, which is the midnight that starts it.
+type TaiOrTimeValue Tai
+
+func (t *TaiOrTimeValue) Set(value string) (err error) {
+	var tai Tai
+
+	if strings.Trim(value, "0123456789.") == "" {
+		if err = tai.Set(value); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		*t = TaiOrTimeValue(tai)
+
+		return err
+	}
+
+	if err = tai.SetFromRFC3339(value); err == nil {
+		*t = TaiOrTimeValue(tai)
+		return err
+	}
+
+	var date time.Time
+
+	if date, err = time.ParseInLocation(time.DateOnly, value, time.Local); err != nil {
+		err = errors.BadRequestf(
+			"expected a tai, an RFC3339 timestamp, or a date, but got %q",
+			value,
+		)
+		return err
+	}
+
+	*t = TaiOrTimeValue(TaiFromTime1(date))
+
+	return err
+}
+
+func (t *TaiOrTimeValue) String() string {
+	if Tai(*t).IsEmpty() {
+		return ""
+	}
+
+	return Tai(*t).String()
+}
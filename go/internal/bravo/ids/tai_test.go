@@ -89,3 +89,43 @@ func TestTaiWithIndex(t1 *testing.T) {
 		t.Fatalf("expected .String() %q but got %q", ex, sut.String())
 	}
 }
+
+func TestTaiOrTimeValue(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	var fromTai TaiOrTimeValue
+
+	if err := fromTai.Set("2052235243.336092"); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	if actual := Tai(fromTai).String(); actual != "2052235243.336092" {
+		t.Errorf("expected tai 2052235243.336092 but got %s", actual)
+	}
+
+	var fromRFC3339, fromDate TaiOrTimeValue
+
+	if err := fromRFC3339.Set("2026-03-01T00:00:00Z"); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	if err := fromDate.Set("2026-03-01"); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	midnight := tyme.Date(2026, tyme.March, 1, 0, 0, 0, 0, tyme.Local)
+
+	if !Tai(fromDate).Equals(TaiFromTime1(midnight)) {
+		t.Errorf("expected %s but got %s", TaiFromTime1(midnight), Tai(fromDate))
+	}
+
+	if Tai(fromRFC3339).IsEmpty() {
+		t.Errorf("expected a tai")
+	}
+
+	var invalid TaiOrTimeValue
+
+	if err := invalid.Set("last march"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -33,6 +33,8 @@ const (
 	TypeTomlBlobStoreConfigV2                       = "!toml-blob_store_config-v2"
 	TypeTomlBlobStoreConfigV3                       = "!toml-blob_store_config-v3"
 	TypeTomlBlobStoreConfigPointerV0                = "!toml-blob_store_config-pointer-v0"
+	TypeTomlBlobStoreConfigLazyParentV0             = "!toml-blob_store_config-lazy_parent-v0"
+	TypeTomlBlobStoreConfigEncryptedV0              = "!toml-blob_store_config-encrypted-v0"
 	TypeTomlBlobStoreConfigInventoryArchiveV0       = "!toml-blob_store_config-inventory_archive-v0"
 	TypeTomlBlobStoreConfigInventoryArchiveV1       = "!toml-blob_store_config-inventory_archive-v1"
 	TypeTomlBlobStoreConfigInventoryArchiveV2       = "!toml-blob_store_config-inventory_archive-v2"
@@ -95,6 +97,16 @@ func init() {
 		genres.Unknown,
 		false,
 	)
+	registerBuiltinTypeString(
+		TypeTomlBlobStoreConfigLazyParentV0,
+		genres.Unknown,
+		false,
+	)
+	registerBuiltinTypeString(
+		TypeTomlBlobStoreConfigEncryptedV0,
+		genres.Unknown,
+		false,
+	)
 	registerBuiltinTypeString(
 		TypeTomlBlobStoreConfigInventoryArchiveV0,
 		genres.Unknown,
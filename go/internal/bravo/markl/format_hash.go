@@ -111,6 +111,12 @@ func (formatHash *FormatHash) Get() (*Hash, interfaces.FuncRepool) {
 	return &hash, repool
 }
 
+// GetPoolStats reports the hash pool's counters so callers can check for
+// leaked hashes or tune allocation on hot paths.
+func (formatHash FormatHash) GetPoolStats() (pool.Stats, bool) {
+	return pool.GetStats(formatHash.pool)
+}
+
 func (formatHash FormatHash) GetMarklFormatId() string {
 	return formatHash.id
 }
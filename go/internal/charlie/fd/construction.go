@@ -140,3 +140,27 @@ func MakeFromFileInfoWithDir(
 
 	return fd, err
 }
+
+// MakeFromFileInfoWithDigest makes a stored file descriptor from a digest
+// already known for the file's contents, without reading it.
+func MakeFromFileInfoWithDigest(
+	fileInfo os.FileInfo,
+	dir string,
+	digest domain_interfaces.MarklId,
+) (fd *FD, err error) {
+	fd = &FD{}
+
+	if err = fd.SetFileInfoWithDir(fileInfo, dir); err != nil {
+		err = errors.Wrap(err)
+		return fd, err
+	}
+
+	if err = fd.SetShaLike(digest); err != nil {
+		err = errors.Wrap(err)
+		return fd, err
+	}
+
+	fd.state = StateStored
+
+	return fd, err
+}
@@ -19,6 +19,9 @@ type Config struct {
 	CheckoutCacheEnabled bool
 	PredictableZettelIds bool
 
+	// `key=value` overrides of the layered repo and workspace config
+	ConfigOverrides []string
+
 	printOptionsOverlay options_print.Overlay
 	ToolOptions         options_tools.Options
 
@@ -59,6 +62,15 @@ func (config *Config) SetFlagDefinitions(flagSet interfaces.CLIFlagDefinitions)
 		"generate new zettel ids in order",
 	)
 
+	flagSet.Func(
+		"config",
+		"override a layered config value, like editor=nvim or defaults.type=md (repeatable, see config-show)",
+		func(value string) (err error) {
+			config.ConfigOverrides = append(config.ConfigOverrides, value)
+			return err
+		},
+	)
+
 	config.printOptionsOverlay.AddToFlags(flagSet)
 	config.ToolOptions.SetFlagDefinitions(flagSet)
 
@@ -98,6 +110,10 @@ func (config Config) GetBasePath() string {
 	return config.BasePath
 }
 
+func (config Config) GetConfigOverrides() []string {
+	return config.ConfigOverrides
+}
+
 func (config Config) GetIgnoreWorkspace() bool {
 	return config.IgnoreWorkspace
 }
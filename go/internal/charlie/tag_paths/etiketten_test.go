@@ -1,6 +1,7 @@
 package tag_paths
 
 import (
+	"fmt"
 	"testing"
 
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
@@ -24,7 +25,7 @@ func TestAddPaths(t1 *testing.T) {
 		i, ok := es.All.ContainsTag(area)
 
 		if !ok {
-			t.Errorf("expected some tag: %d, %t, %s", i, ok, es)
+			t.Errorf("expected some tag: %d, %t, %s", i, ok, &es)
 		}
 	}
 
@@ -62,7 +63,7 @@ func TestRealWorld(t1 *testing.T) {
 		_, ok := es.All.ContainsTag(e)
 
 		if !ok {
-			t.Errorf("expected %s to be in %s", e, es)
+			t.Errorf("expected %s to be in %s", e, &es)
 		}
 	}
 
@@ -77,7 +78,7 @@ func TestRealWorld(t1 *testing.T) {
 	_, ok := es.All.ContainsTag(e)
 
 	if !ok {
-		t.Errorf("expected %s to be in %s", e, es)
+		t.Errorf("expected %s to be in %s", e, &es)
 	}
 }
 
@@ -132,3 +133,79 @@ func BenchmarkMatchFirstNo(b *testing.B) {
 		es.All.ContainsTag(m)
 	}
 }
+
+var benchmarkTags = []string{
+	"area-home",
+	"project-reno",
+	"area-career",
+	"project-recurse",
+	"today",
+}
+
+func BenchmarkAddTagMakeFromString(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var es Tags
+
+		for _, tag := range benchmarkTags {
+			s, _ := catgut.MakeFromString(tag)
+			es.AddTag(s)
+		}
+	}
+}
+
+func BenchmarkAddTagIntern(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var es Tags
+
+		for _, tag := range benchmarkTags {
+			es.AddTag(catgut.Intern(tag))
+		}
+	}
+}
+
+func BenchmarkComparePathSharedPrefix(b *testing.B) {
+	left := makePath(
+		catgut.Intern("area-career"),
+		catgut.Intern("project-recurse-a"),
+	)
+
+	right := makePath(
+		catgut.Intern("area-career"),
+		catgut.Intern("project-recurse-b"),
+	)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		ComparePath(&left, &right)
+	}
+}
+
+func BenchmarkContainsTagManyTags(b *testing.B) {
+	for _, count := range []int{10, 100, 1000, 10000} {
+		var es Tags
+
+		for i := range count {
+			es.AddTag(catgut.Intern(fmt.Sprintf("area-%05d-project", i)))
+		}
+
+		yes := catgut.Intern(fmt.Sprintf("area-%05d", count/2))
+		no := catgut.Intern("area-x")
+
+		b.Run(fmt.Sprintf("%d/yes", count), func(b *testing.B) {
+			for b.Loop() {
+				es.All.ContainsTag(yes)
+			}
+		})
+
+		b.Run(fmt.Sprintf("%d/no", count), func(b *testing.B) {
+			for b.Loop() {
+				es.All.ContainsTag(no)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package tag_paths
+
+import (
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// FuncExpand computes the paths a single tag contributes to an object's Tags
+// (e.g., the tag itself and every tag it implies).
+type FuncExpand func(tag string) PathsWithTypes
+
+// ExpansionCache memoizes FuncExpand results keyed by tag string so that tag
+// hierarchies are derived once per pass over many objects rather than once per
+// object. It is meant to live for a single pass (e.g., one stream index page
+// flush), during which tag objects cannot change, so it is never invalidated.
+// It is not safe for concurrent use.
+//
+// A nil *ExpansionCache is valid and does not cache.
+type ExpansionCache struct {
+	expansions map[string]PathsWithTypes
+
+	hits, misses int64
+}
+
+func MakeExpansionCache() *ExpansionCache {
+	return &ExpansionCache{
+		expansions: make(map[string]PathsWithTypes),
+	}
+}
+
+func (cache *ExpansionCache) Len() int {
+	if cache == nil {
+		return 0
+	}
+
+	return len(cache.expansions)
+}
+
+func (cache *ExpansionCache) Stats() (hits, misses int64) {
+	if cache == nil {
+		return hits, misses
+	}
+
+	return cache.hits, cache.misses
+}
+
+// GetOrExpand returns the expansion of tag, cloned so that callers own the
+// returned paths and can never mutate the memoized ones.
+func (cache *ExpansionCache) GetOrExpand(
+	tag string,
+	expand FuncExpand,
+) (paths PathsWithTypes) {
+	if cache == nil {
+		return expand(tag)
+	}
+
+	cached, ok := cache.expansions[tag]
+
+	if ok {
+		cache.hits++
+	} else {
+		cache.misses++
+		cached = expand(tag)
+		cache.expansions[tag] = cached
+	}
+
+	paths = make(PathsWithTypes, 0, len(cached))
+
+	for _, path := range cached {
+		paths = append(paths, path.Clone())
+	}
+
+	return paths
+}
+
+// AddExpanded adds the (possibly memoized) expansion of tag to tags.
+func (cache *ExpansionCache) AddExpanded(
+	tags *Tags,
+	tag string,
+	expand FuncExpand,
+) (err error) {
+	for _, path := range cache.GetOrExpand(tag, expand) {
+		if err = tags.AddPathWithType(path); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
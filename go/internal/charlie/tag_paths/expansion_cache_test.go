@@ -0,0 +1,80 @@
+//go:build test
+
+package tag_paths
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	"code.linenisgreat.com/dodder/go/lib/delta/catgut"
+)
+
+func TestExpansionCache(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	implied := "area-career"
+	calls := 0
+
+	expand := func(value string) (paths PathsWithTypes) {
+		calls++
+
+		tag, _ := catgut.MakeFromString(value)
+		impliedTag, _ := catgut.MakeFromString(implied)
+
+		path := MakePathWithType(tag, impliedTag)
+		path.Type = TypeIndirect
+		paths.AddPath(path)
+
+		return paths
+	}
+
+	cache := MakeExpansionCache()
+
+	for range 3 {
+		var tags Tags
+
+		if err := cache.AddExpanded(&tags, "project-2022-recurse", expand); err != nil {
+			t.Fatalf("expected no error but got %s", err)
+		}
+
+		prefix, _ := catgut.MakeFromString("area")
+
+		if _, ok := tags.All.ContainsTag(prefix); !ok {
+			t.Errorf("expected implied tag in %s", &tags)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 expansion but got %d", calls)
+	}
+
+	if hits, misses := cache.Stats(); hits != 2 || misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss but got %d and %d", hits, misses)
+	}
+
+	paths := cache.GetOrExpand("project-2022-recurse", expand)
+	paths[0].Type = TypeDirect
+
+	if cached := cache.GetOrExpand("project-2022-recurse", expand); cached[0].Type != TypeIndirect {
+		t.Errorf("expected mutating returned paths to leave the cache intact")
+	}
+}
+
+func TestExpansionCacheNil(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	var cache *ExpansionCache
+	calls := 0
+
+	expand := func(value string) (paths PathsWithTypes) {
+		calls++
+		return paths
+	}
+
+	cache.GetOrExpand("area", expand)
+	cache.GetOrExpand("area", expand)
+
+	if calls != 2 {
+		t.Errorf("expected a nil cache to always expand but got %d calls", calls)
+	}
+}
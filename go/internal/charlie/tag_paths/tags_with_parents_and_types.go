@@ -78,6 +78,9 @@ func (tagsWithParentsAndTypes TagsWithParentsAndTypes) containsObjectIdTag(
 	)
 }
 
+// ContainsTag reports whether any tag starts with e (`area` matching
+// `area-home`). The tags are kept sorted, so this is a binary search, logarithmic
+// in the number of tags.
 func (tagsWithParentsAndTypes TagsWithParentsAndTypes) ContainsTag(e *Tag) (int, bool) {
 	return cmp.BinarySearchFuncIndex(
 		tagsWithParentsAndTypes,
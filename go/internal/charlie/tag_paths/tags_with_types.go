@@ -70,8 +70,7 @@ func (tags *Tags) AddSuperFrom(
 }
 
 func (tags *Tags) AddTagOld(tag ids.Tag) (err error) {
-	s, _ := catgut.MakeFromString(tag.String())
-	return tags.AddTag(s)
+	return tags.AddTag(catgut.Intern(tag.String()))
 }
 
 func (tags *Tags) AddTag(e *Tag) (err error) {
@@ -142,9 +141,7 @@ func (tags *Tags) Set(v string) (err error) {
 			return err
 		}
 
-		es, _ := catgut.MakeFromString(e.String())
-
-		if err = tags.AddTag(es); err != nil {
+		if err = tags.AddTag(catgut.Intern(e.String())); err != nil {
 			err = errors.Wrap(err)
 			return err
 		}
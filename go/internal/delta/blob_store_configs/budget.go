@@ -0,0 +1,52 @@
+package blob_store_configs
+
+import (
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+// BudgetConfig caps the bytes a store keeps, as stored (after compression and
+// encryption). A zero MaxTotalBytes means no budget. Writes that would take
+// the store past MaxTotalBytes fail, and once the store is past
+// WarnAtPercent of it, writes warn and storage reports flag the store.
+type BudgetConfig struct {
+	MaxTotalBytes uint64 `toml:"max-total-bytes,omitempty"`
+	WarnAtPercent int    `toml:"warn-at-percent,omitempty"`
+}
+
+var _ ConfigBudget = BudgetConfig{}
+
+func (config BudgetConfig) GetMaxTotalBytes() uint64 {
+	return config.MaxTotalBytes
+}
+
+func (config BudgetConfig) GetWarnAtPercent() int {
+	return config.WarnAtPercent
+}
+
+func (config *BudgetConfig) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.Func(
+		"max-total-bytes",
+		"fail writes that would take the store past this size (e.g. 10G, 0 = unlimited)",
+		func(value string) (err error) {
+			var bytes ui.HumanReadableBytes
+
+			if err = bytes.Set(value); err != nil {
+				return err
+			}
+
+			config.MaxTotalBytes = bytes.GetByteCount()
+
+			return err
+		},
+	)
+
+	flagSet.IntVar(
+		&config.WarnAtPercent,
+		"warn-at-percent",
+		0,
+		"warn once the store uses this percentage of max-total-bytes (0 = never)",
+	)
+}
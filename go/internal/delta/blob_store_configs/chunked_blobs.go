@@ -0,0 +1,66 @@
+package blob_store_configs
+
+import (
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+const DefaultChunkedBlobAvgChunkSize = 1 << 20
+
+// ChunkedBlobsConfig stores blobs of at least ChunkedBlobThreshold bytes as
+// content-defined chunk blobs plus a manifest, so versions of a large blob
+// share their unchanged chunks. A zero threshold keeps every blob whole.
+type ChunkedBlobsConfig struct {
+	ChunkedBlobThreshold    uint64 `toml:"chunked-blob-threshold,omitempty"`
+	ChunkedBlobAvgChunkSize uint64 `toml:"chunked-blob-avg-chunk-size,omitempty"`
+}
+
+var _ ConfigChunkedBlobs = ChunkedBlobsConfig{}
+
+func (config ChunkedBlobsConfig) GetChunkedBlobThreshold() uint64 {
+	return config.ChunkedBlobThreshold
+}
+
+func (config ChunkedBlobsConfig) GetChunkedBlobAvgChunkSize() uint64 {
+	if config.ChunkedBlobAvgChunkSize == 0 {
+		return DefaultChunkedBlobAvgChunkSize
+	}
+
+	return config.ChunkedBlobAvgChunkSize
+}
+
+func (config *ChunkedBlobsConfig) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.Func(
+		"chunked-blob-threshold",
+		"store blobs of at least this size as content-defined chunks (e.g. 64M, 0 = never)",
+		func(value string) (err error) {
+			var bytes ui.HumanReadableBytes
+
+			if err = bytes.Set(value); err != nil {
+				return err
+			}
+
+			config.ChunkedBlobThreshold = bytes.GetByteCount()
+
+			return err
+		},
+	)
+
+	flagSet.Func(
+		"chunked-blob-avg-chunk-size",
+		"average size of the chunks of chunked blobs (default 1M)",
+		func(value string) (err error) {
+			var bytes ui.HumanReadableBytes
+
+			if err = bytes.Set(value); err != nil {
+				return err
+			}
+
+			config.ChunkedBlobAvgChunkSize = bytes.GetByteCount()
+
+			return err
+		},
+	)
+}
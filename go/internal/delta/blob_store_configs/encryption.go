@@ -53,6 +53,16 @@ func setEncryptionFlagDefinition(
 	)
 }
 
+// SetEncryptionKeysFlagDefinition defines the repeatable -encryption flag of
+// the init commands, which adds a key file, a key, or a generated key to
+// keys.
+func SetEncryptionKeysFlagDefinition(
+	flagSet interfaces.CLIFlagDefinitions,
+	keys *[]markl.Id,
+) {
+	setMultiEncryptionFlagDefinition(flagSet, keys)
+}
+
 func setMultiEncryptionFlagDefinition(
 	flagSet interfaces.CLIFlagDefinitions,
 	keys *[]markl.Id,
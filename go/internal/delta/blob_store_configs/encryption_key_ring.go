@@ -0,0 +1,113 @@
+package blob_store_configs
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/echo/age"
+)
+
+// RetiredEncryptionConfig holds the keys a store's blobs were encrypted with
+// before its keys were rotated. They decrypt blobs but never encrypt new
+// ones, and `rotate-encryption` drops them once every blob is re-encrypted
+// with the current keys.
+type RetiredEncryptionConfig struct {
+	RetiredEncryption []markl.Id `toml:"retired-encryption,omitempty"`
+}
+
+// EncryptionKeyRing is a store's current encryption keys together with its
+// retired ones. As a MarklId it is its current keys, but its IOWrapper also
+// decrypts with the retired keys.
+type EncryptionKeyRing struct {
+	EncryptionKeys
+	Retired EncryptionKeys
+}
+
+var _ domain_interfaces.MarklId = EncryptionKeyRing{}
+
+func (ring EncryptionKeyRing) HasRetired() bool {
+	return !ring.Retired.IsNull()
+}
+
+// Rotate returns the ring that encrypts with keys and decrypts with every key
+// of ring.
+func (ring EncryptionKeyRing) Rotate(keys []markl.Id) EncryptionKeyRing {
+	return EncryptionKeyRing{
+		EncryptionKeys: EncryptionKeys(keys),
+		Retired: append(
+			append(EncryptionKeys{}, ring.Retired...),
+			ring.EncryptionKeys...,
+		),
+	}
+}
+
+func (ring EncryptionKeyRing) GetIOWrapper() (
+	ioWrapper interfaces.IOWrapper,
+	err error,
+) {
+	if !ring.HasRetired() {
+		return ring.EncryptionKeys.GetIOWrapper()
+	}
+
+	if ring.EncryptionKeys.IsNull() {
+		err = errors.BadRequestf(
+			"retired-encryption requires at least one encryption key",
+		)
+
+		return ioWrapper, err
+	}
+
+	var identities, retired []age.Identity
+
+	if identities, err = ring.EncryptionKeys.getAgeIdentities(); err != nil {
+		err = errors.Wrap(err)
+		return ioWrapper, err
+	}
+
+	if retired, err = ring.Retired.getAgeIdentities(); err != nil {
+		err = errors.Wrap(err)
+		return ioWrapper, err
+	}
+
+	mi := age.MakeMultiIdentityWithRetired(identities, retired)
+	ioWrapper = &mi
+
+	return ioWrapper, err
+}
+
+func (config TomlV3) GetEncryptionKeyRing() EncryptionKeyRing {
+	return EncryptionKeyRing{
+		EncryptionKeys: config.Encryption,
+		Retired:        config.RetiredEncryption,
+	}
+}
+
+func (config *TomlV3) SetEncryptionKeyRing(ring EncryptionKeyRing) {
+	config.Encryption = ring.EncryptionKeys
+	config.RetiredEncryption = ring.Retired
+}
+
+func (config TomlLazyParentV0) GetEncryptionKeyRing() EncryptionKeyRing {
+	return EncryptionKeyRing{
+		EncryptionKeys: config.Encryption,
+		Retired:        config.RetiredEncryption,
+	}
+}
+
+func (config *TomlLazyParentV0) SetEncryptionKeyRing(ring EncryptionKeyRing) {
+	config.Encryption = ring.EncryptionKeys
+	config.RetiredEncryption = ring.Retired
+}
+
+func (config TomlEncryptedV0) GetEncryptionKeyRing() EncryptionKeyRing {
+	return EncryptionKeyRing{
+		EncryptionKeys: config.Encryption,
+		Retired:        config.RetiredEncryption,
+	}
+}
+
+func (config *TomlEncryptedV0) SetEncryptionKeyRing(ring EncryptionKeyRing) {
+	config.Encryption = ring.EncryptionKeys
+	config.RetiredEncryption = ring.Retired
+}
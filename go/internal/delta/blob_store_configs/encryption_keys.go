@@ -94,42 +94,51 @@ func (keys EncryptionKeys) GetIOWrapper() (
 	ioWrapper interfaces.IOWrapper,
 	err error,
 ) {
-	nonNullKeys := make([]markl.Id, 0, len(keys))
+	var identities []age.Identity
 
-	for _, key := range keys {
-		if !key.IsNull() {
-			nonNullKeys = append(nonNullKeys, key)
-		}
+	if identities, err = keys.getAgeIdentities(); err != nil {
+		err = errors.Wrap(err)
+		return ioWrapper, err
 	}
 
-	if len(nonNullKeys) == 0 {
+	if len(identities) == 0 {
 		return nil, nil
 	}
 
-	identities := make([]age.Identity, 0, len(nonNullKeys))
+	mi := age.MakeMultiIdentity(identities)
+	ioWrapper = &mi
+
+	return ioWrapper, err
+}
+
+// getAgeIdentities returns the identities of the keys that are not null.
+func (keys EncryptionKeys) getAgeIdentities() (
+	identities []age.Identity,
+	err error,
+) {
+	for _, key := range keys {
+		if key.IsNull() {
+			continue
+		}
 
-	for _, key := range nonNullKeys {
 		var keyWrapper interfaces.IOWrapper
 
 		if keyWrapper, err = key.GetIOWrapper(); err != nil {
 			err = errors.Wrap(err)
-			return ioWrapper, err
+			return identities, err
 		}
 
 		ageIdentity, ok := keyWrapper.(*age.Identity)
 
 		if !ok {
 			err = errors.Errorf("expected *age.Identity, got %T", keyWrapper)
-			return ioWrapper, err
+			return identities, err
 		}
 
 		identities = append(identities, *ageIdentity)
 	}
 
-	mi := age.MakeMultiIdentity(identities)
-	ioWrapper = &mi
-
-	return ioWrapper, err
+	return identities, err
 }
 
 func (keys EncryptionKeys) Verify(
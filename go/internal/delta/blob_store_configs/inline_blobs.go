@@ -0,0 +1,39 @@
+package blob_store_configs
+
+import (
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+// InlineBlobsConfig stores blobs smaller than InlineBlobThreshold bytes in
+// the store's small-blob archive instead of a file each. A zero threshold
+// keeps every blob in its own file.
+type InlineBlobsConfig struct {
+	InlineBlobThreshold uint64 `toml:"inline-blob-threshold,omitempty"`
+}
+
+var _ ConfigInlineBlobs = InlineBlobsConfig{}
+
+func (config InlineBlobsConfig) GetInlineBlobThreshold() uint64 {
+	return config.InlineBlobThreshold
+}
+
+func (config *InlineBlobsConfig) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.Func(
+		"inline-blob-threshold",
+		"store blobs smaller than this size in the small-blob archive (e.g. 4K, 0 = never)",
+		func(value string) (err error) {
+			var bytes ui.HumanReadableBytes
+
+			if err = bytes.Set(value); err != nil {
+				return err
+			}
+
+			config.InlineBlobThreshold = bytes.GetByteCount()
+
+			return err
+		},
+	)
+}
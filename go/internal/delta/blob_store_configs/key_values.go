@@ -30,6 +30,12 @@ func ConfigKeyValues(config Config) map[string]string {
 		)
 	}
 
+	if configKeyRing, ok := config.(ConfigEncryptionKeyRing); ok {
+		if ring := configKeyRing.GetEncryptionKeyRing(); ring.HasRetired() {
+			keyValues["retired-encryption"] = ring.Retired.StringWithFormat()
+		}
+	}
+
 	if configLocal, ok := config.(ConfigLocalHashBucketed); ok {
 		keyValues["hash_buckets"] = fmt.Sprint(
 			configLocal.GetHashBuckets(),
@@ -63,6 +69,40 @@ func ConfigKeyValues(config Config) map[string]string {
 		)
 	}
 
+	if configStrategy, ok := config.(DeltaStrategyConfigImmutable); ok {
+		keyValues["delta.strategy"] = configStrategy.GetDeltaStrategy()
+		keyValues["delta.similarity.sketch-size"] = fmt.Sprint(
+			configStrategy.GetSimilaritySketchSize(),
+		)
+		keyValues["delta.similarity.shingle-size"] = fmt.Sprint(
+			configStrategy.GetSimilarityShingleSize(),
+		)
+		keyValues["delta.similarity.min-similarity"] = fmt.Sprint(
+			configStrategy.GetSimilarityMinSimilarity(),
+		)
+	}
+
+	if configCompression, ok := config.(CompressionConfigImmutable); ok {
+		keyValues["compression.level"] = fmt.Sprint(
+			configCompression.GetCompressionLevel(),
+		)
+		keyValues["compression.dictionary"] = fmt.Sprint(
+			configCompression.GetCompressionDictionaryEnabled(),
+		)
+		keyValues["compression.dictionary-max-size"] = fmt.Sprint(
+			configCompression.GetCompressionDictionaryMaxSize(),
+		)
+		keyValues["compression.min-savings-percent"] = fmt.Sprint(
+			configCompression.GetCompressionMinSavingsPercent(),
+		)
+	}
+
+	if configFooterIndex, ok := config.(FooterIndexConfigImmutable); ok {
+		keyValues["footer-index"] = fmt.Sprint(
+			configFooterIndex.GetFooterIndexEnabled(),
+		)
+	}
+
 	if configSFTP, ok := config.(ConfigSFTPConfigExplicit); ok {
 		keyValues["host"] = configSFTP.GetHost()
 		keyValues["port"] = fmt.Sprint(configSFTP.GetPort())
@@ -91,6 +91,90 @@ type (
 		GetSelectorMaxBlobSize() uint64
 	}
 
+	// DeltaStrategyConfigImmutable selects how delta bases are chosen:
+	// "size" (the default) groups blobs of similar size, and "similarity"
+	// pairs blobs by content sketches computed while collecting them.
+	DeltaStrategyConfigImmutable interface {
+		GetDeltaStrategy() string
+		GetSimilaritySketchSize() int
+		GetSimilarityShingleSize() int
+		GetSimilarityMinSimilarity() float64
+	}
+
+	CompressionConfigImmutable interface {
+		GetCompressionLevel() int
+		GetCompressionDictionaryEnabled() bool
+		GetCompressionDictionaryMaxSize() uint64
+		GetCompressionMinSavingsPercent() int
+	}
+
+	// FooterIndexConfigImmutable selects v2 archives, which carry their index
+	// in a footer of the data file rather than in a separate index file.
+	FooterIndexConfigImmutable interface {
+		GetFooterIndexEnabled() bool
+	}
+
+	// ConfigBudget is implemented by the configs of stores that enforce a
+	// size budget (BudgetConfig).
+	ConfigBudget interface {
+		GetMaxTotalBytes() uint64
+		GetWarnAtPercent() int
+	}
+
+	// ConfigEncryptionKeyRing is implemented by the configs of stores whose
+	// encryption keys can be rotated (RetiredEncryptionConfig).
+	ConfigEncryptionKeyRing interface {
+		GetEncryptionKeyRing() EncryptionKeyRing
+	}
+
+	ConfigEncryptionKeyRingMutable interface {
+		ConfigEncryptionKeyRing
+		SetEncryptionKeyRing(EncryptionKeyRing)
+	}
+
+	// ConfigReadOnly is implemented by the configs of stores that can be
+	// marked read-only (ReadOnlyConfig).
+	ConfigReadOnly interface {
+		IsReadOnly() bool
+	}
+
+	// ConfigTransfer is implemented by the configs of stores that cap the
+	// copies they take part in (TransferConfig).
+	ConfigTransfer interface {
+		GetMaxParallelTransfers() int
+		GetMaxBytesPerSecond() uint64
+	}
+
+	// ConfigChunkedBlobs is implemented by the configs of stores that can
+	// keep large blobs as content-defined chunks (ChunkedBlobsConfig).
+	ConfigChunkedBlobs interface {
+		GetChunkedBlobThreshold() uint64
+		GetChunkedBlobAvgChunkSize() uint64
+	}
+
+	// ConfigInlineBlobs is implemented by the configs of stores that can
+	// keep small blobs in a small-blob archive (InlineBlobsConfig).
+	ConfigInlineBlobs interface {
+		GetInlineBlobThreshold() uint64
+	}
+
+	// TieringConfigImmutable selects where demoted archives go and how long
+	// an archive goes unread before it is demoted. An empty cold path
+	// disables tiering.
+	TieringConfigImmutable interface {
+		GetTieringColdPath() string
+		GetTieringDemoteAfterDays() int
+	}
+
+	// ChunkingConfigImmutable selects content-defined archive boundaries,
+	// the average archive size they aim for, and the alignment of archive
+	// entries, 0 for none.
+	ChunkingConfigImmutable interface {
+		GetChunkingContentDefined() bool
+		GetChunkingAvgArchiveSize() uint64
+		GetChunkingAlign() uint64
+	}
+
 	ConfigInventoryArchiveDelta interface {
 		ConfigInventoryArchive
 		DeltaConfigImmutable
@@ -101,6 +185,18 @@ type (
 		GetPath() directory_layout.BlobStorePath
 	}
 
+	ConfigLazyParent interface {
+		ConfigLocalHashBucketed
+		GetParentPath() directory_layout.BlobStorePath
+	}
+
+	ConfigEncrypted interface {
+		configLocal
+		ConfigHashType
+		domain_interfaces.BlobIOWrapper
+		GetInnerPath() directory_layout.BlobStorePath
+	}
+
 	ConfigSFTPRemotePath interface {
 		Config
 		GetRemotePath() string
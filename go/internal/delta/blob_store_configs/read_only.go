@@ -0,0 +1,16 @@
+package blob_store_configs
+
+// ReadOnlyConfig marks a store as read-only, as for stores on read-only
+// mounts or shared network drives. Writes to a read-only store, including
+// packing and cache writes, fail with env_dir.ErrReadOnly, while reads work
+// as usual. It has no flag, as `-read-only` is the global flag that makes
+// every store read-only.
+type ReadOnlyConfig struct {
+	ReadOnly bool `toml:"read-only,omitempty"`
+}
+
+var _ ConfigReadOnly = ReadOnlyConfig{}
+
+func (config ReadOnlyConfig) IsReadOnly() bool {
+	return config.ReadOnly
+}
@@ -0,0 +1,37 @@
+package blob_store_configs
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
+)
+
+// GetReferencedBlobStoreIds returns the ids of the other blob stores that
+// config's store reads through: the store a pointer points to, the parent of
+// a lazy store, the inner store of an encrypted store, and the loose store of
+// an inventory archive.
+func GetReferencedBlobStoreIds(config Config) (referenced []blob_store_id.Id) {
+	if configPointer, ok := config.(ConfigPointer); ok {
+		referenced = append(referenced, configPointer.GetPath().GetId())
+	}
+
+	if configLazyParent, ok := config.(ConfigLazyParent); ok {
+		referenced = append(referenced, configLazyParent.GetParentPath().GetId())
+	}
+
+	if configEncrypted, ok := config.(ConfigEncrypted); ok {
+		referenced = append(referenced, configEncrypted.GetInnerPath().GetId())
+	}
+
+	if configArchive, ok := config.(ConfigInventoryArchive); ok {
+		referenced = append(referenced, configArchive.GetLooseBlobStoreId())
+	}
+
+	filtered := referenced[:0]
+
+	for _, id := range referenced {
+		if !id.IsEmpty() {
+			filtered = append(filtered, id)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,104 @@
+package blob_store_configs
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/directory_layout"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+// TomlEncryptedV0 wraps another store (referenced the same way as
+// TomlPointerV0) and encrypts blob payloads with age before handing them to
+// it. Blob ids stay computed over the plaintext.
+type TomlEncryptedV0 struct {
+	HashTypeId HashType   `toml:"hash_type-id"`
+	Encryption []markl.Id `toml:"encryption"`
+
+	RetiredEncryptionConfig
+
+	InnerId         blob_store_id.Id `toml:"inner-id"`
+	InnerBasePath   string           `toml:"inner-base-path"`
+	InnerConfigPath string           `toml:"inner-config-path"`
+}
+
+var (
+	_ ConfigEncrypted                = TomlEncryptedV0{}
+	_ ConfigMutable                  = &TomlEncryptedV0{}
+	_ ConfigEncryptionKeyRingMutable = &TomlEncryptedV0{}
+	_                                = registerToml[TomlEncryptedV0](
+		Coder.Blob,
+		ids.TypeTomlBlobStoreConfigEncryptedV0,
+	)
+)
+
+func (TomlEncryptedV0) GetBlobStoreType() string {
+	return "encrypted"
+}
+
+func (blobStoreConfig *TomlEncryptedV0) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	blobStoreConfig.HashTypeId = HashTypeDefault
+
+	flagSet.Var(
+		&blobStoreConfig.HashTypeId,
+		"hash_type-id",
+		"determines the hash type used for the plaintext of new blobs",
+	)
+
+	setMultiEncryptionFlagDefinition(flagSet, &blobStoreConfig.Encryption)
+
+	flagSet.Var(
+		&blobStoreConfig.InnerId,
+		"inner-id",
+		"the id of the blob store that holds the encrypted blobs",
+	)
+
+	flagSet.StringVar(
+		&blobStoreConfig.InnerBasePath,
+		"inner-base-path",
+		"",
+		"path to the inner blob store base directory",
+	)
+
+	flagSet.StringVar(
+		&blobStoreConfig.InnerConfigPath,
+		"inner-config-path",
+		"",
+		"path to the inner blob store config file",
+	)
+}
+
+func (blobStoreConfig TomlEncryptedV0) getBasePath() string {
+	return ""
+}
+
+func (blobStoreConfig TomlEncryptedV0) GetBlobEncryption() domain_interfaces.MarklId {
+	return blobStoreConfig.GetEncryptionKeyRing()
+}
+
+// never compresses: compressing the plaintext would leak information through
+// the sizes of the encrypted blobs
+func (blobStoreConfig TomlEncryptedV0) GetBlobCompression() interfaces.IOWrapper {
+	compressionType := compression_type.CompressionTypeNone
+	return &compressionType
+}
+
+func (blobStoreConfig TomlEncryptedV0) SupportsMultiHash() bool {
+	return true
+}
+
+func (blobStoreConfig TomlEncryptedV0) GetDefaultHashTypeId() string {
+	return string(blobStoreConfig.HashTypeId)
+}
+
+func (blobStoreConfig TomlEncryptedV0) GetInnerPath() directory_layout.BlobStorePath {
+	return directory_layout.MakeBlobStorePath(
+		blobStoreConfig.InnerId,
+		blobStoreConfig.InnerBasePath,
+		blobStoreConfig.InnerConfigPath,
+	)
+}
@@ -25,15 +25,25 @@ type SelectorConfig struct {
 	MaxBlobSize uint64 `toml:"max-blob-size"`
 }
 
+// SimilarityConfig tunes the content sketches used by the "similarity" delta
+// strategy. Zero values select the defaults.
+type SimilarityConfig struct {
+	SketchSize    int     `toml:"sketch-size"`
+	ShingleSize   int     `toml:"shingle-size"`
+	MinSimilarity float64 `toml:"min-similarity"`
+}
+
 // DeltaConfig holds configuration for delta compression in inventory archives.
 type DeltaConfig struct {
-	Enabled     bool            `toml:"enabled"`
-	Algorithm   string          `toml:"algorithm"`
-	MinBlobSize uint64          `toml:"min-blob-size"`
-	MaxBlobSize uint64          `toml:"max-blob-size"`
-	SizeRatio   float64         `toml:"size-ratio"`
-	Signature   SignatureConfig `toml:"signature"`
-	Selector    SelectorConfig  `toml:"selector"`
+	Enabled     bool             `toml:"enabled"`
+	Algorithm   string           `toml:"algorithm"`
+	Strategy    string           `toml:"strategy"`
+	MinBlobSize uint64           `toml:"min-blob-size"`
+	MaxBlobSize uint64           `toml:"max-blob-size"`
+	SizeRatio   float64          `toml:"size-ratio"`
+	Signature   SignatureConfig  `toml:"signature"`
+	Selector    SelectorConfig   `toml:"selector"`
+	Similarity  SimilarityConfig `toml:"similarity"`
 }
 
 // TomlInventoryArchiveV1 is the V1 configuration for the inventory archive
@@ -48,12 +58,13 @@ type TomlInventoryArchiveV1 struct {
 }
 
 var (
-	_ ConfigInventoryArchiveDelta = TomlInventoryArchiveV1{}
-	_ ConfigUpgradeable           = TomlInventoryArchiveV1{}
-	_ ConfigMutable               = &TomlInventoryArchiveV1{}
-	_ SignatureConfigImmutable    = TomlInventoryArchiveV1{}
-	_ SelectorConfigImmutable     = TomlInventoryArchiveV1{}
-	_                             = registerToml[TomlInventoryArchiveV1](
+	_ ConfigInventoryArchiveDelta  = TomlInventoryArchiveV1{}
+	_ ConfigUpgradeable            = TomlInventoryArchiveV1{}
+	_ ConfigMutable                = &TomlInventoryArchiveV1{}
+	_ SignatureConfigImmutable     = TomlInventoryArchiveV1{}
+	_ SelectorConfigImmutable      = TomlInventoryArchiveV1{}
+	_ DeltaStrategyConfigImmutable = TomlInventoryArchiveV1{}
+	_                              = registerToml[TomlInventoryArchiveV1](
 		Coder.Blob,
 		ids.TypeTomlBlobStoreConfigInventoryArchiveV1,
 	)
@@ -90,6 +101,13 @@ func (config *TomlInventoryArchiveV1) SetFlagDefinitions(
 		false,
 		"enable delta compression",
 	)
+
+	flagSet.StringVar(
+		&config.Delta.Strategy,
+		"delta-strategy",
+		"size",
+		`how to choose delta bases: "size" or "similarity"`,
+	)
 }
 
 func (config TomlInventoryArchiveV1) getBasePath() string {
@@ -164,6 +182,24 @@ func (config TomlInventoryArchiveV1) GetMaxChunkSize() int {
 	return config.Delta.Signature.MaxChunkSize
 }
 
+// DeltaStrategyConfigImmutable implementation
+
+func (config TomlInventoryArchiveV1) GetDeltaStrategy() string {
+	return config.Delta.Strategy
+}
+
+func (config TomlInventoryArchiveV1) GetSimilaritySketchSize() int {
+	return config.Delta.Similarity.SketchSize
+}
+
+func (config TomlInventoryArchiveV1) GetSimilarityShingleSize() int {
+	return config.Delta.Similarity.ShingleSize
+}
+
+func (config TomlInventoryArchiveV1) GetSimilarityMinSimilarity() float64 {
+	return config.Delta.Similarity.MinSimilarity
+}
+
 // SelectorConfigImmutable implementation
 
 func (config TomlInventoryArchiveV1) GetSelectorType() string {
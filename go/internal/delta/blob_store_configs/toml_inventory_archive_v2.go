@@ -6,23 +6,77 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
 	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
 )
 
+// CompressionConfig tunes zstd compression of archived blobs. A zero level
+// uses the zstd default. When Dictionary is enabled, packing trains a
+// dictionary over a sample of each archive's blobs and embeds it in the
+// archive, which pays off for many small, similar blobs. Blobs whose
+// compressed form is not at least MinSavingsPercent smaller are stored
+// uncompressed.
+type CompressionConfig struct {
+	Level             int    `toml:"level"`
+	Dictionary        bool   `toml:"dictionary"`
+	DictionaryMaxSize uint64 `toml:"dictionary-max-size"`
+	MinSavingsPercent int    `toml:"min-savings-percent"`
+}
+
+// DefaultTieringDemoteAfterDays is how long an archive goes unread before it
+// is demoted, when a cold path is set without `demote-after-days`.
+const DefaultTieringDemoteAfterDays = 30
+
+// TieringConfig moves the data files of archives that have not been read for
+// DemoteAfterDays to ColdPath, a slower disk or a mounted NAS. An empty
+// ColdPath disables tiering.
+type TieringConfig struct {
+	ColdPath        string `toml:"cold-path,omitempty"`
+	DemoteAfterDays int    `toml:"demote-after-days,omitempty"`
+}
+
+// DefaultChunkingAvgArchiveSize is the average archive size content-defined
+// chunking aims for when neither `avg-archive-size` nor `max-pack-size` is
+// set.
+const DefaultChunkingAvgArchiveSize = 64 << 20
+
+// ChunkingConfig keeps repacked archives friendly to rsync and backup dedup.
+// With ContentDefined, an archive ends after a blob picked by its digest
+// rather than once MaxPackSize fills up, so archives average AvgArchiveSize
+// and the same blobs always fall into the same archives. Align pads entries
+// to start at multiples of Align bytes.
+type ChunkingConfig struct {
+	ContentDefined bool   `toml:"content-defined,omitempty"`
+	AvgArchiveSize uint64 `toml:"avg-archive-size,omitempty"`
+	Align          uint64 `toml:"align,omitempty"`
+}
+
 type TomlInventoryArchiveV2 struct {
 	HashTypeId      HashType                         `toml:"hash_type-id"`
 	CompressionType compression_type.CompressionType `toml:"compression-type"`
+	Compression     CompressionConfig                `toml:"compression"`
 	Encryption      markl.Id                         `toml:"encryption"`
 	Delta           DeltaConfig                      `toml:"delta"`
 	MaxPackSize     uint64                           `toml:"max-pack-size"`
+	FooterIndex     bool                             `toml:"footer-index"`
+	Tiering         TieringConfig                    `toml:"tiering,omitempty"`
+	Chunking        ChunkingConfig                   `toml:"chunking,omitempty"`
+
+	ReadOnlyConfig
 }
 
 var (
-	_ ConfigInventoryArchiveDelta = TomlInventoryArchiveV2{}
-	_ ConfigMutable               = &TomlInventoryArchiveV2{}
-	_ SignatureConfigImmutable    = TomlInventoryArchiveV2{}
-	_ SelectorConfigImmutable     = TomlInventoryArchiveV2{}
-	_                             = registerToml[TomlInventoryArchiveV2](
+	_ ConfigInventoryArchiveDelta  = TomlInventoryArchiveV2{}
+	_ ConfigMutable                = &TomlInventoryArchiveV2{}
+	_ SignatureConfigImmutable     = TomlInventoryArchiveV2{}
+	_ SelectorConfigImmutable      = TomlInventoryArchiveV2{}
+	_ DeltaStrategyConfigImmutable = TomlInventoryArchiveV2{}
+	_ CompressionConfigImmutable   = TomlInventoryArchiveV2{}
+	_ FooterIndexConfigImmutable   = TomlInventoryArchiveV2{}
+	_ TieringConfigImmutable       = TomlInventoryArchiveV2{}
+	_ ChunkingConfigImmutable      = TomlInventoryArchiveV2{}
+	_ ConfigReadOnly               = TomlInventoryArchiveV2{}
+	_                              = registerToml[TomlInventoryArchiveV2](
 		Coder.Blob,
 		ids.TypeTomlBlobStoreConfigInventoryArchiveV2,
 	)
@@ -37,6 +91,27 @@ func (config *TomlInventoryArchiveV2) SetFlagDefinitions(
 ) {
 	config.CompressionType.SetFlagDefinitions(flagSet)
 
+	flagSet.IntVar(
+		&config.Compression.Level,
+		"compression-level",
+		compression_type.ZstdLevelDefault,
+		"zstd compression level (1-22, 0 for the zstd default)",
+	)
+
+	flagSet.BoolVar(
+		&config.Compression.Dictionary,
+		"compression-dictionary",
+		false,
+		"train a zstd dictionary for each archive when packing",
+	)
+
+	flagSet.IntVar(
+		&config.Compression.MinSavingsPercent,
+		"compression-min-savings-percent",
+		0,
+		"store blobs uncompressed unless compression saves more than this percentage",
+	)
+
 	config.HashTypeId = HashTypeDefault
 
 	flagSet.Var(
@@ -53,6 +128,43 @@ func (config *TomlInventoryArchiveV2) SetFlagDefinitions(
 		false,
 		"enable delta compression",
 	)
+
+	flagSet.StringVar(
+		&config.Delta.Strategy,
+		"delta-strategy",
+		"size",
+		`how to choose delta bases: "size" or "similarity"`,
+	)
+
+	flagSet.BoolVar(
+		&config.FooterIndex,
+		"footer-index",
+		true,
+		"write v2 archives that embed their index instead of a separate index file",
+	)
+
+	flagSet.BoolVar(
+		&config.Chunking.ContentDefined,
+		"chunking-content-defined",
+		false,
+		"end archives at boundaries picked by blob digests rather than by size",
+	)
+
+	flagSet.Func(
+		"chunking-align",
+		"pad archive entries to start at multiples of this size (e.g. 4K, 0 = none)",
+		func(value string) (err error) {
+			var bytes ui.HumanReadableBytes
+
+			if err = bytes.Set(value); err != nil {
+				return err
+			}
+
+			config.Chunking.Align = bytes.GetByteCount()
+
+			return err
+		},
+	)
 }
 
 func (config TomlInventoryArchiveV2) getBasePath() string {
@@ -84,6 +196,67 @@ func (config TomlInventoryArchiveV2) GetCompressionType() compression_type.Compr
 	return config.CompressionType
 }
 
+// CompressionConfigImmutable implementation
+
+func (config TomlInventoryArchiveV2) GetCompressionLevel() int {
+	return config.Compression.Level
+}
+
+func (config TomlInventoryArchiveV2) GetCompressionDictionaryEnabled() bool {
+	return config.Compression.Dictionary
+}
+
+func (config TomlInventoryArchiveV2) GetCompressionDictionaryMaxSize() uint64 {
+	return config.Compression.DictionaryMaxSize
+}
+
+func (config TomlInventoryArchiveV2) GetCompressionMinSavingsPercent() int {
+	return config.Compression.MinSavingsPercent
+}
+
+// FooterIndexConfigImmutable implementation
+
+func (config TomlInventoryArchiveV2) GetFooterIndexEnabled() bool {
+	return config.FooterIndex
+}
+
+// TieringConfigImmutable implementation
+
+func (config TomlInventoryArchiveV2) GetTieringColdPath() string {
+	return config.Tiering.ColdPath
+}
+
+func (config TomlInventoryArchiveV2) GetTieringDemoteAfterDays() int {
+	if config.Tiering.DemoteAfterDays == 0 {
+		return DefaultTieringDemoteAfterDays
+	}
+
+	return config.Tiering.DemoteAfterDays
+}
+
+// ChunkingConfigImmutable implementation
+
+func (config TomlInventoryArchiveV2) GetChunkingContentDefined() bool {
+	return config.Chunking.ContentDefined
+}
+
+func (config TomlInventoryArchiveV2) GetChunkingAvgArchiveSize() uint64 {
+	switch {
+	case config.Chunking.AvgArchiveSize != 0:
+		return config.Chunking.AvgArchiveSize
+
+	case config.MaxPackSize != 0:
+		return max(config.MaxPackSize/4, 1)
+
+	default:
+		return DefaultChunkingAvgArchiveSize
+	}
+}
+
+func (config TomlInventoryArchiveV2) GetChunkingAlign() uint64 {
+	return config.Chunking.Align
+}
+
 // DeltaConfigImmutable implementation
 
 func (config TomlInventoryArchiveV2) GetDeltaEnabled() bool {
@@ -128,6 +301,24 @@ func (config TomlInventoryArchiveV2) GetMaxChunkSize() int {
 	return config.Delta.Signature.MaxChunkSize
 }
 
+// DeltaStrategyConfigImmutable implementation
+
+func (config TomlInventoryArchiveV2) GetDeltaStrategy() string {
+	return config.Delta.Strategy
+}
+
+func (config TomlInventoryArchiveV2) GetSimilaritySketchSize() int {
+	return config.Delta.Similarity.SketchSize
+}
+
+func (config TomlInventoryArchiveV2) GetSimilarityShingleSize() int {
+	return config.Delta.Similarity.ShingleSize
+}
+
+func (config TomlInventoryArchiveV2) GetSimilarityMinSimilarity() float64 {
+	return config.Delta.Similarity.MinSimilarity
+}
+
 // SelectorConfigImmutable implementation
 
 func (config TomlInventoryArchiveV2) GetSelectorType() string {
@@ -0,0 +1,132 @@
+package blob_store_configs
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/directory_layout"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/values"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+// TomlLazyParentV0 is a local hash-bucketed store that fetches blobs it is
+// missing from a parent store (referenced the same way as TomlPointerV0) and
+// keeps a local copy.
+type TomlLazyParentV0 struct {
+	HashBuckets values.IntSlice `toml:"hash_buckets"`
+	HashTypeId  HashType        `toml:"hash_type-id"`
+
+	Encryption []markl.Id `toml:"encryption"`
+
+	RetiredEncryptionConfig
+
+	CompressionType   compression_type.CompressionType `toml:"compression-type"`
+	LockInternalFiles bool                             `toml:"lock-internal-files"`
+
+	ParentId         blob_store_id.Id `toml:"parent-id"`
+	ParentBasePath   string           `toml:"parent-base-path"`
+	ParentConfigPath string           `toml:"parent-config-path"`
+}
+
+var (
+	_ ConfigLazyParent               = TomlLazyParentV0{}
+	_ ConfigMutable                  = &TomlLazyParentV0{}
+	_ ConfigEncryptionKeyRingMutable = &TomlLazyParentV0{}
+	_                                = registerToml[TomlLazyParentV0](
+		Coder.Blob,
+		ids.TypeTomlBlobStoreConfigLazyParentV0,
+	)
+)
+
+func (TomlLazyParentV0) GetBlobStoreType() string {
+	return "local-lazy_parent"
+}
+
+func (blobStoreConfig *TomlLazyParentV0) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	blobStoreConfig.CompressionType.SetFlagDefinitions(flagSet)
+
+	blobStoreConfig.HashBuckets = DefaultHashBuckets
+
+	flagSet.Var(
+		&blobStoreConfig.HashBuckets,
+		"hash_buckets",
+		"determines hash bucketing directory structure",
+	)
+
+	blobStoreConfig.HashTypeId = HashTypeDefault
+
+	flagSet.Var(
+		&blobStoreConfig.HashTypeId,
+		"hash_type-id",
+		"determines the hash type used for new blobs written to the store",
+	)
+
+	setMultiEncryptionFlagDefinition(flagSet, &blobStoreConfig.Encryption)
+
+	flagSet.BoolVar(
+		&blobStoreConfig.LockInternalFiles,
+		"lock-internal-files",
+		blobStoreConfig.LockInternalFiles,
+		"",
+	)
+
+	flagSet.Var(
+		&blobStoreConfig.ParentId,
+		"parent-id",
+		"the parent blob store's id",
+	)
+
+	flagSet.StringVar(
+		&blobStoreConfig.ParentBasePath,
+		"parent-base-path",
+		"",
+		"path to the parent blob store base directory",
+	)
+
+	flagSet.StringVar(
+		&blobStoreConfig.ParentConfigPath,
+		"parent-config-path",
+		"",
+		"path to the parent blob store config file",
+	)
+}
+
+func (blobStoreConfig TomlLazyParentV0) getBasePath() string {
+	return ""
+}
+
+func (blobStoreConfig TomlLazyParentV0) GetHashBuckets() []int {
+	return blobStoreConfig.HashBuckets
+}
+
+func (blobStoreConfig TomlLazyParentV0) GetBlobCompression() interfaces.IOWrapper {
+	return &blobStoreConfig.CompressionType
+}
+
+func (blobStoreConfig TomlLazyParentV0) GetBlobEncryption() domain_interfaces.MarklId {
+	return blobStoreConfig.GetEncryptionKeyRing()
+}
+
+func (blobStoreConfig TomlLazyParentV0) GetLockInternalFiles() bool {
+	return blobStoreConfig.LockInternalFiles
+}
+
+func (blobStoreConfig TomlLazyParentV0) SupportsMultiHash() bool {
+	return true
+}
+
+func (blobStoreConfig TomlLazyParentV0) GetDefaultHashTypeId() string {
+	return string(blobStoreConfig.HashTypeId)
+}
+
+func (blobStoreConfig TomlLazyParentV0) GetParentPath() directory_layout.BlobStorePath {
+	return directory_layout.MakeBlobStorePath(
+		blobStoreConfig.ParentId,
+		blobStoreConfig.ParentBasePath,
+		blobStoreConfig.ParentConfigPath,
+	)
+}
@@ -12,11 +12,18 @@ type TomlSFTPV0 struct {
 	Password       string `toml:"password,omitempty"`
 	PrivateKeyPath string `toml:"private-key-path,omitempty"`
 	RemotePath     string `toml:"remote-path"`
+
+	BudgetConfig
+	TransferConfig
+	ReadOnlyConfig
 }
 
 var (
 	_ ConfigSFTPRemotePath = &TomlSFTPV0{}
 	_ ConfigMutable        = &TomlSFTPV0{}
+	_ ConfigBudget         = &TomlSFTPV0{}
+	_ ConfigTransfer       = &TomlSFTPV0{}
+	_ ConfigReadOnly       = &TomlSFTPV0{}
 )
 
 func (*TomlSFTPV0) GetBlobStoreType() string {
@@ -67,6 +74,9 @@ func (blobStoreConfig *TomlSFTPV0) SetFlagDefinitions(
 		blobStoreConfig.RemotePath,
 		"Remote path for blob storage",
 	)
+
+	blobStoreConfig.BudgetConfig.SetFlagDefinitions(flagSet)
+	blobStoreConfig.TransferConfig.SetFlagDefinitions(flagSet)
 }
 
 func (blobStoreConfig *TomlSFTPV0) GetHost() string {
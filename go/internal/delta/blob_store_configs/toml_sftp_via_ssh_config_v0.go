@@ -4,11 +4,17 @@ import "code.linenisgreat.com/dodder/go/lib/_/interfaces"
 
 type TomlSFTPViaSSHConfigV0 struct {
 	TomlUriV0
+	BudgetConfig
+	TransferConfig
+	ReadOnlyConfig
 }
 
 var (
 	_ ConfigSFTPRemotePath = TomlSFTPViaSSHConfigV0{}
 	_ ConfigMutable        = &TomlSFTPViaSSHConfigV0{}
+	_ ConfigBudget         = TomlSFTPViaSSHConfigV0{}
+	_ ConfigTransfer       = TomlSFTPViaSSHConfigV0{}
+	_ ConfigReadOnly       = TomlSFTPViaSSHConfigV0{}
 )
 
 func (TomlSFTPViaSSHConfigV0) GetBlobStoreType() string {
@@ -19,6 +25,8 @@ func (config *TomlSFTPViaSSHConfigV0) SetFlagDefinitions(
 	flagSet interfaces.CLIFlagDefinitions,
 ) {
 	config.TomlUriV0.SetFlagDefinitions(flagSet)
+	config.BudgetConfig.SetFlagDefinitions(flagSet)
+	config.TransferConfig.SetFlagDefinitions(flagSet)
 }
 
 func (config TomlSFTPViaSSHConfigV0) GetRemotePath() string {
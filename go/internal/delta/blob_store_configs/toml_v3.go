@@ -15,14 +15,28 @@ type TomlV3 struct {
 
 	Encryption []markl.Id `toml:"encryption"`
 
+	RetiredEncryptionConfig
+
 	CompressionType   compression_type.CompressionType `toml:"compression-type"`
 	LockInternalFiles bool                             `toml:"lock-internal-files"`
+
+	BudgetConfig
+	TransferConfig
+	ChunkedBlobsConfig
+	InlineBlobsConfig
+	ReadOnlyConfig
 }
 
 var (
-	_ ConfigLocalHashBucketed = TomlV3{}
-	_ ConfigLocalMutable      = &TomlV3{}
-	_ ConfigMutable           = &TomlV3{}
+	_ ConfigLocalHashBucketed        = TomlV3{}
+	_ ConfigLocalMutable             = &TomlV3{}
+	_ ConfigMutable                  = &TomlV3{}
+	_ ConfigBudget                   = TomlV3{}
+	_ ConfigTransfer                 = TomlV3{}
+	_ ConfigChunkedBlobs             = TomlV3{}
+	_ ConfigInlineBlobs              = TomlV3{}
+	_ ConfigReadOnly                 = TomlV3{}
+	_ ConfigEncryptionKeyRingMutable = &TomlV3{}
 )
 
 func (TomlV3) GetBlobStoreType() string {
@@ -58,6 +72,11 @@ func (blobStoreConfig *TomlV3) SetFlagDefinitions(
 		blobStoreConfig.LockInternalFiles,
 		"",
 	)
+
+	blobStoreConfig.BudgetConfig.SetFlagDefinitions(flagSet)
+	blobStoreConfig.TransferConfig.SetFlagDefinitions(flagSet)
+	blobStoreConfig.ChunkedBlobsConfig.SetFlagDefinitions(flagSet)
+	blobStoreConfig.InlineBlobsConfig.SetFlagDefinitions(flagSet)
 }
 
 func (blobStoreConfig TomlV3) getBasePath() string {
@@ -73,7 +92,7 @@ func (blobStoreConfig TomlV3) GetBlobCompression() interfaces.IOWrapper {
 }
 
 func (blobStoreConfig TomlV3) GetBlobEncryption() domain_interfaces.MarklId {
-	return EncryptionKeys(blobStoreConfig.Encryption)
+	return blobStoreConfig.GetEncryptionKeyRing()
 }
 
 func (blobStoreConfig TomlV3) GetLockInternalFiles() bool {
@@ -0,0 +1,51 @@
+package blob_store_configs
+
+import (
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+// TransferConfig caps the copies a store takes part in, as source or
+// destination: how many run at once and how many bytes per second they move
+// between them. Zero means no cap.
+type TransferConfig struct {
+	MaxParallelTransfers int    `toml:"max-parallel-transfers,omitempty"`
+	MaxBytesPerSecond    uint64 `toml:"max-bytes-per-second,omitempty"`
+}
+
+var _ ConfigTransfer = TransferConfig{}
+
+func (config TransferConfig) GetMaxParallelTransfers() int {
+	return config.MaxParallelTransfers
+}
+
+func (config TransferConfig) GetMaxBytesPerSecond() uint64 {
+	return config.MaxBytesPerSecond
+}
+
+func (config *TransferConfig) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.IntVar(
+		&config.MaxParallelTransfers,
+		"max-parallel-transfers",
+		0,
+		"copies to or from the store that may run at once (0 = unlimited)",
+	)
+
+	flagSet.Func(
+		"max-bytes-per-second",
+		"cap the bytes per second copied to or from the store (e.g. 5M, 0 = unlimited)",
+		func(value string) (err error) {
+			var bytes ui.HumanReadableBytes
+
+			if err = bytes.Set(value); err != nil {
+				return err
+			}
+
+			config.MaxBytesPerSecond = bytes.GetByteCount()
+
+			return err
+		},
+	)
+}
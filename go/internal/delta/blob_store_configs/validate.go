@@ -0,0 +1,80 @@
+package blob_store_configs
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// Validate checks the values of config that its blob store only parses when
+// it uses them, like its hash type and delta strategy, and returns all of
+// their errors.
+func Validate(config Config) (err error) {
+	if configHashType, ok := config.(ConfigHashType); ok {
+		if hashTypeId := configHashType.GetDefaultHashTypeId(); hashTypeId != "" {
+			if _, hashErr := markl.GetFormatHashOrError(hashTypeId); hashErr != nil {
+				err = errors.Join(err, hashErr)
+			}
+		}
+	}
+
+	if configStrategy, ok := config.(DeltaStrategyConfigImmutable); ok {
+		switch strategy := configStrategy.GetDeltaStrategy(); strategy {
+		case "", "size", "similarity":
+
+		default:
+			err = errors.Join(
+				err,
+				errors.BadRequestf(
+					"delta.strategy: expected %q or %q, got %q",
+					"size",
+					"similarity",
+					strategy,
+				),
+			)
+		}
+	}
+
+	if configDelta, ok := config.(DeltaConfigImmutable); ok &&
+		configDelta.GetDeltaEnabled() {
+		minSize, maxSize := configDelta.GetDeltaMinBlobSize(),
+			configDelta.GetDeltaMaxBlobSize()
+
+		if maxSize != 0 && minSize > maxSize {
+			err = errors.Join(
+				err,
+				errors.BadRequestf(
+					"delta.min-blob-size %d is larger than delta.max-blob-size %d",
+					minSize,
+					maxSize,
+				),
+			)
+		}
+	}
+
+	if configKeyRing, ok := config.(ConfigEncryptionKeyRing); ok {
+		if ring := configKeyRing.GetEncryptionKeyRing(); ring.HasRetired() &&
+			ring.EncryptionKeys.IsNull() {
+			err = errors.Join(
+				err,
+				errors.BadRequestf(
+					"retired-encryption requires at least one encryption key",
+				),
+			)
+		}
+	}
+
+	if configBudget, ok := config.(ConfigBudget); ok {
+		if percent := configBudget.GetWarnAtPercent(); percent < 0 ||
+			percent > 100 {
+			err = errors.Join(
+				err,
+				errors.BadRequestf(
+					"warn-at-percent: expected a percentage, got %d",
+					percent,
+				),
+			)
+		}
+	}
+
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/alfa/string_format_writer"
 	"code.linenisgreat.com/dodder/go/internal/charlie/fd"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/structured_log"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 	"code.linenisgreat.com/dodder/go/lib/echo/debug"
@@ -118,6 +119,14 @@ func Make(
 		ui.SetOutput(io.Discard)
 	}
 
+	if cliConfig != nil && !cliConfig.GetQuiet() {
+		structured_log.Configure(
+			env.GetErrFile(),
+			structured_log.Verbosity(cliConfig.GetLogVerbosity()),
+			os.Getenv(structured_log.EnvVarFormat),
+		)
+	}
+
 	if cliConfig != nil && cliConfig.GetTodo() {
 		ui.SetTodoOn()
 	}
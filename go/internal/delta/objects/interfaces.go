@@ -35,6 +35,7 @@ type (
 		GetIndex() Index
 		GetTags() TagSet
 		AllTags() interfaces.Seq[Tag]
+		GetProvenance() Provenance
 		GetTai() ids.Tai
 		GetType() Type
 		GetTypeLock() TypeLock
@@ -68,6 +69,7 @@ type (
 		GetMotherObjectSigMutable() domain_interfaces.MarklIdMutable
 		GetObjectDigestMutable() domain_interfaces.MarklIdMutable
 		GetObjectSigMutable() domain_interfaces.MarklIdMutable
+		GetProvenanceMutable() *Provenance
 		GetRepoPubKeyMutable() domain_interfaces.MarklIdMutable
 		GetTaiMutable() *ids.Tai
 		GetTypeMutable() TypeMutable
@@ -32,6 +32,8 @@ type metadata struct {
 
 	Tai ids.Tai
 
+	Provenance Provenance
+
 	Index index
 }
 
@@ -80,6 +82,14 @@ func (metadata *metadata) GetTaiMutable() *ids.Tai {
 	return &metadata.Tai
 }
 
+func (metadata *metadata) GetProvenance() Provenance {
+	return metadata.Provenance
+}
+
+func (metadata *metadata) GetProvenanceMutable() *Provenance {
+	return &metadata.Provenance
+}
+
 func (metadata *metadata) UserInputIsEmpty() bool {
 	if !metadata.Description.IsEmpty() {
 		return false
@@ -166,8 +176,7 @@ func (metadata *metadata) AddTagPtr(tag Tag) (err error) {
 	}
 
 	metadata.Tags.addNormalizedTag(tag)
-	cs, _ := catgut.MakeFromString(tag.String())
-	metadata.Index.TagPaths.AddTag(cs)
+	metadata.Index.TagPaths.AddTag(catgut.Intern(tag.String()))
 
 	return err
 }
@@ -175,9 +184,9 @@ func (metadata *metadata) AddTagPtr(tag Tag) (err error) {
 func (metadata *metadata) AddTagPtrFast(tag Tag) (err error) {
 	ids.TagSetMutableAdd(metadata.GetTagsMutable(), tag)
 
-	tagBytestring, _ := catgut.MakeFromString(tag.String())
-
-	if err = metadata.Index.TagPaths.AddTag(tagBytestring); err != nil {
+	if err = metadata.Index.TagPaths.AddTag(
+		catgut.Intern(tag.String()),
+	); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
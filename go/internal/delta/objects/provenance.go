@@ -0,0 +1,112 @@
+package objects
+
+import (
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const (
+	ProvenanceKeyRepo     = "repo"
+	ProvenanceKeyHostname = "host"
+	ProvenanceKeyVersion  = "dodder"
+)
+
+// Provenance is where an inventory list was committed: the id of the repo,
+// the hostname of the machine, and the dodder version that wrote it. It is
+// part of the object digest, so it is signed with the rest of the metadata.
+type Provenance struct {
+	RepoId   string `json:"repo-id,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+	Version  string `json:"version,omitempty"`
+}
+
+func (provenance Provenance) IsEmpty() bool {
+	return provenance == Provenance{}
+}
+
+// Pairs returns the non-empty fields as key and value pairs, in the order
+// they are written in.
+func (provenance Provenance) Pairs() (pairs [][2]string) {
+	for _, pair := range [][2]string{
+		{ProvenanceKeyRepo, provenance.RepoId},
+		{ProvenanceKeyHostname, provenance.Hostname},
+		{ProvenanceKeyVersion, provenance.Version},
+	} {
+		if pair[1] != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
+
+// String returns the fields as space separated `key=value` pairs.
+func (provenance Provenance) String() string {
+	var sb strings.Builder
+
+	for i, pair := range provenance.Pairs() {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+
+		sb.WriteString(pair[0])
+		sb.WriteByte('=')
+		sb.WriteString(pair[1])
+	}
+
+	return sb.String()
+}
+
+// Set parses the output of String.
+func (provenance *Provenance) Set(value string) (err error) {
+	*provenance = Provenance{}
+
+	for pair := range strings.FieldsSeq(value) {
+		key, value, ok := strings.Cut(pair, "=")
+
+		if !ok {
+			err = errors.BadRequestf("provenance field without a value: %q", pair)
+			return err
+		}
+
+		if ok, err = provenance.SetField(key, value); err != nil {
+			err = errors.Wrap(err)
+			return err
+		} else if !ok {
+			err = errors.BadRequestf("unknown provenance field: %q", key)
+			return err
+		}
+	}
+
+	return err
+}
+
+// SetField sets the field for key, reporting false if key is not one of the
+// provenance keys.
+func (provenance *Provenance) SetField(
+	key, value string,
+) (ok bool, err error) {
+	if strings.ContainsAny(value, " \n") {
+		err = errors.BadRequestf("provenance %s contains whitespace: %q", key, value)
+		return ok, err
+	}
+
+	switch key {
+	case ProvenanceKeyRepo:
+		provenance.RepoId = value
+
+	case ProvenanceKeyHostname:
+		provenance.Hostname = value
+
+	case ProvenanceKeyVersion:
+		provenance.Version = value
+
+	default:
+		return ok, err
+	}
+
+	ok = true
+
+	return ok, err
+}
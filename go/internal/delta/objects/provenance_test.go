@@ -0,0 +1,51 @@
+package objects
+
+import "testing"
+
+func TestProvenanceRoundTrip(t *testing.T) {
+	expected := Provenance{
+		RepoId:   "laptop",
+		Hostname: "laptop.local",
+		Version:  "v0.0.1",
+	}
+
+	value := expected.String()
+
+	if value != "repo=laptop host=laptop.local dodder=v0.0.1" {
+		t.Errorf("unexpected string: %q", value)
+	}
+
+	var actual Provenance
+
+	if err := actual.Set(value); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if actual != expected {
+		t.Errorf("expected %#v but got %#v", expected, actual)
+	}
+}
+
+func TestProvenanceOmitsEmptyFields(t *testing.T) {
+	provenance := Provenance{Hostname: "desktop"}
+
+	if value := provenance.String(); value != "host=desktop" {
+		t.Errorf("unexpected string: %q", value)
+	}
+
+	if (Provenance{}).String() != "" || !(Provenance{}).IsEmpty() {
+		t.Errorf("expected the empty provenance to have no fields")
+	}
+}
+
+func TestProvenanceRejectsUnknownFields(t *testing.T) {
+	var provenance Provenance
+
+	if err := provenance.Set("user=someone"); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+
+	if err := provenance.Set("host"); err == nil {
+		t.Errorf("expected an error for a field without a value")
+	}
+}
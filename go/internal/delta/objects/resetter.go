@@ -14,6 +14,7 @@ func (resetter) Reset(metadatuh MetadataMutable) {
 		resetIndex(&metadata.Index)
 		metadata.Type.Reset()
 		metadata.Tai.Reset()
+		metadata.Provenance = Provenance{}
 		metadata.DigBlob.Reset()
 		metadata.digSelf.Reset()
 		metadata.sigMother.Reset()
@@ -36,6 +37,7 @@ func (resetter) ResetWithExceptFields(dst MetadataMutable, src Metadata) {
 
 		dst.Type.ResetWith(src.Type)
 		dst.Tai = src.Tai
+		dst.Provenance = src.Provenance
 
 		dst.DigBlob.ResetWith(src.DigBlob)
 		dst.digSelf.ResetWith(src.digSelf)
@@ -0,0 +1,325 @@
+package repo_configs
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// Layer is where a layered config value came from. Later layers override
+// earlier ones.
+type Layer int
+
+const (
+	LayerBuiltin = Layer(iota)
+	LayerRepo
+	LayerWorkspace
+	LayerEnv
+	LayerFlags
+)
+
+func (layer Layer) String() string {
+	switch layer {
+	case LayerBuiltin:
+		return "builtin"
+
+	case LayerRepo:
+		return "repo"
+
+	case LayerWorkspace:
+		return "workspace"
+
+	case LayerEnv:
+		return "env"
+
+	case LayerFlags:
+		return "flags"
+
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	LayeredKeyDefaultType = "defaults.type"
+
+	// unlike the other keys, the tags of every layer are combined, so that a
+	// workspace adds to the repo's default tags rather than replacing them
+	LayeredKeyDefaultTags = "defaults.tags"
+
+	LayeredKeyEditor = "editor"
+)
+
+// LayeredKeys are the keys a workspace, the environment, or `-config` may
+// override, in the order `config-show` prints them.
+var LayeredKeys = []string{
+	LayeredKeyDefaultType,
+	LayeredKeyDefaultTags,
+	LayeredKeyEditor,
+}
+
+// LayeredEnvVars are the environment variables of the env layer, by key.
+var LayeredEnvVars = map[string]string{
+	LayeredKeyDefaultType: "DODDER_DEFAULT_TYPE",
+	LayeredKeyDefaultTags: "DODDER_DEFAULT_TAGS",
+	LayeredKeyEditor:      "DODDER_EDITOR",
+}
+
+type LayeredValue struct {
+	Layer Layer
+
+	// the file, variable, or flag the value was read from
+	Source string
+
+	Value string
+}
+
+// Layered resolves the keys in LayeredKeys from every layer that sets them,
+// and remembers each layer's value so that they can be explained.
+type Layered struct {
+	getenv func(string) string
+	values map[string][]LayeredValue
+}
+
+func MakeLayered() Layered {
+	return MakeLayeredWithGetenv(os.Getenv)
+}
+
+// MakeLayeredWithGetenv is MakeLayered reading environment variables from
+// getenv.
+func MakeLayeredWithGetenv(getenv func(string) string) Layered {
+	return Layered{
+		getenv: getenv,
+		values: make(map[string][]LayeredValue),
+	}
+}
+
+// Set records value for key from source at layer. Values from the repo and
+// workspace files have `$VAR` and `${VAR}` expanded. Empty values leave the
+// key to the earlier layers.
+func (layered *Layered) Set(
+	key string,
+	layer Layer,
+	source string,
+	value string,
+) (err error) {
+	if !slices.Contains(LayeredKeys, key) {
+		err = errors.BadRequestf(
+			"unsupported config key: %q. Expected one of %q",
+			key,
+			LayeredKeys,
+		)
+
+		return err
+	}
+
+	if layer == LayerRepo || layer == LayerWorkspace {
+		value = os.Expand(value, layered.getenv)
+	}
+
+	value = strings.TrimSpace(value)
+
+	if value == "" {
+		return err
+	}
+
+	layered.values[key] = append(
+		layered.values[key],
+		LayeredValue{
+			Layer:  layer,
+			Source: source,
+			Value:  value,
+		},
+	)
+
+	return err
+}
+
+// SetBuiltin records the builtin layer, which is the editor of `$EDITOR`,
+// `$VISUAL`, or vim.
+func (layered *Layered) SetBuiltin() (err error) {
+	source, editor := "$EDITOR", layered.getenv("EDITOR")
+
+	if editor == "" {
+		source, editor = "$VISUAL", layered.getenv("VISUAL")
+	}
+
+	if editor == "" {
+		source, editor = "default", "vim"
+	}
+
+	if err = layered.Set(
+		LayeredKeyEditor,
+		LayerBuiltin,
+		source,
+		editor,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// SetDefaults records the default type and tags of defaults at layer.
+func (layered *Layered) SetDefaults(
+	layer Layer,
+	source string,
+	defaults Defaults,
+) (err error) {
+	if err = layered.Set(
+		LayeredKeyDefaultType,
+		layer,
+		source,
+		defaults.GetDefaultType().String(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	tags := make([]string, 0, defaults.GetDefaultTags().Len())
+
+	for tag := range defaults.GetDefaultTags().All() {
+		tags = append(tags, tag.String())
+	}
+
+	if err = layered.Set(
+		LayeredKeyDefaultTags,
+		layer,
+		source,
+		strings.Join(tags, ","),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// SetFromEnv records the env layer from LayeredEnvVars.
+func (layered *Layered) SetFromEnv() (err error) {
+	for _, key := range LayeredKeys {
+		envVar := LayeredEnvVars[key]
+
+		if err = layered.Set(
+			key,
+			LayerEnv,
+			"$"+envVar,
+			layered.getenv(envVar),
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
+
+// SetFromFlags records the flags layer from `-config key=value` overrides.
+func (layered *Layered) SetFromFlags(overrides []string) (err error) {
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+
+		if !ok {
+			err = errors.BadRequestf(
+				"expected -config in the form key=value, got %q",
+				override,
+			)
+
+			return err
+		}
+
+		if err = layered.Set(
+			strings.TrimSpace(key),
+			LayerFlags,
+			"-config",
+			value,
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
+
+// GetAll returns every layer's value for key, from the earliest layer to the
+// latest.
+func (layered Layered) GetAll(key string) []LayeredValue {
+	values := slices.Clone(layered.values[key])
+
+	slices.SortStableFunc(values, func(a, b LayeredValue) int {
+		return int(a.Layer) - int(b.Layer)
+	})
+
+	return values
+}
+
+// Get returns the value of key from the latest layer that sets it.
+func (layered Layered) Get(key string) (value LayeredValue, ok bool) {
+	values := layered.GetAll(key)
+
+	if len(values) == 0 {
+		return value, ok
+	}
+
+	return values[len(values)-1], true
+}
+
+// GetString returns the effective value of key as `config-show` prints it,
+// which for defaults.tags is the tags of every layer.
+func (layered Layered) GetString(key string) string {
+	if key == LayeredKeyDefaultTags {
+		return strings.Join(layered.getTags(), ",")
+	}
+
+	value, _ := layered.Get(key)
+
+	return value.Value
+}
+
+func (layered Layered) getTags() (tags []string) {
+	for _, value := range layered.GetAll(LayeredKeyDefaultTags) {
+		for tag := range strings.FieldsFuncSeq(
+			value.Value,
+			func(r rune) bool {
+				return r == ',' || r == ' '
+			},
+		) {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// GetDefaults returns the default type of the latest layer that sets one,
+// and the default tags of every layer.
+func (layered Layered) GetDefaults() (defaults DefaultsV1, err error) {
+	defaults.Tags = make([]ids.TagStruct, 0)
+
+	if value, ok := layered.Get(LayeredKeyDefaultType); ok {
+		if err = defaults.Type.Set(value.Value); err != nil {
+			err = errors.Wrapf(err, "%s from %s", value.Layer, value.Source)
+			return defaults, err
+		}
+	}
+
+	for _, value := range layered.getTags() {
+		var tag ids.TagStruct
+
+		if tag, err = ids.MakeTag(value); err != nil {
+			err = errors.Wrapf(err, "%s", LayeredKeyDefaultTags)
+			return defaults, err
+		}
+
+		defaults.Tags = append(defaults.Tags, tag)
+	}
+
+	return defaults, err
+}
@@ -0,0 +1,84 @@
+//go:build test
+
+package repo_configs
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func TestLayeredLaterLayersOverride(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	env := map[string]string{
+		"EDITOR":              "vi",
+		"HOME":                "/home/someone",
+		"DODDER_DEFAULT_TYPE": "txt",
+	}
+
+	layered := MakeLayeredWithGetenv(func(key string) string {
+		return env[key]
+	})
+
+	t.AssertNoError(layered.SetBuiltin())
+
+	t.AssertNoError(layered.SetDefaults(
+		LayerRepo,
+		"konfig",
+		DefaultsV1{
+			Type: ids.MustTypeStruct("md"),
+			Tags: []ids.TagStruct{ids.MustTag("repo")},
+		},
+	))
+
+	t.AssertNoError(layered.Set(
+		LayeredKeyEditor,
+		LayerWorkspace,
+		".dodder-workspace",
+		"$HOME/bin/nvim",
+	))
+
+	t.AssertNoError(layered.SetDefaults(
+		LayerWorkspace,
+		".dodder-workspace",
+		DefaultsV1OmitEmpty{
+			Tags: []ids.TagStruct{ids.MustTag("workspace")},
+		},
+	))
+
+	t.AssertNoError(layered.SetFromEnv())
+
+	if editor := layered.GetString(LayeredKeyEditor); editor != "/home/someone/bin/nvim" {
+		t.Errorf("expected the workspace's expanded editor, got %q", editor)
+	}
+
+	t.AssertNoError(layered.SetFromFlags([]string{"editor=emacs"}))
+
+	value, ok := layered.Get(LayeredKeyEditor)
+
+	if !ok || value.Layer != LayerFlags || value.Value != "emacs" {
+		t.Errorf("expected the flag's editor, got %#v", value)
+	}
+
+	if all := layered.GetAll(LayeredKeyEditor); len(all) != 3 ||
+		all[0].Source != "$EDITOR" {
+		t.Errorf("expected every editor layer from $EDITOR up, got %#v", all)
+	}
+
+	defaults, err := layered.GetDefaults()
+	t.AssertNoError(err)
+
+	if defaults.Type.String() != "!txt" {
+		t.Errorf("expected the env's type, got %q", defaults.Type)
+	}
+
+	if tags := layered.GetString(LayeredKeyDefaultTags); tags != "repo,workspace" {
+		t.Errorf("expected the tags of every layer, got %q", tags)
+	}
+
+	if err := layered.SetFromFlags([]string{"blob_store=other"}); err == nil {
+		t.Errorf("expected an unsupported key to be rejected")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
 	"code.linenisgreat.com/dodder/go/internal/charlie/triple_hyphen_io"
 	"code.linenisgreat.com/dodder/go/lib/bravo/collections_slice"
+	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
 )
 
 type (
@@ -28,6 +29,22 @@ type (
 	ConfigOverlay2 interface {
 		ConfigOverlay
 		GetDefaultBlobStoreId() blob_store_id.Id
+
+		// the editor command, like `$EDITOR`, used to edit objects, or empty to
+		// use `$EDITOR`
+		GetEditor() string
+
+		// when true, committing an object that references a type or tag without
+		// an object of its own also commits a placeholder object for it
+		GetAutoVivify() bool
+
+		GetStreamIndexOptions() StreamIndexV1
+		GetHooks() HooksV1
+		GetLuaOptions() LuaV1
+		GetVirtualTags() VirtualTagsV1
+		GetTrash() TrashV1
+		GetMaintenance() MaintenanceV1
+		GetBlobStoreRoutes() []BlobStoreRouteV1
 	}
 
 	Defaults interface {
@@ -83,3 +100,75 @@ func GetDefaultBlobStoreId(
 		return otherwise
 	}
 }
+
+func GetEditor(config ConfigOverlay) string {
+	if config, ok := config.(ConfigOverlay2); ok {
+		return config.GetEditor()
+	} else {
+		return ""
+	}
+}
+
+func GetAutoVivify(config ConfigOverlay) bool {
+	if config, ok := config.(ConfigOverlay2); ok {
+		return config.GetAutoVivify()
+	} else {
+		return false
+	}
+}
+
+func GetStreamIndexOptions(config ConfigOverlay) StreamIndexV1 {
+	if config, ok := config.(ConfigOverlay2); ok {
+		return config.GetStreamIndexOptions()
+	} else {
+		return StreamIndexV1{}
+	}
+}
+
+func GetHooks(config ConfigOverlay) HooksV1 {
+	if config, ok := config.(ConfigOverlay2); ok {
+		return config.GetHooks()
+	} else {
+		return HooksV1{}
+	}
+}
+
+func GetLuaLimits(config ConfigOverlay) lua.Limits {
+	if config, ok := config.(ConfigOverlay2); ok {
+		return config.GetLuaOptions().GetLimits()
+	} else {
+		return LuaV1{}.GetLimits()
+	}
+}
+
+func GetVirtualTags(config ConfigOverlay) VirtualTagsV1 {
+	if config, ok := config.(ConfigOverlay2); ok {
+		return config.GetVirtualTags()
+	} else {
+		return VirtualTagsV1{}
+	}
+}
+
+func GetTrash(config ConfigOverlay) TrashV1 {
+	if config, ok := config.(ConfigOverlay2); ok {
+		return config.GetTrash()
+	} else {
+		return TrashV1{}
+	}
+}
+
+func GetMaintenance(config ConfigOverlay) MaintenanceV1 {
+	if config, ok := config.(ConfigOverlay2); ok {
+		return config.GetMaintenance()
+	} else {
+		return MaintenanceV1{}
+	}
+}
+
+func GetBlobStoreRoutes(config ConfigOverlay) []BlobStoreRouteV1 {
+	if config, ok := config.(ConfigOverlay2); ok {
+		return config.GetBlobStoreRoutes()
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,519 @@
+package repo_configs
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// SchemaKey is a key of a konfig blob: the dotted path of its toml tables and
+// field, and the kind of value it takes.
+type SchemaKey struct {
+	Key  string
+	Type string
+}
+
+var (
+	typeTextMarshaler   = reflect.TypeFor[encoding.TextMarshaler]()
+	typeTextUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// GetSchemaKeys returns the keys of config's version, in the order of its
+// fields.
+func GetSchemaKeys(config ConfigOverlay) []SchemaKey {
+	return getSchemaKeys(reflect.TypeOf(config), "", nil)
+}
+
+// GetKey returns the value of key in config, with lists joined by commas, or
+// the empty string when it is unset.
+func GetKey(config ConfigOverlay, key string) (value string, err error) {
+	if err = assertSchemaKey(config, key); err != nil {
+		return value, err
+	}
+
+	var field reflect.Value
+
+	if field, err = lookupSchemaField(
+		reflect.ValueOf(config),
+		key,
+		false,
+	); err != nil {
+		err = errors.Wrap(err)
+		return value, err
+	}
+
+	if !field.IsValid() {
+		return value, err
+	}
+
+	value = formatSchemaValue(field)
+
+	return value, err
+}
+
+// SetKey returns a copy of config with key set to value, parsed as the type
+// of its field. Lists are comma-separated.
+func SetKey(
+	config ConfigOverlay,
+	key string,
+	value string,
+) (updated ConfigOverlay, err error) {
+	if err = assertSchemaKey(config, key); err != nil {
+		return updated, err
+	}
+
+	copied := copySchemaValue(reflect.ValueOf(config))
+
+	var field reflect.Value
+
+	if field, err = lookupSchemaField(copied, key, true); err != nil {
+		err = errors.Wrap(err)
+		return updated, err
+	}
+
+	if err = setSchemaValue(field, value); err != nil {
+		err = errors.BadRequestf("%s: %w", key, err)
+		return updated, err
+	}
+
+	updated = copied.Interface().(ConfigOverlay)
+
+	return updated, err
+}
+
+// UnsetKey returns a copy of config with key reset to its zero value, which
+// the repo treats as its default.
+func UnsetKey(
+	config ConfigOverlay,
+	key string,
+) (updated ConfigOverlay, err error) {
+	if _, err = GetKey(config, key); err != nil {
+		return updated, err
+	}
+
+	copied := copySchemaValue(reflect.ValueOf(config))
+
+	var field reflect.Value
+
+	if field, err = lookupSchemaField(copied, key, false); err != nil {
+		err = errors.Wrap(err)
+		return updated, err
+	}
+
+	// copy the tables on the way only when the key is set, so that unsetting
+	// an unset key adds no empty tables
+	if field.IsValid() {
+		if field, err = lookupSchemaField(copied, key, true); err != nil {
+			err = errors.Wrap(err)
+			return updated, err
+		}
+
+		field.Set(reflect.Zero(field.Type()))
+	}
+
+	updated = copied.Interface().(ConfigOverlay)
+
+	return updated, err
+}
+
+func assertSchemaKey(config ConfigOverlay, key string) (err error) {
+	for _, schemaKey := range GetSchemaKeys(config) {
+		if schemaKey.Key == key {
+			return err
+		}
+	}
+
+	err = errors.BadRequestf(
+		"unsupported config key: %q. See config-get for the keys of this config",
+		key,
+	)
+
+	return err
+}
+
+// Validate checks the values of config that are otherwise only parsed when
+// they are used, like durations, and returns all of their errors.
+func Validate(config ConfigOverlay) (err error) {
+	if _, ok := config.(ConfigOverlay2); !ok {
+		return err
+	}
+
+	if _, _, durationErr := GetStreamIndexOptions(config).GetRetention(); durationErr != nil {
+		err = errors.Join(err, durationErr)
+	}
+
+	if threshold := GetStreamIndexOptions(
+		config,
+	).CompactionThreshold; threshold < 0 || threshold > 1 {
+		err = errors.Join(
+			err,
+			errors.BadRequestf(
+				"stream-index.compaction-threshold: expected a ratio between 0 and 1, got %v",
+				threshold,
+			),
+		)
+	}
+
+	if _, durationErr := GetTrash(config).GetRetention(); durationErr != nil {
+		err = errors.Join(err, durationErr)
+	}
+
+	maintenance := GetMaintenance(config)
+
+	if _, durationErr := maintenance.GetInterval(); durationErr != nil {
+		err = errors.Join(err, durationErr)
+	}
+
+	if _, durationErr := maintenance.GetBudget(); durationErr != nil {
+		err = errors.Join(err, durationErr)
+	}
+
+	for i, route := range GetBlobStoreRoutes(config) {
+		if route.BlobStoreId.IsEmpty() {
+			err = errors.Join(
+				err,
+				errors.BadRequestf("blob_store-routes[%d]: missing blob_store-id", i),
+			)
+		}
+
+		if route.MaxSize != 0 && route.MaxSize <= route.MinSize {
+			err = errors.Join(
+				err,
+				errors.BadRequestf(
+					"blob_store-routes[%d]: max-size %d is not larger than min-size %d",
+					i,
+					route.MaxSize,
+					route.MinSize,
+				),
+			)
+		}
+	}
+
+	return err
+}
+
+func getSchemaKeys(
+	tipe reflect.Type,
+	prefix string,
+	keys []SchemaKey,
+) []SchemaKey {
+	for tipe.Kind() == reflect.Pointer {
+		tipe = tipe.Elem()
+	}
+
+	for i := range tipe.NumField() {
+		field := tipe.Field(i)
+
+		name, ok := getSchemaFieldName(field)
+
+		if !ok {
+			continue
+		}
+
+		if isSchemaLeaf(field.Type) {
+			keys = append(
+				keys,
+				SchemaKey{
+					Key:  prefix + name,
+					Type: getSchemaTypeName(field.Type),
+				},
+			)
+		} else {
+			keys = getSchemaKeys(field.Type, prefix+name+".", keys)
+		}
+	}
+
+	return keys
+}
+
+func getSchemaFieldName(field reflect.StructField) (name string, ok bool) {
+	if !field.IsExported() {
+		return name, ok
+	}
+
+	name, _, _ = strings.Cut(field.Tag.Get("toml"), ",")
+
+	if name == "-" {
+		return name, ok
+	}
+
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
+
+// isSchemaLeaf is true for fields that hold a value rather than a table,
+// which includes structs encoded as text, like ids.
+func isSchemaLeaf(tipe reflect.Type) bool {
+	if tipe.Kind() == reflect.Pointer {
+		tipe = tipe.Elem()
+	}
+
+	if reflect.PointerTo(tipe).Implements(typeTextUnmarshaler) {
+		return true
+	}
+
+	return tipe.Kind() != reflect.Struct
+}
+
+func getSchemaTypeName(tipe reflect.Type) string {
+	if tipe.Kind() == reflect.Pointer {
+		tipe = tipe.Elem()
+	}
+
+	switch {
+	case reflect.PointerTo(tipe).Implements(typeTextUnmarshaler):
+		return "text"
+
+	case tipe.Kind() == reflect.Slice:
+		return "list of " + getSchemaTypeName(tipe.Elem())
+
+	case tipe.Kind() == reflect.Map, tipe.Kind() == reflect.Struct:
+		return "table"
+
+	case tipe.Kind() == reflect.Float32, tipe.Kind() == reflect.Float64:
+		return "float"
+
+	case tipe.Kind() >= reflect.Int && tipe.Kind() <= reflect.Int64:
+		return "int"
+
+	case tipe.Kind() >= reflect.Uint && tipe.Kind() <= reflect.Uint64:
+		return "uint"
+
+	default:
+		return tipe.Kind().String()
+	}
+}
+
+// copySchemaValue copies the config in value so that setting its fields
+// leaves value unchanged.
+func copySchemaValue(value reflect.Value) reflect.Value {
+	if value.Kind() == reflect.Pointer {
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(value.Elem())
+		return copied
+	}
+
+	copied := reflect.New(value.Type()).Elem()
+	copied.Set(value)
+
+	return copied
+}
+
+// lookupSchemaField returns the field of key in value. When allocate is
+// true, value must be a copy from copySchemaValue, and the tables on the way
+// are allocated or copied so that the field can be set without changing the
+// config value was copied from. Otherwise the returned field is invalid when
+// a table on the way is unset.
+func lookupSchemaField(
+	value reflect.Value,
+	key string,
+	allocate bool,
+) (field reflect.Value, err error) {
+	remaining := key
+
+	for {
+		for value.Kind() == reflect.Pointer {
+			if allocate && value.CanSet() {
+				copied := reflect.New(value.Type().Elem())
+
+				if !value.IsNil() {
+					copied.Elem().Set(value.Elem())
+				}
+
+				value.Set(copied)
+			} else if value.IsNil() {
+				return field, err
+			}
+
+			value = value.Elem()
+		}
+
+		var name string
+		name, remaining, _ = strings.Cut(remaining, ".")
+
+		found := false
+
+		for i := range value.NumField() {
+			fieldName, ok := getSchemaFieldName(value.Type().Field(i))
+
+			if !ok || fieldName != name {
+				continue
+			}
+
+			field = value.Field(i)
+			found = true
+
+			break
+		}
+
+		if !found {
+			err = errors.BadRequestf("unsupported config key: %q", key)
+			return field, err
+		}
+
+		if isSchemaLeaf(field.Type()) {
+			if remaining != "" {
+				err = errors.BadRequestf("unsupported config key: %q", key)
+				return field, err
+			}
+
+			if !allocate && field.Kind() == reflect.Pointer && field.IsNil() {
+				field = reflect.Value{}
+			}
+
+			return field, err
+		}
+
+		if remaining == "" {
+			err = errors.BadRequestf(
+				"config key %q is a table, expected one of its keys",
+				key,
+			)
+
+			return field, err
+		}
+
+		value = field
+	}
+}
+
+func formatSchemaValue(field reflect.Value) string {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return ""
+		}
+
+		field = field.Elem()
+	}
+
+	if !field.Type().Implements(typeTextMarshaler) && field.CanAddr() {
+		field = field.Addr()
+	}
+
+	if marshaler, ok := field.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return err.Error()
+		}
+
+		return string(text)
+	}
+
+	field = reflect.Indirect(field)
+
+	if field.Kind() == reflect.Slice {
+		values := make([]string, field.Len())
+
+		for i := range field.Len() {
+			values[i] = formatSchemaValue(field.Index(i))
+		}
+
+		return strings.Join(values, ",")
+	}
+
+	return fmt.Sprint(field.Interface())
+}
+
+func setSchemaValue(field reflect.Value, value string) (err error) {
+	if field.Kind() == reflect.Pointer {
+		pointer := reflect.New(field.Type().Elem())
+
+		if err = setSchemaValue(pointer.Elem(), value); err != nil {
+			return err
+		}
+
+		field.Set(pointer)
+
+		return err
+	}
+
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(strings.TrimSpace(value)))
+	}
+
+	switch kind := field.Kind(); {
+	case kind == reflect.String:
+		field.SetString(value)
+
+	case kind == reflect.Bool:
+		var parsed bool
+
+		if parsed, err = strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+			return err
+		}
+
+		field.SetBool(parsed)
+
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		var parsed int64
+
+		if parsed, err = strconv.ParseInt(
+			strings.TrimSpace(value),
+			10,
+			field.Type().Bits(),
+		); err != nil {
+			return err
+		}
+
+		field.SetInt(parsed)
+
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		var parsed uint64
+
+		if parsed, err = strconv.ParseUint(
+			strings.TrimSpace(value),
+			10,
+			field.Type().Bits(),
+		); err != nil {
+			return err
+		}
+
+		field.SetUint(parsed)
+
+	case kind == reflect.Float32, kind == reflect.Float64:
+		var parsed float64
+
+		if parsed, err = strconv.ParseFloat(
+			strings.TrimSpace(value),
+			field.Type().Bits(),
+		); err != nil {
+			return err
+		}
+
+		field.SetFloat(parsed)
+
+	case kind == reflect.Slice:
+		var parts []string
+
+		for part := range strings.SplitSeq(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+
+		for i, part := range parts {
+			if err = setSchemaValue(slice.Index(i), part); err != nil {
+				return err
+			}
+		}
+
+		field.Set(slice)
+
+	default:
+		err = errors.Errorf(
+			"%s values cannot be set, use edit-config",
+			getSchemaTypeName(field.Type()),
+		)
+	}
+
+	return err
+}
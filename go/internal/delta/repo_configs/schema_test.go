@@ -0,0 +1,146 @@
+//go:build test
+
+package repo_configs
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func TestSchemaSetGetUnset(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	original := DefaultOverlay(
+		blob_store_id.Make("default"),
+		ids.MustTypeStruct("md"),
+	).Blob.(V2)
+
+	// decoded konfig blobs are pointers
+	var config ConfigOverlay = &original
+
+	keys := GetSchemaKeys(config)
+
+	found := false
+
+	for _, key := range keys {
+		if key.Key == "maintenance.pack-max-blobs" && key.Type == "int" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected maintenance.pack-max-blobs in %v", keys)
+	}
+
+	updated, err := SetKey(config, "defaults.type", "txt")
+	t.AssertNoError(err)
+
+	updated, err = SetKey(updated, "defaults.tags", "one, two")
+	t.AssertNoError(err)
+
+	updated, err = SetKey(updated, "cli-output.abbreviations.zettel_ids", "false")
+	t.AssertNoError(err)
+
+	if value, err := GetKey(updated, "defaults.type"); err != nil ||
+		value != "!txt" {
+		t.Errorf("expected !txt, got %q (%v)", value, err)
+	}
+
+	if value, err := GetKey(updated, "defaults.tags"); err != nil ||
+		value != "one,two" {
+		t.Errorf("expected one,two, got %q (%v)", value, err)
+	}
+
+	if value, _ := GetKey(config, "defaults.type"); value != "!md" {
+		t.Errorf("expected the original config to be unchanged, got %q", value)
+	}
+
+	if value, _ := GetKey(
+		config,
+		"cli-output.abbreviations.zettel_ids",
+	); value != "true" {
+		t.Errorf("expected the original abbreviation to be unchanged, got %q", value)
+	}
+
+	updated, err = UnsetKey(updated, "cli-output.abbreviations.zettel_ids")
+	t.AssertNoError(err)
+
+	if value, _ := GetKey(
+		updated,
+		"cli-output.abbreviations.zettel_ids",
+	); value != "" {
+		t.Errorf("expected the abbreviation to be unset, got %q", value)
+	}
+
+	if _, err := SetKey(config, "auto-vivify", "maybe"); err == nil {
+		t.Errorf("expected a bool key to reject a non-bool")
+	}
+
+	if _, err := SetKey(config, "defaults", "md"); err == nil {
+		t.Errorf("expected a table to be rejected")
+	}
+
+	if _, err := GetKey(config, "no-such-key"); err == nil {
+		t.Errorf("expected an unknown key to be rejected")
+	}
+
+	updated, err = SetKey(config, "trash.retention", "a while")
+	t.AssertNoError(err)
+
+	if err := Validate(updated); err == nil {
+		t.Errorf("expected an invalid duration to fail validation")
+	}
+
+	if err := Validate(config); err != nil {
+		t.Errorf("expected the default config to be valid, got %v", err)
+	}
+}
+
+func TestValidateBlobStoreRoutes(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	config := DefaultOverlay(
+		blob_store_id.Make("default"),
+		ids.MustTypeStruct("md"),
+	).Blob.(V2)
+
+	config.BlobStoreRoutes = []BlobStoreRouteV1{
+		{
+			BlobStoreId: blob_store_id.Make("images"),
+			Types:       []ids.TypeStruct{ids.MustTypeStruct("png")},
+			MinSize:     1 << 20,
+		},
+	}
+
+	if err := Validate(&config); err != nil {
+		t.Errorf("expected the routes to be valid, got %v", err)
+	}
+
+	found := false
+
+	for _, key := range GetSchemaKeys(&config) {
+		if key.Key == "blob_store-routes" && key.Type == "list of table" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected blob_store-routes to be a list of tables")
+	}
+
+	if _, err := SetKey(&config, "blob_store-routes", "images"); err == nil {
+		t.Errorf("expected the routes to only be set with edit-config")
+	}
+
+	config.BlobStoreRoutes = append(
+		config.BlobStoreRoutes,
+		BlobStoreRouteV1{MinSize: 10, MaxSize: 10},
+	)
+
+	if err := Validate(&config); err == nil {
+		t.Errorf("expected a route without a store and an empty size range to fail validation")
+	}
+}
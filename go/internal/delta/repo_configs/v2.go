@@ -1,19 +1,110 @@
 package repo_configs
 
 import (
+	"time"
+
 	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
 	"code.linenisgreat.com/dodder/go/internal/_/options_print"
 	"code.linenisgreat.com/dodder/go/internal/_/options_tools"
 	"code.linenisgreat.com/dodder/go/internal/bravo/file_extensions"
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
 )
 
 type V2 struct {
 	DefaultBlobStoreId blob_store_id.Id       `toml:"default-blob_store"`
+	AutoVivify         bool                   `toml:"auto-vivify,omitempty"`
 	Defaults           DefaultsV1             `toml:"defaults"`
+	Editor             string                 `toml:"editor,omitempty"`
 	FileExtensions     file_extensions.TOMLV1 `toml:"file-extensions"`
 	PrintOptions       options_print.V2       `toml:"cli-output"`
 	Tools              options_tools.Options  `toml:"tools"`
+	StreamIndex        StreamIndexV1          `toml:"stream-index,omitempty"`
+	Hooks              HooksV1                `toml:"hooks,omitempty"`
+	Lua                LuaV1                  `toml:"lua,omitempty"`
+	VirtualTags        VirtualTagsV1          `toml:"virtual-tags,omitempty"`
+	Trash              TrashV1                `toml:"trash,omitempty"`
+	Maintenance        MaintenanceV1          `toml:"maintenance,omitempty"`
+	BlobStoreRoutes    []BlobStoreRouteV1     `toml:"blob_store-routes,omitempty"`
+}
+
+type StreamIndexV1 struct {
+	// garbage ratio past which a flush compacts the stream index, or zero to
+	// only compact with `compact-stream-index`
+	CompactionThreshold float64 `toml:"compaction-threshold,omitempty"`
+
+	// how long superseded versions are kept by compaction, as a Go duration
+	// like `720h`. Unset keeps every version and turns compaction off.
+	Retention string `toml:"retention,omitempty"`
+}
+
+func (options StreamIndexV1) GetRetention() (
+	retention time.Duration,
+	ok bool,
+	err error,
+) {
+	if options.Retention == "" {
+		return retention, ok, err
+	}
+
+	if retention, err = time.ParseDuration(options.Retention); err != nil {
+		err = errors.Wrapf(err, "stream-index.retention")
+		return retention, ok, err
+	}
+
+	ok = true
+
+	return retention, ok, err
+}
+
+// BlobStoreRouteV1 writes the blobs it matches to a blob store other than the
+// default one. A route matches blobs of at least MinSize bytes, of less than
+// MaxSize bytes unless it is zero, and of objects of one of Types unless it is
+// empty. The first matching route wins. Blobs no route matches, and blobs
+// written without an object type, like the konfig's, go to the default blob
+// store.
+type BlobStoreRouteV1 struct {
+	BlobStoreId blob_store_id.Id `toml:"blob_store-id"`
+	Types       []ids.TypeStruct `toml:"types,omitempty"`
+	MinSize     uint64           `toml:"min-size,omitempty"`
+	MaxSize     uint64           `toml:"max-size,omitempty"`
+}
+
+type HooksV1 struct {
+	// object ids of `!lua` objects whose blobs define `on_pre_commit` and
+	// `on_post_commit`, run in order for every commit
+	Scripts []string `toml:"scripts,omitempty"`
+}
+
+type VirtualTagsV1 struct {
+	// object ids of `!lua` objects whose blobs return a table of functions
+	// named after the virtual tags they compute, without the `%`
+	Providers []string `toml:"providers,omitempty"`
+}
+
+type LuaV1 struct {
+	// instructions one call into a lua script may run, or zero for the
+	// default
+	InstructionBudget uint64 `toml:"instruction-budget,omitempty"`
+
+	// MiB the process heap may grow by during one call into a lua script, or
+	// zero for no cap
+	MemoryCapMiB uint64 `toml:"memory-cap-mib,omitempty"`
+}
+
+func (options LuaV1) GetLimits() lua.Limits {
+	limits := lua.DefaultLimits()
+
+	if options.InstructionBudget > 0 {
+		limits.Instructions = options.InstructionBudget
+	}
+
+	if options.MemoryCapMiB > 0 {
+		limits.Memory = options.MemoryCapMiB << 20
+	}
+
+	return limits
 }
 
 var _ ConfigOverlay2 = V2{}
@@ -23,6 +114,15 @@ func (config *V2) Reset() {
 	config.Defaults.Type = ids.TypeStruct{}
 	config.Defaults.Tags = make([]ids.TagStruct, 0)
 	config.PrintOptions = options_print.V2{}
+	config.Editor = ""
+	config.AutoVivify = false
+	config.StreamIndex = StreamIndexV1{}
+	config.Hooks = HooksV1{}
+	config.Lua = LuaV1{}
+	config.VirtualTags = VirtualTagsV1{}
+	config.Trash = TrashV1{}
+	config.Maintenance = MaintenanceV1{}
+	config.BlobStoreRoutes = nil
 }
 
 func (config *V2) ResetWith(b *V2) {
@@ -34,6 +134,23 @@ func (config *V2) ResetWith(b *V2) {
 	copy(config.Defaults.Tags, b.Defaults.Tags)
 
 	config.PrintOptions = b.PrintOptions
+	config.Editor = b.Editor
+	config.AutoVivify = b.AutoVivify
+	config.StreamIndex = b.StreamIndex
+
+	config.Hooks.Scripts = make([]string, len(b.Hooks.Scripts))
+	copy(config.Hooks.Scripts, b.Hooks.Scripts)
+
+	config.Lua = b.Lua
+
+	config.VirtualTags.Providers = make([]string, len(b.VirtualTags.Providers))
+	copy(config.VirtualTags.Providers, b.VirtualTags.Providers)
+
+	config.Trash = b.Trash
+	config.Maintenance = b.Maintenance
+
+	config.BlobStoreRoutes = make([]BlobStoreRouteV1, len(b.BlobStoreRoutes))
+	copy(config.BlobStoreRoutes, b.BlobStoreRoutes)
 }
 
 func (config V2) GetDefaults() Defaults {
@@ -55,3 +172,128 @@ func (config V2) GetToolOptions() options_tools.Options {
 func (config V2) GetDefaultBlobStoreId() blob_store_id.Id {
 	return config.DefaultBlobStoreId
 }
+
+// DefaultTrashRetention is how long objects stay in the trash when
+// `trash.retention` is unset.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+type TrashV1 struct {
+	// how long trashed objects are kept before `empty-trash` purges them, as a
+	// Go duration like `720h`
+	Retention string `toml:"retention,omitempty"`
+}
+
+func (options TrashV1) GetRetention() (retention time.Duration, err error) {
+	if options.Retention == "" {
+		retention = DefaultTrashRetention
+		return retention, err
+	}
+
+	if retention, err = time.ParseDuration(options.Retention); err != nil {
+		err = errors.Wrapf(err, "trash.retention")
+		return retention, err
+	}
+
+	return retention, err
+}
+
+const (
+	// DefaultMaintenanceInterval is how long a maintenance task waits after
+	// it last ran when `maintenance.interval` is unset.
+	DefaultMaintenanceInterval = 24 * time.Hour
+
+	// DefaultMaintenanceBudget is how long the maintenance tasks at the end of
+	// one command may run when `maintenance.budget` is unset.
+	DefaultMaintenanceBudget = 2 * time.Second
+
+	// DefaultMaintenancePackMaxBlobs is how many loose blobs the small pack
+	// packs when `maintenance.pack-max-blobs` is unset.
+	DefaultMaintenancePackMaxBlobs = 256
+)
+
+type MaintenanceV1 struct {
+	// run the maintenance tasks that are due at the end of write commands
+	Auto bool `toml:"auto,omitempty"`
+
+	// how long a task waits after it last ran, as a Go duration like `24h`
+	Interval string `toml:"interval,omitempty"`
+
+	// how long the tasks at the end of one command may run, as a Go duration
+	// like `2s`; no task is started once it is used up
+	Budget string `toml:"budget,omitempty"`
+
+	// the most loose blobs one small pack moves into an archive
+	PackMaxBlobs int `toml:"pack-max-blobs,omitempty"`
+}
+
+func (options MaintenanceV1) GetInterval() (interval time.Duration, err error) {
+	if options.Interval == "" {
+		interval = DefaultMaintenanceInterval
+		return interval, err
+	}
+
+	if interval, err = time.ParseDuration(options.Interval); err != nil {
+		err = errors.Wrapf(err, "maintenance.interval")
+		return interval, err
+	}
+
+	return interval, err
+}
+
+func (options MaintenanceV1) GetBudget() (budget time.Duration, err error) {
+	if options.Budget == "" {
+		budget = DefaultMaintenanceBudget
+		return budget, err
+	}
+
+	if budget, err = time.ParseDuration(options.Budget); err != nil {
+		err = errors.Wrapf(err, "maintenance.budget")
+		return budget, err
+	}
+
+	return budget, err
+}
+
+func (options MaintenanceV1) GetPackMaxBlobs() int {
+	if options.PackMaxBlobs <= 0 {
+		return DefaultMaintenancePackMaxBlobs
+	}
+
+	return options.PackMaxBlobs
+}
+
+func (config V2) GetEditor() string {
+	return config.Editor
+}
+
+func (config V2) GetAutoVivify() bool {
+	return config.AutoVivify
+}
+
+func (config V2) GetStreamIndexOptions() StreamIndexV1 {
+	return config.StreamIndex
+}
+
+func (config V2) GetHooks() HooksV1 {
+	return config.Hooks
+}
+
+func (config V2) GetLuaOptions() LuaV1 {
+	return config.Lua
+}
+
+func (config V2) GetVirtualTags() VirtualTagsV1 {
+	return config.VirtualTags
+}
+
+func (config V2) GetTrash() TrashV1 {
+	return config.Trash
+}
+
+func (config V2) GetMaintenance() MaintenanceV1 {
+	return config.Maintenance
+}
+
+func (config V2) GetBlobStoreRoutes() []BlobStoreRouteV1 {
+	return config.BlobStoreRoutes
+}
@@ -12,6 +12,7 @@ type beforeXDG struct {
 	xdgInitArgs xdg.InitArgs
 
 	dryRun       bool
+	readOnly     bool
 	debugOptions debug.Options
 }
 
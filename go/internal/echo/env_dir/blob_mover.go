@@ -16,6 +16,11 @@ type MoveOptions struct {
 	ErrorOnAttemptedOverwrite   bool
 	FinalPathOrDir              string
 	GenerateFinalPathFromDigest bool
+
+	// ReserveBytes, if set, is called with the size of a new blob as stored
+	// before it is moved into place. If it fails, the blob is discarded and
+	// Close returns its error.
+	ReserveBytes func(bytes int64) error
 }
 
 type localFileMover struct {
@@ -27,6 +32,7 @@ type localFileMover struct {
 	blobPath                  string
 	lockFile                  bool
 	errorOnAttemptedOverwrite bool
+	reserveBytes              func(int64) error
 }
 
 func NewMover(
@@ -48,6 +54,7 @@ func newMover(
 	mover = &localFileMover{
 		funcJoin:                  config.funcJoin,
 		errorOnAttemptedOverwrite: moveOptions.ErrorOnAttemptedOverwrite,
+		reserveBytes:              moveOptions.ReserveBytes,
 	}
 
 	if moveOptions.GenerateFinalPathFromDigest {
@@ -142,6 +149,36 @@ func (mover *localFileMover) Close() (err error) {
 
 	path := mover.file.Name()
 
+	// renaming over an existing blob would replace its file, breaking any
+	// hard links other stores share it through, so the blob is left as is
+	if files.Exists(mover.blobPath) {
+		if err = os.Remove(path); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if mover.errorOnAttemptedOverwrite {
+			err = MakeErrBlobAlreadyExists(digest, mover.blobPath)
+		}
+
+		return err
+	}
+
+	if mover.reserveBytes != nil {
+		var fileInfo os.FileInfo
+
+		if fileInfo, err = os.Stat(path); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if err = mover.reserveBytes(fileInfo.Size()); err != nil {
+			os.Remove(path)
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	if err = os.Rename(path, mover.blobPath); err != nil {
 		if files.Exists(mover.blobPath) {
 			if mover.errorOnAttemptedOverwrite {
@@ -84,6 +84,10 @@ func (err ErrBlobMissing) Is(target error) bool {
 	return ok
 }
 
+func (err ErrBlobMissing) GetErrorCode() errors.Code {
+	return errors.CodeBlobMissing
+}
+
 func (err ErrBlobMissing) GetErrorType() pkgErrDisamb {
 	return pkgErrDisamb{}
 }
@@ -125,3 +129,34 @@ func (err ErrTempAlreadyExists) Is(target error) bool {
 func (err ErrTempAlreadyExists) GetErrorType() pkgErrDisamb {
 	return pkgErrDisamb{}
 }
+
+func IsErrReadOnly(err error) bool {
+	return errors.Is(err, ErrReadOnly{})
+}
+
+// ErrReadOnly is returned by writes to a repo or blob store that is read-only,
+// either by the -read-only flag or by its config.
+type ErrReadOnly struct {
+	Path string
+}
+
+func (err ErrReadOnly) Error() string {
+	if err.Path == "" {
+		return "read-only: writes are disabled"
+	} else {
+		return fmt.Sprintf("read-only: writes are disabled: %q", err.Path)
+	}
+}
+
+func (err ErrReadOnly) Is(target error) bool {
+	_, ok := target.(ErrReadOnly)
+	return ok
+}
+
+func (err ErrReadOnly) GetErrorCode() errors.Code {
+	return errors.CodeReadOnly
+}
+
+func (err ErrReadOnly) GetErrorType() pkgErrDisamb {
+	return pkgErrDisamb{}
+}
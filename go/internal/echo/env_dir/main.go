@@ -23,6 +23,7 @@ type Env interface {
 	interfaces.EnvVarsAdder
 
 	IsDryRun() bool
+	IsReadOnly() bool
 	GetCwd() string
 
 	GetXDG() xdg.XDG
@@ -75,10 +76,29 @@ func (env env) GetDebug() debug.Options {
 	return env.debugOptions
 }
 
+// IsDryRun is true under the global -dry-run or -debug dry-run: commands
+// report what they would do, deletes are skipped, and blob stores write to
+// the temp dir instead of their base path.
 func (env env) IsDryRun() bool {
 	return env.dryRun
 }
 
+// SetDryRun turns on the dry run for the global -dry-run flag; -debug
+// dry-run turns it on regardless.
+func (env *env) SetDryRun(dryRun bool) {
+	env.dryRun = env.dryRun || dryRun
+}
+
+// IsReadOnly is true when writes to the repo and its blob stores must fail
+// with ErrReadOnly, as for repos on read-only mounts.
+func (env env) IsReadOnly() bool {
+	return env.readOnly
+}
+
+func (env *env) SetReadOnly(readOnly bool) {
+	env.readOnly = readOnly
+}
+
 func (env env) GetPid() int {
 	return env.xdgInitArgs.Pid
 }
@@ -0,0 +1,304 @@
+package file_lock
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+type AccessMode int
+
+const (
+	AccessNone = AccessMode(iota)
+	AccessShared
+	AccessExclusive
+)
+
+func (mode AccessMode) String() string {
+	switch mode {
+	case AccessShared:
+		return "shared"
+
+	case AccessExclusive:
+		return "exclusive"
+
+	default:
+		return "none"
+	}
+}
+
+const (
+	// EnvAccessLock is set to the path of an access lock while the process
+	// holds it, so that dodder processes it starts, like hooks and `exec`
+	// scripts, do not wait on it.
+	EnvAccessLock = "DODDER_ACCESS_LOCK"
+
+	// EnvAccessLockTimeout overrides AccessLockDefaultTimeout with a
+	// duration like `5m`, or `0` to fail without waiting.
+	EnvAccessLockTimeout = "DODDER_LOCK_TIMEOUT"
+
+	AccessLockDefaultTimeout = 30 * time.Second
+
+	accessLockPollInterval = 50 * time.Millisecond
+)
+
+var accessLocks = struct {
+	sync.Mutex
+	byPath map[string]*AccessLock
+}{
+	byPath: make(map[string]*AccessLock),
+}
+
+// AccessLock is an advisory flock(2) that readers of a repo share and writers
+// hold exclusively, so that reads do not see the index and caches halfway
+// through a write by another process. It is released by the kernel if its
+// holder dies, so unlike Lock it is never left behind.
+//
+// Holds are counted by mode, and the flock is held in the strongest mode held,
+// so there is one AccessLock per path in a process: holders within a process
+// do not exclude each other, which Lock does.
+type AccessLock struct {
+	path        string
+	description string
+	timeout     time.Duration
+
+	// set if a parent process holds the lock, in which case nothing is locked
+	nested bool
+
+	mutex   sync.Mutex
+	file    *os.File
+	mode    AccessMode
+	holders [AccessExclusive + 1]int
+}
+
+// MakeAccessLock returns the access lock of the process for path, waiting for
+// up to timeout for other processes to release it.
+func MakeAccessLock(
+	path string,
+	description string,
+	timeout time.Duration,
+) (lock *AccessLock) {
+	path = filepath.Clean(path)
+
+	accessLocks.Lock()
+	defer accessLocks.Unlock()
+
+	if lock = accessLocks.byPath[path]; lock != nil {
+		return lock
+	}
+
+	lock = &AccessLock{
+		path:        path,
+		description: description,
+		timeout:     timeout,
+		nested:      os.Getenv(EnvAccessLock) == path,
+	}
+
+	accessLocks.byPath[path] = lock
+
+	return lock
+}
+
+func (lock *AccessLock) Path() string {
+	return lock.path
+}
+
+func (lock *AccessLock) GetMode() AccessMode {
+	lock.mutex.Lock()
+	defer lock.mutex.Unlock()
+
+	return lock.mode
+}
+
+// Lock adds a hold in mode, waiting for other processes if the flock needs to
+// be taken or upgraded. It fails with ErrBusy after the timeout.
+func (lock *AccessLock) Lock(mode AccessMode) (err error) {
+	if mode == AccessNone {
+		return err
+	}
+
+	lock.mutex.Lock()
+	defer lock.mutex.Unlock()
+
+	lock.holders[mode]++
+
+	if err = lock.setMode(lock.strongestHeldMode()); err != nil {
+		lock.holders[mode]--
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// Unlock removes a hold in mode, downgrading or releasing the flock if it was
+// the last hold in its mode.
+func (lock *AccessLock) Unlock(mode AccessMode) (err error) {
+	if mode == AccessNone {
+		return err
+	}
+
+	lock.mutex.Lock()
+	defer lock.mutex.Unlock()
+
+	if lock.holders[mode] == 0 {
+		err = errors.ErrorWithStackf("%s access lock not held", mode)
+		return err
+	}
+
+	lock.holders[mode]--
+
+	if err = lock.setMode(lock.strongestHeldMode()); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (lock *AccessLock) strongestHeldMode() AccessMode {
+	for mode := AccessExclusive; mode > AccessNone; mode-- {
+		if lock.holders[mode] > 0 {
+			return mode
+		}
+	}
+
+	return AccessNone
+}
+
+func (lock *AccessLock) setMode(mode AccessMode) (err error) {
+	if lock.nested || mode == lock.mode {
+		return err
+	}
+
+	if mode == AccessNone {
+		if err = lock.file.Close(); err != nil {
+			err = errors.Wrapf(err, "File: %s", lock.path)
+			return err
+		}
+
+		lock.file = nil
+		lock.mode = AccessNone
+
+		if err = os.Unsetenv(EnvAccessLock); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		return err
+	}
+
+	if lock.file == nil {
+		if err = os.MkdirAll(filepath.Dir(lock.path), 0o755); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if lock.file, err = os.OpenFile(
+			lock.path,
+			os.O_RDONLY|os.O_CREATE,
+			0o644,
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	how := syscall.LOCK_SH
+
+	if mode == AccessExclusive {
+		how = syscall.LOCK_EX
+	}
+
+	// Two processes holding the lock shared that both upgrade it would each
+	// wait for the other to let go of its shared lock, which flock(2) does not
+	// guarantee a failed conversion does, so the shared lock is released
+	// first. Whatever was read under it is not protected by the exclusive one.
+	if lock.mode == AccessShared && mode == AccessExclusive {
+		if err = syscall.Flock(int(lock.file.Fd()), syscall.LOCK_UN); err != nil {
+			err = errors.Wrapf(err, "File: %s", lock.path)
+			return err
+		}
+
+		lock.mode = AccessNone
+	}
+
+	if err = lock.flockOrTimeout(how); err != nil {
+		lock.file.Close()
+		lock.file = nil
+		lock.mode = AccessNone
+		os.Unsetenv(EnvAccessLock)
+
+		if err == syscall.EWOULDBLOCK {
+			err = ErrBusy{
+				Path:        lock.path,
+				Mode:        mode,
+				Timeout:     lock.timeout,
+				description: lock.description,
+			}
+		} else {
+			err = errors.Wrapf(err, "File: %s", lock.path)
+		}
+
+		return err
+	}
+
+	lock.mode = mode
+
+	if err = os.Setenv(EnvAccessLock, lock.path); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// flockOrTimeout polls rather than blocking in flock(2) so that it can give
+// up after the timeout.
+func (lock *AccessLock) flockOrTimeout(how int) (err error) {
+	deadline := time.Now().Add(lock.timeout)
+
+	for {
+		err = syscall.Flock(int(lock.file.Fd()), how|syscall.LOCK_NB)
+
+		switch {
+		case err == syscall.EINTR:
+			continue
+
+		case err != syscall.EWOULDBLOCK:
+			return err
+
+		case !time.Now().Before(deadline):
+			return err
+		}
+
+		time.Sleep(accessLockPollInterval)
+	}
+}
+
+// GetAccessLockTimeout returns the timeout set by EnvAccessLockTimeout, or
+// AccessLockDefaultTimeout.
+func GetAccessLockTimeout() (timeout time.Duration, err error) {
+	value, ok := os.LookupEnv(EnvAccessLockTimeout)
+
+	if !ok || value == "" {
+		timeout = AccessLockDefaultTimeout
+		return timeout, err
+	}
+
+	if timeout, err = time.ParseDuration(value); err != nil || timeout < 0 {
+		err = errors.BadRequestf(
+			"invalid %s: %q, expected a duration like 30s",
+			EnvAccessLockTimeout,
+			value,
+		)
+
+		return timeout, err
+	}
+
+	return timeout, err
+}
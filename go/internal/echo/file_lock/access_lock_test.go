@@ -0,0 +1,117 @@
+//go:build test && debug
+
+package file_lock
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	envTestAccessLockHelper = "DODDER_TEST_ACCESS_LOCK_HELPER"
+	envTestAccessLockDir    = "DODDER_TEST_ACCESS_LOCK_DIR"
+	testAccessLockWriters   = 2
+)
+
+// TestAccessLockWriterHelper is run as a separate process by
+// TestAccessLockConcurrentWritersUpgrade, as flocks within one process do not
+// exclude each other. It takes the lock shared like every command, waits for
+// the other writers to hold it shared too, and then upgrades it like Lock.
+func TestAccessLockWriterHelper(t *testing.T) {
+	name := os.Getenv(envTestAccessLockHelper)
+
+	if name == "" {
+		t.Skip("only run as a helper process")
+	}
+
+	dir := os.Getenv(envTestAccessLockDir)
+	lock := MakeAccessLock(filepath.Join(dir, "access-lock"), "repo", 10*time.Second)
+
+	if err := lock.Lock(AccessShared); err != nil {
+		t.Fatalf("shared: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "ready-"+name), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		ready, err := filepath.Glob(filepath.Join(dir, "ready-*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(ready) == testAccessLockWriters {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := lock.Lock(AccessExclusive); err != nil {
+		t.Fatalf("exclusive: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := lock.Unlock(AccessExclusive); err != nil {
+		t.Fatalf("unlock exclusive: %v", err)
+	}
+
+	if err := lock.Unlock(AccessShared); err != nil {
+		t.Fatalf("unlock shared: %v", err)
+	}
+}
+
+func TestAccessLockConcurrentWritersUpgrade(t *testing.T) {
+	if os.Getenv(envTestAccessLockHelper) != "" {
+		t.Skip("helper process")
+	}
+
+	dir := t.TempDir()
+	start := time.Now()
+
+	var cmds []*exec.Cmd
+	var outputs []*bytes.Buffer
+
+	for i := range testAccessLockWriters {
+		cmd := exec.Command(
+			os.Args[0],
+			"-test.run=^TestAccessLockWriterHelper$",
+		)
+
+		cmd.Env = append(
+			os.Environ(),
+			fmt.Sprintf("%s=%d", envTestAccessLockHelper, i),
+			fmt.Sprintf("%s=%s", envTestAccessLockDir, dir),
+			EnvAccessLock+"=",
+		)
+
+		output := &bytes.Buffer{}
+		cmd.Stdout = output
+		cmd.Stderr = output
+
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		cmds = append(cmds, cmd)
+		outputs = append(outputs, output)
+	}
+
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("writer failed: %v: %s", err, outputs[i])
+		}
+	}
+
+	// the helpers wait up to 10s, so anything close to that is a stall
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("writers took %s, expected them to take turns", elapsed)
+	}
+}
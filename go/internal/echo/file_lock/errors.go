@@ -3,6 +3,7 @@ package file_lock
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"code.linenisgreat.com/dodder/go/internal/delta/env_ui"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
@@ -30,6 +31,10 @@ func (err ErrLockRequired) Error() string {
 	)
 }
 
+func (err ErrLockRequired) GetErrorCode() errors.Code {
+	return errors.CodeLockRequired
+}
+
 func (err ErrLockRequired) GetErrorType() pkgErrDisamb {
 	return pkgErrDisamb{}
 }
@@ -51,6 +56,10 @@ func (err ErrUnableToAcquireLock) Is(target error) bool {
 	return ok
 }
 
+func (err ErrUnableToAcquireLock) GetErrorCode() errors.Code {
+	return errors.CodeLockConflict
+}
+
 func (err ErrUnableToAcquireLock) GetErrorType() pkgErrDisamb {
 	return pkgErrDisamb{}
 }
@@ -88,3 +97,60 @@ func (err ErrUnableToAcquireLock) Recover(
 		abort(errors.Errorf("not deleting the lock"))
 	}
 }
+
+// ErrBusy is returned when another process held an access lock for longer than
+// the timeout.
+type ErrBusy struct {
+	Path        string
+	Mode        AccessMode
+	Timeout     time.Duration
+	description string
+}
+
+func (err ErrBusy) Error() string {
+	return fmt.Sprintf(
+		"%s is busy: timed out after %s waiting for %s access",
+		err.description,
+		err.Timeout,
+		err.Mode,
+	)
+}
+
+func (err ErrBusy) Is(target error) bool {
+	_, ok := target.(ErrBusy)
+	return ok
+}
+
+func (err ErrBusy) GetErrorCode() errors.Code {
+	return errors.CodeLockConflict
+}
+
+func (err ErrBusy) GetErrorType() pkgErrDisamb {
+	return pkgErrDisamb{}
+}
+
+func (err ErrBusy) GetErrorCause() []string {
+	if err.Mode == AccessExclusive {
+		return []string{
+			fmt.Sprintf(
+				"Another dodder process is reading or writing the %s.",
+				err.description,
+			),
+		}
+	}
+
+	return []string{
+		fmt.Sprintf("Another dodder process is writing the %s.", err.description),
+	}
+}
+
+func (err ErrBusy) GetErrorRecovery() []string {
+	return []string{
+		"Retry once the other process is done.",
+		fmt.Sprintf(
+			"Set %s to wait longer (e.g. `%s=5m`).",
+			EnvAccessLockTimeout,
+			EnvAccessLockTimeout,
+		),
+	}
+}
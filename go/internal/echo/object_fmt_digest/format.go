@@ -152,6 +152,21 @@ func (format format) writeMetadataKeyStringTo(
 			return n, err
 		}
 
+	case key_strings.Provenance:
+		for _, pair := range metadata.GetProvenance().Pairs() {
+			n1, err = ohio.WriteKeySpaceValueNewlineString(
+				writer,
+				key.String(),
+				pair[0]+"="+pair[1],
+			)
+			n += int64(n1)
+
+			if err != nil {
+				err = errors.Wrap(err)
+				return n, err
+			}
+		}
+
 	case key_strings.ZZRepoPub:
 		n1, err = format.writeMarklIdKeyIfNotNull(
 			writer,
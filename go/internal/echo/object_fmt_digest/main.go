@@ -99,6 +99,9 @@ func init() {
 		key_strings.Blob,
 		key_strings.Description,
 		key_strings.ObjectId,
+		// written only if set, which keeps the digests of objects without a
+		// provenance unchanged
+		key_strings.Provenance,
 		key_strings.Tag,
 		key_strings.Tai,
 		key_strings.TypeLock,
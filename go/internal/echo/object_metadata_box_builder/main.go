@@ -184,3 +184,15 @@ func (builder *Builder) AddDescription(metadata objects.MetadataMutable) {
 		ColorType: string_format_writer.ColorTypeUserData,
 	})
 }
+
+// AddProvenance adds the provenance fields as quoted `key="value"` pairs.
+func (builder *Builder) AddProvenance(metadata objects.MetadataMutable) {
+	for _, pair := range metadata.GetProvenance().Pairs() {
+		builder.Contents.Append(string_format_writer.Field{
+			Key:        pair[0],
+			Value:      pair[1],
+			ColorType:  string_format_writer.ColorTypeUserData,
+			NoTruncate: true,
+		})
+	}
+}
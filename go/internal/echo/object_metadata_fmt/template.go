@@ -0,0 +1,63 @@
+package object_metadata_fmt
+
+import (
+	"sort"
+	"strings"
+	"text/template"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/string_format_writer"
+	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// TemplateView is the data a `-format template=...` template is executed
+// with. Its fields are only ever added, never renamed or removed, as user
+// templates refer to them by name.
+type TemplateView struct {
+	ObjectId    string   // e.g. one/uno
+	Type        string   // e.g. !md
+	Tags        []string // sorted
+	Description string   // without newlines
+	Tai         string
+	Date        string // the Tai as a local date and time
+	BlobId      string
+}
+
+func MakeTemplateView(
+	objectId string,
+	metadata objects.MetadataMutable,
+) (view TemplateView) {
+	view.ObjectId = objectId
+	view.Type = metadata.GetType().String()
+	view.Tags = make([]string, 0, metadata.GetTags().Len())
+
+	for tag := range metadata.AllTags() {
+		view.Tags = append(view.Tags, tag.String())
+	}
+
+	sort.Strings(view.Tags)
+
+	view.Description = metadata.GetDescription().StringWithoutNewlines()
+	view.Tai = metadata.GetTai().String()
+	view.Date = metadata.GetTai().Format(
+		string_format_writer.StringFormatDateTime,
+	)
+	view.BlobId = metadata.GetBlobDigest().String()
+
+	return view
+}
+
+// ParseTemplate parses text as a text/template for TemplateView, with `join`
+// (strings.Join) available for Tags.
+func ParseTemplate(text string) (tmpl *template.Template, err error) {
+	if tmpl, err = template.New("format").Funcs(
+		template.FuncMap{
+			"join": strings.Join,
+		},
+	).Parse(text); err != nil {
+		err = errors.Wrapf(err, "invalid format template %q", text)
+		return tmpl, err
+	}
+
+	return tmpl, err
+}
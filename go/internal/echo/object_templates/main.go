@@ -0,0 +1,90 @@
+package object_templates
+
+import (
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04"
+)
+
+// View is the data a type's template is executed with when `new` creates an
+// object of the type. Like object_metadata_fmt.TemplateView, its fields are
+// only ever added, as user templates refer to them by name.
+type View struct {
+	ObjectId    string   // e.g. one/uno
+	Type        string   // e.g. !journal
+	Tags        []string // sorted
+	Description string
+	Date        string // the object's tai as a local date, e.g. 2026-10-18
+	Time        string // the object's tai as a local time, e.g. 14:05
+
+	// Fields are the `template-fields` of the type config.
+	Fields map[string]string
+
+	now time.Time
+}
+
+func MakeView(
+	objectId string,
+	metadata objects.MetadataMutable,
+	fields map[string]string,
+) (view View) {
+	view.ObjectId = objectId
+	view.Type = metadata.GetType().String()
+	view.Tags = make([]string, 0, metadata.GetTags().Len())
+
+	for tag := range metadata.AllTags() {
+		view.Tags = append(view.Tags, tag.String())
+	}
+
+	sort.Strings(view.Tags)
+
+	view.Description = metadata.GetDescription().String()
+	view.now = metadata.GetTai().AsTime().GetTime()
+	view.Date = view.now.Format(dateLayout)
+	view.Time = view.now.Format(timeLayout)
+	view.Fields = fields
+
+	if view.Fields == nil {
+		view.Fields = make(map[string]string)
+	}
+
+	return view
+}
+
+// Expand executes a template with a view. Besides the view's fields,
+// templates can call `date` with a Go time layout to format the object's
+// tai differently, and `join` (strings.Join) on Tags. Missing fields expand
+// to nothing.
+func Expand(name, text string, view View) (expanded string, err error) {
+	var tmpl *template.Template
+
+	if tmpl, err = template.New(name).Funcs(
+		template.FuncMap{
+			"join": strings.Join,
+			"date": view.now.Format,
+		},
+	).Option("missingkey=zero").Parse(text); err != nil {
+		err = errors.Wrapf(err, "invalid template %q", name)
+		return expanded, err
+	}
+
+	var builder strings.Builder
+
+	if err = tmpl.Execute(&builder, view); err != nil {
+		err = errors.Wrapf(err, "failed to expand template %q", name)
+		return expanded, err
+	}
+
+	expanded = builder.String()
+
+	return expanded, err
+}
@@ -27,6 +27,16 @@ type (
 		GetDefaultQueryString() string
 	}
 
+	ConfigSparse interface {
+		ConfigWithDefaultQueryString
+		IsSparse() bool
+	}
+
+	ConfigWithEditor interface {
+		Config
+		GetEditor() string
+	}
+
 	ConfigWithDryRun interface {
 		Config
 		domain_interfaces.ConfigDryRunGetter
@@ -35,5 +45,7 @@ type (
 
 var (
 	_ ConfigWithDefaultQueryString = V0{}
+	_ ConfigSparse                 = V0{}
+	_ ConfigWithEditor             = V0{}
 	_ ConfigTemporary              = Temporary{}
 )
@@ -12,7 +12,15 @@ type V0 struct {
 
 	Query string `toml:"query,omitempty"`
 
+	// Sparse makes Query define which objects are checked out in the
+	// workspace, so that refreshing it also evicts objects that stopped
+	// matching.
+	Sparse bool `toml:"sparse,omitempty"`
+
 	DryRun bool `toml:"dry-run"`
+
+	// Editor overrides the repo's editor for this workspace.
+	Editor string `toml:"editor,omitempty"`
 }
 
 func (blob V0) GetDefaults() repo_configs.Defaults {
@@ -23,6 +31,14 @@ func (blob V0) GetDefaultQueryString() string {
 	return blob.Query
 }
 
+func (blob V0) IsSparse() bool {
+	return blob.Sparse
+}
+
 func (blob V0) IsDryRun() bool {
 	return blob.DryRun
 }
+
+func (blob V0) GetEditor() string {
+	return blob.Editor
+}
@@ -0,0 +1,188 @@
+package blob_stores
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// archiveBloomFilters holds a bloom filter per archive in the index, so that
+// lookups of blobs in no archive are answered from the filters, without
+// building the id string the index is keyed by. While any indexed archive
+// lacks a filter, the set is incomplete and every lookup goes to the index.
+type archiveBloomFilters struct {
+	lock       sync.RWMutex
+	filters    map[string]*inventory_archive.BloomFilterV1
+	incomplete bool
+}
+
+func (filters *archiveBloomFilters) reset() {
+	if filters == nil {
+		return
+	}
+
+	filters.lock.Lock()
+	defer filters.lock.Unlock()
+
+	clear(filters.filters)
+	filters.incomplete = false
+}
+
+func (filters *archiveBloomFilters) add(
+	archiveChecksum string,
+	filter *inventory_archive.BloomFilterV1,
+) {
+	if filters == nil {
+		return
+	}
+
+	filters.lock.Lock()
+	defer filters.lock.Unlock()
+
+	if filters.filters == nil {
+		filters.filters = make(map[string]*inventory_archive.BloomFilterV1)
+	}
+
+	filters.filters[archiveChecksum] = filter
+}
+
+func (filters *archiveBloomFilters) setIncomplete() {
+	if filters == nil {
+		return
+	}
+
+	filters.lock.Lock()
+	defer filters.lock.Unlock()
+
+	filters.incomplete = true
+}
+
+// mayContain is false only when no archive in the index has hash. Stores
+// without filters may contain every hash.
+func (filters *archiveBloomFilters) mayContain(hash []byte) bool {
+	if filters == nil {
+		return true
+	}
+
+	filters.lock.RLock()
+	defer filters.lock.RUnlock()
+
+	if filters.incomplete {
+		return true
+	}
+
+	for _, filter := range filters.filters {
+		if filter.MayContain(hash) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (store inventoryArchiveV1) bloomFilterPath(archiveChecksum string) string {
+	return filepath.Join(
+		store.archivesPath(),
+		archiveChecksum+inventory_archive.BloomFileExtensionV1,
+	)
+}
+
+func (store inventoryArchiveV1) writeBloomFilter(
+	archiveChecksum string,
+	filter *inventory_archive.BloomFilterV1,
+) (err error) {
+	return files.WriteAtomic(
+		store.bloomFilterPath(archiveChecksum),
+		func(writer io.Writer) (err error) {
+			return inventory_archive.WriteBloomFilterV1(writer, filter)
+		},
+	)
+}
+
+func (store inventoryArchiveV1) readBloomFilter(
+	archiveChecksum string,
+) (filter *inventory_archive.BloomFilterV1, err error) {
+	bloomPath := store.bloomFilterPath(archiveChecksum)
+
+	file, err := os.Open(bloomPath)
+	if err != nil {
+		return filter, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	info, err := file.Stat()
+	if err != nil {
+		err = errors.Wrap(err)
+		return filter, err
+	}
+
+	if filter, err = inventory_archive.ReadBloomFilterV1(
+		file,
+		info.Size(),
+	); err != nil {
+		err = errors.Wrapf(err, "reading bloom filter %s", bloomPath)
+		return filter, err
+	}
+
+	return filter, err
+}
+
+// addBloomFilterForIndex adds the filter of an archive whose index was just
+// read and, with write, writes its file, for archives packed before filters
+// were, unless the store is read-only or in a dry run. Failing to write it
+// only costs the next load a read of the archive's index.
+func (store inventoryArchiveV1) addBloomFilterForIndex(
+	archiveChecksum string,
+	indexEntries []inventory_archive.IndexEntryV1,
+	write bool,
+) {
+	if store.blooms == nil {
+		return
+	}
+
+	filter := inventory_archive.MakeBloomFilterV1ForIndex(indexEntries)
+	store.blooms.add(archiveChecksum, filter)
+
+	if !write || store.readOnly || store.dryRun {
+		return
+	}
+
+	store.writeBloomFilter(archiveChecksum, filter)
+}
+
+// loadBloomFilters loads the filter of every archive, for an index loaded
+// from the cache. Archives without a readable filter file get theirs from
+// their index, and the set is left incomplete when that fails too.
+func (store inventoryArchiveV1) loadBloomFilters(
+	archives []inventory_archive.ManifestArchive,
+) {
+	if store.blooms == nil {
+		return
+	}
+
+	store.blooms.reset()
+
+	for _, archive := range archives {
+		archiveChecksum := hex.EncodeToString(archive.Checksum)
+
+		if filter, err := store.readBloomFilter(archiveChecksum); err == nil {
+			store.blooms.add(archiveChecksum, filter)
+			continue
+		}
+
+		indexEntries, _, err := store.readArchiveIndex(archive)
+		if err != nil {
+			store.blooms.setIncomplete()
+			continue
+		}
+
+		store.addBloomFilterForIndex(archiveChecksum, indexEntries, true)
+	}
+}
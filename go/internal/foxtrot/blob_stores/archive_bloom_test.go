@@ -0,0 +1,121 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+func reopenTestStoreV1WithBlooms(
+	t *testing.T,
+	packed inventoryArchiveV1,
+	loose localHashBucketed,
+) inventoryArchiveV1 {
+	t.Helper()
+
+	store := makeTestStoreV1ForHash(
+		packed.basePath,
+		packed.cachePath,
+		markl.FormatHashSha256,
+		loose,
+	)
+
+	store.blooms = &archiveBloomFilters{}
+
+	if err := store.loadIndex(); err != nil {
+		t.Fatalf("loadIndex: %v", err)
+	}
+
+	return store
+}
+
+func TestArchiveBloomFiltersAnswerMisses(t *testing.T) {
+	packed, loose, ids := packTestBlobsV1(
+		t,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+		"first archived blob",
+		"second archived blob",
+	)
+
+	var archiveChecksum string
+
+	for _, entry := range packed.index {
+		archiveChecksum = entry.ArchiveChecksum
+	}
+
+	if !files.Exists(packed.bloomFilterPath(archiveChecksum)) {
+		t.Fatalf("expected pack to write a bloom filter for %s", archiveChecksum)
+	}
+
+	store := reopenTestStoreV1WithBlooms(t, packed, loose)
+
+	if store.blooms.incomplete || len(store.blooms.filters) != 1 {
+		t.Fatalf("expected the archive's filter to be loaded")
+	}
+
+	for _, id := range ids {
+		if !store.HasBlob(id) {
+			t.Errorf("expected %s to be found", id)
+		}
+	}
+
+	missingHash := sha256.Sum256([]byte("in no archive"))
+
+	missing, repool := markl.FormatHashSha256.GetBlobIdForHexString(
+		hex.EncodeToString(missingHash[:]),
+	)
+	defer repool()
+
+	if store.HasBlob(missing) {
+		t.Errorf("expected %s to be missing", missing)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		store.blooms.mayContain(missingHash[:])
+	}); allocs != 0 {
+		t.Errorf("expected misses not to allocate, got %.0f allocations", allocs)
+	}
+}
+
+func TestArchiveBloomFiltersBackfilled(t *testing.T) {
+	packed, loose, ids := packTestBlobsV1(
+		t,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+		"archived before bloom filters",
+	)
+
+	archiveChecksum := packed.index[ids[0].String()].ArchiveChecksum
+	bloomPath := packed.bloomFilterPath(archiveChecksum)
+
+	if err := os.Remove(bloomPath); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	store := reopenTestStoreV1WithBlooms(t, packed, loose)
+
+	if store.blooms.incomplete || len(store.blooms.filters) != 1 {
+		t.Fatalf("expected the archive's filter to be rebuilt from its index")
+	}
+
+	if !files.Exists(bloomPath) {
+		t.Errorf("expected the filter file to be written back")
+	}
+
+	if !store.HasBlob(ids[0]) {
+		t.Errorf("expected %s to be found", ids[0])
+	}
+}
@@ -0,0 +1,425 @@
+package blob_stores
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// ConvertibleArchive is implemented by blob stores that can re-pack the v0
+// archives left in their directory, by a store whose config was upgraded from
+// a v0 inventory archive, into archives of their own format.
+type ConvertibleArchive interface {
+	ConvertArchives(options PackOptions) error
+}
+
+var _ ConvertibleArchive = inventoryArchiveV1{}
+
+// ConvertArchives re-packs the entries of every v0 archive in the store's
+// archive directory through the v1 writer, with deltas when the config or
+// options.Delta enables them. Each new archive is validated and recorded in
+// the manifest and cache before the v0 data and index files and the v0 cache
+// are removed, so a crash leaves at most v0 files whose entries the next
+// conversion finds already indexed and only removes. Of options, Context,
+// MaxPackSize, Delta, NoWait and TapWriter apply.
+func (store inventoryArchiveV1) ConvertArchives(
+	options PackOptions,
+) (err error) {
+	ctx := options.Context
+	tw := options.TapWriter
+
+	// every entry of a v0 archive has to make it into the new archives
+	// before the v0 files are removed
+	options.SkipMissingBlobs = false
+
+	if err = errIfReadOnly(store.readOnly, store.basePath); err != nil {
+		return err
+	}
+
+	if err = errIfDryRun(store.dryRun, store.basePath); err != nil {
+		return err
+	}
+
+	lock, err := lockStore(store.basePath, options.NoWait)
+	if err != nil {
+		return err
+	}
+
+	defer errors.Deferred(&err, lock.Unlock)
+
+	if err = store.refreshIndex(); err != nil {
+		return err
+	}
+
+	indexPaths, err := filepath.Glob(filepath.Join(
+		store.archivesPath(),
+		"*"+inventory_archive.IndexFileExtension,
+	))
+	if err != nil {
+		err = errors.Wrapf(err, "globbing v0 index files")
+		return err
+	}
+
+	// the v0 archives are only removed once all of them are converted
+	var v0Bytes uint64
+
+	for _, indexPath := range indexPaths {
+		dataPath := strings.TrimSuffix(
+			indexPath,
+			inventory_archive.IndexFileExtension,
+		) + inventory_archive.DataFileExtension
+
+		if info, statErr := os.Stat(dataPath); statErr == nil {
+			v0Bytes += uint64(info.Size())
+		}
+	}
+
+	if err = checkFreeSpaceForArchives(
+		store.basePath,
+		store.cachePath,
+		v0Bytes,
+		0,
+		len(store.index),
+	); err != nil {
+		tapNotOk(tw, "check free space", err)
+		return err
+	}
+
+	var converted []string
+	var added []inventory_archive.ManifestArchive
+
+	for _, indexPath := range indexPaths {
+		if err = packContextCancelled(ctx); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		archiveChecksum := strings.TrimSuffix(
+			filepath.Base(indexPath),
+			inventory_archive.IndexFileExtension,
+		)
+
+		if _, decodeErr := hex.DecodeString(archiveChecksum); decodeErr != nil {
+			continue
+		}
+
+		var archives []inventory_archive.ManifestArchive
+
+		if archives, err = store.convertArchiveV0(
+			options,
+			archiveChecksum,
+		); err != nil {
+			tapNotOk(tw, fmt.Sprintf("convert archive %s", archiveChecksum), err)
+			return err
+		}
+
+		tapOk(tw, fmt.Sprintf(
+			"convert archive %s (%d v1 archives)",
+			archiveChecksum,
+			len(archives),
+		))
+
+		converted = append(converted, archiveChecksum)
+		added = append(added, archives...)
+	}
+
+	if len(added) > 0 {
+		var manifestChanged bool
+
+		if manifestChanged, err = store.commitManifest(added); err != nil {
+			tapNotOk(tw, "write manifest", err)
+			return err
+		}
+
+		tapOk(tw, "write manifest")
+
+		// Another process packed while this one converted: pick up its
+		// archives too.
+		if manifestChanged {
+			if err = store.rebuildIndex(); err != nil {
+				tapNotOk(tw, "write cache", err)
+				return err
+			}
+		}
+	}
+
+	if len(converted) > 0 {
+		if err = store.writeCacheV1(); err != nil {
+			tapNotOk(tw, "write cache", err)
+			return err
+		}
+
+		tapOk(tw, "write cache")
+	}
+
+	for _, archiveChecksum := range converted {
+		if err = store.removeArchiveV0(archiveChecksum); err != nil {
+			desc := fmt.Sprintf("remove v0 archive %s", archiveChecksum)
+			tapNotOk(tw, desc, err)
+			return err
+		}
+
+		tapOk(tw, fmt.Sprintf("remove v0 archive %s", archiveChecksum))
+	}
+
+	v0CachePath := filepath.Join(store.cachePath, inventory_archive.CacheFileName)
+
+	if err = os.Remove(v0CachePath); err != nil && !errors.IsNotExist(err) {
+		err = errors.Wrapf(err, "removing v0 cache %s", v0CachePath)
+		return err
+	}
+
+	return nil
+}
+
+// convertArchiveV0 validates a v0 archive and writes its entries that are not
+// yet in the index to new archives, in the v0 archive's hash format. The v0
+// entries are read through an inventoryArchiveV0 over the archive, which
+// stands in for the loose blob store while packing.
+func (store inventoryArchiveV1) convertArchiveV0(
+	options PackOptions,
+	archiveChecksum string,
+) (archives []inventory_archive.ManifestArchive, err error) {
+	source, metas, err := store.readArchiveV0(archiveChecksum)
+	if err != nil {
+		return archives, err
+	}
+
+	converter := store
+	converter.defaultHash = source.defaultHash
+	converter.looseBlobStore = source
+	converter.forceDelta = options.Delta
+
+	strategy, similarity, err := converter.similarityStrategy()
+	if err != nil {
+		return archives, err
+	}
+
+	if similarity {
+		sketchFn := converter.sketchFn(strategy)
+
+		for i := range metas {
+			marklId, repool := source.defaultHash.GetBlobIdForHexString(
+				hex.EncodeToString(metas[i].digest),
+			)
+
+			_, metas[i].sketch, err = sketchFn(marklId)
+			repool()
+
+			if err != nil {
+				return archives, err
+			}
+		}
+	}
+
+	maxPackSize := options.MaxPackSize
+	if maxPackSize == 0 {
+		maxPackSize = store.config.GetMaxPackSize()
+	}
+
+	for _, chunkMetas := range converter.splitBlobChunksV1(metas, maxPackSize) {
+		if err = packContextCancelled(options.Context); err != nil {
+			err = errors.Wrap(err)
+			return archives, err
+		}
+
+		dataPath, fullCount, deltaCount, packErr := converter.packChunkArchiveV1(
+			options.Context,
+			options,
+			chunkMetas,
+		)
+		if packErr != nil {
+			err = packErr
+			return archives, err
+		}
+
+		entryCount := fullCount + deltaCount
+
+		if err = converter.validateArchiveV1(dataPath, entryCount); err != nil {
+			return archives, err
+		}
+
+		archive, archiveErr := manifestArchiveForDataPath(dataPath, entryCount)
+		if archiveErr != nil {
+			err = archiveErr
+			return archives, err
+		}
+
+		archives = append(archives, archive)
+	}
+
+	for key := range source.index {
+		if _, ok := store.index[key]; !ok {
+			err = errors.Errorf(
+				"converting archive %s: entry %s was not written",
+				archiveChecksum,
+				key,
+			)
+			return archives, err
+		}
+	}
+
+	return archives, err
+}
+
+// readArchiveV0 checks a v0 archive's data file against its checksum and its
+// index, and returns a v0 store over the archive along with the metadata of
+// its entries not yet in the index, sorted by digest.
+func (store inventoryArchiveV1) readArchiveV0(
+	archiveChecksum string,
+) (source inventoryArchiveV0, metas []packedBlobMeta, err error) {
+	dataPath := filepath.Join(
+		store.archivesPath(),
+		archiveChecksum+inventory_archive.DataFileExtension,
+	)
+
+	dataFile, err := os.Open(dataPath)
+	if err != nil {
+		err = errors.Wrapf(err, "opening v0 archive %s", dataPath)
+		return source, metas, err
+	}
+
+	defer errors.DeferredCloser(&err, dataFile)
+
+	dataReader, err := inventory_archive.NewDataReader(dataFile, store.encryption)
+	if err != nil {
+		err = errors.Wrapf(err, "reading v0 archive header %s", dataPath)
+		return source, metas, err
+	}
+
+	if err = dataReader.Validate(); err != nil {
+		err = errors.Wrapf(err, "validating v0 archive %s", dataPath)
+		return source, metas, err
+	}
+
+	hashFormatId := dataReader.HashFormatId()
+
+	if source.defaultHash, err = markl.GetFormatHashOrError(
+		hashFormatId,
+	); err != nil {
+		err = errors.Wrapf(err, "v0 archive %s", dataPath)
+		return source, metas, err
+	}
+
+	indexEntries, err := store.readIndexFileV0(archiveChecksum, hashFormatId)
+	if err != nil {
+		return source, metas, err
+	}
+
+	source.basePath = store.basePath
+	source.encryption = store.encryption
+	source.looseBlobStore = store.looseBlobStore
+	source.index = make(map[string]archiveEntry, len(indexEntries))
+
+	for _, ie := range indexEntries {
+		marklId, repool := source.defaultHash.GetBlobIdForHexString(
+			hex.EncodeToString(ie.Hash),
+		)
+		key := marklId.String()
+		repool()
+
+		source.index[key] = archiveEntry{
+			ArchiveChecksum: archiveChecksum,
+			Offset:          ie.PackOffset,
+			StoredSize:      ie.StoredSize,
+		}
+
+		if _, ok := store.index[key]; ok {
+			continue
+		}
+
+		dataEntry, readErr := dataReader.ReadEntryAt(ie.PackOffset)
+		if readErr != nil {
+			err = errors.Wrapf(
+				readErr,
+				"reading v0 entry at offset %d in %s",
+				ie.PackOffset,
+				dataPath,
+			)
+			return source, metas, err
+		}
+
+		if !bytes.Equal(dataEntry.Hash, ie.Hash) {
+			err = errors.Errorf(
+				"v0 archive %s: index entry %x points at entry %x",
+				dataPath,
+				ie.Hash,
+				dataEntry.Hash,
+			)
+			return source, metas, err
+		}
+
+		metas = append(metas, packedBlobMeta{
+			digest: ie.Hash,
+			size:   dataEntry.LogicalSize,
+		})
+	}
+
+	sort.Slice(metas, func(i, j int) bool {
+		return bytes.Compare(metas[i].digest, metas[j].digest) < 0
+	})
+
+	return source, metas, err
+}
+
+func (store inventoryArchiveV1) readIndexFileV0(
+	archiveChecksum string,
+	hashFormatId string,
+) (indexEntries []inventory_archive.IndexEntry, err error) {
+	indexPath := filepath.Join(
+		store.archivesPath(),
+		archiveChecksum+inventory_archive.IndexFileExtension,
+	)
+
+	contents, err := os.ReadFile(indexPath)
+	if err != nil {
+		err = errors.Wrapf(err, "opening v0 index %s", indexPath)
+		return indexEntries, err
+	}
+
+	indexReader, err := inventory_archive.NewIndexReader(
+		bytes.NewReader(contents),
+		int64(len(contents)),
+		hashFormatId,
+	)
+	if err != nil {
+		err = errors.Wrapf(err, "reading v0 index %s", indexPath)
+		return indexEntries, err
+	}
+
+	if indexEntries, err = indexReader.ReadAllEntries(); err != nil {
+		err = errors.Wrapf(err, "reading entries from v0 index %s", indexPath)
+		return indexEntries, err
+	}
+
+	return indexEntries, err
+}
+
+// removeArchiveV0 removes a converted v0 archive's index and then its data
+// file, so that an interrupted removal leaves no index of a missing archive
+// for the next conversion to trip over.
+func (store inventoryArchiveV1) removeArchiveV0(
+	archiveChecksum string,
+) (err error) {
+	for _, extension := range []string{
+		inventory_archive.IndexFileExtension,
+		inventory_archive.DataFileExtension,
+	} {
+		path := filepath.Join(store.archivesPath(), archiveChecksum+extension)
+
+		if err = os.Remove(path); err != nil && !errors.IsNotExist(err) {
+			err = errors.Wrapf(err, "removing %s", path)
+			return err
+		}
+	}
+
+	return nil
+}
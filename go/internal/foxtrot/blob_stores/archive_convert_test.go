@@ -0,0 +1,300 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+type convertTestBlob struct {
+	id   domain_interfaces.MarklId
+	data []byte
+}
+
+// packTestArchiveV0 packs blobs into a v0 archive in basePath, leaving a v0
+// cache in cachePath.
+func packTestArchiveV0(
+	t *testing.T,
+	basePath, cachePath string,
+	contents ...string,
+) (blobs []convertTestBlob) {
+	t.Helper()
+
+	stub := &stubBlobStore{blobData: make(map[string][]byte)}
+
+	for _, content := range contents {
+		data := []byte(content)
+		rawHash := sha256.Sum256(data)
+
+		id, repool := markl.FormatHashSha256.GetBlobIdForHexString(
+			hex.EncodeToString(rawHash[:]),
+		)
+		t.Cleanup(repool)
+
+		stub.allBlobIds = append(stub.allBlobIds, id)
+		stub.blobData[id.String()] = data
+		blobs = append(blobs, convertTestBlob{id: id, data: data})
+	}
+
+	store := inventoryArchiveV0{
+		defaultHash:    markl.FormatHashSha256,
+		basePath:       basePath,
+		cachePath:      cachePath,
+		looseBlobStore: stub,
+		index:          make(map[string]archiveEntry),
+		config: blob_store_configs.TomlInventoryArchiveV0{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+	}
+
+	if err := store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("v0 Pack: %v", err)
+	}
+
+	return blobs
+}
+
+func globArchiveFiles(t *testing.T, basePath, extension string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(
+		filepath.Join(basePath, "archives", "*"+extension),
+	)
+	if err != nil {
+		t.Fatalf("globbing %s files: %v", extension, err)
+	}
+
+	return matches
+}
+
+func assertConvertedBlobsReadable(
+	t *testing.T,
+	store inventoryArchiveV1,
+	blobs []convertTestBlob,
+) {
+	t.Helper()
+
+	for _, blob := range blobs {
+		if _, ok := store.index[blob.id.String()]; !ok {
+			t.Fatalf("expected %s in the archive index", blob.id)
+		}
+
+		reader, err := store.MakeBlobReader(blob.id)
+		if err != nil {
+			t.Fatalf("MakeBlobReader %s: %v", blob.id, err)
+		}
+
+		actual, err := io.ReadAll(reader)
+		reader.Close()
+
+		if err != nil {
+			t.Fatalf("ReadAll %s: %v", blob.id, err)
+		}
+
+		if !bytes.Equal(actual, blob.data) {
+			t.Errorf("%s: expected %q, got %q", blob.id, blob.data, actual)
+		}
+	}
+}
+
+func TestConvertArchivesRepacksV0Archives(t *testing.T) {
+	basePath := t.TempDir()
+	cachePath := t.TempDir()
+
+	blobs := packTestArchiveV0(
+		t,
+		basePath,
+		cachePath,
+		"convert test blob one",
+		"convert test blob two",
+	)
+
+	store := makeTestStoreV1ForHash(
+		basePath,
+		cachePath,
+		markl.FormatHashSha256,
+		&stubBlobStore{},
+	)
+
+	if err := store.ConvertArchives(PackOptions{}); err != nil {
+		t.Fatalf("ConvertArchives: %v", err)
+	}
+
+	assertConvertedBlobsReadable(t, store, blobs)
+
+	for _, extension := range []string{
+		inventory_archive.DataFileExtension,
+		inventory_archive.IndexFileExtension,
+	} {
+		if matches := globArchiveFiles(t, basePath, extension); len(matches) != 0 {
+			t.Errorf("expected v0 %s files to be removed, got %v", extension, matches)
+		}
+	}
+
+	if matches := globArchiveFiles(
+		t,
+		basePath,
+		inventory_archive.DataFileExtensionV1,
+	); len(matches) != 1 {
+		t.Fatalf("expected 1 v1 data file, got %d", len(matches))
+	}
+
+	v0CachePath := filepath.Join(cachePath, inventory_archive.CacheFileName)
+
+	if _, err := os.Stat(v0CachePath); !os.IsNotExist(err) {
+		t.Errorf("expected the v0 cache to be removed, got %v", err)
+	}
+
+	manifest, ok, err := store.readManifest()
+	if err != nil || !ok {
+		t.Fatalf("expected a manifest, got %v (ok %t)", err, ok)
+	}
+
+	if len(manifest.Archives) != 1 || manifest.Archives[0].EntryCount != 2 {
+		t.Fatalf("expected 1 archive of 2 entries in manifest, got %+v", manifest)
+	}
+
+	reopened := makeTestStoreV1ForHash(
+		basePath,
+		cachePath,
+		markl.FormatHashSha256,
+		&stubBlobStore{},
+	)
+
+	if err := reopened.loadIndex(); err != nil {
+		t.Fatalf("loadIndex: %v", err)
+	}
+
+	assertConvertedBlobsReadable(t, reopened, blobs)
+}
+
+func TestConvertArchivesWithDelta(t *testing.T) {
+	basePath := t.TempDir()
+	cachePath := t.TempDir()
+
+	commonPrefix := strings.Repeat("shared content block ", 100)
+
+	blobs := packTestArchiveV0(
+		t,
+		basePath,
+		cachePath,
+		commonPrefix+" unique suffix alpha",
+		commonPrefix+" unique suffix beta",
+		commonPrefix+" unique suffix gamma",
+	)
+
+	store := makeTestStoreV1ForHash(
+		basePath,
+		cachePath,
+		markl.FormatHashSha256,
+		&stubBlobStore{},
+	)
+
+	// as upgraded from a v0 config: deltas configured but disabled
+	store.config = blob_store_configs.TomlInventoryArchiveV1{
+		HashTypeId:      markl.FormatIdHashSha256,
+		CompressionType: compression_type.CompressionTypeNone,
+		Delta: blob_store_configs.DeltaConfig{
+			Algorithm:   "bsdiff",
+			MinBlobSize: 1,
+			MaxBlobSize: 10485760,
+			SizeRatio:   2.0,
+		},
+	}
+
+	if err := store.ConvertArchives(PackOptions{Delta: true}); err != nil {
+		t.Fatalf("ConvertArchives: %v", err)
+	}
+
+	var deltaCount int
+
+	for _, entry := range store.index {
+		if entry.EntryType == inventory_archive.EntryTypeDelta {
+			deltaCount++
+		}
+	}
+
+	if deltaCount == 0 {
+		t.Fatal("expected at least one delta entry, got none")
+	}
+
+	assertConvertedBlobsReadable(t, store, blobs)
+}
+
+func TestConvertArchivesRemovesAlreadyConvertedV0Files(t *testing.T) {
+	basePath := t.TempDir()
+	cachePath := t.TempDir()
+
+	blobs := packTestArchiveV0(t, basePath, cachePath, "converted once")
+
+	// keep the v0 files, as a conversion interrupted before removing them
+	// would have
+	leftovers := make(map[string][]byte)
+
+	for _, extension := range []string{
+		inventory_archive.DataFileExtension,
+		inventory_archive.IndexFileExtension,
+	} {
+		for _, path := range globArchiveFiles(t, basePath, extension) {
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading %s: %v", path, err)
+			}
+
+			leftovers[path] = contents
+		}
+	}
+
+	store := makeTestStoreV1ForHash(
+		basePath,
+		cachePath,
+		markl.FormatHashSha256,
+		&stubBlobStore{},
+	)
+
+	if err := store.ConvertArchives(PackOptions{}); err != nil {
+		t.Fatalf("ConvertArchives: %v", err)
+	}
+
+	for path, contents := range leftovers {
+		if err := os.WriteFile(path, contents, 0o644); err != nil {
+			t.Fatalf("restoring %s: %v", path, err)
+		}
+	}
+
+	if err := store.ConvertArchives(PackOptions{}); err != nil {
+		t.Fatalf("second ConvertArchives: %v", err)
+	}
+
+	if matches := globArchiveFiles(
+		t,
+		basePath,
+		inventory_archive.DataFileExtensionV1,
+	); len(matches) != 1 {
+		t.Fatalf("expected 1 v1 data file, got %d", len(matches))
+	}
+
+	if matches := globArchiveFiles(
+		t,
+		basePath,
+		inventory_archive.IndexFileExtension,
+	); len(matches) != 0 {
+		t.Errorf("expected v0 index files to be removed, got %v", matches)
+	}
+
+	assertConvertedBlobsReadable(t, store, blobs)
+}
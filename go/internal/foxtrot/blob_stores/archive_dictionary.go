@@ -0,0 +1,60 @@
+package blob_stores
+
+import (
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+const (
+	// blobs larger than this are left out of dictionary training: zstd gains
+	// little from a dictionary once a blob has enough context of its own
+	archiveDictionarySampleMaxBlobSize = 16 * 1024
+
+	// the trainer sees at most this many bytes per byte of dictionary
+	archiveDictionarySampleBudgetRatio = 100
+)
+
+// trainArchiveDictionary trains a dictionary over the small blobs of an
+// archive, in order, until the sample budget for maxSize is spent. Only the
+// samples are loaded, so memory stays bounded by the budget. Blobs that
+// cannot be read are left out of the sample. Returns nil if the blobs do not
+// share enough content. The data writer embeds the dictionary in the header
+// of the archive it is trained for.
+func trainArchiveDictionary(
+	metas []packedBlobMeta,
+	maxSize uint64,
+	readBlob func(digest []byte) ([]byte, error),
+) []byte {
+	if maxSize == 0 {
+		maxSize = compression_type.DictionaryMaxSizeDefault
+	}
+
+	budget := maxSize * archiveDictionarySampleBudgetRatio
+
+	var samples [][]byte
+	var sampled uint64
+
+	for _, meta := range metas {
+		if meta.size == 0 || meta.size > archiveDictionarySampleMaxBlobSize {
+			continue
+		}
+
+		if sampled+meta.size > budget {
+			break
+		}
+
+		data, err := readBlob(meta.digest)
+		if err != nil {
+			continue
+		}
+
+		samples = append(samples, data)
+		sampled += uint64(len(data))
+	}
+
+	// a dictionary only pays for itself when it is shared by many blobs
+	if len(samples) < 2 {
+		return nil
+	}
+
+	return compression_type.TrainDictionary(samples, int(maxSize))
+}
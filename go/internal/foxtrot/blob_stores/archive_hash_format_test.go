@@ -0,0 +1,175 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func makeTestStoreV1ForHash(
+	basePath, cachePath string,
+	hashFormat markl.FormatHash,
+	looseBlobStore domain_interfaces.BlobStore,
+) inventoryArchiveV1 {
+	return inventoryArchiveV1{
+		defaultHash:    hashFormat,
+		basePath:       basePath,
+		cachePath:      cachePath,
+		looseBlobStore: looseBlobStore,
+		index:          make(map[string]archiveEntryV1),
+		generation:     &archiveGeneration{},
+		config: blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      blob_store_configs.HashType(hashFormat.GetMarklFormatId()),
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+	}
+}
+
+func TestArchiveV1ReadableAfterDefaultHashChange(t *testing.T) {
+	basePath := t.TempDir()
+	cachePath := t.TempDir()
+
+	testData := []byte("blob packed before the default hash changed")
+	rawHash := sha256.Sum256(testData)
+
+	id, repool := markl.FormatHashSha256.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	defer repool()
+
+	stub := &stubBlobStore{
+		allBlobIds: []domain_interfaces.MarklId{id},
+		blobData:   map[string][]byte{id.String(): testData},
+	}
+
+	sha256Store := makeTestStoreV1ForHash(
+		basePath,
+		cachePath,
+		markl.FormatHashSha256,
+		stub,
+	)
+
+	if err := sha256Store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	assertReadable := func(t *testing.T, store inventoryArchiveV1) {
+		t.Helper()
+
+		if _, ok := store.index[id.String()]; !ok {
+			t.Fatalf("expected %s in the archive index", id)
+		}
+
+		reader, err := store.MakeBlobReader(id)
+		if err != nil {
+			t.Fatalf("MakeBlobReader: %v", err)
+		}
+
+		defer reader.Close()
+
+		actual, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+
+		if !bytes.Equal(actual, testData) {
+			t.Errorf("expected %q, got %q", testData, actual)
+		}
+	}
+
+	// the first load after the change rebuilds the index from the archives
+	// and writes a cache per hash format
+	os.RemoveAll(cachePath)
+
+	rebuilt := makeTestStoreV1ForHash(
+		basePath,
+		cachePath,
+		markl.FormatHashBlake2b256,
+		&stubBlobStore{},
+	)
+
+	if err := rebuilt.loadIndex(); err != nil {
+		t.Fatalf("loadIndex: %v", err)
+	}
+
+	assertReadable(t, rebuilt)
+
+	sha256CachePath := rebuilt.cacheFilePathForFormat(markl.FormatIdHashSha256)
+
+	if _, err := os.Stat(sha256CachePath); err != nil {
+		t.Fatalf("expected a cache for sha256 entries: %v", err)
+	}
+
+	// later loads read the caches of both formats
+	cached := makeTestStoreV1ForHash(
+		basePath,
+		cachePath,
+		markl.FormatHashBlake2b256,
+		&stubBlobStore{},
+	)
+
+	if err := cached.loadIndex(); err != nil {
+		t.Fatalf("loadIndex: %v", err)
+	}
+
+	caches, ok := cached.tryReadCache()
+	if !ok || len(caches) != 2 {
+		t.Fatalf(
+			"expected caches for two hash formats, got %d (ok %t)",
+			len(caches),
+			ok,
+		)
+	}
+
+	assertReadable(t, cached)
+}
+
+func TestCollectBlobMetasParallelSkipsOtherHashFormats(t *testing.T) {
+	sha256Id, repoolSha256 := markl.FormatHashSha256.GetMarklIdForString(
+		"sha256 blob",
+	)
+	defer repoolSha256()
+
+	blake2bId, repoolBlake2b := markl.FormatHashBlake2b256.GetMarklIdForString(
+		"blake2b256 blob",
+	)
+	defer repoolBlake2b()
+
+	stub := &stubBlobStore{
+		allBlobIds: []domain_interfaces.MarklId{sha256Id, blake2bId},
+		blobData: map[string][]byte{
+			sha256Id.String():  []byte("sha256 blob"),
+			blake2bId.String(): []byte("blake2b256 blob"),
+		},
+	}
+
+	metas, err := collectBlobMetasParallel(
+		nil,
+		nil,
+		stub,
+		markl.FormatIdHashBlake2b256,
+		make(map[string]bool),
+		PackOptions{},
+		func(id domain_interfaces.MarklId) (uint64, error) {
+			return uint64(len(stub.blobData[id.String()])), nil
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("collectBlobMetasParallel: %v", err)
+	}
+
+	if len(metas) != 1 || !bytes.Equal(metas[0].digest, blake2bId.GetBytes()) {
+		t.Fatalf("expected only the blake2b256 blob, got %d metas", len(metas))
+	}
+}
@@ -0,0 +1,405 @@
+package blob_stores
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// archiveGeneration holds the manifest generation that the in-memory index
+// reflects. It is shared by all copies of a store value, like its index. A
+// nil generation is always 0.
+type archiveGeneration struct {
+	lock  sync.Mutex
+	value uint64
+}
+
+func (generation *archiveGeneration) get() uint64 {
+	if generation == nil {
+		return 0
+	}
+
+	generation.lock.Lock()
+	defer generation.lock.Unlock()
+
+	return generation.value
+}
+
+func (generation *archiveGeneration) set(value uint64) {
+	if generation == nil {
+		return
+	}
+
+	generation.lock.Lock()
+	defer generation.lock.Unlock()
+
+	generation.value = value
+}
+
+func (store inventoryArchiveV1) manifestPath() string {
+	return filepath.Join(store.basePath, inventory_archive.ManifestFileName)
+}
+
+// cacheFilePath names the index cache after the manifest generation it was
+// built from, so that a cache from before another process changed the
+// archives is never read. Stores without a manifest use generation 0 and the
+// unsuffixed name.
+func (store inventoryArchiveV1) cacheFilePath() string {
+	name := inventory_archive.CacheFileNameV1
+
+	if generation := store.generation.get(); generation > 0 {
+		name = fmt.Sprintf("%s-%d", name, generation)
+	}
+
+	return filepath.Join(store.cachePath, name)
+}
+
+// cacheFilePathForFormat names the cache of the entries in one hash format.
+// Entries in the store's default format use cacheFilePath, and those of
+// archives written before the repo changed its default hash a sibling
+// suffixed with their format id.
+func (store inventoryArchiveV1) cacheFilePathForFormat(
+	hashFormatId string,
+) string {
+	if hashFormatId == store.defaultHash.GetMarklFormatId() {
+		return store.cacheFilePath()
+	}
+
+	return store.cacheFilePath() + "." + hashFormatId
+}
+
+// cacheFilePaths lists the cache files of the current generation, starting
+// with that of the default hash format.
+func (store inventoryArchiveV1) cacheFilePaths() (paths []string) {
+	current := store.cacheFilePath()
+	paths = append(paths, current)
+
+	matches, _ := filepath.Glob(current + ".*")
+
+	for _, match := range matches {
+		if !strings.HasSuffix(match, ".tmp") {
+			paths = append(paths, match)
+		}
+	}
+
+	return paths
+}
+
+// removeStaleCaches removes the caches of generations other than the
+// current one.
+func (store inventoryArchiveV1) removeStaleCaches() {
+	current := store.cacheFilePath()
+
+	matches, _ := filepath.Glob(
+		filepath.Join(store.cachePath, inventory_archive.CacheFileNameV1+"*"),
+	)
+
+	for _, match := range matches {
+		if match == current || strings.HasPrefix(match, current+".") {
+			continue
+		}
+
+		if !strings.HasSuffix(match, ".tmp") {
+			os.Remove(match)
+		}
+	}
+}
+
+// readManifest reads the store's manifest. ok is false, with an empty
+// manifest of generation 0, when the store has none yet.
+func (store inventoryArchiveV1) readManifest() (
+	manifest inventory_archive.Manifest,
+	ok bool,
+	err error,
+) {
+	manifestPath := store.manifestPath()
+
+	contents, err := os.ReadFile(manifestPath)
+	if errors.IsNotExist(err) {
+		err = nil
+		return manifest, false, err
+	} else if err != nil {
+		err = errors.Wrapf(err, "reading manifest %s", manifestPath)
+		return manifest, false, err
+	}
+
+	if manifest, err = inventory_archive.ReadManifest(
+		bytes.NewReader(contents),
+	); err != nil {
+		err = errors.Wrapf(err, "reading manifest %s", manifestPath)
+		return manifest, false, err
+	}
+
+	return manifest, true, err
+}
+
+// discoverArchives lists the archives found on disk, for stores packed
+// before they had a manifest: every external index file, and every v2 data
+// file without one.
+func (store inventoryArchiveV1) discoverArchives() (
+	archives []inventory_archive.ManifestArchive,
+	err error,
+) {
+	indexMatches, err := filepath.Glob(filepath.Join(
+		store.archivesPath(),
+		"*"+inventory_archive.IndexFileExtensionV1,
+	))
+	if err != nil {
+		err = errors.Wrapf(err, "globbing v1 index files")
+		return archives, err
+	}
+
+	indexed := make(map[string]struct{}, len(indexMatches))
+
+	for _, indexPath := range indexMatches {
+		archiveChecksum := strings.TrimSuffix(
+			filepath.Base(indexPath),
+			inventory_archive.IndexFileExtensionV1,
+		)
+
+		checksum, decodeErr := hex.DecodeString(archiveChecksum)
+		if decodeErr != nil {
+			continue
+		}
+
+		var entryCount uint64
+
+		if entryCount, err = readIndexFileEntryCount(indexPath); err != nil {
+			return archives, err
+		}
+
+		archives = append(archives, inventory_archive.ManifestArchive{
+			Checksum:        checksum,
+			DataFileVersion: inventory_archive.DataFileVersionV1,
+			EntryCount:      entryCount,
+		})
+
+		indexed[archiveChecksum] = struct{}{}
+	}
+
+	dataMatches, err := filepath.Glob(filepath.Join(
+		store.archivesPath(),
+		"*"+inventory_archive.DataFileExtensionV2,
+	))
+	if err != nil {
+		err = errors.Wrapf(err, "globbing v2 data files")
+		return archives, err
+	}
+
+	for _, dataPath := range dataMatches {
+		archiveChecksum := strings.TrimSuffix(
+			filepath.Base(dataPath),
+			inventory_archive.DataFileExtensionV2,
+		)
+
+		if _, ok := indexed[archiveChecksum]; ok {
+			continue
+		}
+
+		checksum, decodeErr := hex.DecodeString(archiveChecksum)
+		if decodeErr != nil {
+			continue
+		}
+
+		var indexEntries []inventory_archive.IndexEntryV1
+
+		if indexEntries, _, err = store.readFooterIndex(dataPath); err != nil {
+			return archives, err
+		}
+
+		archives = append(archives, inventory_archive.ManifestArchive{
+			Checksum:        checksum,
+			DataFileVersion: inventory_archive.DataFileVersionV2,
+			EntryCount:      uint64(len(indexEntries)),
+		})
+	}
+
+	return archives, err
+}
+
+func readIndexFileEntryCount(indexPath string) (entryCount uint64, err error) {
+	var indexReader *inventory_archive.IndexReaderV1
+
+	if indexReader, err = openIndexFileV1(indexPath); err != nil {
+		return entryCount, err
+	}
+
+	return indexReader.EntryCount(), err
+}
+
+func openIndexFileV1(
+	indexPath string,
+) (indexReader *inventory_archive.IndexReaderV1, err error) {
+	contents, err := os.ReadFile(indexPath)
+	if err != nil {
+		err = errors.Wrapf(err, "opening v1 index %s", indexPath)
+		return indexReader, err
+	}
+
+	if indexReader, err = inventory_archive.NewIndexReaderV1(
+		bytes.NewReader(contents),
+		int64(len(contents)),
+	); err != nil {
+		err = errors.Wrapf(err, "reading v1 index %s", indexPath)
+		return indexReader, err
+	}
+
+	return indexReader, err
+}
+
+// readArchiveIndex reads an archive's index from its external index file when
+// there is one, and otherwise from the footer of its v2 data file, along with
+// the hash format of its entries.
+func (store inventoryArchiveV1) readArchiveIndex(
+	archive inventory_archive.ManifestArchive,
+) (
+	indexEntries []inventory_archive.IndexEntryV1,
+	hashFormatId string,
+	err error,
+) {
+	archiveChecksum := hex.EncodeToString(archive.Checksum)
+
+	indexPath := filepath.Join(
+		store.archivesPath(),
+		archiveChecksum+inventory_archive.IndexFileExtensionV1,
+	)
+
+	if _, statErr := os.Stat(indexPath); statErr == nil ||
+		archive.DataFileVersion != inventory_archive.DataFileVersionV2 {
+		var indexReader *inventory_archive.IndexReaderV1
+
+		if indexReader, err = openIndexFileV1(indexPath); err != nil {
+			return indexEntries, hashFormatId, err
+		}
+
+		hashFormatId = indexReader.HashFormatId()
+
+		if indexEntries, err = indexReader.ReadAllEntries(); err != nil {
+			err = errors.Wrapf(err, "reading entries from v1 index %s", indexPath)
+			return indexEntries, hashFormatId, err
+		}
+
+		return indexEntries, hashFormatId, err
+	}
+
+	dataPaths := store.archiveDataPaths(
+		archiveChecksum,
+		inventory_archive.DataFileExtensionV2,
+	)
+
+	dataPath := dataPaths[0]
+
+	for _, candidate := range dataPaths {
+		if _, statErr := os.Stat(candidate); statErr == nil {
+			dataPath = candidate
+			break
+		}
+	}
+
+	return store.readFooterIndex(dataPath)
+}
+
+func manifestArchiveForDataPath(
+	dataPath string,
+	entryCount int,
+) (archive inventory_archive.ManifestArchive, err error) {
+	extension := filepath.Ext(dataPath)
+
+	archive.DataFileVersion = inventory_archive.DataFileVersionV1
+	archive.EntryCount = uint64(entryCount)
+
+	if extension == inventory_archive.DataFileExtensionV2 {
+		archive.DataFileVersion = inventory_archive.DataFileVersionV2
+	}
+
+	if archive.Checksum, err = hex.DecodeString(
+		strings.TrimSuffix(filepath.Base(dataPath), extension),
+	); err != nil {
+		err = errors.Wrapf(err, "archive checksum of %s", dataPath)
+		return archive, err
+	}
+
+	return archive, err
+}
+
+// refreshIndex rebuilds the index if another process committed a manifest
+// generation since it was loaded, so that blobs packed by that process are
+// not packed again.
+func (store *inventoryArchiveV1) refreshIndex() (err error) {
+	manifest, _, err := store.readManifest()
+	if err != nil {
+		return err
+	}
+
+	if manifest.Generation == store.generation.get() {
+		return err
+	}
+
+	return store.rebuildIndex()
+}
+
+// commitManifest records newly written archives in the manifest under the
+// next generation, starting the manifest from the archives on disk if the
+// store has none yet. changed reports whether another process committed a
+// generation since the in-memory index was loaded, in which case the index
+// is missing that process's archives and has to be rebuilt.
+func (store inventoryArchiveV1) commitManifest(
+	added []inventory_archive.ManifestArchive,
+) (changed bool, err error) {
+	manifest, hasManifest, err := store.readManifest()
+	if err != nil {
+		return changed, err
+	}
+
+	changed = manifest.Generation != store.generation.get()
+
+	if !hasManifest {
+		if manifest.Archives, err = store.discoverArchives(); err != nil {
+			return changed, err
+		}
+	}
+
+	listed := make(map[string]struct{}, len(manifest.Archives))
+
+	for _, archive := range manifest.Archives {
+		listed[string(archive.Checksum)] = struct{}{}
+	}
+
+	for _, archive := range added {
+		if _, ok := listed[string(archive.Checksum)]; ok {
+			continue
+		}
+
+		manifest.Archives = append(manifest.Archives, archive)
+		listed[string(archive.Checksum)] = struct{}{}
+	}
+
+	manifest.Generation++
+
+	if err = files.WriteAtomic(
+		store.manifestPath(),
+		func(writer io.Writer) (err error) {
+			_, err = inventory_archive.WriteManifest(
+				writer,
+				store.defaultHash.GetMarklFormatId(),
+				manifest,
+			)
+			return err
+		},
+	); err != nil {
+		return changed, err
+	}
+
+	store.generation.set(manifest.Generation)
+
+	return changed, err
+}
@@ -0,0 +1,122 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestPackV1ManifestGenerations(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+	basePath := t.TempDir()
+	cachePath := t.TempDir()
+
+	makeStore := func(data []byte) (inventoryArchiveV1, domain_interfaces.MarklId) {
+		rawHash := sha256.Sum256(data)
+		id, repool := hashFormat.GetBlobIdForHexString(
+			hex.EncodeToString(rawHash[:]),
+		)
+		t.Cleanup(repool)
+
+		return inventoryArchiveV1{
+			defaultHash: hashFormat,
+			basePath:    basePath,
+			cachePath:   cachePath,
+			looseBlobStore: &stubBlobStore{
+				allBlobIds: []domain_interfaces.MarklId{id},
+				blobData:   map[string][]byte{id.String(): data},
+			},
+			index: make(map[string]archiveEntryV1),
+			config: blob_store_configs.TomlInventoryArchiveV2{
+				HashTypeId:      markl.FormatIdHashSha256,
+				CompressionType: compression_type.CompressionTypeNone,
+				FooterIndex:     true,
+			},
+			generation: &archiveGeneration{},
+		}, id
+	}
+
+	storeA, id1 := makeStore([]byte("manifest blob one"))
+
+	if err := storeA.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack A: %v", err)
+	}
+
+	manifest, ok, err := storeA.readManifest()
+	if err != nil || !ok {
+		t.Fatalf("readManifest: %v (ok %t)", err, ok)
+	}
+
+	if manifest.Generation != 1 || len(manifest.Archives) != 1 {
+		t.Fatalf(
+			"expected generation 1 with 1 archive, got %d with %d",
+			manifest.Generation,
+			len(manifest.Archives),
+		)
+	}
+
+	if _, err := os.Stat(storeA.cacheFilePath()); err != nil {
+		t.Fatalf("expected cache for generation 1: %v", err)
+	}
+
+	// Another process opens the store and packs a blob of its own.
+	storeB, id2 := makeStore([]byte("manifest blob two"))
+
+	if err := storeB.loadIndex(); err != nil {
+		t.Fatalf("loadIndex B: %v", err)
+	}
+
+	if _, ok := storeB.index[id1.String()]; !ok {
+		t.Fatal("expected B to load A's archive from the cache")
+	}
+
+	if err := storeB.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack B: %v", err)
+	}
+
+	if manifest, _, err = storeB.readManifest(); err != nil {
+		t.Fatalf("readManifest: %v", err)
+	}
+
+	if manifest.Generation != 2 || len(manifest.Archives) != 2 {
+		t.Fatalf(
+			"expected generation 2 with 2 archives, got %d with %d",
+			manifest.Generation,
+			len(manifest.Archives),
+		)
+	}
+
+	// A notices the new generation and picks up B's archive.
+	if _, ok := storeA.index[id2.String()]; ok {
+		t.Fatal("A should not see B's archive before refreshing")
+	}
+
+	if err := storeA.refreshIndex(); err != nil {
+		t.Fatalf("refreshIndex: %v", err)
+	}
+
+	if _, ok := storeA.index[id2.String()]; !ok {
+		t.Fatal("expected A to load B's archive after refreshing")
+	}
+
+	if storeA.generation.get() != 2 {
+		t.Errorf("expected A at generation 2, got %d", storeA.generation.get())
+	}
+
+	caches, err := filepath.Glob(
+		filepath.Join(cachePath, inventory_archive.CacheFileNameV1+"*"),
+	)
+	if err != nil || len(caches) != 1 || caches[0] != storeA.cacheFilePath() {
+		t.Errorf("expected only the generation 2 cache, got %v", caches)
+	}
+}
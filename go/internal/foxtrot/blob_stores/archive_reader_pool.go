@@ -0,0 +1,95 @@
+package blob_stores
+
+import (
+	"io"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/pool"
+)
+
+// pooledDataReaderV1 holds a data reader whose header and dictionary are
+// loaded, or nil until the first read of its archive through the pool.
+type pooledDataReaderV1 struct {
+	reader *inventory_archive.DataReaderV1
+}
+
+type dataReaderV1Pool = interfaces.PoolPtr[
+	pooledDataReaderV1,
+	*pooledDataReaderV1,
+]
+
+// archiveReaderPools keeps a pool of data readers per archive checksum, so
+// that bulk reads of archived blobs, like `show :z -format text`, reuse the
+// parsed header, dictionary and payload buffer of earlier reads instead of
+// allocating them for every blob.
+type archiveReaderPools struct {
+	lock  sync.Mutex
+	pools map[string]dataReaderV1Pool
+}
+
+func (pools *archiveReaderPools) get(archiveChecksum string) dataReaderV1Pool {
+	pools.lock.Lock()
+	defer pools.lock.Unlock()
+
+	if readerPool, ok := pools.pools[archiveChecksum]; ok {
+		return readerPool
+	}
+
+	if pools.pools == nil {
+		pools.pools = make(map[string]dataReaderV1Pool)
+	}
+
+	readerPool := pool.Make[pooledDataReaderV1](
+		nil,
+		func(pooled *pooledDataReaderV1) {
+			if pooled.reader != nil {
+				pooled.reader.Reset(nil)
+			}
+		},
+	)
+
+	pools.pools[archiveChecksum] = readerPool
+
+	return readerPool
+}
+
+// getDataReaderV1 returns a data reader for file, an open handle on the data
+// file of the archive with archiveChecksum, and the func that returns it to
+// the store's pool. The entries it read stay valid after repooling, but the
+// reader must not be used, and file may be closed. Stores without pools open
+// a new reader.
+func (store inventoryArchiveV1) getDataReaderV1(
+	archiveChecksum string,
+	file io.ReadSeeker,
+) (
+	dataReader *inventory_archive.DataReaderV1,
+	repool interfaces.FuncRepool,
+	err error,
+) {
+	if store.readerPools == nil {
+		dataReader, err = inventory_archive.NewDataReaderV1(
+			file,
+			store.encryption,
+		)
+		return dataReader, func() {}, err
+	}
+
+	pooled, repool := store.readerPools.get(archiveChecksum).GetWithRepool()
+
+	if pooled.reader != nil {
+		pooled.reader.Reset(file)
+		return pooled.reader, repool, err
+	}
+
+	if pooled.reader, err = inventory_archive.NewDataReaderV1(
+		file,
+		store.encryption,
+	); err != nil {
+		repool()
+		return dataReader, func() {}, err
+	}
+
+	return pooled.reader, repool, err
+}
@@ -0,0 +1,121 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func packTestBlobsV1ForReads(
+	tb testing.TB,
+	count int,
+) (store inventoryArchiveV1, ids []domain_interfaces.MarklId, contents []string) {
+	tb.Helper()
+
+	random := rand.New(rand.NewPCG(uint64(count), 0))
+
+	for i := range count {
+		noise := make([]byte, 4096)
+
+		for j := range noise {
+			noise[j] = byte(random.Uint32())
+		}
+
+		contents = append(
+			contents,
+			fmt.Sprintf("blob %d read through a pooled reader %x", i, noise),
+		)
+	}
+
+	store, _, ids = packTestBlobsV1(
+		tb,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeZstd,
+		},
+		contents...,
+	)
+
+	return store, ids, contents
+}
+
+func readTestArchiveBlob(
+	tb testing.TB,
+	store inventoryArchiveV1,
+	id domain_interfaces.MarklId,
+) string {
+	tb.Helper()
+
+	reader, err := store.MakeBlobReader(id)
+	if err != nil {
+		tb.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	actual, err := io.ReadAll(reader)
+	reader.Close()
+
+	if err != nil {
+		tb.Fatalf("ReadAll: %v", err)
+	}
+
+	return string(actual)
+}
+
+func TestArchiveReaderPoolReusesReaders(t *testing.T) {
+	store, ids, contents := packTestBlobsV1ForReads(t, 8)
+	store.readerPools = &archiveReaderPools{}
+
+	for range 2 {
+		for i, id := range ids {
+			if actual := readTestArchiveBlob(t, store, id); actual != contents[i] {
+				t.Errorf("%s: expected %q, got %q", id, contents[i], actual)
+			}
+		}
+	}
+
+	if count := len(store.readerPools.pools); count != 1 {
+		t.Errorf("expected one pool for the one archive, got %d", count)
+	}
+}
+
+func benchmarkArchiveReads(b *testing.B, pooled bool) {
+	store, ids, _ := packTestBlobsV1ForReads(b, 256)
+
+	if pooled {
+		store.readerPools = &archiveReaderPools{}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := range b.N {
+		reader, err := store.MakeBlobReader(ids[i%len(ids)])
+		if err != nil {
+			b.Fatalf("MakeBlobReader: %v", err)
+		}
+
+		if _, err := io.Copy(io.Discard, reader); err != nil {
+			b.Fatalf("Copy: %v", err)
+		}
+
+		reader.Close()
+	}
+}
+
+// BenchmarkArchiveReadsUnpooled and BenchmarkArchiveReadsPooled compare the
+// allocations of bulk reads from one archive, like `show :z -format text`.
+func BenchmarkArchiveReadsUnpooled(b *testing.B) {
+	benchmarkArchiveReads(b, false)
+}
+
+func BenchmarkArchiveReadsPooled(b *testing.B) {
+	benchmarkArchiveReads(b, true)
+}
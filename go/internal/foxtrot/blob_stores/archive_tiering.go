@@ -0,0 +1,390 @@
+package blob_stores
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// TieredArchive is implemented by blob stores that can demote archives that
+// have not been read for a while to a cold path. IsTiered is false for those
+// without one configured.
+type TieredArchive interface {
+	IsTiered() bool
+	DemoteArchives(now time.Time, dryRun bool) ([]DemotedArchive, error)
+}
+
+var _ TieredArchive = inventoryArchiveV1{}
+
+// DemotedArchive is an archive whose data file was, or on dry runs would be,
+// moved to the cold path. Blobs and BlobBytes are the number of blobs in the
+// archive and their total size.
+type DemotedArchive struct {
+	Checksum  string
+	Path      string
+	LastRead  time.Time
+	Size      int64
+	Blobs     int
+	BlobBytes uint64
+}
+
+// archiveTiers holds the tier of each archive as of the manifest the index
+// reflects, and the archives this process already recorded a read of. It is
+// shared by all copies of a store value, like its index. A nil archiveTiers
+// has every archive hot.
+type archiveTiers struct {
+	lock sync.Mutex
+	cold map[string]struct{}
+	read map[string]struct{}
+}
+
+func (tiers *archiveTiers) set(manifest inventory_archive.Manifest) {
+	if tiers == nil {
+		return
+	}
+
+	tiers.lock.Lock()
+	defer tiers.lock.Unlock()
+
+	tiers.cold = make(map[string]struct{})
+
+	for _, archive := range manifest.Archives {
+		if archive.Tier == inventory_archive.ArchiveTierCold {
+			tiers.cold[hex.EncodeToString(archive.Checksum)] = struct{}{}
+		}
+	}
+}
+
+func (tiers *archiveTiers) isCold(archiveChecksum string) bool {
+	if tiers == nil {
+		return false
+	}
+
+	tiers.lock.Lock()
+	defer tiers.lock.Unlock()
+
+	_, ok := tiers.cold[archiveChecksum]
+
+	return ok
+}
+
+// markRead reports whether this is the first read of the archive by this
+// process.
+func (tiers *archiveTiers) markRead(archiveChecksum string) bool {
+	if tiers == nil {
+		return false
+	}
+
+	tiers.lock.Lock()
+	defer tiers.lock.Unlock()
+
+	if _, ok := tiers.read[archiveChecksum]; ok {
+		return false
+	}
+
+	if tiers.read == nil {
+		tiers.read = make(map[string]struct{})
+	}
+
+	tiers.read[archiveChecksum] = struct{}{}
+
+	return true
+}
+
+// tiering returns the store's cold path and how long an archive goes unread
+// before it is demoted, with ok false when the store has no cold path.
+func (store inventoryArchiveV1) tiering() (
+	coldPath string,
+	demoteAfter time.Duration,
+	ok bool,
+) {
+	config, isTiered := store.config.(blob_store_configs.TieringConfigImmutable)
+
+	if !isTiered || config.GetTieringColdPath() == "" {
+		return coldPath, demoteAfter, false
+	}
+
+	coldPath = config.GetTieringColdPath()
+	demoteAfter = time.Duration(config.GetTieringDemoteAfterDays()) * 24 * time.Hour
+
+	return coldPath, demoteAfter, true
+}
+
+func (store inventoryArchiveV1) IsTiered() bool {
+	_, _, ok := store.tiering()
+	return ok
+}
+
+// archiveDataPaths lists where an archive's data file may be, for each of
+// extensions: first in the tier the manifest records, then in the other, so
+// that archives demoted by another process since the manifest was read are
+// still found.
+func (store inventoryArchiveV1) archiveDataPaths(
+	archiveChecksum string,
+	extensions ...string,
+) (paths []string) {
+	dirs := []string{store.archivesPath()}
+
+	if coldPath, _, ok := store.tiering(); ok {
+		if store.tiers.isCold(archiveChecksum) {
+			dirs = []string{coldPath, store.archivesPath()}
+		} else {
+			dirs = append(dirs, coldPath)
+		}
+	}
+
+	for _, dir := range dirs {
+		for _, extension := range extensions {
+			paths = append(paths, filepath.Join(dir, archiveChecksum+extension))
+		}
+	}
+
+	return paths
+}
+
+// recordArchiveRead sets the modification time of an archive's data file to
+// now the first time this process reads it, as demotion goes by how long an
+// archive has not been read. Only writable stores with a cold path record
+// reads, and not during a dry run.
+func (store inventoryArchiveV1) recordArchiveRead(
+	archiveChecksum string,
+	archivePath string,
+) {
+	if _, _, ok := store.tiering(); !ok || store.readOnly || store.dryRun {
+		return
+	}
+
+	if !store.tiers.markRead(archiveChecksum) {
+		return
+	}
+
+	now := time.Now()
+	os.Chtimes(archivePath, now, now)
+}
+
+// DemoteArchives moves the data files of the hot archives that have not been
+// read for the store's `demote-after-days` to its cold path, and records
+// them as cold in the manifest. Index files stay hot. Each data file is
+// copied durably before the manifest is committed, and removed from
+// the hot tier only after, so a crash leaves at most a stray copy. On dry
+// runs, and under the global -dry-run, the archives that would be demoted
+// are only returned.
+func (store inventoryArchiveV1) DemoteArchives(
+	now time.Time,
+	dryRun bool,
+) (demoted []DemotedArchive, err error) {
+	dryRun = dryRun || store.dryRun
+
+	coldPath, demoteAfter, ok := store.tiering()
+	if !ok {
+		err = errors.BadRequestf(
+			"blob store %s has no tiering.cold-path",
+			store.basePath,
+		)
+
+		return demoted, err
+	}
+
+	if !dryRun {
+		if err = errIfReadOnly(store.readOnly, store.basePath); err != nil {
+			return demoted, err
+		}
+	}
+
+	lock, err := lockStore(store.basePath, false)
+	if err != nil {
+		return demoted, err
+	}
+
+	defer errors.Deferred(&err, lock.Unlock)
+
+	if err = store.refreshIndex(); err != nil {
+		return demoted, err
+	}
+
+	manifest, hasManifest, err := store.readManifest()
+	if err != nil {
+		return demoted, err
+	}
+
+	if !hasManifest {
+		if manifest.Archives, err = store.discoverArchives(); err != nil {
+			return demoted, err
+		}
+	}
+
+	cutoff := now.Add(-demoteAfter)
+
+	var hotPaths []string
+	var demotedIndexes []int
+
+	for i, archive := range manifest.Archives {
+		if archive.Tier != inventory_archive.ArchiveTierHot {
+			continue
+		}
+
+		extension := inventory_archive.DataFileExtensionV1
+
+		if archive.DataFileVersion == inventory_archive.DataFileVersionV2 {
+			extension = inventory_archive.DataFileExtensionV2
+		}
+
+		archiveChecksum := hex.EncodeToString(archive.Checksum)
+		hotPath := filepath.Join(store.archivesPath(), archiveChecksum+extension)
+
+		var info os.FileInfo
+
+		if info, err = os.Stat(hotPath); err != nil {
+			err = errors.Wrapf(err, "archive %s", archiveChecksum)
+			return demoted, err
+		}
+
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		demoted = append(demoted, DemotedArchive{
+			Checksum: archiveChecksum,
+			Path:     filepath.Join(coldPath, filepath.Base(hotPath)),
+			LastRead: info.ModTime(),
+			Size:     info.Size(),
+		})
+
+		hotPaths = append(hotPaths, hotPath)
+		demotedIndexes = append(demotedIndexes, i)
+	}
+
+	if len(demoted) == 0 {
+		return demoted, err
+	}
+
+	if err = store.countDemotedBlobs(demoted); err != nil {
+		return demoted, err
+	}
+
+	if dryRun {
+		return demoted, err
+	}
+
+	if err = os.MkdirAll(coldPath, 0o755); err != nil {
+		err = errors.Wrapf(err, "creating cold path %s", coldPath)
+		return demoted, err
+	}
+
+	for i, archive := range demoted {
+		if err = copyArchiveDataFile(
+			hotPaths[i],
+			archive.Path,
+			archive.LastRead,
+		); err != nil {
+			return demoted, err
+		}
+
+		manifest.Archives[demotedIndexes[i]].Tier = inventory_archive.ArchiveTierCold
+	}
+
+	manifest.Generation++
+
+	if err = files.WriteAtomic(
+		store.manifestPath(),
+		func(writer io.Writer) (err error) {
+			_, err = inventory_archive.WriteManifest(
+				writer,
+				store.defaultHash.GetMarklFormatId(),
+				manifest,
+			)
+			return err
+		},
+	); err != nil {
+		return demoted, err
+	}
+
+	store.generation.set(manifest.Generation)
+	store.tiers.set(manifest)
+
+	// the index is unchanged, so it is cached again under the new generation
+	if err = store.writeCacheV1(); err != nil {
+		return demoted, err
+	}
+
+	for _, hotPath := range hotPaths {
+		if err = os.Remove(hotPath); err != nil {
+			err = errors.Wrap(err)
+			return demoted, err
+		}
+	}
+
+	return demoted, err
+}
+
+// countDemotedBlobs sets the blob counts and sizes of the archives being
+// demoted from the headers of their entries.
+func (store inventoryArchiveV1) countDemotedBlobs(
+	demoted []DemotedArchive,
+) (err error) {
+	byChecksum := make(map[string]*DemotedArchive, len(demoted))
+
+	for i := range demoted {
+		byChecksum[demoted[i].Checksum] = &demoted[i]
+	}
+
+	for info, err := range archivedBlobInfos(
+		store.indexedBlobInfos(func(archiveChecksum string) bool {
+			_, ok := byChecksum[archiveChecksum]
+			return ok
+		}),
+		store.openArchiveForBlobInfos,
+	) {
+		if err != nil {
+			return err
+		}
+
+		archive := byChecksum[info.ArchiveChecksum]
+		archive.Blobs++
+		archive.BlobBytes += info.Size
+	}
+
+	return err
+}
+
+// copyArchiveDataFile durably copies an archive's data file to dst, keeping
+// lastRead as its modification time.
+func copyArchiveDataFile(
+	src string,
+	dst string,
+	lastRead time.Time,
+) (err error) {
+	var srcFile *os.File
+
+	if srcFile, err = os.Open(src); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, srcFile)
+
+	if err = files.WriteAtomic(
+		dst,
+		func(writer io.Writer) (err error) {
+			_, err = io.Copy(writer, srcFile)
+			return err
+		},
+	); err != nil {
+		return err
+	}
+
+	if err = os.Chtimes(dst, lastRead, lastRead); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,149 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestDemoteArchivesMovesUnreadArchivesToColdPath(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+	basePath := t.TempDir()
+	cachePath := t.TempDir()
+	coldPath := filepath.Join(t.TempDir(), "cold")
+
+	data := []byte("rarely read blob")
+	rawHash := sha256.Sum256(data)
+	id, repool := hashFormat.GetBlobIdForHexString(hex.EncodeToString(rawHash[:]))
+	t.Cleanup(repool)
+
+	makeStore := func() inventoryArchiveV1 {
+		return inventoryArchiveV1{
+			defaultHash: hashFormat,
+			basePath:    basePath,
+			cachePath:   cachePath,
+			looseBlobStore: &stubBlobStore{
+				allBlobIds: []domain_interfaces.MarklId{id},
+				blobData:   map[string][]byte{id.String(): data},
+			},
+			index: make(map[string]archiveEntryV1),
+			config: blob_store_configs.TomlInventoryArchiveV2{
+				HashTypeId:      markl.FormatIdHashSha256,
+				CompressionType: compression_type.CompressionTypeNone,
+				FooterIndex:     true,
+				Tiering: blob_store_configs.TieringConfig{
+					ColdPath:        coldPath,
+					DemoteAfterDays: 7,
+				},
+			},
+			generation: &archiveGeneration{},
+			tiers:      &archiveTiers{},
+		}
+	}
+
+	store := makeStore()
+
+	if err := store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	demoted, err := store.DemoteArchives(time.Now(), false)
+	if err != nil {
+		t.Fatalf("DemoteArchives: %v", err)
+	}
+
+	if len(demoted) != 0 {
+		t.Fatalf("expected a fresh archive to stay hot, got %v", demoted)
+	}
+
+	later := time.Now().Add(8 * 24 * time.Hour)
+
+	if demoted, err = store.DemoteArchives(later, true); err != nil ||
+		len(demoted) != 1 {
+		t.Fatalf("expected a dry run to list one archive, got %v (%v)", demoted, err)
+	}
+
+	if _, err = os.Stat(demoted[0].Path); !os.IsNotExist(err) {
+		t.Fatalf("expected a dry run to leave the cold path empty: %v", err)
+	}
+
+	if demoted[0].Blobs != 1 || demoted[0].BlobBytes != uint64(len(data)) {
+		t.Errorf(
+			"expected 1 blob of %d bytes, got %d of %d",
+			len(data),
+			demoted[0].Blobs,
+			demoted[0].BlobBytes,
+		)
+	}
+
+	if demoted, err = store.DemoteArchives(later, false); err != nil ||
+		len(demoted) != 1 {
+		t.Fatalf("expected one archive to be demoted, got %v (%v)", demoted, err)
+	}
+
+	if _, err = os.Stat(demoted[0].Path); err != nil {
+		t.Fatalf("expected the data file in the cold path: %v", err)
+	}
+
+	manifest, _, err := store.readManifest()
+	if err != nil {
+		t.Fatalf("readManifest: %v", err)
+	}
+
+	if len(manifest.Archives) != 1 ||
+		manifest.Archives[0].Tier != inventory_archive.ArchiveTierCold {
+		t.Fatalf("expected the manifest to record a cold archive, got %+v", manifest)
+	}
+
+	lastRead := time.Now().Add(-time.Hour)
+
+	if err = os.Chtimes(demoted[0].Path, lastRead, lastRead); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process opens the store and reads the blob from the cold tier.
+	reopened := makeStore()
+	reopened.looseBlobStore = &stubBlobStore{}
+
+	if err = reopened.loadIndex(); err != nil {
+		t.Fatalf("loadIndex: %v", err)
+	}
+
+	reader, err := reopened.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	defer reader.Close()
+
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if string(actual) != string(data) {
+		t.Errorf("expected %q but got %q", data, actual)
+	}
+
+	// reading records the archive as read, so it would not be demoted again
+	info, err := os.Stat(demoted[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !info.ModTime().After(lastRead) {
+		t.Error("expected reading the archive to update its last read time")
+	}
+}
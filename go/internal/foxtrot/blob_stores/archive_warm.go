@@ -0,0 +1,280 @@
+package blob_stores
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// ArchiveWarmer is implemented by stores that load an index of their archives
+// when opened. WarmArchives leaves a valid index cache behind for the next
+// process to load, and can read archives into the page cache ahead of the
+// first query.
+type ArchiveWarmer interface {
+	WarmArchives(options WarmOptions) (WarmReport, error)
+}
+
+var (
+	_ ArchiveWarmer = inventoryArchiveV0{}
+	_ ArchiveWarmer = inventoryArchiveV1{}
+)
+
+type WarmOptions struct {
+	// read the data and index files of hot archives through, so that they are
+	// in the page cache
+	PageCache bool
+}
+
+type WarmReport struct {
+	IndexEntries int
+	// the cache was stale or failed validation, and was rebuilt
+	CacheRebuilt bool
+	FilesRead    int
+	BytesRead    int64
+}
+
+// WarmBlobStore warms a store's archives. ok is false when the store does not
+// implement ArchiveWarmer.
+func WarmBlobStore(
+	blobStore domain_interfaces.BlobStore,
+	options WarmOptions,
+) (report WarmReport, ok bool, err error) {
+	warmer, ok := blobStore.(ArchiveWarmer)
+	if !ok {
+		return report, ok, err
+	}
+
+	if report, err = warmer.WarmArchives(options); err != nil {
+		err = errors.Wrap(err)
+		return report, ok, err
+	}
+
+	return report, ok, err
+}
+
+func (store inventoryArchiveV0) WarmArchives(
+	options WarmOptions,
+) (report WarmReport, err error) {
+	if store.checkCacheFreshness().Err != nil || !store.isCacheValid() {
+		clear(store.index)
+
+		if err = store.rebuildIndex(); err != nil {
+			err = errors.Wrap(err)
+			return report, err
+		}
+
+		report.CacheRebuilt = true
+	}
+
+	report.IndexEntries = len(store.index)
+
+	if !options.PageCache {
+		return report, err
+	}
+
+	var paths []string
+
+	if paths, err = filepath.Glob(
+		filepath.Join(store.archivesPath(), "*"),
+	); err != nil {
+		err = errors.Wrap(err)
+		return report, err
+	}
+
+	if err = readIntoPageCache(&report, paths...); err != nil {
+		err = errors.Wrap(err)
+		return report, err
+	}
+
+	return report, err
+}
+
+func (store inventoryArchiveV0) isCacheValid() bool {
+	if len(store.index) == 0 {
+		return true
+	}
+
+	file, err := os.Open(
+		filepath.Join(store.cachePath, inventory_archive.CacheFileName),
+	)
+	if err != nil {
+		return false
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	reader, err := inventory_archive.NewCacheReader(
+		file,
+		info.Size(),
+		store.defaultHash.GetMarklFormatId(),
+	)
+	if err != nil {
+		return false
+	}
+
+	return reader.Validate() == nil
+}
+
+// WarmArchives rebuilds the index when another process committed a manifest
+// generation since it was loaded, or when the cache for its generation is
+// missing or fails validation. Only hot archives are read into the page
+// cache, and reading them does not count as a read for demotion.
+func (store inventoryArchiveV1) WarmArchives(
+	options WarmOptions,
+) (report WarmReport, err error) {
+	if store.checkCacheFreshness().Err != nil || !store.isCacheValid() {
+		if err = store.rebuildIndex(); err != nil {
+			err = errors.Wrap(err)
+			return report, err
+		}
+
+		if err = store.tryWriteCacheV1(); err != nil {
+			err = errors.Wrap(err)
+			return report, err
+		}
+
+		report.CacheRebuilt = true
+	}
+
+	report.IndexEntries = len(store.index)
+
+	if !options.PageCache {
+		return report, err
+	}
+
+	manifest, hasManifest, err := store.readManifest()
+	if err != nil {
+		err = errors.Wrap(err)
+		return report, err
+	}
+
+	archives := manifest.Archives
+
+	if !hasManifest {
+		if archives, err = store.discoverArchives(); err != nil {
+			err = errors.Wrap(err)
+			return report, err
+		}
+	}
+
+	for _, archive := range archives {
+		if archive.Tier == inventory_archive.ArchiveTierCold {
+			continue
+		}
+
+		var paths []string
+
+		if paths, err = filepath.Glob(filepath.Join(
+			store.archivesPath(),
+			hex.EncodeToString(archive.Checksum)+".*",
+		)); err != nil {
+			err = errors.Wrap(err)
+			return report, err
+		}
+
+		if err = readIntoPageCache(&report, paths...); err != nil {
+			err = errors.Wrap(err)
+			return report, err
+		}
+	}
+
+	return report, err
+}
+
+func (store inventoryArchiveV1) isCacheValid() bool {
+	if len(store.index) == 0 {
+		return true
+	}
+
+	for _, cachePath := range store.cacheFilePaths() {
+		if !isCacheFileValidV1(cachePath) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isCacheFileValidV1(cachePath string) bool {
+	file, err := os.Open(cachePath)
+	if err != nil {
+		return false
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	reader, err := inventory_archive.NewCacheReaderV1(
+		file,
+		info.Size(),
+	)
+	if err != nil {
+		return false
+	}
+
+	return reader.Validate() == nil
+}
+
+// readIntoPageCache reads each file through and discards it, skipping
+// directories and the temp files of packs in progress.
+func readIntoPageCache(report *WarmReport, paths ...string) (err error) {
+	for _, path := range paths {
+		if filepath.Ext(path) == ".tmp" {
+			continue
+		}
+
+		var file *os.File
+
+		if file, err = os.Open(path); err != nil {
+			if errors.IsNotExist(err) {
+				err = nil
+				continue
+			}
+
+			err = errors.Wrap(err)
+			return err
+		}
+
+		var info os.FileInfo
+
+		if info, err = file.Stat(); err != nil {
+			file.Close()
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if info.IsDir() {
+			file.Close()
+			continue
+		}
+
+		var n int64
+
+		n, err = io.Copy(io.Discard, file)
+		file.Close()
+
+		if err != nil {
+			err = errors.Wrapf(err, "reading %s", path)
+			return err
+		}
+
+		report.FilesRead++
+		report.BytesRead += n
+	}
+
+	return err
+}
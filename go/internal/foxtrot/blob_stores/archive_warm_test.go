@@ -0,0 +1,82 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestWarmArchivesRebuildsCorruptCache(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+	basePath := t.TempDir()
+	cachePath := t.TempDir()
+
+	data := []byte("blob warmed before the first query")
+	rawHash := sha256.Sum256(data)
+	id, repool := hashFormat.GetBlobIdForHexString(hex.EncodeToString(rawHash[:]))
+	t.Cleanup(repool)
+
+	store := inventoryArchiveV1{
+		defaultHash: hashFormat,
+		basePath:    basePath,
+		cachePath:   cachePath,
+		looseBlobStore: &stubBlobStore{
+			allBlobIds: []domain_interfaces.MarklId{id},
+			blobData:   map[string][]byte{id.String(): data},
+		},
+		index: make(map[string]archiveEntryV1),
+		config: blob_store_configs.TomlInventoryArchiveV2{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+			FooterIndex:     true,
+		},
+		generation: &archiveGeneration{},
+		tiers:      &archiveTiers{},
+	}
+
+	if err := store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	report, err := store.WarmArchives(WarmOptions{PageCache: true})
+	if err != nil {
+		t.Fatalf("WarmArchives: %v", err)
+	}
+
+	if report.CacheRebuilt {
+		t.Error("expected the cache written by the pack to be valid")
+	}
+
+	if report.IndexEntries != 1 {
+		t.Errorf("expected 1 index entry, got %d", report.IndexEntries)
+	}
+
+	// the archive and its bloom filter
+	if report.FilesRead != 2 || report.BytesRead == 0 {
+		t.Errorf("expected the archive to be read, got %+v", report)
+	}
+
+	if err = os.WriteFile(store.cacheFilePath(), []byte("corrupt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if report, err = store.WarmArchives(WarmOptions{}); err != nil {
+		t.Fatalf("WarmArchives: %v", err)
+	}
+
+	if !report.CacheRebuilt || report.FilesRead != 0 {
+		t.Errorf("expected only the cache to be rebuilt, got %+v", report)
+	}
+
+	if !store.isCacheValid() {
+		t.Error("expected a valid cache after warming")
+	}
+}
@@ -0,0 +1,457 @@
+package blob_stores
+
+import (
+	"cmp"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// BlobEntryType is how a store keeps a blob.
+type BlobEntryType string
+
+const (
+	// a file of its own in a local store
+	BlobEntryTypeLoose = BlobEntryType("loose")
+	// a record in the small-blob archive of a local store
+	BlobEntryTypeInline = BlobEntryType("inline")
+	// a manifest of chunks, each of them a blob of its own
+	BlobEntryTypeChunked = BlobEntryType("chunked")
+	// an archive entry holding the whole blob
+	BlobEntryTypeFull = BlobEntryType("full")
+	// an archive entry holding a delta against another entry
+	BlobEntryTypeDelta = BlobEntryType("delta")
+)
+
+// BlobInfo describes a blob as a store keeps it. Size is the blob's own size
+// and StoredSize the bytes it takes in Path, after compression and
+// encryption. Path is the blob's file, its chunk manifest, or the data file
+// of the archive holding it at Offset. Stores that cannot tell where a blob
+// is kept leave Path and StoredSize empty.
+type BlobInfo struct {
+	Id              markl.Id      `json:"id"`
+	Size            uint64        `json:"size"`
+	StoredSize      uint64        `json:"stored_size"`
+	EntryType       BlobEntryType `json:"entry_type,omitempty"`
+	Path            string        `json:"path,omitempty"`
+	ArchiveChecksum string        `json:"archive_checksum,omitempty"`
+	Offset          uint64        `json:"offset"`
+}
+
+// BlobInfoLister is implemented by stores that can list their blobs along
+// with how they keep them.
+type BlobInfoLister interface {
+	AllBlobInfos() interfaces.SeqError[BlobInfo]
+}
+
+var (
+	_ BlobInfoLister = localHashBucketed{}
+	_ BlobInfoLister = lazyParent{}
+	_ BlobInfoLister = inventoryArchiveV0{}
+	_ BlobInfoLister = inventoryArchiveV1{}
+)
+
+// AllBlobInfos lists the blobs of blobStore. Stores that are BlobInfoListers
+// describe their own blobs; the blobs of all others are read to find their
+// size. Unlike the ids yielded by AllBlobs, the infos are safe to keep.
+func AllBlobInfos(
+	blobStore domain_interfaces.BlobStore,
+) interfaces.SeqError[BlobInfo] {
+	if lister, ok := blobStore.(BlobInfoLister); ok {
+		return lister.AllBlobInfos()
+	}
+
+	return func(yield func(BlobInfo, error) bool) {
+		for id, err := range blobStore.AllBlobs() {
+			if err != nil {
+				if !yield(BlobInfo{}, err) {
+					return
+				}
+
+				continue
+			}
+
+			var info BlobInfo
+			info.Id.ResetWithMarklId(id)
+			info.Size, err = readBlobSize(blobStore, id)
+
+			if !yield(info, err) {
+				return
+			}
+		}
+	}
+}
+
+// readBlobSize reads a blob through to find its size.
+func readBlobSize(
+	blobStore domain_interfaces.BlobStore,
+	id domain_interfaces.MarklId,
+) (size uint64, err error) {
+	reader, err := blobStore.MakeBlobReader(id)
+	if err != nil {
+		err = errors.Wrapf(err, "opening blob %s for size", id)
+		return size, err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	n, err := io.Copy(io.Discard, reader)
+	if err != nil {
+		err = errors.Wrapf(err, "reading blob %s for size", id)
+		return size, err
+	}
+
+	return uint64(n), err
+}
+
+func (blobStore localHashBucketed) AllBlobInfos() interfaces.SeqError[BlobInfo] {
+	return func(yield func(BlobInfo, error) bool) {
+		for id, err := range blobStore.AllBlobs() {
+			if err != nil {
+				if !yield(BlobInfo{}, err) {
+					return
+				}
+
+				continue
+			}
+
+			info, err := blobStore.blobInfo(id)
+
+			if !yield(info, err) {
+				return
+			}
+		}
+	}
+}
+
+// storesPlainBlobs reports whether the store's blob files hold the blobs'
+// bytes as they are, so that a file's size is the blob's.
+func (blobStore localHashBucketed) storesPlainBlobs() bool {
+	if !markl.IsNull(blobStore.config.GetBlobEncryption()) {
+		return false
+	}
+
+	compression, ok := blobStore.config.GetBlobCompression().(*compression_type.CompressionType)
+
+	return ok && (*compression == compression_type.CompressionTypeNone ||
+		*compression == compression_type.CompressionTypeEmpty)
+}
+
+func (blobStore localHashBucketed) blobInfo(
+	id domain_interfaces.MarklId,
+) (info BlobInfo, err error) {
+	info.Id.ResetWithMarklId(id)
+
+	path := env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		blobStore.buckets,
+		blobStore.multiHash,
+		blobStore.basePath,
+	)
+
+	var fileInfo os.FileInfo
+
+	switch {
+	case files.Exists(path):
+		if fileInfo, err = os.Stat(path); err != nil {
+			err = errors.Wrap(err)
+			return info, err
+		}
+
+		info.EntryType = BlobEntryTypeLoose
+		info.Path = path
+		info.StoredSize = uint64(fileInfo.Size())
+
+		if blobStore.storesPlainBlobs() {
+			info.Size = info.StoredSize
+			return info, err
+		}
+
+	case files.Exists(path + chunkedBlobManifestSuffix):
+		if fileInfo, err = os.Stat(path + chunkedBlobManifestSuffix); err != nil {
+			err = errors.Wrap(err)
+			return info, err
+		}
+
+		info.EntryType = BlobEntryTypeChunked
+		info.Path = path + chunkedBlobManifestSuffix
+		info.StoredSize = uint64(fileInfo.Size())
+
+	case blobStore.smallBlobs != nil:
+		var entry smallBlobEntry
+		var ok bool
+
+		if entry, ok, err = blobStore.smallBlobs.lookup(id); err != nil {
+			err = errors.Wrap(err)
+			return info, err
+		} else if !ok {
+			err = env_dir.ErrBlobMissing{BlobId: &info.Id, Path: path}
+			return info, err
+		}
+
+		info.EntryType = BlobEntryTypeInline
+		info.Path = blobStore.smallBlobs.getDataPath()
+		info.Offset = entry.offset
+		info.StoredSize = entry.size
+
+	default:
+		err = env_dir.ErrBlobMissing{BlobId: &info.Id, Path: path}
+		return info, err
+	}
+
+	info.Size, err = readBlobSize(blobStore, id)
+
+	return info, err
+}
+
+func (blobStore lazyParent) AllBlobInfos() interfaces.SeqError[BlobInfo] {
+	return blobStore.local.AllBlobInfos()
+}
+
+// entryLogicalSizer reads the size of the blob in an archive entry from the
+// entry's header.
+type entryLogicalSizer interface {
+	LogicalSizeAt(offset uint64) (uint64, error)
+}
+
+// archiveOpener opens the data file of an archive for reading entry sizes,
+// returning its path.
+type archiveOpener func(
+	archiveChecksum string,
+) (path string, file *os.File, sizer entryLogicalSizer, err error)
+
+// archivedBlobInfos yields the infos of an archive store's indexed blobs, by
+// index key, which have their archive, offset, stored size and entry type
+// set, in archive and offset order, reading each blob's size from its entry
+// header.
+func archivedBlobInfos(
+	indexed map[string]BlobInfo,
+	openArchive archiveOpener,
+) interfaces.SeqError[BlobInfo] {
+	return func(yield func(BlobInfo, error) bool) {
+		archived := make([]BlobInfo, 0, len(indexed))
+
+		for key, info := range indexed {
+			if err := info.Id.Set(key); err != nil {
+				if !yield(info, errors.Wrap(err)) {
+					return
+				}
+
+				continue
+			}
+
+			archived = append(archived, info)
+		}
+
+		slices.SortFunc(archived, func(a, b BlobInfo) int {
+			return cmp.Or(
+				cmp.Compare(a.ArchiveChecksum, b.ArchiveChecksum),
+				cmp.Compare(a.Offset, b.Offset),
+			)
+		})
+
+		var file *os.File
+		var sizer entryLogicalSizer
+		var path, openChecksum string
+		var openErr error
+
+		defer func() {
+			if file != nil {
+				file.Close()
+			}
+		}()
+
+		for _, info := range archived {
+			if info.ArchiveChecksum != openChecksum {
+				if file != nil {
+					file.Close()
+					file = nil
+				}
+
+				openChecksum = info.ArchiveChecksum
+				path, file, sizer, openErr = openArchive(openChecksum)
+			}
+
+			info.Path = path
+
+			if openErr != nil {
+				if !yield(info, openErr) {
+					return
+				}
+
+				continue
+			}
+
+			var err error
+
+			if info.Size, err = sizer.LogicalSizeAt(info.Offset); err != nil {
+				err = errors.Wrapf(
+					err,
+					"reading size of entry at offset %d in %s",
+					info.Offset,
+					path,
+				)
+			}
+
+			if !yield(info, err) {
+				return
+			}
+		}
+	}
+}
+
+// archiveAndLooseBlobInfos yields the infos of an archive store's indexed
+// blobs followed by those of the blobs in looseBlobStore that are not
+// indexed.
+func archiveAndLooseBlobInfos(
+	indexed map[string]BlobInfo,
+	openArchive archiveOpener,
+	looseBlobStore domain_interfaces.BlobStore,
+) interfaces.SeqError[BlobInfo] {
+	return func(yield func(BlobInfo, error) bool) {
+		for info, err := range archivedBlobInfos(indexed, openArchive) {
+			if !yield(info, err) {
+				return
+			}
+		}
+
+		for info, err := range AllBlobInfos(looseBlobStore) {
+			if err == nil {
+				if _, ok := indexed[info.Id.String()]; ok {
+					continue
+				}
+			}
+
+			if !yield(info, err) {
+				return
+			}
+		}
+	}
+}
+
+func (store inventoryArchiveV0) AllBlobInfos() interfaces.SeqError[BlobInfo] {
+	indexed := make(map[string]BlobInfo, len(store.index))
+
+	for key, entry := range store.index {
+		indexed[key] = BlobInfo{
+			EntryType:       BlobEntryTypeFull,
+			ArchiveChecksum: entry.ArchiveChecksum,
+			Offset:          entry.Offset,
+			StoredSize:      entry.StoredSize,
+		}
+	}
+
+	return archiveAndLooseBlobInfos(
+		indexed,
+		store.openArchiveForBlobInfos,
+		store.looseBlobStore,
+	)
+}
+
+func (store inventoryArchiveV0) openArchiveForBlobInfos(
+	archiveChecksum string,
+) (path string, file *os.File, sizer entryLogicalSizer, err error) {
+	path = filepath.Join(
+		store.archivesPath(),
+		archiveChecksum+inventory_archive.DataFileExtension,
+	)
+
+	if file, err = os.Open(path); err != nil {
+		err = errors.Wrapf(err, "opening archive %s", path)
+		return path, file, sizer, err
+	}
+
+	if sizer, err = inventory_archive.NewDataReader(
+		file,
+		store.encryption,
+	); err != nil {
+		file.Close()
+		file = nil
+		err = errors.Wrapf(err, "reading archive header %s", path)
+		return path, file, sizer, err
+	}
+
+	return path, file, sizer, err
+}
+
+func (store inventoryArchiveV1) AllBlobInfos() interfaces.SeqError[BlobInfo] {
+	return archiveAndLooseBlobInfos(
+		store.indexedBlobInfos(nil),
+		store.openArchiveForBlobInfos,
+		store.looseBlobStore,
+	)
+}
+
+// indexedBlobInfos returns the infos, without their size and path, of the
+// indexed blobs in the archives that include reports true for, or in all
+// archives when include is nil.
+func (store inventoryArchiveV1) indexedBlobInfos(
+	include func(archiveChecksum string) bool,
+) map[string]BlobInfo {
+	indexed := make(map[string]BlobInfo, len(store.index))
+
+	for key, entry := range store.index {
+		if include != nil && !include(entry.ArchiveChecksum) {
+			continue
+		}
+
+		info := BlobInfo{
+			EntryType:       BlobEntryTypeFull,
+			ArchiveChecksum: entry.ArchiveChecksum,
+			Offset:          entry.Offset,
+			StoredSize:      entry.StoredSize,
+		}
+
+		if entry.EntryType == inventory_archive.EntryTypeDelta {
+			info.EntryType = BlobEntryTypeDelta
+		}
+
+		indexed[key] = info
+	}
+
+	return indexed
+}
+
+// openArchiveForBlobInfos opens an archive's data file in whichever tier it
+// is found. Unlike openArchiveDataFile, it does not record a read, as
+// listing an archive's blobs should not keep it from being demoted.
+func (store inventoryArchiveV1) openArchiveForBlobInfos(
+	archiveChecksum string,
+) (path string, file *os.File, sizer entryLogicalSizer, err error) {
+	for _, path = range store.archiveDataPaths(
+		archiveChecksum,
+		inventory_archive.DataFileExtensionV2,
+		inventory_archive.DataFileExtensionV1,
+	) {
+		if file, err = os.Open(path); err == nil || !os.IsNotExist(err) {
+			break
+		}
+	}
+
+	if err != nil {
+		err = errors.Wrapf(err, "opening archive %s", path)
+		return path, file, sizer, err
+	}
+
+	if sizer, err = inventory_archive.NewDataReaderV1(
+		file,
+		store.encryption,
+	); err != nil {
+		file.Close()
+		file = nil
+		err = errors.Wrapf(err, "reading archive header %s", path)
+		return path, file, sizer, err
+	}
+
+	return path, file, sizer, err
+}
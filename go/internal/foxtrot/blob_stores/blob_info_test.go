@@ -0,0 +1,195 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+func collectBlobInfos(
+	t *testing.T,
+	seq interfaces.SeqError[BlobInfo],
+) map[string]BlobInfo {
+	t.Helper()
+
+	infos := make(map[string]BlobInfo)
+
+	for info, err := range seq {
+		if err != nil {
+			t.Fatalf("AllBlobInfos: %v", err)
+		}
+
+		infos[info.Id.String()] = info
+	}
+
+	return infos
+}
+
+func TestAllBlobInfosLocal(t *testing.T) {
+	store := makeTestLocalHashBucketed(t)
+	content := "a loose blob"
+	id := writeTestBlob(t, store, content)
+
+	infos := collectBlobInfos(t, store.AllBlobInfos())
+
+	info, ok := infos[id.String()]
+	if !ok || len(infos) != 1 {
+		t.Fatalf("expected only %s, got %v", id, infos)
+	}
+
+	if info.EntryType != BlobEntryTypeLoose {
+		t.Errorf("expected a loose blob, got %q", info.EntryType)
+	}
+
+	if info.Size != uint64(len(content)) || info.StoredSize != info.Size {
+		t.Errorf(
+			"expected %d bytes stored as is, got %d stored as %d",
+			len(content),
+			info.Size,
+			info.StoredSize,
+		)
+	}
+
+	if !files.Exists(info.Path) {
+		t.Errorf("expected the blob's file at %s", info.Path)
+	}
+}
+
+func TestAllBlobInfosV1(t *testing.T) {
+	commonPrefix := strings.Repeat("shared content block ", 100)
+	contents := []string{
+		commonPrefix + " unique suffix alpha",
+		commonPrefix + " unique suffix beta",
+		commonPrefix + " unique suffix gamma",
+	}
+
+	store, loose, ids := packTestBlobsV1(
+		t,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeZstd,
+			Delta: blob_store_configs.DeltaConfig{
+				Enabled:     true,
+				Algorithm:   "bsdiff",
+				MinBlobSize: 1,
+				MaxBlobSize: 10485760,
+				SizeRatio:   2.0,
+			},
+		},
+		contents...,
+	)
+
+	looseContent := "written after packing"
+	looseId := writeTestBlob(t, loose, looseContent)
+
+	infos := collectBlobInfos(t, store.AllBlobInfos())
+
+	if len(infos) != len(ids)+1 {
+		t.Fatalf("expected %d blobs, got %d", len(ids)+1, len(infos))
+	}
+
+	var deltaCount int
+
+	for i, id := range ids {
+		info := infos[id.String()]
+
+		if info.Size != uint64(len(contents[i])) {
+			t.Errorf("%s: expected size %d, got %d", id, len(contents[i]), info.Size)
+		}
+
+		if info.ArchiveChecksum == "" || !files.Exists(info.Path) {
+			t.Errorf("%s: expected an archive data file, got %q", id, info.Path)
+		}
+
+		if info.StoredSize == 0 || info.StoredSize >= info.Size {
+			t.Errorf(
+				"%s: expected a compressed entry, got %d stored bytes",
+				id,
+				info.StoredSize,
+			)
+		}
+
+		if info.EntryType == BlobEntryTypeDelta {
+			deltaCount++
+		}
+	}
+
+	if deltaCount == 0 {
+		t.Error("expected at least one delta entry")
+	}
+
+	if info := infos[looseId.String()]; info.EntryType != BlobEntryTypeLoose ||
+		info.Size != uint64(len(looseContent)) {
+		t.Errorf("expected the unpacked blob as loose, got %+v", info)
+	}
+
+	stored, err := blobInfoStoredBytes(store)
+	if err != nil {
+		t.Fatalf("blobInfoStoredBytes: %v", err)
+	}
+
+	if stored == 0 {
+		t.Error("expected a total of stored bytes")
+	}
+}
+
+func TestAllBlobInfosV0(t *testing.T) {
+	loose := makeTestLocalHashBucketed(t)
+	content := "archived v0 blob"
+	id := writeTestBlob(t, loose, content)
+
+	store := inventoryArchiveV0{
+		defaultHash:    markl.FormatHashSha256,
+		basePath:       t.TempDir(),
+		cachePath:      t.TempDir(),
+		looseBlobStore: loose,
+		index:          make(map[string]archiveEntry),
+		config: blob_store_configs.TomlInventoryArchiveV0{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+	}
+
+	if err := store.Pack(PackOptions{DeleteLoose: true}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	infos := collectBlobInfos(t, store.AllBlobInfos())
+
+	info, ok := infos[id.String()]
+	if !ok || len(infos) != 1 {
+		t.Fatalf("expected only %s, got %v", id, infos)
+	}
+
+	if info.EntryType != BlobEntryTypeFull ||
+		info.Size != uint64(len(content)) ||
+		!files.Exists(info.Path) {
+		t.Errorf("expected a full archive entry of %d bytes, got %+v", len(content), info)
+	}
+}
+
+func TestAllBlobInfosReadsSizesOfOtherStores(t *testing.T) {
+	content := []byte("only readable")
+	loose := makeTestLocalHashBucketed(t)
+	id := writeTestBlob(t, loose, string(content))
+
+	stub := &stubBlobStore{
+		allBlobIds: []domain_interfaces.MarklId{id},
+		blobData:   map[string][]byte{id.String(): content},
+	}
+
+	infos := collectBlobInfos(t, AllBlobInfos(stub))
+
+	if info := infos[id.String()]; info.Size != uint64(len(content)) ||
+		info.EntryType != "" {
+		t.Errorf("expected a size of %d and no entry type, got %+v", len(content), info)
+	}
+}
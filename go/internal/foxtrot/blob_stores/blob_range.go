@@ -0,0 +1,155 @@
+package blob_stores
+
+import (
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// BlobRangeReader is implemented by stores that can read part of a blob
+// without reading what comes before it. ok is false when the blob is not
+// stored in a way that allows it; callers then skip through a full reader.
+type BlobRangeReader interface {
+	MakeBlobRangeReader(
+		id domain_interfaces.MarklId,
+		offset int64,
+		length int64,
+	) (reader io.ReadCloser, ok bool, err error)
+}
+
+var _ BlobRangeReader = inventoryArchiveV1{}
+
+// MakeBlobRangeReader returns a reader for length bytes of the blob starting
+// at offset, or for the rest of the blob when length is negative. Stores
+// that are BlobRangeReaders read the range directly, all others are read and
+// discarded up to offset. Unlike MakeBlobReader, the content read is not
+// checked against the blob id, as only part of it is read.
+func MakeBlobRangeReader(
+	blobStore domain_interfaces.BlobStore,
+	id domain_interfaces.MarklId,
+	offset int64,
+	length int64,
+) (reader io.ReadCloser, err error) {
+	if offset < 0 {
+		err = errors.Errorf("negative offset: %d", offset)
+		return reader, err
+	}
+
+	if rangeReader, isRangeReader := blobStore.(BlobRangeReader); isRangeReader {
+		var ok bool
+
+		if reader, ok, err = rangeReader.MakeBlobRangeReader(
+			id,
+			offset,
+			length,
+		); err != nil || ok {
+			return reader, err
+		}
+	}
+
+	var blobReader domain_interfaces.BlobReader
+
+	if blobReader, err = blobStore.MakeBlobReader(id); err != nil {
+		err = errors.Wrap(err)
+		return reader, err
+	}
+
+	if _, err = io.CopyN(io.Discard, blobReader, offset); err != nil {
+		blobReader.Close()
+
+		if err == io.EOF {
+			err = errors.Errorf("offset %d is past the end of blob %s", offset, id)
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return reader, err
+	}
+
+	if length < 0 {
+		return blobReader, err
+	}
+
+	reader = limitedReadCloser{
+		Reader: io.LimitReader(blobReader, length),
+		Closer: blobReader,
+	}
+
+	return reader, err
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// MakeBlobRangeReader reads a range of a blob straight from its archive when
+// it is stored as a full entry that is neither compressed nor encrypted.
+// Loose blobs and all other entries are left to the caller.
+func (store inventoryArchiveV1) MakeBlobRangeReader(
+	id domain_interfaces.MarklId,
+	offset int64,
+	length int64,
+) (reader io.ReadCloser, ok bool, err error) {
+	entry, inArchive := store.index[id.String()]
+	if !inArchive {
+		return reader, false, err
+	}
+
+	file, archivePath, err := store.openArchiveDataFile(entry.ArchiveChecksum)
+	if err != nil {
+		return reader, false, err
+	}
+
+	defer func() {
+		if !ok || err != nil {
+			file.Close()
+		}
+	}()
+
+	dataReader, repoolDataReader, err := store.getDataReaderV1(
+		entry.ArchiveChecksum,
+		file,
+	)
+	if err != nil {
+		err = errors.Wrapf(err, "reading v1 archive header %s", archivePath)
+		return reader, false, err
+	}
+
+	defer repoolDataReader()
+
+	start, size, ok, err := dataReader.PlainEntryPayloadAt(entry.Offset)
+	if err != nil {
+		err = errors.Wrapf(
+			err,
+			"reading v1 entry at offset %d in %s",
+			entry.Offset,
+			archivePath,
+		)
+
+		return reader, false, err
+	}
+
+	if !ok {
+		return reader, false, err
+	}
+
+	if uint64(offset) > size {
+		err = errors.Errorf("offset %d is past the end of blob %s", offset, id)
+		return reader, false, err
+	}
+
+	remaining := int64(size) - offset
+
+	if length < 0 || length > remaining {
+		length = remaining
+	}
+
+	reader = limitedReadCloser{
+		Reader: io.NewSectionReader(file, start+offset, length),
+		Closer: file,
+	}
+
+	return reader, true, err
+}
@@ -0,0 +1,145 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func makePackedRangeStore(
+	t *testing.T,
+	compressionType compression_type.CompressionType,
+	data []byte,
+) (store inventoryArchiveV1, id domain_interfaces.MarklId) {
+	t.Helper()
+
+	rawHash := sha256.Sum256(data)
+	id, repool := markl.FormatHashSha256.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	t.Cleanup(repool)
+
+	store = inventoryArchiveV1{
+		defaultHash: markl.FormatHashSha256,
+		basePath:    t.TempDir(),
+		cachePath:   t.TempDir(),
+		looseBlobStore: &stubBlobStore{
+			allBlobIds: []domain_interfaces.MarklId{id},
+			blobData:   map[string][]byte{id.String(): data},
+		},
+		index: make(map[string]archiveEntryV1),
+		config: blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compressionType,
+		},
+	}
+
+	if err := store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	if _, ok := store.index[id.String()]; !ok {
+		t.Fatal("expected the blob to be packed")
+	}
+
+	return store, id
+}
+
+func readRange(
+	t *testing.T,
+	blobStore domain_interfaces.BlobStore,
+	id domain_interfaces.MarklId,
+	offset, length int64,
+) []byte {
+	t.Helper()
+
+	reader, err := MakeBlobRangeReader(blobStore, id, offset, length)
+	if err != nil {
+		t.Fatalf("MakeBlobRangeReader: %v", err)
+	}
+
+	defer reader.Close()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	return got
+}
+
+func TestBlobRangeReaderPlainArchiveEntry(t *testing.T) {
+	var buffer bytes.Buffer
+
+	for i := range 100 {
+		fmt.Fprintf(&buffer, "line %d\n", i)
+	}
+
+	data := buffer.Bytes()
+
+	store, id := makePackedRangeStore(
+		t,
+		compression_type.CompressionTypeNone,
+		data,
+	)
+
+	reader, ok, err := store.MakeBlobRangeReader(id, 10, 20)
+	if err != nil {
+		t.Fatalf("MakeBlobRangeReader: %v", err)
+	}
+
+	if !ok {
+		t.Fatal("expected an uncompressed entry to be read in place")
+	}
+
+	reader.Close()
+
+	if got := readRange(t, store, id, 10, 20); !bytes.Equal(got, data[10:30]) {
+		t.Errorf("expected %q, got %q", data[10:30], got)
+	}
+
+	if got := readRange(t, store, id, 10, -1); !bytes.Equal(got, data[10:]) {
+		t.Errorf("expected the rest of the blob, got %d bytes", len(got))
+	}
+
+	if got := readRange(t, store, id, 0, 1<<20); !bytes.Equal(got, data) {
+		t.Errorf("expected a long length to stop at the end of the blob")
+	}
+
+	if _, err := MakeBlobRangeReader(
+		store,
+		id,
+		int64(len(data))+1,
+		-1,
+	); err == nil {
+		t.Error("expected an error for an offset past the end of the blob")
+	}
+}
+
+func TestBlobRangeReaderCompressedFallsBack(t *testing.T) {
+	data := bytes.Repeat([]byte("compressible "), 200)
+
+	store, id := makePackedRangeStore(
+		t,
+		compression_type.CompressionTypeZstd,
+		data,
+	)
+
+	if _, ok, err := store.MakeBlobRangeReader(id, 0, 1); err != nil || ok {
+		t.Fatalf("expected a compressed entry to be refused, got %t, %v", ok, err)
+	}
+
+	if got := readRange(t, store, id, 13, 26); !bytes.Equal(got, data[13:39]) {
+		t.Errorf("expected %q, got %q", data[13:39], got)
+	}
+}
@@ -0,0 +1,348 @@
+package blob_stores
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+// ErrBudgetExceeded is returned by writes that would take a store past its
+// `max-total-bytes`.
+type ErrBudgetExceeded struct {
+	Store         string
+	MaxTotalBytes uint64
+	UsedBytes     int64
+	BlobBytes     int64
+}
+
+func (err ErrBudgetExceeded) Error() string {
+	return fmt.Sprintf(
+		"blob store %s is over budget: a %s blob does not fit in the %s left of %s",
+		err.Store,
+		ui.GetHumanBytesStringOrError(err.BlobBytes),
+		ui.GetHumanBytesStringOrError(int64(err.MaxTotalBytes)-err.UsedBytes),
+		ui.GetHumanBytesString(err.MaxTotalBytes),
+	)
+}
+
+func (err ErrBudgetExceeded) Is(target error) bool {
+	_, ok := target.(ErrBudgetExceeded)
+	return ok
+}
+
+func (err ErrBudgetExceeded) GetErrorCode() errors.Code {
+	return errors.CodeBudgetExceeded
+}
+
+// StorageUsage is the bytes a store keeps, as stored, against its budget. A
+// zero MaxTotalBytes means the store has no budget.
+type StorageUsage struct {
+	UsedBytes     int64
+	MaxTotalBytes uint64
+	WarnAtPercent int
+}
+
+func makeStorageUsage(
+	config blob_store_configs.Config,
+	usedBytes int64,
+) (usage StorageUsage) {
+	usage.UsedBytes = usedBytes
+
+	if budgetConfig, ok := config.(blob_store_configs.ConfigBudget); ok {
+		usage.MaxTotalBytes = budgetConfig.GetMaxTotalBytes()
+		usage.WarnAtPercent = budgetConfig.GetWarnAtPercent()
+	}
+
+	return usage
+}
+
+func (usage StorageUsage) HasBudget() bool {
+	return usage.MaxTotalBytes > 0
+}
+
+// GetPercent returns the percentage of the budget in use, or 0 without one.
+func (usage StorageUsage) GetPercent() float64 {
+	if !usage.HasBudget() {
+		return 0
+	}
+
+	return float64(usage.UsedBytes) * 100 / float64(usage.MaxTotalBytes)
+}
+
+// IsOverWarning reports whether the store is at or past `warn-at-percent` of
+// its budget.
+func (usage StorageUsage) IsOverWarning() bool {
+	return usage.HasBudget() && usage.WarnAtPercent > 0 &&
+		usage.GetPercent() >= float64(usage.WarnAtPercent)
+}
+
+func (usage StorageUsage) String() string {
+	if !usage.HasBudget() {
+		return ui.GetHumanBytesStringOrError(usage.UsedBytes)
+	}
+
+	return fmt.Sprintf(
+		"%s of %s (%.1f%%)",
+		ui.GetHumanBytesStringOrError(usage.UsedBytes),
+		ui.GetHumanBytesString(usage.MaxTotalBytes),
+		usage.GetPercent(),
+	)
+}
+
+// StorageReporter is implemented by stores that can total the bytes they
+// keep.
+type StorageReporter interface {
+	GetStorageUsage() (StorageUsage, error)
+}
+
+var (
+	_ StorageReporter = localHashBucketed{}
+	_ StorageReporter = lazyParent{}
+	_ StorageReporter = &remoteSftp{}
+)
+
+// StorageReportEntry is one store's usage in a StorageReport. Err is set
+// when the store's usage could not be totaled.
+type StorageReportEntry struct {
+	StoreId string
+	Usage   StorageUsage
+	Err     error
+}
+
+// StorageReport totals the usage of each of blobStores, by store id. Stores
+// that are not StorageReporters but BlobInfoListers are totaled by the stored
+// sizes of their blobs, and all others are included with an error.
+func StorageReport(blobStores BlobStoreMap) (entries []StorageReportEntry) {
+	for storeId, blobStore := range blobStores {
+		entry := StorageReportEntry{StoreId: storeId}
+
+		if reporter, ok := blobStore.BlobStore.(StorageReporter); ok {
+			entry.Usage, entry.Err = reporter.GetStorageUsage()
+		} else if lister, ok := blobStore.BlobStore.(BlobInfoLister); ok {
+			var used int64
+
+			if used, entry.Err = blobInfoStoredBytes(lister); entry.Err == nil {
+				entry.Usage = makeStorageUsage(blobStore.Config.Blob, used)
+			}
+		} else {
+			entry.Err = errors.Errorf(
+				"cannot total the usage of a %s store",
+				blobStore.GetBlobStoreDescription(),
+			)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b StorageReportEntry) int {
+		return strings.Compare(a.StoreId, b.StoreId)
+	})
+
+	return entries
+}
+
+// blobInfoStoredBytes totals the stored sizes of a store's blobs.
+func blobInfoStoredBytes(lister BlobInfoLister) (total int64, err error) {
+	for info, err := range lister.AllBlobInfos() {
+		if err != nil {
+			return total, err
+		}
+
+		total += int64(info.StoredSize)
+	}
+
+	return total, err
+}
+
+// storeBudget enforces a store's `max-total-bytes`. The store's usage is
+// totaled on the first reservation and tracked in memory after that, so
+// writes by other processes in the meantime are not accounted for.
+type storeBudget struct {
+	config    blob_store_configs.ConfigBudget
+	label     string
+	usedBytes func() (int64, error)
+
+	lock   sync.Mutex
+	loaded bool
+	used   int64
+	warned bool
+}
+
+// makeStoreBudget returns nil unless config sets a budget.
+func makeStoreBudget(
+	config blob_store_configs.Config,
+	label string,
+	usedBytes func() (int64, error),
+) *storeBudget {
+	budgetConfig, ok := config.(blob_store_configs.ConfigBudget)
+
+	if !ok || budgetConfig.GetMaxTotalBytes() == 0 {
+		return nil
+	}
+
+	return &storeBudget{
+		config:    budgetConfig,
+		label:     label,
+		usedBytes: usedBytes,
+	}
+}
+
+func (budget *storeBudget) loadLocked() (err error) {
+	if budget.loaded {
+		return err
+	}
+
+	if budget.used, err = budget.usedBytes(); err != nil {
+		err = errors.Wrapf(err, "totaling usage of blob store %s", budget.label)
+		return err
+	}
+
+	budget.loaded = true
+
+	return err
+}
+
+// reserve accounts for a new blob of bytes, failing with ErrBudgetExceeded if
+// it does not fit. Crossing `warn-at-percent` warns once per process.
+func (budget *storeBudget) reserve(bytes int64) (err error) {
+	budget.lock.Lock()
+	defer budget.lock.Unlock()
+
+	if err = budget.loadLocked(); err != nil {
+		return err
+	}
+
+	maxTotalBytes := budget.config.GetMaxTotalBytes()
+
+	if budget.used+bytes > int64(maxTotalBytes) {
+		err = ErrBudgetExceeded{
+			Store:         budget.label,
+			MaxTotalBytes: maxTotalBytes,
+			UsedBytes:     budget.used,
+			BlobBytes:     bytes,
+		}
+
+		return err
+	}
+
+	budget.used += bytes
+
+	usage := StorageUsage{
+		UsedBytes:     budget.used,
+		MaxTotalBytes: maxTotalBytes,
+		WarnAtPercent: budget.config.GetWarnAtPercent(),
+	}
+
+	if !budget.warned && usage.IsOverWarning() {
+		budget.warned = true
+		ui.Err().Printf("blob store %s is using %s of its budget", budget.label, usage)
+	}
+
+	return err
+}
+
+// release accounts for a removed blob of bytes.
+func (budget *storeBudget) release(bytes int64) {
+	budget.lock.Lock()
+	defer budget.lock.Unlock()
+
+	if !budget.loaded {
+		return
+	}
+
+	budget.used = max(budget.used-bytes, 0)
+}
+
+// localStoredBytes totals the sizes of the files under basePath, leaving out
+// the dot-prefixed temporary files that linking leaves while it runs.
+func localStoredBytes(basePath string) (total int64, err error) {
+	if err = filepath.WalkDir(
+		basePath,
+		func(path string, dirEntry fs.DirEntry, in error) (err error) {
+			if in != nil {
+				if errors.IsNotExist(in) && path == basePath {
+					return filepath.SkipAll
+				}
+
+				err = errors.Wrap(in)
+				return err
+			}
+
+			if dirEntry.IsDir() || strings.HasPrefix(dirEntry.Name(), ".") {
+				return err
+			}
+
+			var info fs.FileInfo
+
+			if info, err = dirEntry.Info(); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			total += info.Size()
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return total, err
+	}
+
+	return total, err
+}
+
+func (blobStore localHashBucketed) GetStorageUsage() (usage StorageUsage, err error) {
+	var used int64
+
+	if used, err = localStoredBytes(blobStore.basePath); err != nil {
+		return usage, err
+	}
+
+	return makeStorageUsage(blobStore.config, used), err
+}
+
+func (blobStore lazyParent) GetStorageUsage() (StorageUsage, error) {
+	return blobStore.local.GetStorageUsage()
+}
+
+// storedBytes totals the sizes of the files under the remote path, leaving
+// out the temporary files of writes in progress.
+func (blobStore *remoteSftp) storedBytes() (total int64, err error) {
+	blobStore.initializeOnce()
+
+	walker := blobStore.sftpClient.Walk(blobStore.config.GetRemotePath())
+
+	for walker.Step() {
+		if err = walker.Err(); err != nil {
+			err = errors.Wrap(err)
+			return total, err
+		}
+
+		info := walker.Stat()
+
+		if info.IsDir() || strings.HasPrefix(info.Name(), "tmp_") {
+			continue
+		}
+
+		total += info.Size()
+	}
+
+	return total, err
+}
+
+func (blobStore *remoteSftp) GetStorageUsage() (usage StorageUsage, err error) {
+	var used int64
+
+	if used, err = blobStore.storedBytes(); err != nil {
+		return usage, err
+	}
+
+	return makeStorageUsage(blobStore.config, used), err
+}
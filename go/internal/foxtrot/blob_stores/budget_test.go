@@ -0,0 +1,83 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func makeTestBudgetedLocalHashBucketed(
+	t *testing.T,
+	maxTotalBytes uint64,
+) localHashBucketed {
+	blobStore := makeTestLocalHashBucketed(t)
+
+	config := &blob_store_configs.TomlV3{
+		HashBuckets:     blob_store_configs.DefaultHashBuckets,
+		HashTypeId:      blob_store_configs.HashTypeSha256,
+		CompressionType: compression_type.CompressionTypeNone,
+		BudgetConfig: blob_store_configs.BudgetConfig{
+			MaxTotalBytes: maxTotalBytes,
+			WarnAtPercent: 50,
+		},
+	}
+
+	blobStore.config = config
+	blobStore.budget = makeStoreBudget(config, "test", func() (int64, error) {
+		return localStoredBytes(blobStore.basePath)
+	})
+
+	return blobStore
+}
+
+func TestBudgetRejectsWritesPastMaxTotalBytes(t *testing.T) {
+	blobStore := makeTestBudgetedLocalHashBucketed(t, 10)
+
+	writeTestBlob(t, blobStore, "six b.")
+
+	writer, err := blobStore.MakeBlobWriter(nil)
+	if err != nil {
+		t.Fatalf("MakeBlobWriter: %v", err)
+	}
+
+	if _, err := writer.Write([]byte("six c.")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); !errors.Is(err, ErrBudgetExceeded{}) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+
+	if blobStore.HasBlob(writer.GetMarklId()) {
+		t.Error("expected the blob past the budget to be discarded")
+	}
+
+	// an existing blob takes no more space
+	writeTestBlob(t, blobStore, "six b.")
+
+	usage, err := blobStore.GetStorageUsage()
+	if err != nil {
+		t.Fatalf("GetStorageUsage: %v", err)
+	}
+
+	if usage.UsedBytes != 6 || usage.MaxTotalBytes != 10 ||
+		!usage.IsOverWarning() {
+		t.Errorf("expected 6 of 10 bytes, past the warning, got %v", usage)
+	}
+}
+
+func TestBudgetReleasesDeletedBlobs(t *testing.T) {
+	blobStore := makeTestBudgetedLocalHashBucketed(t, 10)
+
+	id := writeTestBlob(t, blobStore, "six b.")
+
+	if err := blobStore.DeleteBlob(id); err != nil {
+		t.Fatalf("DeleteBlob: %v", err)
+	}
+
+	writeTestBlob(t, blobStore, "six c.")
+}
@@ -7,6 +7,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
 	"code.linenisgreat.com/dodder/go/internal/delta/env_ui"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 )
 
@@ -43,6 +44,48 @@ func CopyBlobIfNecessary(
 
 	errors.PanicIfError(markl.AssertIdIsNotNull(expectedDigest))
 
+	limiters := getTransferLimiters(dst, src)
+
+	{
+		release, err := limiters.acquire(env)
+		if err != nil {
+			copyResult.SetError(err)
+			return copyResult
+		}
+
+		defer release()
+	}
+
+	// a resumed upload is stored under the destination's own hash type, so it
+	// is only used when that is the expected digest's. extraWriter only sees
+	// the bytes sent by this attempt.
+	if resumer, ok := dst.(BlobUploadResumer); ok &&
+		(hashType == nil ||
+			hashType.GetMarklFormatId() ==
+				expectedDigest.GetMarklFormat().GetMarklFormatId()) &&
+		dst.GetDefaultHashType().GetMarklFormatId() ==
+			expectedDigest.GetMarklFormat().GetMarklFormatId() {
+		size, err := resumer.ResumeBlobUpload(
+			expectedDigest,
+			func() (domain_interfaces.BlobReader, error) {
+				return src.MakeBlobReader(expectedDigest)
+			},
+			func(reader io.Reader) io.Reader {
+				reader = limiters.pace(env, reader)
+
+				if extraWriter != nil {
+					reader = io.TeeReader(reader, extraWriter)
+				}
+
+				return reader
+			},
+		)
+
+		copyResult.setErrorAfterCopy(size, err)
+
+		return copyResult
+	}
+
 	var readCloser domain_interfaces.BlobReader
 
 	{
@@ -91,7 +134,7 @@ func CopyBlobIfNecessary(
 
 		copyResult.bytesWritten, err = io.Copy(
 			outputWriter,
-			readCloser,
+			limiters.pace(env, readCloser),
 		)
 		if err != nil {
 			copyResult.setErrorAfterCopy(copyResult.bytesWritten, err)
@@ -145,6 +188,44 @@ func CopyBlobIfNecessary(
 	return copyResult
 }
 
+// CopyBlobIfNecessaryWithRetry is CopyBlobIfNecessary, retried according to
+// policy when the copy fails with a retryable error (e.g., a dropped remote
+// connection). An extraWriter that is an interfaces.Resetable, like a progress
+// writer, is reset before every attempt, so it only counts the bytes of the
+// latest one.
+func CopyBlobIfNecessaryWithRetry(
+	env env_ui.Env,
+	policy errors.RetryPolicy,
+	dst domain_interfaces.BlobStore,
+	src domain_interfaces.BlobStore,
+	expectedDigest domain_interfaces.MarklId,
+	extraWriter io.Writer,
+	hashType domain_interfaces.FormatHash,
+) (copyResult CopyResult) {
+	errors.Retry(
+		env,
+		policy,
+		func() error {
+			if resetable, ok := extraWriter.(interfaces.Resetable); ok {
+				resetable.Reset()
+			}
+
+			copyResult = CopyBlobIfNecessary(
+				env,
+				dst,
+				src,
+				expectedDigest,
+				extraWriter,
+				hashType,
+			)
+
+			return copyResult.GetError()
+		},
+	)
+
+	return copyResult
+}
+
 func CopyReaderToWriter(
 	ctx errors.Context,
 	dst domain_interfaces.BlobWriter,
@@ -154,6 +235,20 @@ func CopyReaderToWriter(
 	heartbeats func(time time.Time),
 	pulse time.Duration,
 ) (copyResult CopyResult) {
+	limiters := getTransferLimiters()
+
+	{
+		release, err := limiters.acquire(ctx)
+		if err != nil {
+			copyResult.SetError(err)
+			return copyResult
+		}
+
+		defer release()
+	}
+
+	src = limiters.pace(ctx, src)
+
 	var writer io.Writer = dst
 
 	if extraWriter != nil {
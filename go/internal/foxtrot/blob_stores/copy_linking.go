@@ -0,0 +1,278 @@
+package blob_stores
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/env_ui"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// BlobLinker is implemented by stores that can add a blob by sharing the
+// file another store keeps it in, instead of streaming its content through a
+// writer. LinkBlobFrom fails when the stores do not encode blobs
+// identically or do not share a filesystem; callers then copy the blob.
+type BlobLinker interface {
+	LinkBlobFrom(
+		src domain_interfaces.BlobStore,
+		id domain_interfaces.MarklId,
+	) (size int64, err error)
+}
+
+var _ BlobLinker = localHashBucketed{}
+
+// LinkingCopyBlobIfNecessary is CopyBlobIfNecessary for stores on the same
+// filesystem: when dst is a BlobLinker it first tries to hard link, then to
+// reflink, the blob's file from src, and only copies the blob when neither
+// works. Linking skips extraWriter, so it is only attempted without one.
+func LinkingCopyBlobIfNecessary(
+	env env_ui.Env,
+	dst domain_interfaces.BlobStore,
+	src domain_interfaces.BlobStore,
+	expectedDigest domain_interfaces.MarklId,
+	extraWriter io.Writer,
+	hashType domain_interfaces.FormatHash,
+) (copyResult CopyResult) {
+	linker, ok := dst.(BlobLinker)
+
+	// a different hash type means the blob is rehashed while copying
+	sameHashType := hashType == nil ||
+		hashType.GetMarklFormatId() ==
+			expectedDigest.GetMarklFormat().GetMarklFormatId()
+
+	if ok && src != nil && extraWriter == nil && sameHashType &&
+		!dst.HasBlob(expectedDigest) {
+		if size, err := linker.LinkBlobFrom(src, expectedDigest); err == nil {
+			copyResult.BlobId = expectedDigest
+			copyResult.bytesWritten = size
+			copyResult.state = CopyResultStateSuccess
+			return copyResult
+		}
+	}
+
+	return CopyBlobIfNecessary(
+		env,
+		dst,
+		src,
+		expectedDigest,
+		extraWriter,
+		hashType,
+	)
+}
+
+// LinkBlobFrom places src's file for id in this store as a hard link, or as
+// a reflink when the hard link fails, and verifies the result: a hard link
+// has to resolve to the source file, and a reflink has to match its size.
+// Both stores have to be local hash bucketed stores with the same
+// compression and encryption, as the file is shared without re-encoding it.
+func (blobStore localHashBucketed) LinkBlobFrom(
+	src domain_interfaces.BlobStore,
+	id domain_interfaces.MarklId,
+) (size int64, err error) {
+	srcLocal, ok := src.(localHashBucketed)
+	if !ok {
+		err = errors.Errorf(
+			"cannot link blobs from a %s store",
+			src.GetBlobStoreDescription(),
+		)
+
+		return size, err
+	}
+
+	if err = blobStore.checkLinkableFrom(srcLocal, id); err != nil {
+		return size, err
+	}
+
+	srcPath := env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		srcLocal.buckets,
+		srcLocal.multiHash,
+		srcLocal.basePath,
+	)
+
+	dstPath := env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		blobStore.buckets,
+		blobStore.multiHash,
+		blobStore.basePath,
+	)
+
+	srcInfo, err := os.Stat(srcPath)
+	if err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(dstPath), os.ModeDir|0o755); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	// a linked blob counts against the budget like a copied one
+	if blobStore.budget != nil {
+		if err = blobStore.budget.reserve(srcInfo.Size()); err != nil {
+			err = errors.Wrap(err)
+			return size, err
+		}
+
+		defer func() {
+			if err != nil {
+				blobStore.budget.release(srcInfo.Size())
+			}
+		}()
+	}
+
+	if linkErr := os.Link(srcPath, dstPath); linkErr == nil {
+		var dstInfo os.FileInfo
+
+		if dstInfo, err = os.Stat(dstPath); err != nil {
+			err = errors.Wrap(err)
+			return size, err
+		}
+
+		if !os.SameFile(srcInfo, dstInfo) {
+			err = errors.Errorf(
+				"hard link %s does not resolve to %s",
+				dstPath,
+				srcPath,
+			)
+
+			return size, err
+		}
+
+		return srcInfo.Size(), err
+	}
+
+	if err = reflinkBlobFile(srcPath, dstPath, srcInfo); err != nil {
+		return size, err
+	}
+
+	return srcInfo.Size(), err
+}
+
+// checkLinkableFrom fails unless a file written by src for id is also what
+// this store would write for it, and this store is writable outside of a dry
+// run.
+func (blobStore localHashBucketed) checkLinkableFrom(
+	src localHashBucketed,
+	id domain_interfaces.MarklId,
+) (err error) {
+	if err = errIfReadOnly(blobStore.readOnly, blobStore.basePath); err != nil {
+		return err
+	}
+
+	// a dry run copies the blob into its own store instead
+	if blobStore.dryRun != nil {
+		err = errors.Errorf("cannot link blobs during a dry run")
+		return err
+	}
+
+	if id.IsNull() {
+		err = errors.Errorf("cannot link the null blob")
+		return err
+	}
+
+	if !blobStore.multiHash &&
+		id.GetMarklFormat().GetMarklFormatId() !=
+			blobStore.defaultHashFormat.GetMarklFormatId() {
+		err = errors.Errorf(
+			"single-hash store cannot hold a %s blob",
+			id.GetMarklFormat().GetMarklFormatId(),
+		)
+
+		return err
+	}
+
+	if fmt.Sprint(src.config.GetBlobCompression()) !=
+		fmt.Sprint(blobStore.config.GetBlobCompression()) {
+		err = errors.Errorf("stores use different compression")
+		return err
+	}
+
+	if !markl.Equals(
+		src.config.GetBlobEncryption(),
+		blobStore.config.GetBlobEncryption(),
+	) {
+		err = errors.Errorf("stores use different encryption")
+		return err
+	}
+
+	return err
+}
+
+// reflinkBlobFile clones srcPath into a temporary file next to dstPath and
+// renames it into place, so that a failed clone never leaves a partial blob
+// under its final name.
+func reflinkBlobFile(
+	srcPath string,
+	dstPath string,
+	srcInfo os.FileInfo,
+) (err error) {
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, srcFile)
+
+	tempFile, err := os.CreateTemp(filepath.Dir(dstPath), ".reflink-*")
+	if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	tempPath := tempFile.Name()
+
+	defer func() {
+		if err != nil {
+			os.Remove(tempPath)
+		}
+	}()
+
+	if err = reflinkFile(tempFile, srcFile); err != nil {
+		tempFile.Close()
+		err = errors.Wrapf(err, "reflinking %s", srcPath)
+		return err
+	}
+
+	if err = tempFile.Chmod(srcInfo.Mode().Perm()); err != nil {
+		tempFile.Close()
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = tempFile.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	tempInfo, err := os.Stat(tempPath)
+	if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if tempInfo.Size() != srcInfo.Size() {
+		err = errors.Errorf(
+			"reflink of %s has %d bytes, expected %d",
+			srcPath,
+			tempInfo.Size(),
+			srcInfo.Size(),
+		)
+
+		return err
+	}
+
+	if err = os.Rename(tempPath, dstPath); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
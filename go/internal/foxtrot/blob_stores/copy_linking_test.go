@@ -0,0 +1,107 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestLinkBlobFromHardLinksSharedFile(t *testing.T) {
+	src := makeTestLocalHashBucketed(t)
+	dst := makeTestLocalHashBucketed(t)
+
+	testData := []byte("linked rather than copied")
+
+	writer, err := src.MakeBlobWriter(nil)
+	if err != nil {
+		t.Fatalf("MakeBlobWriter: %v", err)
+	}
+
+	if _, err := writer.Write(testData); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	id := writer.GetMarklId()
+
+	if _, err := dst.LinkBlobFrom(src, id); err != nil {
+		t.Fatalf("LinkBlobFrom: %v", err)
+	}
+
+	srcInfo, err := os.Stat(env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		src.buckets,
+		src.multiHash,
+		src.basePath,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dstInfo, err := os.Stat(env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		dst.buckets,
+		dst.multiHash,
+		dst.basePath,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !os.SameFile(srcInfo, dstInfo) {
+		t.Error("expected the destination blob to be a hard link to the source")
+	}
+
+	reader, err := dst.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	defer reader.Close()
+
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if string(actual) != string(testData) {
+		t.Errorf("expected %q but got %q", testData, actual)
+	}
+}
+
+func TestLinkBlobFromRefusesDifferentCompression(t *testing.T) {
+	src := makeTestLocalHashBucketed(t)
+	dst := makeTestLocalHashBucketed(t)
+
+	dst.config = &blob_store_configs.TomlLazyParentV0{
+		HashBuckets:     blob_store_configs.DefaultHashBuckets,
+		HashTypeId:      blob_store_configs.HashTypeSha256,
+		CompressionType: compression_type.CompressionTypeZstd,
+	}
+
+	writer, err := src.MakeBlobWriter(nil)
+	if err != nil {
+		t.Fatalf("MakeBlobWriter: %v", err)
+	}
+
+	if _, err := writer.Write([]byte("stored uncompressed")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := dst.LinkBlobFrom(src, writer.GetMarklId()); err == nil {
+		t.Fatal("expected linking to fail between stores with different compression")
+	}
+}
@@ -0,0 +1,97 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/env_ui"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// flakySrc fails its first read with a retryable error after failAfter bytes.
+type flakySrc struct {
+	domain_interfaces.BlobStore
+	failAfter int
+	failed    bool
+}
+
+func (src *flakySrc) MakeBlobReader(
+	id domain_interfaces.MarklId,
+) (domain_interfaces.BlobReader, error) {
+	reader, err := src.BlobStore.MakeBlobReader(id)
+	if err != nil || src.failed {
+		return reader, err
+	}
+
+	src.failed = true
+
+	return &flakyReader{BlobReader: reader, remaining: src.failAfter}, nil
+}
+
+type flakyReader struct {
+	domain_interfaces.BlobReader
+	remaining int
+}
+
+func (reader *flakyReader) Read(p []byte) (n int, err error) {
+	if reader.remaining <= 0 {
+		return n, errors.MarkRetryable(io.ErrUnexpectedEOF)
+	}
+
+	p = p[:min(len(p), reader.remaining)]
+	n, err = reader.BlobReader.Read(p)
+	reader.remaining -= n
+
+	return n, err
+}
+
+func (reader *flakyReader) WriteTo(writer io.Writer) (int64, error) {
+	return io.Copy(writer, struct{ io.Reader }{reader})
+}
+
+func TestCopyBlobWithRetryResetsProgress(t *testing.T) {
+	src := makeTestLocalHashBucketed(t)
+	dst := makeTestLocalHashBucketed(t)
+
+	testData := []byte("counted once, even though the first attempt failed")
+
+	writer, err := src.MakeBlobWriter(nil)
+	if err != nil {
+		t.Fatalf("MakeBlobWriter: %v", err)
+	}
+
+	if _, err := writer.Write(testData); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	id, _ := markl.Clone(writer.GetMarklId())
+
+	var progressWriter env_ui.ProgressWriter
+
+	copyResult := CopyBlobIfNecessaryWithRetry(
+		env_ui.MakeDefault(errors.MakeContextDefault()),
+		errors.RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond},
+		dst,
+		&flakySrc{BlobStore: src, failAfter: 10},
+		id,
+		&progressWriter,
+		nil,
+	)
+
+	if err := copyResult.GetError(); err != nil {
+		t.Fatalf("CopyBlobIfNecessaryWithRetry: %v", err)
+	}
+
+	if written := progressWriter.GetWritten(); written != int64(len(testData)) {
+		t.Errorf("expected %d bytes of progress, got %d", len(testData), written)
+	}
+}
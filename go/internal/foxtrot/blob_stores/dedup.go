@@ -0,0 +1,228 @@
+package blob_stores
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// Duplicate is a blob kept by more than one store. Blob ids digest the
+// plaintext, so copies stored with different compression or encryption are
+// duplicates too.
+type Duplicate struct {
+	BlobId   domain_interfaces.MarklId
+	StoreIds []string
+}
+
+// FindDuplicates lists the blobs kept by more than one of blobStores, in the
+// order they are first found when walking the stores by id.
+func FindDuplicates(
+	blobStores BlobStoreMap,
+) (duplicates []Duplicate, err error) {
+	storeIds := make([]string, 0, len(blobStores))
+
+	for storeId := range blobStores {
+		storeIds = append(storeIds, storeId)
+	}
+
+	slices.Sort(storeIds)
+
+	indexes := make(map[string]int)
+	var found []Duplicate
+
+	for _, storeId := range storeIds {
+		for id, iterErr := range blobStores[storeId].AllBlobs() {
+			if iterErr != nil {
+				err = errors.Wrapf(iterErr, "blob store: %s", storeId)
+				return duplicates, err
+			}
+
+			key := id.String()
+
+			if i, ok := indexes[key]; ok {
+				if !slices.Contains(found[i].StoreIds, storeId) {
+					found[i].StoreIds = append(found[i].StoreIds, storeId)
+				}
+
+				continue
+			}
+
+			// ids are reused while iterating
+			cloned, _ := markl.Clone(id)
+
+			indexes[key] = len(found)
+			found = append(
+				found,
+				Duplicate{BlobId: cloned, StoreIds: []string{storeId}},
+			)
+		}
+	}
+
+	for _, duplicate := range found {
+		if len(duplicate.StoreIds) > 1 {
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+
+	return duplicates, err
+}
+
+// BlobRelinker is implemented by stores that can replace their copy of a blob
+// with the file another store keeps it in.
+type BlobRelinker interface {
+	RelinkBlobFrom(
+		src domain_interfaces.BlobStore,
+		id domain_interfaces.MarklId,
+	) (size int64, err error)
+}
+
+var _ BlobRelinker = localHashBucketed{}
+
+// DedupResult is what consolidating a duplicate did to one store's copy.
+// Size is the size of the replaced file, or 0 if the copy already shared the
+// kept store's file. Err is why the copy could not be replaced.
+type DedupResult struct {
+	StoreId     string
+	KeptStoreId string
+	Size        int64
+	Err         error
+}
+
+// Dedup consolidates a duplicate by hard linking every copy to the one in the
+// first local store, by id, that verifies. Copies in stores that cannot be relinked,
+// or that encode blobs differently from the kept store, are left in place
+// and reported with an error.
+func Dedup(
+	ctx errors.Context,
+	blobStores BlobStoreMap,
+	duplicate Duplicate,
+) (results []DedupResult) {
+	keptStoreId := ""
+
+	for _, storeId := range duplicate.StoreIds {
+		// only a store that can be relinked can be linked to
+		if _, ok := blobStores[storeId].BlobStore.(BlobRelinker); !ok {
+			continue
+		}
+
+		if err := VerifyBlob(
+			ctx,
+			blobStores[storeId],
+			duplicate.BlobId,
+			io.Discard,
+		); err == nil {
+			keptStoreId = storeId
+			break
+		}
+	}
+
+	for _, storeId := range duplicate.StoreIds {
+		if storeId == keptStoreId {
+			continue
+		}
+
+		result := DedupResult{StoreId: storeId, KeptStoreId: keptStoreId}
+
+		if keptStoreId == "" {
+			result.Err = errors.Errorf("no local copy verifies")
+			results = append(results, result)
+			continue
+		}
+
+		relinker, ok := blobStores[storeId].BlobStore.(BlobRelinker)
+
+		if !ok {
+			result.Err = errors.Errorf(
+				"cannot relink blobs in a %s store",
+				blobStores[storeId].GetBlobStoreDescription(),
+			)
+		} else {
+			result.Size, result.Err = relinker.RelinkBlobFrom(
+				blobStores[keptStoreId].BlobStore,
+				duplicate.BlobId,
+			)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// RelinkBlobFrom replaces this store's file for id with a hard link to src's,
+// returning the size of the replaced file, or 0 if both already are the same
+// file. Like LinkBlobFrom, both stores have to encode blobs identically. The
+// link is made next to the file and renamed over it, so the blob is never
+// missing.
+func (blobStore localHashBucketed) RelinkBlobFrom(
+	src domain_interfaces.BlobStore,
+	id domain_interfaces.MarklId,
+) (size int64, err error) {
+	srcLocal, ok := src.(localHashBucketed)
+	if !ok {
+		err = errors.Errorf(
+			"cannot link blobs from a %s store",
+			src.GetBlobStoreDescription(),
+		)
+
+		return size, err
+	}
+
+	if err = blobStore.checkLinkableFrom(srcLocal, id); err != nil {
+		return size, err
+	}
+
+	srcPath := env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		srcLocal.buckets,
+		srcLocal.multiHash,
+		srcLocal.basePath,
+	)
+
+	dstPath := env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		blobStore.buckets,
+		blobStore.multiHash,
+		blobStore.basePath,
+	)
+
+	srcInfo, err := os.Stat(srcPath)
+	if err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	dstInfo, err := os.Stat(dstPath)
+	if err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	if os.SameFile(srcInfo, dstInfo) {
+		return size, err
+	}
+
+	tempPath := filepath.Join(
+		filepath.Dir(dstPath),
+		".relink-"+filepath.Base(dstPath),
+	)
+
+	if err = os.Link(srcPath, tempPath); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	if err = os.Rename(tempPath, dstPath); err != nil {
+		os.Remove(tempPath)
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	return dstInfo.Size(), err
+}
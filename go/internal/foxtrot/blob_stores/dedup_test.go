@@ -0,0 +1,115 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"os"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func writeTestBlob(
+	t testing.TB,
+	blobStore localHashBucketed,
+	data string,
+) domain_interfaces.MarklId {
+	t.Helper()
+
+	writer, err := blobStore.MakeBlobWriter(nil)
+	if err != nil {
+		t.Fatalf("MakeBlobWriter: %v", err)
+	}
+
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	return writer.GetMarklId()
+}
+
+func statTestBlob(
+	t *testing.T,
+	blobStore localHashBucketed,
+	id domain_interfaces.MarklId,
+) os.FileInfo {
+	t.Helper()
+
+	info, err := os.Stat(env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		blobStore.buckets,
+		blobStore.multiHash,
+		blobStore.basePath,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return info
+}
+
+func TestFindDuplicatesAndDedup(t *testing.T) {
+	one := makeTestLocalHashBucketed(t)
+	two := makeTestLocalHashBucketed(t)
+
+	id := writeTestBlob(t, one, "stored twice")
+	writeTestBlob(t, two, "stored twice")
+	writeTestBlob(t, two, "stored once")
+
+	blobStores := BlobStoreMap{
+		"one": BlobStoreInitialized{BlobStore: one},
+		"two": BlobStoreInitialized{BlobStore: two},
+	}
+
+	duplicates, err := FindDuplicates(blobStores)
+	if err != nil {
+		t.Fatalf("FindDuplicates: %v", err)
+	}
+
+	if len(duplicates) != 1 || duplicates[0].BlobId.String() != id.String() {
+		t.Fatalf("expected one duplicate, got %v", duplicates)
+	}
+
+	ctx := errors.MakeContextDefault()
+
+	results := Dedup(ctx, blobStores, duplicates[0])
+
+	if len(results) != 1 || results[0].Err != nil ||
+		results[0].StoreId != "two" || results[0].KeptStoreId != "one" ||
+		results[0].Size != int64(len("stored twice")) {
+		t.Fatalf("expected two's copy to be relinked to one's, got %v", results)
+	}
+
+	if !os.SameFile(statTestBlob(t, one, id), statTestBlob(t, two, id)) {
+		t.Error("expected both stores to share the blob's file")
+	}
+
+	results = Dedup(ctx, blobStores, duplicates[0])
+
+	if len(results) != 1 || results[0].Err != nil || results[0].Size != 0 {
+		t.Errorf("expected relinking again to do nothing, got %v", results)
+	}
+}
+
+func TestBlobWriterKeepsExistingBlob(t *testing.T) {
+	src := makeTestLocalHashBucketed(t)
+	dst := makeTestLocalHashBucketed(t)
+
+	id := writeTestBlob(t, src, "shared")
+
+	if _, err := dst.LinkBlobFrom(src, id); err != nil {
+		t.Fatalf("LinkBlobFrom: %v", err)
+	}
+
+	writeTestBlob(t, dst, "shared")
+
+	if !os.SameFile(statTestBlob(t, src, id), statTestBlob(t, dst, id)) {
+		t.Error("expected rewriting an existing blob to keep its hard link")
+	}
+}
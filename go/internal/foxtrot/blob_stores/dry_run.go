@@ -0,0 +1,156 @@
+package blob_stores
+
+import (
+	"io"
+	"path/filepath"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+const dryRunDirName = "dry-run"
+
+// makeDryRunStore returns the local store that takes the blob writes of the
+// store at basePath under the global -dry-run, or nil outside of one. It
+// lives in the temp dir, which is removed on exit, so a dry run can read back
+// the blobs it wrote without leaving them in the store.
+func makeDryRunStore(
+	envDir env_dir.Env,
+	basePath string,
+	config blob_store_configs.ConfigLocalHashBucketed,
+) (store *localHashBucketed, err error) {
+	if !envDir.IsDryRun() {
+		return store, err
+	}
+
+	store = &localHashBucketed{
+		config:    config,
+		multiHash: config.SupportsMultiHash(),
+		buckets:   config.GetHashBuckets(),
+		basePath: filepath.Join(
+			envDir.GetTempLocal().BasePath,
+			dryRunDirName,
+			basePath,
+		),
+		tempFS: envDir.GetTempLocal(),
+	}
+
+	if store.defaultHashFormat, err = markl.GetFormatHashOrError(
+		config.GetDefaultHashTypeId(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return store, err
+	}
+
+	return store, err
+}
+
+// makeDryRunStoreForRemote is makeDryRunStore for stores whose config does
+// not describe a local layout: their dry-run blobs are kept uncompressed in
+// the default hash type.
+func makeDryRunStoreForRemote(
+	envDir env_dir.Env,
+	basePath string,
+) (store *localHashBucketed, err error) {
+	return makeDryRunStore(
+		envDir,
+		basePath,
+		blob_store_configs.TomlV2{
+			HashBuckets: blob_store_configs.DefaultHashBuckets,
+			HashTypeId: blob_store_configs.HashType(
+				blob_store_configs.DefaultHashTypeId,
+			),
+		},
+	)
+}
+
+// hasDryRunBlob is true when id was written to the store during this dry
+// run.
+func hasDryRunBlob(
+	dryRun *localHashBucketed,
+	id domain_interfaces.MarklId,
+) bool {
+	if dryRun == nil {
+		return false
+	}
+
+	return files.Exists(
+		env_dir.MakeHashBucketPathFromMerkleId(
+			id,
+			dryRun.buckets,
+			dryRun.multiHash,
+			dryRun.basePath,
+		),
+	)
+}
+
+// copyToDryRunStore writes the blob opened by open to dryRun, and fails
+// unless it digests to expected.
+func copyToDryRunStore(
+	dryRun *localHashBucketed,
+	expected domain_interfaces.MarklId,
+	open func() (domain_interfaces.BlobReader, error),
+) (size int64, err error) {
+	var reader domain_interfaces.BlobReader
+
+	if reader, err = open(); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	var hashFormat markl.FormatHash
+
+	if hashFormat, err = markl.GetFormatHashOrError(
+		expected.GetMarklFormat().GetMarklFormatId(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	var writer domain_interfaces.BlobWriter
+
+	if writer, err = dryRun.blobWriterTo(
+		dryRun.basePath,
+		hashFormat,
+	); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	if size, err = io.Copy(writer, reader); err != nil {
+		writer.Close()
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	if err = writer.Close(); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	if err = markl.AssertEqual(expected, writer.GetMarklId()); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	return size, err
+}
+
+// errIfDryRun refuses, during a dry run, changes to the store at path that
+// cannot be simulated without writing to it, like packing archives.
+func errIfDryRun(dryRun bool, path string) (err error) {
+	if dryRun {
+		err = errors.BadRequestf(
+			"blob store %s cannot be changed during a dry run",
+			path,
+		)
+	}
+
+	return err
+}
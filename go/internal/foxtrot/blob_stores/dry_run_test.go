@@ -0,0 +1,123 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func makeTestDryRunStore(
+	t *testing.T,
+	store localHashBucketed,
+) localHashBucketed {
+	dryRun := store
+	dryRun.basePath = t.TempDir()
+	store.dryRun = &dryRun
+
+	return store
+}
+
+func TestDryRunLocalWritesAside(t *testing.T) {
+	store := makeTestLocalHashBucketed(t)
+	existing := writeTestBlob(t, store, "written before the dry run")
+
+	store = makeTestDryRunStore(t, store)
+	id := writeTestBlob(t, store, "written during the dry run")
+
+	for blobId, err := range store.AllBlobs() {
+		if err != nil {
+			t.Fatalf("AllBlobs: %v", err)
+		}
+
+		if markl.Equals(blobId, id) {
+			t.Fatal("expected the dry run blob to stay out of the store")
+		}
+	}
+
+	if !store.HasBlob(id) {
+		t.Fatal("expected the dry run blob to be found")
+	}
+
+	reader, err := store.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	actual, err := io.ReadAll(reader)
+	reader.Close()
+
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if string(actual) != "written during the dry run" {
+		t.Errorf("expected the dry run blob, got %q", actual)
+	}
+
+	if err := store.DeleteBlob(existing); err != nil {
+		t.Fatalf("DeleteBlob: %v", err)
+	}
+
+	if !store.HasBlob(existing) {
+		t.Error("expected the delete to be skipped")
+	}
+
+	if _, err := store.LinkBlobFrom(
+		makeTestLocalHashBucketed(t),
+		existing,
+	); err == nil {
+		t.Error("expected linking to fail during a dry run")
+	}
+}
+
+func TestDryRunArchiveRefusesPackAndSkipsCacheWrites(t *testing.T) {
+	packed, loose, ids := packTestBlobsV1(
+		t,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+		"archived before the dry run",
+	)
+
+	store := makeTestStoreV1ForHash(
+		packed.basePath,
+		t.TempDir(),
+		markl.FormatHashSha256,
+		loose,
+	)
+
+	store.dryRun = true
+
+	if err := store.loadIndex(); err != nil {
+		t.Fatalf("loadIndex: %v", err)
+	}
+
+	if entries, err := os.ReadDir(store.cachePath); err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	} else if len(entries) != 0 {
+		t.Errorf("expected no cache to be written, got %d files", len(entries))
+	}
+
+	if !store.HasBlob(ids[0]) {
+		t.Fatalf("expected %s to be readable from the archive", ids[0])
+	}
+
+	if err := store.Pack(PackOptions{}); err == nil {
+		t.Error("expected Pack to be refused during a dry run")
+	}
+
+	if err := store.Unpack(ids[0]); err == nil {
+		t.Error("expected Unpack to be refused during a dry run")
+	}
+
+	if loose.HasBlob(ids[0]) {
+		t.Errorf("expected %s to stay archived only", ids[0])
+	}
+}
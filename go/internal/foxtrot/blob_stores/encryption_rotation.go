@@ -0,0 +1,358 @@
+package blob_stores
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// BlobEncryptionRotator is implemented by stores that can re-encrypt their
+// blobs with the current keys of their blob_store_configs.EncryptionKeyRing.
+type BlobEncryptionRotator interface {
+	// RotateBlobEncryption re-encrypts id with the current keys if one of the
+	// retired keys can decrypt it, and reports whether it had to. Blobs that
+	// are rotated already are only read up to their header, so that running
+	// a rotation again resumes it.
+	RotateBlobEncryption(id domain_interfaces.MarklId) (rotated bool, err error)
+}
+
+var (
+	_ BlobEncryptionRotator = localHashBucketed{}
+	_ BlobEncryptionRotator = lazyParent{}
+	_ BlobEncryptionRotator = encrypted{}
+)
+
+// retiredKeysIOWrapper is the IOWrapper of a key ring with retired keys,
+// which decrypts with every key of the ring but encrypts with the current
+// ones only.
+type retiredKeysIOWrapper interface {
+	interfaces.IOWrapper
+	IsEncryptedToRetired(io.Reader) (bool, error)
+}
+
+// getRetiredKeysIOWrapper returns the IOWrapper of the key ring of config,
+// or false if config has no retired keys.
+func getRetiredKeysIOWrapper(
+	config domain_interfaces.BlobIOWrapper,
+) (ioWrapper retiredKeysIOWrapper, ok bool, err error) {
+	ring, ok := config.GetBlobEncryption().(blob_store_configs.EncryptionKeyRing)
+
+	if !ok || !ring.HasRetired() {
+		return ioWrapper, false, err
+	}
+
+	var wrapper interfaces.IOWrapper
+
+	if wrapper, err = ring.GetIOWrapper(); err != nil {
+		err = errors.Wrap(err)
+		return ioWrapper, false, err
+	}
+
+	if ioWrapper, ok = wrapper.(retiredKeysIOWrapper); !ok {
+		err = errors.Errorf("expected retired keys, got %T", wrapper)
+		return ioWrapper, false, err
+	}
+
+	return ioWrapper, ok, err
+}
+
+// reencrypt decrypts src and encrypts it again into dst. Only the encryption
+// changes: compressed blobs stay compressed as they were.
+func reencrypt(
+	ioWrapper interfaces.IOWrapper,
+	dst io.Writer,
+	src io.Reader,
+) (err error) {
+	var reader io.ReadCloser
+
+	if reader, err = ioWrapper.WrapReader(src); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	var writer io.WriteCloser
+
+	if writer, err = ioWrapper.WrapWriter(dst); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if _, err = io.Copy(writer, reader); err != nil {
+		writer.Close()
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// RotateBlobEncryption re-encrypts the stored bytes of id wherever the store
+// keeps them: its file, its chunked blob manifest (its chunks are blobs of
+// their own), or its entry in the small-blob archive. Files are replaced by
+// a rename, and archive entries by appending a new one, which leaves the old
+// bytes in the archive's data file.
+func (blobStore localHashBucketed) RotateBlobEncryption(
+	id domain_interfaces.MarklId,
+) (rotated bool, err error) {
+	if err = errIfReadOnly(blobStore.readOnly, blobStore.basePath); err != nil {
+		return rotated, err
+	}
+
+	var ioWrapper retiredKeysIOWrapper
+	var ok bool
+
+	if ioWrapper, ok, err = getRetiredKeysIOWrapper(
+		blobStore.config,
+	); err != nil || !ok || id.IsNull() {
+		return rotated, err
+	}
+
+	path := env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		blobStore.buckets,
+		blobStore.multiHash,
+		blobStore.basePath,
+	)
+
+	switch {
+	case files.Exists(path):
+
+	case files.Exists(path + chunkedBlobManifestSuffix):
+		path += chunkedBlobManifestSuffix
+
+	case blobStore.smallBlobs != nil && blobStore.smallBlobs.has(id):
+		return blobStore.rotateSmallBlobEncryption(ioWrapper, id)
+
+	default:
+		clonedId, _ := markl.Clone(id)
+		err = env_dir.ErrBlobMissing{BlobId: clonedId, Path: path}
+		return rotated, err
+	}
+
+	if rotated, err = blobStore.rotateFileEncryption(
+		ioWrapper,
+		path,
+	); err != nil {
+		err = errors.Wrapf(err, "rotating blob %s", id)
+		return rotated, err
+	}
+
+	return rotated, err
+}
+
+// rotateFileEncryption writes the re-encrypted file to a temporary file and
+// renames it over path, so that the blob is never missing or partial.
+func (blobStore localHashBucketed) rotateFileEncryption(
+	ioWrapper retiredKeysIOWrapper,
+	path string,
+) (rotated bool, err error) {
+	var file *os.File
+
+	if file, err = os.Open(path); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	if rotated, err = ioWrapper.IsEncryptedToRetired(file); err != nil ||
+		!rotated || blobStore.dryRun != nil {
+		return rotated, err
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	var temp *os.File
+
+	if temp, err = blobStore.tempFS.FileTemp(); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	if err = reencrypt(ioWrapper, temp, file); err == nil {
+		err = temp.Sync()
+	}
+
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(temp.Name())
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	return rotated, err
+}
+
+func (blobStore localHashBucketed) rotateSmallBlobEncryption(
+	ioWrapper retiredKeysIOWrapper,
+	id domain_interfaces.MarklId,
+) (rotated bool, err error) {
+	var entry smallBlobEntry
+
+	if entry, _, err = blobStore.smallBlobs.lookup(id); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	var section *io.SectionReader
+
+	if section, err = blobStore.smallBlobs.openEntry(entry); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	if rotated, err = ioWrapper.IsEncryptedToRetired(section); err != nil ||
+		!rotated || blobStore.dryRun != nil {
+		return rotated, err
+	}
+
+	if _, err = section.Seek(0, io.SeekStart); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	var stored bytes.Buffer
+
+	if err = reencrypt(ioWrapper, &stored, section); err != nil {
+		err = errors.Wrapf(err, "rotating inlined blob %s", id)
+		return rotated, err
+	}
+
+	if blobStore.budget != nil {
+		if err = blobStore.budget.reserve(int64(stored.Len())); err != nil {
+			err = errors.Wrap(err)
+			return rotated, err
+		}
+	}
+
+	if err = blobStore.smallBlobs.replace(id, stored.Bytes()); err != nil {
+		err = errors.Wrapf(err, "rotating inlined blob %s", id)
+		return rotated, err
+	}
+
+	return rotated, err
+}
+
+func (blobStore lazyParent) RotateBlobEncryption(
+	id domain_interfaces.MarklId,
+) (rotated bool, err error) {
+	return blobStore.local.RotateBlobEncryption(id)
+}
+
+// RotateBlobEncryption writes id to the store again, which encrypts it with
+// the current keys and records its new ciphertext in the index, and deletes
+// the old ciphertext from the inner store when it supports deleting blobs.
+func (blobStore encrypted) RotateBlobEncryption(
+	id domain_interfaces.MarklId,
+) (rotated bool, err error) {
+	var ioWrapper retiredKeysIOWrapper
+	var ok bool
+
+	if ioWrapper, ok, err = getRetiredKeysIOWrapper(
+		blobStore.config,
+	); err != nil || !ok || id.IsNull() {
+		return rotated, err
+	}
+
+	ciphertextId, ok := blobStore.index.get(id)
+
+	if !ok {
+		clonedId, _ := markl.Clone(id)
+		err = env_dir.ErrBlobMissing{BlobId: clonedId}
+		return rotated, err
+	}
+
+	{
+		var innerReader domain_interfaces.BlobReader
+
+		if innerReader, err = blobStore.inner.MakeBlobReader(
+			ciphertextId,
+		); err != nil {
+			err = errors.Wrap(err)
+			return rotated, err
+		}
+
+		rotated, err = ioWrapper.IsEncryptedToRetired(innerReader)
+		innerReader.Close()
+
+		if err != nil || !rotated {
+			err = errors.Wrap(err)
+			return rotated, err
+		}
+	}
+
+	var hashFormat markl.FormatHash
+
+	if hashFormat, err = markl.GetFormatHashOrError(
+		id.GetMarklFormat().GetMarklFormatId(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	var reader domain_interfaces.BlobReader
+
+	if reader, err = blobStore.MakeBlobReader(id); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	var writer domain_interfaces.BlobWriter
+
+	if writer, err = blobStore.makeBlobWriter(hashFormat, true); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	if _, err = io.Copy(writer, reader); err != nil {
+		writer.Close()
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	if err = writer.Close(); err != nil {
+		err = errors.Wrap(err)
+		return rotated, err
+	}
+
+	if err = markl.AssertEqual(id, writer.GetMarklId()); err != nil {
+		err = errors.Wrapf(err, "rotating blob %s", id)
+		return rotated, err
+	}
+
+	if deleter, ok := blobStore.inner.(BlobDeleter); ok {
+		if err = deleter.DeleteBlob(ciphertextId); err != nil {
+			err = errors.Wrapf(err, "deleting the old ciphertext of %s", id)
+			return rotated, err
+		}
+	}
+
+	return rotated, err
+}
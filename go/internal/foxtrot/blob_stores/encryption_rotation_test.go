@@ -0,0 +1,155 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+)
+
+func makeTestEncryptionKey(t *testing.T) markl.Id {
+	var key markl.Id
+
+	if err := key.GeneratePrivateKey(
+		nil,
+		markl.FormatIdAgeX25519Sec,
+		markl.PurposeMadderPrivateKeyV1,
+	); err != nil {
+		t.Fatalf("GeneratePrivateKey: %v", err)
+	}
+
+	return key
+}
+
+func writeTestRotatedBlob(
+	t *testing.T,
+	store domain_interfaces.BlobStore,
+	data []byte,
+) domain_interfaces.MarklId {
+	writer, err := store.MakeBlobWriter(nil)
+	if err != nil {
+		t.Fatalf("MakeBlobWriter: %v", err)
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	id, _ := markl.Clone(writer.GetMarklId())
+
+	return id
+}
+
+func assertTestRotatedBlob(
+	t *testing.T,
+	store domain_interfaces.BlobStore,
+	id domain_interfaces.MarklId,
+	expected []byte,
+) {
+	t.Helper()
+
+	reader, err := store.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	defer reader.Close()
+
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("expected %q but got %q", expected, actual)
+	}
+}
+
+func assertTestRotation(
+	t *testing.T,
+	rotator BlobEncryptionRotator,
+	id domain_interfaces.MarklId,
+	expected bool,
+) {
+	t.Helper()
+
+	rotated, err := rotator.RotateBlobEncryption(id)
+	if err != nil {
+		t.Fatalf("RotateBlobEncryption: %v", err)
+	}
+
+	if rotated != expected {
+		t.Errorf("expected rotated to be %t", expected)
+	}
+}
+
+func TestLocalRotatesBlobEncryption(t *testing.T) {
+	oldKey, newKey := makeTestEncryptionKey(t), makeTestEncryptionKey(t)
+
+	store := makeTestLocalHashBucketed(t)
+	config := store.config.(*blob_store_configs.TomlLazyParentV0)
+	config.Encryption = []markl.Id{oldKey}
+
+	testData := []byte("encrypted with the old key")
+	id := writeTestRotatedBlob(t, store, testData)
+
+	config.SetEncryptionKeyRing(config.GetEncryptionKeyRing().Rotate(
+		[]markl.Id{newKey},
+	))
+
+	// readable with the retired key while rotating
+	assertTestRotatedBlob(t, store, id, testData)
+
+	assertTestRotation(t, store, id, true)
+	assertTestRotation(t, store, id, false)
+
+	config.RetiredEncryption = nil
+
+	assertTestRotatedBlob(t, store, id, testData)
+}
+
+func TestEncryptedRotatesBlobEncryption(t *testing.T) {
+	oldKey, newKey := makeTestEncryptionKey(t), makeTestEncryptionKey(t)
+
+	basePath := t.TempDir()
+	inner := makeTestLocalHashBucketed(t)
+	store := makeTestEncrypted(t, basePath, inner, oldKey, false)
+
+	testData := []byte("encrypted with the old key")
+	id := writeTestRotatedBlob(t, store, testData)
+
+	config := store.config.(*blob_store_configs.TomlEncryptedV0)
+	config.SetEncryptionKeyRing(config.GetEncryptionKeyRing().Rotate(
+		[]markl.Id{newKey},
+	))
+
+	assertTestRotation(t, store, id, true)
+	assertTestRotation(t, store, id, false)
+
+	count := 0
+
+	for _, err := range inner.AllBlobs() {
+		if err != nil {
+			t.Fatalf("AllBlobs: %v", err)
+		}
+
+		count++
+	}
+
+	if count != 1 {
+		t.Errorf("expected the old ciphertext to be deleted, got %d blobs", count)
+	}
+
+	config.RetiredEncryption = nil
+
+	assertTestRotatedBlob(t, makeTestEncrypted(t, basePath, inner, newKey, false), id, testData)
+}
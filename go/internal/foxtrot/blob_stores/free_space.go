@@ -0,0 +1,122 @@
+package blob_stores
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// archiveEntryEstimateBytes over-estimates what one entry adds to an
+// archive's data and index files, and to its store's cache, on top of its
+// payload.
+const archiveEntryEstimateBytes = 128
+
+// FreeSpaceChecker is implemented by stores that write blobs to a local
+// filesystem, and can tell up front whether bytes more fit on it.
+type FreeSpaceChecker interface {
+	CheckFreeSpace(requiredBytes uint64) error
+}
+
+var (
+	_ FreeSpaceChecker = localHashBucketed{}
+	_ FreeSpaceChecker = lazyParent{}
+	_ FreeSpaceChecker = encrypted{}
+	_ FreeSpaceChecker = inventoryArchiveV0{}
+	_ FreeSpaceChecker = inventoryArchiveV1{}
+)
+
+// CheckFreeSpace fails with files.ErrInsufficientSpace when blobStore is a
+// FreeSpaceChecker without room for requiredBytes of new blobs. Other stores,
+// like remote ones, are not checked.
+func CheckFreeSpace(
+	blobStore domain_interfaces.BlobStore,
+	requiredBytes uint64,
+) (err error) {
+	if initialized, ok := blobStore.(BlobStoreInitialized); ok {
+		blobStore = initialized.BlobStore
+	}
+
+	checker, ok := blobStore.(FreeSpaceChecker)
+	if !ok {
+		return err
+	}
+
+	if err = checker.CheckFreeSpace(requiredBytes); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (blobStore localHashBucketed) CheckFreeSpace(
+	requiredBytes uint64,
+) (err error) {
+	basePath := blobStore.basePath
+
+	if blobStore.dryRun != nil {
+		basePath = blobStore.dryRun.basePath
+	}
+
+	return files.CheckAvailableBytes(basePath, requiredBytes)
+}
+
+func (blobStore lazyParent) CheckFreeSpace(requiredBytes uint64) (err error) {
+	return blobStore.local.CheckFreeSpace(requiredBytes)
+}
+
+func (blobStore encrypted) CheckFreeSpace(requiredBytes uint64) (err error) {
+	return CheckFreeSpace(blobStore.inner, requiredBytes)
+}
+
+// CheckFreeSpace checks the loose store, which takes an archive store's
+// writes.
+func (store inventoryArchiveV0) CheckFreeSpace(
+	requiredBytes uint64,
+) (err error) {
+	return CheckFreeSpace(store.looseBlobStore, requiredBytes)
+}
+
+func (store inventoryArchiveV1) CheckFreeSpace(
+	requiredBytes uint64,
+) (err error) {
+	return CheckFreeSpace(store.looseBlobStore, requiredBytes)
+}
+
+// checkFreeSpaceForArchives fails with files.ErrInsufficientSpace unless
+// basePath has room for archives of payloadBytes over entryCount entries,
+// and cachePath for a cache of cacheEntryCount entries. Packing checks it
+// before writing the first archive, as the loose blobs stay until all of
+// them are written.
+func checkFreeSpaceForArchives(
+	basePath string,
+	cachePath string,
+	payloadBytes uint64,
+	entryCount int,
+	cacheEntryCount int,
+) (err error) {
+	archiveBytes := payloadBytes + uint64(entryCount)*archiveEntryEstimateBytes
+	cacheBytes := uint64(cacheEntryCount) * archiveEntryEstimateBytes
+
+	if err = files.CheckAvailableBytes(basePath, archiveBytes); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = files.CheckAvailableBytes(cachePath, cacheBytes); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// packedPayloadBytes is the uncompressed size of metas, an upper bound on
+// the size of their archive entries unless they are incompressible.
+func packedPayloadBytes(metas []packedBlobMeta) (bytes uint64) {
+	for _, meta := range metas {
+		bytes += meta.size
+	}
+
+	return bytes
+}
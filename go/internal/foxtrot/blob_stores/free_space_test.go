@@ -0,0 +1,53 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"math"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+func TestCheckFreeSpaceLocal(t *testing.T) {
+	store := makeTestLocalHashBucketed(t)
+
+	if err := CheckFreeSpace(store, 1); err != nil {
+		t.Fatalf("CheckFreeSpace: %v", err)
+	}
+
+	err := CheckFreeSpace(makeLazyParent(store, nil), math.MaxUint64)
+
+	if !files.IsErrInsufficientSpace(err) {
+		t.Fatalf("expected ErrInsufficientSpace, got %v", err)
+	}
+
+	if code := errors.GetCode(err); code != errors.CodeInsufficientSpace {
+		t.Errorf("expected code %s, got %s", errors.CodeInsufficientSpace, code)
+	}
+}
+
+func TestCheckFreeSpaceForArchives(t *testing.T) {
+	basePath := t.TempDir()
+
+	if err := checkFreeSpaceForArchives(
+		basePath,
+		t.TempDir(),
+		1,
+		1,
+		1,
+	); err != nil {
+		t.Fatalf("checkFreeSpaceForArchives: %v", err)
+	}
+
+	if err := checkFreeSpaceForArchives(
+		basePath,
+		t.TempDir(),
+		math.MaxUint64-archiveEntryEstimateBytes,
+		0,
+		0,
+	); !files.IsErrInsufficientSpace(err) {
+		t.Fatalf("expected ErrInsufficientSpace, got %v", err)
+	}
+}
@@ -0,0 +1,316 @@
+package blob_stores
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// HealthCheck is the outcome of one check a blob store ran on itself. Err is
+// nil when the check passed.
+type HealthCheck struct {
+	Description string
+	Err         error
+}
+
+// HealthChecker is implemented by blob stores that can verify their
+// configuration and backing storage without reading every blob, so that a
+// misconfigured store is reported up front rather than partway through a
+// command.
+type HealthChecker interface {
+	HealthCheck() []HealthCheck
+}
+
+var (
+	_ HealthChecker = localHashBucketed{}
+	_ HealthChecker = lazyParent{}
+	_ HealthChecker = encrypted{}
+	_ HealthChecker = &remoteSftp{}
+	_ HealthChecker = inventoryArchiveV0{}
+	_ HealthChecker = inventoryArchiveV1{}
+)
+
+// CheckHealth runs a store's health checks. ok is false when the store does
+// not implement HealthChecker.
+func CheckHealth(
+	blobStore domain_interfaces.BlobStore,
+) (checks []HealthCheck, ok bool) {
+	checker, ok := blobStore.(HealthChecker)
+	if !ok {
+		return checks, ok
+	}
+
+	return checker.HealthCheck(), ok
+}
+
+// checkChildHealth runs the checks of a store that another store wraps or
+// falls back to, prefixing their descriptions with the child's role.
+func checkChildHealth(
+	role string,
+	child domain_interfaces.BlobStore,
+) (checks []HealthCheck) {
+	childChecks, ok := CheckHealth(child)
+	if !ok {
+		return checks
+	}
+
+	for _, check := range childChecks {
+		check.Description = fmt.Sprintf("%s: %s", role, check.Description)
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// W_OK from access(2), which the syscall package does not export.
+const accessWritable = 0x2
+
+func checkBasePath(basePath string) (check HealthCheck) {
+	check.Description = fmt.Sprintf("base path %s", basePath)
+
+	info, err := os.Stat(basePath)
+	if err != nil {
+		check.Err = errors.Wrap(err)
+		return check
+	}
+
+	if !info.IsDir() {
+		check.Err = errors.Errorf("%s is not a directory", basePath)
+		return check
+	}
+
+	if err = syscall.Access(basePath, accessWritable); err != nil {
+		check.Err = errors.Wrapf(err, "%s is not writable", basePath)
+		return check
+	}
+
+	return check
+}
+
+func (blobStore localHashBucketed) HealthCheck() []HealthCheck {
+	return []HealthCheck{checkBasePath(blobStore.basePath)}
+}
+
+func (blobStore lazyParent) HealthCheck() []HealthCheck {
+	return append(
+		blobStore.local.HealthCheck(),
+		checkChildHealth("parent", blobStore.parent)...,
+	)
+}
+
+func (store encrypted) HealthCheck() []HealthCheck {
+	return append(
+		[]HealthCheck{checkBasePath(filepath.Dir(store.index.path))},
+		checkChildHealth("inner", store.inner)...,
+	)
+}
+
+// HealthCheck connects to the remote and stats its base path. Unlike the
+// lazy initialization used by reads and writes, a failure is reported
+// rather than cancelling the context.
+func (blobStore *remoteSftp) HealthCheck() (checks []HealthCheck) {
+	connect := HealthCheck{Description: "connect"}
+
+	blobStore.once.Do(func() {
+		connect.Err = blobStore.initialize()
+	})
+
+	if connect.Err == nil && blobStore.sftpClient == nil {
+		connect.Err = errors.Errorf("an earlier connection attempt failed")
+	}
+
+	checks = append(checks, connect)
+
+	if connect.Err != nil {
+		return checks
+	}
+
+	remotePath := blobStore.config.GetRemotePath()
+	ping := HealthCheck{Description: fmt.Sprintf("remote path %s", remotePath)}
+
+	if _, err := blobStore.sftpClient.Stat(remotePath); err != nil {
+		ping.Err = errors.Wrap(err)
+	}
+
+	return append(checks, ping)
+}
+
+// pickArchiveDataFile returns one of the data files matching the given
+// extensions at random, or an empty path if there are none.
+func pickArchiveDataFile(
+	archivesPath string,
+	extensions ...string,
+) (dataPath string, err error) {
+	var matches []string
+
+	for _, extension := range extensions {
+		var extensionMatches []string
+
+		if extensionMatches, err = filepath.Glob(
+			filepath.Join(archivesPath, "*"+extension),
+		); err != nil {
+			err = errors.Wrapf(err, "globbing archive data files")
+			return dataPath, err
+		}
+
+		matches = append(matches, extensionMatches...)
+	}
+
+	if len(matches) == 0 {
+		return dataPath, err
+	}
+
+	return matches[rand.IntN(len(matches))], err
+}
+
+func (store inventoryArchiveV0) HealthCheck() (checks []HealthCheck) {
+	checks = append(checks, checkBasePath(store.basePath))
+
+	header := HealthCheck{Description: "read archive header"}
+
+	if dataPath, err := pickArchiveDataFile(
+		store.archivesPath(),
+		inventory_archive.DataFileExtension,
+	); err != nil {
+		header.Err = err
+	} else if dataPath != "" {
+		header.Description = fmt.Sprintf("read archive header %s", dataPath)
+		header.Err = readArchiveHeader(dataPath, func(file *os.File) (err error) {
+			_, err = inventory_archive.NewDataReader(file, store.encryption)
+			return err
+		})
+	}
+
+	checks = append(checks, header)
+	checks = append(checks, store.checkCacheFreshness())
+
+	return append(checks, checkChildHealth("loose", store.looseBlobStore)...)
+}
+
+// checkCacheFreshness fails if an index file was written after the cache, as
+// the cache is then missing that archive's blobs.
+func (store inventoryArchiveV0) checkCacheFreshness() (check HealthCheck) {
+	check.Description = "cache freshness"
+
+	indexPaths, err := filepath.Glob(filepath.Join(
+		store.archivesPath(),
+		"*"+inventory_archive.IndexFileExtension,
+	))
+	if err != nil || len(indexPaths) == 0 {
+		check.Err = err
+		return check
+	}
+
+	cachePath := filepath.Join(store.cachePath, inventory_archive.CacheFileName)
+
+	cacheInfo, err := os.Stat(cachePath)
+	if err != nil {
+		check.Err = errors.Wrapf(err, "archives exist but the cache does not")
+		return check
+	}
+
+	for _, indexPath := range indexPaths {
+		indexInfo, err := os.Stat(indexPath)
+		if err != nil {
+			check.Err = errors.Wrap(err)
+			return check
+		}
+
+		if indexInfo.ModTime().After(cacheInfo.ModTime()) {
+			check.Err = errors.Errorf(
+				"index %s is newer than the cache %s",
+				indexPath,
+				cachePath,
+			)
+
+			return check
+		}
+	}
+
+	return check
+}
+
+func (store inventoryArchiveV1) HealthCheck() (checks []HealthCheck) {
+	checks = append(checks, checkBasePath(store.basePath))
+
+	header := HealthCheck{Description: "read archive header"}
+
+	if dataPath, err := pickArchiveDataFile(
+		store.archivesPath(),
+		inventory_archive.DataFileExtensionV1,
+		inventory_archive.DataFileExtensionV2,
+	); err != nil {
+		header.Err = err
+	} else if dataPath != "" {
+		header.Description = fmt.Sprintf("read archive header %s", dataPath)
+		header.Err = readArchiveHeader(dataPath, func(file *os.File) (err error) {
+			_, err = inventory_archive.NewDataReaderV1(file, store.encryption)
+			return err
+		})
+	}
+
+	checks = append(checks, header)
+	checks = append(checks, store.checkCacheFreshness())
+
+	return append(checks, checkChildHealth("loose", store.looseBlobStore)...)
+}
+
+// checkCacheFreshness fails if another process committed a manifest
+// generation since the index was loaded, or if there is no cache for the
+// loaded generation.
+func (store inventoryArchiveV1) checkCacheFreshness() (check HealthCheck) {
+	check.Description = "cache freshness"
+
+	manifest, _, err := store.readManifest()
+	if err != nil {
+		check.Err = err
+		return check
+	}
+
+	if generation := store.generation.get(); manifest.Generation != generation {
+		check.Err = errors.Errorf(
+			"index is at generation %d but the manifest is at %d",
+			generation,
+			manifest.Generation,
+		)
+
+		return check
+	}
+
+	if len(store.index) == 0 {
+		return check
+	}
+
+	if _, err = os.Stat(store.cacheFilePath()); err != nil {
+		check.Err = errors.Wrapf(err, "archives exist but the cache does not")
+		return check
+	}
+
+	return check
+}
+
+func readArchiveHeader(
+	dataPath string,
+	read func(*os.File) error,
+) (err error) {
+	file, err := os.Open(dataPath)
+	if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	if err = read(file); err != nil {
+		err = errors.Wrapf(err, "reading header of %s", dataPath)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,103 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func failedHealthChecks(checks []HealthCheck) (failed []HealthCheck) {
+	for _, check := range checks {
+		if check.Err != nil {
+			failed = append(failed, check)
+		}
+	}
+
+	return failed
+}
+
+func TestInventoryArchiveV1HealthCheck(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+
+	data := []byte("healthy blob")
+	rawHash := sha256.Sum256(data)
+	id, repool := hashFormat.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	defer repool()
+
+	store := inventoryArchiveV1{
+		defaultHash: hashFormat,
+		basePath:    t.TempDir(),
+		cachePath:   t.TempDir(),
+		looseBlobStore: &stubBlobStore{
+			allBlobIds: []domain_interfaces.MarklId{id},
+			blobData:   map[string][]byte{id.String(): data},
+		},
+		index: make(map[string]archiveEntryV1),
+		config: blob_store_configs.TomlInventoryArchiveV2{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+			FooterIndex:     true,
+		},
+		generation: &archiveGeneration{},
+	}
+
+	if err := store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	checks, ok := CheckHealth(store)
+	if !ok {
+		t.Fatal("expected the store to implement HealthChecker")
+	}
+
+	if failed := failedHealthChecks(checks); len(failed) != 0 {
+		t.Fatalf("expected a healthy store, got %v", failed)
+	}
+
+	// a stale cache is reported
+	if err := os.Remove(store.cacheFilePath()); err != nil {
+		t.Fatalf("removing cache: %v", err)
+	}
+
+	if failed := failedHealthChecks(store.HealthCheck()); len(failed) != 1 ||
+		failed[0].Description != "cache freshness" {
+		t.Errorf("expected the cache freshness check to fail, got %v", failed)
+	}
+
+	// so is a corrupt archive header
+	dataPaths, err := filepath.Glob(filepath.Join(
+		store.archivesPath(),
+		"*"+inventory_archive.DataFileExtensionV2,
+	))
+	if err != nil || len(dataPaths) != 1 {
+		t.Fatalf("expected a single archive, got %v (%v)", dataPaths, err)
+	}
+
+	if err := os.WriteFile(dataPaths[0], []byte("not an archive"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if failed := failedHealthChecks(store.HealthCheck()); len(failed) != 2 {
+		t.Errorf("expected the header check to fail too, got %v", failed)
+	}
+
+	// and a missing base path
+	store.basePath = filepath.Join(t.TempDir(), "missing")
+
+	if failed := failedHealthChecks(store.HealthCheck()); len(failed) == 0 ||
+		failed[0].Description != "base path "+store.basePath {
+		t.Errorf("expected the base path check to fail, got %v", failed)
+	}
+}
@@ -76,6 +76,8 @@ func MakeBlobStores(
 	envDir env_dir.Env,
 	directoryLayout directory_layout.BlobStore,
 ) (blobStores BlobStoreMap) {
+	writeEnvMetricsAfter(ctx)
+
 	// based on explicit xdg (that is, may include override)
 	blobStores = makeBlobStoreConfigs(ctx, directoryLayout)
 
@@ -188,10 +190,23 @@ func MakeBlobStore(
 
 	switch config := configBlob.(type) {
 	case blob_store_configs.ConfigSFTPUri:
+		var dryRun *localHashBucketed
+
+		if dryRun, err = makeDryRunStoreForRemote(
+			envDir,
+			configNamed.Path.GetBase(),
+		); err != nil {
+			err = errors.Wrap(err)
+			return store, err
+		}
+
 		return makeSftpStore(
 			envDir.GetActiveContext(),
 			printer,
+			configNamed.Path.GetBase(),
 			config,
+			isReadOnly(envDir, config),
+			dryRun,
 			func() (*ssh.Client, error) {
 				return MakeSSHClientFromSSHConfig(
 					envDir.GetActiveContext(),
@@ -202,10 +217,23 @@ func MakeBlobStore(
 		)
 
 	case blob_store_configs.ConfigSFTPConfigExplicit:
+		var dryRun *localHashBucketed
+
+		if dryRun, err = makeDryRunStoreForRemote(
+			envDir,
+			configNamed.Path.GetBase(),
+		); err != nil {
+			err = errors.Wrap(err)
+			return store, err
+		}
+
 		return makeSftpStore(
 			envDir.GetActiveContext(),
 			printer,
+			configNamed.Path.GetBase(),
 			config,
+			isReadOnly(envDir, config),
+			dryRun,
 			func() (*ssh.Client, error) {
 				return MakeSSHClientForExplicitConfig(
 					envDir.GetActiveContext(),
@@ -215,6 +243,73 @@ func MakeBlobStore(
 			},
 		)
 
+	// must precede ConfigLocalHashBucketed, which it also satisfies
+	case blob_store_configs.ConfigLazyParent:
+		var localStore localHashBucketed
+
+		if localStore, err = makeLocalHashBucketed(
+			envDir,
+			configNamed.Path.GetBase(),
+			config,
+		); err != nil {
+			err = errors.Wrap(err)
+			return store, err
+		}
+
+		parentPath := config.GetParentPath()
+		parentConfigNamed := blob_store_configs.ConfigNamed{Path: parentPath}
+
+		if parentConfigNamed.Config, err = triple_hyphen_io.DecodeFromFile(
+			blob_store_configs.Coder,
+			parentPath.GetConfig(),
+		); err != nil {
+			err = errors.Wrap(err)
+			return store, err
+		}
+
+		var parentStore domain_interfaces.BlobStore
+
+		if parentStore, err = MakeBlobStore(
+			envDir,
+			parentConfigNamed,
+			blobStores,
+		); err != nil {
+			err = errors.Wrapf(err, "parent blob store: %q", parentPath.GetBase())
+			return store, err
+		}
+
+		return makeLazyParent(localStore, parentStore), nil
+
+	case blob_store_configs.ConfigEncrypted:
+		innerPath := config.GetInnerPath()
+		innerConfigNamed := blob_store_configs.ConfigNamed{Path: innerPath}
+
+		if innerConfigNamed.Config, err = triple_hyphen_io.DecodeFromFile(
+			blob_store_configs.Coder,
+			innerPath.GetConfig(),
+		); err != nil {
+			err = errors.Wrap(err)
+			return store, err
+		}
+
+		var innerStore domain_interfaces.BlobStore
+
+		if innerStore, err = MakeBlobStore(
+			envDir,
+			innerConfigNamed,
+			blobStores,
+		); err != nil {
+			err = errors.Wrapf(err, "inner blob store: %q", innerPath.GetBase())
+			return store, err
+		}
+
+		return makeEncrypted(
+			configNamed.Path.GetBase(),
+			config,
+			innerStore,
+			envDir.IsDryRun(),
+		)
+
 	case blob_store_configs.ConfigLocalHashBucketed:
 		return makeLocalHashBucketed(
 			envDir,
@@ -233,6 +328,9 @@ func MakeBlobStore(
 				HashBuckets:       blob_store_configs.DefaultHashBuckets,
 				CompressionType:   config.GetCompressionType(),
 				LockInternalFiles: true,
+				ReadOnlyConfig: blob_store_configs.ReadOnlyConfig{
+					ReadOnly: isReadOnly(envDir, config),
+				},
 			}
 
 			if looseBlobStore, err = makeLocalHashBucketed(
@@ -0,0 +1,245 @@
+package blob_stores
+
+import (
+	"io"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+)
+
+// BlobStoreObserver receives instrumentation events from every blob store.
+// store labels the store that emitted the event with its description and
+// path. Stores that wrap or fall back to another store report their own
+// events in addition to the other store's, so totals across stores count the
+// same bytes more than once.
+//
+// Implementations are called concurrently and on hot paths, and must not
+// block.
+type BlobStoreObserver interface {
+	// ObserveRead is called when a blob reader is closed, with the time since
+	// it was opened and the bytes read through it.
+	ObserveRead(store string, elapsed time.Duration, bytes int64)
+
+	// ObserveWrite is called when a blob writer is closed, with the time since
+	// it was opened and the bytes written through it.
+	ObserveWrite(store string, elapsed time.Duration, bytes int64)
+
+	// ObserveLookup is called for every HasBlob.
+	ObserveLookup(store string, elapsed time.Duration, found bool)
+
+	// ObserveCache is called when a store consults a cache: inventory
+	// archives when loading their index cache, SFTP stores when checking
+	// their cache of known remote blobs, and the repo store when reading an
+	// object from its object cache.
+	ObserveCache(store string, hit bool)
+}
+
+type nopBlobStoreObserver struct{}
+
+func (nopBlobStoreObserver) ObserveRead(string, time.Duration, int64)  {}
+func (nopBlobStoreObserver) ObserveWrite(string, time.Duration, int64) {}
+func (nopBlobStoreObserver) ObserveLookup(string, time.Duration, bool) {}
+func (nopBlobStoreObserver) ObserveCache(string, bool)                 {}
+
+// atomic.Value requires every stored value to have the same concrete type
+type blobStoreObserverBox struct {
+	observer BlobStoreObserver
+}
+
+var blobStoreObserver atomic.Value
+
+func init() {
+	blobStoreObserver.Store(blobStoreObserverBox{nopBlobStoreObserver{}})
+}
+
+// SetBlobStoreObserver installs the observer that all blob stores report to.
+// A nil observer restores the default, which discards every event.
+func SetBlobStoreObserver(observer BlobStoreObserver) {
+	if observer == nil {
+		observer = nopBlobStoreObserver{}
+	}
+
+	blobStoreObserver.Store(blobStoreObserverBox{observer})
+}
+
+// getBlobStoreObserver returns the installed observer, and false when it is
+// the default, so callers can skip computing labels and wrapping streams.
+func getBlobStoreObserver() (observer BlobStoreObserver, ok bool) {
+	observer = blobStoreObserver.Load().(blobStoreObserverBox).observer
+	_, isNop := observer.(nopBlobStoreObserver)
+	return observer, !isNop
+}
+
+// observedBlobStore is implemented by every store that reports to the
+// BlobStoreObserver.
+type observedBlobStore interface {
+	observerLabel() string
+}
+
+func (blobStore localHashBucketed) observerLabel() string {
+	return blobStore.GetBlobStoreDescription() + " " + blobStore.basePath
+}
+
+func (blobStore lazyParent) observerLabel() string {
+	return blobStore.GetBlobStoreDescription() + " " + blobStore.local.basePath
+}
+
+func (blobStore encrypted) observerLabel() string {
+	return blobStore.GetBlobStoreDescription() + " " +
+		filepath.Dir(blobStore.index.path)
+}
+
+func (blobStore *remoteSftp) observerLabel() string {
+	return blobStore.GetBlobStoreDescription() + " " +
+		blobStore.config.GetRemotePath()
+}
+
+func (store inventoryArchiveV0) observerLabel() string {
+	return store.GetBlobStoreDescription() + " " + store.basePath
+}
+
+func (store inventoryArchiveV1) observerLabel() string {
+	return store.GetBlobStoreDescription() + " " + store.basePath
+}
+
+// observeLookup is deferred by HasBlob implementations.
+func observeLookup(store observedBlobStore, start time.Time, found *bool) {
+	if observer, ok := getBlobStoreObserver(); ok {
+		observer.ObserveLookup(store.observerLabel(), time.Since(start), *found)
+	}
+}
+
+func observeCache(store observedBlobStore, hit bool) {
+	ObserveCache(store.observerLabel(), hit)
+}
+
+// ObserveCache reports a cache consultation to the installed observer under
+// label, for caches that are not kept by a blob store.
+func ObserveCache(label string, hit bool) {
+	if observer, ok := getBlobStoreObserver(); ok {
+		observer.ObserveCache(label, hit)
+	}
+}
+
+// observeBlobReader is deferred by MakeBlobReader implementations, and wraps
+// the returned reader so that it reports to the observer when closed.
+func observeBlobReader(
+	store observedBlobStore,
+	start time.Time,
+	reader *domain_interfaces.BlobReader,
+	err *error,
+) {
+	observer, ok := getBlobStoreObserver()
+	if !ok || *err != nil || *reader == nil {
+		return
+	}
+
+	*reader = &observedBlobReader{
+		BlobReader: *reader,
+		observer:   observer,
+		store:      store.observerLabel(),
+		start:      start,
+	}
+}
+
+// observeBlobWriter is deferred by MakeBlobWriter implementations, and wraps
+// the returned writer so that it reports to the observer when closed.
+func observeBlobWriter(
+	store observedBlobStore,
+	start time.Time,
+	writer *domain_interfaces.BlobWriter,
+	err *error,
+) {
+	observer, ok := getBlobStoreObserver()
+	if !ok || *err != nil || *writer == nil {
+		return
+	}
+
+	*writer = &observedBlobWriter{
+		BlobWriter: *writer,
+		observer:   observer,
+		store:      store.observerLabel(),
+		start:      start,
+	}
+}
+
+type observedBlobReader struct {
+	domain_interfaces.BlobReader
+
+	observer BlobStoreObserver
+	store    string
+	start    time.Time
+	bytes    atomic.Int64
+	once     sync.Once
+}
+
+func (reader *observedBlobReader) Read(p []byte) (n int, err error) {
+	n, err = reader.BlobReader.Read(p)
+	reader.bytes.Add(int64(n))
+	return n, err
+}
+
+func (reader *observedBlobReader) ReadAt(p []byte, off int64) (n int, err error) {
+	n, err = reader.BlobReader.ReadAt(p, off)
+	reader.bytes.Add(int64(n))
+	return n, err
+}
+
+func (reader *observedBlobReader) WriteTo(w io.Writer) (n int64, err error) {
+	n, err = reader.BlobReader.WriteTo(w)
+	reader.bytes.Add(n)
+	return n, err
+}
+
+func (reader *observedBlobReader) Close() (err error) {
+	err = reader.BlobReader.Close()
+
+	reader.once.Do(func() {
+		reader.observer.ObserveRead(
+			reader.store,
+			time.Since(reader.start),
+			reader.bytes.Load(),
+		)
+	})
+
+	return err
+}
+
+type observedBlobWriter struct {
+	domain_interfaces.BlobWriter
+
+	observer BlobStoreObserver
+	store    string
+	start    time.Time
+	bytes    atomic.Int64
+	once     sync.Once
+}
+
+func (writer *observedBlobWriter) Write(p []byte) (n int, err error) {
+	n, err = writer.BlobWriter.Write(p)
+	writer.bytes.Add(int64(n))
+	return n, err
+}
+
+func (writer *observedBlobWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	n, err = writer.BlobWriter.ReadFrom(r)
+	writer.bytes.Add(n)
+	return n, err
+}
+
+func (writer *observedBlobWriter) Close() (err error) {
+	err = writer.BlobWriter.Close()
+
+	writer.once.Do(func() {
+		writer.observer.ObserveWrite(
+			writer.store,
+			time.Since(writer.start),
+			writer.bytes.Load(),
+		)
+	})
+
+	return err
+}
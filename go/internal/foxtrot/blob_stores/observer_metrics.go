@@ -0,0 +1,316 @@
+package blob_stores
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// EnvBlobStoreMetrics enables a MetricsObserver for the whole process when
+// set. Its totals are written to stderr when the blob stores' context ends,
+// as a summary, or in the Prometheus text format when set to "prometheus".
+const EnvBlobStoreMetrics = "DODDER_BLOB_STORE_METRICS"
+
+var (
+	envMetricsObserver       *MetricsObserver
+	envMetricsPrometheus     bool
+	envMetricsOutputRegister sync.Once
+)
+
+func init() {
+	value, ok := os.LookupEnv(EnvBlobStoreMetrics)
+	if !ok {
+		return
+	}
+
+	envMetricsObserver = MakeMetricsObserver()
+	envMetricsPrometheus = value == "prometheus"
+	SetBlobStoreObserver(envMetricsObserver)
+}
+
+// writeEnvMetricsAfter arranges for the metrics enabled by
+// EnvBlobStoreMetrics to be written when ctx ends. Only the first context
+// gets to write them.
+func writeEnvMetricsAfter(ctx interfaces.ActiveContext) {
+	if envMetricsObserver == nil {
+		return
+	}
+
+	envMetricsOutputRegister.Do(func() {
+		ctx.After(errors.MakeFuncContextFromFuncErr(func() error {
+			if envMetricsPrometheus {
+				return envMetricsObserver.WritePrometheus(os.Stderr)
+			}
+
+			return envMetricsObserver.WriteSummary(os.Stderr)
+		}))
+	})
+}
+
+type blobStoreMetrics struct {
+	reads, readBytes    int64
+	readTime            time.Duration
+	writes, writeBytes  int64
+	writeTime           time.Duration
+	lookups, lookupHits int64
+	lookupTime          time.Duration
+	cacheHits           int64
+	cacheMisses         int64
+}
+
+func (metrics blobStoreMetrics) cacheHitRate() float64 {
+	consultations := metrics.cacheHits + metrics.cacheMisses
+
+	if consultations == 0 {
+		return 0
+	}
+
+	return float64(metrics.cacheHits) / float64(consultations)
+}
+
+// MetricsObserver is a BlobStoreObserver that keeps running totals per store.
+type MetricsObserver struct {
+	lock   sync.Mutex
+	stores map[string]*blobStoreMetrics
+}
+
+var _ BlobStoreObserver = &MetricsObserver{}
+
+func MakeMetricsObserver() *MetricsObserver {
+	return &MetricsObserver{
+		stores: make(map[string]*blobStoreMetrics),
+	}
+}
+
+// update applies f to the totals of store under the lock
+func (observer *MetricsObserver) update(
+	store string,
+	f func(*blobStoreMetrics),
+) {
+	observer.lock.Lock()
+	defer observer.lock.Unlock()
+
+	metrics, ok := observer.stores[store]
+	if !ok {
+		metrics = &blobStoreMetrics{}
+		observer.stores[store] = metrics
+	}
+
+	f(metrics)
+}
+
+func (observer *MetricsObserver) ObserveRead(
+	store string,
+	elapsed time.Duration,
+	bytes int64,
+) {
+	observer.update(store, func(metrics *blobStoreMetrics) {
+		metrics.reads++
+		metrics.readBytes += bytes
+		metrics.readTime += elapsed
+	})
+}
+
+func (observer *MetricsObserver) ObserveWrite(
+	store string,
+	elapsed time.Duration,
+	bytes int64,
+) {
+	observer.update(store, func(metrics *blobStoreMetrics) {
+		metrics.writes++
+		metrics.writeBytes += bytes
+		metrics.writeTime += elapsed
+	})
+}
+
+func (observer *MetricsObserver) ObserveLookup(
+	store string,
+	elapsed time.Duration,
+	found bool,
+) {
+	observer.update(store, func(metrics *blobStoreMetrics) {
+		metrics.lookups++
+		metrics.lookupTime += elapsed
+
+		if found {
+			metrics.lookupHits++
+		}
+	})
+}
+
+func (observer *MetricsObserver) ObserveCache(store string, hit bool) {
+	observer.update(store, func(metrics *blobStoreMetrics) {
+		if hit {
+			metrics.cacheHits++
+		} else {
+			metrics.cacheMisses++
+		}
+	})
+}
+
+// snapshot returns a copy of the totals, sorted by store
+func (observer *MetricsObserver) snapshot() (
+	stores []string,
+	metrics []blobStoreMetrics,
+) {
+	observer.lock.Lock()
+	defer observer.lock.Unlock()
+
+	for store := range observer.stores {
+		stores = append(stores, store)
+	}
+
+	slices.Sort(stores)
+
+	for _, store := range stores {
+		metrics = append(metrics, *observer.stores[store])
+	}
+
+	return stores, metrics
+}
+
+// WriteSummary writes the totals of every store that reported an event, in a
+// form meant for people.
+func (observer *MetricsObserver) WriteSummary(w io.Writer) (err error) {
+	stores, metrics := observer.snapshot()
+
+	var summary strings.Builder
+
+	summary.WriteString("blob store metrics:\n")
+
+	for i, store := range stores {
+		storeMetrics := metrics[i]
+
+		fmt.Fprintf(&summary, "  %s\n", store)
+		fmt.Fprintf(
+			&summary,
+			"    reads: %d, %d bytes, %s\n",
+			storeMetrics.reads,
+			storeMetrics.readBytes,
+			storeMetrics.readTime,
+		)
+		fmt.Fprintf(
+			&summary,
+			"    writes: %d, %d bytes, %s\n",
+			storeMetrics.writes,
+			storeMetrics.writeBytes,
+			storeMetrics.writeTime,
+		)
+		fmt.Fprintf(
+			&summary,
+			"    lookups: %d, %d found, %s\n",
+			storeMetrics.lookups,
+			storeMetrics.lookupHits,
+			storeMetrics.lookupTime,
+		)
+		fmt.Fprintf(
+			&summary,
+			"    cache: %d hits, %d misses (%.1f%% hit rate)\n",
+			storeMetrics.cacheHits,
+			storeMetrics.cacheMisses,
+			storeMetrics.cacheHitRate()*100,
+		)
+	}
+
+	if _, err = io.WriteString(w, summary.String()); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+var prometheusLabelEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+)
+
+// WritePrometheus writes the totals in the Prometheus text exposition format,
+// as counters labeled by store.
+func (observer *MetricsObserver) WritePrometheus(w io.Writer) (err error) {
+	stores, metrics := observer.snapshot()
+
+	families := []struct {
+		name, help string
+		value      func(blobStoreMetrics) string
+	}{
+		{
+			"reads_total", "Blob readers closed.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.reads) },
+		},
+		{
+			"read_bytes_total", "Bytes read through blob readers.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.readBytes) },
+		},
+		{
+			"read_seconds_total", "Time blob readers were open.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.readTime.Seconds()) },
+		},
+		{
+			"writes_total", "Blob writers closed.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.writes) },
+		},
+		{
+			"write_bytes_total", "Bytes written through blob writers.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.writeBytes) },
+		},
+		{
+			"write_seconds_total", "Time blob writers were open.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.writeTime.Seconds()) },
+		},
+		{
+			"lookups_total", "Blob presence lookups.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.lookups) },
+		},
+		{
+			"lookup_hits_total", "Blob presence lookups that found the blob.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.lookupHits) },
+		},
+		{
+			"lookup_seconds_total", "Time spent in blob presence lookups.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.lookupTime.Seconds()) },
+		},
+		{
+			"cache_hits_total", "Cache consultations that hit.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.cacheHits) },
+		},
+		{
+			"cache_misses_total", "Cache consultations that missed.",
+			func(m blobStoreMetrics) string { return fmt.Sprint(m.cacheMisses) },
+		},
+	}
+
+	var exposition strings.Builder
+
+	for _, family := range families {
+		name := "dodder_blob_store_" + family.name
+
+		fmt.Fprintf(&exposition, "# HELP %s %s\n", name, family.help)
+		fmt.Fprintf(&exposition, "# TYPE %s counter\n", name)
+
+		for i, store := range stores {
+			fmt.Fprintf(
+				&exposition,
+				"%s{store=\"%s\"} %s\n",
+				name,
+				prometheusLabelEscaper.Replace(store),
+				family.value(metrics[i]),
+			)
+		}
+	}
+
+	if _, err = io.WriteString(w, exposition.String()); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,123 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestMetricsObserverCountsArchiveReads(t *testing.T) {
+	observer := MakeMetricsObserver()
+	SetBlobStoreObserver(observer)
+	t.Cleanup(func() { SetBlobStoreObserver(nil) })
+
+	hashFormat := markl.FormatHashSha256
+
+	data := []byte("observed blob")
+	rawHash := sha256.Sum256(data)
+	id, repool := hashFormat.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	defer repool()
+
+	store := inventoryArchiveV1{
+		defaultHash: hashFormat,
+		basePath:    t.TempDir(),
+		cachePath:   t.TempDir(),
+		looseBlobStore: &stubBlobStore{
+			allBlobIds: []domain_interfaces.MarklId{id},
+			blobData:   map[string][]byte{id.String(): data},
+		},
+		index: make(map[string]archiveEntryV1),
+		config: blob_store_configs.TomlInventoryArchiveV2{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+			FooterIndex:     true,
+		},
+		generation: &archiveGeneration{},
+	}
+
+	if err := store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	if !store.HasBlob(id) {
+		t.Fatal("expected the packed blob to be found")
+	}
+
+	reader, err := store.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		t.Fatalf("reading blob: %v", err)
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	metrics := observer.stores[store.observerLabel()]
+	if metrics == nil {
+		t.Fatalf("expected metrics for %q, got %v", store.observerLabel(), observer.stores)
+	}
+
+	if metrics.reads != 1 || metrics.readBytes != int64(len(data)) {
+		t.Errorf(
+			"expected 1 read of %d bytes, got %d of %d",
+			len(data),
+			metrics.reads,
+			metrics.readBytes,
+		)
+	}
+
+	if metrics.lookups == 0 || metrics.lookupHits == 0 {
+		t.Errorf("expected a successful lookup, got %+v", metrics)
+	}
+
+	var exposition strings.Builder
+
+	if err := observer.WritePrometheus(&exposition); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+
+	expected := `dodder_blob_store_read_bytes_total{store="` +
+		store.observerLabel() + `"} 13`
+
+	if !strings.Contains(exposition.String(), expected) {
+		t.Errorf("expected %q in:\n%s", expected, exposition.String())
+	}
+}
+
+func TestMetricsObserverSummarizesCacheHitRate(t *testing.T) {
+	observer := MakeMetricsObserver()
+	SetBlobStoreObserver(observer)
+	t.Cleanup(func() { SetBlobStoreObserver(nil) })
+
+	ObserveCache("object cache", true)
+	ObserveCache("object cache", true)
+	ObserveCache("object cache", true)
+	ObserveCache("object cache", false)
+
+	var summary strings.Builder
+
+	if err := observer.WriteSummary(&summary); err != nil {
+		t.Fatalf("WriteSummary: %v", err)
+	}
+
+	expected := "cache: 3 hits, 1 misses (75.0% hit rate)"
+
+	if !strings.Contains(summary.String(), expected) {
+		t.Errorf("expected %q in:\n%s", expected, summary.String())
+	}
+}
@@ -1,9 +1,15 @@
 package blob_stores
 
 import (
+	"encoding/hex"
+	"fmt"
+	"io"
+
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
 	"code.linenisgreat.com/dodder/go/internal/charlie/tap_diagnostics"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
 )
 
@@ -29,6 +35,11 @@ type PackOptions struct {
 	// MaxPackSize overrides the configured max pack size when non-zero.
 	MaxPackSize uint64
 
+	// MaxBlobs stops collecting loose blobs once that many not yet in the
+	// archive were found, leaving the rest for a later pack, so that the pack
+	// stays small. Zero means no limit.
+	MaxBlobs int
+
 	// SkipMissingBlobs causes unreadable loose blobs to be skipped with a
 	// TAP comment instead of aborting the pack. When false, an unreadable
 	// blob emits a not-ok test point and stops packing.
@@ -37,6 +48,10 @@ type PackOptions struct {
 	// Delta enables delta compression during packing.
 	Delta bool
 
+	// NoWait causes Pack to fail with ErrStoreBusy instead of waiting when
+	// another process holds the store's lock.
+	NoWait bool
+
 	// TapWriter emits phase-level TAP test points during packing. When nil,
 	// packing is silent (backward compatible for unit tests).
 	TapWriter *tap.Writer
@@ -79,3 +94,68 @@ func tapComment(tw *tap.Writer, msg string) {
 		tw.Comment(msg)
 	}
 }
+
+// openPackedBlob opens the loose blob with the given digest so that it can be
+// streamed into an archive.
+func openPackedBlob(
+	looseBlobStore domain_interfaces.BlobStore,
+	hashFormat markl.FormatHash,
+	digest []byte,
+) (reader domain_interfaces.BlobReader, err error) {
+	marklId, repool := hashFormat.GetBlobIdForHexString(
+		hex.EncodeToString(digest),
+	)
+	defer repool()
+
+	if reader, err = looseBlobStore.MakeBlobReader(marklId); err != nil {
+		err = errors.Wrapf(err, "reading loose blob %x", digest)
+		return reader, err
+	}
+
+	return reader, err
+}
+
+// readPackedBlob loads a whole loose blob, for the packing steps that need
+// random access to its content: delta computation and dictionary training.
+func readPackedBlob(
+	looseBlobStore domain_interfaces.BlobStore,
+	hashFormat markl.FormatHash,
+	digest []byte,
+) (data []byte, err error) {
+	var reader domain_interfaces.BlobReader
+
+	if reader, err = openPackedBlob(looseBlobStore, hashFormat, digest); err != nil {
+		return data, err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	if data, err = io.ReadAll(reader); err != nil {
+		err = errors.Wrapf(err, "reading loose blob data %x", digest)
+		return data, err
+	}
+
+	return data, err
+}
+
+// skipUnreadablePackedBlob handles a loose blob that could not be opened for
+// packing. With SkipMissingBlobs the blob is left out of the archive and nil
+// is returned; otherwise readErr is returned to abort the pack.
+func skipUnreadablePackedBlob(
+	options PackOptions,
+	hashFormat markl.FormatHash,
+	digest []byte,
+	readErr error,
+) error {
+	if !options.SkipMissingBlobs {
+		return readErr
+	}
+
+	marklId, repool := hashFormat.GetBlobIdForHexString(
+		hex.EncodeToString(digest),
+	)
+	tapComment(options.TapWriter, fmt.Sprintf("blob skipped: %s", marklId))
+	repool()
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package blob_stores
+
+import (
+	"encoding/binary"
+	"math"
+
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+)
+
+// splitBlobChunksV1 splits the digest-sorted metas into the blobs of each
+// archive: on content-defined boundaries when the store's chunking asks for
+// them, and otherwise whenever maxPackSize fills up.
+func (store inventoryArchiveV1) splitBlobChunksV1(
+	metas []packedBlobMeta,
+	maxPackSize uint64,
+) [][]packedBlobMeta {
+	config, ok := store.config.(blob_store_configs.ChunkingConfigImmutable)
+
+	if !ok || !config.GetChunkingContentDefined() {
+		return splitBlobChunks(metas, maxPackSize)
+	}
+
+	return splitBlobChunksContentDefined(
+		metas,
+		config.GetChunkingAvgArchiveSize(),
+		maxPackSize,
+	)
+}
+
+// splitBlobChunksContentDefined ends a chunk after each blob whose digest
+// marks a boundary. A blob marks one with a probability of its size over
+// avgChunkSize, so chunks average avgChunkSize bytes. As boundaries depend
+// only on the blobs themselves, packing the same blobs always yields the same
+// chunks, and adding or removing a blob only changes the chunk it falls in,
+// where splitting by size would shift every chunk after it. Chunks that would
+// exceed a non-zero maxPackSize are still split early.
+func splitBlobChunksContentDefined(
+	metas []packedBlobMeta,
+	avgChunkSize uint64,
+	maxPackSize uint64,
+) [][]packedBlobMeta {
+	if len(metas) == 0 {
+		return nil
+	}
+
+	var chunks [][]packedBlobMeta
+	var current []packedBlobMeta
+	var currentSize uint64
+
+	for _, meta := range metas {
+		if len(current) > 0 && maxPackSize > 0 &&
+			currentSize+meta.size > maxPackSize {
+			chunks = append(chunks, current)
+			current = nil
+			currentSize = 0
+		}
+
+		current = append(current, meta)
+		currentSize += meta.size
+
+		if isChunkBoundary(meta, avgChunkSize) {
+			chunks = append(chunks, current)
+			current = nil
+			currentSize = 0
+		}
+	}
+
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
+
+// isChunkBoundary reads the leading bytes of the blob's digest, which are
+// uniformly distributed, as a number, and compares it to a threshold that
+// scales with the blob's size.
+func isChunkBoundary(meta packedBlobMeta, avgChunkSize uint64) bool {
+	if avgChunkSize == 0 || meta.size >= avgChunkSize {
+		return true
+	}
+
+	if len(meta.digest) < 8 {
+		return false
+	}
+
+	threshold := math.MaxUint64 / avgChunkSize * meta.size
+
+	return binary.BigEndian.Uint64(meta.digest[:8]) < threshold
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
@@ -26,8 +27,18 @@ import (
 // a fast path like os.Stat when available.
 type blobSizeFn func(domain_interfaces.MarklId) (uint64, error)
 
+// blobSketchFn returns the uncompressed size of a blob together with a
+// similarity sketch of its content, computed in the same pass.
+type blobSketchFn func(domain_interfaces.MarklId) (uint64, []uint32, error)
+
 // collectBlobMetasParallel iterates the loose blob store to find packing
-// candidates, then fans out size lookups across multiple goroutines.
+// candidates, then fans out size lookups across multiple goroutines. When
+// sketchFn is non-nil it is used instead of sizeFn, so that delta strategies
+// which compare content get their sketches without reading blobs again.
+//
+// Only blobs in hashFormatId are collected: archives hold one hash format, so
+// loose blobs in another format, left from before the repo changed its
+// default hash, stay loose rather than being archived under the wrong id.
 //
 // The AllBlobs iterator is consumed serially (it is not concurrent-safe).
 // Size lookups are parallel with min(NumCPU, len(candidates)) workers.
@@ -35,13 +46,16 @@ func collectBlobMetasParallel(
 	ctx interfaces.ActiveContext,
 	tw *tap.Writer,
 	looseBlobStore domain_interfaces.BlobStore,
+	hashFormatId string,
 	index map[string]bool,
 	options PackOptions,
 	sizeFn blobSizeFn,
+	sketchFn blobSketchFn,
 ) (metas []packedBlobMeta, err error) {
-	// Phase 1a: Serial iteration to collect candidate IDs.
+	// Phase 1a: Serial iteration to collect candidate IDs. Iterators may
+	// reuse and repool the id they yield, so each candidate keeps a copy.
 	type candidate struct {
-		id     domain_interfaces.MarklId
+		id     markl.Id
 		digest []byte
 	}
 
@@ -64,6 +78,10 @@ func collectBlobMetasParallel(
 			continue
 		}
 
+		if looseId.GetMarklFormat().GetMarklFormatId() != hashFormatId {
+			continue
+		}
+
 		if index[looseId.String()] {
 			continue
 		}
@@ -77,10 +95,17 @@ func collectBlobMetasParallel(
 		digestBytes := make([]byte, len(looseId.GetBytes()))
 		copy(digestBytes, looseId.GetBytes())
 
+		var id markl.Id
+		id.ResetWithMarklId(looseId)
+
 		candidates = append(candidates, candidate{
-			id:     looseId,
+			id:     id,
 			digest: digestBytes,
 		})
+
+		if options.MaxBlobs > 0 && len(candidates) >= options.MaxBlobs {
+			break
+		}
 	}
 
 	if len(candidates) == 0 {
@@ -127,7 +152,16 @@ func collectBlobMetasParallel(
 				return
 			}
 
-			blobSize, sizeErr := sizeFn(cand.id)
+			var blobSize uint64
+			var sketch []uint32
+			var sizeErr error
+
+			if sketchFn != nil {
+				blobSize, sketch, sizeErr = sketchFn(&cand.id)
+			} else {
+				blobSize, sizeErr = sizeFn(&cand.id)
+			}
+
 			if sizeErr != nil {
 				if options.SkipMissingBlobs {
 					// Mark as nil digest; filtered and logged after wg.Wait.
@@ -139,7 +173,7 @@ func collectBlobMetasParallel(
 					firstErr = errors.Wrapf(
 						sizeErr,
 						"getting size of loose blob %s",
-						cand.id,
+						&cand.id,
 					)
 					cancel()
 				})
@@ -150,6 +184,7 @@ func collectBlobMetasParallel(
 			metas[idx] = packedBlobMeta{
 				digest: cand.digest,
 				size:   blobSize,
+				sketch: sketch,
 			}
 		}(i, c)
 	}
@@ -169,7 +204,7 @@ func collectBlobMetasParallel(
 		if m.digest != nil {
 			filtered = append(filtered, m)
 		} else if options.SkipMissingBlobs && i < len(candidates) {
-			tapComment(tw, fmt.Sprintf("blob skipped: %s", candidates[i].id))
+			tapComment(tw, fmt.Sprintf("blob skipped: %s", &candidates[i].id))
 		}
 	}
 
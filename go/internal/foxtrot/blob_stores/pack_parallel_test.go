@@ -51,9 +51,11 @@ func TestCollectBlobMetasParallelBasic(t *testing.T) {
 		nil,
 		nil,
 		stub,
+		hashFormat.GetMarklFormatId(),
 		make(map[string]bool),
 		PackOptions{},
 		sizeFn,
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("collectBlobMetasParallel: %v", err)
@@ -121,9 +123,11 @@ func TestCollectBlobMetasParallelSkipsArchived(t *testing.T) {
 		nil,
 		nil,
 		stub,
+		hashFormat.GetMarklFormatId(),
 		indexPresence,
 		PackOptions{},
 		sizeFn,
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("collectBlobMetasParallel: %v", err)
@@ -134,6 +138,45 @@ func TestCollectBlobMetasParallelSkipsArchived(t *testing.T) {
 	}
 }
 
+func TestCollectBlobMetasParallelStopsAtMaxBlobs(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+
+	stub := &stubBlobStore{blobData: make(map[string][]byte)}
+
+	for _, data := range []string{"first", "second", "third"} {
+		rawHash := sha256.Sum256([]byte(data))
+		id, repool := hashFormat.GetBlobIdForHexString(
+			hex.EncodeToString(rawHash[:]),
+		)
+		defer repool()
+
+		stub.allBlobIds = append(stub.allBlobIds, id)
+		stub.blobData[id.String()] = []byte(data)
+	}
+
+	sizeFn := func(id domain_interfaces.MarklId) (uint64, error) {
+		return uint64(len(stub.blobData[id.String()])), nil
+	}
+
+	metas, err := collectBlobMetasParallel(
+		nil,
+		nil,
+		stub,
+		hashFormat.GetMarklFormatId(),
+		map[string]bool{},
+		PackOptions{MaxBlobs: 2},
+		sizeFn,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("collectBlobMetasParallel: %v", err)
+	}
+
+	if len(metas) != 2 {
+		t.Fatalf("expected 2 metas, got %d", len(metas))
+	}
+}
+
 func TestCollectBlobMetasParallelEmpty(t *testing.T) {
 	stub := &stubBlobStore{}
 
@@ -141,9 +184,11 @@ func TestCollectBlobMetasParallelEmpty(t *testing.T) {
 		nil,
 		nil,
 		stub,
+		markl.FormatIdHashSha256,
 		make(map[string]bool),
 		PackOptions{},
 		func(domain_interfaces.MarklId) (uint64, error) { return 0, nil },
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("collectBlobMetasParallel: %v", err)
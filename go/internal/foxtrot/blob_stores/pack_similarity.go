@@ -0,0 +1,142 @@
+package blob_stores
+
+import (
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const (
+	deltaStrategySize       = "size"
+	deltaStrategySimilarity = "similarity"
+
+	defaultSimilaritySketchSize    = 64
+	defaultSimilarityShingleSize   = 8
+	defaultSimilarityMinSimilarity = 0.1
+)
+
+// similarityStrategy holds the resolved settings of the "similarity" delta
+// strategy, with defaults applied.
+type similarityStrategy struct {
+	sketchSize    int
+	shingleSize   int
+	minSimilarity float64
+}
+
+// similarityStrategy reports whether the store selects delta bases by
+// content similarity. It fails for an unknown strategy so that a typo in the
+// config is not silently packed with size grouping.
+func (store inventoryArchiveV1) similarityStrategy() (
+	strategy similarityStrategy,
+	ok bool,
+	err error,
+) {
+	if !store.deltaEnabled() {
+		return strategy, false, err
+	}
+
+	config, hasConfig := store.config.(blob_store_configs.DeltaStrategyConfigImmutable)
+	if !hasConfig {
+		return strategy, false, err
+	}
+
+	switch config.GetDeltaStrategy() {
+	case "", deltaStrategySize:
+		return strategy, false, err
+
+	case deltaStrategySimilarity:
+
+	default:
+		err = errors.Errorf(
+			"unknown delta strategy: %q",
+			config.GetDeltaStrategy(),
+		)
+		return strategy, false, err
+	}
+
+	strategy = similarityStrategy{
+		sketchSize:    config.GetSimilaritySketchSize(),
+		shingleSize:   config.GetSimilarityShingleSize(),
+		minSimilarity: config.GetSimilarityMinSimilarity(),
+	}
+
+	if strategy.sketchSize <= 0 {
+		strategy.sketchSize = defaultSimilaritySketchSize
+	}
+
+	if strategy.shingleSize <= 0 {
+		strategy.shingleSize = defaultSimilarityShingleSize
+	}
+
+	if strategy.minSimilarity <= 0 {
+		strategy.minSimilarity = defaultSimilarityMinSimilarity
+	}
+
+	return strategy, true, err
+}
+
+// sketchFn returns the blobSketchFn used while collecting loose blobs, which
+// sizes each blob by counting the bytes its sketch was computed over.
+func (store inventoryArchiveV1) sketchFn(
+	strategy similarityStrategy,
+) blobSketchFn {
+	computer := &inventory_archive.ShingleMinHashComputer{
+		ShingleSize: strategy.shingleSize,
+		K:           strategy.sketchSize,
+	}
+
+	return func(
+		id domain_interfaces.MarklId,
+	) (size uint64, sketch []uint32, err error) {
+		reader, err := store.looseBlobStore.MakeBlobReader(id)
+		if err != nil {
+			err = errors.Wrapf(err, "opening blob %s for sketch", id)
+			return size, sketch, err
+		}
+
+		defer errors.DeferredCloser(&err, reader)
+
+		counter := &countingReader{reader: reader}
+
+		if sketch, err = computer.ComputeSignature(counter); err != nil {
+			err = errors.Wrapf(err, "sketching blob %s", id)
+			return size, sketch, err
+		}
+
+		return counter.count, sketch, err
+	}
+}
+
+// similaritySelector returns the BaseSelector for the similarity strategy,
+// which compares the sketches carried in BlobMetadata.Signature.
+func (store inventoryArchiveV1) similaritySelector(
+	strategy similarityStrategy,
+) (selector inventory_archive.BaseSelector, err error) {
+	if selector, err = inventory_archive.BaseSelectorForName(
+		deltaStrategySimilarity,
+		inventory_archive.BaseSelectorParams{
+			MinBlobSize:   store.config.GetDeltaMinBlobSize(),
+			MaxBlobSize:   store.config.GetDeltaMaxBlobSize(),
+			MinSimilarity: strategy.minSimilarity,
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return selector, err
+	}
+
+	return selector, err
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  uint64
+}
+
+func (reader *countingReader) Read(p []byte) (n int, err error) {
+	n, err = reader.reader.Read(p)
+	reader.count += uint64(n)
+	return n, err
+}
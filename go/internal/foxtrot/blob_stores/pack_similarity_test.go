@@ -0,0 +1,122 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestPackV1SimilarityStrategy(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+
+	var original bytes.Buffer
+
+	for i := range 60 {
+		fmt.Fprintf(&original, "line %d of a document that keeps changing\n", i)
+	}
+
+	// extended shares all of original but is too much larger for size
+	// grouping to pair them, while unrelated matches original's size.
+	extended := append(bytes.Clone(original.Bytes()), original.Bytes()[:1500]...)
+
+	unrelated := make([]byte, original.Len())
+	if _, err := rand.Read(unrelated); err != nil {
+		t.Fatal(err)
+	}
+
+	blobs := map[string][]byte{}
+	var ids []domain_interfaces.MarklId
+
+	for _, data := range [][]byte{original.Bytes(), extended, unrelated} {
+		rawHash := sha256.Sum256(data)
+		id, repool := hashFormat.GetBlobIdForHexString(
+			hex.EncodeToString(rawHash[:]),
+		)
+		defer repool()
+
+		blobs[id.String()] = data
+		ids = append(ids, id)
+	}
+
+	store := inventoryArchiveV1{
+		defaultHash: hashFormat,
+		basePath:    t.TempDir(),
+		cachePath:   t.TempDir(),
+		looseBlobStore: &stubBlobStore{
+			allBlobIds: ids,
+			blobData:   blobs,
+		},
+		index: make(map[string]archiveEntryV1),
+		config: blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+			Delta: blob_store_configs.DeltaConfig{
+				Enabled:     true,
+				Algorithm:   "bsdiff",
+				Strategy:    "similarity",
+				MinBlobSize: 1,
+				MaxBlobSize: 10485760,
+				SizeRatio:   1.1,
+			},
+		},
+	}
+
+	if err := store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	if entry := store.index[ids[0].String()]; entry.EntryType != inventory_archive.EntryTypeDelta {
+		t.Errorf("expected the original to be a delta against the extended blob")
+	} else if base := store.index[ids[1].String()]; entry.BaseOffset != base.Offset {
+		t.Errorf("expected base offset %d, got %d", base.Offset, entry.BaseOffset)
+	}
+
+	if entry := store.index[ids[2].String()]; entry.EntryType != inventory_archive.EntryTypeFull {
+		t.Errorf("expected the unrelated blob to be stored in full")
+	}
+
+	for _, id := range ids {
+		reader, err := store.MakeBlobReader(id)
+		if err != nil {
+			t.Fatalf("MakeBlobReader: %v", err)
+		}
+
+		got, err := io.ReadAll(reader)
+		reader.Close()
+
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+
+		if !bytes.Equal(got, blobs[id.String()]) {
+			t.Errorf("blob %s does not round-trip", id)
+		}
+	}
+}
+
+func TestPackV1UnknownDeltaStrategy(t *testing.T) {
+	store := inventoryArchiveV1{
+		config: blob_store_configs.TomlInventoryArchiveV1{
+			Delta: blob_store_configs.DeltaConfig{
+				Enabled:  true,
+				Strategy: "similar",
+			},
+		},
+	}
+
+	if _, _, err := store.similarityStrategy(); err == nil {
+		t.Fatal("expected an error for an unknown delta strategy")
+	}
+}
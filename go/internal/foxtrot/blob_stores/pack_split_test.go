@@ -3,6 +3,12 @@
 package blob_stores
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -78,3 +84,86 @@ func TestSplitBlobChunksEmpty(t *testing.T) {
 		t.Fatalf("expected 0 chunks for empty input, got %d", len(chunks))
 	}
 }
+
+func makeTestSortedMetas(count int, size uint64) []packedBlobMeta {
+	metas := make([]packedBlobMeta, count)
+
+	for i := range metas {
+		digest := sha256.Sum256([]byte(fmt.Sprintf("blob %d", i)))
+		metas[i] = packedBlobMeta{digest: digest[:], size: size}
+	}
+
+	sort.Slice(metas, func(i, j int) bool {
+		return bytes.Compare(metas[i].digest, metas[j].digest) < 0
+	})
+
+	return metas
+}
+
+func chunkKeys(chunks [][]packedBlobMeta) map[string]bool {
+	keys := make(map[string]bool, len(chunks))
+
+	for _, chunk := range chunks {
+		var key strings.Builder
+
+		for _, meta := range chunk {
+			key.WriteString(hex.EncodeToString(meta.digest))
+		}
+
+		keys[key.String()] = true
+	}
+
+	return keys
+}
+
+func TestSplitBlobChunksContentDefinedIsStableUnderInsertion(t *testing.T) {
+	metas := makeTestSortedMetas(400, 100)
+	before := splitBlobChunksContentDefined(metas, 1000, 0)
+
+	if len(before) < 10 {
+		t.Fatalf("expected chunks of about 10 blobs, got %d chunks", len(before))
+	}
+
+	// one more blob lands in the middle of the digest order
+	extra := makeTestSortedMetas(401, 100)
+	after := splitBlobChunksContentDefined(extra, 1000, 0)
+
+	beforeKeys := chunkKeys(before)
+	var changed int
+
+	for key := range chunkKeys(after) {
+		if !beforeKeys[key] {
+			changed++
+		}
+	}
+
+	// the chunk that gets the new blob changes, and splits in two if the
+	// new blob marks a boundary
+	if changed > 2 {
+		t.Errorf("expected at most 2 changed chunks, got %d", changed)
+	}
+
+	// splitting by size shifts every chunk after the new blob
+	beforeKeys = chunkKeys(splitBlobChunks(metas, 1000))
+	changed = 0
+
+	for key := range chunkKeys(splitBlobChunks(extra, 1000)) {
+		if !beforeKeys[key] {
+			changed++
+		}
+	}
+
+	if changed <= 2 {
+		t.Errorf("expected splitting by size to change more chunks, got %d", changed)
+	}
+}
+
+func TestSplitBlobChunksContentDefinedRespectsMaxPackSize(t *testing.T) {
+	metas := makeTestSortedMetas(100, 100)
+
+	for _, chunk := range splitBlobChunksContentDefined(metas, 1<<30, 250) {
+		if len(chunk) > 2 {
+			t.Fatalf("expected at most 2 blobs per chunk, got %d", len(chunk))
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package blob_stores
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/hex"
 	"fmt"
@@ -13,16 +14,13 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
 )
 
-type packedBlob struct {
-	digest []byte
-	data   []byte
-}
-
 type packedBlobMeta struct {
 	digest []byte
 	size   uint64
+	sketch []uint32
 }
 
 // splitBlobChunks partitions sorted blob metadata into chunks where each
@@ -64,13 +62,30 @@ func (store inventoryArchiveV0) Pack(options PackOptions) (err error) {
 	ctx := options.Context
 	tw := options.TapWriter
 
+	if err = errIfReadOnly(store.readOnly, store.basePath); err != nil {
+		return err
+	}
+
+	if err = errIfDryRun(store.dryRun, store.basePath); err != nil {
+		return err
+	}
+
+	lock, err := lockStore(store.basePath, options.NoWait)
+	if err != nil {
+		return err
+	}
+
+	defer errors.Deferred(&err, lock.Unlock)
+
 	metas, err := collectBlobMetasParallel(
 		ctx,
 		tw,
 		store.looseBlobStore,
+		store.defaultHash.GetMarklFormatId(),
 		indexPresenceFromV0(store.index),
 		options,
 		store.GetBlobSize,
+		nil,
 	)
 	if err != nil {
 		return err
@@ -80,6 +95,17 @@ func (store inventoryArchiveV0) Pack(options PackOptions) (err error) {
 		return nil
 	}
 
+	if err = checkFreeSpaceForArchives(
+		store.basePath,
+		store.cachePath,
+		packedPayloadBytes(metas),
+		len(metas),
+		len(store.index)+len(metas),
+	); err != nil {
+		tapNotOk(tw, "check free space", err)
+		return err
+	}
+
 	maxPackSize := options.MaxPackSize
 	if maxPackSize == 0 {
 		maxPackSize = store.config.GetMaxPackSize()
@@ -95,71 +121,32 @@ func (store inventoryArchiveV0) Pack(options PackOptions) (err error) {
 
 	var results []chunkResult
 
-	// Phase 2: Load blob data one chunk at a time, write archive, release.
+	// Phase 2: Stream each chunk's blobs into its archive, one blob at a time.
 	for chunkIdx, chunkMetas := range chunks {
 		if err = packContextCancelled(ctx); err != nil {
 			err = errors.Wrap(err)
 			return err
 		}
 
-		var blobs []packedBlob
-
-		for _, meta := range chunkMetas {
-			marklId, repool := store.defaultHash.GetBlobIdForHexString(
-				hex.EncodeToString(meta.digest),
-			)
-			idString := marklId.String()
-
-			reader, readErr := store.looseBlobStore.MakeBlobReader(marklId)
-			repool()
-
-			if readErr != nil {
-				if options.SkipMissingBlobs {
-					tapComment(tw, fmt.Sprintf("blob skipped: %s", idString))
-					continue
-				}
-
-				err = errors.Wrapf(readErr, "reading loose blob %x", meta.digest)
-				tapNotOk(tw, fmt.Sprintf("blob skipped: %s", idString), err)
-				return err
-			}
-
-			data, readAllErr := io.ReadAll(reader)
-			reader.Close()
-
-			if readAllErr != nil {
-				if options.SkipMissingBlobs {
-					tapComment(tw, fmt.Sprintf("blob skipped: %s", idString))
-					continue
-				}
-
-				err = errors.Wrapf(readAllErr, "reading loose blob data %x", meta.digest)
-				tapNotOk(tw, fmt.Sprintf("blob skipped: %s", idString), err)
-				return err
-			}
-
-			blobs = append(blobs, packedBlob{digest: meta.digest, data: data})
-		}
-
-		if len(blobs) == 0 {
-			continue
-		}
-
-		dataPath, entryCount, packErr := store.packChunkArchive(blobs)
+		dataPath, entryCount, packErr := store.packChunkArchive(
+			options,
+			chunkMetas,
+		)
 		if packErr != nil {
 			desc := fmt.Sprintf("write chunk %d/%d", chunkIdx+1, totalChunks)
 			tapNotOk(tw, desc, packErr)
 			return packErr
 		}
 
+		if entryCount == 0 {
+			continue
+		}
+
 		tapOk(tw, fmt.Sprintf(
 			"write chunk %d/%d (%d entries, 0 delta)",
 			chunkIdx+1, totalChunks, entryCount,
 		))
 
-		// Release blob data — let GC reclaim before next chunk.
-		blobs = nil
-
 		results = append(results, chunkResult{dataPath: dataPath, metas: chunkMetas})
 	}
 
@@ -225,8 +212,11 @@ func (store inventoryArchiveV0) Pack(options PackOptions) (err error) {
 	return nil
 }
 
+// packChunkArchive streams the chunk's loose blobs into a new archive. It
+// returns an empty dataPath and no entries when every blob was skipped.
 func (store inventoryArchiveV0) packChunkArchive(
-	blobs []packedBlob,
+	options PackOptions,
+	metas []packedBlobMeta,
 ) (dataPath string, entryCount int, err error) {
 	hashFormatId := store.defaultHash.GetMarklFormatId()
 	ct := store.config.GetCompressionType()
@@ -250,8 +240,12 @@ func (store inventoryArchiveV0) packChunkArchive(
 		}
 	}()
 
+	// Buffer the many small header and entry field writes; the buffer is
+	// flushed before the file is synced and renamed into place.
+	bufferedFile := bufio.NewWriter(tmpFile)
+
 	dataWriter, err := inventory_archive.NewDataWriter(
-		tmpFile,
+		bufferedFile,
 		hashFormatId,
 		ct,
 		store.encryption,
@@ -262,12 +256,46 @@ func (store inventoryArchiveV0) packChunkArchive(
 		return dataPath, 0, err
 	}
 
-	for _, blob := range blobs {
-		if err = dataWriter.WriteEntry(blob.digest, blob.data); err != nil {
+	dataWriter.SetSpoolDir(store.archivesPath())
+
+	var written int
+
+	for _, meta := range metas {
+		reader, openErr := openPackedBlob(
+			store.looseBlobStore,
+			store.defaultHash,
+			meta.digest,
+		)
+		if openErr != nil {
+			if err = skipUnreadablePackedBlob(
+				options,
+				store.defaultHash,
+				meta.digest,
+				openErr,
+			); err != nil {
+				tmpFile.Close()
+				return dataPath, 0, err
+			}
+
+			continue
+		}
+
+		err = dataWriter.WriteEntryFrom(meta.digest, reader, meta.size)
+		reader.Close()
+
+		if err != nil {
 			tmpFile.Close()
-			err = errors.Wrap(err)
+			err = errors.Wrapf(err, "packing loose blob %x", meta.digest)
 			return dataPath, 0, err
 		}
+
+		written++
+	}
+
+	if written == 0 {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return "", 0, nil
 	}
 
 	checksum, writtenEntries, err := dataWriter.Close()
@@ -277,8 +305,9 @@ func (store inventoryArchiveV0) packChunkArchive(
 		return dataPath, 0, err
 	}
 
-	if err = tmpFile.Close(); err != nil {
-		err = errors.Wrapf(err, "closing temp data file %s", tmpPath)
+	if err = bufferedFile.Flush(); err != nil {
+		tmpFile.Close()
+		err = errors.Wrapf(err, "writing temp data file %s", tmpPath)
 		return dataPath, 0, err
 	}
 
@@ -289,8 +318,7 @@ func (store inventoryArchiveV0) packChunkArchive(
 		archiveChecksum+inventory_archive.DataFileExtension,
 	)
 
-	if err = os.Rename(tmpPath, dataPath); err != nil {
-		err = errors.Wrapf(err, "renaming temp data file to %s", dataPath)
+	if err = files.CommitTemp(tmpFile, dataPath); err != nil {
 		return dataPath, 0, err
 	}
 
@@ -304,24 +332,22 @@ func (store inventoryArchiveV0) packChunkArchive(
 		}
 	}
 
-	var indexBuf bytes.Buffer
-
-	if _, err = inventory_archive.WriteIndex(
-		&indexBuf,
-		hashFormatId,
-		indexEntries,
-	); err != nil {
-		err = errors.Wrap(err)
-		return dataPath, 0, err
-	}
-
 	indexPath := filepath.Join(
 		store.archivesPath(),
 		archiveChecksum+inventory_archive.IndexFileExtension,
 	)
 
-	if err = os.WriteFile(indexPath, indexBuf.Bytes(), 0o644); err != nil {
-		err = errors.Wrapf(err, "writing index file %s", indexPath)
+	if err = files.WriteAtomic(
+		indexPath,
+		func(writer io.Writer) (err error) {
+			_, err = inventory_archive.WriteIndex(
+				writer,
+				hashFormatId,
+				indexEntries,
+			)
+			return err
+		},
+	); err != nil {
 		return dataPath, 0, err
 	}
 
@@ -346,6 +372,10 @@ func (store inventoryArchiveV0) packChunkArchive(
 }
 
 func (store inventoryArchiveV0) writeCache() (err error) {
+	if err = errIfReadOnly(store.readOnly, store.cachePath); err != nil {
+		return err
+	}
+
 	hashFormatId := store.defaultHash.GetMarklFormatId()
 
 	var allCacheEntries []inventory_archive.CacheEntry
@@ -391,20 +421,17 @@ func (store inventoryArchiveV0) writeCache() (err error) {
 		inventory_archive.CacheFileName,
 	)
 
-	cacheFile, err := os.Create(cachePath)
-	if err != nil {
-		err = errors.Wrapf(err, "creating cache file %s", cachePath)
-		return err
-	}
-
-	defer errors.DeferredCloser(&err, cacheFile)
-
-	if _, err = inventory_archive.WriteCache(
-		cacheFile,
-		hashFormatId,
-		allCacheEntries,
+	if err = files.WriteAtomic(
+		cachePath,
+		func(writer io.Writer) (err error) {
+			_, err = inventory_archive.WriteCache(
+				writer,
+				hashFormatId,
+				allCacheEntries,
+			)
+			return err
+		},
 	); err != nil {
-		err = errors.Wrapf(err, "writing cache file %s", cachePath)
 		return err
 	}
 
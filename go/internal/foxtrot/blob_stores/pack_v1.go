@@ -1,6 +1,7 @@
 package blob_stores
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/hex"
 	"fmt"
@@ -19,6 +20,8 @@ import (
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
 )
 
 // sliceBlobSet implements inventory_archive.BlobSet backed by a slice.
@@ -52,13 +55,45 @@ func (store inventoryArchiveV1) Pack(options PackOptions) (err error) {
 	ctx := options.Context
 	tw := options.TapWriter
 
+	if err = errIfReadOnly(store.readOnly, store.basePath); err != nil {
+		return err
+	}
+
+	if err = errIfDryRun(store.dryRun, store.basePath); err != nil {
+		return err
+	}
+
+	lock, err := lockStore(store.basePath, options.NoWait)
+	if err != nil {
+		return err
+	}
+
+	defer errors.Deferred(&err, lock.Unlock)
+
+	if err = store.refreshIndex(); err != nil {
+		return err
+	}
+
+	strategy, similarity, err := store.similarityStrategy()
+	if err != nil {
+		return err
+	}
+
+	var sketchFn blobSketchFn
+
+	if similarity {
+		sketchFn = store.sketchFn(strategy)
+	}
+
 	metas, err := collectBlobMetasParallel(
 		ctx,
 		tw,
 		store.looseBlobStore,
+		store.defaultHash.GetMarklFormatId(),
 		indexPresenceFromV1(store.index),
 		options,
 		store.GetBlobSize,
+		sketchFn,
 	)
 	if err != nil {
 		return err
@@ -68,13 +103,25 @@ func (store inventoryArchiveV1) Pack(options PackOptions) (err error) {
 		return nil
 	}
 
-	// Split into chunks based on max pack size.
+	if err = checkFreeSpaceForArchives(
+		store.basePath,
+		store.cachePath,
+		packedPayloadBytes(metas),
+		len(metas),
+		len(store.index)+len(metas),
+	); err != nil {
+		tapNotOk(tw, "check free space", err)
+		return err
+	}
+
+	// Split into chunks based on max pack size, or on content-defined
+	// boundaries.
 	maxPackSize := options.MaxPackSize
 	if maxPackSize == 0 {
 		maxPackSize = store.config.GetMaxPackSize()
 	}
 
-	chunks := splitBlobChunks(metas, maxPackSize)
+	chunks := store.splitBlobChunksV1(metas, maxPackSize)
 	totalChunks := len(chunks)
 
 	type chunkResult struct {
@@ -83,72 +130,38 @@ func (store inventoryArchiveV1) Pack(options PackOptions) (err error) {
 	}
 
 	var results []chunkResult
+	var added []inventory_archive.ManifestArchive
 
-	// Phase 2: Load blob data one chunk at a time, write archive, release.
+	// Phase 2: Stream each chunk's blobs into its archive, one blob at a time.
 	for chunkIdx, chunkMetas := range chunks {
 		if err = packContextCancelled(ctx); err != nil {
 			err = errors.Wrap(err)
 			return err
 		}
 
-		var blobs []packedBlob
-
-		for _, meta := range chunkMetas {
-			marklId, repool := store.defaultHash.GetBlobIdForHexString(
-				hex.EncodeToString(meta.digest),
-			)
-			idString := marklId.String()
-
-			reader, readErr := store.looseBlobStore.MakeBlobReader(marklId)
-			repool()
-
-			if readErr != nil {
-				if options.SkipMissingBlobs {
-					tapComment(tw, fmt.Sprintf("blob skipped: %s", idString))
-					continue
-				}
-
-				err = errors.Wrapf(readErr, "reading loose blob %x", meta.digest)
-				tapNotOk(tw, fmt.Sprintf("blob skipped: %s", idString), err)
-				return err
-			}
-
-			data, readAllErr := io.ReadAll(reader)
-			reader.Close()
-
-			if readAllErr != nil {
-				if options.SkipMissingBlobs {
-					tapComment(tw, fmt.Sprintf("blob skipped: %s", idString))
-					continue
-				}
-
-				err = errors.Wrapf(readAllErr, "reading loose blob data %x", meta.digest)
-				tapNotOk(tw, fmt.Sprintf("blob skipped: %s", idString), err)
-				return err
-			}
-
-			blobs = append(blobs, packedBlob{digest: meta.digest, data: data})
-		}
-
-		if len(blobs) == 0 {
-			continue
-		}
-
 		var rawSize uint64
-		for _, blob := range blobs {
-			rawSize += uint64(len(blob.data))
+		for _, meta := range chunkMetas {
+			rawSize += meta.size
 		}
 
-		dataPath, fullCount, deltaCount, packErr := store.packChunkArchiveV1(ctx, blobs)
+		dataPath, fullCount, deltaCount, packErr := store.packChunkArchiveV1(
+			ctx,
+			options,
+			chunkMetas,
+		)
 		if packErr != nil {
 			desc := fmt.Sprintf("write archive %d/%d", chunkIdx+1, totalChunks)
 			tapNotOk(tw, desc, packErr)
 			return packErr
 		}
 
+		if fullCount+deltaCount == 0 {
+			continue
+		}
+
 		archiveChecksum := strings.TrimSuffix(
 			filepath.Base(dataPath),
-			inventory_archive.DataFileExtensionV1,
+			filepath.Ext(dataPath),
 		)
 
 		entryCount := fullCount + deltaCount
@@ -178,13 +191,35 @@ func (store inventoryArchiveV1) Pack(options PackOptions) (err error) {
 			))
 		}
 
-		// Release blob data — let GC reclaim before next chunk.
-		blobs = nil
-
 		results = append(results, chunkResult{dataPath: dataPath, metas: chunkMetas})
+
+		archive, archiveErr := manifestArchiveForDataPath(dataPath, entryCount)
+		if archiveErr != nil {
+			return archiveErr
+		}
+
+		added = append(added, archive)
+	}
+
+	var manifestChanged bool
+
+	if len(added) > 0 {
+		if manifestChanged, err = store.commitManifest(added); err != nil {
+			tapNotOk(tw, "write manifest", err)
+			return err
+		}
+
+		tapOk(tw, "write manifest")
+	}
+
+	// Another process packed while this one did: pick up its archives too.
+	if manifestChanged {
+		if err = store.rebuildIndex(); err != nil {
+			tapNotOk(tw, "write cache", err)
+			return err
+		}
 	}
 
-	// Write cache from the full in-memory index.
 	if err = store.writeCacheV1(); err != nil {
 		tapNotOk(tw, "write cache", err)
 		return err
@@ -248,14 +283,24 @@ func (store inventoryArchiveV1) Pack(options PackOptions) (err error) {
 	return nil
 }
 
+// packChunkArchiveV1 streams the chunk's loose blobs into a new archive.
+// Full entries are streamed straight from the loose store; only delta
+// computation holds a base and target in memory at once (one window per
+// worker), and dictionary training loads only its bounded sample. It returns
+// an empty dataPath and no entries when every blob was skipped.
 func (store inventoryArchiveV1) packChunkArchiveV1(
 	ctx interfaces.ActiveContext,
-	blobs []packedBlob,
+	options PackOptions,
+	metas []packedBlobMeta,
 ) (dataPath string, fullCount int, deltaCount int, err error) {
 	hashFormatId := store.defaultHash.GetMarklFormatId()
 
+	readBlob := func(digest []byte) ([]byte, error) {
+		return readPackedBlob(store.looseBlobStore, store.defaultHash, digest)
+	}
+
 	// Phase 2: Select delta bases if delta is enabled.
-	deltaEnabled := store.config.GetDeltaEnabled()
+	deltaEnabled := store.deltaEnabled()
 
 	// assignments maps blob index -> base index (for deltas)
 	assignments := make(map[int]int)
@@ -284,9 +329,20 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 		sigConfig, hasSigConfig := store.config.(blob_store_configs.SignatureConfigImmutable)
 		selConfig, hasSelConfig := store.config.(blob_store_configs.SelectorConfigImmutable)
 
+		strategy, similarity, strategyErr := store.similarityStrategy()
+		if strategyErr != nil {
+			err = strategyErr
+			return dataPath, 0, 0, err
+		}
+
 		var selector inventory_archive.BaseSelector
 
-		if hasSelConfig && selConfig.GetSelectorType() != "" && selConfig.GetSelectorType() != "size-based" {
+		if similarity {
+			// Sketches were computed while collecting the loose blobs.
+			if selector, err = store.similaritySelector(strategy); err != nil {
+				return dataPath, 0, 0, err
+			}
+		} else if hasSelConfig && selConfig.GetSelectorType() != "" && selConfig.GetSelectorType() != "size-based" {
 			var selErr error
 			selector, selErr = inventory_archive.BaseSelectorForName(
 				selConfig.GetSelectorType(),
@@ -313,22 +369,23 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 
 		// Build BlobSet.
 		blobSet := &sliceBlobSet{
-			blobs: make([]inventory_archive.BlobMetadata, len(blobs)),
+			blobs: make([]inventory_archive.BlobMetadata, len(metas)),
 		}
 
-		for i, blob := range blobs {
+		for i, meta := range metas {
 			marklId, repool := store.defaultHash.GetBlobIdForHexString(
-				hex.EncodeToString(blob.digest),
+				hex.EncodeToString(meta.digest),
 			)
 			blobSet.blobs[i] = inventory_archive.BlobMetadata{
-				Id:   marklId,
-				Size: uint64(len(blob.data)),
+				Id:        marklId,
+				Size:      meta.size,
+				Signature: meta.sketch,
 			}
 			repool()
 		}
 
-		// Compute signatures if configured.
-		if hasSigConfig && sigConfig.GetSignatureType() != "" {
+		// Compute signatures if configured, streaming each blob.
+		if !similarity && hasSigConfig && sigConfig.GetSignatureType() != "" {
 			sigComputer, sigErr := inventory_archive.SignatureComputerForName(
 				sigConfig.GetSignatureType(),
 				inventory_archive.SignatureComputerParams{
@@ -344,10 +401,21 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 			}
 
 			if sigComputer != nil {
-				for i, blob := range blobs {
-					sig, compErr := sigComputer.ComputeSignature(
-						bytes.NewReader(blob.data),
+				for i, meta := range metas {
+					reader, openErr := openPackedBlob(
+						store.looseBlobStore,
+						store.defaultHash,
+						meta.digest,
 					)
+					if openErr != nil {
+						// Unreadable blobs get no signature; they are skipped
+						// or reported when written.
+						continue
+					}
+
+					sig, compErr := sigComputer.ComputeSignature(reader)
+					reader.Close()
+
 					if compErr != nil {
 						err = errors.Wrapf(compErr, "computing signature for blob %d", i)
 						return dataPath, 0, 0, err
@@ -368,7 +436,31 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 		isDelta[blobIdx] = true
 	}
 
-	ct := store.config.GetCompressionType()
+	compression := compression_type.Params{
+		Type: store.config.GetCompressionType(),
+	}
+
+	var minCompressionSavingsPercent int
+
+	var footerIndex bool
+
+	if footerIndexConfig, ok := store.config.(blob_store_configs.FooterIndexConfigImmutable); ok {
+		footerIndex = footerIndexConfig.GetFooterIndexEnabled()
+	}
+
+	if compressionConfig, ok := store.config.(blob_store_configs.CompressionConfigImmutable); ok {
+		compression.Level = compressionConfig.GetCompressionLevel()
+		minCompressionSavingsPercent = compressionConfig.GetCompressionMinSavingsPercent()
+
+		if compressionConfig.GetCompressionDictionaryEnabled() &&
+			compression.Type == compression_type.CompressionTypeZstd {
+			compression.Dictionary = trainArchiveDictionary(
+				metas,
+				compressionConfig.GetCompressionDictionaryMaxSize(),
+				readBlob,
+			)
+		}
+	}
 
 	// The hasDeltas flag will be set based on whether any deltas were
 	// actually written (some may fall back to full during trial-and-discard).
@@ -402,10 +494,22 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 		}
 	}()
 
-	dataWriter, err := inventory_archive.NewDataWriterV1(
-		tmpFile,
+	// Buffer the many small header and entry field writes; the buffer is
+	// flushed before the file is synced and renamed into place.
+	bufferedFile := bufio.NewWriter(tmpFile)
+
+	newDataWriter := inventory_archive.NewDataWriterV1WithCompression
+	dataFileExtension := inventory_archive.DataFileExtensionV1
+
+	if footerIndex {
+		newDataWriter = inventory_archive.NewDataWriterV2
+		dataFileExtension = inventory_archive.DataFileExtensionV2
+	}
+
+	dataWriter, err := newDataWriter(
+		bufferedFile,
 		hashFormatId,
-		ct,
+		compression,
 		flags,
 		store.encryption,
 	)
@@ -415,15 +519,64 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 		return dataPath, 0, 0, err
 	}
 
+	dataWriter.SetMinCompressionSavingsPercent(minCompressionSavingsPercent)
+	dataWriter.SetSpoolDir(store.archivesPath())
+
+	if chunkingConfig, ok := store.config.(blob_store_configs.ChunkingConfigImmutable); ok {
+		dataWriter.SetEntryAlignment(chunkingConfig.GetChunkingAlign())
+	}
+
+	// written tracks which blobs made it into the archive as full entries, so
+	// that no delta is written against a base that was skipped.
+	written := make(map[int]bool, len(metas))
+
+	writeFull := func(blobIdx int) (err error) {
+		meta := metas[blobIdx]
+
+		reader, openErr := openPackedBlob(
+			store.looseBlobStore,
+			store.defaultHash,
+			meta.digest,
+		)
+		if openErr != nil {
+			return skipUnreadablePackedBlob(
+				options,
+				store.defaultHash,
+				meta.digest,
+				openErr,
+			)
+		}
+
+		defer errors.DeferredCloser(&err, reader)
+
+		if err = dataWriter.WriteFullEntryFrom(
+			meta.digest,
+			reader,
+			meta.size,
+		); err != nil {
+			err = errors.Wrapf(err, "packing loose blob %x", meta.digest)
+			return err
+		}
+
+		written[blobIdx] = true
+
+		return err
+	}
+
 	// First pass: write all blobs NOT assigned as deltas (bases + unassigned).
-	for i, blob := range blobs {
+	for i := range metas {
 		if isDelta[i] {
 			continue
 		}
 
-		if writeErr := dataWriter.WriteFullEntry(blob.digest, blob.data); writeErr != nil {
+		if err = packContextCancelled(ctx); err != nil {
+			tmpFile.Close()
+			err = errors.Wrap(err)
+			return dataPath, 0, 0, err
+		}
+
+		if err = writeFull(i); err != nil {
 			tmpFile.Close()
-			err = errors.Wrap(writeErr)
 			return dataPath, 0, 0, err
 		}
 	}
@@ -443,7 +596,7 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 		})
 	}
 
-	// Sort by blobIdx for deterministic archive output. blobs is already
+	// Sort by blobIdx for deterministic archive output. metas is already
 	// digest-sorted, so blobIdx order preserves that ordering.
 	sort.Slice(orderedAssignments, func(i, j int) bool {
 		return orderedAssignments[i].blobIdx < orderedAssignments[j].blobIdx
@@ -473,52 +626,51 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 				defer wg.Done()
 				defer func() { <-sem }()
 
-				if packContextCancelled(ctx) != nil {
+				// A missing delta result means the blob is stored as full.
+				results[a.resultIdx] = deltaResult{
+					blobIdx: a.blobIdx,
+					baseIdx: a.baseIdx,
+				}
+
+				if packContextCancelled(ctx) != nil || !written[a.baseIdx] {
 					return
 				}
 
-				targetBlob := blobs[a.blobIdx]
-				baseBlob := blobs[a.baseIdx]
+				baseData, readErr := readBlob(metas[a.baseIdx].digest)
+				if readErr != nil {
+					return
+				}
+
+				targetData, readErr := readBlob(metas[a.blobIdx].digest)
+				if readErr != nil {
+					return
+				}
 
 				baseHash, _ := store.defaultHash.Get() //repool:owned
 				baseReader := markl_io.MakeReadCloser(
 					baseHash,
-					bytes.NewReader(baseBlob.data),
+					bytes.NewReader(baseData),
 				)
 
 				var deltaBuf bytes.Buffer
 
-				computeErr := alg.Compute(
+				if computeErr := alg.Compute(
 					baseReader,
-					int64(len(baseBlob.data)),
-					bytes.NewReader(targetBlob.data),
+					int64(len(baseData)),
+					bytes.NewReader(targetData),
 					&deltaBuf,
-				)
-				if computeErr != nil {
-					// Delta computation failed — store as full.
-					results[a.resultIdx] = deltaResult{
-						blobIdx: a.blobIdx,
-						baseIdx: a.baseIdx,
-					}
+				); computeErr != nil {
 					return
 				}
 
 				rawDelta := deltaBuf.Bytes()
 
 				// Trial-and-discard: if delta is not smaller, store as full.
-				if len(rawDelta) >= len(targetBlob.data) {
-					results[a.resultIdx] = deltaResult{
-						blobIdx: a.blobIdx,
-						baseIdx: a.baseIdx,
-					}
+				if len(rawDelta) >= len(targetData) {
 					return
 				}
 
-				results[a.resultIdx] = deltaResult{
-					blobIdx:   a.blobIdx,
-					baseIdx:   a.baseIdx,
-					deltaData: rawDelta,
-				}
+				results[a.resultIdx].deltaData = rawDelta
 			}(assignment)
 		}
 
@@ -539,17 +691,11 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 			return dataPath, 0, 0, err
 		}
 
-		targetBlob := blobs[dr.blobIdx]
-		baseBlob := blobs[dr.baseIdx]
-
 		if dr.deltaData == nil {
-			// Store as full entry (delta failed or was larger).
-			if writeErr := dataWriter.WriteFullEntry(
-				targetBlob.digest,
-				targetBlob.data,
-			); writeErr != nil {
+			// Store as full entry (delta failed, was larger, or its base
+			// was skipped).
+			if err = writeFull(dr.blobIdx); err != nil {
 				tmpFile.Close()
-				err = errors.Wrap(writeErr)
 				return dataPath, 0, 0, err
 			}
 
@@ -557,16 +703,24 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 		}
 
 		if writeErr := dataWriter.WriteDeltaEntry(
-			targetBlob.digest,
+			metas[dr.blobIdx].digest,
 			algByte,
-			baseBlob.digest,
-			uint64(len(targetBlob.data)),
+			metas[dr.baseIdx].digest,
+			metas[dr.blobIdx].size,
 			dr.deltaData,
 		); writeErr != nil {
 			tmpFile.Close()
 			err = errors.Wrap(writeErr)
 			return dataPath, 0, 0, err
 		}
+
+		written[dr.blobIdx] = true
+	}
+
+	if len(written) == 0 {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return "", 0, 0, nil
 	}
 
 	checksum, writtenEntries, err := dataWriter.Close()
@@ -576,8 +730,9 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 		return dataPath, 0, 0, err
 	}
 
-	if err = tmpFile.Close(); err != nil {
-		err = errors.Wrapf(err, "closing temp data file %s", tmpPath)
+	if err = bufferedFile.Flush(); err != nil {
+		tmpFile.Close()
+		err = errors.Wrapf(err, "writing temp data file %s", tmpPath)
 		return dataPath, 0, 0, err
 	}
 
@@ -585,102 +740,86 @@ func (store inventoryArchiveV1) packChunkArchiveV1(
 
 	dataPath = filepath.Join(
 		store.archivesPath(),
-		archiveChecksum+inventory_archive.DataFileExtensionV1,
+		archiveChecksum+dataFileExtension,
 	)
 
-	if err = os.Rename(tmpPath, dataPath); err != nil {
-		err = errors.Wrapf(err, "renaming temp data file to %s", dataPath)
+	if err = files.CommitTemp(tmpFile, dataPath); err != nil {
 		return dataPath, 0, 0, err
 	}
 
-	// Phase 4: Build and write index file.
-	// Build a map from hash hex -> offset in the data file for resolving
-	// base offsets in delta index entries.
-	hashHexToDataOffset := make(map[string]uint64, len(writtenEntries))
-	for _, de := range writtenEntries {
-		hashHexToDataOffset[hex.EncodeToString(de.Hash)] = de.Offset
-	}
-
-	indexEntries := make([]inventory_archive.IndexEntryV1, len(writtenEntries))
-	for i, de := range writtenEntries {
-		var baseOffset uint64
+	// Phase 4: Write the index file, unless the data file carries its own.
+	indexEntries := inventory_archive.IndexEntriesForDataEntriesV1(writtenEntries)
 
-		if de.EntryType == inventory_archive.EntryTypeDelta {
-			baseHashHex := hex.EncodeToString(de.BaseHash)
-			baseOffset = hashHexToDataOffset[baseHashHex]
-		}
+	if !footerIndex {
+		indexPath := filepath.Join(
+			store.archivesPath(),
+			archiveChecksum+inventory_archive.IndexFileExtensionV1,
+		)
 
-		indexEntries[i] = inventory_archive.IndexEntryV1{
-			Hash:       de.Hash,
-			PackOffset: de.Offset,
-			StoredSize: de.StoredSize,
-			EntryType:  de.EntryType,
-			BaseOffset: baseOffset,
+		if err = files.WriteAtomic(
+			indexPath,
+			func(writer io.Writer) (err error) {
+				_, err = inventory_archive.WriteIndexV1(
+					writer,
+					hashFormatId,
+					indexEntries,
+				)
+				return err
+			},
+		); err != nil {
+			return dataPath, 0, 0, err
 		}
 	}
 
-	// Sort index entries by hash for the fan-out table.
-	sort.Slice(indexEntries, func(i, j int) bool {
-		return bytes.Compare(indexEntries[i].Hash, indexEntries[j].Hash) < 0
-	})
-
-	var indexBuf bytes.Buffer
+	// Phase 4b: Write the bloom filter that answers lookups of blobs not in
+	// the archive.
+	bloomFilter := inventory_archive.MakeBloomFilterV1ForIndex(indexEntries)
 
-	if _, err = inventory_archive.WriteIndexV1(
-		&indexBuf,
-		hashFormatId,
-		indexEntries,
-	); err != nil {
-		err = errors.Wrap(err)
+	if err = store.writeBloomFilter(archiveChecksum, bloomFilter); err != nil {
 		return dataPath, 0, 0, err
 	}
 
-	indexPath := filepath.Join(
-		store.archivesPath(),
-		archiveChecksum+inventory_archive.IndexFileExtensionV1,
-	)
-
-	if err = os.WriteFile(indexPath, indexBuf.Bytes(), 0o644); err != nil {
-		err = errors.Wrapf(err, "writing v1 index file %s", indexPath)
-		return dataPath, 0, 0, err
-	}
+	store.blooms.add(archiveChecksum, bloomFilter)
 
 	// Phase 5: Update in-memory index and count entry types.
-	for _, de := range writtenEntries {
-		if de.EntryType == inventory_archive.EntryTypeDelta {
+	for _, ie := range indexEntries {
+		if ie.EntryType == inventory_archive.EntryTypeDelta {
 			deltaCount++
 		} else {
 			fullCount++
 		}
 
 		marklId, repool := store.defaultHash.GetBlobIdForHexString(
-			hex.EncodeToString(de.Hash),
+			hex.EncodeToString(ie.Hash),
 		)
 		key := marklId.String()
 		repool()
 
-		var baseOffset uint64
-		if de.EntryType == inventory_archive.EntryTypeDelta {
-			baseHashHex := hex.EncodeToString(de.BaseHash)
-			baseOffset = hashHexToDataOffset[baseHashHex]
-		}
-
 		store.index[key] = archiveEntryV1{
 			ArchiveChecksum: archiveChecksum,
-			Offset:          de.Offset,
-			StoredSize:      de.StoredSize,
-			EntryType:       de.EntryType,
-			BaseOffset:      baseOffset,
+			Offset:          ie.PackOffset,
+			StoredSize:      ie.StoredSize,
+			EntryType:       ie.EntryType,
+			BaseOffset:      ie.BaseOffset,
 		}
 	}
 
 	return dataPath, fullCount, deltaCount, nil
 }
 
+// writeCacheV1 writes the index to one cache file per hash format. The
+// default format's cache is always written, even when empty, since its
+// presence marks the cache as current.
 func (store inventoryArchiveV1) writeCacheV1() (err error) {
-	hashFormatId := store.defaultHash.GetMarklFormatId()
+	if err = errIfReadOnly(store.readOnly, store.cachePath); err != nil {
+		return err
+	}
+
+	defaultFormatId := store.defaultHash.GetMarklFormatId()
 
-	var allCacheEntries []inventory_archive.CacheEntryV1
+	cacheEntries := map[string][]inventory_archive.CacheEntryV1{
+		defaultFormatId: nil,
+	}
 
 	for key, entry := range store.index {
 		id, repool := store.defaultHash.GetBlobId()
@@ -689,6 +828,7 @@ func (store inventoryArchiveV1) writeCacheV1() (err error) {
 			continue
 		}
 
+		hashFormatId := id.GetMarklFormat().GetMarklFormatId()
 		hashBytes := make([]byte, len(id.GetBytes()))
 		copy(hashBytes, id.GetBytes())
 		repool()
@@ -698,49 +838,45 @@ func (store inventoryArchiveV1) writeCacheV1() (err error) {
 			continue
 		}
 
-		allCacheEntries = append(allCacheEntries, inventory_archive.CacheEntryV1{
-			Hash:            hashBytes,
-			ArchiveChecksum: archiveBytes,
-			Offset:          entry.Offset,
-			StoredSize:      entry.StoredSize,
-			EntryType:       entry.EntryType,
-			BaseOffset:      entry.BaseOffset,
-		})
+		cacheEntries[hashFormatId] = append(
+			cacheEntries[hashFormatId],
+			inventory_archive.CacheEntryV1{
+				Hash:            hashBytes,
+				ArchiveChecksum: archiveBytes,
+				Offset:          entry.Offset,
+				StoredSize:      entry.StoredSize,
+				EntryType:       entry.EntryType,
+				BaseOffset:      entry.BaseOffset,
+			},
+		)
 	}
 
-	sort.Slice(allCacheEntries, func(i, j int) bool {
-		return bytes.Compare(
-			allCacheEntries[i].Hash,
-			allCacheEntries[j].Hash,
-		) < 0
-	})
-
 	if err = os.MkdirAll(store.cachePath, 0o755); err != nil {
 		err = errors.Wrapf(err, "creating cache directory %s", store.cachePath)
 		return err
 	}
 
-	cachePath := filepath.Join(
-		store.cachePath,
-		inventory_archive.CacheFileNameV1,
-	)
+	for hashFormatId, entries := range cacheEntries {
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].Hash, entries[j].Hash) < 0
+		})
 
-	cacheFile, err := os.Create(cachePath)
-	if err != nil {
-		err = errors.Wrapf(err, "creating v1 cache file %s", cachePath)
-		return err
+		if err = files.WriteAtomic(
+			store.cacheFilePathForFormat(hashFormatId),
+			func(writer io.Writer) (err error) {
+				_, err = inventory_archive.WriteCacheV1(
+					writer,
+					hashFormatId,
+					entries,
+				)
+				return err
+			},
+		); err != nil {
+			return err
+		}
 	}
 
-	defer errors.DeferredCloser(&err, cacheFile)
-
-	if _, err = inventory_archive.WriteCacheV1(
-		cacheFile,
-		hashFormatId,
-		allCacheEntries,
-	); err != nil {
-		err = errors.Wrapf(err, "writing v1 cache file %s", cachePath)
-		return err
-	}
+	store.removeStaleCaches()
 
 	return nil
 }
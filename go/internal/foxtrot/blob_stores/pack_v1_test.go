@@ -7,9 +7,11 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -282,6 +284,25 @@ func TestPackV1WithoutDelta(t *testing.T) {
 			t.Errorf("%s data mismatch", tc.name)
 		}
 	}
+
+	// Verify only the committed archive files remain, no temp files.
+	archiveFiles, err := os.ReadDir(store.archivesPath())
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+
+	if len(archiveFiles) != 3 {
+		t.Fatalf(
+			"expected data, index and bloom filter file, got %d files",
+			len(archiveFiles),
+		)
+	}
+
+	for _, archiveFile := range archiveFiles {
+		if strings.HasSuffix(archiveFile.Name(), ".tmp") {
+			t.Errorf("leftover temp file %s", archiveFile.Name())
+		}
+	}
 }
 
 func TestPackV1DeltaFallsBackToFullWhenLarger(t *testing.T) {
@@ -493,3 +514,359 @@ func TestPackV1SplitsWhenExceedingMaxPackSize(t *testing.T) {
 		t.Fatalf("expected at least 2 data files (split), got %d", len(dataMatches))
 	}
 }
+
+func TestPackV1WithDictionary(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+
+	var blobIds []domain_interfaces.MarklId
+	blobData := make(map[string][]byte)
+
+	for i := range 200 {
+		data := []byte(fmt.Sprintf(
+			"---\n! toml-tag-v1\n---\n\n"+
+				"description = 'a tag describing project number %d'\n"+
+				"hidden = false\nrecursive = true\n",
+			i,
+		))
+
+		rawHash := sha256.Sum256(data)
+		id, repool := hashFormat.GetBlobIdForHexString(
+			hex.EncodeToString(rawHash[:]),
+		)
+		defer repool()
+
+		blobIds = append(blobIds, id)
+		blobData[id.String()] = data
+	}
+
+	packWith := func(dictionary bool) (inventoryArchiveV1, int64) {
+		basePath := t.TempDir()
+
+		store := inventoryArchiveV1{
+			defaultHash: hashFormat,
+			basePath:    basePath,
+			cachePath:   t.TempDir(),
+			looseBlobStore: &stubBlobStore{
+				allBlobIds: blobIds,
+				blobData:   blobData,
+			},
+			index: make(map[string]archiveEntryV1),
+			config: blob_store_configs.TomlInventoryArchiveV2{
+				HashTypeId:      markl.FormatIdHashSha256,
+				CompressionType: compression_type.CompressionTypeZstd,
+				Compression: blob_store_configs.CompressionConfig{
+					Level:      3,
+					Dictionary: dictionary,
+				},
+			},
+		}
+
+		if err := store.Pack(PackOptions{}); err != nil {
+			t.Fatalf("Pack: %v", err)
+		}
+
+		dataMatches, err := filepath.Glob(filepath.Join(
+			store.archivesPath(),
+			"*"+inventory_archive.DataFileExtensionV1,
+		))
+		if err != nil || len(dataMatches) != 1 {
+			t.Fatalf("expected one data file, got %v (%v)", dataMatches, err)
+		}
+
+		info, err := os.Stat(dataMatches[0])
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+
+		return store, info.Size()
+	}
+
+	plainStore, plainSize := packWith(false)
+	store, size := packWith(true)
+
+	dataMatches, err := filepath.Glob(filepath.Join(
+		store.archivesPath(),
+		"*"+inventory_archive.DataFileExtensionV1,
+	))
+	if err != nil || len(dataMatches) != 1 {
+		t.Fatalf("expected one data file, got %v (%v)", dataMatches, err)
+	}
+
+	dataFile, err := os.Open(dataMatches[0])
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	defer dataFile.Close()
+
+	// the dictionary is embedded in the archive rather than stored next to it
+	dataReader, err := inventory_archive.NewDataReaderV1(dataFile, nil)
+	if err != nil {
+		t.Fatalf("NewDataReaderV1: %v", err)
+	}
+
+	if dataReader.DictionaryId() == 0 {
+		t.Error("expected the archive to use a dictionary")
+	}
+
+	archiveFiles, err := os.ReadDir(store.archivesPath())
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+
+	for _, archiveFile := range archiveFiles {
+		if strings.Contains(archiveFile.Name(), "dictionary") {
+			t.Errorf("unexpected dictionary file %s", archiveFile.Name())
+		}
+	}
+
+	if size >= plainSize {
+		t.Errorf(
+			"expected the dictionary to shrink the archive: %d >= %d",
+			size,
+			plainSize,
+		)
+	}
+
+	t.Logf("archive size without dictionary: %d, with: %d", plainSize, size)
+
+	for _, readStore := range []inventoryArchiveV1{plainStore, store} {
+		for i, id := range blobIds {
+			reader, err := readStore.MakeBlobReader(id)
+			if err != nil {
+				t.Fatalf("MakeBlobReader for blob %d: %v", i, err)
+			}
+
+			got, err := io.ReadAll(reader)
+			reader.Close()
+
+			if err != nil {
+				t.Fatalf("ReadAll for blob %d: %v", i, err)
+			}
+
+			if !bytes.Equal(got, blobData[id.String()]) {
+				t.Errorf("blob %d data mismatch", i)
+			}
+		}
+	}
+}
+
+func TestPackV2WithFooterIndex(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+
+	var blobIds []domain_interfaces.MarklId
+	blobData := make(map[string][]byte)
+
+	base := bytes.Repeat([]byte("shared content for footer index deltas "), 32)
+
+	for i := range 4 {
+		data := append(bytes.Clone(base), fmt.Sprintf("variant %d", i)...)
+
+		rawHash := sha256.Sum256(data)
+		id, repool := hashFormat.GetBlobIdForHexString(
+			hex.EncodeToString(rawHash[:]),
+		)
+		defer repool()
+
+		blobIds = append(blobIds, id)
+		blobData[id.String()] = data
+	}
+
+	store := inventoryArchiveV1{
+		defaultHash: hashFormat,
+		basePath:    t.TempDir(),
+		cachePath:   t.TempDir(),
+		looseBlobStore: &stubBlobStore{
+			allBlobIds: blobIds,
+			blobData:   blobData,
+		},
+		index: make(map[string]archiveEntryV1),
+		config: blob_store_configs.TomlInventoryArchiveV2{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeZstd,
+			FooterIndex:     true,
+			Delta: blob_store_configs.DeltaConfig{
+				Enabled:     true,
+				Algorithm:   "bsdiff",
+				MinBlobSize: 1,
+				MaxBlobSize: 10485760,
+				SizeRatio:   2.0,
+			},
+		},
+	}
+
+	if err := store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	archiveFiles, err := os.ReadDir(store.archivesPath())
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+
+	if len(archiveFiles) != 2 ||
+		!strings.HasSuffix(
+			archiveFiles[0].Name(),
+			inventory_archive.DataFileExtensionV2,
+		) ||
+		!strings.HasSuffix(
+			archiveFiles[1].Name(),
+			inventory_archive.BloomFileExtensionV1,
+		) {
+		t.Fatalf(
+			"expected a single v2 data file and its bloom filter, got %v",
+			archiveFiles,
+		)
+	}
+
+	packedIndex := store.index
+
+	// a store without a cache rebuilds its index from the footer
+	store.index = make(map[string]archiveEntryV1)
+
+	if err := store.rebuildIndex(); err != nil {
+		t.Fatalf("rebuildIndex: %v", err)
+	}
+
+	if len(store.index) != len(packedIndex) {
+		t.Fatalf(
+			"expected %d rebuilt index entries, got %d",
+			len(packedIndex),
+			len(store.index),
+		)
+	}
+
+	var deltaCount int
+
+	for key, entry := range packedIndex {
+		if store.index[key] != entry {
+			t.Errorf("rebuilt index entry for %s does not match", key)
+		}
+
+		if entry.EntryType == inventory_archive.EntryTypeDelta {
+			deltaCount++
+		}
+	}
+
+	if deltaCount == 0 {
+		t.Error("expected at least one delta entry")
+	}
+
+	for i, id := range blobIds {
+		reader, err := store.MakeBlobReader(id)
+		if err != nil {
+			t.Fatalf("MakeBlobReader for blob %d: %v", i, err)
+		}
+
+		got, err := io.ReadAll(reader)
+		reader.Close()
+
+		if err != nil {
+			t.Fatalf("ReadAll for blob %d: %v", i, err)
+		}
+
+		if !bytes.Equal(got, blobData[id.String()]) {
+			t.Errorf("blob %d data mismatch", i)
+		}
+	}
+}
+
+func TestPackV2ChunkingIsReproducible(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+
+	var blobIds []domain_interfaces.MarklId
+	blobData := make(map[string][]byte)
+
+	for i := range 32 {
+		data := []byte(fmt.Sprintf("chunked blob %d %s", i, strings.Repeat("x", i*10)))
+
+		rawHash := sha256.Sum256(data)
+		id, repool := hashFormat.GetBlobIdForHexString(
+			hex.EncodeToString(rawHash[:]),
+		)
+		defer repool()
+
+		blobIds = append(blobIds, id)
+		blobData[id.String()] = data
+	}
+
+	const align = 512
+
+	packStore := func() inventoryArchiveV1 {
+		store := inventoryArchiveV1{
+			defaultHash: hashFormat,
+			basePath:    t.TempDir(),
+			cachePath:   t.TempDir(),
+			looseBlobStore: &stubBlobStore{
+				allBlobIds: blobIds,
+				blobData:   blobData,
+			},
+			index: make(map[string]archiveEntryV1),
+			config: blob_store_configs.TomlInventoryArchiveV2{
+				HashTypeId:      markl.FormatIdHashSha256,
+				CompressionType: compression_type.CompressionTypeZstd,
+				FooterIndex:     true,
+				Chunking: blob_store_configs.ChunkingConfig{
+					ContentDefined: true,
+					AvgArchiveSize: 1024,
+					Align:          align,
+				},
+			},
+		}
+
+		if err := store.Pack(PackOptions{}); err != nil {
+			t.Fatalf("Pack: %v", err)
+		}
+
+		return store
+	}
+
+	archiveNames := func(store inventoryArchiveV1) (names []string) {
+		archiveFiles, err := os.ReadDir(store.archivesPath())
+		if err != nil {
+			t.Fatalf("ReadDir: %v", err)
+		}
+
+		for _, archiveFile := range archiveFiles {
+			names = append(names, archiveFile.Name())
+		}
+
+		return names
+	}
+
+	store := packStore()
+	names := archiveNames(store)
+
+	if len(names) < 2 {
+		t.Fatalf("expected several archives, got %v", names)
+	}
+
+	// archives are named by their checksum, so equal names mean equal bytes
+	if otherNames := archiveNames(packStore()); !slices.Equal(names, otherNames) {
+		t.Errorf("expected the same archives, got %v and %v", names, otherNames)
+	}
+
+	for key, entry := range store.index {
+		if entry.Offset%align != 0 {
+			t.Errorf("entry for %s at unaligned offset %d", key, entry.Offset)
+		}
+	}
+
+	for i, id := range blobIds {
+		reader, err := store.MakeBlobReader(id)
+		if err != nil {
+			t.Fatalf("MakeBlobReader for blob %d: %v", i, err)
+		}
+
+		got, err := io.ReadAll(reader)
+		reader.Close()
+
+		if err != nil {
+			t.Fatalf("ReadAll for blob %d: %v", i, err)
+		}
+
+		if !bytes.Equal(got, blobData[id.String()]) {
+			t.Errorf("blob %d data mismatch", i)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package blob_stores
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// isReadOnly is true when writes to a store have to fail with
+// env_dir.ErrReadOnly: under the global -read-only flag, or when the store's
+// config marks it read-only.
+func isReadOnly(envDir env_dir.Env, config any) bool {
+	if envDir.IsReadOnly() {
+		return true
+	}
+
+	configReadOnly, ok := config.(blob_store_configs.ConfigReadOnly)
+
+	return ok && configReadOnly.IsReadOnly()
+}
+
+// errIfReadOnly returns env_dir.ErrReadOnly for the store at path when it is
+// read-only. Writes check it before taking the store lock, since the lock file
+// cannot be created on a read-only mount.
+func errIfReadOnly(readOnly bool, path string) (err error) {
+	if readOnly {
+		err = errors.Wrap(env_dir.ErrReadOnly{Path: path})
+	}
+
+	return err
+}
@@ -0,0 +1,132 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func assertErrReadOnly(t *testing.T, operation string, err error) {
+	t.Helper()
+
+	if !env_dir.IsErrReadOnly(err) {
+		t.Fatalf("%s: expected ErrReadOnly, got %v", operation, err)
+	}
+
+	if code := errors.GetCode(err); code != errors.CodeReadOnly {
+		t.Errorf("%s: expected code %s, got %s", operation, errors.CodeReadOnly, code)
+	}
+}
+
+func TestReadOnlyLocalRefusesWrites(t *testing.T) {
+	store := makeTestLocalHashBucketed(t)
+	id := writeTestBlob(t, store, "written before the store went read-only")
+
+	store.readOnly = true
+
+	_, err := store.MakeBlobWriter(nil)
+	assertErrReadOnly(t, "MakeBlobWriter", err)
+
+	assertErrReadOnly(t, "DeleteBlob", store.DeleteBlob(id))
+
+	_, err = store.LinkBlobFrom(makeTestLocalHashBucketed(t), id)
+	assertErrReadOnly(t, "LinkBlobFrom", err)
+
+	if !store.HasBlob(id) {
+		t.Fatal("expected the blob to stay in the store")
+	}
+
+	reader, err := store.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	defer reader.Close()
+
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+}
+
+func TestReadOnlyArchiveRefusesPackAndSkipsCacheWrites(t *testing.T) {
+	packed, loose, ids := packTestBlobsV1(
+		t,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+		"archived before the store went read-only",
+	)
+
+	store := makeTestStoreV1ForHash(
+		packed.basePath,
+		t.TempDir(),
+		markl.FormatHashSha256,
+		loose,
+	)
+
+	store.readOnly = true
+
+	if err := store.loadIndex(); err != nil {
+		t.Fatalf("loadIndex: %v", err)
+	}
+
+	if entries, err := os.ReadDir(store.cachePath); err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	} else if len(entries) != 0 {
+		t.Errorf("expected no cache to be written, got %d files", len(entries))
+	}
+
+	if !store.HasBlob(ids[0]) {
+		t.Fatalf("expected %s to be readable from the archive", ids[0])
+	}
+
+	_, err := store.MakeBlobWriter(nil)
+	assertErrReadOnly(t, "MakeBlobWriter", err)
+
+	assertErrReadOnly(t, "Pack", store.Pack(PackOptions{}))
+	assertErrReadOnly(t, "Unpack", store.Unpack(ids[0]))
+	assertErrReadOnly(t, "writeCacheV1", store.writeCacheV1())
+
+	if loose.HasBlob(ids[0]) {
+		t.Errorf("expected %s to stay archived only", ids[0])
+	}
+}
+
+func TestReadOnlyLazyParentReadsWithoutCaching(t *testing.T) {
+	parent := makeTestLocalHashBucketed(t)
+	id := writeTestBlob(t, parent, "only in the parent")
+
+	local := makeTestLocalHashBucketed(t)
+	local.readOnly = true
+
+	store := makeLazyParent(local, parent)
+
+	reader, err := store.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	actual, err := io.ReadAll(reader)
+	reader.Close()
+
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if string(actual) != "only in the parent" {
+		t.Errorf("expected the parent's blob, got %q", actual)
+	}
+
+	if store.local.HasBlob(id) {
+		t.Error("expected no local copy of the blob")
+	}
+}
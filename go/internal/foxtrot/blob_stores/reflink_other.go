@@ -0,0 +1,14 @@
+//go:build !linux
+
+package blob_stores
+
+import (
+	"os"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func reflinkFile(dst, src *os.File) (err error) {
+	err = errors.Errorf("reflinks are not supported on this platform")
+	return err
+}
@@ -0,0 +1,309 @@
+package blob_stores
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// BlobStoreRoute sends the blobs it matches to BlobStore: blobs of at least
+// MinSize bytes, of less than MaxSize bytes unless it is zero, and of objects
+// of one of Types (without the `!`) unless it is empty.
+type BlobStoreRoute struct {
+	BlobStore BlobStoreInitialized
+	Types     []string
+	MinSize   uint64
+	MaxSize   uint64
+}
+
+func (route BlobStoreRoute) matchesType(tipe string) bool {
+	return len(route.Types) == 0 || slices.Contains(route.Types, tipe)
+}
+
+func (route BlobStoreRoute) matchesSize(size uint64) bool {
+	return size >= route.MinSize && (route.MaxSize == 0 || size < route.MaxSize)
+}
+
+func (route BlobStoreRoute) isSized() bool {
+	return route.MinSize > 0 || route.MaxSize > 0
+}
+
+// Routing writes each blob of an object of a known type to the store of the
+// first route that matches it, or to Default, and reads blobs from whichever
+// of them has them. Blobs written without a type, like the konfig's and
+// inventory lists', always go to Default, which is where they are read from
+// before the routes are known. Sizes are only known once enough of a blob is
+// written, so a writer keeps blobs in memory until they reach the largest size
+// any of its routes checks, and then commits to a store.
+type Routing struct {
+	Default BlobStoreInitialized
+	Routes  []BlobStoreRoute
+}
+
+var (
+	_ domain_interfaces.BlobStore              = Routing{}
+	_ domain_interfaces.TypedBlobWriterFactory = Routing{}
+	_ domain_interfaces.BlobForeignDigestAdder = Routing{}
+)
+
+// getStores returns Default and the stores of the routes, each once.
+func (routing Routing) getStores() []BlobStoreInitialized {
+	stores := []BlobStoreInitialized{routing.Default}
+	seen := map[string]struct{}{
+		routing.Default.Path.GetId().String(): {},
+	}
+
+	for _, route := range routing.Routes {
+		idString := route.BlobStore.Path.GetId().String()
+
+		if _, ok := seen[idString]; ok {
+			continue
+		}
+
+		seen[idString] = struct{}{}
+		stores = append(stores, route.BlobStore)
+	}
+
+	return stores
+}
+
+func (routing Routing) GetBlobStoreDescription() string {
+	return fmt.Sprintf(
+		"%s, routing to %d other blob stores",
+		routing.Default.GetBlobStoreDescription(),
+		len(routing.getStores())-1,
+	)
+}
+
+func (routing Routing) GetBlobIOWrapper() domain_interfaces.BlobIOWrapper {
+	return routing.Default.GetBlobIOWrapper()
+}
+
+func (routing Routing) GetDefaultHashType() domain_interfaces.FormatHash {
+	return routing.Default.GetDefaultHashType()
+}
+
+func (routing Routing) HasBlob(id domain_interfaces.MarklId) bool {
+	for _, store := range routing.getStores() {
+		if store.HasBlob(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (routing Routing) AllBlobs() interfaces.SeqError[domain_interfaces.MarklId] {
+	return func(yield func(domain_interfaces.MarklId, error) bool) {
+		seen := make(map[string]struct{})
+
+		for _, store := range routing.getStores() {
+			for id, err := range store.AllBlobs() {
+				if err != nil {
+					if !yield(nil, err) {
+						return
+					}
+
+					continue
+				}
+
+				key := id.String()
+
+				if _, ok := seen[key]; ok {
+					continue
+				}
+
+				seen[key] = struct{}{}
+
+				if !yield(id, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (routing Routing) MakeBlobReader(
+	id domain_interfaces.MarklId,
+) (domain_interfaces.BlobReader, error) {
+	for _, store := range routing.getStores() {
+		if store.HasBlob(id) {
+			return store.MakeBlobReader(id)
+		}
+	}
+
+	return routing.Default.MakeBlobReader(id)
+}
+
+// AddForeignBlobDigestForNativeDigest adds the digest to the store that has
+// the native blob.
+func (routing Routing) AddForeignBlobDigestForNativeDigest(
+	foreign, native domain_interfaces.MarklId,
+) (err error) {
+	for _, store := range routing.getStores() {
+		adder, ok := store.BlobStore.(domain_interfaces.BlobForeignDigestAdder)
+
+		if !ok || !store.HasBlob(native) {
+			continue
+		}
+
+		return adder.AddForeignBlobDigestForNativeDigest(foreign, native)
+	}
+
+	return err
+}
+
+func (routing Routing) MakeBlobWriter(
+	hashType domain_interfaces.FormatHash,
+) (domain_interfaces.BlobWriter, error) {
+	return routing.Default.MakeBlobWriter(hashType)
+}
+
+// MakeTypedBlobWriter returns a writer for a blob of an object of tipe, or
+// one for Default when tipe is empty.
+func (routing Routing) MakeTypedBlobWriter(
+	hashType domain_interfaces.FormatHash,
+	tipe string,
+) (domain_interfaces.BlobWriter, error) {
+	tipe = strings.TrimPrefix(tipe, "!")
+
+	if tipe == "" {
+		return routing.MakeBlobWriter(hashType)
+	}
+
+	// blobs get the same digest whichever store they are routed to
+	if hashType == nil {
+		hashType = routing.Default.GetDefaultHashType()
+	}
+
+	writer := &routingBlobWriter{
+		routing:  routing,
+		hashType: hashType,
+	}
+
+	for _, route := range routing.Routes {
+		if !route.matchesType(tipe) {
+			continue
+		}
+
+		writer.routes = append(writer.routes, route)
+		writer.threshold = max(writer.threshold, route.MinSize, route.MaxSize)
+	}
+
+	// without any size to check, the first route matches every blob
+	if len(writer.routes) == 0 || !writer.routes[0].isSized() {
+		if err := writer.commit(0); err != nil {
+			err = errors.Wrap(err)
+			return nil, err
+		}
+	}
+
+	return writer, nil
+}
+
+// MakeTypedBlobWriter makes a writer for a blob of an object of tipe when
+// factory routes blobs by type, and an untyped one otherwise.
+func MakeTypedBlobWriter(
+	factory domain_interfaces.BlobWriterFactory,
+	hashType domain_interfaces.FormatHash,
+	tipe string,
+) (domain_interfaces.BlobWriter, error) {
+	if typed, ok := factory.(domain_interfaces.TypedBlobWriterFactory); ok {
+		return typed.MakeTypedBlobWriter(hashType, tipe)
+	}
+
+	return factory.MakeBlobWriter(hashType)
+}
+
+type routingBlobWriter struct {
+	routing  Routing
+	hashType domain_interfaces.FormatHash
+
+	// the routes matching the blob's type, and the largest size they check
+	routes    []BlobStoreRoute
+	threshold uint64
+
+	buffer    bytes.Buffer
+	committed domain_interfaces.BlobWriter
+}
+
+var _ domain_interfaces.BlobWriter = &routingBlobWriter{}
+
+// commit picks the store of a blob of size bytes, which is exact for blobs
+// under the threshold and a lower bound otherwise, and writes the buffered
+// bytes to it.
+func (writer *routingBlobWriter) commit(size uint64) (err error) {
+	store := writer.routing.Default
+
+	for _, route := range writer.routes {
+		if route.matchesSize(size) {
+			store = route.BlobStore
+			break
+		}
+	}
+
+	if writer.committed, err = store.MakeBlobWriter(writer.hashType); err != nil {
+		err = errors.Wrapf(err, "blob store %s", store.Path.GetId())
+		return err
+	}
+
+	if _, err = writer.buffer.WriteTo(writer.committed); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (writer *routingBlobWriter) Write(bites []byte) (n int, err error) {
+	if writer.committed != nil {
+		return writer.committed.Write(bites)
+	}
+
+	n, _ = writer.buffer.Write(bites)
+
+	if uint64(writer.buffer.Len()) >= writer.threshold {
+		if err = writer.commit(uint64(writer.buffer.Len())); err != nil {
+			return n, err
+		}
+	}
+
+	return n, err
+}
+
+func (writer *routingBlobWriter) ReadFrom(reader io.Reader) (n int64, err error) {
+	if n, err = io.Copy(struct{ io.Writer }{writer}, reader); err != nil {
+		err = errors.Wrap(err)
+		return n, err
+	}
+
+	return n, err
+}
+
+func (writer *routingBlobWriter) Close() (err error) {
+	if writer.committed == nil {
+		if err = writer.commit(uint64(writer.buffer.Len())); err != nil {
+			return err
+		}
+	}
+
+	return writer.committed.Close()
+}
+
+// GetMarklId commits the blob if it is still buffered, since callers may ask
+// for the digest before closing.
+func (writer *routingBlobWriter) GetMarklId() domain_interfaces.MarklId {
+	if writer.committed == nil {
+		if err := writer.commit(uint64(writer.buffer.Len())); err != nil {
+			panic(err)
+		}
+	}
+
+	return writer.committed.GetMarklId()
+}
@@ -0,0 +1,126 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/directory_layout"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+)
+
+func makeTestRoutedStore(
+	t *testing.T,
+	id string,
+) (BlobStoreInitialized, localHashBucketed) {
+	store := makeTestLocalHashBucketed(t)
+
+	return BlobStoreInitialized{
+		ConfigNamed: blob_store_configs.ConfigNamed{
+			Path: directory_layout.MakeBlobStorePath(
+				blob_store_id.Make(id),
+				store.basePath,
+				"",
+			),
+		},
+		BlobStore: store,
+	}, store
+}
+
+func writeTestRoutedBlob(
+	t *testing.T,
+	routing Routing,
+	tipe string,
+	data string,
+) domain_interfaces.MarklId {
+	t.Helper()
+
+	writer, err := MakeTypedBlobWriter(routing, nil, tipe)
+	if err != nil {
+		t.Fatalf("MakeTypedBlobWriter: %v", err)
+	}
+
+	// written in pieces to cross the threshold within one blob
+	for _, piece := range []string{data[:len(data)/2], data[len(data)/2:]} {
+		if _, err := writer.Write([]byte(piece)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	id := writer.GetMarklId()
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	return id
+}
+
+func TestRoutingRoutesBySizeAndType(t *testing.T) {
+	defaultStore, defaultLocal := makeTestRoutedStore(t, "default")
+	largeStore, largeLocal := makeTestRoutedStore(t, "large")
+	imagesStore, imagesLocal := makeTestRoutedStore(t, "images")
+
+	routing := Routing{
+		Default: defaultStore,
+		Routes: []BlobStoreRoute{
+			{BlobStore: imagesStore, Types: []string{"png"}, MinSize: 16},
+			{BlobStore: largeStore, MinSize: 32},
+		},
+	}
+
+	small := writeTestRoutedBlob(t, routing, "!txt", "small")
+	large := writeTestRoutedBlob(t, routing, "!txt", strings.Repeat("large", 10))
+	untyped := writeTestRoutedBlob(t, routing, "", strings.Repeat("untyped", 10))
+	smallImage := writeTestRoutedBlob(t, routing, "!png", "tiny png")
+	image := writeTestRoutedBlob(t, routing, "!png", strings.Repeat("png", 10))
+
+	for _, testCase := range []struct {
+		name  string
+		id    domain_interfaces.MarklId
+		store localHashBucketed
+	}{
+		{"small", small, defaultLocal},
+		{"large", large, largeLocal},
+		{"small image", smallImage, defaultLocal},
+		{"image", image, imagesLocal},
+		{"untyped", untyped, defaultLocal},
+	} {
+		if !testCase.store.HasBlob(testCase.id) {
+			t.Errorf("%s: expected the blob in its routed store", testCase.name)
+		}
+
+		reader, err := routing.MakeBlobReader(testCase.id)
+		if err != nil {
+			t.Fatalf("%s: MakeBlobReader: %v", testCase.name, err)
+		}
+
+		if _, err := io.ReadAll(reader); err != nil {
+			t.Errorf("%s: ReadAll: %v", testCase.name, err)
+		}
+
+		reader.Close()
+	}
+
+	if defaultLocal.HasBlob(image) || largeLocal.HasBlob(image) {
+		t.Errorf("expected the image only in the images store")
+	}
+
+	count := 0
+
+	for _, err := range routing.AllBlobs() {
+		if err != nil {
+			t.Fatalf("AllBlobs: %v", err)
+		}
+
+		count++
+	}
+
+	if count != 5 {
+		t.Errorf("expected 5 blobs across the stores, got %d", count)
+	}
+}
@@ -0,0 +1,432 @@
+package blob_stores
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+const fileNameEncryptedIndex = "index"
+
+// encrypted stores age-encrypted blobs in an inner store. The inner store
+// identifies each blob by the digest of its ciphertext, so the plaintext id of
+// every blob is mapped to its ciphertext id in an append-only index kept in
+// this store's base directory. The inner store can therefore live on
+// untrusted storage, but the index is needed to read anything back.
+//
+// During a dry run the inner store keeps its writes aside, and the index only
+// records their ids in memory.
+type encrypted struct {
+	config            blob_store_configs.ConfigEncrypted
+	defaultHashFormat markl.FormatHash
+	inner             domain_interfaces.BlobStore
+	index             *encryptedIndex
+}
+
+var _ domain_interfaces.BlobStore = encrypted{}
+
+func makeEncrypted(
+	basePath string,
+	config blob_store_configs.ConfigEncrypted,
+	inner domain_interfaces.BlobStore,
+	dryRun bool,
+) (store encrypted, err error) {
+	if config.GetBlobEncryption().IsNull() {
+		err = errors.BadRequestf(
+			"encrypted blob store requires at least one encryption key",
+		)
+
+		return store, err
+	}
+
+	store.config = config
+	store.inner = inner
+
+	if store.defaultHashFormat, err = markl.GetFormatHashOrError(
+		config.GetDefaultHashTypeId(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return store, err
+	}
+
+	if store.index, err = readEncryptedIndex(
+		filepath.Join(basePath, fileNameEncryptedIndex),
+	); err != nil {
+		err = errors.Wrap(err)
+		return store, err
+	}
+
+	store.index.dryRun = dryRun
+
+	return store, err
+}
+
+func (blobStore encrypted) GetBlobStoreDescription() string {
+	return fmt.Sprintf("encrypted %s", blobStore.inner.GetBlobStoreDescription())
+}
+
+func (blobStore encrypted) GetBlobIOWrapper() domain_interfaces.BlobIOWrapper {
+	return blobStore.config
+}
+
+func (blobStore encrypted) GetDefaultHashType() domain_interfaces.FormatHash {
+	return blobStore.defaultHashFormat
+}
+
+func (blobStore encrypted) makeEnvDirConfig(
+	hashFormat domain_interfaces.FormatHash,
+) env_dir.Config {
+	if hashFormat == nil {
+		hashFormat = blobStore.defaultHashFormat
+	}
+
+	return env_dir.MakeConfig(
+		hashFormat,
+		nil,
+		blobStore.config.GetBlobCompression(),
+		blobStore.config.GetBlobEncryption(),
+	)
+}
+
+func (blobStore encrypted) HasBlob(id domain_interfaces.MarklId) (ok bool) {
+	defer observeLookup(blobStore, time.Now(), &ok)
+
+	if id.IsNull() {
+		ok = true
+		return ok
+	}
+
+	ciphertextId, ok := blobStore.index.get(id)
+	ok = ok && blobStore.inner.HasBlob(ciphertextId)
+
+	return ok
+}
+
+func (blobStore encrypted) AllBlobs() interfaces.SeqError[domain_interfaces.MarklId] {
+	return blobStore.index.all()
+}
+
+func (blobStore encrypted) MakeBlobReader(
+	id domain_interfaces.MarklId,
+) (reader domain_interfaces.BlobReader, err error) {
+	defer observeBlobReader(blobStore, time.Now(), &reader, &err)
+
+	if id.IsNull() {
+		return env_dir.NewNopReader()
+	}
+
+	ciphertextId, ok := blobStore.index.get(id)
+
+	if !ok || !blobStore.inner.HasBlob(ciphertextId) {
+		clonedId, _ := markl.Clone(id)
+		err = env_dir.ErrBlobMissing{BlobId: clonedId}
+		return reader, err
+	}
+
+	var hashFormat domain_interfaces.FormatHash
+
+	if hashFormat, err = markl.GetFormatHashOrError(
+		id.GetMarklFormat().GetMarklFormatId(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return reader, err
+	}
+
+	var innerReader domain_interfaces.BlobReader
+
+	if innerReader, err = blobStore.inner.MakeBlobReader(
+		ciphertextId,
+	); err != nil {
+		err = errors.Wrap(err)
+		return reader, err
+	}
+
+	if reader, err = env_dir.NewReader(
+		blobStore.makeEnvDirConfig(hashFormat),
+		innerReader,
+	); err != nil {
+		innerReader.Close()
+		err = errors.Wrapf(err, "decrypting blob %s", id)
+		return reader, err
+	}
+
+	return reader, err
+}
+
+func (blobStore encrypted) MakeBlobWriter(
+	hashFormat domain_interfaces.FormatHash,
+) (writer domain_interfaces.BlobWriter, err error) {
+	defer observeBlobWriter(blobStore, time.Now(), &writer, &err)
+
+	return blobStore.makeBlobWriter(hashFormat, false)
+}
+
+// makeBlobWriter returns a writer that keeps the ciphertext already indexed
+// for the blob it writes, unless replace is set.
+func (blobStore encrypted) makeBlobWriter(
+	hashFormat domain_interfaces.FormatHash,
+	replace bool,
+) (writer domain_interfaces.BlobWriter, err error) {
+	var innerWriter domain_interfaces.BlobWriter
+
+	if innerWriter, err = blobStore.inner.MakeBlobWriter(nil); err != nil {
+		err = errors.Wrap(err)
+		return writer, err
+	}
+
+	var plaintextWriter domain_interfaces.BlobWriter
+
+	if plaintextWriter, err = env_dir.NewWriter(
+		blobStore.makeEnvDirConfig(hashFormat),
+		innerWriter,
+	); err != nil {
+		innerWriter.Close()
+		err = errors.Wrap(err)
+		return writer, err
+	}
+
+	writer = &encryptedWriter{
+		BlobWriter: plaintextWriter,
+		inner:      innerWriter,
+		innerStore: blobStore.inner,
+		index:      blobStore.index,
+		replace:    replace,
+	}
+
+	return writer, err
+}
+
+// encryptedWriter digests and encrypts the plaintext written to it, passes
+// the ciphertext to the inner store's writer, and records both ids on close.
+// Encryption is not deterministic, so a blob written again gets new
+// ciphertext, which is deleted again unless replace is set.
+type encryptedWriter struct {
+	domain_interfaces.BlobWriter
+	inner      domain_interfaces.BlobWriter
+	innerStore domain_interfaces.BlobStore
+	index      *encryptedIndex
+	replace    bool
+}
+
+func (writer *encryptedWriter) Close() (err error) {
+	if err = writer.BlobWriter.Close(); err != nil {
+		writer.inner.Close()
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = writer.inner.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if !writer.replace {
+		existing, ok := writer.index.get(writer.GetMarklId())
+
+		if ok && writer.innerStore.HasBlob(existing) {
+			if deleter, ok := writer.innerStore.(BlobDeleter); ok &&
+				!markl.Equals(existing, writer.inner.GetMarklId()) {
+				if err = deleter.DeleteBlob(writer.inner.GetMarklId()); err != nil {
+					err = errors.Wrap(err)
+					return err
+				}
+			}
+
+			return err
+		}
+	}
+
+	if err = writer.index.add(
+		writer.GetMarklId(),
+		writer.inner.GetMarklId(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+type encryptedIndexEntry struct {
+	plaintext, ciphertext markl.Id
+}
+
+type encryptedIndex struct {
+	path   string
+	dryRun bool
+
+	lock    sync.Mutex
+	order   []string
+	entries map[string]encryptedIndexEntry
+}
+
+// each line holds a plaintext id and the id of its ciphertext in the inner
+// store. Later lines win, since re-encrypting a blob produces new ciphertext.
+func readEncryptedIndex(path string) (index *encryptedIndex, err error) {
+	index = &encryptedIndex{
+		path:    path,
+		entries: make(map[string]encryptedIndexEntry),
+	}
+
+	var file *os.File
+
+	if file, err = files.OpenReadOnly(path); err != nil {
+		if errors.IsNotExist(err) {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return index, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		plaintextString, ciphertextString, ok := strings.Cut(line, " ")
+
+		if !ok {
+			err = errors.Errorf("malformed encrypted blob index line: %q", line)
+			return index, err
+		}
+
+		var entry encryptedIndexEntry
+
+		if err = entry.plaintext.Set(plaintextString); err != nil {
+			err = errors.Wrapf(err, "encrypted blob index line: %q", line)
+			return index, err
+		}
+
+		if err = entry.ciphertext.Set(ciphertextString); err != nil {
+			err = errors.Wrapf(err, "encrypted blob index line: %q", line)
+			return index, err
+		}
+
+		index.set(entry)
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return index, err
+	}
+
+	return index, err
+}
+
+func (index *encryptedIndex) set(entry encryptedIndexEntry) {
+	key := entry.plaintext.String()
+
+	if _, ok := index.entries[key]; !ok {
+		index.order = append(index.order, key)
+	}
+
+	index.entries[key] = entry
+}
+
+func (index *encryptedIndex) get(
+	plaintext domain_interfaces.MarklId,
+) (ciphertext domain_interfaces.MarklId, ok bool) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	var entry encryptedIndexEntry
+
+	if entry, ok = index.entries[plaintext.String()]; ok {
+		ciphertext = entry.ciphertext
+	}
+
+	return ciphertext, ok
+}
+
+func (index *encryptedIndex) add(
+	plaintext, ciphertext domain_interfaces.MarklId,
+) (err error) {
+	var entry encryptedIndexEntry
+	entry.plaintext.ResetWithMarklId(plaintext)
+	entry.ciphertext.ResetWithMarklId(ciphertext)
+
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	if index.dryRun {
+		index.set(entry)
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(index.path), os.ModeDir|0o755); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var file *os.File
+
+	if file, err = files.OpenFile(
+		index.path,
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0o666,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	if _, err = fmt.Fprintf(
+		file,
+		"%s %s\n",
+		entry.plaintext.StringWithFormat(),
+		entry.ciphertext.StringWithFormat(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// the ciphertext cannot be read back without its line
+	if err = file.Sync(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	index.set(entry)
+
+	return err
+}
+
+func (index *encryptedIndex) all() interfaces.SeqError[domain_interfaces.MarklId] {
+	return func(yield func(domain_interfaces.MarklId, error) bool) {
+		index.lock.Lock()
+		ids := make([]domain_interfaces.MarklId, 0, len(index.order))
+
+		for _, key := range index.order {
+			ids = append(ids, index.entries[key].plaintext)
+		}
+
+		index.lock.Unlock()
+
+		for _, id := range ids {
+			if !yield(id, nil) {
+				return
+			}
+		}
+	}
+}
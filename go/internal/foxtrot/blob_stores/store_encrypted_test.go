@@ -0,0 +1,268 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+)
+
+func makeTestEncrypted(
+	t *testing.T,
+	basePath string,
+	inner localHashBucketed,
+	key markl.Id,
+	dryRun bool,
+) encrypted {
+	store, err := makeEncrypted(
+		basePath,
+		&blob_store_configs.TomlEncryptedV0{
+			HashTypeId: blob_store_configs.HashTypeSha256,
+			Encryption: []markl.Id{key},
+		},
+		inner,
+		dryRun,
+	)
+	if err != nil {
+		t.Fatalf("makeEncrypted: %v", err)
+	}
+
+	return store
+}
+
+func TestEncryptedRoundTripWithPlaintextIds(t *testing.T) {
+	var key markl.Id
+
+	if err := key.GeneratePrivateKey(
+		nil,
+		markl.FormatIdAgeX25519Sec,
+		markl.PurposeMadderPrivateKeyV1,
+	); err != nil {
+		t.Fatalf("GeneratePrivateKey: %v", err)
+	}
+
+	basePath := t.TempDir()
+	inner := makeTestLocalHashBucketed(t)
+	store := makeTestEncrypted(t, basePath, inner, key, false)
+
+	testData := []byte("secret blob contents that should not be stored in the clear")
+
+	writer, err := store.MakeBlobWriter(nil)
+	if err != nil {
+		t.Fatalf("MakeBlobWriter: %v", err)
+	}
+
+	if _, err := writer.Write(testData); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	id := writer.GetMarklId()
+	plaintextHash := sha256.Sum256(testData)
+
+	if hex.EncodeToString(id.GetBytes()) != hex.EncodeToString(plaintextHash[:]) {
+		t.Fatalf("expected id over the plaintext but got %s", id)
+	}
+
+	if inner.HasBlob(id) {
+		t.Fatal("expected the inner store to not know the plaintext id")
+	}
+
+	for innerId, err := range inner.AllBlobs() {
+		if err != nil {
+			t.Fatalf("AllBlobs: %v", err)
+		}
+
+		innerReader, err := inner.MakeBlobReader(innerId)
+		if err != nil {
+			t.Fatalf("MakeBlobReader: %v", err)
+		}
+
+		ciphertext, err := io.ReadAll(innerReader)
+		innerReader.Close()
+
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+
+		if bytes.Contains(ciphertext, testData) {
+			t.Fatal("expected the inner store to only hold ciphertext")
+		}
+	}
+
+	// a fresh store reads the index back from disk
+	reopened := makeTestEncrypted(t, basePath, inner, key, false)
+
+	if !reopened.HasBlob(id) {
+		t.Fatal("expected reopened store to have the blob")
+	}
+
+	var allIds []string
+
+	for blobId, err := range reopened.AllBlobs() {
+		if err != nil {
+			t.Fatalf("AllBlobs: %v", err)
+		}
+
+		allIds = append(allIds, blobId.String())
+	}
+
+	if len(allIds) != 1 || allIds[0] != id.String() {
+		t.Errorf("expected only %s but got %v", id, allIds)
+	}
+
+	reader, err := reopened.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if !bytes.Equal(actual, testData) {
+		t.Errorf("expected %q but got %q", testData, actual)
+	}
+}
+
+func TestEncryptedRequiresKey(t *testing.T) {
+	if _, err := makeEncrypted(
+		t.TempDir(),
+		&blob_store_configs.TomlEncryptedV0{
+			HashTypeId: blob_store_configs.HashTypeSha256,
+		},
+		makeTestLocalHashBucketed(t),
+		false,
+	); err == nil {
+		t.Fatal("expected an error without encryption keys")
+	}
+}
+
+func TestEncryptedDryRunLeavesIndexAlone(t *testing.T) {
+	var key markl.Id
+
+	if err := key.GeneratePrivateKey(
+		nil,
+		markl.FormatIdAgeX25519Sec,
+		markl.PurposeMadderPrivateKeyV1,
+	); err != nil {
+		t.Fatalf("GeneratePrivateKey: %v", err)
+	}
+
+	basePath := t.TempDir()
+	inner := makeTestDryRunStore(t, makeTestLocalHashBucketed(t))
+	store := makeTestEncrypted(t, basePath, inner, key, true)
+
+	testData := []byte("encrypted during the dry run")
+
+	writer, err := store.MakeBlobWriter(nil)
+	if err != nil {
+		t.Fatalf("MakeBlobWriter: %v", err)
+	}
+
+	if _, err := writer.Write(testData); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	id := writer.GetMarklId()
+
+	reader, err := store.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	actual, err := io.ReadAll(reader)
+	reader.Close()
+
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if !bytes.Equal(actual, testData) {
+		t.Errorf("expected %q but got %q", testData, actual)
+	}
+
+	if _, err := os.Stat(
+		filepath.Join(basePath, fileNameEncryptedIndex),
+	); !os.IsNotExist(err) {
+		t.Errorf("expected no index to be written, got %v", err)
+	}
+
+	if makeTestEncrypted(t, basePath, inner, key, false).HasBlob(id) {
+		t.Error("expected the dry run blob to stay out of the index")
+	}
+}
+
+func TestEncryptedRewriteKeepsIndexedCiphertext(t *testing.T) {
+	var key markl.Id
+
+	if err := key.GeneratePrivateKey(
+		nil,
+		markl.FormatIdAgeX25519Sec,
+		markl.PurposeMadderPrivateKeyV1,
+	); err != nil {
+		t.Fatalf("GeneratePrivateKey: %v", err)
+	}
+
+	basePath := t.TempDir()
+	inner := makeTestLocalHashBucketed(t)
+	store := makeTestEncrypted(t, basePath, inner, key, false)
+
+	for range 2 {
+		writer, err := store.MakeBlobWriter(nil)
+		if err != nil {
+			t.Fatalf("MakeBlobWriter: %v", err)
+		}
+
+		if _, err := writer.Write([]byte("written twice")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+
+	var ciphertexts int
+
+	for _, err := range inner.AllBlobs() {
+		if err != nil {
+			t.Fatalf("AllBlobs: %v", err)
+		}
+
+		ciphertexts++
+	}
+
+	if ciphertexts != 1 {
+		t.Errorf("expected one ciphertext in the inner store, got %d", ciphertexts)
+	}
+
+	index, err := os.ReadFile(filepath.Join(basePath, fileNameEncryptedIndex))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	if lines := bytes.Count(index, []byte("\n")); lines != 1 {
+		t.Errorf("expected one index line, got %d", lines)
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"encoding/hex"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+	"time"
 
 	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
@@ -34,6 +34,12 @@ type inventoryArchiveV0 struct {
 	looseBlobStore domain_interfaces.BlobStore
 	encryption     interfaces.IOWrapper
 	index          map[string]archiveEntry // keyed by hex hash
+
+	// set by -read-only or the config; writes fail with env_dir.ErrReadOnly
+	readOnly bool
+
+	// set by -dry-run; index caches are not written and packing is refused
+	dryRun bool
 }
 
 var _ domain_interfaces.BlobStore = inventoryArchiveV0{}
@@ -52,6 +58,8 @@ func makeInventoryArchiveV0(
 	store.config = config
 	store.looseBlobStore = looseBlobStore
 	store.basePath = basePath
+	store.readOnly = isReadOnly(envDir, config)
+	store.dryRun = envDir.IsDryRun()
 
 	if store.defaultHash, err = markl.GetFormatHashOrError(
 		config.GetDefaultHashTypeId(),
@@ -84,6 +92,8 @@ func makeInventoryArchiveV0(
 
 func (store *inventoryArchiveV0) loadIndex() (err error) {
 	entries, ok := store.tryReadCache()
+	observeCache(store, ok)
+
 	if !ok {
 		return store.rebuildIndex()
 	}
@@ -134,6 +144,12 @@ func (store *inventoryArchiveV0) tryReadCache() (
 		return nil, false
 	}
 
+	// a cache left truncated or torn by a crash fails its checksum and is
+	// rebuilt from the archive indexes rather than partially trusted
+	if err = reader.Validate(); err != nil {
+		return nil, false
+	}
+
 	entries, err = reader.ReadAllEntries()
 	if err != nil {
 		return nil, false
@@ -156,8 +172,6 @@ func (store *inventoryArchiveV0) rebuildIndex() (err error) {
 
 	hashFormatId := store.defaultHash.GetMarklFormatId()
 
-	var allCacheEntries []inventory_archive.CacheEntry
-
 	for _, indexPath := range matches {
 		base := filepath.Base(indexPath)
 		archiveChecksum := strings.TrimSuffix(
@@ -165,8 +179,7 @@ func (store *inventoryArchiveV0) rebuildIndex() (err error) {
 			inventory_archive.IndexFileExtension,
 		)
 
-		archiveChecksumBytes, decodeErr := hex.DecodeString(archiveChecksum)
-		if decodeErr != nil {
+		if _, decodeErr := hex.DecodeString(archiveChecksum); decodeErr != nil {
 			continue
 		}
 
@@ -216,57 +229,31 @@ func (store *inventoryArchiveV0) rebuildIndex() (err error) {
 				StoredSize:      ie.StoredSize,
 			}
 
-			allCacheEntries = append(
-				allCacheEntries,
-				inventory_archive.CacheEntry{
-					Hash:            ie.Hash,
-					ArchiveChecksum: archiveChecksumBytes,
-					Offset:          ie.PackOffset,
-					StoredSize:      ie.StoredSize,
-				},
-			)
 		}
 	}
 
-	if len(allCacheEntries) == 0 {
-		return nil
-	}
-
-	sort.Slice(allCacheEntries, func(i, j int) bool {
-		return bytes.Compare(
-			allCacheEntries[i].Hash,
-			allCacheEntries[j].Hash,
-		) < 0
-	})
+	return store.tryWriteCache()
+}
 
-	if err = os.MkdirAll(store.cachePath, 0o755); err != nil {
-		err = errors.Wrapf(err, "creating cache directory %s", store.cachePath)
+// tryWriteCache writes the cache for a freshly rebuilt index unless the store
+// is read-only or in a dry run, or another process holds the store's lock, in
+// which case that process is packing and will write a cache of its own.
+func (store *inventoryArchiveV0) tryWriteCache() (err error) {
+	if len(store.index) == 0 || store.readOnly || store.dryRun {
 		return err
 	}
 
-	cachePath := filepath.Join(
-		store.cachePath,
-		inventory_archive.CacheFileName,
-	)
-
-	cacheFile, err := os.Create(cachePath)
-	if err != nil {
-		err = errors.Wrapf(err, "creating cache file %s", cachePath)
+	lock, err := lockStore(store.basePath, true)
+	if errors.Is(err, ErrStoreBusy{}) {
+		err = nil
 		return err
-	}
-
-	defer errors.DeferredCloser(&err, cacheFile)
-
-	if _, err = inventory_archive.WriteCache(
-		cacheFile,
-		hashFormatId,
-		allCacheEntries,
-	); err != nil {
-		err = errors.Wrapf(err, "writing cache file %s", cachePath)
+	} else if err != nil {
 		return err
 	}
 
-	return nil
+	defer errors.Deferred(&err, lock.Unlock)
+
+	return store.writeCache()
 }
 
 func (store inventoryArchiveV0) GetBlobStoreDescription() string {
@@ -284,6 +271,8 @@ func (store inventoryArchiveV0) GetDefaultHashType() domain_interfaces.FormatHas
 func (store inventoryArchiveV0) HasBlob(
 	id domain_interfaces.MarklId,
 ) (ok bool) {
+	defer observeLookup(store, time.Now(), &ok)
+
 	if id.IsNull() {
 		ok = true
 		return ok
@@ -299,12 +288,20 @@ func (store inventoryArchiveV0) HasBlob(
 func (store inventoryArchiveV0) MakeBlobWriter(
 	hashFormat domain_interfaces.FormatHash,
 ) (blobWriter domain_interfaces.BlobWriter, err error) {
+	defer observeBlobWriter(store, time.Now(), &blobWriter, &err)
+
+	if err = errIfReadOnly(store.readOnly, store.basePath); err != nil {
+		return blobWriter, err
+	}
+
 	return store.looseBlobStore.MakeBlobWriter(hashFormat)
 }
 
 func (store inventoryArchiveV0) MakeBlobReader(
 	id domain_interfaces.MarklId,
 ) (readCloser domain_interfaces.BlobReader, err error) {
+	defer observeBlobReader(store, time.Now(), &readCloser, &err)
+
 	if id.IsNull() {
 		hash, _ := store.defaultHash.Get()
 		readCloser = markl_io.MakeNopReadCloser(
@@ -825,3 +825,62 @@ func TestPackDeleteLooseNotCalledWithoutFlag(t *testing.T) {
 			len(stub.deletedBlobIds))
 	}
 }
+
+func TestTryReadCacheRejectsTruncatedCache(t *testing.T) {
+	basePath := t.TempDir()
+	cachePath := t.TempDir()
+
+	hashFormat := markl.FormatHashSha256
+
+	testData := []byte("truncated cache blob")
+	rawHash := sha256.Sum256(testData)
+
+	id, repool := hashFormat.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	defer repool()
+
+	stub := &stubBlobStore{
+		allBlobIds: []domain_interfaces.MarklId{id},
+		blobData: map[string][]byte{
+			id.String(): testData,
+		},
+	}
+
+	store := inventoryArchiveV0{
+		defaultHash:    hashFormat,
+		basePath:       basePath,
+		cachePath:      cachePath,
+		looseBlobStore: stub,
+		index:          make(map[string]archiveEntry),
+		config: blob_store_configs.TomlInventoryArchiveV0{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+	}
+
+	if err := store.Pack(PackOptions{}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	if _, ok := store.tryReadCache(); !ok {
+		t.Fatal("expected the freshly written cache to be readable")
+	}
+
+	cacheFilePath := filepath.Join(cachePath, inventory_archive.CacheFileName)
+
+	info, err := os.Stat(cacheFilePath)
+	if err != nil {
+		t.Fatalf("stat cache: %v", err)
+	}
+
+	// simulate a write torn by a crash: the entries survive, the trailing
+	// checksum does not
+	if err := os.Truncate(cacheFilePath, info.Size()-1); err != nil {
+		t.Fatalf("truncating cache: %v", err)
+	}
+
+	if _, ok := store.tryReadCache(); ok {
+		t.Fatal("expected a truncated cache to be rejected")
+	}
+}
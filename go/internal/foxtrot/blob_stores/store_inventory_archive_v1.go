@@ -5,8 +5,7 @@ import (
 	"encoding/hex"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
+	"time"
 
 	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
@@ -18,6 +17,7 @@ import (
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/ohio"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
 )
 
 type archiveEntryV1 struct {
@@ -35,7 +35,21 @@ type inventoryArchiveV1 struct {
 	cachePath      string
 	looseBlobStore domain_interfaces.BlobStore
 	encryption     interfaces.IOWrapper
-	index          map[string]archiveEntryV1 // keyed by hex hash
+	index          map[string]archiveEntryV1 // keyed by markl id string
+	readerPools    *archiveReaderPools
+	blooms         *archiveBloomFilters
+	generation     *archiveGeneration
+	tiers          *archiveTiers
+
+	// forceDelta selects delta bases even when the config disables deltas,
+	// for conversions asked to delta-compress archives.
+	forceDelta bool
+
+	// set by -read-only or the config; writes fail with env_dir.ErrReadOnly
+	readOnly bool
+
+	// set by -dry-run; index caches are not written and packing is refused
+	dryRun bool
 }
 
 var _ domain_interfaces.BlobStore = inventoryArchiveV1{}
@@ -54,6 +68,8 @@ func makeInventoryArchiveV1(
 	store.config = config
 	store.looseBlobStore = looseBlobStore
 	store.basePath = basePath
+	store.readOnly = isReadOnly(envDir, config)
+	store.dryRun = envDir.IsDryRun()
 
 	if store.defaultHash, err = markl.GetFormatHashOrError(
 		config.GetDefaultHashTypeId(),
@@ -75,6 +91,10 @@ func makeInventoryArchiveV1(
 	}
 
 	store.index = make(map[string]archiveEntryV1)
+	store.readerPools = &archiveReaderPools{}
+	store.blooms = &archiveBloomFilters{}
+	store.generation = &archiveGeneration{}
+	store.tiers = &archiveTiers{}
 
 	if err = store.loadIndex(); err != nil {
 		err = errors.Wrap(err)
@@ -84,197 +104,297 @@ func makeInventoryArchiveV1(
 	return store, err
 }
 
+// loadIndex loads the index from the cache kept for the manifest's current
+// generation, and rebuilds it from the archives when there is no such cache.
 func (store *inventoryArchiveV1) loadIndex() (err error) {
-	entries, ok := store.tryReadCache()
+	manifest, hasManifest, err := store.readManifest()
+	if err != nil {
+		return err
+	}
+
+	store.generation.set(manifest.Generation)
+	store.tiers.set(manifest)
+
+	caches, ok := store.tryReadCache()
+	observeCache(store, ok)
+
 	if !ok {
-		return store.rebuildIndex()
+		if err = store.rebuildIndex(); err != nil {
+			return err
+		}
+
+		return store.tryWriteCacheV1()
 	}
 
-	for _, entry := range entries {
-		marklId, repool := store.defaultHash.GetBlobIdForHexString(
-			hex.EncodeToString(entry.Hash),
-		)
-		key := marklId.String()
-		repool()
+	for _, cache := range caches {
+		for _, entry := range cache.entries {
+			marklId, repool := cache.formatHash.GetBlobIdForHexString(
+				hex.EncodeToString(entry.Hash),
+			)
+			key := marklId.String()
+			repool()
 
-		store.index[key] = archiveEntryV1{
-			ArchiveChecksum: hex.EncodeToString(entry.ArchiveChecksum),
-			Offset:          entry.Offset,
-			StoredSize:      entry.StoredSize,
-			EntryType:       entry.EntryType,
-			BaseOffset:      entry.BaseOffset,
+			store.index[key] = archiveEntryV1{
+				ArchiveChecksum: hex.EncodeToString(entry.ArchiveChecksum),
+				Offset:          entry.Offset,
+				StoredSize:      entry.StoredSize,
+				EntryType:       entry.EntryType,
+				BaseOffset:      entry.BaseOffset,
+			}
 		}
 	}
 
+	archives := manifest.Archives
+
+	if !hasManifest {
+		if archives, err = store.discoverArchives(); err != nil {
+			return err
+		}
+	}
+
+	store.loadBloomFilters(archives)
+
 	return nil
 }
 
+// archiveCacheV1 is the contents of one index cache file, which holds the
+// entries of a single hash format.
+type archiveCacheV1 struct {
+	formatHash markl.FormatHash
+	entries    []inventory_archive.CacheEntryV1
+}
+
+// tryReadCache reads every cache file of the current generation, one per hash
+// format in the index. ok is false when the default format's cache is missing
+// or any cache fails to read, in which case the index must be rebuilt.
 func (store *inventoryArchiveV1) tryReadCache() (
-	entries []inventory_archive.CacheEntryV1,
+	caches []archiveCacheV1,
 	ok bool,
 ) {
-	cachePath := filepath.Join(store.cachePath, inventory_archive.CacheFileNameV1)
+	for _, cachePath := range store.cacheFilePaths() {
+		cache, cacheOk := readCacheFileV1(cachePath)
 
+		if !cacheOk {
+			return nil, false
+		}
+
+		caches = append(caches, cache)
+	}
+
+	return caches, true
+}
+
+func readCacheFileV1(cachePath string) (cache archiveCacheV1, ok bool) {
 	file, err := os.Open(cachePath)
 	if err != nil {
-		return nil, false
+		return cache, false
 	}
 
 	defer file.Close()
 
 	info, err := file.Stat()
 	if err != nil {
-		return nil, false
+		return cache, false
 	}
 
-	hashFormatId := store.defaultHash.GetMarklFormatId()
-
 	reader, err := inventory_archive.NewCacheReaderV1(
 		file,
 		info.Size(),
-		hashFormatId,
 	)
 	if err != nil {
-		return nil, false
+		return cache, false
 	}
 
-	entries, err = reader.ReadAllEntries()
-	if err != nil {
-		return nil, false
+	if cache.formatHash, err = markl.GetFormatHashOrError(
+		reader.HashFormatId(),
+	); err != nil {
+		return cache, false
 	}
 
-	return entries, true
+	// a cache left truncated or torn by a crash fails its checksum and is
+	// rebuilt from the archive indexes rather than partially trusted
+	if err = reader.Validate(); err != nil {
+		return cache, false
+	}
+
+	if cache.entries, err = reader.ReadAllEntries(); err != nil {
+		return cache, false
+	}
+
+	return cache, true
 }
 
+// rebuildIndex replaces the in-memory index with the indexes of the archives
+// listed in the manifest, or found on disk for stores without a manifest.
 func (store *inventoryArchiveV1) rebuildIndex() (err error) {
-	pattern := filepath.Join(
-		store.archivesPath(),
-		"*"+inventory_archive.IndexFileExtensionV1,
-	)
-
-	matches, err := filepath.Glob(pattern)
+	manifest, hasManifest, err := store.readManifest()
 	if err != nil {
-		err = errors.Wrapf(err, "globbing v1 index files")
 		return err
 	}
 
-	hashFormatId := store.defaultHash.GetMarklFormatId()
+	archives := manifest.Archives
 
-	var allCacheEntries []inventory_archive.CacheEntryV1
+	if !hasManifest {
+		if archives, err = store.discoverArchives(); err != nil {
+			return err
+		}
+	}
 
-	for _, indexPath := range matches {
-		base := filepath.Base(indexPath)
-		archiveChecksum := strings.TrimSuffix(
-			base,
-			inventory_archive.IndexFileExtensionV1,
-		)
+	store.generation.set(manifest.Generation)
+	store.tiers.set(manifest)
+	clear(store.index)
+	store.blooms.reset()
 
-		archiveChecksumBytes, decodeErr := hex.DecodeString(archiveChecksum)
-		if decodeErr != nil {
-			continue
-		}
+	for _, archive := range archives {
+		var indexEntries []inventory_archive.IndexEntryV1
+		var hashFormatId string
 
-		file, openErr := os.Open(indexPath)
-		if openErr != nil {
-			err = errors.Wrapf(openErr, "opening v1 index %s", indexPath)
+		if indexEntries, hashFormatId, err = store.readArchiveIndex(
+			archive,
+		); err != nil {
 			return err
 		}
 
-		info, statErr := file.Stat()
-		if statErr != nil {
-			file.Close()
-			err = errors.Wrapf(statErr, "stat v1 index %s", indexPath)
-			return err
-		}
+		archiveChecksum := hex.EncodeToString(archive.Checksum)
 
-		reader, readerErr := inventory_archive.NewIndexReaderV1(
-			file,
-			info.Size(),
+		if err = store.addIndexEntries(
+			archiveChecksum,
 			hashFormatId,
-		)
-		if readerErr != nil {
-			file.Close()
-			err = errors.Wrapf(readerErr, "reading v1 index %s", indexPath)
+			indexEntries,
+		); err != nil {
 			return err
 		}
 
-		indexEntries, readErr := reader.ReadAllEntries()
+		store.addBloomFilterForIndex(
+			archiveChecksum,
+			indexEntries,
+			!files.Exists(store.bloomFilterPath(archiveChecksum)),
+		)
+	}
 
-		file.Close()
+	return err
+}
 
-		if readErr != nil {
-			err = errors.Wrapf(readErr, "reading entries from v1 index %s", indexPath)
-			return err
-		}
+// tryWriteCacheV1 writes the cache for a freshly rebuilt index unless the
+// store is read-only or in a dry run, or another process holds the store's
+// lock, in which case that process is packing and will write a cache of its
+// own.
+func (store *inventoryArchiveV1) tryWriteCacheV1() (err error) {
+	if len(store.index) == 0 || store.readOnly || store.dryRun {
+		return err
+	}
 
-		for _, ie := range indexEntries {
-			marklId, repool := store.defaultHash.GetBlobIdForHexString(
-				hex.EncodeToString(ie.Hash),
-			)
-			key := marklId.String()
-			repool()
+	lock, err := lockStore(store.basePath, true)
+	if errors.Is(err, ErrStoreBusy{}) {
+		err = nil
+		return err
+	} else if err != nil {
+		return err
+	}
 
-			store.index[key] = archiveEntryV1{
-				ArchiveChecksum: archiveChecksum,
-				Offset:          ie.PackOffset,
-				StoredSize:      ie.StoredSize,
-				EntryType:       ie.EntryType,
-				BaseOffset:      ie.BaseOffset,
-			}
+	defer errors.Deferred(&err, lock.Unlock)
 
-			allCacheEntries = append(
-				allCacheEntries,
-				inventory_archive.CacheEntryV1{
-					Hash:            ie.Hash,
-					ArchiveChecksum: archiveChecksumBytes,
-					Offset:          ie.PackOffset,
-					StoredSize:      ie.StoredSize,
-					EntryType:       ie.EntryType,
-					BaseOffset:      ie.BaseOffset,
-				},
-			)
-		}
+	return store.writeCacheV1()
+}
+
+func (store *inventoryArchiveV1) readFooterIndex(
+	dataPath string,
+) (
+	indexEntries []inventory_archive.IndexEntryV1,
+	hashFormatId string,
+	err error,
+) {
+	file, err := os.Open(dataPath)
+	if err != nil {
+		err = errors.Wrapf(err, "opening v2 archive %s", dataPath)
+		return indexEntries, hashFormatId, err
 	}
 
-	if len(allCacheEntries) == 0 {
-		return nil
+	defer errors.DeferredCloser(&err, file)
+
+	dataReader, err := inventory_archive.NewDataReaderV1(file, store.encryption)
+	if err != nil {
+		err = errors.Wrapf(err, "reading v2 archive header %s", dataPath)
+		return indexEntries, hashFormatId, err
 	}
 
-	sort.Slice(allCacheEntries, func(i, j int) bool {
-		return bytes.Compare(
-			allCacheEntries[i].Hash,
-			allCacheEntries[j].Hash,
-		) < 0
-	})
+	indexReader, err := dataReader.FooterIndex()
+	if err != nil {
+		err = errors.Wrapf(err, "reading footer index of %s", dataPath)
+		return indexEntries, hashFormatId, err
+	}
 
-	if err = os.MkdirAll(store.cachePath, 0o755); err != nil {
-		err = errors.Wrapf(err, "creating cache directory %s", store.cachePath)
-		return err
+	hashFormatId = indexReader.HashFormatId()
+
+	if indexEntries, err = indexReader.ReadAllEntries(); err != nil {
+		err = errors.Wrapf(err, "reading entries from footer index of %s", dataPath)
+		return indexEntries, hashFormatId, err
 	}
 
-	cachePath := filepath.Join(
-		store.cachePath,
-		inventory_archive.CacheFileNameV1,
-	)
+	return indexEntries, hashFormatId, err
+}
 
-	cacheFile, err := os.Create(cachePath)
+// addIndexEntries adds the entries of an archive to the index, keyed by ids in
+// the archive's own hash format so that archives written before the repo
+// changed its default hash stay reachable.
+func (store *inventoryArchiveV1) addIndexEntries(
+	archiveChecksum string,
+	hashFormatId string,
+	indexEntries []inventory_archive.IndexEntryV1,
+) (err error) {
+	formatHash, err := markl.GetFormatHashOrError(hashFormatId)
 	if err != nil {
-		err = errors.Wrapf(err, "creating v1 cache file %s", cachePath)
+		err = errors.Wrapf(err, "archive %s", archiveChecksum)
 		return err
 	}
 
-	defer errors.DeferredCloser(&err, cacheFile)
+	for _, ie := range indexEntries {
+		marklId, repool := formatHash.GetBlobIdForHexString(
+			hex.EncodeToString(ie.Hash),
+		)
+		key := marklId.String()
+		repool()
 
-	if _, err = inventory_archive.WriteCacheV1(
-		cacheFile,
-		hashFormatId,
-		allCacheEntries,
-	); err != nil {
-		err = errors.Wrapf(err, "writing v1 cache file %s", cachePath)
-		return err
+		store.index[key] = archiveEntryV1{
+			ArchiveChecksum: archiveChecksum,
+			Offset:          ie.PackOffset,
+			StoredSize:      ie.StoredSize,
+			EntryType:       ie.EntryType,
+			BaseOffset:      ie.BaseOffset,
+		}
 	}
 
-	return nil
+	return err
+}
+
+// openArchiveDataFile opens an archive's data file by checksum, which may be
+// a v2 file with a footer index or a v1 file with a separate index, in either
+// tier.
+func (store inventoryArchiveV1) openArchiveDataFile(
+	archiveChecksum string,
+) (file *os.File, archivePath string, err error) {
+	for _, archivePath = range store.archiveDataPaths(
+		archiveChecksum,
+		inventory_archive.DataFileExtensionV2,
+		inventory_archive.DataFileExtensionV1,
+	) {
+		if file, err = os.Open(archivePath); err == nil || !os.IsNotExist(err) {
+			break
+		}
+	}
+
+	if err != nil {
+		err = errors.Wrapf(err, "opening archive %s", archivePath)
+		return file, archivePath, err
+	}
+
+	store.recordArchiveRead(archiveChecksum, archivePath)
+
+	return file, archivePath, err
+}
+
+func (store inventoryArchiveV1) deltaEnabled() bool {
+	return store.forceDelta || store.config.GetDeltaEnabled()
 }
 
 func (store inventoryArchiveV1) GetBlobStoreDescription() string {
@@ -292,13 +412,18 @@ func (store inventoryArchiveV1) GetDefaultHashType() domain_interfaces.FormatHas
 func (store inventoryArchiveV1) HasBlob(
 	id domain_interfaces.MarklId,
 ) (ok bool) {
+	defer observeLookup(store, time.Now(), &ok)
+
 	if id.IsNull() {
 		ok = true
 		return ok
 	}
 
-	if _, ok = store.index[id.String()]; ok {
-		return ok
+	// the filters answer for blobs in no archive without the id string
+	if store.blooms.mayContain(id.GetBytes()) {
+		if _, ok = store.index[id.String()]; ok {
+			return ok
+		}
 	}
 
 	return store.looseBlobStore.HasBlob(id)
@@ -307,12 +432,20 @@ func (store inventoryArchiveV1) HasBlob(
 func (store inventoryArchiveV1) MakeBlobWriter(
 	hashFormat domain_interfaces.FormatHash,
 ) (blobWriter domain_interfaces.BlobWriter, err error) {
+	defer observeBlobWriter(store, time.Now(), &blobWriter, &err)
+
+	if err = errIfReadOnly(store.readOnly, store.basePath); err != nil {
+		return blobWriter, err
+	}
+
 	return store.looseBlobStore.MakeBlobWriter(hashFormat)
 }
 
 func (store inventoryArchiveV1) MakeBlobReader(
 	id domain_interfaces.MarklId,
 ) (readCloser domain_interfaces.BlobReader, err error) {
+	defer observeBlobReader(store, time.Now(), &readCloser, &err)
+
 	if id.IsNull() {
 		hash, _ := store.defaultHash.Get() //repool:owned
 		readCloser = markl_io.MakeNopReadCloser(
@@ -327,14 +460,18 @@ func (store inventoryArchiveV1) MakeBlobReader(
 		return store.looseBlobStore.MakeBlobReader(id)
 	}
 
-	archivePath := filepath.Join(
-		store.archivesPath(),
-		entry.ArchiveChecksum+inventory_archive.DataFileExtensionV1,
+	// ids in the index are in their archive's hash format, which may differ
+	// from the store's default
+	formatHash, err := markl.GetFormatHashOrError(
+		id.GetMarklFormat().GetMarklFormatId(),
 	)
+	if err != nil {
+		err = errors.Wrap(err)
+		return readCloser, err
+	}
 
-	file, err := os.Open(archivePath)
+	file, archivePath, err := store.openArchiveDataFile(entry.ArchiveChecksum)
 	if err != nil {
-		err = errors.Wrapf(err, "opening v1 archive %s", archivePath)
 		return readCloser, err
 	}
 
@@ -342,12 +479,19 @@ func (store inventoryArchiveV1) MakeBlobReader(
 	// into dataEntry.Data before returning, so the file is not needed after.
 	defer errors.DeferredCloser(&err, file)
 
-	dataReader, err := inventory_archive.NewDataReaderV1(file, store.encryption)
+	dataReader, repoolDataReader, err := store.getDataReaderV1(
+		entry.ArchiveChecksum,
+		file,
+	)
 	if err != nil {
 		err = errors.Wrapf(err, "reading v1 archive header %s", archivePath)
 		return readCloser, err
 	}
 
+	// entries are decoded into their own slices, so the reader can go back
+	// to the pool before they are read
+	defer repoolDataReader()
+
 	dataEntry, err := dataReader.ReadEntryAt(entry.Offset)
 	if err != nil {
 		err = errors.Wrapf(
@@ -359,7 +503,7 @@ func (store inventoryArchiveV1) MakeBlobReader(
 		return readCloser, err
 	}
 
-	hash, _ := store.defaultHash.Get() //repool:owned
+	hash, _ := formatHash.Get() //repool:owned
 
 	if dataEntry.EntryType == inventory_archive.EntryTypeFull {
 		readCloser = markl_io.MakeReadCloser(
@@ -371,7 +515,7 @@ func (store inventoryArchiveV1) MakeBlobReader(
 
 	// Delta entry: reconstruct from base + delta
 	baseHashHex := hex.EncodeToString(dataEntry.BaseHash)
-	baseId, baseRepool := store.defaultHash.GetBlobIdForHexString(baseHashHex)
+	baseId, baseRepool := formatHash.GetBlobIdForHexString(baseHashHex)
 	baseEntry, baseInArchive := store.index[baseId.String()]
 	baseRepool()
 
@@ -408,7 +552,7 @@ func (store inventoryArchiveV1) MakeBlobReader(
 		return readCloser, err
 	}
 
-	baseHash, _ := store.defaultHash.Get() //repool:owned
+	baseHash, _ := formatHash.Get() //repool:owned
 	baseReader := markl_io.MakeReadCloser(
 		baseHash,
 		bytes.NewReader(baseDataEntry.Data),
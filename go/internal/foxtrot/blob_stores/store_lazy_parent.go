@@ -0,0 +1,180 @@
+package blob_stores
+
+import (
+	"io"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/structured_log"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// lazyParent is a local hash-bucketed store that, on a read miss, fetches the
+// blob from its parent store, persists it locally, and then serves it from
+// the local copy. Writes only go to the local store. When the local store is
+// read-only, misses are served from the parent without a local copy.
+type lazyParent struct {
+	local  localHashBucketed
+	parent domain_interfaces.BlobStore
+}
+
+var (
+	_ domain_interfaces.BlobStore              = lazyParent{}
+	_ BlobDeleter                              = lazyParent{}
+	_ domain_interfaces.BlobForeignDigestAdder = lazyParent{}
+)
+
+func makeLazyParent(
+	local localHashBucketed,
+	parent domain_interfaces.BlobStore,
+) lazyParent {
+	return lazyParent{
+		local:  local,
+		parent: parent,
+	}
+}
+
+func (blobStore lazyParent) GetBlobStoreDescription() string {
+	return "lazy parent"
+}
+
+func (blobStore lazyParent) GetBlobIOWrapper() domain_interfaces.BlobIOWrapper {
+	return blobStore.local.GetBlobIOWrapper()
+}
+
+func (blobStore lazyParent) GetDefaultHashType() domain_interfaces.FormatHash {
+	return blobStore.local.GetDefaultHashType()
+}
+
+func (blobStore lazyParent) HasBlob(id domain_interfaces.MarklId) (ok bool) {
+	defer observeLookup(blobStore, time.Now(), &ok)
+
+	ok = blobStore.local.HasBlob(id) || blobStore.parent.HasBlob(id)
+
+	return ok
+}
+
+// only the blobs that have been fetched or written locally
+func (blobStore lazyParent) AllBlobs() interfaces.SeqError[domain_interfaces.MarklId] {
+	return blobStore.local.AllBlobs()
+}
+
+func (blobStore lazyParent) MakeBlobReader(
+	id domain_interfaces.MarklId,
+) (reader domain_interfaces.BlobReader, err error) {
+	defer observeBlobReader(blobStore, time.Now(), &reader, &err)
+
+	if !blobStore.local.HasBlob(id) {
+		if blobStore.local.readOnly {
+			return blobStore.parent.MakeBlobReader(id)
+		}
+
+		start := time.Now()
+
+		if err = blobStore.fetchFromParent(id); err != nil {
+			logger.Debug(
+				"fetching blob from parent failed",
+				structured_log.BlobId(id),
+				"error",
+				err,
+			)
+
+			return reader, err
+		}
+
+		logger.Info(
+			"fetched blob from parent",
+			structured_log.BlobId(id),
+			structured_log.Since(start),
+		)
+	}
+
+	return blobStore.local.MakeBlobReader(id)
+}
+
+func (blobStore lazyParent) fetchFromParent(
+	id domain_interfaces.MarklId,
+) (err error) {
+	if err = markl.AssertIdIsNotNull(id); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if !blobStore.parent.HasBlob(id) {
+		clonedId, _ := markl.Clone(id)
+		err = env_dir.ErrBlobMissing{BlobId: clonedId}
+		return err
+	}
+
+	// on the same filesystem, the parent's file is shared instead of copied
+	if _, err = blobStore.local.LinkBlobFrom(blobStore.parent, id); err == nil {
+		return err
+	}
+
+	err = nil
+
+	var hashType domain_interfaces.FormatHash
+
+	if hashType, err = markl.GetFormatHashOrError(
+		id.GetMarklFormat().GetMarklFormatId(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var reader domain_interfaces.BlobReader
+
+	if reader, err = blobStore.parent.MakeBlobReader(id); err != nil {
+		err = errors.Wrapf(err, "fetching from parent")
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	var writer domain_interfaces.BlobWriter
+
+	if writer, err = blobStore.local.MakeBlobWriter(hashType); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if _, err = io.Copy(writer, reader); err != nil {
+		writer.Close()
+		err = errors.Wrapf(err, "fetching from parent")
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = markl.AssertEqual(id, writer.GetMarklId()); err != nil {
+		err = errors.Wrapf(err, "fetching from parent")
+		return err
+	}
+
+	return err
+}
+
+func (blobStore lazyParent) MakeBlobWriter(
+	hashType domain_interfaces.FormatHash,
+) (writer domain_interfaces.BlobWriter, err error) {
+	defer observeBlobWriter(blobStore, time.Now(), &writer, &err)
+
+	return blobStore.local.MakeBlobWriter(hashType)
+}
+
+func (blobStore lazyParent) DeleteBlob(id domain_interfaces.MarklId) error {
+	return blobStore.local.DeleteBlob(id)
+}
+
+func (blobStore lazyParent) AddForeignBlobDigestForNativeDigest(
+	foreign domain_interfaces.MarklId,
+	native domain_interfaces.MarklId,
+) error {
+	return blobStore.local.AddForeignBlobDigestForNativeDigest(foreign, native)
+}
@@ -0,0 +1,105 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func makeTestLocalHashBucketed(t testing.TB) localHashBucketed {
+	config := &blob_store_configs.TomlLazyParentV0{
+		HashBuckets:     blob_store_configs.DefaultHashBuckets,
+		HashTypeId:      blob_store_configs.HashTypeSha256,
+		CompressionType: compression_type.CompressionTypeNone,
+	}
+
+	return localHashBucketed{
+		config:            config,
+		multiHash:         config.SupportsMultiHash(),
+		defaultHashFormat: markl.FormatHashSha256,
+		buckets:           config.GetHashBuckets(),
+		basePath:          t.TempDir(),
+		tempFS:            env_dir.TemporaryFS{BasePath: t.TempDir()},
+	}
+}
+
+func TestLazyParentFetchesMissingBlobs(t *testing.T) {
+	parent := makeTestLocalHashBucketed(t)
+	store := makeLazyParent(makeTestLocalHashBucketed(t), parent)
+
+	testData := []byte("only in the parent")
+
+	writer, err := parent.MakeBlobWriter(nil)
+	if err != nil {
+		t.Fatalf("MakeBlobWriter: %v", err)
+	}
+
+	if _, err := writer.Write(testData); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	id := writer.GetMarklId()
+
+	if store.local.HasBlob(id) {
+		t.Fatal("expected blob to be missing locally before the first read")
+	}
+
+	if !store.HasBlob(id) {
+		t.Fatal("expected blob to be available via the parent")
+	}
+
+	reader, err := store.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if string(actual) != string(testData) {
+		t.Errorf("expected %q but got %q", testData, actual)
+	}
+
+	if !store.local.HasBlob(id) {
+		t.Error("expected blob to be stored locally after the first read")
+	}
+}
+
+func TestLazyParentMissingEverywhere(t *testing.T) {
+	store := makeLazyParent(
+		makeTestLocalHashBucketed(t),
+		makeTestLocalHashBucketed(t),
+	)
+
+	rawHash := sha256.Sum256([]byte("nowhere"))
+	id, repool := markl.FormatHashSha256.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	defer repool()
+
+	if store.HasBlob(id) {
+		t.Fatal("expected blob to be missing")
+	}
+
+	if _, err := store.MakeBlobReader(id); !env_dir.IsErrBlobMissing(err) {
+		t.Fatalf("expected blob missing error but got %v", err)
+	}
+}
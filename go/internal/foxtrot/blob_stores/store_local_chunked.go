@@ -0,0 +1,454 @@
+package blob_stores
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/alfa/markl_io"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+const (
+	// a chunked blob's manifest is kept beside where its whole file would be
+	chunkedBlobManifestSuffix  = ".chunked"
+	chunkedBlobManifestVersion = "chunked_blob-v1"
+)
+
+// chunkedBlobs are the FastCDC parameters of a local store that keeps blobs
+// of at least threshold bytes as chunks. Chunks are stored as blobs of the
+// store, under their own ids, and are never chunked themselves.
+type chunkedBlobs struct {
+	threshold                 int64
+	minSize, avgSize, maxSize int
+}
+
+func makeChunkedBlobs(config blob_store_configs.Config) *chunkedBlobs {
+	chunkedConfig, ok := config.(blob_store_configs.ConfigChunkedBlobs)
+
+	if !ok || chunkedConfig.GetChunkedBlobThreshold() == 0 {
+		return nil
+	}
+
+	avgSize := int(chunkedConfig.GetChunkedBlobAvgChunkSize())
+
+	return &chunkedBlobs{
+		threshold: int64(chunkedConfig.GetChunkedBlobThreshold()),
+		minSize:   avgSize / 4,
+		avgSize:   avgSize,
+		maxSize:   avgSize * 8,
+	}
+}
+
+type chunkedBlobChunk struct {
+	id   markl.Id
+	size int64
+}
+
+// chunkedBlobManifest lists a chunked blob's chunks in order. It is stored
+// encoded like the store's blobs, as a version line, a size line, and one
+// `<chunk id> <size>` line per chunk.
+type chunkedBlobManifest struct {
+	size   int64
+	chunks []chunkedBlobChunk
+}
+
+func (manifest chunkedBlobManifest) WriteTo(
+	writer io.Writer,
+) (n int64, err error) {
+	var builder strings.Builder
+
+	fmt.Fprintf(
+		&builder,
+		"%s\nsize %d\n",
+		chunkedBlobManifestVersion,
+		manifest.size,
+	)
+
+	for _, chunk := range manifest.chunks {
+		fmt.Fprintf(&builder, "%s %d\n", chunk.id.String(), chunk.size)
+	}
+
+	var written int
+
+	written, err = io.WriteString(writer, builder.String())
+	n = int64(written)
+
+	if err != nil {
+		err = errors.Wrap(err)
+		return n, err
+	}
+
+	return n, err
+}
+
+func (manifest *chunkedBlobManifest) ReadFrom(
+	reader io.Reader,
+) (n int64, err error) {
+	scanner := bufio.NewScanner(reader)
+
+	for lineNumber := 0; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		n += int64(len(line)) + 1
+
+		switch lineNumber {
+		case 0:
+			if line != chunkedBlobManifestVersion {
+				err = errors.Errorf("unsupported chunked blob manifest %q", line)
+				return n, err
+			}
+
+		case 1:
+			value, ok := strings.CutPrefix(line, "size ")
+
+			if !ok {
+				err = errors.Errorf("malformed chunked blob size %q", line)
+				return n, err
+			}
+
+			if manifest.size, err = strconv.ParseInt(value, 10, 64); err != nil {
+				err = errors.Wrap(err)
+				return n, err
+			}
+
+		default:
+			idString, sizeString, ok := strings.Cut(line, " ")
+
+			if !ok {
+				err = errors.Errorf("malformed chunked blob chunk %q", line)
+				return n, err
+			}
+
+			var chunk chunkedBlobChunk
+
+			if err = chunk.id.Set(idString); err != nil {
+				err = errors.Wrap(err)
+				return n, err
+			}
+
+			if chunk.size, err = strconv.ParseInt(sizeString, 10, 64); err != nil {
+				err = errors.Wrap(err)
+				return n, err
+			}
+
+			manifest.chunks = append(manifest.chunks, chunk)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return n, err
+	}
+
+	return n, err
+}
+
+// chunkBlob splits the whole file of a blob into chunks, writes its manifest,
+// and then removes the file. The file is only removed once the manifest is in
+// place, so an interruption leaves the blob readable.
+func (blobStore localHashBucketed) chunkBlob(
+	id domain_interfaces.MarklId,
+) (err error) {
+	path := env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		blobStore.buckets,
+		blobStore.multiHash,
+		blobStore.basePath,
+	)
+
+	manifestPath := path + chunkedBlobManifestSuffix
+
+	if files.Exists(manifestPath) {
+		if err = blobStore.removeBlobFile(path); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		return err
+	}
+
+	var hashFormat markl.FormatHash
+
+	if hashFormat, err = markl.GetFormatHashOrError(
+		id.GetMarklFormat().GetMarklFormatId(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var reader domain_interfaces.BlobReader
+
+	if reader, err = blobStore.blobReaderFrom(id, blobStore.basePath); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	chunker := inventory_archive.MakeFastCDCChunker(
+		reader,
+		blobStore.chunkedBlobs.minSize,
+		blobStore.chunkedBlobs.avgSize,
+		blobStore.chunkedBlobs.maxSize,
+	)
+
+	var manifest chunkedBlobManifest
+
+	for {
+		var chunk []byte
+
+		if chunk, err = chunker.Next(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		var chunkId markl.Id
+
+		if chunkId, err = blobStore.writeChunk(hashFormat, chunk); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		manifest.chunks = append(
+			manifest.chunks,
+			chunkedBlobChunk{id: chunkId, size: int64(len(chunk))},
+		)
+
+		manifest.size += int64(len(chunk))
+	}
+
+	if err = markl.AssertEqual(id, reader.GetMarklId()); err != nil {
+		err = errors.Wrapf(err, "chunking blob %s", id)
+		return err
+	}
+
+	moveOptions := env_dir.MoveOptions{
+		FinalPathOrDir: manifestPath,
+		TemporaryFS:    blobStore.tempFS,
+	}
+
+	if blobStore.budget != nil {
+		moveOptions.ReserveBytes = blobStore.budget.reserve
+	}
+
+	var manifestWriter domain_interfaces.BlobWriter
+
+	if manifestWriter, err = env_dir.NewMover(
+		blobStore.makeEnvDirConfig(hashFormat),
+		moveOptions,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if _, err = manifest.WriteTo(manifestWriter); err != nil {
+		manifestWriter.Close()
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = manifestWriter.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = blobStore.removeBlobFile(path); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// writeChunk writes chunk as a blob unless the store already has it, which is
+// how versions of a chunked blob share their unchanged chunks.
+func (blobStore localHashBucketed) writeChunk(
+	hashFormat markl.FormatHash,
+	chunk []byte,
+) (id markl.Id, err error) {
+	{
+		hash, repool := hashFormat.GetHash()
+		defer repool()
+
+		hash.Write(chunk)
+
+		digest, digestRepool := hash.GetMarklId()
+		defer digestRepool()
+
+		id.ResetWithMarklId(digest)
+	}
+
+	if blobStore.HasBlob(id) {
+		return id, err
+	}
+
+	var writer domain_interfaces.BlobWriter
+
+	if writer, err = blobStore.blobWriterTo(
+		blobStore.basePath,
+		hashFormat,
+	); err != nil {
+		err = errors.Wrap(err)
+		return id, err
+	}
+
+	if _, err = writer.Write(chunk); err != nil {
+		writer.Close()
+		err = errors.Wrap(err)
+		return id, err
+	}
+
+	if err = writer.Close(); err != nil {
+		err = errors.Wrap(err)
+		return id, err
+	}
+
+	return id, err
+}
+
+func (blobStore localHashBucketed) removeBlobFile(path string) (err error) {
+	var size int64
+
+	if info, statErr := os.Stat(path); statErr == nil {
+		size = info.Size()
+	} else if errors.IsNotExist(statErr) {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if blobStore.budget != nil {
+		blobStore.budget.release(size)
+	}
+
+	return err
+}
+
+// chunkedBlobReaderFrom reads the manifest at manifestPath and returns a
+// reader of the reassembled blob. Each chunk is checked against its id as it
+// is read; the blob's own id is left to the caller, as for whole files.
+func (blobStore localHashBucketed) chunkedBlobReaderFrom(
+	hashFormat markl.FormatHash,
+	manifestPath string,
+	basePath string,
+) (readCloser domain_interfaces.BlobReader, err error) {
+	var manifestReader domain_interfaces.BlobReader
+
+	if manifestReader, err = env_dir.NewFileReaderOrErrNotExist(
+		blobStore.makeEnvDirConfig(hashFormat),
+		manifestPath,
+	); err != nil {
+		err = errors.Wrap(err)
+		return readCloser, err
+	}
+
+	defer errors.DeferredCloser(&err, manifestReader)
+
+	var manifest chunkedBlobManifest
+
+	if _, err = manifest.ReadFrom(manifestReader); err != nil {
+		err = errors.Wrapf(err, "chunked blob manifest %q", manifestPath)
+		return readCloser, err
+	}
+
+	hash, _ := hashFormat.Get() //repool:owned
+
+	readCloser = markl_io.MakeReadCloser(
+		hash,
+		&chunkedBlobContent{
+			store:    blobStore,
+			basePath: basePath,
+			chunks:   manifest.chunks,
+		},
+	)
+
+	return readCloser, err
+}
+
+type chunkedBlobContent struct {
+	store    localHashBucketed
+	basePath string
+	chunks   []chunkedBlobChunk
+	current  domain_interfaces.BlobReader
+}
+
+func (content *chunkedBlobContent) Read(p []byte) (n int, err error) {
+	for {
+		if content.current == nil {
+			if len(content.chunks) == 0 {
+				err = io.EOF
+				return n, err
+			}
+
+			if content.current, err = content.store.blobReaderFrom(
+				content.chunks[0].id,
+				content.basePath,
+			); err != nil {
+				err = errors.Wrapf(err, "chunk %s", content.chunks[0].id)
+				return n, err
+			}
+		}
+
+		n, err = content.current.Read(p)
+
+		if err != io.EOF {
+			return n, err
+		}
+
+		if err = content.finishChunk(); err != nil {
+			return n, err
+		}
+
+		if n > 0 {
+			return n, err
+		}
+	}
+}
+
+// finishChunk checks the chunk just read against its id and closes it.
+func (content *chunkedBlobContent) finishChunk() (err error) {
+	chunk := content.chunks[0]
+	reader := content.current
+
+	content.chunks = content.chunks[1:]
+	content.current = nil
+
+	defer errors.DeferredCloser(&err, reader)
+
+	if err = markl.AssertEqual(chunk.id, reader.GetMarklId()); err != nil {
+		err = errors.Wrapf(err, "chunk %s", chunk.id)
+		return err
+	}
+
+	return err
+}
+
+func (content *chunkedBlobContent) Close() (err error) {
+	if content.current == nil {
+		return err
+	}
+
+	if err = content.current.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	content.current = nil
+
+	return err
+}
@@ -0,0 +1,152 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+func makeTestChunkedLocalHashBucketed(t *testing.T) localHashBucketed {
+	store := makeTestLocalHashBucketed(t)
+	store.chunkedBlobs = &chunkedBlobs{
+		threshold: 32 << 10,
+		minSize:   256,
+		avgSize:   1024,
+		maxSize:   8192,
+	}
+
+	return store
+}
+
+func makeChunkedTestData(size int, seed uint64) string {
+	random := rand.New(rand.NewPCG(seed, seed))
+	data := make([]byte, size)
+
+	for i := range data {
+		data[i] = byte(random.Uint32())
+	}
+
+	return string(data)
+}
+
+func readTestBlob(
+	t *testing.T,
+	store localHashBucketed,
+	id domain_interfaces.MarklId,
+) string {
+	t.Helper()
+
+	reader, err := store.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("MakeBlobReader: %v", err)
+	}
+
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if err := reader.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	return string(actual)
+}
+
+func testBlobPath(
+	store localHashBucketed,
+	id domain_interfaces.MarklId,
+) string {
+	return env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		store.buckets,
+		store.multiHash,
+		store.basePath,
+	)
+}
+
+func countTestBlobs(t *testing.T, store localHashBucketed) (count int) {
+	t.Helper()
+
+	for _, err := range store.AllBlobs() {
+		if err != nil {
+			t.Fatalf("AllBlobs: %v", err)
+		}
+
+		count++
+	}
+
+	return count
+}
+
+func TestChunkedBlobRoundTrip(t *testing.T) {
+	store := makeTestChunkedLocalHashBucketed(t)
+	data := makeChunkedTestData(128<<10, 1)
+	id := writeTestBlob(t, store, data)
+	path := testBlobPath(store, id)
+
+	if files.Exists(path) {
+		t.Error("expected the whole file to be removed")
+	}
+
+	if !files.Exists(path + chunkedBlobManifestSuffix) {
+		t.Fatal("expected a chunk manifest")
+	}
+
+	if !store.HasBlob(id) {
+		t.Error("expected the chunked blob to be present")
+	}
+
+	if actual := readTestBlob(t, store, id); actual != data {
+		t.Errorf("read %d bytes differing from the %d written", len(actual), len(data))
+	}
+
+	if err := store.DeleteBlob(id); err != nil {
+		t.Fatalf("DeleteBlob: %v", err)
+	}
+
+	if store.HasBlob(id) {
+		t.Error("expected the chunked blob to be deleted")
+	}
+}
+
+func TestChunkedBlobSmallBlobsStayWhole(t *testing.T) {
+	store := makeTestChunkedLocalHashBucketed(t)
+	id := writeTestBlob(t, store, "small")
+	path := testBlobPath(store, id)
+
+	if !files.Exists(path) {
+		t.Error("expected a whole file")
+	}
+
+	if files.Exists(path + chunkedBlobManifestSuffix) {
+		t.Error("expected no chunk manifest")
+	}
+}
+
+func TestChunkedBlobVersionsShareChunks(t *testing.T) {
+	store := makeTestChunkedLocalHashBucketed(t)
+	data := makeChunkedTestData(128<<10, 2)
+	edited := data[:1000] + "inserted" + data[1000:]
+
+	writeTestBlob(t, store, data)
+	original := countTestBlobs(t, store)
+
+	editedId := writeTestBlob(t, store, edited)
+	added := countTestBlobs(t, store) - original
+
+	// the manifest plus the few chunks around the insertion
+	if added > 5 {
+		t.Errorf("expected the edited version to add few blobs, it added %d", added)
+	}
+
+	if actual := readTestBlob(t, store, editedId); actual != edited {
+		t.Error("edited version does not read back")
+	}
+}
@@ -2,8 +2,10 @@ package blob_stores
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/alfa/markl_io"
@@ -25,12 +27,31 @@ type localHashBucketed struct {
 
 	basePath string
 	tempFS   env_dir.TemporaryFS
+
+	// set by -read-only or the config; writes fail with env_dir.ErrReadOnly
+	readOnly bool
+
+	// nil outside of -dry-run; takes the writes, deletes are skipped
+	dryRun *localHashBucketed
+
+	// nil unless the config sets a budget
+	budget *storeBudget
+
+	// nil unless the config sets transfer caps
+	transferLimiter *TransferLimiter
+
+	// nil unless the config sets a chunked blob threshold
+	chunkedBlobs *chunkedBlobs
+
+	// nil unless the config sets an inline blob threshold
+	smallBlobs *smallBlobs
 }
 
 var (
 	_ domain_interfaces.BlobStore              = localHashBucketed{}
 	_ BlobDeleter                              = localHashBucketed{}
 	_ domain_interfaces.BlobForeignDigestAdder = localHashBucketed{}
+	_ TransferLimited                          = localHashBucketed{}
 )
 
 func makeLocalHashBucketed(
@@ -51,6 +72,20 @@ func makeLocalHashBucketed(
 
 	store.basePath = basePath
 	store.tempFS = envDir.GetTempLocal()
+	store.readOnly = isReadOnly(envDir, config)
+
+	if store.dryRun, err = makeDryRunStore(envDir, basePath, config); err != nil {
+		err = errors.Wrap(err)
+		return store, err
+	}
+
+	store.budget = makeStoreBudget(config, basePath, func() (int64, error) {
+		return localStoredBytes(basePath)
+	})
+
+	store.transferLimiter = makeTransferLimiterForConfig(config)
+	store.chunkedBlobs = makeChunkedBlobs(config)
+	store.smallBlobs = makeSmallBlobs(config, basePath)
 
 	return store, err
 }
@@ -59,6 +94,10 @@ func (blobStore localHashBucketed) GetBlobStoreConfig() blob_store_configs.Confi
 	return blobStore.config
 }
 
+func (blobStore localHashBucketed) GetTransferLimiter() *TransferLimiter {
+	return blobStore.transferLimiter
+}
+
 func (blobStore localHashBucketed) GetBlobStoreDescription() string {
 	return "local hash bucketed"
 }
@@ -89,6 +128,8 @@ func (blobStore localHashBucketed) makeEnvDirConfig(
 func (blobStore localHashBucketed) HasBlob(
 	merkleId domain_interfaces.MarklId,
 ) (ok bool) {
+	defer observeLookup(blobStore, time.Now(), &ok)
+
 	if merkleId.IsNull() {
 		ok = true
 		return ok
@@ -101,22 +142,62 @@ func (blobStore localHashBucketed) HasBlob(
 		blobStore.basePath,
 	)
 
-	ok = files.Exists(path)
+	ok = files.Exists(path) || files.Exists(path+chunkedBlobManifestSuffix) ||
+		(blobStore.smallBlobs != nil && blobStore.smallBlobs.has(merkleId)) ||
+		hasDryRunBlob(blobStore.dryRun, merkleId)
 
 	return ok
 }
 
 func (blobStore localHashBucketed) AllBlobs() interfaces.SeqError[domain_interfaces.MarklId] {
+	var seq interfaces.SeqError[domain_interfaces.MarklId]
+
 	if blobStore.multiHash {
-		return localAllBlobsMultihash(blobStore.basePath)
+		seq = localAllBlobsMultihash(blobStore.basePath)
 	} else {
-		return localAllBlobs(blobStore.basePath, blobStore.defaultHashFormat)
+		seq = localAllBlobs(blobStore.basePath, blobStore.defaultHashFormat)
+	}
+
+	if blobStore.smallBlobs == nil {
+		return seq
+	}
+
+	return func(yield func(domain_interfaces.MarklId, error) bool) {
+		for id, err := range seq {
+			if !yield(id, err) {
+				return
+			}
+		}
+
+		ids, err := blobStore.smallBlobs.getIds()
+		if err != nil {
+			yield(nil, errors.Wrap(err))
+			return
+		}
+
+		for _, id := range ids {
+			// a blob whose file has yet to be removed was listed already
+			if files.Exists(env_dir.MakeHashBucketPathFromMerkleId(
+				id,
+				blobStore.buckets,
+				blobStore.multiHash,
+				blobStore.basePath,
+			)) {
+				continue
+			}
+
+			if !yield(id, nil) {
+				return
+			}
+		}
 	}
 }
 
 func (blobStore localHashBucketed) MakeBlobReader(
 	digest domain_interfaces.MarklId,
 ) (readCloser domain_interfaces.BlobReader, err error) {
+	defer observeBlobReader(blobStore, time.Now(), &readCloser, &err)
+
 	if digest.IsNull() {
 		hash, _ := blobStore.defaultHashFormat.Get() //repool:owned
 		readCloser = markl_io.MakeNopReadCloser(
@@ -129,7 +210,14 @@ func (blobStore localHashBucketed) MakeBlobReader(
 	if readCloser, err = blobStore.blobReaderFrom(
 		digest,
 		blobStore.basePath,
-	); err != nil {
+	); env_dir.IsErrBlobMissing(err) && hasDryRunBlob(blobStore.dryRun, digest) {
+		readCloser, err = blobStore.dryRun.blobReaderFrom(
+			digest,
+			blobStore.dryRun.basePath,
+		)
+	}
+
+	if err != nil {
 		if !env_dir.IsErrBlobMissing(err) {
 			err = errors.Wrap(err)
 		}
@@ -143,6 +231,19 @@ func (blobStore localHashBucketed) MakeBlobReader(
 func (blobStore localHashBucketed) MakeBlobWriter(
 	marklHashType domain_interfaces.FormatHash,
 ) (blobWriter domain_interfaces.BlobWriter, err error) {
+	defer observeBlobWriter(blobStore, time.Now(), &blobWriter, &err)
+
+	if err = errIfReadOnly(blobStore.readOnly, blobStore.basePath); err != nil {
+		return blobWriter, err
+	}
+
+	if blobStore.dryRun != nil {
+		return blobStore.dryRun.blobWriterTo(
+			blobStore.dryRun.basePath,
+			marklHashType,
+		)
+	}
+
 	if blobWriter, err = blobStore.blobWriterTo(
 		blobStore.basePath,
 		marklHashType,
@@ -151,6 +252,13 @@ func (blobStore localHashBucketed) MakeBlobWriter(
 		return blobWriter, err
 	}
 
+	if blobStore.chunkedBlobs != nil || blobStore.smallBlobs != nil {
+		blobWriter = &placingBlobWriter{
+			BlobWriter: blobWriter,
+			store:      blobStore,
+		}
+	}
+
 	return blobWriter, err
 }
 
@@ -169,13 +277,19 @@ func (blobStore localHashBucketed) blobWriterTo(
 		)
 	}
 
+	moveOptions := env_dir.MoveOptions{
+		FinalPathOrDir:              path,
+		GenerateFinalPathFromDigest: true,
+		TemporaryFS:                 blobStore.tempFS,
+	}
+
+	if blobStore.budget != nil {
+		moveOptions.ReserveBytes = blobStore.budget.reserve
+	}
+
 	if mover, err = env_dir.NewMover(
 		blobStore.makeEnvDirConfig(hashFormat),
-		env_dir.MoveOptions{
-			FinalPathOrDir:              path,
-			GenerateFinalPathFromDigest: true,
-			TemporaryFS:                 blobStore.tempFS,
-		},
+		moveOptions,
 	); err != nil {
 		err = errors.Wrap(err)
 		return mover, err
@@ -184,6 +298,52 @@ func (blobStore localHashBucketed) blobWriterTo(
 	return mover, err
 }
 
+// placingBlobWriter is a local store's blob writer that, on Close, replaces
+// a blob of at least the store's chunking threshold with its chunks and
+// manifest, and one under its inline threshold with an entry in the
+// small-blob archive.
+type placingBlobWriter struct {
+	domain_interfaces.BlobWriter
+	store localHashBucketed
+	size  int64
+}
+
+func (writer *placingBlobWriter) Write(p []byte) (n int, err error) {
+	n, err = writer.BlobWriter.Write(p)
+	writer.size += int64(n)
+	return n, err
+}
+
+func (writer *placingBlobWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	n, err = writer.BlobWriter.ReadFrom(r)
+	writer.size += n
+	return n, err
+}
+
+func (writer *placingBlobWriter) Close() (err error) {
+	if err = writer.BlobWriter.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	chunkedBlobs := writer.store.chunkedBlobs
+	smallBlobs := writer.store.smallBlobs
+
+	if chunkedBlobs != nil && writer.size >= chunkedBlobs.threshold {
+		if err = writer.store.chunkBlob(writer.GetMarklId()); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	} else if smallBlobs != nil && writer.size < smallBlobs.threshold {
+		if err = writer.store.inlineBlob(writer.GetMarklId()); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
+
 func (blobStore localHashBucketed) blobReaderFrom(
 	digest domain_interfaces.MarklId,
 	basePath string,
@@ -209,6 +369,9 @@ func (blobStore localHashBucketed) blobReaderFrom(
 		return readCloser, err
 	}
 
+	// chunks of a chunked blob are read from the same base path
+	storePath := basePath
+
 	basePath = env_dir.MakeHashBucketPathFromMerkleId(
 		digest,
 		blobStore.buckets,
@@ -229,7 +392,29 @@ func (blobStore localHashBucketed) blobReaderFrom(
 		blobStore.makeEnvDirConfig(hashFormat),
 		basePath,
 	); err != nil {
+		if errors.IsNotExist(err) &&
+			files.Exists(basePath+chunkedBlobManifestSuffix) {
+			return blobStore.chunkedBlobReaderFrom(
+				hashFormat,
+				basePath+chunkedBlobManifestSuffix,
+				storePath,
+			)
+		}
+
 		if errors.IsNotExist(err) {
+			var inlined domain_interfaces.BlobReader
+
+			if inlined, err = blobStore.smallBlobReaderFrom(
+				hashFormat,
+				digest,
+			); err != nil {
+				err = errors.Wrap(err)
+				return readCloser, err
+			} else if inlined != nil {
+				readCloser = inlined
+				return readCloser, err
+			}
+
 			err = env_dir.ErrBlobMissing{
 				BlobId: func() domain_interfaces.MarklId { id, _ := markl.Clone(digest); return id }(),
 				Path:   basePath,
@@ -252,6 +437,14 @@ func (blobStore localHashBucketed) blobReaderFrom(
 func (blobStore localHashBucketed) DeleteBlob(
 	id domain_interfaces.MarklId,
 ) (err error) {
+	if err = errIfReadOnly(blobStore.readOnly, blobStore.basePath); err != nil {
+		return err
+	}
+
+	if blobStore.dryRun != nil {
+		return err
+	}
+
 	path := env_dir.MakeHashBucketPathFromMerkleId(
 		id,
 		blobStore.buckets,
@@ -259,11 +452,40 @@ func (blobStore localHashBucketed) DeleteBlob(
 		blobStore.basePath,
 	)
 
+	// a chunked blob's chunks may be shared, so only its manifest is deleted
+	if !files.Exists(path) && files.Exists(path+chunkedBlobManifestSuffix) {
+		path += chunkedBlobManifestSuffix
+	} else if !files.Exists(path) && blobStore.smallBlobs != nil &&
+		blobStore.smallBlobs.has(id) {
+		// an inlined blob's bytes stay in the archive
+		if err = blobStore.smallBlobs.addEntry(
+			id,
+			smallBlobEntry{offset: smallBlobsTombstoneOffset},
+		); err != nil {
+			err = errors.Wrapf(err, "deleting blob %s", id)
+			return err
+		}
+
+		return err
+	}
+
+	var size int64
+
+	if blobStore.budget != nil {
+		if info, statErr := os.Stat(path); statErr == nil {
+			size = info.Size()
+		}
+	}
+
 	if err = os.Remove(path); err != nil {
 		err = errors.Wrapf(err, "deleting blob %s", id)
 		return err
 	}
 
+	if blobStore.budget != nil {
+		blobStore.budget.release(size)
+	}
+
 	return nil
 }
 
@@ -271,6 +493,14 @@ func (blobStore localHashBucketed) AddForeignBlobDigestForNativeDigest(
 	foreign domain_interfaces.MarklId,
 	native domain_interfaces.MarklId,
 ) (err error) {
+	if err = errIfReadOnly(blobStore.readOnly, blobStore.basePath); err != nil {
+		return err
+	}
+
+	if blobStore.dryRun != nil {
+		return err
+	}
+
 	if !blobStore.multiHash {
 		err = errors.Errorf(
 			"single-hash store does not support foreign digest mapping",
@@ -292,6 +522,28 @@ func (blobStore localHashBucketed) AddForeignBlobDigestForNativeDigest(
 		blobStore.basePath,
 	)
 
+	// a chunked native blob is mapped through its manifest
+	if !files.Exists(nativePath) &&
+		files.Exists(nativePath+chunkedBlobManifestSuffix) {
+		nativePath += chunkedBlobManifestSuffix
+		foreignPath += chunkedBlobManifestSuffix
+	} else if !files.Exists(nativePath) && blobStore.smallBlobs != nil {
+		// and an inlined one is indexed again under the foreign digest
+		if entry, ok, lookupErr := blobStore.smallBlobs.lookup(
+			native,
+		); lookupErr != nil {
+			err = errors.Wrap(lookupErr)
+			return err
+		} else if ok {
+			if err = blobStore.smallBlobs.addEntry(foreign, entry); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			return err
+		}
+	}
+
 	foreignDir := filepath.Dir(foreignPath)
 
 	if err = os.MkdirAll(foreignDir, os.ModeDir|0o755); err != nil {
@@ -0,0 +1,614 @@
+package blob_stores
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+const (
+	// the small-blob archive of a local store lives in this directory of its
+	// base path: one data file shared by every hash format, and one index per
+	// hash format
+	smallBlobsDirName        = "small_blobs"
+	smallBlobsDataFileName   = "data.small_blobs-v1"
+	smallBlobsIndexExtension = ".small_blobs_index-v1"
+
+	smallBlobsIndexMagic             = "MISB"
+	smallBlobsIndexVersion    uint16 = 1
+	smallBlobsIndexHeaderSize        = 6
+
+	// an index record with this offset deletes the blob it names
+	smallBlobsTombstoneOffset = ^uint64(0)
+)
+
+// smallBlobs is the small-blob archive of a local store that keeps blobs
+// under threshold bytes inline rather than in a file each. Blobs are appended
+// to the data file as the store would have written their files (compressed
+// and encrypted alike), and indexed by fixed-width records of digest, offset
+// and stored size appended to their hash format's index. Later records win,
+// so a blob is deleted by appending a tombstone. Appends hold a flock(2) on
+// the data file, and every lookup first loads whatever records other
+// processes appended since.
+type smallBlobs struct {
+	threshold int64
+	path      string
+
+	lock    sync.Mutex
+	data    *os.File
+	indexes map[string]*smallBlobIndex
+}
+
+type smallBlobEntry struct {
+	offset, size uint64
+}
+
+type smallBlobIndex struct {
+	path     string
+	hashSize int
+	loaded   int64
+	entries  map[string]smallBlobEntry
+}
+
+func makeSmallBlobs(
+	config blob_store_configs.Config,
+	basePath string,
+) *smallBlobs {
+	inlineConfig, ok := config.(blob_store_configs.ConfigInlineBlobs)
+
+	if !ok || inlineConfig.GetInlineBlobThreshold() == 0 || basePath == "" {
+		return nil
+	}
+
+	return &smallBlobs{
+		threshold: int64(inlineConfig.GetInlineBlobThreshold()),
+		path:      filepath.Join(basePath, smallBlobsDirName),
+		indexes:   make(map[string]*smallBlobIndex),
+	}
+}
+
+func (blobs *smallBlobs) getDataPath() string {
+	return filepath.Join(blobs.path, smallBlobsDataFileName)
+}
+
+// getIndex returns the index of formatId, loading any records appended since
+// it was last read. The caller holds blobs.lock.
+func (blobs *smallBlobs) getIndex(
+	formatId string,
+) (index *smallBlobIndex, err error) {
+	index, ok := blobs.indexes[formatId]
+
+	if !ok {
+		var hashFormat markl.FormatHash
+
+		if hashFormat, err = markl.GetFormatHashOrError(formatId); err != nil {
+			err = errors.Wrap(err)
+			return index, err
+		}
+
+		index = &smallBlobIndex{
+			path:     filepath.Join(blobs.path, formatId+smallBlobsIndexExtension),
+			hashSize: hashFormat.GetSize(),
+			entries:  make(map[string]smallBlobEntry),
+		}
+
+		blobs.indexes[formatId] = index
+	}
+
+	if err = index.load(); err != nil {
+		err = errors.Wrapf(err, "small blob index %q", index.path)
+		return index, err
+	}
+
+	return index, err
+}
+
+func (index *smallBlobIndex) getRecordSize() int64 {
+	return int64(index.hashSize) + 16
+}
+
+// load reads the complete records past the ones already loaded. A partial
+// record left by an interrupted append is ignored, and cut off by the next
+// append.
+func (index *smallBlobIndex) load() (err error) {
+	var file *os.File
+
+	if file, err = os.Open(index.path); err != nil {
+		if errors.IsNotExist(err) {
+			err = nil
+		}
+
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	var info os.FileInfo
+
+	if info, err = file.Stat(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if info.Size() < smallBlobsIndexHeaderSize ||
+		info.Size() == index.loaded {
+		return err
+	}
+
+	if index.loaded == 0 {
+		header := make([]byte, smallBlobsIndexHeaderSize)
+
+		if _, err = io.ReadFull(file, header); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if string(header[:4]) != smallBlobsIndexMagic {
+			err = errors.Errorf("bad magic %q", header[:4])
+			return err
+		}
+
+		if version := binary.BigEndian.Uint16(header[4:]); version != smallBlobsIndexVersion {
+			err = errors.Errorf("unsupported version %d", version)
+			return err
+		}
+
+		index.loaded = smallBlobsIndexHeaderSize
+	}
+
+	recordSize := index.getRecordSize()
+	count := (info.Size() - index.loaded) / recordSize
+	records := make([]byte, count*recordSize)
+
+	if _, err = file.ReadAt(records, index.loaded); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	for record := range slices.Chunk(records, int(recordSize)) {
+		digest := string(record[:index.hashSize])
+
+		entry := smallBlobEntry{
+			offset: binary.BigEndian.Uint64(record[index.hashSize:]),
+			size:   binary.BigEndian.Uint64(record[index.hashSize+8:]),
+		}
+
+		if entry.offset == smallBlobsTombstoneOffset {
+			delete(index.entries, digest)
+		} else {
+			index.entries[digest] = entry
+		}
+	}
+
+	index.loaded += count * recordSize
+
+	return err
+}
+
+func (blobs *smallBlobs) lookup(
+	id domain_interfaces.MarklId,
+) (entry smallBlobEntry, ok bool, err error) {
+	blobs.lock.Lock()
+	defer blobs.lock.Unlock()
+
+	var index *smallBlobIndex
+
+	if index, err = blobs.getIndex(
+		id.GetMarklFormat().GetMarklFormatId(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return entry, ok, err
+	}
+
+	entry, ok = index.entries[string(id.GetBytes())]
+
+	return entry, ok, err
+}
+
+func (blobs *smallBlobs) has(id domain_interfaces.MarklId) bool {
+	_, ok, err := blobs.lookup(id)
+	return ok && err == nil
+}
+
+// openEntry returns a reader of an entry's stored bytes. The data file stays
+// open for later reads, so closing the reader is not needed.
+func (blobs *smallBlobs) openEntry(
+	entry smallBlobEntry,
+) (reader *io.SectionReader, err error) {
+	blobs.lock.Lock()
+	defer blobs.lock.Unlock()
+
+	if blobs.data == nil {
+		if blobs.data, err = os.Open(blobs.getDataPath()); err != nil {
+			err = errors.Wrap(err)
+			return reader, err
+		}
+	}
+
+	reader = io.NewSectionReader(
+		blobs.data,
+		int64(entry.offset),
+		int64(entry.size),
+	)
+
+	return reader, err
+}
+
+// add appends the stored bytes of id to the archive, unless it already holds
+// id, in which case added is false.
+func (blobs *smallBlobs) add(
+	id domain_interfaces.MarklId,
+	stored []byte,
+) (added bool, err error) {
+	return blobs.appendStored(id, stored, false)
+}
+
+// replace appends the stored bytes of id to the archive even if it already
+// holds id. The new record wins, and the old bytes stay in the data file.
+func (blobs *smallBlobs) replace(
+	id domain_interfaces.MarklId,
+	stored []byte,
+) (err error) {
+	_, err = blobs.appendStored(id, stored, true)
+	return err
+}
+
+func (blobs *smallBlobs) appendStored(
+	id domain_interfaces.MarklId,
+	stored []byte,
+	replace bool,
+) (added bool, err error) {
+	err = blobs.withAppendLock(
+		func(data *os.File) (err error) {
+			var index *smallBlobIndex
+
+			if index, err = blobs.getIndex(
+				id.GetMarklFormat().GetMarklFormatId(),
+			); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			if _, ok := index.entries[string(id.GetBytes())]; ok && !replace {
+				return err
+			}
+
+			var info os.FileInfo
+
+			if info, err = data.Stat(); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			if _, err = data.Write(stored); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			if err = data.Sync(); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			if err = index.append(
+				id,
+				smallBlobEntry{
+					offset: uint64(info.Size()),
+					size:   uint64(len(stored)),
+				},
+			); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			added = true
+
+			return err
+		},
+	)
+
+	return added, err
+}
+
+// addEntry indexes entry under id, whose format may differ from that of the
+// blob the entry was added for. A tombstone entry deletes id.
+func (blobs *smallBlobs) addEntry(
+	id domain_interfaces.MarklId,
+	entry smallBlobEntry,
+) (err error) {
+	return blobs.withAppendLock(
+		func(*os.File) (err error) {
+			var index *smallBlobIndex
+
+			if index, err = blobs.getIndex(
+				id.GetMarklFormat().GetMarklFormatId(),
+			); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			if err = index.append(id, entry); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			return err
+		},
+	)
+}
+
+// withAppendLock runs appendFunc with the data file open for appending and
+// flock'd, and with blobs.lock held.
+func (blobs *smallBlobs) withAppendLock(
+	appendFunc func(*os.File) error,
+) (err error) {
+	blobs.lock.Lock()
+	defer blobs.lock.Unlock()
+
+	if err = os.MkdirAll(blobs.path, 0o755); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var data *os.File
+
+	if data, err = os.OpenFile(
+		blobs.getDataPath(),
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0o644,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// closing the file drops the flock
+	defer errors.DeferredCloser(&err, data)
+
+	for {
+		err = syscall.Flock(int(data.Fd()), syscall.LOCK_EX)
+
+		if err != syscall.EINTR {
+			break
+		}
+	}
+
+	if err != nil {
+		err = errors.Wrapf(err, "locking %s", blobs.getDataPath())
+		return err
+	}
+
+	if err = appendFunc(data); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// append writes a record for id to the index, which the caller has loaded
+// while holding the append lock, first writing the header of a new index and
+// cutting off a partial record.
+func (index *smallBlobIndex) append(
+	id domain_interfaces.MarklId,
+	entry smallBlobEntry,
+) (err error) {
+	var file *os.File
+
+	if file, err = os.OpenFile(
+		index.path,
+		os.O_WRONLY|os.O_CREATE,
+		0o644,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	offset := index.loaded
+
+	if offset == 0 {
+		header := make([]byte, smallBlobsIndexHeaderSize)
+		copy(header, smallBlobsIndexMagic)
+		binary.BigEndian.PutUint16(header[4:], smallBlobsIndexVersion)
+
+		if _, err = file.WriteAt(header, 0); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		offset = smallBlobsIndexHeaderSize
+	}
+
+	record := make([]byte, index.getRecordSize())
+	copy(record, id.GetBytes())
+	binary.BigEndian.PutUint64(record[index.hashSize:], entry.offset)
+	binary.BigEndian.PutUint64(record[index.hashSize+8:], entry.size)
+
+	if err = file.Truncate(offset); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if _, err = file.WriteAt(record, offset); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = file.Sync(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	digest := string(id.GetBytes())
+
+	if entry.offset == smallBlobsTombstoneOffset {
+		delete(index.entries, digest)
+	} else {
+		index.entries[digest] = entry
+	}
+
+	index.loaded = offset + int64(len(record))
+
+	return err
+}
+
+// getIds returns the ids of every blob in the archive, ordered by format and
+// digest.
+func (blobs *smallBlobs) getIds() (ids []markl.Id, err error) {
+	blobs.lock.Lock()
+	defer blobs.lock.Unlock()
+
+	var dirEntries []os.DirEntry
+
+	if dirEntries, err = files.DirEntries(blobs.path); err != nil {
+		if errors.IsNotExist(err) {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return ids, err
+	}
+
+	for _, dirEntry := range dirEntries {
+		formatId, ok := strings.CutSuffix(
+			dirEntry.Name(),
+			smallBlobsIndexExtension,
+		)
+
+		if !ok {
+			continue
+		}
+
+		var index *smallBlobIndex
+
+		if index, err = blobs.getIndex(formatId); err != nil {
+			err = errors.Wrap(err)
+			return ids, err
+		}
+
+		digests := make([]string, 0, len(index.entries))
+
+		for digest := range index.entries {
+			digests = append(digests, digest)
+		}
+
+		slices.Sort(digests)
+
+		for _, digest := range digests {
+			var id markl.Id
+
+			if err = id.SetMarklId(formatId, []byte(digest)); err != nil {
+				err = errors.Wrap(err)
+				return ids, err
+			}
+
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, err
+}
+
+// inlineBlob moves the file of a blob under the inline threshold into the
+// small-blob archive. The file is only removed once its record is in the
+// index, so an interruption leaves the blob readable.
+func (blobStore localHashBucketed) inlineBlob(
+	id domain_interfaces.MarklId,
+) (err error) {
+	if id.IsNull() {
+		return err
+	}
+
+	path := env_dir.MakeHashBucketPathFromMerkleId(
+		id,
+		blobStore.buckets,
+		blobStore.multiHash,
+		blobStore.basePath,
+	)
+
+	var stored []byte
+
+	if stored, err = os.ReadFile(path); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// the archive grows by what removing the file releases
+	if blobStore.budget != nil {
+		if err = blobStore.budget.reserve(int64(len(stored))); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	var added bool
+
+	if added, err = blobStore.smallBlobs.add(id, stored); err != nil {
+		err = errors.Wrapf(err, "inlining blob %s", id)
+	}
+
+	if !added && blobStore.budget != nil {
+		blobStore.budget.release(int64(len(stored)))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = blobStore.removeBlobFile(path); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// smallBlobReaderFrom returns a reader of id from the small-blob archive, or
+// a nil reader if the archive does not hold it.
+func (blobStore localHashBucketed) smallBlobReaderFrom(
+	hashFormat markl.FormatHash,
+	id domain_interfaces.MarklId,
+) (readCloser domain_interfaces.BlobReader, err error) {
+	if blobStore.smallBlobs == nil {
+		return readCloser, err
+	}
+
+	var entry smallBlobEntry
+	var ok bool
+
+	if entry, ok, err = blobStore.smallBlobs.lookup(id); err != nil || !ok {
+		err = errors.Wrap(err)
+		return readCloser, err
+	}
+
+	var section *io.SectionReader
+
+	if section, err = blobStore.smallBlobs.openEntry(entry); err != nil {
+		err = errors.Wrap(err)
+		return readCloser, err
+	}
+
+	if readCloser, err = env_dir.NewReader(
+		blobStore.makeEnvDirConfig(hashFormat),
+		section,
+	); err != nil {
+		err = errors.Wrapf(err, "reading inlined blob %s", id)
+		return readCloser, err
+	}
+
+	return readCloser, err
+}
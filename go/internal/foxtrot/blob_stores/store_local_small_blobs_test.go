@@ -0,0 +1,111 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+func makeTestInlineLocalHashBucketed(t *testing.T) localHashBucketed {
+	store := makeTestLocalHashBucketed(t)
+	store.smallBlobs = &smallBlobs{
+		threshold: 64,
+		path:      filepath.Join(store.basePath, smallBlobsDirName),
+		indexes:   make(map[string]*smallBlobIndex),
+	}
+
+	return store
+}
+
+func TestSmallBlobsRoundTrip(t *testing.T) {
+	store := makeTestInlineLocalHashBucketed(t)
+	small := writeTestBlob(t, store, "a short note")
+	large := writeTestBlob(t, store, makeChunkedTestData(1024, 3))
+
+	if files.Exists(testBlobPath(store, small)) {
+		t.Error("expected the small blob's file to be removed")
+	}
+
+	if !files.Exists(testBlobPath(store, large)) {
+		t.Error("expected the large blob to keep its file")
+	}
+
+	if !store.HasBlob(small) {
+		t.Error("expected the inlined blob to be present")
+	}
+
+	if actual := readTestBlob(t, store, small); actual != "a short note" {
+		t.Errorf("expected %q but got %q", "a short note", actual)
+	}
+
+	if count := countTestBlobs(t, store); count != 2 {
+		t.Errorf("expected 2 blobs, got %d", count)
+	}
+
+	if err := store.DeleteBlob(small); err != nil {
+		t.Fatalf("DeleteBlob: %v", err)
+	}
+
+	if store.HasBlob(small) {
+		t.Error("expected the inlined blob to be deleted")
+	}
+
+	if count := countTestBlobs(t, store); count != 1 {
+		t.Errorf("expected 1 blob after deleting, got %d", count)
+	}
+}
+
+func TestSmallBlobsRewriteIsNotAppendedTwice(t *testing.T) {
+	store := makeTestInlineLocalHashBucketed(t)
+
+	writeTestBlob(t, store, "written twice")
+
+	info, err := os.Stat(store.smallBlobs.getDataPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := writeTestBlob(t, store, "written twice")
+
+	after, err := os.Stat(store.smallBlobs.getDataPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if after.Size() != info.Size() {
+		t.Errorf("expected the archive to stay %d bytes, it is %d", info.Size(), after.Size())
+	}
+
+	if files.Exists(testBlobPath(store, id)) {
+		t.Error("expected the rewritten blob's file to be removed")
+	}
+}
+
+func TestSmallBlobsSeenByOtherStoreValues(t *testing.T) {
+	store := makeTestInlineLocalHashBucketed(t)
+	other := store
+	other.smallBlobs = &smallBlobs{
+		threshold: store.smallBlobs.threshold,
+		path:      store.smallBlobs.path,
+		indexes:   make(map[string]*smallBlobIndex),
+	}
+
+	// loads the empty index before the other store appends to it
+	if countTestBlobs(t, other) != 0 {
+		t.Fatal("expected an empty store")
+	}
+
+	id := writeTestBlob(t, store, "appended by another process")
+
+	if !other.HasBlob(id) {
+		t.Fatal("expected records appended elsewhere to be loaded")
+	}
+
+	if actual := readTestBlob(t, other, id); actual != "appended by another process" {
+		t.Errorf("unexpected content %q", actual)
+	}
+}
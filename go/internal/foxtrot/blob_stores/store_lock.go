@@ -0,0 +1,97 @@
+package blob_stores
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// ErrStoreBusy is returned when a store's lock is held by another pack or
+// cache write and the caller asked not to wait for it.
+type ErrStoreBusy struct {
+	Path string
+}
+
+func (err ErrStoreBusy) Error() string {
+	return fmt.Sprintf(
+		"blob store %s is busy: another pack or cache write is in progress",
+		err.Path,
+	)
+}
+
+func (err ErrStoreBusy) Is(target error) bool {
+	_, ok := target.(ErrStoreBusy)
+	return ok
+}
+
+func (err ErrStoreBusy) GetErrorCode() errors.Code {
+	return errors.CodeLockConflict
+}
+
+// storeLock is an advisory flock(2) on a store's base directory. It
+// serializes packs and cache writes across processes, and is released by the
+// kernel if its holder dies. Locks taken through separate calls conflict
+// even within one process, so a holder must not lock the store again.
+type storeLock struct {
+	file *os.File
+}
+
+// lockStore acquires the lock on basePath, waiting for other holders unless
+// noWait is set, in which case it fails with ErrStoreBusy.
+func lockStore(basePath string, noWait bool) (lock storeLock, err error) {
+	if err = os.MkdirAll(basePath, 0o755); err != nil {
+		err = errors.Wrapf(err, "creating store directory %s", basePath)
+		return lock, err
+	}
+
+	if lock.file, err = os.Open(basePath); err != nil {
+		err = errors.Wrapf(err, "opening store directory %s", basePath)
+		return lock, err
+	}
+
+	how := syscall.LOCK_EX
+
+	if noWait {
+		how |= syscall.LOCK_NB
+	}
+
+	for {
+		err = syscall.Flock(int(lock.file.Fd()), how)
+
+		if err != syscall.EINTR {
+			break
+		}
+	}
+
+	if err == nil {
+		return lock, err
+	}
+
+	lock.file.Close()
+	lock.file = nil
+
+	if err == syscall.EWOULDBLOCK {
+		err = ErrStoreBusy{Path: basePath}
+		return lock, err
+	}
+
+	err = errors.Wrapf(err, "locking store directory %s", basePath)
+
+	return lock, err
+}
+
+// Unlock releases the lock. Closing the directory drops the flock.
+func (lock storeLock) Unlock() (err error) {
+	if lock.file == nil {
+		return err
+	}
+
+	if err = lock.file.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
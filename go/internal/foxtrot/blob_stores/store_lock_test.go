@@ -0,0 +1,102 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+func TestPackNoWaitReturnsErrStoreBusy(t *testing.T) {
+	hashFormat := markl.FormatHashSha256
+	basePath := t.TempDir()
+	cachePath := t.TempDir()
+
+	data := []byte("locked blob")
+	rawHash := sha256.Sum256(data)
+	id, repool := hashFormat.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	defer repool()
+
+	makeStore := func() inventoryArchiveV1 {
+		return inventoryArchiveV1{
+			defaultHash: hashFormat,
+			basePath:    basePath,
+			cachePath:   cachePath,
+			looseBlobStore: &stubBlobStore{
+				allBlobIds: []domain_interfaces.MarklId{id},
+				blobData:   map[string][]byte{id.String(): data},
+			},
+			index: make(map[string]archiveEntryV1),
+			config: blob_store_configs.TomlInventoryArchiveV2{
+				HashTypeId:      markl.FormatIdHashSha256,
+				CompressionType: compression_type.CompressionTypeNone,
+				FooterIndex:     true,
+			},
+			generation: &archiveGeneration{},
+		}
+	}
+
+	store := makeStore()
+
+	lock, err := lockStore(basePath, false)
+	if err != nil {
+		t.Fatalf("lockStore: %v", err)
+	}
+
+	err = store.Pack(PackOptions{NoWait: true})
+	if !errors.Is(err, ErrStoreBusy{}) {
+		t.Fatalf("expected ErrStoreBusy, got %v", err)
+	}
+
+	if len(store.index) != 0 {
+		t.Fatal("a busy store should not have packed anything")
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	if err := store.Pack(PackOptions{NoWait: true}); err != nil {
+		t.Fatalf("Pack after unlock: %v", err)
+	}
+
+	if _, ok := store.index[id.String()]; !ok {
+		t.Fatal("expected the blob to be packed after the lock was released")
+	}
+
+	// A store opened while another process holds the lock still loads its
+	// index, but leaves the cache to the lock holder.
+	if err := os.Remove(store.cacheFilePath()); err != nil {
+		t.Fatalf("removing cache: %v", err)
+	}
+
+	if lock, err = lockStore(basePath, false); err != nil {
+		t.Fatalf("lockStore: %v", err)
+	}
+
+	defer lock.Unlock()
+
+	reopened := makeStore()
+
+	if err := reopened.loadIndex(); err != nil {
+		t.Fatalf("loadIndex: %v", err)
+	}
+
+	if _, ok := reopened.index[id.String()]; !ok {
+		t.Fatal("expected the reopened store to rebuild its index")
+	}
+
+	if _, err := os.Stat(reopened.cacheFilePath()); !os.IsNotExist(err) {
+		t.Fatalf("expected no cache while the store is locked, got %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
@@ -45,17 +46,39 @@ type remoteSftp struct {
 	sshClient            *ssh.Client
 	sftpClient           *sftp.Client
 
+	// nil unless the config sets a budget
+	budget *storeBudget
+
+	// nil unless the config sets transfer caps
+	transferLimiter *TransferLimiter
+
+	// local directory for the journal of resumable uploads, empty when the
+	// store has no base path
+	transfersPath string
+
+	// set by -read-only or the config; writes fail with env_dir.ErrReadOnly
+	readOnly bool
+
+	// nil outside of -dry-run; takes the writes instead of the remote
+	dryRun *localHashBucketed
+
 	// TODO extract below into separate struct
 	blobCacheLock sync.RWMutex
 	blobCache     map[string]struct{}
 }
 
-var _ domain_interfaces.BlobStore = &remoteSftp{}
+var (
+	_ domain_interfaces.BlobStore = &remoteSftp{}
+	_ TransferLimited             = &remoteSftp{}
+)
 
 func makeSftpStore(
 	ctx interfaces.ActiveContext,
 	uiPrinter ui.Printer,
+	basePath string,
 	config blob_store_configs.ConfigSFTPRemotePath,
+	readOnly bool,
+	dryRun *localHashBucketed,
 	sshClientInitializer func() (*ssh.Client, error),
 ) (blobStore *remoteSftp, err error) {
 	var defaultHashType markl.FormatHash
@@ -74,9 +97,23 @@ func makeSftpStore(
 		buckets:              defaultBuckets,
 		config:               config,
 		blobCache:            make(map[string]struct{}),
+		readOnly:             readOnly,
+		dryRun:               dryRun,
 		sshClientInitializer: sshClientInitializer,
 	}
 
+	blobStore.budget = makeStoreBudget(
+		config,
+		config.GetRemotePath(),
+		blobStore.storedBytes,
+	)
+
+	blobStore.transferLimiter = makeTransferLimiterForConfig(config)
+
+	if basePath != "" {
+		blobStore.transfersPath = filepath.Join(basePath, sftpTransfersDirName)
+	}
+
 	return blobStore, err
 }
 
@@ -84,6 +121,10 @@ func (blobStore *remoteSftp) GetBlobStoreConfig() blob_store_configs.Config {
 	return blobStore.config
 }
 
+func (blobStore *remoteSftp) GetTransferLimiter() *TransferLimiter {
+	return blobStore.transferLimiter
+}
+
 func (blobStore *remoteSftp) GetDefaultHashType() domain_interfaces.FormatHash {
 	return blobStore.defaultHashType
 }
@@ -188,6 +229,8 @@ func (blobStore *remoteSftp) remotePathForMerkleId(
 func (blobStore *remoteSftp) HasBlob(
 	merkleId domain_interfaces.MarklId,
 ) (ok bool) {
+	defer observeLookup(blobStore, time.Now(), &ok)
+
 	blobStore.initializeOnce()
 
 	if merkleId.IsNull() {
@@ -197,12 +240,20 @@ func (blobStore *remoteSftp) HasBlob(
 
 	blobStore.blobCacheLock.RLock()
 
-	if _, ok = blobStore.blobCache[string(merkleId.GetBytes())]; ok {
-		blobStore.blobCacheLock.RUnlock()
+	_, ok = blobStore.blobCache[string(merkleId.GetBytes())]
+
+	blobStore.blobCacheLock.RUnlock()
+
+	observeCache(blobStore, ok)
+
+	if ok {
 		return ok
 	}
 
-	blobStore.blobCacheLock.RUnlock()
+	if hasDryRunBlob(blobStore.dryRun, merkleId) {
+		ok = true
+		return ok
+	}
 
 	remotePath := blobStore.remotePathForMerkleId(merkleId)
 
@@ -284,6 +335,22 @@ func (blobStore *remoteSftp) AllBlobs() interfaces.SeqError[domain_interfaces.Ma
 func (blobStore *remoteSftp) MakeBlobWriter(
 	marklHashType domain_interfaces.FormatHash,
 ) (blobWriter domain_interfaces.BlobWriter, err error) {
+	defer observeBlobWriter(blobStore, time.Now(), &blobWriter, &err)
+
+	if err = errIfReadOnly(
+		blobStore.readOnly,
+		blobStore.config.GetRemotePath(),
+	); err != nil {
+		return blobWriter, err
+	}
+
+	if blobStore.dryRun != nil {
+		return blobStore.dryRun.blobWriterTo(
+			blobStore.dryRun.basePath,
+			blobStore.defaultHashType,
+		)
+	}
+
 	blobStore.initializeOnce()
 
 	// TODO use hash type
@@ -307,6 +374,8 @@ func (blobStore *remoteSftp) MakeBlobWriter(
 func (blobStore *remoteSftp) MakeBlobReader(
 	digest domain_interfaces.MarklId,
 ) (readCloser domain_interfaces.BlobReader, err error) {
+	defer observeBlobReader(blobStore, time.Now(), &readCloser, &err)
+
 	blobStore.initializeOnce()
 
 	if digest.IsNull() {
@@ -318,6 +387,13 @@ func (blobStore *remoteSftp) MakeBlobReader(
 		return readCloser, err
 	}
 
+	if hasDryRunBlob(blobStore.dryRun, digest) {
+		return blobStore.dryRun.blobReaderFrom(
+			digest,
+			blobStore.dryRun.basePath,
+		)
+	}
+
 	remotePath := blobStore.remotePathForMerkleId(digest)
 
 	var remoteFile *sftp.File
@@ -330,7 +406,7 @@ func (blobStore *remoteSftp) MakeBlobReader(
 				Path:   remotePath,
 			}
 		} else {
-			err = errors.Wrap(err)
+			err = markSftpErrorRetryable(errors.Wrap(err))
 		}
 		return readCloser, err
 	}
@@ -360,6 +436,17 @@ func (blobStore *remoteSftp) MakeBlobReader(
 	return readCloser, err
 }
 
+// markSftpErrorRetryable marks errors caused by a dropped SFTP connection as
+// transient so copy and replication can retry them.
+func markSftpErrorRetryable(err error) error {
+	if errors.Is(err, sftp.ErrSSHFxConnectionLost) ||
+		errors.Is(err, sftp.ErrSSHFxNoConnection) {
+		return errors.MarkRetryable(err)
+	}
+
+	return err
+}
+
 // sftpMover implements interfaces.Mover and interfaces.ShaWriteCloser
 // TODO explore using env_dir.Mover generically instead of this
 type sftpMover struct {
@@ -388,7 +475,7 @@ func (mover *sftpMover) initialize(hash domain_interfaces.Hash) (err error) {
 	if mover.tempFile, err = mover.store.sftpClient.Create(
 		mover.tempPath,
 	); err != nil {
-		ui.Debug().Printf("unable to create temp file: %q", mover.tempPath)
+		logger.Debug("unable to create temp file", "path", mover.tempPath)
 		err = errors.Wrapf(err, "unable to create temp file: %q", mover.tempPath)
 		return err
 	}
@@ -467,6 +554,30 @@ func (mover *sftpMover) Close() (err error) {
 	blobDigest := mover.writer.GetDigest()
 	finalPath := mover.store.remotePathForMerkleId(blobDigest)
 
+	if mover.store.budget != nil {
+		// an existing blob takes no more space, and the deferred cleanup
+		// removes the temp file
+		if _, statErr := mover.store.sftpClient.Stat(finalPath); statErr == nil {
+			mover.store.blobCacheLock.Lock()
+			mover.store.blobCache[string(blobDigest.GetBytes())] = struct{}{}
+			mover.store.blobCacheLock.Unlock()
+
+			return err
+		}
+
+		var tempInfo os.FileInfo
+
+		if tempInfo, err = mover.store.sftpClient.Stat(mover.tempPath); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if err = mover.store.budget.reserve(tempInfo.Size()); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	// Ensure the target directory exists (Git-like bucketing)
 	finalDir := path.Dir(finalPath)
 	if err = mover.store.sftpClient.MkdirAll(finalDir); err != nil {
@@ -0,0 +1,592 @@
+package blob_stores
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/sftp"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// BlobUploadResumer is implemented by remote stores that can continue an
+// interrupted blob upload from where it stopped instead of restarting it.
+// open is only called when no earlier attempt left the blob staged. pace
+// wraps the reader of the bytes sent to the remote, for transfer caps.
+type BlobUploadResumer interface {
+	ResumeBlobUpload(
+		expected domain_interfaces.MarklId,
+		open func() (domain_interfaces.BlobReader, error),
+		pace func(io.Reader) io.Reader,
+	) (size int64, err error)
+}
+
+var _ BlobUploadResumer = &remoteSftp{}
+
+const (
+	// directory in the store's base path holding staged uploads
+	sftpTransfersDirName = "transfers"
+
+	// blobs smaller than this are uploaded directly, as restarting them costs
+	// less than staging them
+	sftpResumableMinSize = 1 << 20
+
+	sftpTransferJournalVersion = "sftp_transfer-v1"
+)
+
+// sftpTransfer is a blob upload staged in the local transfer journal: the
+// blob, encoded as the remote stores it, in a spool file, and a journal file
+// naming the remote temp file the spool is appended to. Both are removed once
+// the temp file is renamed into place.
+type sftpTransfer struct {
+	spoolPath   string
+	journalPath string
+
+	// recorded in the journal
+	tempPath  string
+	size      int64
+	plainSize int64
+}
+
+func makeSftpTransfer(
+	transfersPath string,
+	id domain_interfaces.MarklId,
+) sftpTransfer {
+	name := fmt.Sprintf(
+		"%s-%s",
+		id.GetMarklFormat().GetMarklFormatId(),
+		markl.FormatBytesAsHex(id),
+	)
+
+	return sftpTransfer{
+		spoolPath:   filepath.Join(transfersPath, name+".spool"),
+		journalPath: filepath.Join(transfersPath, name+".journal"),
+	}
+}
+
+func (blobStore *remoteSftp) ResumeBlobUpload(
+	expected domain_interfaces.MarklId,
+	open func() (domain_interfaces.BlobReader, error),
+	pace func(io.Reader) io.Reader,
+) (size int64, err error) {
+	if err = errIfReadOnly(
+		blobStore.readOnly,
+		blobStore.config.GetRemotePath(),
+	); err != nil {
+		return size, err
+	}
+
+	if blobStore.dryRun != nil {
+		return copyToDryRunStore(blobStore.dryRun, expected, open)
+	}
+
+	blobStore.initializeOnce()
+
+	if blobStore.transfersPath == "" {
+		return blobStore.uploadBlobDirectly(expected, open, nil, pace)
+	}
+
+	transfer := makeSftpTransfer(blobStore.transfersPath, expected)
+
+	if err = transfer.readJournal(); errors.IsNotExist(err) {
+		var head []byte
+
+		if head, err = blobStore.stageTransfer(&transfer, expected, open); err != nil {
+			err = errors.Wrap(err)
+			return size, err
+		}
+
+		if head != nil {
+			return blobStore.uploadBlobDirectly(expected, nil, head, pace)
+		}
+	} else if err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	if err = blobStore.uploadTransfer(transfer, expected, pace); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	size = transfer.plainSize
+
+	return size, err
+}
+
+// stageTransfer encodes the blob into the transfer's spool file and writes
+// its journal. Blobs under sftpResumableMinSize are not staged; they are
+// returned whole as head instead.
+func (blobStore *remoteSftp) stageTransfer(
+	transfer *sftpTransfer,
+	expected domain_interfaces.MarklId,
+	open func() (domain_interfaces.BlobReader, error),
+) (head []byte, err error) {
+	var reader domain_interfaces.BlobReader
+
+	if reader, err = open(); err != nil {
+		err = errors.Wrap(err)
+		return head, err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	var headBuffer bytes.Buffer
+
+	if _, err = io.CopyN(&headBuffer, reader, sftpResumableMinSize); err == io.EOF {
+		err = nil
+		head = headBuffer.Bytes()
+		return head, err
+	} else if err != nil {
+		err = errors.Wrap(err)
+		return head, err
+	}
+
+	if err = stageSftpTransfer(
+		transfer,
+		blobStore.makeEnvDirConfig(),
+		blobStore.defaultHashType,
+		blobStore.config.GetRemotePath(),
+		expected,
+		io.MultiReader(&headBuffer, reader),
+	); err != nil {
+		err = errors.Wrap(err)
+		return head, err
+	}
+
+	return head, err
+}
+
+func stageSftpTransfer(
+	transfer *sftpTransfer,
+	config env_dir.Config,
+	hashType domain_interfaces.FormatHash,
+	remotePath string,
+	expected domain_interfaces.MarklId,
+	reader io.Reader,
+) (err error) {
+	transfersPath := filepath.Dir(transfer.spoolPath)
+
+	if err = os.MkdirAll(transfersPath, os.ModeDir|0o755); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var spool *os.File
+
+	if spool, err = os.CreateTemp(transfersPath, "spool-*.tmp"); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	hash, repool := hashType.GetHash()
+	defer repool()
+
+	var writer *sftpWriter
+
+	if writer, err = newSftpWriter(config, spool, hash); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if transfer.plainSize, err = writer.ReadFrom(reader); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = markl.AssertEqual(expected, writer.GetDigest()); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = spool.Sync(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var info os.FileInfo
+
+	if info, err = spool.Stat(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	transfer.size = info.Size()
+
+	var tempNameBytes [16]byte
+
+	if _, err = rand.Read(tempNameBytes[:]); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	transfer.tempPath = path.Join(
+		remotePath,
+		fmt.Sprintf("tmp_%x", tempNameBytes),
+	)
+
+	if err = os.Rename(spool.Name(), transfer.spoolPath); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = transfer.writeJournal(); err != nil {
+		os.Remove(transfer.spoolPath)
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// uploadTransfer appends the part of the spool the remote temp file lacks,
+// then moves the temp file into place and removes the transfer.
+func (blobStore *remoteSftp) uploadTransfer(
+	transfer sftpTransfer,
+	expected domain_interfaces.MarklId,
+	pace func(io.Reader) io.Reader,
+) (err error) {
+	finalPath := blobStore.remotePathForMerkleId(expected)
+
+	if _, statErr := blobStore.sftpClient.Stat(finalPath); statErr == nil {
+		blobStore.sftpClient.Remove(transfer.tempPath)
+		blobStore.addToBlobCache(expected)
+
+		if err = transfer.remove(); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		return err
+	}
+
+	var offset int64
+
+	if info, statErr := blobStore.sftpClient.Stat(
+		transfer.tempPath,
+	); statErr == nil {
+		offset = info.Size()
+	} else if !errors.IsNotExist(statErr) {
+		err = markSftpErrorRetryable(errors.Wrap(statErr))
+		return err
+	}
+
+	// a temp file longer than the spool is not ours to append to
+	if offset > transfer.size {
+		offset = 0
+	}
+
+	if err = blobStore.appendSpool(transfer, offset, pace); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if blobStore.budget != nil {
+		if err = blobStore.budget.reserve(transfer.size); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	if err = blobStore.sftpClient.MkdirAll(path.Dir(finalPath)); err != nil {
+		err = markSftpErrorRetryable(errors.Wrap(err))
+		return err
+	}
+
+	if err = blobStore.sftpClient.Rename(transfer.tempPath, finalPath); err != nil {
+		if _, statErr := blobStore.sftpClient.Stat(finalPath); statErr == nil {
+			blobStore.sftpClient.Remove(transfer.tempPath)
+			err = nil
+		} else {
+			err = markSftpErrorRetryable(errors.Wrap(err))
+			return err
+		}
+	}
+
+	blobStore.addToBlobCache(expected)
+
+	if err = transfer.remove(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (blobStore *remoteSftp) appendSpool(
+	transfer sftpTransfer,
+	offset int64,
+	pace func(io.Reader) io.Reader,
+) (err error) {
+	var spool *os.File
+
+	if spool, err = os.Open(transfer.spoolPath); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, spool)
+
+	if _, err = spool.Seek(offset, io.SeekStart); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var tempFile *sftp.File
+
+	if tempFile, err = blobStore.sftpClient.OpenFile(
+		transfer.tempPath,
+		os.O_WRONLY|os.O_CREATE,
+	); err != nil {
+		err = markSftpErrorRetryable(errors.Wrap(err))
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, tempFile)
+
+	if err = tempFile.Truncate(offset); err != nil {
+		err = markSftpErrorRetryable(errors.Wrap(err))
+		return err
+	}
+
+	if _, err = tempFile.Seek(offset, io.SeekStart); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var written int64
+
+	if written, err = io.Copy(tempFile, pace(spool)); err != nil {
+		err = markSftpErrorRetryable(errors.Wrap(err))
+		return err
+	}
+
+	if offset+written != transfer.size {
+		err = errors.Errorf(
+			"uploaded %d of %d bytes to %q",
+			offset+written,
+			transfer.size,
+			transfer.tempPath,
+		)
+
+		return err
+	}
+
+	return err
+}
+
+// uploadBlobDirectly writes the blob through a regular blob writer, reading
+// it from head when set and from open otherwise.
+func (blobStore *remoteSftp) uploadBlobDirectly(
+	expected domain_interfaces.MarklId,
+	open func() (domain_interfaces.BlobReader, error),
+	head []byte,
+	pace func(io.Reader) io.Reader,
+) (size int64, err error) {
+	var reader io.Reader
+
+	if head != nil {
+		reader = bytes.NewReader(head)
+	} else {
+		var blobReader domain_interfaces.BlobReader
+
+		if blobReader, err = open(); err != nil {
+			err = errors.Wrap(err)
+			return size, err
+		}
+
+		defer errors.DeferredCloser(&err, blobReader)
+
+		reader = blobReader
+	}
+
+	var writer domain_interfaces.BlobWriter
+
+	if writer, err = blobStore.MakeBlobWriter(
+		blobStore.defaultHashType,
+	); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	if size, err = io.Copy(writer, pace(reader)); err != nil {
+		writer.Close()
+		err = markSftpErrorRetryable(errors.Wrap(err))
+		return size, err
+	}
+
+	if err = writer.Close(); err != nil {
+		err = markSftpErrorRetryable(errors.Wrap(err))
+		return size, err
+	}
+
+	if err = markl.AssertEqual(expected, writer.GetMarklId()); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	return size, err
+}
+
+func (blobStore *remoteSftp) addToBlobCache(id domain_interfaces.MarklId) {
+	blobStore.blobCacheLock.Lock()
+	blobStore.blobCache[string(id.GetBytes())] = struct{}{}
+	blobStore.blobCacheLock.Unlock()
+}
+
+func (transfer *sftpTransfer) readJournal() (err error) {
+	var file *os.File
+
+	if file, err = os.Open(transfer.journalPath); err != nil {
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	scanner := bufio.NewScanner(file)
+	fields := make(map[string]string)
+
+	for lineNumber := 0; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+
+		if lineNumber == 0 {
+			if line != sftpTransferJournalVersion {
+				err = errors.Errorf(
+					"unsupported transfer journal %q in %q",
+					line,
+					transfer.journalPath,
+				)
+
+				return err
+			}
+
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, " ")
+
+		if !ok {
+			err = errors.Errorf(
+				"malformed line %q in transfer journal %q",
+				line,
+				transfer.journalPath,
+			)
+
+			return err
+		}
+
+		fields[key] = value
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	transfer.tempPath = fields["temp-path"]
+
+	if transfer.size, err = strconv.ParseInt(fields["size"], 10, 64); err != nil {
+		err = errors.Wrapf(err, "transfer journal %q", transfer.journalPath)
+		return err
+	}
+
+	if transfer.plainSize, err = strconv.ParseInt(
+		fields["plain-size"],
+		10,
+		64,
+	); err != nil {
+		err = errors.Wrapf(err, "transfer journal %q", transfer.journalPath)
+		return err
+	}
+
+	if transfer.tempPath == "" {
+		err = errors.Errorf(
+			"transfer journal %q has no temp path",
+			transfer.journalPath,
+		)
+
+		return err
+	}
+
+	return err
+}
+
+// writeJournal replaces the journal atomically, so a reader sees either no
+// journal or a whole one.
+func (transfer sftpTransfer) writeJournal() (err error) {
+	var file *os.File
+
+	if file, err = os.CreateTemp(
+		filepath.Dir(transfer.journalPath),
+		"journal-*.tmp",
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if _, err = fmt.Fprintf(
+		file,
+		"%s\ntemp-path %s\nsize %d\nplain-size %d\n",
+		sftpTransferJournalVersion,
+		transfer.tempPath,
+		transfer.size,
+		transfer.plainSize,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = file.Sync(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = os.Rename(file.Name(), transfer.journalPath); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// remove drops the journal before the spool, so a crash in between leaves a
+// stray spool rather than a journal without one.
+func (transfer sftpTransfer) remove() (err error) {
+	if err = os.Remove(transfer.journalPath); err != nil &&
+		!errors.IsNotExist(err) {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = os.Remove(transfer.spoolPath); err != nil &&
+		!errors.IsNotExist(err) {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	err = nil
+
+	return err
+}
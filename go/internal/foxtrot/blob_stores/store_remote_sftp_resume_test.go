@@ -0,0 +1,119 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+)
+
+func TestStageSftpTransferWritesSpoolAndJournal(t *testing.T) {
+	data := bytes.Repeat([]byte("resumable upload "), sftpResumableMinSize/8)
+	id := writeTestBlob(t, makeTestLocalHashBucketed(t), string(data))
+
+	hashType, err := markl.GetFormatHashOrError(
+		blob_store_configs.DefaultHashTypeId,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transfersPath := filepath.Join(t.TempDir(), sftpTransfersDirName)
+	transfer := makeSftpTransfer(transfersPath, id)
+
+	if err := stageSftpTransfer(
+		&transfer,
+		env_dir.DefaultConfig,
+		hashType,
+		"/remote/blobs",
+		id,
+		bytes.NewReader(data),
+	); err != nil {
+		t.Fatalf("stageSftpTransfer: %v", err)
+	}
+
+	spoolInfo, err := os.Stat(transfer.spoolPath)
+	if err != nil {
+		t.Fatalf("expected a spool file: %v", err)
+	}
+
+	reloaded := makeSftpTransfer(transfersPath, id)
+
+	if err := reloaded.readJournal(); err != nil {
+		t.Fatalf("readJournal: %v", err)
+	}
+
+	if reloaded.tempPath != transfer.tempPath {
+		t.Errorf("expected temp path %q, got %q", transfer.tempPath, reloaded.tempPath)
+	}
+
+	if filepath.Dir(reloaded.tempPath) != "/remote/blobs" {
+		t.Errorf("expected the temp file in the remote path, got %q", reloaded.tempPath)
+	}
+
+	if reloaded.size != spoolInfo.Size() {
+		t.Errorf("expected size %d, got %d", spoolInfo.Size(), reloaded.size)
+	}
+
+	if reloaded.plainSize != int64(len(data)) {
+		t.Errorf("expected plain size %d, got %d", len(data), reloaded.plainSize)
+	}
+
+	if err := reloaded.remove(); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	entries, err := os.ReadDir(transfersPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("expected an empty transfers directory, got %d entries", len(entries))
+	}
+}
+
+func TestStageSftpTransferRejectsMismatchedDigest(t *testing.T) {
+	data := bytes.Repeat([]byte("resumable upload "), sftpResumableMinSize/8)
+	otherId := writeTestBlob(
+		t,
+		makeTestLocalHashBucketed(t),
+		"a different blob",
+	)
+
+	hashType, err := markl.GetFormatHashOrError(
+		blob_store_configs.DefaultHashTypeId,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transfersPath := filepath.Join(t.TempDir(), sftpTransfersDirName)
+	transfer := makeSftpTransfer(transfersPath, otherId)
+
+	if err := stageSftpTransfer(
+		&transfer,
+		env_dir.DefaultConfig,
+		hashType,
+		"/remote/blobs",
+		otherId,
+		bytes.NewReader(data),
+	); err == nil {
+		t.Fatal("expected staging a blob under the wrong digest to fail")
+	}
+
+	entries, err := os.ReadDir(transfersPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("expected no staged files, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,211 @@
+package blob_stores
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"sync/atomic"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/alfa/token_bucket"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+// EnvTransferMaxParallel and EnvTransferMaxBytesPerSecond set the global
+// transfer caps, shared by every copy in the process. The byte rate takes
+// human-readable sizes (e.g. 5M).
+const (
+	EnvTransferMaxParallel       = "DODDER_TRANSFER_MAX_PARALLEL"
+	EnvTransferMaxBytesPerSecond = "DODDER_TRANSFER_MAX_BYTES_PER_SECOND"
+)
+
+var (
+	transferLimiterCount  atomic.Uint64
+	globalTransferLimiter atomic.Pointer[TransferLimiter]
+)
+
+func init() {
+	var maxParallel int
+	var maxBytesPerSecond ui.HumanReadableBytes
+
+	if value, ok := os.LookupEnv(EnvTransferMaxParallel); ok {
+		var err error
+
+		if maxParallel, err = strconv.Atoi(value); err != nil {
+			ui.Err().Printf("ignoring %s: %s", EnvTransferMaxParallel, err)
+		}
+	}
+
+	if value, ok := os.LookupEnv(EnvTransferMaxBytesPerSecond); ok {
+		if err := maxBytesPerSecond.Set(value); err != nil {
+			ui.Err().Printf("ignoring %s: %s", EnvTransferMaxBytesPerSecond, err)
+		}
+	}
+
+	SetGlobalTransferLimits(maxParallel, maxBytesPerSecond.GetByteCount())
+}
+
+// TransferLimiter caps the copies it is shared by: how many run at once and
+// how many bytes per second they move together. A nil *TransferLimiter caps
+// nothing.
+type TransferLimiter struct {
+	// copies take limiters in creation order, so two copies sharing limiters
+	// cannot each hold a slot the other waits for
+	order  uint64
+	slots  chan struct{}
+	bucket *token_bucket.Bucket
+}
+
+// TransferLimited is implemented by stores whose config sets transfer caps
+// (blob_store_configs.ConfigTransfer).
+type TransferLimited interface {
+	GetTransferLimiter() *TransferLimiter
+}
+
+// MakeTransferLimiter returns a limiter for the given caps, or nil when both
+// are zero.
+func MakeTransferLimiter(
+	maxParallel int,
+	maxBytesPerSecond uint64,
+) *TransferLimiter {
+	if maxParallel <= 0 && maxBytesPerSecond == 0 {
+		return nil
+	}
+
+	limiter := &TransferLimiter{
+		order:  transferLimiterCount.Add(1),
+		bucket: token_bucket.Make(maxBytesPerSecond),
+	}
+
+	if maxParallel > 0 {
+		limiter.slots = make(chan struct{}, maxParallel)
+	}
+
+	return limiter
+}
+
+func (limiter *TransferLimiter) GetMaxParallel() int {
+	if limiter == nil {
+		return 0
+	}
+
+	return cap(limiter.slots)
+}
+
+func (limiter *TransferLimiter) GetMaxBytesPerSecond() uint64 {
+	if limiter == nil {
+		return 0
+	}
+
+	return limiter.bucket.GetRate()
+}
+
+func makeTransferLimiterForConfig(
+	config blob_store_configs.Config,
+) *TransferLimiter {
+	transferConfig, ok := config.(blob_store_configs.ConfigTransfer)
+
+	if !ok {
+		return nil
+	}
+
+	return MakeTransferLimiter(
+		transferConfig.GetMaxParallelTransfers(),
+		transferConfig.GetMaxBytesPerSecond(),
+	)
+}
+
+// SetGlobalTransferLimits replaces the global transfer caps, overriding the
+// environment. Copies already running keep the caps they started with.
+func SetGlobalTransferLimits(maxParallel int, maxBytesPerSecond uint64) {
+	globalTransferLimiter.Store(
+		MakeTransferLimiter(maxParallel, maxBytesPerSecond),
+	)
+}
+
+func GetGlobalTransferLimiter() *TransferLimiter {
+	return globalTransferLimiter.Load()
+}
+
+// transferLimiters are the limiters a copy is subject to: the global one and
+// those of the stores it copies between, in creation order.
+type transferLimiters []*TransferLimiter
+
+func getTransferLimiters(
+	stores ...domain_interfaces.BlobStore,
+) (limiters transferLimiters) {
+	if limiter := globalTransferLimiter.Load(); limiter != nil {
+		limiters = append(limiters, limiter)
+	}
+
+	for _, store := range stores {
+		limited, ok := store.(TransferLimited)
+
+		if !ok {
+			continue
+		}
+
+		if limiter := limited.GetTransferLimiter(); limiter != nil &&
+			!slices.Contains(limiters, limiter) {
+			limiters = append(limiters, limiter)
+		}
+	}
+
+	slices.SortFunc(limiters, func(left, right *TransferLimiter) int {
+		return cmp.Compare(left.order, right.order)
+	})
+
+	return limiters
+}
+
+// acquire takes a slot from every limiter with a parallel cap, waiting for
+// one to free up, and returns the function that gives them back. When ctx
+// ends first, the slots taken so far are given back and its cause returned.
+func (limiters transferLimiters) acquire(
+	ctx context.Context,
+) (release func(), err error) {
+	var held []*TransferLimiter
+
+	release = func() {
+		for _, limiter := range held {
+			<-limiter.slots
+		}
+	}
+
+	for _, limiter := range limiters {
+		if limiter.slots == nil {
+			continue
+		}
+
+		select {
+		case limiter.slots <- struct{}{}:
+			held = append(held, limiter)
+
+		case <-ctx.Done():
+			release()
+			release = func() {}
+			err = context.Cause(ctx)
+			return release, err
+		}
+	}
+
+	return release, err
+}
+
+// pace returns reader paced by every limiter with a byte rate.
+func (limiters transferLimiters) pace(
+	ctx context.Context,
+	reader io.Reader,
+) io.Reader {
+	buckets := make([]*token_bucket.Bucket, 0, len(limiters))
+
+	for _, limiter := range limiters {
+		buckets = append(buckets, limiter.bucket)
+	}
+
+	return token_bucket.MakeReader(ctx, reader, buckets...)
+}
@@ -0,0 +1,76 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGetTransferLimitersSortsAndDedups(t *testing.T) {
+	first := MakeTransferLimiter(1, 0)
+	second := MakeTransferLimiter(0, 1024)
+
+	dst := makeTestLocalHashBucketed(t)
+	dst.transferLimiter = second
+
+	src := makeTestLocalHashBucketed(t)
+	src.transferLimiter = first
+
+	other := makeTestLocalHashBucketed(t)
+	other.transferLimiter = second
+
+	limiters := getTransferLimiters(dst, src, other)
+
+	if len(limiters) != 2 || limiters[0] != first || limiters[1] != second {
+		t.Errorf("expected each limiter once, in creation order: %v", limiters)
+	}
+}
+
+func TestTransferLimitersAcquireGivesBackSlotsOnCancel(t *testing.T) {
+	free := MakeTransferLimiter(1, 0)
+	busy := MakeTransferLimiter(1, 0)
+
+	releaseBusy, err := transferLimiters{busy}.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cause := errors.New("stopped")
+	cancel(cause)
+
+	if _, err := (transferLimiters{free, busy}).acquire(ctx); !errors.Is(err, cause) {
+		t.Fatalf("expected the context's cause, got %v", err)
+	}
+
+	if len(free.slots) != 0 {
+		t.Error("expected the slot taken before the wait to be given back")
+	}
+
+	releaseBusy()
+
+	release, err := transferLimiters{free, busy}.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+
+	release()
+
+	if len(free.slots) != 0 || len(busy.slots) != 0 {
+		t.Error("expected release to give back every slot")
+	}
+}
+
+func TestMakeTransferLimiterWithoutCapsIsNil(t *testing.T) {
+	if limiter := MakeTransferLimiter(0, 0); limiter != nil {
+		t.Error("expected no limiter without caps")
+	}
+
+	var limiter *TransferLimiter
+
+	if limiter.GetMaxParallel() != 0 || limiter.GetMaxBytesPerSecond() != 0 {
+		t.Error("expected a nil limiter to report no caps")
+	}
+}
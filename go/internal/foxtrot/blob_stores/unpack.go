@@ -0,0 +1,246 @@
+package blob_stores
+
+import (
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// UnpackableArchive is implemented by blob stores that can extract archived
+// blobs back into their loose blob store, as is needed before pruning an
+// archive or migrating to another store type.
+type UnpackableArchive interface {
+	Unpack(ids ...domain_interfaces.MarklId) error
+}
+
+var (
+	_ UnpackableArchive = inventoryArchiveV0{}
+	_ UnpackableArchive = inventoryArchiveV1{}
+)
+
+// Unpack extracts the given archived blobs, or every archived blob when none
+// are given, into the loose blob store, verifying each against its id, and
+// drops them from the index and its cache so that they are read from the
+// loose store. An index rebuilt from the archive index files lists them again
+// until their archive is pruned.
+func (store inventoryArchiveV0) Unpack(
+	ids ...domain_interfaces.MarklId,
+) (err error) {
+	if err = errIfReadOnly(store.readOnly, store.basePath); err != nil {
+		return err
+	}
+
+	if err = errIfDryRun(store.dryRun, store.basePath); err != nil {
+		return err
+	}
+
+	lock, err := lockStore(store.basePath, false)
+	if err != nil {
+		return err
+	}
+
+	defer errors.Deferred(&err, lock.Unlock)
+
+	keys, err := archivedKeysToUnpack(store.index, store.looseBlobStore, ids)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return err
+	}
+
+	for _, key := range keys {
+		if err = unpackArchivedBlob(store, store.looseBlobStore, key); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range keys {
+		delete(store.index, key)
+	}
+
+	return store.writeCache()
+}
+
+// Unpack extracts the given archived blobs, or every archived blob when none
+// are given, into the loose blob store, verifying each against its id, and
+// drops them from the index and its cache so that they are read from the
+// loose store. Entries that are the delta base of an entry left archived stay
+// indexed. An index rebuilt from the manifest's archives lists the unpacked
+// entries again until their archive is pruned.
+func (store inventoryArchiveV1) Unpack(
+	ids ...domain_interfaces.MarklId,
+) (err error) {
+	if err = errIfReadOnly(store.readOnly, store.basePath); err != nil {
+		return err
+	}
+
+	if err = errIfDryRun(store.dryRun, store.basePath); err != nil {
+		return err
+	}
+
+	lock, err := lockStore(store.basePath, false)
+	if err != nil {
+		return err
+	}
+
+	defer errors.Deferred(&err, lock.Unlock)
+
+	if err = store.refreshIndex(); err != nil {
+		return err
+	}
+
+	keys, err := archivedKeysToUnpack(store.index, store.looseBlobStore, ids)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return err
+	}
+
+	for _, key := range keys {
+		if err = unpackArchivedBlob(store, store.looseBlobStore, key); err != nil {
+			return err
+		}
+	}
+
+	unpacked := make(map[string]struct{}, len(keys))
+
+	for _, key := range keys {
+		unpacked[key] = struct{}{}
+	}
+
+	type archiveOffset struct {
+		archiveChecksum string
+		offset          uint64
+	}
+
+	bases := make(map[archiveOffset]struct{})
+
+	for key, entry := range store.index {
+		if _, ok := unpacked[key]; ok {
+			continue
+		}
+
+		if entry.EntryType == inventory_archive.EntryTypeDelta {
+			bases[archiveOffset{entry.ArchiveChecksum, entry.BaseOffset}] = struct{}{}
+		}
+	}
+
+	for _, key := range keys {
+		entry := store.index[key]
+
+		if _, isBase := bases[archiveOffset{entry.ArchiveChecksum, entry.Offset}]; isBase {
+			continue
+		}
+
+		delete(store.index, key)
+	}
+
+	return store.writeCacheV1()
+}
+
+// archivedKeysToUnpack returns the index keys of ids, or all of them when
+// there are no ids. Ids already in the loose blob store and not archived are
+// left out, and any other id that is not archived is an error.
+func archivedKeysToUnpack[ENTRY any](
+	index map[string]ENTRY,
+	looseBlobStore domain_interfaces.BlobStore,
+	ids []domain_interfaces.MarklId,
+) (keys []string, err error) {
+	if len(ids) == 0 {
+		for key := range index {
+			keys = append(keys, key)
+		}
+
+		return keys, err
+	}
+
+	for _, id := range ids {
+		if id.IsNull() {
+			continue
+		}
+
+		key := id.String()
+
+		if _, ok := index[key]; ok {
+			keys = append(keys, key)
+			continue
+		}
+
+		if looseBlobStore.HasBlob(id) {
+			continue
+		}
+
+		err = errors.BadRequestf("blob %s is not in the archive", id)
+		return keys, err
+	}
+
+	return keys, err
+}
+
+// unpackArchivedBlob copies the blob with the given index key from the
+// archive to the loose blob store, unless it is there already, and checks
+// that both the archived content and the written blob digest to the key.
+func unpackArchivedBlob(
+	archive domain_interfaces.BlobStore,
+	looseBlobStore domain_interfaces.BlobStore,
+	key string,
+) (err error) {
+	var id markl.Id
+
+	if err = id.Set(key); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if looseBlobStore.HasBlob(id) {
+		return err
+	}
+
+	formatHash, err := markl.GetFormatHashOrError(
+		id.GetMarklFormat().GetMarklFormatId(),
+	)
+	if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	reader, err := archive.MakeBlobReader(id)
+	if err != nil {
+		err = errors.Wrapf(err, "reading archived blob %s", id)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	writer, err := looseBlobStore.MakeBlobWriter(formatHash)
+	if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, writer)
+
+	if _, err = io.Copy(writer, reader); err != nil {
+		err = errors.Wrapf(err, "unpacking blob %s", id)
+		return err
+	}
+
+	if err = markl.AssertEqual(&id, reader.GetMarklId()); err != nil {
+		err = errors.Wrapf(err, "archived blob %s", id)
+		return err
+	}
+
+	if err = markl.AssertEqual(&id, writer.GetMarklId()); err != nil {
+		err = errors.Wrapf(err, "unpacked blob %s", id)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,283 @@
+//go:build test && debug
+
+package blob_stores
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/inventory_archive"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/lib/delta/compression_type"
+)
+
+// packTestBlobsV1 writes contents to a new loose store and packs them into a
+// v1 store, deleting the loose copies.
+func packTestBlobsV1(
+	t testing.TB,
+	config blob_store_configs.TomlInventoryArchiveV1,
+	contents ...string,
+) (store inventoryArchiveV1, loose localHashBucketed, ids []domain_interfaces.MarklId) {
+	t.Helper()
+
+	loose = makeTestLocalHashBucketed(t)
+
+	for _, content := range contents {
+		ids = append(ids, writeTestBlob(t, loose, content))
+	}
+
+	store = makeTestStoreV1ForHash(
+		t.TempDir(),
+		t.TempDir(),
+		markl.FormatHashSha256,
+		loose,
+	)
+	store.config = config
+
+	if err := store.Pack(PackOptions{DeleteLoose: true}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	for _, id := range ids {
+		if loose.HasBlob(id) {
+			t.Fatalf("expected %s to be packed and deleted from the loose store", id)
+		}
+	}
+
+	return store, loose, ids
+}
+
+func assertLooseBlob(
+	t *testing.T,
+	loose localHashBucketed,
+	id domain_interfaces.MarklId,
+	expected string,
+) {
+	t.Helper()
+
+	reader, err := loose.MakeBlobReader(id)
+	if err != nil {
+		t.Fatalf("expected %s in the loose store: %v", id, err)
+	}
+
+	defer reader.Close()
+
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if !bytes.Equal(actual, []byte(expected)) {
+		t.Errorf("%s: expected %q, got %q", id, expected, actual)
+	}
+}
+
+func TestUnpackV1SelectedBlobs(t *testing.T) {
+	store, loose, ids := packTestBlobsV1(
+		t,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+		"unpack blob one",
+		"unpack blob two",
+	)
+
+	if err := store.Unpack(ids[0]); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	assertLooseBlob(t, loose, ids[0], "unpack blob one")
+
+	if _, ok := store.index[ids[0].String()]; ok {
+		t.Errorf("expected %s to be dropped from the index", ids[0])
+	}
+
+	if loose.HasBlob(ids[1]) {
+		t.Errorf("expected %s to stay archived only", ids[1])
+	}
+
+	// the cache was rewritten without the unpacked blob
+	reloaded := makeTestStoreV1ForHash(
+		store.basePath,
+		store.cachePath,
+		markl.FormatHashSha256,
+		loose,
+	)
+
+	if err := reloaded.loadIndex(); err != nil {
+		t.Fatalf("loadIndex: %v", err)
+	}
+
+	if _, ok := reloaded.index[ids[0].String()]; ok {
+		t.Errorf("expected %s to be missing from the cache", ids[0])
+	}
+
+	if _, ok := reloaded.index[ids[1].String()]; !ok {
+		t.Errorf("expected %s to stay in the cache", ids[1])
+	}
+
+	if !reloaded.HasBlob(ids[0]) {
+		t.Errorf("expected %s to be found in the loose store", ids[0])
+	}
+}
+
+func TestUnpackV1AllBlobs(t *testing.T) {
+	store, loose, ids := packTestBlobsV1(
+		t,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeZstd,
+		},
+		"unpack all one",
+		"unpack all two",
+	)
+
+	if err := store.Unpack(); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	assertLooseBlob(t, loose, ids[0], "unpack all one")
+	assertLooseBlob(t, loose, ids[1], "unpack all two")
+
+	if len(store.index) != 0 {
+		t.Errorf("expected an empty index, got %d entries", len(store.index))
+	}
+}
+
+func TestUnpackV1KeepsDeltaBasesIndexed(t *testing.T) {
+	commonPrefix := strings.Repeat("shared content block ", 100)
+	contents := []string{
+		commonPrefix + " unique suffix alpha",
+		commonPrefix + " unique suffix beta",
+		commonPrefix + " unique suffix gamma",
+	}
+
+	store, loose, ids := packTestBlobsV1(
+		t,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+			Delta: blob_store_configs.DeltaConfig{
+				Enabled:     true,
+				Algorithm:   "bsdiff",
+				MinBlobSize: 1,
+				MaxBlobSize: 10485760,
+				SizeRatio:   2.0,
+			},
+		},
+		contents...,
+	)
+
+	var baseIndex, deltaIndex = -1, -1
+
+	for i, id := range ids {
+		entry := store.index[id.String()]
+
+		if entry.EntryType != inventory_archive.EntryTypeDelta {
+			continue
+		}
+
+		deltaIndex = i
+
+		for j, baseId := range ids {
+			if store.index[baseId.String()].Offset == entry.BaseOffset {
+				baseIndex = j
+			}
+		}
+	}
+
+	if deltaIndex == -1 || baseIndex == -1 {
+		t.Fatal("expected a delta entry and its base")
+	}
+
+	if err := store.Unpack(ids[baseIndex]); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	assertLooseBlob(t, loose, ids[baseIndex], contents[baseIndex])
+
+	if _, ok := store.index[ids[baseIndex].String()]; !ok {
+		t.Fatal("expected the delta base to stay indexed")
+	}
+
+	reader, err := store.MakeBlobReader(ids[deltaIndex])
+	if err != nil {
+		t.Fatalf("MakeBlobReader for delta entry: %v", err)
+	}
+
+	defer reader.Close()
+
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if string(actual) != contents[deltaIndex] {
+		t.Errorf("delta entry mismatch after unpacking its base")
+	}
+}
+
+func TestUnpackRejectsBlobsNotArchived(t *testing.T) {
+	store, loose, _ := packTestBlobsV1(
+		t,
+		blob_store_configs.TomlInventoryArchiveV1{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+		"archived",
+	)
+
+	looseOnly := writeTestBlob(t, loose, "loose only")
+
+	if err := store.Unpack(looseOnly); err != nil {
+		t.Fatalf("expected a loose blob to need no unpacking: %v", err)
+	}
+
+	missing := makeTestLocalHashBucketed(t)
+	missingId := writeTestBlob(t, missing, "in neither store")
+
+	if err := store.Unpack(missingId); err == nil {
+		t.Fatal("expected an error for a blob that is not archived")
+	}
+}
+
+func TestUnpackV0(t *testing.T) {
+	loose := makeTestLocalHashBucketed(t)
+
+	id := writeTestBlob(t, loose, "unpack v0 blob")
+
+	store := inventoryArchiveV0{
+		defaultHash:    markl.FormatHashSha256,
+		basePath:       t.TempDir(),
+		cachePath:      t.TempDir(),
+		looseBlobStore: loose,
+		index:          make(map[string]archiveEntry),
+		config: blob_store_configs.TomlInventoryArchiveV0{
+			HashTypeId:      markl.FormatIdHashSha256,
+			CompressionType: compression_type.CompressionTypeNone,
+		},
+	}
+
+	if err := store.Pack(PackOptions{DeleteLoose: true}); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	if loose.HasBlob(id) {
+		t.Fatal("expected the blob to be deleted from the loose store")
+	}
+
+	if err := store.Unpack(id); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	assertLooseBlob(t, loose, id, "unpack v0 blob")
+
+	if len(store.index) != 0 {
+		t.Errorf("expected an empty index, got %d entries", len(store.index))
+	}
+}
@@ -3,6 +3,7 @@ package blob_stores
 import (
 	"io/fs"
 	"path/filepath"
+	"strings"
 
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
@@ -46,9 +47,17 @@ func localAllBlobs(
 				}
 
 				if dirEntry.IsDir() {
+					// the small-blob archive is listed separately
+					if dirEntry.Name() == smallBlobsDirName {
+						err = filepath.SkipDir
+					}
+
 					return err
 				}
 
+				// a chunked blob is listed by its manifest
+				path = strings.TrimSuffix(path, chunkedBlobManifestSuffix)
+
 				if err = markl.SetHexStringFromAbsolutePath(id, path, basePath); err != nil {
 					if !yield(nil, errors.Wrap(err)) {
 						if dirEntry.IsDir() {
@@ -99,7 +108,7 @@ func localAllBlobsMultihash(
 
 			hashTypeId := dirEntry.Name()
 
-			if hashTypeId == "." {
+			if hashTypeId == "." || hashTypeId == smallBlobsDirName {
 				continue
 			}
 
@@ -26,7 +26,7 @@ func MakeSSHAgent(
 
 	var connSshSock net.Conn
 
-	ui.Log().Print("connecting to SSH_AUTH_SOCK: %s", socket)
+	logger.Debug("connecting to SSH_AUTH_SOCK", "socket", socket)
 	if connSshSock, err = net.Dial("unix", socket); err != nil {
 		err = errors.Wrapf(err, "failed to connect to SSH_AUTH_SOCK")
 		return
@@ -34,7 +34,7 @@ func MakeSSHAgent(
 
 	ctx.After(errors.MakeFuncContextFromFuncErr(connSshSock.Close))
 
-	ui.Log().Print("creating ssh-agent client")
+	logger.Debug("creating ssh-agent client")
 	sshAgent = agent.NewClient(connSshSock)
 
 	return
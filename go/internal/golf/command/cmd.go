@@ -1,5 +1,7 @@
 package command
 
+import "strings"
+
 type (
 	Cmd interface {
 		Run(Request)
@@ -13,3 +15,9 @@ type (
 		GetDescription() Description
 	}
 )
+
+// IsHiddenCmdName is true for commands meant for scripts rather than users,
+// like `__complete`, which are left out of usage and subcommand completion.
+func IsHiddenCmdName(name string) bool {
+	return strings.HasPrefix(name, "__")
+}
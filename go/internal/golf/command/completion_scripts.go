@@ -0,0 +1,104 @@
+package command
+
+import (
+	"io"
+	"text/template"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// CompleteCmdName is the hidden command that completion scripts call with the
+// words of the command line after the utility name, the word in progress last
+// and also passed as `-in-progress`. It prints one `<value>\t<description>`
+// line per completion.
+const CompleteCmdName = "__complete"
+
+// CompletionShells are the shells WriteCompletionScript supports.
+var CompletionShells = []string{"bash", "fish", "zsh"}
+
+var completionScripts = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Parse(`_{{.Name}}() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local -a words=("${COMP_WORDS[@]:1:COMP_CWORD-1}")
+	local value description
+
+	COMPREPLY=()
+
+	while IFS=$'\t' read -r value description; do
+		if [[ $value == "$cur"* ]]; then
+			COMPREPLY+=("$value")
+		fi
+	done < <({{.Name}} {{.Complete}} -in-progress="$cur" -- "${words[@]}" ${cur:+"$cur"} 2>/dev/null)
+}
+
+complete -o default -F _{{.Name}} {{.Name}}
+`)),
+
+	"fish": template.Must(template.New("fish").Parse(`function __{{.Name}}_complete
+	set -l words (commandline -opc)[2..-1]
+	set -l current (commandline -ct)
+
+	if test -n "$current"
+		set -a words $current
+	end
+
+	{{.Name}} {{.Complete}} -in-progress="$current" -- $words 2>/dev/null
+end
+
+complete -c {{.Name}} -f -a '(__{{.Name}}_complete)'
+`)),
+
+	"zsh": template.Must(template.New("zsh").Parse(`#compdef {{.Name}}
+
+_{{.Name}}() {
+	local -a completions
+	local line value description
+	local cur="${words[CURRENT]}"
+	local -a previous=("${(@)words[2,CURRENT-1]}")
+
+	while IFS= read -r line; do
+		value="${line%%$'\t'*}"
+		description="${line#*$'\t'}"
+
+		if [[ $description == $line ]]; then
+			completions+=("${value//:/\\:}")
+		else
+			completions+=("${value//:/\\:}:$description")
+		fi
+	done < <({{.Name}} {{.Complete}} -in-progress="$cur" -- "${previous[@]}" ${cur:+"$cur"} 2>/dev/null)
+
+	_describe '{{.Name}}' completions
+}
+
+compdef _{{.Name}} {{.Name}}
+`)),
+}
+
+// WriteCompletionScript writes the completion script for shell that completes
+// the commands of the utility through its CompleteCmdName command.
+func WriteCompletionScript(
+	writer io.Writer,
+	shell string,
+	utilityName string,
+) (err error) {
+	script, ok := completionScripts[shell]
+	if !ok {
+		err = errors.BadRequestf(
+			"unsupported shell: %q, expected one of %q",
+			shell,
+			CompletionShells,
+		)
+
+		return err
+	}
+
+	if err = script.Execute(writer, struct{ Name, Complete string }{
+		Name:     utilityName,
+		Complete: CompleteCmdName,
+	}); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -107,6 +107,10 @@ func (utility Utility) PrintUsage(ctx errors.Context, err error) {
 	flagSets := make([]*flags.FlagSet, 0, utility.LenCmds())
 
 	for name, cmd := range utility.AllCmds() {
+		if IsHiddenCmdName(name) {
+			continue
+		}
+
 		flagSet := flags.NewFlagSet(name, flags.ContinueOnError)
 
 		if cmd, ok := cmd.(interfaces.CommandComponentWriter); ok {
@@ -187,7 +191,14 @@ func (utility Utility) Run(
 			cmd.Run(req)
 		},
 	); err != nil {
-		os.Exit(handleMainErrors(ctx, utilityNameWithExtension, err))
+		os.Exit(
+			handleMainErrors(
+				ctx,
+				utilityNameWithExtension,
+				utility.GetConfig(),
+				err,
+			),
+		)
 	}
 }
 
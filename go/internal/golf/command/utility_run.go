@@ -7,6 +7,7 @@ import (
 	"code.linenisgreat.com/dodder/go/lib/_/stack_frame"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	"code.linenisgreat.com/dodder/go/lib/foxtrot/config_cli"
 )
 
 func extendNameIfNecessary(name string) string {
@@ -20,14 +21,23 @@ func extendNameIfNecessary(name string) string {
 func handleMainErrors(
 	ctx errors.Context,
 	name string,
+	config config_cli.Config,
 	err error,
 ) (exitStatus int) {
 	exitStatus = 1
 
+	jsonErrors := config.IsErrorFormatJSON()
+
 	var signal errors.Signal
 
 	if errors.As(err, &signal) {
-		if signal.Signal != syscall.SIGHUP {
+		if signal.Signal == syscall.SIGHUP {
+			return exitStatus
+		}
+
+		if jsonErrors {
+			errors.EncodeErrorEnvelope(ui.Err(), err)
+		} else {
 			ui.Err().Printf(
 				"%s aborting due to signal: %s",
 				name,
@@ -41,7 +51,12 @@ func handleMainErrors(
 	var helpful errors.Helpful
 
 	if errors.As(err, &helpful) {
-		errors.PrintHelpful(ui.Err(), helpful)
+		if jsonErrors {
+			errors.EncodeErrorEnvelope(ui.Err(), err)
+		} else {
+			errors.PrintHelpful(ui.Err(), helpful)
+		}
+
 		return exitStatus
 	}
 
@@ -49,6 +64,11 @@ func handleMainErrors(
 		return exitStatus
 	}
 
+	if jsonErrors {
+		errors.EncodeErrorEnvelope(ui.Err(), err)
+		return exitStatus
+	}
+
 	_, frames := ctx.CauseWithStackFrames()
 	err = stack_frame.MakeErrorTreeOrErr(err, frames...)
 
@@ -0,0 +1,79 @@
+package env_repo
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/lib/echo/snapshot_tar"
+	"code.linenisgreat.com/dodder/go/lib/echo/xdg"
+)
+
+// GetBackupRoots lists the directories a backup of the repo includes: the
+// data, config, state and cache directories of the repo and of its blob
+// stores, named like `dodder-data`. Runtime directories are left out, and so
+// are directories within one already listed. Blob stores configured outside
+// of these directories are not included.
+func (env Env) GetBackupRoots() (roots []snapshot_tar.Root) {
+	var candidates []snapshot_tar.Root
+
+	for _, xdg := range []xdg.XDG{env.GetXDG(), env.GetXDGForBlobStores()} {
+		for _, dir := range []struct {
+			kind string
+			path string
+		}{
+			{"data", xdg.Data.String()},
+			{"config", xdg.Config.String()},
+			{"state", xdg.State.String()},
+			{"cache", xdg.Cache.String()},
+		} {
+			candidates = append(candidates, snapshot_tar.Root{
+				Name: fmt.Sprintf("%s-%s", xdg.UtilityName, dir.kind),
+				Path: filepath.Clean(dir.path),
+			})
+		}
+	}
+
+	for i, candidate := range candidates {
+		if !isWithinBackupRoot(candidate, candidates[:i], candidates[i+1:]) {
+			roots = append(roots, candidate)
+		}
+	}
+
+	return roots
+}
+
+// isWithinBackupRoot reports whether root equals one of the roots before it,
+// or lies strictly within any other root.
+func isWithinBackupRoot(
+	root snapshot_tar.Root,
+	before []snapshot_tar.Root,
+	after []snapshot_tar.Root,
+) bool {
+	for _, other := range before {
+		if other.Path == root.Path {
+			return true
+		}
+	}
+
+	for _, other := range append(slices.Clone(before), after...) {
+		rel, err := filepath.Rel(other.Path, root.Path)
+
+		if err == nil && rel != "." && rel != ".." &&
+			!strings.HasPrefix(rel, "../") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsSkippedInBackup reports whether a file within the backup roots is left
+// out of backups, which is only the repo's locks.
+func (env Env) IsSkippedInBackup(path string) bool {
+	path = filepath.Clean(path)
+
+	return path == filepath.Clean(env.FileLock()) ||
+		path == filepath.Clean(env.FileAccessLock())
+}
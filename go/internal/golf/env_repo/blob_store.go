@@ -11,6 +11,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/bravo/directory_layout"
 	"code.linenisgreat.com/dodder/go/internal/charlie/triple_hyphen_io"
 	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/delta/repo_configs"
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/env_local"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
@@ -22,6 +23,14 @@ type BlobStoreEnv struct {
 
 	defaultBlobStoreIdString string
 
+	// set when the default blob store was replaced by a workspace's blob store,
+	// and refers to the default blob store it replaced
+	workspaceParentBlobStoreIdString string
+
+	// set from the konfig's blob_store-routes, and wrap the default blob store
+	// in a blob_stores.Routing
+	blobStoreRoutes []blob_stores.BlobStoreRoute
+
 	// TODO switch to implementing LocalBlobStore directly and writing to all of
 	// the defined blob stores instead of having a default
 	// TODO switch to primary blob store and others, and add support for v10
@@ -80,7 +89,57 @@ func (env BlobStoreEnv) GetDefaultBlobStore() blob_stores.BlobStoreInitialized {
 		)
 	}
 
-	return env.blobStores[env.defaultBlobStoreIdString]
+	defaultBlobStore := env.blobStores[env.defaultBlobStoreIdString]
+
+	if len(env.blobStoreRoutes) > 0 {
+		defaultBlobStore.BlobStore = blob_stores.Routing{
+			Default: env.blobStores[env.defaultBlobStoreIdString],
+			Routes:  env.blobStoreRoutes,
+		}
+	}
+
+	return defaultBlobStore
+}
+
+// SetBlobStoreRoutes makes the default blob store write the blobs that routes
+// match to their blob stores, or stops routing when routes is empty.
+func (env *BlobStoreEnv) SetBlobStoreRoutes(
+	routes []repo_configs.BlobStoreRouteV1,
+) (err error) {
+	env.blobStoreRoutes = nil
+
+	for i, route := range routes {
+		blobStore, ok := env.blobStores[route.BlobStoreId.String()]
+
+		if !ok {
+			err = errors.BadRequestf(
+				"blob_store-routes[%d]: blob store not found: %q",
+				i,
+				route.BlobStoreId,
+			)
+
+			env.blobStoreRoutes = nil
+
+			return err
+		}
+
+		blobStoreRoute := blob_stores.BlobStoreRoute{
+			BlobStore: blobStore,
+			MinSize:   route.MinSize,
+			MaxSize:   route.MaxSize,
+		}
+
+		for _, tipe := range route.Types {
+			blobStoreRoute.Types = append(
+				blobStoreRoute.Types,
+				tipe.StringSansOp(),
+			)
+		}
+
+		env.blobStoreRoutes = append(env.blobStoreRoutes, blobStoreRoute)
+	}
+
+	return err
 }
 
 func (env BlobStoreEnv) GetBlobStores() blob_stores.BlobStoreMap {
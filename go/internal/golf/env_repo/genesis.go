@@ -87,6 +87,7 @@ func (env *Env) Genesis(bigBang BigBang) {
 
 	env.writeFile(env.FileConfig(), "")
 	env.writeFile(env.FileCacheDormant(), "")
+	env.writeFile(env.FileCacheBlobRefcounts(), "")
 }
 
 func (env Env) writeInventoryListLog() {
@@ -2,6 +2,7 @@ package env_repo
 
 import (
 	"os"
+	"time"
 
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/alfa/store_version"
@@ -23,12 +24,16 @@ const (
 	// TODO move to mutable config
 	FileWorkspaceTemplate = ".%s-workspace"
 	FileWorkspace         = ".dodder-workspace"
+	DirWorkspaceBlobStore = ".dodder-workspace-blob_store"
+	FileIgnore            = ".dodderignore"
+	FileWorkspaceManifest = ".dodder-workspace-manifest"
 )
 
 type Env struct {
 	config genesis_configs.TypedConfigPrivate
 
-	lockSmith interfaces.LockSmith
+	lockSmith  interfaces.LockSmith
+	accessLock *file_lock.AccessLock
 
 	directoryLayoutBlobStore directory_layout.BlobStore
 	directory_layout.Repo
@@ -124,6 +129,21 @@ func Make(
 
 	env.lockSmith = file_lock.New(envLocal, env.FileLock(), "repo")
 
+	{
+		var timeout time.Duration
+
+		if timeout, err = file_lock.GetAccessLockTimeout(); err != nil {
+			err = errors.Wrap(err)
+			return env, err
+		}
+
+		env.accessLock = file_lock.MakeAccessLock(
+			env.FileAccessLock(),
+			"repo",
+			timeout,
+		)
+	}
+
 	envVars := env_vars.Make(env)
 
 	env.Must(errors.MakeFuncContextFromFuncErr(envVars.Set))
@@ -131,6 +151,11 @@ func Make(
 
 	if configLoaded {
 		env.BlobStoreEnv = MakeBlobStoreEnv(envLocal)
+
+		if err = env.setupWorkspaceStoreIfNecessary(); err != nil {
+			err = errors.Wrap(err)
+			return env, err
+		}
 	}
 
 	return env, err
@@ -165,6 +190,12 @@ func (env Env) GetLockSmith() interfaces.LockSmith {
 	return env.lockSmith
 }
 
+// GetAccessLock returns the lock readers of the repo share and writers hold
+// exclusively, across processes.
+func (env Env) GetAccessLock() *file_lock.AccessLock {
+	return env.accessLock
+}
+
 func (env Env) ResetCache() (err error) {
 	if err = files.SetAllowUserChangesRecursive(env.DirDataIndex()); err != nil {
 		err = errors.Wrap(err)
@@ -0,0 +1,143 @@
+package env_repo
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// GetTrustedRepoPubKeys returns this repo's public key followed by the public
+// keys in the trust store, whose signatures are accepted when importing
+// objects.
+func (env Env) GetTrustedRepoPubKeys() (pubKeys []markl.Id, err error) {
+	var own markl.Id
+	own.ResetWithMarklId(env.GetConfigPublic().Blob.GetPublicKey())
+	pubKeys = append(pubKeys, own)
+
+	var file *os.File
+
+	if file, err = files.OpenReadOnly(env.FileTrustedRepoPubKeys()); err != nil {
+		if errors.IsNotExist(err) {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return pubKeys, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var pubKey markl.Id
+
+		if err = pubKey.Set(line); err != nil {
+			err = errors.Wrapf(err, "trusted repo pubkeys: %q", line)
+			return pubKeys, err
+		}
+
+		pubKeys = append(pubKeys, pubKey)
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return pubKeys, err
+	}
+
+	return pubKeys, err
+}
+
+// IsTrustedRepoPubKey is true if pubKey is this repo's own public key or in the
+// trust store.
+func (env Env) IsTrustedRepoPubKey(
+	pubKey domain_interfaces.MarklId,
+) (ok bool, err error) {
+	var trusted []markl.Id
+
+	if trusted, err = env.GetTrustedRepoPubKeys(); err != nil {
+		err = errors.Wrap(err)
+		return ok, err
+	}
+
+	ok = isTrusted(trusted, pubKey)
+
+	return ok, err
+}
+
+// AddTrustedRepoPubKeys appends the given public keys to the trust store,
+// skipping any that are already trusted.
+func (env Env) AddTrustedRepoPubKeys(
+	pubKeys ...domain_interfaces.MarklId,
+) (added int, err error) {
+	var trusted []markl.Id
+
+	if trusted, err = env.GetTrustedRepoPubKeys(); err != nil {
+		err = errors.Wrap(err)
+		return added, err
+	}
+
+	var file *os.File
+
+	if file, err = files.OpenFile(
+		env.FileTrustedRepoPubKeys(),
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0o666,
+	); err != nil {
+		err = errors.Wrap(err)
+		return added, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	for _, pubKey := range pubKeys {
+		if err = markl.AssertIdIsNotNullWithPurpose(pubKey, "pubkey"); err != nil {
+			err = errors.Wrap(err)
+			return added, err
+		}
+
+		if isTrusted(trusted, pubKey) {
+			continue
+		}
+
+		if _, err = file.WriteString(
+			pubKey.StringWithFormat() + "\n",
+		); err != nil {
+			err = errors.Wrap(err)
+			return added, err
+		}
+
+		var clone markl.Id
+		clone.ResetWithMarklId(pubKey)
+		trusted = append(trusted, clone)
+		added++
+	}
+
+	return added, err
+}
+
+func isTrusted(
+	trusted []markl.Id,
+	pubKey domain_interfaces.MarklId,
+) bool {
+	for _, trustedPubKey := range trusted {
+		if bytes.Equal(trustedPubKey.GetBytes(), pubKey.GetBytes()) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,283 @@
+package env_repo
+
+import (
+	"path/filepath"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/directory_layout"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/charlie/triple_hyphen_io"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// WorkspaceBlobStoreType determines how a new workspace's blob store relates
+// to the repo's default blob store.
+type WorkspaceBlobStoreType string
+
+const (
+	// reads and writes go directly to the repo's default blob store
+	WorkspaceBlobStorePointer = WorkspaceBlobStoreType("pointer")
+	// a separate store that fetches missing blobs from the repo's default blob
+	// store on demand
+	WorkspaceBlobStoreLazy = WorkspaceBlobStoreType("lazy")
+	// a separate store populated with a copy of every blob in the repo's
+	// default blob store, hard linked where possible
+	WorkspaceBlobStoreCopy = WorkspaceBlobStoreType("copy")
+)
+
+func (tipe WorkspaceBlobStoreType) String() string {
+	return string(tipe)
+}
+
+func (tipe *WorkspaceBlobStoreType) Set(value string) (err error) {
+	switch value := WorkspaceBlobStoreType(
+		strings.TrimSpace(strings.ToLower(value)),
+	); value {
+	case WorkspaceBlobStorePointer,
+		WorkspaceBlobStoreLazy,
+		WorkspaceBlobStoreCopy:
+		*tipe = value
+
+	default:
+		err = errors.BadRequestf(
+			"unsupported workspace blob store type: %q. Expected one of %q",
+			value,
+			[]WorkspaceBlobStoreType{
+				WorkspaceBlobStorePointer,
+				WorkspaceBlobStoreLazy,
+				WorkspaceBlobStoreCopy,
+			},
+		)
+
+		return err
+	}
+
+	return err
+}
+
+func (tipe *WorkspaceBlobStoreType) GetCLICompletion() map[string]string {
+	return map[string]string{
+		WorkspaceBlobStorePointer.String(): "use the repo's default blob store",
+		WorkspaceBlobStoreLazy.String():    "fetch blobs from the repo's default blob store on demand",
+		WorkspaceBlobStoreCopy.String():    "copy every blob from the repo's default blob store",
+	}
+}
+
+// MakeWorkspaceBlobStorePath returns the path of the blob store that a
+// workspace rooted at workspaceDir may define for itself.
+func MakeWorkspaceBlobStorePath(
+	workspaceDir string,
+) directory_layout.BlobStorePath {
+	base := filepath.Join(workspaceDir, DirWorkspaceBlobStore)
+
+	return directory_layout.GetBlobStorePathForCustomPath(
+		"workspace",
+		base,
+		filepath.Join(base, directory_layout.FileNameBlobStoreConfig),
+	)
+}
+
+// FindWorkspaceDir walks up from dir to the nearest directory containing a
+// workspace file.
+func FindWorkspaceDir(dir string) (workspaceDir string, ok bool) {
+	for {
+		if files.Exists(filepath.Join(dir, FileWorkspace)) {
+			workspaceDir = dir
+			ok = true
+			return workspaceDir, ok
+		}
+
+		parent := filepath.Dir(dir)
+
+		if parent == dir || parent == "." {
+			return workspaceDir, ok
+		}
+
+		dir = parent
+	}
+}
+
+// if the cwd is within a workspace that defines its own blob store, that store
+// replaces the repo's default blob store
+func (env *BlobStoreEnv) setupWorkspaceStoreIfNecessary() (err error) {
+	workspaceDir, ok := FindWorkspaceDir(env.GetCwd())
+
+	if !ok {
+		return err
+	}
+
+	path := MakeWorkspaceBlobStorePath(workspaceDir)
+
+	if !files.Exists(path.GetConfig()) {
+		logger.Debug("workspace has no blob store", "workspace", workspaceDir)
+		return err
+	}
+
+	blobStore := blob_stores.BlobStoreInitialized{
+		ConfigNamed: blob_store_configs.ConfigNamed{Path: path},
+	}
+
+	if blobStore.Config, err = triple_hyphen_io.DecodeFromFile(
+		blob_store_configs.Coder,
+		path.GetConfig(),
+	); err != nil {
+		err = errors.Wrapf(err, "workspace blob store: %q", path.GetConfig())
+		return err
+	}
+
+	if blobStore.BlobStore, err = blob_stores.MakeBlobStore(
+		env,
+		blobStore.ConfigNamed,
+		env.blobStores,
+	); err != nil {
+		err = errors.Wrapf(err, "workspace blob store: %q", path.GetConfig())
+		return err
+	}
+
+	blobStoreIdString := path.GetId().String()
+
+	env.blobStores[blobStoreIdString] = blobStore
+	env.workspaceParentBlobStoreIdString = env.defaultBlobStoreIdString
+	env.defaultBlobStoreIdString = blobStoreIdString
+
+	logger.Info(
+		"using workspace blob store",
+		"workspace",
+		workspaceDir,
+		"blob_store",
+		blobStore.GetBlobStoreDescription(),
+		"parent",
+		env.workspaceParentBlobStoreIdString,
+	)
+
+	return err
+}
+
+// GetWorkspaceBlobStores returns the workspace's blob store and the default
+// blob store that it replaced, if the cwd is within a workspace that defines
+// its own blob store.
+func (env BlobStoreEnv) GetWorkspaceBlobStores() (
+	workspace, parent blob_stores.BlobStoreInitialized,
+	ok bool,
+) {
+	if env.workspaceParentBlobStoreIdString == "" {
+		return workspace, parent, ok
+	}
+
+	workspace = env.blobStores[env.defaultBlobStoreIdString]
+	parent = env.blobStores[env.workspaceParentBlobStoreIdString]
+	ok = true
+
+	return workspace, parent, ok
+}
+
+// CreateWorkspaceBlobStore writes the config for the blob store of the
+// workspace rooted at workspaceDir, with the repo's default blob store as its
+// parent, and populates it if tipe requires it.
+func (env Env) CreateWorkspaceBlobStore(
+	workspaceDir string,
+	tipe WorkspaceBlobStoreType,
+) (err error) {
+	parent := env.GetDefaultBlobStore()
+	parentPath := parent.Path
+	path := MakeWorkspaceBlobStorePath(workspaceDir)
+
+	blobStore := blob_stores.BlobStoreInitialized{
+		ConfigNamed: blob_store_configs.ConfigNamed{Path: path},
+	}
+
+	switch tipe {
+	case WorkspaceBlobStorePointer, "":
+		blobStore.Config = blob_store_configs.TypedConfig{
+			Type: ids.GetOrPanic(ids.TypeTomlBlobStoreConfigPointerV0).TypeStruct,
+			Blob: &blob_store_configs.TomlPointerV0{
+				Id:         parentPath.GetId(),
+				BasePath:   parentPath.GetBase(),
+				ConfigPath: parentPath.GetConfig(),
+			},
+		}
+
+	case WorkspaceBlobStoreLazy:
+		defaults := blob_store_configs.Default().Blob.(*blob_store_configs.DefaultType)
+
+		blobStore.Config = blob_store_configs.TypedConfig{
+			Type: ids.GetOrPanic(ids.TypeTomlBlobStoreConfigLazyParentV0).TypeStruct,
+			Blob: &blob_store_configs.TomlLazyParentV0{
+				HashBuckets:       defaults.HashBuckets,
+				HashTypeId:        defaults.HashTypeId,
+				CompressionType:   defaults.CompressionType,
+				LockInternalFiles: defaults.LockInternalFiles,
+				ParentId:          parentPath.GetId(),
+				ParentBasePath:    parentPath.GetBase(),
+				ParentConfigPath:  parentPath.GetConfig(),
+			},
+		}
+
+	case WorkspaceBlobStoreCopy:
+		defaults := blob_store_configs.Default()
+
+		blobStore.Config = blob_store_configs.TypedConfig{
+			Type: defaults.Type,
+			Blob: defaults.Blob,
+		}
+
+	default:
+		err = errors.Errorf("unsupported workspace blob store type: %q", tipe)
+		return err
+	}
+
+	if err = env.MakeDirs(path.GetBase()); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = triple_hyphen_io.EncodeToFile(
+		blob_store_configs.Coder,
+		&blobStore.Config,
+		path.GetConfig(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if tipe != WorkspaceBlobStoreCopy {
+		return err
+	}
+
+	if blobStore.BlobStore, err = blob_stores.MakeBlobStore(
+		env,
+		blobStore.ConfigNamed,
+		env.blobStores,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	for blobId, errIter := range parent.AllBlobs() {
+		if errIter != nil {
+			err = errors.Wrap(errIter)
+			return err
+		}
+
+		// hard links make copying a large repo's blobs near-instant when the
+		// workspace shares its filesystem and blob encoding
+		copyResult := blob_stores.LinkingCopyBlobIfNecessary(
+			env.GetEnv(),
+			blobStore.GetBlobStore(),
+			parent.GetBlobStore(),
+			blobId,
+			nil,
+			nil,
+		)
+
+		if err = copyResult.GetError(); err != nil {
+			err = errors.Wrapf(err, "Blob: %s", blobId)
+			return err
+		}
+	}
+
+	return err
+}
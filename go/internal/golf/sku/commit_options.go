@@ -32,6 +32,14 @@ type StoreOptions struct {
 	RunHooks           bool
 	UpdateTai          bool
 	Validate           bool
+
+	// AllowInvalidBlob skips checking blobs against the schema of their type
+	// when validating.
+	AllowInvalidBlob bool
+
+	// ApplyTemplate gives new objects without a blob the expanded template of
+	// their type.
+	ApplyTemplate bool
 }
 
 type LockfileOptions struct {
@@ -76,6 +84,7 @@ func GetStoreOptionsImport() StoreOptions {
 		AddToInventoryList: true,
 		RunHooks:           true,
 		Validate:           true,
+		AllowInvalidBlob:   true,
 	}
 }
 
@@ -12,8 +12,13 @@ type (
 		sk *Transacted,
 	) (ok bool)
 
+	// FuncReadMany reads the latest version of each object id, or nil for the
+	// ones without one
+	FuncReadMany = func(objectIds []string) ([]*Transacted, error)
+
 	ObjectProbeIndex interface {
 		ReadOneObjectId(domain_interfaces.ObjectId, *Transacted) error
+		ReadManyObjectIds(objectIds []string) ([]*Transacted, error)
 	}
 
 	IndexPrimitives interface {
@@ -36,6 +41,8 @@ type (
 			sh domain_interfaces.MarklId,
 			sk *Transacted,
 		) (ok bool)
+
+		ReadManyObjectIds(objectIds []string) ([]*Transacted, error)
 	}
 
 	Index interface {
@@ -26,6 +26,15 @@ type (
 		ContainsObjectId(*ids.ObjectId) bool
 	}
 
+	// Implemented by queries that can reject objects from their object id,
+	// genre, tags and type alone, so that the stream index only decodes the
+	// rest of the objects they may contain. MayContainSku must be true for
+	// every object whose ContainsSku could be.
+	QueryWithPrefilter interface {
+		QueryWithSigilAndObjectId
+		MayContainSku(TransactedGetter) bool
+	}
+
 	// Used by store_verzeichnisse.binary*
 	PrimitiveQueryGroup interface {
 		Get(genres.Genre) (QueryWithSigilAndObjectId, bool)
@@ -33,6 +42,13 @@ type (
 		HasHidden() bool
 	}
 
+	// Implemented by query groups that bound how many stream index pages a
+	// query reads at once
+	PrimitiveQueryGroupWithJobs interface {
+		PrimitiveQueryGroup
+		GetJobs() int
+	}
+
 	FuncQuery = func(
 		QueryGroup,
 		interfaces.FuncIter[*Transacted],
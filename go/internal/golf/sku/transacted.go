@@ -145,6 +145,19 @@ func (transacted *Transacted) GetObjectId() *ids.ObjectId {
 	return &transacted.ObjectId
 }
 
+// GetObjectIdStringQualified returns the object id prefixed with the id of
+// the repo the object came from, as in `/work/one/uno`, or the bare object id
+// when the object has no repo id.
+func (transacted *Transacted) GetObjectIdStringQualified() string {
+	objectIdString := (&ids.StringerSansRepo{Id: &transacted.ObjectId}).String()
+
+	if transacted.RepoId.IsEmpty() {
+		return objectIdString
+	}
+
+	return transacted.RepoId.StringWithSlashPrefix() + "/" + objectIdString
+}
+
 func (transacted *Transacted) GetObjectIdMutable() *ids.ObjectId {
 	return &transacted.ObjectId
 }
@@ -0,0 +1,37 @@
+package blob_refcount_index
+
+import "fmt"
+
+type ErrNotBuilt struct {
+	Path string
+}
+
+func (err ErrNotBuilt) Error() string {
+	return fmt.Sprintf(
+		"blob refcount index %s has not been built yet, run `rebuild-blob-refcounts`",
+		err.Path,
+	)
+}
+
+func (err ErrNotBuilt) Is(target error) bool {
+	_, ok := target.(ErrNotBuilt)
+	return ok
+}
+
+type ErrBlobReferenced struct {
+	BlobId string
+	Count  uint64
+}
+
+func (err ErrBlobReferenced) Error() string {
+	return fmt.Sprintf(
+		"blob %s is referenced by %d object versions",
+		err.BlobId,
+		err.Count,
+	)
+}
+
+func (err ErrBlobReferenced) Is(target error) bool {
+	_, ok := target.(ErrBlobReferenced)
+	return ok
+}
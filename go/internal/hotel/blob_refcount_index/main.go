@@ -0,0 +1,252 @@
+package blob_refcount_index
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// Index counts, for every blob, the object versions that reference it. Every
+// committed version counts, not just the latest, as older versions can still
+// be checked out, so a count only ever drops when the index is rebuilt.
+//
+// The index is persisted as one "<count> <blob id>" line per blob. A missing
+// file means the index has never been built; it then ignores commits and
+// refuses to vouch for any deletion until it is rebuilt, as counts gathered
+// from only some of the history would call referenced blobs unreferenced.
+type Index struct {
+	path string
+
+	lock    sync.Mutex
+	built   bool
+	changed bool
+	counts  map[string]uint64
+}
+
+var _ blob_stores.DeletionPrecondition = &Index{}
+
+func Make(path string) (index *Index, err error) {
+	index = &Index{
+		path:   path,
+		counts: make(map[string]uint64),
+	}
+
+	if err = index.load(); err != nil {
+		return index, err
+	}
+
+	return index, err
+}
+
+func key(id domain_interfaces.MarklId) string {
+	return id.StringWithFormat()
+}
+
+func (index *Index) load() (err error) {
+	file, err := os.Open(index.path)
+	if errors.IsNotExist(err) {
+		err = nil
+		return err
+	} else if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+
+		countString, id, ok := strings.Cut(line, " ")
+		if !ok {
+			err = errors.Errorf(
+				"%s:%d: malformed line %q",
+				index.path,
+				lineNumber,
+				line,
+			)
+
+			return err
+		}
+
+		var count uint64
+
+		if count, err = strconv.ParseUint(countString, 10, 64); err != nil {
+			err = errors.Wrapf(err, "%s:%d", index.path, lineNumber)
+			return err
+		}
+
+		index.counts[id] = count
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	index.built = true
+
+	return err
+}
+
+// IsBuilt reports whether the index reflects the whole history.
+func (index *Index) IsBuilt() bool {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	return index.built
+}
+
+// Reset clears every count and marks the index as built, for a rebuild that
+// then adds every object version in the history.
+func (index *Index) Reset() {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	index.counts = make(map[string]uint64)
+	index.built = true
+	index.changed = true
+}
+
+// Invalidate marks the index as not built, for a rebuild that could not read
+// the whole history. Flush then removes the file.
+func (index *Index) Invalidate() {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	index.counts = make(map[string]uint64)
+	index.built = false
+	index.changed = true
+}
+
+// AddBlob records one more object version referencing id. It does nothing
+// for a null id or an index that has not been built.
+func (index *Index) AddBlob(id domain_interfaces.MarklId) {
+	if markl.IsNull(id) {
+		return
+	}
+
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	if !index.built {
+		return
+	}
+
+	index.counts[key(id)]++
+	index.changed = true
+}
+
+// GetCount returns the number of object versions referencing id.
+func (index *Index) GetCount(id domain_interfaces.MarklId) uint64 {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	return index.counts[key(id)]
+}
+
+// CheckBlobsSafeToDelete fails unless the index is built and no object
+// version references any of the blobs, so that deleting them from every
+// store loses nothing an object points to.
+func (index *Index) CheckBlobsSafeToDelete(
+	blobs interfaces.SeqError[domain_interfaces.MarklId],
+) (err error) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	if !index.built {
+		err = ErrNotBuilt{Path: index.path}
+		return err
+	}
+
+	for id, iterErr := range blobs {
+		if iterErr != nil {
+			err = errors.Wrap(iterErr)
+			return err
+		}
+
+		if count := index.counts[key(id)]; count > 0 {
+			err = ErrBlobReferenced{BlobId: key(id), Count: count}
+			return err
+		}
+	}
+
+	return err
+}
+
+// Flush writes the index if it changed since it was loaded, replacing the
+// file atomically, or removes the file if the index was invalidated.
+func (index *Index) Flush() (err error) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	if !index.changed {
+		return err
+	}
+
+	if !index.built {
+		if err = os.Remove(index.path); err != nil && !errors.IsNotExist(err) {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		index.changed = false
+
+		return nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(index.path), 0o755); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	ids := make([]string, 0, len(index.counts))
+
+	for id := range index.counts {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	if err = files.WriteAtomic(
+		index.path,
+		func(writer io.Writer) (err error) {
+			for _, id := range ids {
+				if _, err = fmt.Fprintf(
+					writer,
+					"%d %s\n",
+					index.counts[id],
+					id,
+				); err != nil {
+					return err
+				}
+			}
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	index.changed = false
+
+	return err
+}
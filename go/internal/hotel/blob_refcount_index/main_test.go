@@ -0,0 +1,133 @@
+package blob_refcount_index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+)
+
+func makeBlobId(t *testing.T, content string) domain_interfaces.MarklId {
+	t.Helper()
+
+	rawHash := sha256.Sum256([]byte(content))
+
+	id, repool := markl.FormatHashSha256.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+
+	t.Cleanup(repool)
+
+	return id
+}
+
+func seqOf(
+	ids ...domain_interfaces.MarklId,
+) func(func(domain_interfaces.MarklId, error) bool) {
+	return func(yield func(domain_interfaces.MarklId, error) bool) {
+		for _, id := range ids {
+			if !yield(id, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestUnbuiltIndexRefusesDeletion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blob_refcounts")
+
+	index, err := Make(path)
+	if err != nil {
+		t.Fatalf("Make: %v", err)
+	}
+
+	if index.IsBuilt() {
+		t.Fatal("expected an index without a file to be unbuilt")
+	}
+
+	blob := makeBlobId(t, "unreferenced")
+	index.AddBlob(blob)
+
+	if count := index.GetCount(blob); count != 0 {
+		t.Errorf("expected an unbuilt index to ignore commits, got %d", count)
+	}
+
+	if err := index.CheckBlobsSafeToDelete(seqOf(blob)); !errors.Is(
+		err,
+		ErrNotBuilt{},
+	) {
+		t.Errorf("expected ErrNotBuilt, got %v", err)
+	}
+}
+
+func TestRoundTripAndReferencedRefusal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blob_refcounts")
+
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := Make(path)
+	if err != nil {
+		t.Fatalf("Make: %v", err)
+	}
+
+	referenced := makeBlobId(t, "referenced")
+	unreferenced := makeBlobId(t, "unreferenced")
+
+	index.AddBlob(referenced)
+	index.AddBlob(referenced)
+
+	if err := index.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if index, err = Make(path); err != nil {
+		t.Fatalf("Make: %v", err)
+	}
+
+	if count := index.GetCount(referenced); count != 2 {
+		t.Errorf("expected 2 references after reload, got %d", count)
+	}
+
+	if err := index.CheckBlobsSafeToDelete(seqOf(unreferenced)); err != nil {
+		t.Errorf("expected an unreferenced blob to be deletable, got %v", err)
+	}
+
+	if err := index.CheckBlobsSafeToDelete(
+		seqOf(unreferenced, referenced),
+	); !errors.Is(err, ErrBlobReferenced{}) {
+		t.Errorf("expected ErrBlobReferenced, got %v", err)
+	}
+}
+
+func TestInvalidateRemovesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blob_refcounts")
+
+	index, err := Make(path)
+	if err != nil {
+		t.Fatalf("Make: %v", err)
+	}
+
+	index.Reset()
+	index.AddBlob(makeBlobId(t, "referenced"))
+
+	if err := index.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	index.Invalidate()
+
+	if err := index.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the invalidated index file to be removed, got %v", err)
+	}
+}
@@ -129,8 +129,9 @@ func (blobImporter *BlobImporter) ImportBlobToStoreIfNecessary(
 				hashType = dst.GetDefaultHashType()
 			}
 
-			copyResult.CopyResult = blob_stores.CopyBlobIfNecessary(
+			copyResult.CopyResult = blob_stores.CopyBlobIfNecessaryWithRetry(
 				blobImporter.EnvBlobStore,
+				errors.RetryPolicyDefault,
 				dst.GetBlobStore(),
 				blobImporter.Src.GetBlobStore(),
 				blobId,
@@ -0,0 +1,123 @@
+//go:build test && debug
+
+package blob_transfers
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/env_ui"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/env_local"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// testSrc holds a single blob in memory.
+type testSrc struct {
+	domain_interfaces.BlobStore
+	data []byte
+}
+
+func (src testSrc) HasBlob(domain_interfaces.MarklId) bool {
+	return true
+}
+
+func (src testSrc) MakeBlobReader(
+	domain_interfaces.MarklId,
+) (domain_interfaces.BlobReader, error) {
+	return env_dir.NewReader(env_dir.DefaultConfig, bytes.NewReader(src.data))
+}
+
+// testResumer is a destination that only takes blobs through resumed uploads.
+type testResumer struct {
+	domain_interfaces.BlobStore
+	hashType domain_interfaces.FormatHash
+	uploaded bytes.Buffer
+}
+
+var _ blob_stores.BlobUploadResumer = &testResumer{}
+
+func (dst *testResumer) HasBlob(domain_interfaces.MarklId) bool {
+	return false
+}
+
+func (dst *testResumer) GetDefaultHashType() domain_interfaces.FormatHash {
+	return dst.hashType
+}
+
+func (dst *testResumer) ResumeBlobUpload(
+	expected domain_interfaces.MarklId,
+	open func() (domain_interfaces.BlobReader, error),
+	pace func(io.Reader) io.Reader,
+) (size int64, err error) {
+	var reader domain_interfaces.BlobReader
+
+	if reader, err = open(); err != nil {
+		return size, err
+	}
+
+	defer reader.Close()
+
+	return io.Copy(&dst.uploaded, pace(reader))
+}
+
+func TestImportBlobResumesUploads(t *testing.T) {
+	src := testSrc{data: []byte("resumed through the blob importer")}
+
+	var id domain_interfaces.MarklId
+
+	{
+		reader, err := src.MakeBlobReader(nil)
+		if err != nil {
+			t.Fatalf("MakeBlobReader: %v", err)
+		}
+
+		if _, err := io.Copy(io.Discard, reader); err != nil {
+			t.Fatalf("Copy: %v", err)
+		}
+
+		id, _ = markl.Clone(reader.GetMarklId())
+	}
+
+	hashType, err := markl.GetFormatHashOrError(
+		id.GetMarklFormat().GetMarklFormatId(),
+	)
+	if err != nil {
+		t.Fatalf("GetFormatHashOrError: %v", err)
+	}
+
+	dst := &testResumer{hashType: hashType}
+
+	ctx := errors.MakeContextDefault()
+
+	importer := MakeBlobImporter(
+		env_repo.BlobStoreEnv{
+			Env: env_local.Make(env_ui.MakeDefault(ctx), nil),
+		},
+		blob_stores.BlobStoreInitialized{BlobStore: src},
+		nil,
+	)
+
+	copyResult := importer.ImportBlobToStoreIfNecessary(
+		blob_stores.BlobStoreInitialized{BlobStore: dst},
+		id,
+		nil,
+	)
+
+	if err := copyResult.GetError(); err != nil {
+		t.Fatalf("ImportBlobToStoreIfNecessary: %v", err)
+	}
+
+	if !bytes.Equal(dst.uploaded.Bytes(), src.data) {
+		t.Errorf("expected the upload to be resumed, got %q", dst.uploaded.Bytes())
+	}
+
+	if importer.Counts.Succeeded != 1 {
+		t.Errorf("expected one successful copy, got %+v", importer.Counts)
+	}
+}
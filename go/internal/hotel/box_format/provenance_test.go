@@ -0,0 +1,90 @@
+package box_format
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/options_print"
+	"code.linenisgreat.com/dodder/go/internal/alfa/string_format_writer"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+)
+
+func makeTestArchiveFormat() *BoxTransacted {
+	colorOptions := string_format_writer.ColorOptions{OffEntirely: true}
+
+	format := MakeBoxTransacted(
+		colorOptions,
+		options_print.Options{}.
+			WithPrintBlobDigests(true).
+			WithExcludeFields(true).
+			WithDescriptionInBox(true),
+		string_format_writer.MakeBoxStringEncoder(
+			string_format_writer.CliFormatTruncationNone,
+			colorOptions,
+		),
+		ids.Abbr{},
+		nil,
+		nil,
+		nil,
+	)
+
+	format.isArchive = true
+
+	return format
+}
+
+func TestProvenanceRoundTripsOnInventoryLists(t *testing.T) {
+	format := makeTestArchiveFormat()
+
+	expected := objects.Provenance{
+		RepoId:   "laptop",
+		Hostname: "laptop.local",
+		Version:  "v0.0.1",
+	}
+
+	object, repool := sku.GetTransactedPool().GetWithRepool()
+	defer repool()
+
+	tai := ids.NowTai()
+
+	if err := object.GetObjectIdMutable().SetWithSeq(tai.ToSeq()); err != nil {
+		t.Fatalf("SetWithSeq: %v", err)
+	}
+
+	object.SetTai(tai)
+	*object.GetMetadataMutable().GetProvenanceMutable() = expected
+
+	var sb strings.Builder
+	writer := bufio.NewWriter(&sb)
+
+	if _, err := format.EncodeStringTo(object, writer); err != nil {
+		t.Fatalf("EncodeStringTo: %v", err)
+	}
+
+	writer.Flush()
+
+	if !strings.Contains(sb.String(), `host="laptop.local"`) {
+		t.Errorf("expected the hostname in %q", sb.String())
+	}
+
+	decoded, repoolDecoded := sku.GetTransactedPool().GetWithRepool()
+	defer repoolDecoded()
+
+	if _, err := format.ReadStringFormat(
+		decoded,
+		bufio.NewReader(strings.NewReader(sb.String())),
+	); err != nil {
+		t.Fatalf("ReadStringFormat: %v", err)
+	}
+
+	if actual := decoded.GetMetadata().GetProvenance(); actual != expected {
+		t.Errorf("expected %#v but got %#v", expected, actual)
+	}
+
+	for field := range decoded.GetMetadata().GetIndex().GetFields() {
+		t.Errorf("expected no fields besides the provenance, got %q", field.Key)
+	}
+}
@@ -251,6 +251,20 @@ LOOP_AFTER_OBJECT_ID:
 				Value: value.String(),
 			}
 
+			if object.GetObjectId().GetGenre() == genres.InventoryList {
+				var ok bool
+
+				if ok, err = object.GetMetadataMutable().GetProvenanceMutable().SetField(
+					field.Key,
+					field.Value,
+				); err != nil {
+					err = errors.Wrap(err)
+					return err
+				} else if ok {
+					continue
+				}
+			}
+
 			field.ColorType = string_format_writer.ColorTypeUserData
 			object.GetMetadataMutable().GetIndexMutable().GetFieldsMutable().Append(field)
 
@@ -208,6 +208,12 @@ func (format *BoxTransacted) addFieldsObjectIds(
 		return err
 	}
 
+	// objects from federated repos are qualified with their repo id, in full
+	// as abbreviations are only unique within one repo
+	if !format.isArchive && internal.Value != "" && !object.GetRepoId().IsEmpty() {
+		internal.Value = object.GetObjectIdStringQualified()
+	}
+
 	switch {
 	// case internal.Value != "" && external.Value != "":
 	// 	if strings.HasPrefix(external.Value, strings.TrimPrefix(internal.Value,
@@ -273,6 +279,10 @@ func (format *BoxTransacted) addFieldsMetadata(
 
 	builder.AddTags(metadata)
 
+	if format.isArchive {
+		builder.AddProvenance(metadata)
+	}
+
 	if !options.BoxExcludeFields && !format.isArchive {
 		quiter.AppendSeq(&builder.Contents, metadata.GetIndex().GetFields())
 	}
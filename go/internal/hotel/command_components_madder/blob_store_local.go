@@ -37,6 +37,9 @@ func (cmd BlobStoreLocal) MakeBlobStoreLocal(
 		config.Debug,
 	)
 
+	dir.SetReadOnly(config.IsReadOnly())
+	dir.SetDryRun(config.IsDryRun())
+
 	ui := env_ui.Make(
 		req,
 		config,
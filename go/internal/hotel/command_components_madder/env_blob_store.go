@@ -24,16 +24,22 @@ func (cmd EnvBlobStore) MakeEnvBlobStore(
 	var debugOptions debug.Options
 	var cliConfig domain_interfaces.CLIConfigProvider
 	var envOptions env_ui.Options
+	var readOnly bool
+	var dryRun bool
 
 	switch c := configAny.(type) {
 	case *config_cli.Config:
 		debugOptions = c.Debug
 		cliConfig = c
+		readOnly = c.IsReadOnly()
+		dryRun = c.IsDryRun()
 		envOptions.CustomOut = c.CustomOut
 		envOptions.CustomErr = c.CustomErr
 	case *repo_config_cli.Config:
 		debugOptions = c.Debug
 		cliConfig = c
+		readOnly = c.IsReadOnly()
+		dryRun = c.IsDryRun()
 	default:
 		panic(fmt.Sprintf("unsupported config type: %T", configAny))
 	}
@@ -44,6 +50,9 @@ func (cmd EnvBlobStore) MakeEnvBlobStore(
 		debugOptions,
 	)
 
+	dir.SetReadOnly(readOnly)
+	dir.SetDryRun(dryRun)
+
 	envUI := env_ui.Make(
 		req,
 		cliConfig,
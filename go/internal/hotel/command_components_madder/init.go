@@ -1,15 +1,19 @@
 package command_components_madder
 
 import (
+	"bytes"
+	"os"
 	"path/filepath"
 
 	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
 	"code.linenisgreat.com/dodder/go/internal/bravo/directory_layout"
 	"code.linenisgreat.com/dodder/go/internal/charlie/triple_hyphen_io"
 	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
 	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
 )
 
 type Init struct{}
@@ -19,6 +23,117 @@ func (cmd Init) InitBlobStore(
 	envBlobStore env_repo.BlobStoreEnv,
 	id blob_store_id.Id,
 	config *blob_store_configs.TypedConfig,
+) (path directory_layout.BlobStorePath) {
+	path = cmd.GetBlobStorePath(envBlobStore, id)
+
+	if err := envBlobStore.MakeDirs(
+		filepath.Dir(path.GetBase()),
+		filepath.Dir(path.GetConfig()),
+	); err != nil {
+		envBlobStore.Cancel(err)
+		return path
+	}
+
+	if err := triple_hyphen_io.EncodeToFile(
+		blob_store_configs.Coder,
+		config,
+		path.GetConfig(),
+	); err != nil {
+		envBlobStore.Cancel(err)
+		return path
+	}
+
+	return path
+}
+
+// AddBlobStore writes the config of a new blob store next to the existing
+// ones. The config is validated, decoded again, and used to make the store
+// before it is written, since a config that fails to load stops every later
+// command, and it is written
+// to a temp file and renamed into place so that no command sees it partially
+// written.
+func (cmd Init) AddBlobStore(
+	ctx interfaces.ActiveContext,
+	envBlobStore env_repo.BlobStoreEnv,
+	id blob_store_id.Id,
+	config *blob_store_configs.TypedConfig,
+) (path directory_layout.BlobStorePath) {
+	path = cmd.GetBlobStorePath(envBlobStore, id)
+
+	if _, err := os.Stat(path.GetConfig()); err == nil {
+		errors.ContextCancelWithBadRequestf(
+			ctx,
+			"blob store %q already exists: %s",
+			id,
+			path.GetConfig(),
+		)
+
+		return path
+	}
+
+	if err := blob_store_configs.Validate(config.Blob); err != nil {
+		errors.ContextCancelWithBadRequestError(ctx, err)
+		return path
+	}
+
+	var buffer bytes.Buffer
+
+	if _, err := blob_store_configs.Coder.EncodeTo(config, &buffer); err != nil {
+		ctx.Cancel(errors.Wrap(err))
+		return path
+	}
+
+	{
+		var decoded blob_store_configs.TypedConfig
+
+		if _, err := blob_store_configs.Coder.DecodeFrom(
+			&decoded,
+			bytes.NewReader(buffer.Bytes()),
+		); err != nil {
+			ctx.Cancel(errors.Wrapf(err, "encoded config does not decode"))
+			return path
+		}
+
+		// remote stores connect lazily, so this only reads local files, like
+		// the config a pointer points to
+		if _, err := blob_stores.MakeBlobStore(
+			envBlobStore,
+			blob_store_configs.ConfigNamed{
+				Path:   path,
+				Config: decoded,
+			},
+			envBlobStore.GetBlobStores(),
+		); err != nil {
+			errors.ContextCancelWithBadRequestError(ctx, err)
+			return path
+		}
+	}
+
+	if err := envBlobStore.MakeDirs(
+		filepath.Dir(path.GetBase()),
+		filepath.Dir(path.GetConfig()),
+	); err != nil {
+		ctx.Cancel(err)
+		return path
+	}
+
+	if err := files.WriteFileAtomic(
+		path.GetConfig(),
+		buffer.Bytes(),
+	); err != nil {
+		ctx.Cancel(err)
+		return path
+	}
+
+	return path
+}
+
+// GetBlobStorePath returns where the blob store with id keeps its config and
+// blobs, which is relative to the working directory for ids with the cwd
+// location.
+func (cmd Init) GetBlobStorePath(
+	envBlobStore env_repo.BlobStoreEnv,
+	id blob_store_id.Id,
 ) (path directory_layout.BlobStorePath) {
 	var layout directory_layout.BlobStore = envBlobStore
 
@@ -44,22 +159,5 @@ func (cmd Init) InitBlobStore(
 		id.GetName(),
 	)
 
-	if err := envBlobStore.MakeDirs(
-		filepath.Dir(path.GetBase()),
-		filepath.Dir(path.GetConfig()),
-	); err != nil {
-		envBlobStore.Cancel(err)
-		return path
-	}
-
-	if err := triple_hyphen_io.EncodeToFile(
-		blob_store_configs.Coder,
-		config,
-		path.GetConfig(),
-	); err != nil {
-		envBlobStore.Cancel(err)
-		return path
-	}
-
 	return path
 }
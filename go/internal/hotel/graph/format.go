@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+var dotShapes = map[string]string{
+	"zettel":    "note",
+	KindTag:     "ellipse",
+	KindType:    "diamond",
+	KindBlob:    "cylinder",
+	KindVersion: "box",
+}
+
+var dotEdgeStyles = map[string]string{
+	EdgeTag:    "solid",
+	EdgeType:   "dotted",
+	EdgeBlob:   "dashed",
+	EdgeMother: "bold",
+	EdgeParent: "solid",
+}
+
+func dotQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+
+	return `"` + value + `"`
+}
+
+// WriteDOT writes the graph in the GraphViz DOT language, labeling nodes with
+// their id and label and edges with their kind.
+func (graph Graph) WriteDOT(writer io.Writer) (err error) {
+	bufferedWriter := bufio.NewWriter(writer)
+
+	fmt.Fprintln(bufferedWriter, "digraph dodder {")
+
+	for _, node := range graph.Nodes {
+		label := node.Id
+
+		if node.Label != "" {
+			label += "\n" + node.Label
+		}
+
+		shape, ok := dotShapes[node.Kind]
+
+		if !ok {
+			shape = "box"
+		}
+
+		fmt.Fprintf(
+			bufferedWriter,
+			"\t%s [label=%s, shape=%s];\n",
+			dotQuote(node.Id),
+			dotQuote(label),
+			shape,
+		)
+	}
+
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(
+			bufferedWriter,
+			"\t%s -> %s [label=%s, style=%s];\n",
+			dotQuote(edge.From),
+			dotQuote(edge.To),
+			dotQuote(edge.Kind),
+			dotEdgeStyles[edge.Kind],
+		)
+	}
+
+	fmt.Fprintln(bufferedWriter, "}")
+
+	if err = bufferedWriter.Flush(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// WriteJSON writes the graph as an object with `nodes` and `edges` arrays.
+func (graph Graph) WriteJSON(writer io.Writer) (err error) {
+	encoder := json.NewEncoder(writer)
+
+	if err = encoder.Encode(graph); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,221 @@
+package graph
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const (
+	KindTag     = "tag"
+	KindType    = "type"
+	KindBlob    = "blob"
+	KindVersion = "version"
+
+	EdgeTag    = "tag"
+	EdgeType   = "type"
+	EdgeBlob   = "blob"
+	EdgeMother = "mother"
+	EdgeParent = "parent"
+)
+
+// Node is an object, identified by its object id and with its lowercased
+// genre as its kind, or something objects refer to: a tag, a type, a blob, or
+// a previous version of an object. Tags and types share their node with the
+// tag or type object of the same id, if it is in the graph.
+type Node struct {
+	Id    string `json:"id"`
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+}
+
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+type Options struct {
+	Tags    bool
+	Types   bool
+	Blobs   bool
+	Mothers bool
+}
+
+// FuncReadMother reads the object version with the given object signature,
+// reporting whether it was found.
+type FuncReadMother func(domain_interfaces.MarklId, *sku.Transacted) bool
+
+// Builder collects the nodes and edges of the objects added to it. Add is safe
+// to call concurrently, so it can be passed to QueryTransacted.
+type Builder struct {
+	Options
+	ReadMother FuncReadMother
+
+	lock  sync.Mutex
+	nodes map[string]Node
+	edges map[Edge]struct{}
+}
+
+func (builder *Builder) Add(object *sku.Transacted) (err error) {
+	builder.lock.Lock()
+	defer builder.lock.Unlock()
+
+	if builder.nodes == nil {
+		builder.nodes = make(map[string]Node)
+		builder.edges = make(map[Edge]struct{})
+	}
+
+	metadata := object.GetMetadata()
+	objectId := object.GetObjectId().String()
+
+	// an object replaces the tag or type node added for its id
+	builder.nodes[objectId] = Node{
+		Id:    objectId,
+		Kind:  strings.ToLower(object.GetGenre().String()),
+		Label: metadata.GetDescription().String(),
+	}
+
+	if builder.Tags {
+		for tag := range metadata.GetTags().All() {
+			builder.addTag(tag.String())
+			builder.addEdge(objectId, tag.String(), EdgeTag)
+		}
+	}
+
+	if tipe := metadata.GetType().String(); builder.Types && tipe != "" {
+		builder.addNode(Node{Id: tipe, Kind: KindType})
+		builder.addEdge(objectId, tipe, EdgeType)
+	}
+
+	if blobId := metadata.GetBlobDigest(); builder.Blobs && !blobId.IsNull() {
+		builder.addNode(Node{Id: blobId.String(), Kind: KindBlob})
+		builder.addEdge(objectId, blobId.String(), EdgeBlob)
+	}
+
+	if builder.Mothers && builder.ReadMother != nil {
+		if err = builder.addMothers(object); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
+
+func (builder *Builder) addNode(node Node) {
+	if _, ok := builder.nodes[node.Id]; ok {
+		return
+	}
+
+	builder.nodes[node.Id] = node
+}
+
+func (builder *Builder) addEdge(from, to, kind string) {
+	builder.edges[Edge{From: from, To: to, Kind: kind}] = struct{}{}
+}
+
+// addTag adds the tag and its ancestors in the tag hierarchy, where the parent
+// of `project-2021-zit` is `project-2021`.
+func (builder *Builder) addTag(tag string) {
+	for {
+		builder.addNode(Node{Id: tag, Kind: KindTag})
+
+		index := strings.LastIndexByte(tag, '-')
+
+		if index <= 0 {
+			return
+		}
+
+		parent := tag[:index]
+		builder.addEdge(tag, parent, EdgeParent)
+		tag = parent
+	}
+}
+
+// addMothers follows the object's chain of previous versions, each its own
+// node identified by its object signature.
+func (builder *Builder) addMothers(object *sku.Transacted) (err error) {
+	mother, repoolMother := sku.GetTransactedPool().GetWithRepool()
+	defer repoolMother()
+
+	from := object.GetObjectId().String()
+
+	// copied, as each mother is read into the same object
+	var motherSig markl.Id
+	motherSig.ResetWithMarklId(object.GetMetadata().GetMotherObjectSig())
+
+	for !motherSig.IsNull() {
+		to := motherSig.String()
+		_, seen := builder.nodes[to]
+
+		builder.addEdge(from, to, EdgeMother)
+
+		if seen {
+			return err
+		}
+
+		sku.TransactedResetter.Reset(mother)
+
+		if !builder.ReadMother(&motherSig, mother) {
+			builder.addNode(Node{Id: to, Kind: KindVersion})
+			return err
+		}
+
+		builder.addNode(Node{
+			Id:    to,
+			Kind:  KindVersion,
+			Label: mother.GetObjectId().String() + " " + mother.GetTai().String(),
+		})
+
+		from = to
+		motherSig.ResetWithMarklId(mother.GetMetadata().GetMotherObjectSig())
+	}
+
+	return err
+}
+
+// Graph returns the nodes ordered by id and the edges ordered by their ends.
+func (builder *Builder) Graph() (graph Graph) {
+	builder.lock.Lock()
+	defer builder.lock.Unlock()
+
+	graph.Nodes = make([]Node, 0, len(builder.nodes))
+	graph.Edges = make([]Edge, 0, len(builder.edges))
+
+	for _, node := range builder.nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for edge := range builder.edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+
+	slices.SortFunc(graph.Nodes, func(left, right Node) int {
+		return strings.Compare(left.Id, right.Id)
+	})
+
+	slices.SortFunc(graph.Edges, func(left, right Edge) int {
+		if cmp := strings.Compare(left.From, right.From); cmp != 0 {
+			return cmp
+		}
+
+		if cmp := strings.Compare(left.To, right.To); cmp != 0 {
+			return cmp
+		}
+
+		return strings.Compare(left.Kind, right.Kind)
+	})
+
+	return graph
+}
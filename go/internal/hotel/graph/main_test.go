@@ -0,0 +1,191 @@
+package graph
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+)
+
+func makeObject(
+	t *testing.T,
+	objectId, description, tipe, blob string,
+	tags ...string,
+) *sku.Transacted {
+	t.Helper()
+
+	object, repool := sku.GetTransactedPool().GetWithRepool()
+	t.Cleanup(repool)
+
+	if err := object.GetObjectIdMutable().Set(objectId); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := object.GetMetadataMutable()
+
+	if err := metadata.GetDescriptionMutable().Set(description); err != nil {
+		t.Fatal(err)
+	}
+
+	if tipe != "" {
+		if err := metadata.GetTypeMutable().SetType(tipe); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tag := range tags {
+		if err := metadata.AddTagString(tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rawHash := sha256.Sum256([]byte(blob))
+
+	blobId, repoolBlobId := markl.FormatHashSha256.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	t.Cleanup(repoolBlobId)
+
+	metadata.GetBlobDigestMutable().ResetWithMarklId(blobId)
+
+	return object
+}
+
+func setSig(t *testing.T, object *sku.Transacted, seed byte) {
+	t.Helper()
+
+	sig := object.GetMetadataMutable().GetObjectSigMutable()
+
+	if err := sig.SetMarklId(
+		markl.FormatIdEd25519Sig,
+		bytes.Repeat([]byte{seed}, 64),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sig.SetPurposeId(markl.PurposeObjectSigV2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTagsAndTypes(t *testing.T) {
+	builder := Builder{Options: Options{Tags: true, Types: true}}
+
+	if err := builder.Add(
+		makeObject(t, "one/uno", "wow", "md", "body", "project-2021-zit"),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := builder.Add(makeObject(t, "project", "", "", "")); err != nil {
+		t.Fatal(err)
+	}
+
+	graph := builder.Graph()
+
+	expectedNodes := []Node{
+		{Id: "!md", Kind: KindType},
+		{Id: "one/uno", Kind: "zettel", Label: "wow"},
+		{Id: "project", Kind: "tag"},
+		{Id: "project-2021", Kind: KindTag},
+		{Id: "project-2021-zit", Kind: KindTag},
+	}
+
+	if !slices.Equal(graph.Nodes, expectedNodes) {
+		t.Errorf("expected nodes %v, got %v", expectedNodes, graph.Nodes)
+	}
+
+	expectedEdges := []Edge{
+		{From: "one/uno", To: "!md", Kind: EdgeType},
+		{From: "one/uno", To: "project-2021-zit", Kind: EdgeTag},
+		{From: "project-2021", To: "project", Kind: EdgeParent},
+		{From: "project-2021-zit", To: "project-2021", Kind: EdgeParent},
+	}
+
+	if !slices.Equal(graph.Edges, expectedEdges) {
+		t.Errorf("expected edges %v, got %v", expectedEdges, graph.Edges)
+	}
+}
+
+func TestBlobsAndMothers(t *testing.T) {
+	first := makeObject(t, "one/uno", "first", "md", "first")
+	setSig(t, first, 1)
+
+	second := makeObject(t, "one/uno", "second", "md", "second")
+	setSig(t, second, 2)
+
+	if err := second.SetMother(first); err != nil {
+		t.Fatal(err)
+	}
+
+	latest := makeObject(t, "one/uno", "latest", "md", "latest")
+
+	if err := latest.SetMother(second); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := map[string]*sku.Transacted{
+		first.GetMetadata().GetObjectSig().String():  first,
+		second.GetMetadata().GetObjectSig().String(): second,
+	}
+
+	builder := Builder{
+		Options: Options{Blobs: true, Mothers: true},
+		ReadMother: func(
+			sig domain_interfaces.MarklId,
+			object *sku.Transacted,
+		) bool {
+			version, ok := versions[sig.String()]
+
+			if ok {
+				sku.TransactedResetter.ResetWith(object, version)
+			}
+
+			return ok
+		},
+	}
+
+	if err := builder.Add(latest); err != nil {
+		t.Fatal(err)
+	}
+
+	graph := builder.Graph()
+
+	var kinds []string
+
+	for _, edge := range graph.Edges {
+		kinds = append(kinds, edge.Kind)
+	}
+
+	slices.Sort(kinds)
+
+	if expected := []string{EdgeBlob, EdgeMother, EdgeMother}; !slices.Equal(
+		kinds,
+		expected,
+	) {
+		t.Fatalf("expected edges %q, got %v", expected, graph.Edges)
+	}
+
+	var out bytes.Buffer
+
+	if err := graph.WriteDOT(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	dot := out.String()
+
+	if !strings.Contains(dot, `"one/uno" -> "`+
+		second.GetMetadata().GetObjectSig().String()+`" [label="mother"`) {
+		t.Errorf("expected the latest version to point at its mother:\n%s", dot)
+	}
+
+	if !strings.Contains(dot, `label="`+second.GetMetadata().GetObjectSig().String()+`\none/uno`) {
+		t.Errorf("expected the mother to be labeled with its object id:\n%s", dot)
+	}
+}
@@ -0,0 +1,190 @@
+package maintenance
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// Task is a unit of maintenance, like rebuilding a cache, that the
+// Coordinator runs when it is due.
+type Task struct {
+	Name string
+	Run  func() error
+}
+
+type Options struct {
+	// how long a task waits after it last ran
+	Interval time.Duration
+
+	// how long the tasks of one RunDue may run together; no task is started
+	// once it is used up, but a started task is not interrupted
+	Budget time.Duration
+}
+
+// Result is a task that RunDue started, with its error, if any.
+type Result struct {
+	Name    string
+	Elapsed time.Duration
+	Err     error
+}
+
+// Coordinator runs the maintenance tasks that are due, recording when each
+// last ran so that the next process knows.
+//
+// The last runs are persisted as one "<task> <unix seconds>" line per task. A
+// task is recorded when it is started, whether or not it succeeds, so a task
+// that keeps failing is retried once per interval and not by every command.
+// The Coordinator is not safe for concurrent processes; callers hold the repo
+// lock.
+type Coordinator struct {
+	path    string
+	now     func() time.Time
+	lastRun map[string]time.Time
+}
+
+func Make(path string) (coordinator *Coordinator, err error) {
+	coordinator = &Coordinator{
+		path:    path,
+		now:     time.Now,
+		lastRun: make(map[string]time.Time),
+	}
+
+	if err = coordinator.load(); err != nil {
+		return coordinator, err
+	}
+
+	return coordinator, err
+}
+
+func (coordinator *Coordinator) load() (err error) {
+	file, err := os.Open(coordinator.path)
+	if errors.IsNotExist(err) {
+		err = nil
+		return err
+	} else if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		name, secondsString, ok := strings.Cut(line, " ")
+		if !ok {
+			err = errors.Errorf("malformed maintenance entry: %q", line)
+			return err
+		}
+
+		var seconds int64
+
+		if seconds, err = strconv.ParseInt(secondsString, 10, 64); err != nil {
+			err = errors.Wrapf(err, "maintenance entry: %q", line)
+			return err
+		}
+
+		coordinator.lastRun[name] = time.Unix(seconds, 0)
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// GetLastRun returns when a task was last started.
+func (coordinator *Coordinator) GetLastRun(name string) (time.Time, bool) {
+	lastRun, ok := coordinator.lastRun[name]
+	return lastRun, ok
+}
+
+// IsDue is true when a task never ran, or last ran at least interval ago.
+func (coordinator *Coordinator) IsDue(name string, interval time.Duration) bool {
+	lastRun, ok := coordinator.lastRun[name]
+	return !ok || coordinator.now().Sub(lastRun) >= interval
+}
+
+// RunDue runs, in order, the tasks that are due until the budget is used up,
+// and then writes when they ran. The errors of tasks are returned in their
+// results, as maintenance failing should not fail the command it ran after;
+// err is only set when the last runs could not be written.
+func (coordinator *Coordinator) RunDue(
+	options Options,
+	tasks ...Task,
+) (results []Result, err error) {
+	start := coordinator.now()
+
+	for _, task := range tasks {
+		if coordinator.now().Sub(start) >= options.Budget {
+			break
+		}
+
+		if !coordinator.IsDue(task.Name, options.Interval) {
+			continue
+		}
+
+		taskStart := coordinator.now()
+		coordinator.lastRun[task.Name] = taskStart
+
+		result := Result{Name: task.Name}
+		result.Err = task.Run()
+		result.Elapsed = coordinator.now().Sub(taskStart)
+
+		results = append(results, result)
+	}
+
+	if len(results) == 0 {
+		return results, err
+	}
+
+	if err = coordinator.flush(); err != nil {
+		err = errors.Wrap(err)
+		return results, err
+	}
+
+	return results, err
+}
+
+// flush replaces the file of last runs atomically.
+func (coordinator *Coordinator) flush() (err error) {
+	if err = os.MkdirAll(filepath.Dir(coordinator.path), 0o755); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	lines := make([]string, 0, len(coordinator.lastRun))
+
+	for name, lastRun := range coordinator.lastRun {
+		lines = append(lines, fmt.Sprintf("%s %d", name, lastRun.Unix()))
+	}
+
+	slices.Sort(lines)
+
+	if err = files.WriteFileAtomic(
+		coordinator.path,
+		[]byte(strings.Join(lines, "\n")+"\n"),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,106 @@
+package maintenance
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunDueRecordsLastRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maintenance")
+	now := time.Unix(1_700_000_000, 0)
+
+	coordinator, err := Make(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coordinator.now = func() time.Time { return now }
+
+	var ran []string
+
+	tasks := []Task{
+		{Name: "rebuild", Run: func() error {
+			ran = append(ran, "rebuild")
+			return nil
+		}},
+		{Name: "pack", Run: func() error {
+			ran = append(ran, "pack")
+			return errors.New("store busy")
+		}},
+	}
+
+	options := Options{Interval: time.Hour, Budget: time.Second}
+
+	results, err := coordinator.RunDue(options, tasks...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 || results[1].Err == nil {
+		t.Fatalf("expected both tasks to run and pack to fail, got %+v", results)
+	}
+
+	reloaded, err := Make(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded.now = func() time.Time { return now.Add(30 * time.Minute) }
+
+	if results, err = reloaded.RunDue(options, tasks...); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 0 {
+		t.Errorf("expected no task to be due, got %+v", results)
+	}
+
+	reloaded.now = func() time.Time { return now.Add(time.Hour) }
+
+	if results, err = reloaded.RunDue(options, tasks...); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 || len(ran) != 4 {
+		t.Errorf("expected both tasks to be due again, got %+v", results)
+	}
+}
+
+func TestRunDueStopsWhenBudgetIsUsedUp(t *testing.T) {
+	coordinator, err := Make(filepath.Join(t.TempDir(), "maintenance"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1_700_000_000, 0)
+	coordinator.now = func() time.Time { return now }
+
+	slow := Task{Name: "slow", Run: func() error {
+		now = now.Add(2 * time.Second)
+		return nil
+	}}
+
+	skipped := Task{Name: "skipped", Run: func() error {
+		t.Error("expected the budget to be used up")
+		return nil
+	}}
+
+	results, err := coordinator.RunDue(
+		Options{Interval: time.Hour, Budget: time.Second},
+		slow,
+		skipped,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Errorf("expected only the slow task to run, got %+v", results)
+	}
+
+	if _, ok := coordinator.GetLastRun("skipped"); ok {
+		t.Error("expected the skipped task to stay due")
+	}
+}
@@ -0,0 +1,26 @@
+package object_diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrConflict is returned when an object changed since it was edited in the
+// same fields as the edit.
+type ErrConflict struct {
+	ObjectId string
+	Fields   []string
+}
+
+func (err ErrConflict) Error() string {
+	return fmt.Sprintf(
+		"%s changed while it was being edited, conflicting fields: %s",
+		err.ObjectId,
+		strings.Join(err.Fields, ", "),
+	)
+}
+
+func (err ErrConflict) Is(target error) bool {
+	_, ok := target.(ErrConflict)
+	return ok
+}
@@ -0,0 +1,194 @@
+package object_diff
+
+import (
+	"slices"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/descriptions"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const (
+	FieldTags        = "tags"
+	FieldDescription = "description"
+	FieldType        = "type"
+	FieldBlob        = "blob"
+)
+
+// Diff is what an edit changed in the user-editable fields of an object: its
+// tags, description, type, and blob. Applying it to a newer version of the
+// object changes only those fields, so that changes made to the others in the
+// meantime are kept.
+type Diff struct {
+	TagsAdded   []string
+	TagsRemoved []string
+
+	DescriptionChanged bool
+	Description        descriptions.Description
+
+	TypeChanged bool
+	Type        ids.SeqId
+
+	BlobChanged bool
+	Blob        markl.Id
+}
+
+// Make compares the edited object against the version it was edited from.
+func Make(base, edited *sku.Transacted) (diff Diff) {
+	baseTags := tagStrings(base)
+	editedTags := tagStrings(edited)
+
+	for _, tag := range editedTags {
+		if !slices.Contains(baseTags, tag) {
+			diff.TagsAdded = append(diff.TagsAdded, tag)
+		}
+	}
+
+	for _, tag := range baseTags {
+		if !slices.Contains(editedTags, tag) {
+			diff.TagsRemoved = append(diff.TagsRemoved, tag)
+		}
+	}
+
+	if !base.GetMetadata().GetDescription().Equals(
+		edited.GetMetadata().GetDescription(),
+	) {
+		diff.DescriptionChanged = true
+		diff.Description.ResetWith(edited.GetMetadata().GetDescription())
+	}
+
+	if !base.GetMetadata().GetType().Equals(edited.GetMetadata().GetType()) {
+		diff.TypeChanged = true
+		diff.Type.ResetWith(edited.GetMetadata().GetType())
+	}
+
+	if !markl.Equals(base.GetBlobDigest(), edited.GetBlobDigest()) {
+		diff.BlobChanged = true
+		diff.Blob.ResetWithMarklId(edited.GetBlobDigest())
+	}
+
+	return diff
+}
+
+func tagStrings(object *sku.Transacted) (tags []string) {
+	for tag := range object.GetMetadata().AllTags() {
+		tags = append(tags, tag.String())
+	}
+
+	slices.Sort(tags)
+
+	return tags
+}
+
+func (diff Diff) IsEmpty() bool {
+	return len(diff.GetFields()) == 0
+}
+
+// GetFields returns the names of the changed fields.
+func (diff Diff) GetFields() (fields []string) {
+	if len(diff.TagsAdded) > 0 || len(diff.TagsRemoved) > 0 {
+		fields = append(fields, FieldTags)
+	}
+
+	if diff.DescriptionChanged {
+		fields = append(fields, FieldDescription)
+	}
+
+	if diff.TypeChanged {
+		fields = append(fields, FieldType)
+	}
+
+	if diff.BlobChanged {
+		fields = append(fields, FieldBlob)
+	}
+
+	return fields
+}
+
+// GetConflicts returns the fields that diff and other, both made from the
+// same base, changed in incompatible ways: a description, type, or blob
+// changed to different values, or a tag one added and the other removed.
+// Tags added or removed by both, or by only one, merge.
+func (diff Diff) GetConflicts(other Diff) (fields []string) {
+	tagsConflict := false
+
+	for _, tag := range diff.TagsAdded {
+		if slices.Contains(other.TagsRemoved, tag) {
+			tagsConflict = true
+		}
+	}
+
+	for _, tag := range diff.TagsRemoved {
+		if slices.Contains(other.TagsAdded, tag) {
+			tagsConflict = true
+		}
+	}
+
+	if tagsConflict {
+		fields = append(fields, FieldTags)
+	}
+
+	if diff.DescriptionChanged && other.DescriptionChanged &&
+		!diff.Description.Equals(other.Description) {
+		fields = append(fields, FieldDescription)
+	}
+
+	if diff.TypeChanged && other.TypeChanged &&
+		!diff.Type.Equals(other.Type) {
+		fields = append(fields, FieldType)
+	}
+
+	if diff.BlobChanged && other.BlobChanged &&
+		!markl.Equals(diff.Blob, other.Blob) {
+		fields = append(fields, FieldBlob)
+	}
+
+	return fields
+}
+
+// Apply changes the fields of object that diff changed, leaving the rest as
+// they are.
+func (diff Diff) Apply(object *sku.Transacted) (err error) {
+	metadata := object.GetMetadataMutable()
+
+	if len(diff.TagsAdded) > 0 || len(diff.TagsRemoved) > 0 {
+		tags := tagStrings(object)
+
+		tags = slices.DeleteFunc(tags, func(tag string) bool {
+			return slices.Contains(diff.TagsRemoved, tag)
+		})
+
+		for _, tag := range diff.TagsAdded {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		metadata.ResetTags()
+
+		for _, tag := range tags {
+			if err = metadata.AddTagString(tag); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+		}
+	}
+
+	if diff.DescriptionChanged {
+		metadata.GetDescriptionMutable().ResetWith(diff.Description)
+	}
+
+	if diff.TypeChanged {
+		// the lock pins the signature of the old type
+		metadata.GetTypeLockMutable().GetValueMutable().Reset()
+		metadata.GetTypeMutable().ResetWith(diff.Type)
+	}
+
+	if diff.BlobChanged {
+		metadata.GetBlobDigestMutable().ResetWithMarklId(diff.Blob)
+	}
+
+	return err
+}
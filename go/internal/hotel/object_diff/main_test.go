@@ -0,0 +1,129 @@
+package object_diff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+)
+
+func makeObject(
+	t *testing.T,
+	description, tipe, blob string,
+	tags ...string,
+) *sku.Transacted {
+	t.Helper()
+
+	object, repool := sku.GetTransactedPool().GetWithRepool()
+	t.Cleanup(repool)
+
+	metadata := object.GetMetadataMutable()
+
+	if err := metadata.GetDescriptionMutable().Set(description); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := metadata.GetTypeMutable().SetType(tipe); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tag := range tags {
+		if err := metadata.AddTagString(tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rawHash := sha256.Sum256([]byte(blob))
+
+	blobId, repoolBlobId := markl.FormatHashSha256.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	t.Cleanup(repoolBlobId)
+
+	metadata.GetBlobDigestMutable().ResetWithMarklId(blobId)
+
+	return object
+}
+
+func TestMakeAndApply(t *testing.T) {
+	base := makeObject(t, "wow", "!md", "body", "one", "two")
+	edited := makeObject(t, "wow", "!md", "body", "two", "three")
+
+	diff := Make(base, edited)
+
+	if fields := diff.GetFields(); !slices.Equal(fields, []string{FieldTags}) {
+		t.Fatalf("expected only tags to change, got %q", fields)
+	}
+
+	if !slices.Equal(diff.TagsAdded, []string{"three"}) ||
+		!slices.Equal(diff.TagsRemoved, []string{"one"}) {
+		t.Fatalf(
+			"expected +three -one, got +%q -%q",
+			diff.TagsAdded,
+			diff.TagsRemoved,
+		)
+	}
+
+	// the object changed underneath: its description and another tag
+	current := makeObject(t, "newer", "!md", "body", "one", "two", "four")
+
+	if conflicts := diff.GetConflicts(Make(base, current)); len(conflicts) > 0 {
+		t.Fatalf("expected no conflicts, got %q", conflicts)
+	}
+
+	if err := diff.Apply(current); err != nil {
+		t.Fatal(err)
+	}
+
+	if tags := tagStrings(current); !slices.Equal(
+		tags,
+		[]string{"four", "three", "two"},
+	) {
+		t.Errorf("expected merged tags, got %q", tags)
+	}
+
+	if description := current.GetMetadata().GetDescription().String(); description != "newer" {
+		t.Errorf("expected the newer description to be kept, got %q", description)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	base := makeObject(t, "wow", "!md", "body", "one")
+	edited := makeObject(t, "mine", "!md", "edited body", "two")
+	current := makeObject(t, "theirs", "!md", "edited body", "one", "two")
+
+	conflicts := Make(base, edited).GetConflicts(Make(base, current))
+
+	// both changed the blob the same way and added the same tag, which merge
+	if expected := []string{FieldDescription}; !slices.Equal(conflicts, expected) {
+		t.Errorf("expected conflicts %q, got %q", expected, conflicts)
+	}
+
+	current = makeObject(t, "wow", "!txt", "other body")
+	conflicts = Make(base, edited).GetConflicts(Make(base, current))
+
+	// both removed "one", and only the blob changed to different values
+	if expected := []string{FieldBlob}; !slices.Equal(conflicts, expected) {
+		t.Errorf("expected conflicts %q, got %q", expected, conflicts)
+	}
+
+	current = makeObject(t, "wow", "!md", "body", "one", "three")
+	edited = makeObject(t, "wow", "!md", "body")
+	base = makeObject(t, "wow", "!md", "body", "one", "three")
+
+	if conflicts := Make(base, edited).GetConflicts(Make(base, current)); len(conflicts) > 0 {
+		t.Errorf("expected an unchanged current to never conflict, got %q", conflicts)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	base := makeObject(t, "wow", "!md", "body", "one")
+	edited := makeObject(t, "wow", "!md", "body", "one")
+
+	if diff := Make(base, edited); !diff.IsEmpty() {
+		t.Errorf("expected an identical object to have no changes, got %q", diff.GetFields())
+	}
+}
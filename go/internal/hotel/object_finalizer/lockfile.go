@@ -3,71 +3,39 @@ package object_finalizer
 import (
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
 	"code.linenisgreat.com/dodder/go/internal/delta/objects"
-	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 )
 
-func (finalizer finalizer) writeTypeLockIfNecessary(
-	metadata objects.MetadataMutable,
-	tipe ids.Type,
-	funcs ...sku.FuncReadOne,
-) (err error) {
-	if tipe.IsEmpty() {
-		err = ErrEmptyLockKey
-		return err
-	} else if ids.IsBuiltin(tipe) {
-		// TODO stop excluding builtin types and create a process for signing those
-		// too
-		err = ErrBuiltinType
-		return err
-	}
-
-	typeLock := metadata.GetTypeLockMutable()
-
-	// TODO There are cases where we will want to overwrite the typelock id,
-	// should we use CommitOptions?
-	if !typeLock.GetValue().IsNull() {
-		return err
-	}
-
-	typeObject, repool := sku.GetTransactedPool().GetWithRepool()
-	defer repool()
-
-	if ok := sku.ReadOneObjectIdBespoke(tipe, typeObject, funcs...); ok {
-		typeLock.GetValueMutable().ResetWithMarklId(typeObject.GetMetadataMutable().GetObjectSig())
-	} else {
-		err = ErrFailedToReadCurrentLockObject
-		return err
-	}
-
-	return err
+// lockKeys are the object ids of an object's type and tags whose locks are not
+// set yet, with the type first if it is one of them, so that they can be read
+// in one batch.
+type lockKeys struct {
+	objectIds []string
+	hasType   bool
+	tags      []ids.TagStruct
 }
 
-func (finalizer finalizer) writeTagLockIfNecessary(
-	metadata objects.MetadataMutable,
-	tag ids.TagStruct, funcs ...sku.FuncReadOne,
-) (err error) {
-	if tag.IsEmpty() {
-		err = ErrEmptyLockKey
-		return err
-	}
-
-	tagLock := metadata.GetTagLockMutable(tag)
+func collectLockKeys(metadata objects.MetadataMutable) (keys lockKeys) {
+	tipe := metadata.GetType()
 
+	// TODO stop excluding builtin types and create a process for signing those
+	// too
 	// TODO There are cases where we will want to overwrite the typelock id,
 	// should we use CommitOptions?
-	if !tagLock.GetValue().IsNull() {
-		return err
+	if !tipe.IsEmpty() &&
+		!ids.IsBuiltin(tipe) &&
+		metadata.GetTypeLockMutable().GetValue().IsNull() {
+		keys.hasType = true
+		keys.objectIds = append(keys.objectIds, tipe.String())
 	}
 
-	typeObject, repool := sku.GetTransactedPool().GetWithRepool()
-	defer repool()
+	for tag := range metadata.GetTags().All() {
+		if tag.IsEmpty() || !metadata.GetTagLockMutable(tag).GetValue().IsNull() {
+			continue
+		}
 
-	if ok := sku.ReadOneObjectIdBespoke(tag, typeObject, funcs...); ok {
-		tagLock.GetValueMutable().ResetWithMarklId(typeObject.GetMetadataMutable().GetObjectSig())
-	} else {
-		err = ErrFailedToReadCurrentLockObject
-		return err
+		keys.tags = append(keys.tags, tag)
+		keys.objectIds = append(keys.objectIds, tag.String())
 	}
 
-	return err
+	return keys
 }
@@ -132,8 +132,7 @@ func (finalizer finalizer) WriteLockfileIfNecessary(
 	return finalizer.WriteLockfile(
 		object,
 		sku.LockfileOptions{},
-		finalizer.index.ReadOneMarklIdAdded,
-		finalizer.index.ReadOneMarklId,
+		finalizer.index.ReadManyObjectIds,
 	)
 }
 
@@ -141,52 +140,46 @@ func (finalizer finalizer) WriteLockfileIfNecessary(
 func (finalizer finalizer) WriteLockfile(
 	object object,
 	options sku.LockfileOptions,
-	funcs ...sku.FuncReadOne,
+	readMany sku.FuncReadMany,
 ) (err error) {
 	metadata := object.GetMetadataMutable()
+	keys := collectLockKeys(metadata)
 
-	tipe := metadata.GetType()
+	if len(keys.objectIds) == 0 {
+		return err
+	}
 
-	if err = finalizer.writeTypeLockIfNecessary(
-		metadata,
-		tipe,
-		funcs...,
-	); err != nil {
-		switch err {
-		case ErrEmptyLockKey, ErrBuiltinType:
-			err = nil
+	var lockObjects []*sku.Transacted
 
-		case ErrFailedToReadCurrentLockObject:
-			if options.AllowTypeFailure {
-				err = nil
-				break
-			}
+	if lockObjects, err = readMany(keys.objectIds); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
 
-			fallthrough
+	if keys.hasType {
+		if typeObject := lockObjects[0]; typeObject != nil {
+			metadata.GetTypeLockMutable().GetValueMutable().ResetWithMarklId(
+				typeObject.GetMetadataMutable().GetObjectSig(),
+			)
+		} else if !options.AllowTypeFailure {
+			err = errors.Wrapf(
+				ErrFailedToReadCurrentLockObject,
+				"failed to write type lock for type: %q",
+				metadata.GetType(),
+			)
 
-		default:
-			err = errors.Wrapf(err, "failed to write type lock for type: %q", tipe)
 			return err
 		}
-	}
 
-	for tag := range metadata.GetTags().All() {
-		if err = finalizer.writeTagLockIfNecessary(
-			metadata,
-			tag,
-			funcs...,
-		); err != nil {
-			switch err {
-			case ErrEmptyLockKey:
-				err = nil
-
-			case ErrFailedToReadCurrentLockObject:
-				err = nil
+		lockObjects = lockObjects[1:]
+	}
 
-			default:
-				err = errors.Wrapf(err, "failed to write tag lock for tag: %q", tag)
-				return err
-			}
+	// tags without objects of their own are left without locks
+	for i, tag := range keys.tags {
+		if tagObject := lockObjects[i]; tagObject != nil {
+			metadata.GetTagLockMutable(tag).GetValueMutable().ResetWithMarklId(
+				tagObject.GetMetadataMutable().GetObjectSig(),
+			)
 		}
 	}
 
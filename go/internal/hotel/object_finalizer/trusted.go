@@ -0,0 +1,103 @@
+package object_finalizer
+
+import (
+	"fmt"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// TrustedRepoPubKeys is the set of repo public keys whose object signatures
+// are accepted on import, keyed by the raw key bytes.
+type TrustedRepoPubKeys map[string]struct{}
+
+func MakeTrustedRepoPubKeys[T domain_interfaces.MarklId](
+	pubKeys ...T,
+) TrustedRepoPubKeys {
+	trusted := make(TrustedRepoPubKeys, len(pubKeys))
+
+	for _, pubKey := range pubKeys {
+		trusted.Add(pubKey)
+	}
+
+	return trusted
+}
+
+func (trusted TrustedRepoPubKeys) Add(pubKey domain_interfaces.MarklId) {
+	if pubKey == nil || pubKey.IsNull() {
+		return
+	}
+
+	trusted[string(pubKey.GetBytes())] = struct{}{}
+}
+
+func (trusted TrustedRepoPubKeys) Contains(
+	pubKey domain_interfaces.MarklId,
+) bool {
+	_, ok := trusted[string(pubKey.GetBytes())]
+	return ok
+}
+
+type ErrUntrustedRepoPubKey struct {
+	PubKey string
+	Object string
+}
+
+func (err ErrUntrustedRepoPubKey) Error() string {
+	return fmt.Sprintf(
+		"object %s is signed by untrusted repo pubkey %q (add it with `trust-repo-pubkey` if it is expected)",
+		err.Object,
+		err.PubKey,
+	)
+}
+
+func (err ErrUntrustedRepoPubKey) Is(target error) bool {
+	_, ok := target.(ErrUntrustedRepoPubKey)
+	return ok
+}
+
+func IsErrUntrustedRepoPubKey(err error) bool {
+	return errors.Is(err, ErrUntrustedRepoPubKey{})
+}
+
+// VerifyTrusted checks that the object carries a valid signature made by one
+// of the trusted repo pubkeys. A nil set skips the trust check but still
+// requires a valid signature. Objects from v1 inventory lists predate
+// signatures and are skipped.
+func (finalizer *Finalizer) VerifyTrusted(
+	object *sku.Transacted,
+	trusted TrustedRepoPubKeys,
+) (err error) {
+	metadata := object.GetMetadata()
+
+	if metadata.GetType().String() == ids.TypeInventoryListV1 {
+		return err
+	}
+
+	if err = finalizer.verify(object, defaultVerifyOptions); err != nil {
+		err = errors.Wrapf(
+			err,
+			"signature verification failed for object %s",
+			sku.String(object),
+		)
+
+		return err
+	}
+
+	if trusted == nil {
+		return err
+	}
+
+	if !trusted.Contains(metadata.GetRepoPubKey()) {
+		err = errors.Wrap(ErrUntrustedRepoPubKey{
+			PubKey: metadata.GetRepoPubKey().StringWithFormat(),
+			Object: sku.String(object),
+		})
+
+		return err
+	}
+
+	return err
+}
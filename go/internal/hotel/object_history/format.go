@@ -0,0 +1,77 @@
+package object_history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// WriteText writes each version as its tai and signature, the provenance of
+// the list that committed it, a line per changed field, and its blob diff.
+func (history History) WriteText(writer io.Writer) (err error) {
+	bufferedWriter := bufio.NewWriter(writer)
+
+	for i, version := range history.Versions {
+		if i > 0 {
+			fmt.Fprintln(bufferedWriter)
+		}
+
+		fmt.Fprintf(bufferedWriter, "%s @%s", history.ObjectId, version.Tai)
+
+		if version.ObjectSig != "" {
+			fmt.Fprintf(bufferedWriter, " %s", version.ObjectSig)
+		}
+
+		fmt.Fprintln(bufferedWriter)
+
+		if version.Provenance != nil {
+			fmt.Fprintf(bufferedWriter, "  from: %s\n", version.Provenance)
+		}
+
+		for _, change := range version.Changes {
+			fmt.Fprintf(
+				bufferedWriter,
+				"  %s: %s -> %s\n",
+				change.Field,
+				textOrNone(change.Before),
+				textOrNone(change.After),
+			)
+		}
+
+		fmt.Fprint(bufferedWriter, version.BlobDiff)
+	}
+
+	if history.Truncated {
+		fmt.Fprintln(bufferedWriter, "\n(older versions are missing)")
+	}
+
+	if err = bufferedWriter.Flush(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func textOrNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+
+	return fmt.Sprintf("%q", value)
+}
+
+// WriteJSON writes the history as an object with a `versions` array.
+func (history History) WriteJSON(writer io.Writer) (err error) {
+	encoder := json.NewEncoder(writer)
+
+	if err = encoder.Encode(history); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,262 @@
+package object_history
+
+import (
+	"slices"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/alfa/pool"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const (
+	FieldType        = "type"
+	FieldDescription = "description"
+	FieldTags        = "tags"
+	FieldBlob        = "blob"
+)
+
+// FuncReadMother reads the object version with the given object signature,
+// reporting whether it was found.
+type FuncReadMother func(domain_interfaces.MarklId, *sku.Transacted) bool
+
+// FuncReadBlob reads the blob of a version, reporting false for blobs that
+// are not diffed, like those of binary types.
+type FuncReadBlob func(*sku.Transacted) (text string, ok bool, err error)
+
+// FuncDiffBlobs diffs the blobs of a version and its mother, which is nil for
+// the oldest version, reporting false if it does not diff blobs of their type.
+type FuncDiffBlobs func(mother, daughter *sku.Transacted) (diff string, ok bool, err error)
+
+// FuncReadProvenance reads the provenance of the inventory list that
+// committed a version, which is empty if it is unknown.
+type FuncReadProvenance func(*sku.Transacted) (objects.Provenance, error)
+
+// Change is a metadata field that differs from the previous version.
+type Change struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Version is one version of an object and how it differs from its mother.
+type Version struct {
+	Tai       string   `json:"tai"`
+	ObjectSig string   `json:"object-sig,omitempty"`
+	MotherSig string   `json:"mother-sig,omitempty"`
+	Changes   []Change `json:"changes"`
+	BlobDiff  string   `json:"blob-diff,omitempty"`
+
+	Provenance *objects.Provenance `json:"provenance,omitempty"`
+}
+
+// History is the versions of an object, newest first. It is Truncated if the
+// mother of the oldest version could not be read.
+type History struct {
+	ObjectId  string    `json:"object-id"`
+	Versions  []Version `json:"versions"`
+	Truncated bool      `json:"truncated"`
+}
+
+// Reader follows the mother chain of an object. Blobs are only diffed if
+// DiffBlobs or ReadBlob is set, DiffBlobs taking precedence, and provenances
+// are only read if ReadProvenance is.
+type Reader struct {
+	ReadMother     FuncReadMother
+	DiffBlobs      FuncDiffBlobs
+	ReadBlob       FuncReadBlob
+	ReadProvenance FuncReadProvenance
+}
+
+func (reader Reader) Read(object *sku.Transacted) (history History, err error) {
+	history.ObjectId = object.GetObjectId().String()
+
+	chain := []*sku.Transacted{object}
+	var repools pool.Repools
+	defer repools.Repool()
+
+	seen := map[string]struct{}{}
+
+	// copied, as the signature of each mother is read into it
+	var motherSig markl.Id
+	motherSig.ResetWithMarklId(object.GetMetadata().GetMotherObjectSig())
+
+	for !motherSig.IsNull() {
+		if _, ok := seen[motherSig.String()]; ok {
+			err = errors.Errorf("mother chain has a cycle at %s", &motherSig)
+			return history, err
+		}
+
+		seen[motherSig.String()] = struct{}{}
+
+		mother, repool := sku.GetTransactedPool().GetWithRepool()
+		repools = append(repools, repool)
+
+		if !reader.ReadMother(&motherSig, mother) {
+			history.Truncated = true
+			break
+		}
+
+		chain = append(chain, mother)
+		motherSig.ResetWithMarklId(mother.GetMetadata().GetMotherObjectSig())
+	}
+
+	history.Versions = make([]Version, len(chain))
+
+	for i, version := range chain {
+		var mother *sku.Transacted
+
+		if i+1 < len(chain) {
+			mother = chain[i+1]
+		}
+
+		if history.Versions[i], err = reader.makeVersion(
+			mother,
+			version,
+		); err != nil {
+			err = errors.Wrapf(err, "version: %s", version.GetTai())
+			return history, err
+		}
+	}
+
+	return history, err
+}
+
+func (reader Reader) makeVersion(
+	mother, daughter *sku.Transacted,
+) (version Version, err error) {
+	metadata := daughter.GetMetadata()
+
+	version = Version{
+		Tai:     daughter.GetTai().String(),
+		Changes: []Change{},
+	}
+
+	if sig := metadata.GetObjectSig(); !sig.IsNull() {
+		version.ObjectSig = sig.String()
+	}
+
+	if sig := metadata.GetMotherObjectSig(); !sig.IsNull() {
+		version.MotherSig = sig.String()
+	}
+
+	if reader.ReadProvenance != nil {
+		var provenance objects.Provenance
+
+		if provenance, err = reader.ReadProvenance(daughter); err != nil {
+			err = errors.Wrap(err)
+			return version, err
+		}
+
+		if !provenance.IsEmpty() {
+			version.Provenance = &provenance
+		}
+	}
+
+	before := makeFields(mother)
+	after := makeFields(daughter)
+
+	for i, field := range []string{
+		FieldType,
+		FieldDescription,
+		FieldTags,
+		FieldBlob,
+	} {
+		if before[i] != after[i] {
+			version.Changes = append(
+				version.Changes,
+				Change{Field: field, Before: before[i], After: after[i]},
+			)
+		}
+	}
+
+	if before[3] == after[3] {
+		return version, err
+	}
+
+	if reader.DiffBlobs != nil {
+		var diff string
+		var ok bool
+
+		if diff, ok, err = reader.DiffBlobs(mother, daughter); err != nil {
+			err = errors.Wrap(err)
+			return version, err
+		} else if ok {
+			version.BlobDiff = diff
+			return version, err
+		}
+	}
+
+	if reader.ReadBlob == nil {
+		return version, err
+	}
+
+	var beforeText, afterText string
+	ok := true
+
+	if mother != nil {
+		if beforeText, ok, err = reader.ReadBlob(mother); err != nil {
+			err = errors.Wrap(err)
+			return version, err
+		}
+	}
+
+	if !ok {
+		return version, err
+	}
+
+	if afterText, ok, err = reader.ReadBlob(daughter); err != nil {
+		err = errors.Wrap(err)
+		return version, err
+	} else if !ok {
+		return version, err
+	}
+
+	version.BlobDiff = UnifiedDiff(
+		makeLabel(mother),
+		makeLabel(daughter),
+		beforeText,
+		afterText,
+	)
+
+	return version, err
+}
+
+// makeFields returns the type, description, sorted tags, and blob digest of a
+// version, all empty for the mother of the oldest one.
+func makeFields(object *sku.Transacted) (fields [4]string) {
+	if object == nil {
+		return fields
+	}
+
+	metadata := object.GetMetadata()
+
+	var tags []string
+
+	for tag := range metadata.AllTags() {
+		tags = append(tags, tag.String())
+	}
+
+	slices.Sort(tags)
+
+	fields[0] = metadata.GetType().String()
+	fields[1] = metadata.GetDescription().String()
+	fields[2] = strings.Join(tags, " ")
+
+	if digest := object.GetBlobDigest(); !digest.IsNull() {
+		fields[3] = digest.String()
+	}
+
+	return fields
+}
+
+func makeLabel(object *sku.Transacted) string {
+	if object == nil {
+		return "/dev/null"
+	}
+
+	return object.GetObjectId().String() + "@" + object.GetTai().String()
+}
@@ -0,0 +1,299 @@
+package object_history
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+)
+
+func makeObject(
+	t *testing.T,
+	description, blob string,
+	tags ...string,
+) *sku.Transacted {
+	t.Helper()
+
+	object, repool := sku.GetTransactedPool().GetWithRepool()
+	t.Cleanup(repool)
+
+	if err := object.GetObjectIdMutable().Set("one/uno"); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := object.GetMetadataMutable()
+
+	if err := metadata.GetDescriptionMutable().Set(description); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := metadata.GetTypeMutable().SetType("md"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tag := range tags {
+		if err := metadata.AddTagString(tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rawHash := sha256.Sum256([]byte(blob))
+
+	blobId, repoolBlobId := markl.FormatHashSha256.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+	t.Cleanup(repoolBlobId)
+
+	metadata.GetBlobDigestMutable().ResetWithMarklId(blobId)
+
+	return object
+}
+
+func setSig(t *testing.T, object *sku.Transacted, seed byte) {
+	t.Helper()
+
+	sig := object.GetMetadataMutable().GetObjectSigMutable()
+
+	if err := sig.SetMarklId(
+		markl.FormatIdEd25519Sig,
+		bytes.Repeat([]byte{seed}, 64),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sig.SetPurposeId(markl.PurposeObjectSigV2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+
+	expected := `--- before
++++ after
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+
+	if actual := UnifiedDiff("before", "after", before, after); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	if actual := UnifiedDiff("before", "after", before, before); actual != "" {
+		t.Errorf("expected no diff, got:\n%s", actual)
+	}
+
+	expected = `--- /dev/null
++++ after
+@@ -0,0 +1,2 @@
++one
++two
+`
+
+	if actual := UnifiedDiff("/dev/null", "after", "", "one\ntwo\n"); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestRead(t *testing.T) {
+	blobs := map[string]string{}
+
+	makeVersion := func(description, blob string, tags ...string) *sku.Transacted {
+		object := makeObject(t, description, blob, tags...)
+		blobs[object.GetBlobDigest().String()] = blob
+		return object
+	}
+
+	first := makeVersion("first", "body\n", "project")
+	setSig(t, first, 1)
+
+	second := makeVersion("second", "body\nmore\n", "project", "urgent")
+	setSig(t, second, 2)
+
+	if err := second.SetMother(first); err != nil {
+		t.Fatal(err)
+	}
+
+	latest := makeVersion("second", "body\nmore\n", "urgent")
+
+	if err := latest.SetMother(second); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := map[string]*sku.Transacted{
+		first.GetMetadata().GetObjectSig().String():  first,
+		second.GetMetadata().GetObjectSig().String(): second,
+	}
+
+	reader := Reader{
+		ReadMother: func(
+			sig domain_interfaces.MarklId,
+			object *sku.Transacted,
+		) bool {
+			version, ok := versions[sig.String()]
+
+			if ok {
+				sku.TransactedResetter.ResetWith(object, version)
+			}
+
+			return ok
+		},
+		ReadBlob: func(object *sku.Transacted) (string, bool, error) {
+			return blobs[object.GetBlobDigest().String()], true, nil
+		},
+	}
+
+	history, err := reader.Read(latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history.Versions) != 3 || history.Truncated {
+		t.Fatalf("expected 3 versions, got %#v", history)
+	}
+
+	newest := history.Versions[0]
+
+	if len(newest.Changes) != 1 ||
+		newest.Changes[0] != (Change{
+			Field:  FieldTags,
+			Before: "project urgent",
+			After:  "urgent",
+		}) {
+		t.Errorf("unexpected changes of the newest version: %v", newest.Changes)
+	}
+
+	if newest.BlobDiff != "" {
+		t.Errorf("expected no blob diff, got:\n%s", newest.BlobDiff)
+	}
+
+	if !strings.Contains(history.Versions[1].BlobDiff, "\n+more\n") {
+		t.Errorf("expected an added line, got:\n%s", history.Versions[1].BlobDiff)
+	}
+
+	oldest := history.Versions[2]
+
+	if len(oldest.Changes) != 4 || oldest.MotherSig != "" {
+		t.Errorf("expected the oldest version to add every field: %v", oldest)
+	}
+
+	if !strings.HasPrefix(oldest.BlobDiff, "--- /dev/null\n") {
+		t.Errorf("expected a diff from nothing, got:\n%s", oldest.BlobDiff)
+	}
+
+	delete(versions, first.GetMetadata().GetObjectSig().String())
+
+	if history, err = reader.Read(latest); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history.Versions) != 2 || !history.Truncated {
+		t.Errorf("expected 2 versions and a truncated history, got %#v", history)
+	}
+}
+
+func TestReadProvenance(t *testing.T) {
+	object := makeObject(t, "first", "body\n")
+	setSig(t, object, 1)
+
+	laptop := objects.Provenance{RepoId: "laptop", Hostname: "laptop.local"}
+
+	reader := Reader{
+		ReadMother: func(domain_interfaces.MarklId, *sku.Transacted) bool {
+			return false
+		},
+		ReadProvenance: func(
+			version *sku.Transacted,
+		) (objects.Provenance, error) {
+			return laptop, nil
+		},
+	}
+
+	history, err := reader.Read(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if provenance := history.Versions[0].Provenance; provenance == nil ||
+		*provenance != laptop {
+		t.Errorf("expected the provenance of the list, got %v", provenance)
+	}
+
+	var text strings.Builder
+
+	if err = history.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(text.String(), "  from: repo=laptop host=laptop.local\n") {
+		t.Errorf("expected the provenance in the text, got:\n%s", text.String())
+	}
+}
+
+func TestReadDiffBlobs(t *testing.T) {
+	first := makeObject(t, "first", "page one")
+	setSig(t, first, 1)
+
+	latest := makeObject(t, "first", "page two")
+
+	if err := latest.SetMother(first); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := Reader{
+		ReadMother: func(
+			sig domain_interfaces.MarklId,
+			object *sku.Transacted,
+		) bool {
+			sku.TransactedResetter.ResetWith(object, first)
+			return true
+		},
+		DiffBlobs: func(mother, daughter *sku.Transacted) (string, bool, error) {
+			if mother == nil {
+				return "", false, nil
+			}
+
+			return "pages differ\n", true, nil
+		},
+		ReadBlob: func(object *sku.Transacted) (string, bool, error) {
+			return "text", true, nil
+		},
+	}
+
+	history, err := reader.Read(latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history.Versions) != 2 {
+		t.Fatalf("expected 2 versions, got %#v", history)
+	}
+
+	if actual := history.Versions[0].BlobDiff; actual != "pages differ\n" {
+		t.Errorf("expected the external diff, got:\n%s", actual)
+	}
+
+	if actual := history.Versions[1].BlobDiff; !strings.HasPrefix(
+		actual,
+		"--- /dev/null\n",
+	) {
+		t.Errorf("expected a text diff for the oldest version, got:\n%s", actual)
+	}
+}
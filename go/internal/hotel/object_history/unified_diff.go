@@ -0,0 +1,217 @@
+package object_history
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	diffContext = 3
+
+	// diffMaxCells bounds the lines compared after trimming the common prefix
+	// and suffix, past which the rest is shown as removed and added whole.
+	diffMaxCells = 4_000_000
+)
+
+type diffOp struct {
+	kind byte // ' ', '-', or '+'
+	line string
+}
+
+// UnifiedDiff returns the unified diff, with three lines of context, of two
+// texts, or "" if their lines are the same.
+func UnifiedDiff(labelBefore, labelAfter, before, after string) string {
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	var builder strings.Builder
+
+	for start := 0; start < len(ops); {
+		first := nextChange(ops, start)
+
+		if first == len(ops) {
+			break
+		}
+
+		hunkStart := max(first-diffContext, start)
+		hunkEnd := first + 1
+
+		for {
+			next := nextChange(ops, hunkEnd)
+
+			if next == len(ops) || next-hunkEnd > 2*diffContext {
+				break
+			}
+
+			hunkEnd = next + 1
+		}
+
+		hunkEnd = min(hunkEnd+diffContext, len(ops))
+
+		if builder.Len() == 0 {
+			fmt.Fprintf(&builder, "--- %s\n+++ %s\n", labelBefore, labelAfter)
+		}
+
+		writeHunk(&builder, ops, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+
+	return builder.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+func nextChange(ops []diffOp, start int) int {
+	for start < len(ops) && ops[start].kind == ' ' {
+		start++
+	}
+
+	return start
+}
+
+func writeHunk(builder *strings.Builder, ops []diffOp, start, end int) {
+	// line numbers are those of the first line of the hunk in each text
+	beforeLine, afterLine := 1, 1
+
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			beforeLine++
+		}
+
+		if op.kind != '-' {
+			afterLine++
+		}
+	}
+
+	var beforeCount, afterCount int
+
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			beforeCount++
+		}
+
+		if op.kind != '-' {
+			afterCount++
+		}
+	}
+
+	// empty ranges are numbered by the line before them
+	if beforeCount == 0 {
+		beforeLine--
+	}
+
+	if afterCount == 0 {
+		afterLine--
+	}
+
+	fmt.Fprintf(
+		builder,
+		"@@ -%d,%d +%d,%d @@\n",
+		beforeLine,
+		beforeCount,
+		afterLine,
+		afterCount,
+	)
+
+	for _, op := range ops[start:end] {
+		builder.WriteByte(op.kind)
+		builder.WriteString(op.line)
+		builder.WriteByte('\n')
+	}
+}
+
+// diffLines finds the longest common subsequence of the lines that are not a
+// common prefix or suffix.
+func diffLines(before, after []string) (ops []diffOp) {
+	prefix := 0
+
+	for prefix < len(before) && prefix < len(after) &&
+		before[prefix] == after[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range before[:prefix] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+
+	middleBefore := before[prefix : len(before)-suffix]
+	middleAfter := after[prefix : len(after)-suffix]
+
+	if len(middleBefore)*len(middleAfter) > diffMaxCells {
+		for _, line := range middleBefore {
+			ops = append(ops, diffOp{kind: '-', line: line})
+		}
+
+		for _, line := range middleAfter {
+			ops = append(ops, diffOp{kind: '+', line: line})
+		}
+	} else {
+		ops = append(ops, diffLCS(middleBefore, middleAfter)...)
+	}
+
+	for _, line := range before[len(before)-suffix:] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+
+	return ops
+}
+
+func diffLCS(before, after []string) (ops []diffOp) {
+	width := len(after) + 1
+	// lengths[i*width+j] is the length of the LCS of before[i:] and after[j:]
+	lengths := make([]int, (len(before)+1)*width)
+
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lengths[i*width+j] = lengths[(i+1)*width+j+1] + 1
+			} else {
+				lengths[i*width+j] = max(
+					lengths[(i+1)*width+j],
+					lengths[i*width+j+1],
+				)
+			}
+		}
+	}
+
+	i, j := 0, 0
+
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			ops = append(ops, diffOp{kind: ' ', line: before[i]})
+			i++
+			j++
+
+		case lengths[(i+1)*width+j] >= lengths[i*width+j+1]:
+			ops = append(ops, diffOp{kind: '-', line: before[i]})
+			i++
+
+		default:
+			ops = append(ops, diffOp{kind: '+', line: after[j]})
+			j++
+		}
+	}
+
+	for ; i < len(before); i++ {
+		ops = append(ops, diffOp{kind: '-', line: before[i]})
+	}
+
+	for ; j < len(after); j++ {
+		ops = append(ops, diffOp{kind: '+', line: after[j]})
+	}
+
+	return ops
+}
@@ -1,7 +1,9 @@
 package object_probe_index
 
 import (
+	"bytes"
 	"io"
+	"slices"
 
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
@@ -163,6 +165,69 @@ func (index *Index) ReadMany(
 	return err
 }
 
+// ReadOneBatch is ReadOne for many ids at once. The ids are grouped by page and
+// sorted, so that every page is locked once and searched in a single forward
+// pass. locs and found line up with ids, and null ids are not found.
+func (index *Index) ReadOneBatch(
+	originalIds []domain_interfaces.MarklId,
+) (locs []Loc, found []bool, err error) {
+	locs = make([]Loc, len(originalIds))
+	found = make([]bool, len(originalIds))
+
+	var pageProbes [PageCount][]batchProbe
+
+	for position, id := range originalIds {
+		if id.IsNull() {
+			continue
+		}
+
+		if id.GetMarklFormat().GetMarklFormatId() != index.hashType.GetMarklFormatId() {
+			replacementId, repool := index.hashType.GetMarklIdForMarklId(id)
+			defer repool()
+
+			id = replacementId
+		}
+
+		var pageIndex uint8
+
+		if pageIndex, err = page_id.PageIndexForDigest(
+			DigitWidth,
+			id,
+		); err != nil {
+			err = errors.Wrap(err)
+			return locs, found, err
+		}
+
+		pageProbes[pageIndex] = append(
+			pageProbes[pageIndex],
+			batchProbe{position: position, id: id},
+		)
+	}
+
+	waitGroup := errors.MakeWaitGroupParallel()
+
+	for pageIndex, probes := range pageProbes {
+		if len(probes) == 0 {
+			continue
+		}
+
+		waitGroup.Do(func() error {
+			slices.SortFunc(probes, func(left, right batchProbe) int {
+				return bytes.Compare(left.id.GetBytes(), right.id.GetBytes())
+			})
+
+			return index.pages[pageIndex].readOneSorted(probes, locs, found)
+		})
+	}
+
+	if err = waitGroup.GetError(); err != nil {
+		err = errors.Wrap(err)
+		return locs, found, err
+	}
+
+	return locs, found, err
+}
+
 func (index *Index) PrintAll(env env_ui.Env) (err error) {
 	for pageIndex := range index.pages {
 		page := &index.pages[pageIndex]
@@ -176,6 +241,24 @@ func (index *Index) PrintAll(env env_ui.Env) (err error) {
 	return err
 }
 
+// Warm reads every page through, so that a later process searching the index
+// finds it in the page cache, and checks that each page holds whole rows in
+// order. It returns how many rows it read.
+func (index *Index) Warm() (rows int64, err error) {
+	for pageIndex := range index.pages {
+		var pageRows int64
+
+		if pageRows, err = index.pages[pageIndex].warm(); err != nil {
+			err = errors.Wrap(err)
+			return rows, err
+		}
+
+		rows += pageRows
+	}
+
+	return rows, err
+}
+
 func (index *Index) Reset() (err error) {
 	for pageIndex := range index.pages {
 		page := &index.pages[pageIndex]
@@ -190,6 +273,7 @@ func (index *Index) Reset() (err error) {
 		}
 
 		page.added.Reset()
+		page.wasReset = true
 	}
 
 	return err
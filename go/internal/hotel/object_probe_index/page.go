@@ -28,6 +28,10 @@ type page struct {
 	envRepo        env_repo.Env
 	searchFunc     func(domain_interfaces.MarklId) (mid int64, err error)
 	id             page_id.PageId
+
+	// set by Reset, so that a page nothing was added back to is removed
+	// rather than keeping its rows from before
+	wasReset bool
 }
 
 func (page *page) initialize(
@@ -185,6 +189,70 @@ func (page *page) ReadMany(sh domain_interfaces.MarklId, locs *[]Loc) (err error
 	return err
 }
 
+// batchProbe is an id looked up by ReadOneBatch, along with its position in
+// the ids passed to it.
+type batchProbe struct {
+	position int
+	id       domain_interfaces.MarklId
+}
+
+// readOneSorted is ReadOne for probes sorted by id, which searches for each
+// one only in the rows after the previous one's. Results are written to the
+// probe's position in locs and found.
+func (page *page) readOneSorted(
+	probes []batchProbe,
+	locs []Loc,
+	found []bool,
+) (err error) {
+	page.Lock()
+	defer page.Unlock()
+
+	if page.file == nil {
+		return err
+	}
+
+	var lastRow int64
+
+	if lastRow, err = page.GetRowCount(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	rowCount := lastRow + 1
+
+	var low int64
+
+	for _, probe := range probes {
+		if low = page.searchLowerBound(probe.id, low, rowCount); low == rowCount {
+			// every remaining probe sorts after the last row
+			break
+		}
+
+		if markl.CompareToReaderAt(
+			page.file,
+			low*int64(page.rowWidth),
+			probe.id,
+		) != 0 {
+			continue
+		}
+
+		if err = page.seekAndResetTo(low); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if locs[probe.position], found[probe.position], err = page.readCurrentLoc(
+			probe.id,
+			&page.bufferedReader,
+		); err != nil {
+			err = errors.Wrapf(err, "Start: %d", low)
+			return err
+		}
+	}
+
+	return err
+}
+
 func (page *page) readCurrentLoc(
 	expectedBlobId domain_interfaces.MarklId,
 	bufferedReader *bufio.Reader,
@@ -259,11 +327,84 @@ func (page *page) PrintAll(env env_ui.Env) (err error) {
 	}
 }
 
+// warm reads the page's rows through, checking that the file holds whole
+// rows in digest order, and returns how many it read.
+func (page *page) warm() (rows int64, err error) {
+	page.Lock()
+	defer page.Unlock()
+
+	if page.file == nil {
+		return rows, err
+	}
+
+	var fileInfo os.FileInfo
+
+	if fileInfo, err = page.file.Stat(); err != nil {
+		err = errors.Wrap(err)
+		return rows, err
+	}
+
+	if fileInfo.Size()%int64(page.rowWidth) != 0 {
+		err = errors.Errorf(
+			"page %s is %d bytes, not a multiple of its %d-byte rows",
+			page.id.Path(),
+			fileInfo.Size(),
+			page.rowWidth,
+		)
+
+		return rows, err
+	}
+
+	if err = page.seekAndResetTo(0); err != nil {
+		err = errors.WrapExceptSentinelAsNil(err, io.EOF)
+		return rows, err
+	}
+
+	var previous, current row
+
+	for {
+		if _, err = page.readRowFrom(
+			&current,
+			&page.bufferedReader,
+		); err != nil {
+			err = errors.WrapExceptSentinelAsNil(err, io.EOF)
+			return rows, err
+		}
+
+		if rows > 0 && (rowLessor{}).Less(&current, &previous) {
+			err = errors.Errorf(
+				"page %s is out of order at row %d",
+				page.id.Path(),
+				rows,
+			)
+
+			return rows, err
+		}
+
+		previous.Digest.ResetWith(current.Digest)
+		previous.Loc = current.Loc
+		rows++
+	}
+}
+
 func (page *page) Flush() (err error) {
 	page.Lock()
 	defer page.Unlock()
 
 	if page.added.Len() == 0 {
+		if page.wasReset {
+			if err = os.Remove(page.id.Path()); err != nil {
+				if errors.IsNotExist(err) {
+					err = nil
+				} else {
+					err = errors.Wrap(err)
+					return err
+				}
+			}
+
+			page.wasReset = false
+		}
+
 		return err
 	}
 
@@ -316,6 +457,7 @@ func (page *page) Flush() (err error) {
 		}
 
 		page.added.Reset()
+		page.wasReset = false
 	}
 
 	// re-open the page for future reads
@@ -78,6 +78,29 @@ func (page *page) seekToFirstBinarySearch(
 	return mid, err
 }
 
+// searchLowerBound returns the first row in [low, high) that does not sort
+// before expected, or high if there is none. The page must have a file.
+func (page *page) searchLowerBound(
+	expected domain_interfaces.MarklId,
+	low, high int64,
+) int64 {
+	for low < high {
+		mid := low + (high-low)/2
+
+		if markl.CompareToReaderAt(
+			page.file,
+			mid*int64(page.rowWidth),
+			expected,
+		) > 0 {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+
+	return low
+}
+
 func (page *page) seekToFirstLinearSearch(
 	expected domain_interfaces.MarklId,
 ) (loc int64, err error) {
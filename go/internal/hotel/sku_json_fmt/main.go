@@ -19,20 +19,21 @@ import (
 )
 
 type Transacted struct {
-	BlobId          string   `json:"blob-id"`
-	BlobString      string   `json:"blob-string,omitempty"`
-	Date            string   `json:"date"`
-	Description     string   `json:"description"`
-	Lock            Lock     `json:"lock"`
-	MotherObjectSig markl.Id `json:"mother-object-sig"`
-	ObjectDigest    markl.Id `json:"object-digest"`
-	ObjectId        string   `json:"object-id"`
-	RepoPubkey      markl.Id `json:"repo-pub_key"`
-	RepoSig         markl.Id `json:"repo-sig"`
-	Sha             string   `json:"sha"`
-	Tags            []string `json:"tags"`
-	Tai             string   `json:"tai"`
-	Type            string   `json:"type"`
+	BlobId          string              `json:"blob-id"`
+	BlobString      string              `json:"blob-string,omitempty"`
+	Date            string              `json:"date"`
+	Description     string              `json:"description"`
+	Lock            Lock                `json:"lock"`
+	MotherObjectSig markl.Id            `json:"mother-object-sig"`
+	ObjectDigest    markl.Id            `json:"object-digest"`
+	ObjectId        string              `json:"object-id"`
+	Provenance      *objects.Provenance `json:"provenance,omitempty"`
+	RepoPubkey      markl.Id            `json:"repo-pub_key"`
+	RepoSig         markl.Id            `json:"repo-sig"`
+	Sha             string              `json:"sha"`
+	Tags            []string            `json:"tags"`
+	Tai             string              `json:"tai"`
+	Type            string              `json:"type"`
 }
 
 // TODO make a json factory
@@ -70,6 +71,12 @@ func (json *Transacted) FromObjectIdStringAndMetadata(
 	json.MotherObjectSig.ResetWithMarklId(metadata.GetMotherObjectSig())
 	json.ObjectDigest.ResetWithMarklId(metadata.GetObjectDigest())
 	json.ObjectId = objectId
+	json.Provenance = nil
+
+	if provenance := metadata.GetProvenance(); !provenance.IsEmpty() {
+		json.Provenance = &provenance
+	}
+
 	json.RepoPubkey.ResetWithMarklId(metadata.GetRepoPubKey())
 	json.RepoSig.ResetWithMarklId(metadata.GetObjectSig())
 	json.Tags = slices.Collect(quiter.Strings(quiter_seq.Seq[interfaces.Collection[ids.TagStruct]](metadata.GetTags())))
@@ -174,6 +181,10 @@ func (json *Transacted) ToTransacted(
 		metadata.GetObjectDigestMutable().ResetWithMarklId(json.ObjectDigest)
 	}
 
+	if json.Provenance != nil {
+		*metadata.GetProvenanceMutable() = *json.Provenance
+	}
+
 	if !json.RepoPubkey.IsNull() {
 		metadata.GetRepoPubKeyMutable().ResetWithMarklId(json.RepoPubkey)
 	}
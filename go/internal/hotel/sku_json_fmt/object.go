@@ -0,0 +1,105 @@
+package sku_json_fmt
+
+import (
+	"io"
+	"slices"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/quiter_seq"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/quiter"
+)
+
+// Object is the schema of the `json` and `ndjson` show formats. Unlike
+// Transacted it is meant for external tooling: fields are only ever added,
+// never renamed or removed, and Tags is always an array, empty rather than
+// null.
+type Object struct {
+	ObjectId    string   `json:"object-id"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	Description string   `json:"description"`
+	Tai         string   `json:"tai"`
+	BlobId      string   `json:"blob-id"`
+
+	// Repo is the id of the repo the object came from, only present for
+	// objects of federated queries, whose ObjectId is then qualified with it.
+	Repo string `json:"repo,omitempty"`
+
+	// Provenance is where an inventory list was committed, only present for
+	// inventory lists that record it.
+	Provenance *objects.Provenance `json:"provenance,omitempty"`
+
+	// Blob is the blob's content as text, only present when it was inlined.
+	Blob *string `json:"blob,omitempty"`
+}
+
+// FromTransacted fills in the object's fields, inlining its blob when
+// blobStore is not nil.
+func (json *Object) FromTransacted(
+	object *sku.Transacted,
+	blobStore domain_interfaces.BlobStore,
+) (err error) {
+	metadata := object.GetMetadata()
+
+	json.ObjectId = object.GetObjectId().String()
+	json.Repo = object.GetRepoId().String()
+
+	if json.Repo != "" {
+		json.ObjectId = object.GetObjectIdStringQualified()
+	}
+
+	json.Type = metadata.GetType().String()
+	json.Tags = slices.Collect(
+		quiter.Strings(
+			quiter_seq.Seq[interfaces.Collection[ids.TagStruct]](
+				metadata.GetTags(),
+			),
+		),
+	)
+	json.Description = metadata.GetDescription().String()
+	json.Tai = metadata.GetTai().String()
+	json.BlobId = metadata.GetBlobDigest().String()
+	json.Blob = nil
+	json.Provenance = nil
+
+	if provenance := metadata.GetProvenance(); !provenance.IsEmpty() {
+		json.Provenance = &provenance
+	}
+
+	if json.Tags == nil {
+		json.Tags = []string{}
+	}
+
+	if blobStore == nil {
+		return err
+	}
+
+	var readCloser domain_interfaces.BlobReader
+
+	if readCloser, err = blobStore.MakeBlobReader(
+		metadata.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, readCloser)
+
+	var blobStringBuilder strings.Builder
+
+	if _, err = io.Copy(&blobStringBuilder, readCloser); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	blob := blobStringBuilder.String()
+	json.Blob = &blob
+
+	return err
+}
@@ -0,0 +1,138 @@
+package sku_lua
+
+import (
+	"bytes"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/lib/alfa/pool"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
+)
+
+const (
+	// ObjectMetatable is the type metatable of object tables, which gives
+	// them the `blob` method.
+	ObjectMetatable = "dodder.object"
+
+	// BlobSizeCap is the largest blob `blob` reads into a string.
+	BlobSizeCap = 1 << 20
+
+	// blobCacheEntries is how many blobs a VM keeps read, so that calling
+	// `blob` on an object again, or on another version with the same blob,
+	// does not read it again.
+	blobCacheEntries = 16
+)
+
+type FuncMakeBlobReader func(
+	domain_interfaces.MarklId,
+) (domain_interfaces.BlobReader, error)
+
+var blobBuffers = pool.Make[bytes.Buffer](
+	nil,
+	func(buffer *bytes.Buffer) {
+		buffer.Reset()
+	},
+)
+
+// RegisterObjectMetatable installs the object metatable in a VM, so that
+// tables from the V1 and V2 table pools have `object:blob()`. It returns the
+// blob of the object as a string, or nil and a message if it is larger than
+// BlobSizeCap or cannot be read. Blobs are read on the first call only.
+func RegisterObjectMetatable(
+	vm *lua.VM,
+	makeBlobReader FuncMakeBlobReader,
+) (err error) {
+	cache := make(map[string]lua.LString, blobCacheEntries)
+
+	methods := map[string]lua.LGFunction{
+		"blob": func(state *lua.LState) int {
+			table := state.CheckTable(1)
+			digestString := state.GetField(table, "BlobDigest").String()
+
+			if digestString == "" {
+				state.Push(lua.LString(""))
+				return 1
+			}
+
+			if blob, ok := cache[digestString]; ok {
+				state.Push(blob)
+				return 1
+			}
+
+			blob, err := readBlob(digestString, makeBlobReader)
+			if err != nil {
+				state.Push(lua.LNil)
+				state.Push(lua.LString(err.Error()))
+				return 2
+			}
+
+			if len(cache) >= blobCacheEntries {
+				clear(cache)
+			}
+
+			cache[digestString] = blob
+			state.Push(blob)
+
+			return 1
+		},
+	}
+
+	metatable := vm.NewTypeMetatable(ObjectMetatable)
+	vm.SetField(metatable, "__index", vm.SetFuncs(vm.NewTable(), methods))
+
+	return err
+}
+
+func readBlob(
+	digestString string,
+	makeBlobReader FuncMakeBlobReader,
+) (blob lua.LString, err error) {
+	var digest markl.Id
+
+	if err = digest.Set(digestString); err != nil {
+		err = errors.Wrap(err)
+		return blob, err
+	}
+
+	var reader domain_interfaces.BlobReader
+
+	if reader, err = makeBlobReader(digest); err != nil {
+		err = errors.Wrap(err)
+		return blob, err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	buffer, repool := blobBuffers.GetWithRepool()
+	defer repool()
+
+	if _, err = io.Copy(
+		buffer,
+		io.LimitReader(reader, BlobSizeCap+1),
+	); err != nil {
+		err = errors.Wrap(err)
+		return blob, err
+	}
+
+	if buffer.Len() > BlobSizeCap {
+		err = errors.Errorf(
+			"blob %s is larger than %d bytes",
+			digestString,
+			BlobSizeCap,
+		)
+		return blob, err
+	}
+
+	blob = lua.LString(buffer.String())
+
+	return blob, err
+}
+
+// setObjectMetatable gives a table the object metatable, if the VM has one.
+func setObjectMetatable(vm *lua.VM, table *lua.LTable) {
+	if metatable, ok := vm.GetTypeMetatable(ObjectMetatable).(*lua.LTable); ok {
+		vm.SetMetatable(table, metatable)
+	}
+}
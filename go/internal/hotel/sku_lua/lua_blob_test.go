@@ -0,0 +1,157 @@
+package sku_lua
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
+)
+
+type blobReader struct {
+	*bytes.Reader
+	digest domain_interfaces.MarklId
+}
+
+func (reader blobReader) Close() error {
+	return nil
+}
+
+func (reader blobReader) GetMarklId() domain_interfaces.MarklId {
+	return reader.digest
+}
+
+func makeBlobDigest(t *testing.T, content string) string {
+	t.Helper()
+
+	rawHash := sha256.Sum256([]byte(content))
+
+	id, repool := markl.FormatHashSha256.GetBlobIdForHexString(
+		hex.EncodeToString(rawHash[:]),
+	)
+
+	t.Cleanup(repool)
+
+	return id.String()
+}
+
+func makeBlobVM(
+	t *testing.T,
+	blob string,
+	reads *int,
+) *lua.VM {
+	t.Helper()
+
+	vmPool, err := (&lua.VMPoolBuilder{}).
+		WithScript(`return {
+			read = function(object)
+				local first, err = object:blob()
+				local second = object:blob()
+				return first, err, second
+			end,
+		}`).
+		WithPrepare(func(vm *lua.VM) error {
+			return RegisterObjectMetatable(
+				vm,
+				func(
+					digest domain_interfaces.MarklId,
+				) (domain_interfaces.BlobReader, error) {
+					*reads++
+					return blobReader{
+						Reader: bytes.NewReader([]byte(blob)),
+						digest: digest,
+					}, nil
+				},
+			)
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build vm pool: %s", err)
+	}
+
+	vm, repool := vmPool.GetWithRepool()
+	t.Cleanup(repool)
+
+	return vm
+}
+
+func callRead(t *testing.T, vm *lua.VM, digest string) []lua.LValue {
+	t.Helper()
+
+	table, _ := MakeLuaTablePoolV2(vm).GetWithRepool()
+	vm.SetField(table.Transacted, "BlobDigest", lua.LString(digest))
+
+	top, err := vm.GetTopTableOrError()
+	if err != nil {
+		t.Fatalf("expected script table: %s", err)
+	}
+
+	vm.Push(vm.GetField(top, "read"))
+	vm.Push(table.Transacted)
+
+	if err := vm.PCall(1, 3, nil); err != nil {
+		t.Fatalf("failed to call read: %s", err)
+	}
+
+	values := []lua.LValue{vm.Get(-3), vm.Get(-2), vm.Get(-1)}
+	vm.Pop(3)
+
+	return values
+}
+
+func TestObjectBlobIsReadOnce(t *testing.T) {
+	const blob = "body of the zettel\n"
+
+	var reads int
+	vm := makeBlobVM(t, blob, &reads)
+
+	values := callRead(t, vm, makeBlobDigest(t, blob))
+
+	if values[0].String() != blob || values[2].String() != blob {
+		t.Errorf("expected %q twice, got %q and %q", blob, values[0], values[2])
+	}
+
+	if values[1] != lua.LNil {
+		t.Errorf("expected no error, got %q", values[1])
+	}
+
+	if reads != 1 {
+		t.Errorf("expected one read, got %d", reads)
+	}
+}
+
+func TestObjectBlobEmptyDigest(t *testing.T) {
+	var reads int
+	vm := makeBlobVM(t, "unused", &reads)
+
+	values := callRead(t, vm, "")
+
+	if values[0].String() != "" {
+		t.Errorf("expected empty blob, got %q", values[0])
+	}
+
+	if reads != 0 {
+		t.Errorf("expected no reads, got %d", reads)
+	}
+}
+
+func TestObjectBlobOverSizeCap(t *testing.T) {
+	blob := strings.Repeat("x", BlobSizeCap+1)
+
+	var reads int
+	vm := makeBlobVM(t, blob, &reads)
+
+	values := callRead(t, vm, makeBlobDigest(t, blob))
+
+	if values[0] != lua.LNil {
+		t.Errorf("expected nil blob, got %d bytes", len(values[0].String()))
+	}
+
+	if !strings.Contains(values[1].String(), "larger than") {
+		t.Errorf("expected size cap error, got %q", values[1])
+	}
+}
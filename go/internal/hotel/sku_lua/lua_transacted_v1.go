@@ -36,6 +36,11 @@ func ToLuaTableV1(
 		"Typ",
 		lua.LString(object.GetType().String()),
 	)
+	luaState.SetField(
+		luaTable.Transacted,
+		"BlobDigest",
+		lua.LString(object.GetBlobDigest().String()),
+	)
 
 	tags := luaTable.Tags
 
@@ -66,6 +66,8 @@ func MakeLuaTablePoolV1(vm *lua.VM) LuaTablePoolV1 {
 			tags, _ := vm.PoolPtr.GetWithRepool()
 			tagsImplicit, _ := vm.PoolPtr.GetWithRepool()
 
+			setObjectMetatable(vm, transacted)
+
 			table = &LuaTableV1{
 				Transacted:   transacted,
 				Tags:         tags,
@@ -39,6 +39,21 @@ func ToLuaTableV2(
 		"Type",
 		lua.LString(object.GetType().String()),
 	)
+	luaState.SetField(
+		luaTable.Transacted,
+		"Time",
+		lua.LNumber(object.GetTai().AsTime().Unix()),
+	)
+	luaState.SetField(
+		luaTable.Transacted,
+		"BlobDigest",
+		lua.LString(object.GetBlobDigest().String()),
+	)
+	luaState.SetField(
+		luaTable.Transacted,
+		"Description",
+		lua.LString(object.GetMetadata().GetDescription().String()),
+	)
 
 	tags := luaTable.Tags
 
@@ -72,6 +87,15 @@ func FromLuaTableV2(
 		}
 	}
 
+	if description, ok := luaState.GetField(t, "Description").(lua.LString); ok {
+		if err = object.GetMetadataMutable().GetDescriptionMutable().Set(
+			string(description),
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	tags := luaState.GetField(t, "Tags")
 	tagsTable, ok := tags.(*lua.LTable)
 
@@ -95,7 +119,6 @@ func FromLuaTableV2(
 		},
 	)
 
-	// TODO Description
 	// TODO Type
 	// TODO Tai
 	// TODO Blob
@@ -66,6 +66,8 @@ func MakeLuaTablePoolV2(vm *lua.VM) LuaTablePoolV2 {
 			tags, _ := vm.PoolPtr.GetWithRepool()
 			tagsImplicit, _ := vm.PoolPtr.GetWithRepool()
 
+			setObjectMetatable(vm, transacted)
+
 			t = &LuaTableV2{
 				Transacted:   transacted,
 				Tags:         tags,
@@ -1,6 +1,8 @@
 package type_blobs
 
 import (
+	"io"
+
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
@@ -70,6 +72,41 @@ func (store Coder) SaveBlobText(
 	return digest, n, err
 }
 
+// ValidateBlob checks a blob against the schema of its type, returning an
+// ErrBlobSchema with every field that does not match it.
+func (store Coder) ValidateBlob(
+	tipe domain_interfaces.ObjectId,
+	schema Schema,
+	blobId domain_interfaces.MarklId,
+) (err error) {
+	if len(schema) == 0 || blobId.IsNull() {
+		return err
+	}
+
+	var reader domain_interfaces.BlobReader
+
+	if reader, err = store.envRepo.GetDefaultBlobStore().MakeBlobReader(blobId); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	var blob []byte
+
+	if blob, err = io.ReadAll(reader); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = schema.Validate(tipe.String(), blob); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
 func (store Coder) ParseTypedBlob(
 	tipe domain_interfaces.ObjectId,
 	blobId domain_interfaces.MarklId,
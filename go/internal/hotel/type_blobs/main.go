@@ -24,8 +24,12 @@ type Blob interface {
 }
 
 var (
-	_ Blob = &TomlV0{}
-	_ Blob = &TomlV1{}
+	_ Blob                   = &TomlV0{}
+	_ Blob                   = &TomlV1{}
+	_ WithStringLuaMigration = &TomlV1{}
+	_ WithSchema             = &TomlV1{}
+	_ WithTemplate           = &TomlV1{}
+	_ WithExternalTools      = &TomlV1{}
 )
 
 type WithFormatters interface {
@@ -40,3 +44,23 @@ type WithFormatterUTIGroups interface {
 type WithStringLuaHooks interface {
 	GetStringLuaHooks() string
 }
+
+// WithStringLuaMigration is implemented by type blobs that can declare a
+// migration script.
+type WithStringLuaMigration interface {
+	GetStringLuaMigration() string
+}
+
+// WithTemplate is implemented by type blobs that can declare a template for
+// new objects of their type.
+type WithTemplate interface {
+	GetTemplate() string
+	GetTemplateFields() map[string]string
+}
+
+// WithExternalTools is implemented by type blobs that can declare the
+// commands that diff and merge the blobs of their type.
+type WithExternalTools interface {
+	GetDiffTool() *script_config.ExternalTool
+	GetMergeTool() *script_config.ExternalTool
+}
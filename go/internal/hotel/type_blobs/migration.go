@@ -0,0 +1,80 @@
+package type_blobs
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// BuiltinMigration re-encodes the blobs of a deprecated builtin type as the
+// builtin type that replaced it.
+type BuiltinMigration struct {
+	From, To string
+
+	migrate func(Blob) Blob
+}
+
+var builtinMigrations = map[string]BuiltinMigration{
+	ids.TypeTomlTypeV0: {
+		From:    ids.TypeTomlTypeV0,
+		To:      ids.TypeTomlTypeV1,
+		migrate: migrateTomlV0ToV1,
+	},
+}
+
+func GetBuiltinMigration(tipe string) (migration BuiltinMigration, ok bool) {
+	migration, ok = builtinMigrations[tipe]
+	return migration, ok
+}
+
+// MigrateBlob decodes a blob of the migration's From type and encodes it as
+// its To type. The blob is only written if save is true.
+func (store Coder) MigrateBlob(
+	migration BuiltinMigration,
+	blobId domain_interfaces.MarklId,
+	save bool,
+) (digest domain_interfaces.MarklId, err error) {
+	var blob Blob
+	var repool interfaces.FuncRepool
+
+	if blob, repool, _, err = store.ParseTypedBlob(
+		ids.MustTypeStruct(migration.From),
+		blobId,
+	); err != nil {
+		err = errors.Wrap(err)
+		return digest, err
+	}
+
+	defer repool()
+
+	migrated := migration.migrate(blob)
+
+	if !save {
+		return digest, err
+	}
+
+	if digest, _, err = store.SaveBlobText(
+		ids.MustTypeStruct(migration.To),
+		migrated,
+	); err != nil {
+		err = errors.Wrap(err)
+		return digest, err
+	}
+
+	return digest, err
+}
+
+func migrateTomlV0ToV1(blob Blob) Blob {
+	v0 := blob.(*TomlV0)
+
+	return &TomlV1{
+		Binary:        !v0.InlineBlob,
+		FileExtension: v0.FileExtension,
+		ExecCommand:   v0.ExecCommand,
+		VimSyntaxType: v0.VimSyntaxType,
+		UTIGroups:     v0.FormatterUTIGroups,
+		Formatters:    v0.Formatters,
+		Hooks:         v0.Hooks,
+	}
+}
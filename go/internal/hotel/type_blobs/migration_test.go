@@ -0,0 +1,55 @@
+package type_blobs
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+)
+
+func TestBuiltinMigrationTomlTypeV0(t *testing.T) {
+	migration, ok := GetBuiltinMigration(ids.TypeTomlTypeV0)
+
+	if !ok {
+		t.Fatalf("expected a migration for %s", ids.TypeTomlTypeV0)
+	}
+
+	if migration.To != ids.TypeTomlTypeV1 {
+		t.Errorf("expected migration to %s, got %s", ids.TypeTomlTypeV1, migration.To)
+	}
+
+	migrated := migration.migrate(
+		&TomlV0{
+			InlineBlob:         true,
+			FileExtension:      "md",
+			VimSyntaxType:      "markdown",
+			FormatterUTIGroups: map[string]UTIGroup{"public.text": {}},
+			Hooks:              "return {}",
+		},
+	)
+
+	v1, ok := migrated.(*TomlV1)
+
+	if !ok {
+		t.Fatalf("expected *TomlV1, got %T", migrated)
+	}
+
+	if v1.Binary {
+		t.Errorf("expected inline v0 blob to migrate as not binary")
+	}
+
+	if v1.FileExtension != "md" || v1.VimSyntaxType != "markdown" {
+		t.Errorf("unexpected migrated blob: %#v", v1)
+	}
+
+	if _, ok := v1.UTIGroups["public.text"]; !ok {
+		t.Errorf("expected uti groups to carry over: %#v", v1.UTIGroups)
+	}
+
+	if v1.GetStringLuaHooks() != "return {}" {
+		t.Errorf("expected hooks to carry over, got %q", v1.GetStringLuaHooks())
+	}
+
+	if _, ok := GetBuiltinMigration(ids.TypeTomlTypeV1); ok {
+		t.Errorf("expected no migration for the current type")
+	}
+}
@@ -0,0 +1,281 @@
+package type_blobs
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/toml"
+)
+
+const (
+	FieldTypeString   = "string"
+	FieldTypeInteger  = "integer"
+	FieldTypeFloat    = "float"
+	FieldTypeBoolean  = "boolean"
+	FieldTypeDatetime = "datetime"
+	FieldTypeArray    = "array"
+	FieldTypeTable    = "table"
+)
+
+// Schema declares the fields of the TOML blobs of a type, keyed by their
+// dotted path, like `meta.title` for `title` in the `[meta]` table.
+type Schema map[string]FieldSchema
+
+// FieldSchema constrains one field. Min and Max bound numbers, and the
+// length of strings and arrays.
+type FieldSchema struct {
+	Required bool     `toml:"required,omitempty"`
+	Type     string   `toml:"type,omitempty"`
+	Enum     []string `toml:"enum,omitempty"`
+	Pattern  string   `toml:"pattern,omitempty"`
+	Min      *float64 `toml:"min,omitempty"`
+	Max      *float64 `toml:"max,omitempty"`
+}
+
+// WithSchema is implemented by type blobs that can declare a schema for the
+// blobs of their type.
+type WithSchema interface {
+	GetSchema() Schema
+}
+
+// ErrBlobField is a field of a blob that does not match its type's schema.
+type ErrBlobField struct {
+	Field   string
+	Message string
+}
+
+func (err ErrBlobField) Error() string {
+	if err.Field == "" {
+		return err.Message
+	}
+
+	return fmt.Sprintf("field %q: %s", err.Field, err.Message)
+}
+
+func (err ErrBlobField) GetErrorCode() errors.Code {
+	return errors.CodeInvalidBlob
+}
+
+// ErrBlobSchema lists every field of a blob that does not match the schema
+// of its type.
+type ErrBlobSchema struct {
+	Type   string
+	Fields []ErrBlobField
+}
+
+func (err ErrBlobSchema) Error() string {
+	return fmt.Sprintf(
+		"blob does not match the schema of type %q (%d fields)",
+		err.Type,
+		len(err.Fields),
+	)
+}
+
+func (err ErrBlobSchema) Unwrap() []error {
+	errs := make([]error, len(err.Fields))
+
+	for i, field := range err.Fields {
+		errs[i] = field
+	}
+
+	return errs
+}
+
+func (err ErrBlobSchema) Is(target error) bool {
+	_, ok := target.(ErrBlobSchema)
+	return ok
+}
+
+func (err ErrBlobSchema) GetErrorCode() errors.Code {
+	return errors.CodeInvalidBlob
+}
+
+// Validate checks a TOML blob against the schema, returning an ErrBlobSchema
+// with every field that does not match it.
+func (schema Schema) Validate(tipe string, blob []byte) (err error) {
+	if len(schema) == 0 {
+		return err
+	}
+
+	schemaErr := ErrBlobSchema{Type: tipe}
+
+	var document map[string]any
+
+	if err = toml.Unmarshal(blob, &document); err != nil {
+		field := ErrBlobField{Message: fmt.Sprintf("invalid toml: %s", err)}
+
+		var decodeErr *toml.DecodeError
+
+		if errors.As(err, &decodeErr) {
+			row, column := decodeErr.Position()
+			field.Message = fmt.Sprintf(
+				"invalid toml at line %d, column %d: %s",
+				row,
+				column,
+				decodeErr,
+			)
+
+			if key := decodeErr.Key(); len(key) > 0 {
+				field.Field = strings.Join(key, ".")
+			}
+		}
+
+		schemaErr.Fields = append(schemaErr.Fields, field)
+		err = schemaErr
+
+		return err
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(schema)) {
+		field := schema[path]
+		value, ok := lookupField(document, path)
+
+		if !ok {
+			if field.Required {
+				schemaErr.Fields = append(
+					schemaErr.Fields,
+					ErrBlobField{Field: path, Message: "required but missing"},
+				)
+			}
+
+			continue
+		}
+
+		if message := field.check(value); message != "" {
+			schemaErr.Fields = append(
+				schemaErr.Fields,
+				ErrBlobField{Field: path, Message: message},
+			)
+		}
+	}
+
+	if len(schemaErr.Fields) > 0 {
+		err = schemaErr
+	}
+
+	return err
+}
+
+func lookupField(document map[string]any, path string) (value any, ok bool) {
+	value = document
+
+	for key := range strings.SplitSeq(path, ".") {
+		var table map[string]any
+
+		if table, ok = value.(map[string]any); !ok {
+			return value, ok
+		}
+
+		if value, ok = table[key]; !ok {
+			return value, ok
+		}
+	}
+
+	return value, ok
+}
+
+// check returns why a value does not match the field, or "" if it does.
+func (field FieldSchema) check(value any) string {
+	if field.Type != "" {
+		if actual := fieldTypeOf(value); actual != field.Type &&
+			!(field.Type == FieldTypeFloat && actual == FieldTypeInteger) {
+			return fmt.Sprintf("expected %s, got %s", field.Type, actual)
+		}
+	}
+
+	if len(field.Enum) > 0 {
+		valueString := fmt.Sprint(value)
+
+		if !slices.Contains(field.Enum, valueString) {
+			return fmt.Sprintf(
+				"%q is not one of %s",
+				valueString,
+				strings.Join(field.Enum, ", "),
+			)
+		}
+	}
+
+	if field.Pattern != "" {
+		valueString, ok := value.(string)
+
+		if !ok {
+			return fmt.Sprintf("expected string to match %q", field.Pattern)
+		}
+
+		pattern, err := regexp.Compile(field.Pattern)
+		if err != nil {
+			return fmt.Sprintf("invalid pattern %q in schema: %s", field.Pattern, err)
+		}
+
+		if !pattern.MatchString(valueString) {
+			return fmt.Sprintf("%q does not match %q", valueString, field.Pattern)
+		}
+	}
+
+	if field.Min == nil && field.Max == nil {
+		return ""
+	}
+
+	var measure float64
+	var measured string
+
+	switch value := value.(type) {
+	case int64:
+		measure, measured = float64(value), "value"
+
+	case float64:
+		measure, measured = value, "value"
+
+	case string:
+		measure, measured = float64(len([]rune(value))), "length"
+
+	case []any:
+		measure, measured = float64(len(value)), "length"
+
+	default:
+		return ""
+	}
+
+	if field.Min != nil && measure < *field.Min {
+		return fmt.Sprintf("%s %g is less than %g", measured, measure, *field.Min)
+	}
+
+	if field.Max != nil && measure > *field.Max {
+		return fmt.Sprintf("%s %g is more than %g", measured, measure, *field.Max)
+	}
+
+	return ""
+}
+
+func fieldTypeOf(value any) string {
+	switch value.(type) {
+	case string:
+		return FieldTypeString
+
+	case int64:
+		return FieldTypeInteger
+
+	case float64:
+		return FieldTypeFloat
+
+	case bool:
+		return FieldTypeBoolean
+
+	case time.Time, toml.LocalDate, toml.LocalDateTime, toml.LocalTime:
+		return FieldTypeDatetime
+
+	case []any:
+		return FieldTypeArray
+
+	case map[string]any:
+		return FieldTypeTable
+
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
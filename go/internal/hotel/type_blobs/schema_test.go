@@ -0,0 +1,111 @@
+package type_blobs
+
+import (
+	"errors"
+	"testing"
+)
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func validateSchema(t *testing.T, schema Schema, blob string) []ErrBlobField {
+	t.Helper()
+
+	err := schema.Validate("!task", []byte(blob))
+
+	if err == nil {
+		return nil
+	}
+
+	var schemaErr ErrBlobSchema
+
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected ErrBlobSchema, got %T: %s", err, err)
+	}
+
+	return schemaErr.Fields
+}
+
+func TestSchemaValid(t *testing.T) {
+	schema := Schema{
+		"title":       {Required: true, Type: FieldTypeString},
+		"priority":    {Type: FieldTypeInteger, Min: floatPtr(1), Max: floatPtr(5)},
+		"meta.status": {Enum: []string{"todo", "done"}},
+		"estimate":    {Type: FieldTypeFloat},
+	}
+
+	fields := validateSchema(t, schema, `
+title = "write the schema"
+priority = 2
+estimate = 3
+
+[meta]
+status = "todo"
+`)
+
+	if len(fields) != 0 {
+		t.Errorf("expected no errors, got %v", fields)
+	}
+}
+
+func TestSchemaInvalidFields(t *testing.T) {
+	schema := Schema{
+		"title":       {Required: true},
+		"priority":    {Type: FieldTypeInteger, Max: floatPtr(5)},
+		"meta.status": {Enum: []string{"todo", "done"}},
+		"slug":        {Pattern: "^[a-z-]+$"},
+		"tags":        {Type: FieldTypeArray, Min: floatPtr(1)},
+	}
+
+	fields := validateSchema(t, schema, `
+priority = 7
+slug = "Not A Slug"
+tags = []
+
+[meta]
+status = "blocked"
+`)
+
+	expected := []ErrBlobField{
+		{Field: "meta.status", Message: `"blocked" is not one of todo, done`},
+		{Field: "priority", Message: "value 7 is more than 5"},
+		{Field: "slug", Message: `"Not A Slug" does not match "^[a-z-]+$"`},
+		{Field: "tags", Message: "length 0 is less than 1"},
+		{Field: "title", Message: "required but missing"},
+	}
+
+	if len(fields) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), fields)
+	}
+
+	for i, field := range fields {
+		if field != expected[i] {
+			t.Errorf("expected %#v, got %#v", expected[i], field)
+		}
+	}
+}
+
+func TestSchemaWrongType(t *testing.T) {
+	fields := validateSchema(
+		t,
+		Schema{"priority": {Type: FieldTypeInteger}},
+		`priority = "high"`,
+	)
+
+	if len(fields) != 1 || fields[0].Message != "expected integer, got string" {
+		t.Errorf("unexpected errors: %v", fields)
+	}
+}
+
+func TestSchemaInvalidToml(t *testing.T) {
+	fields := validateSchema(
+		t,
+		Schema{"title": {Required: true}},
+		"title = \n",
+	)
+
+	if len(fields) != 1 {
+		t.Fatalf("expected one error, got %v", fields)
+	}
+}
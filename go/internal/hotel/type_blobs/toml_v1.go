@@ -16,6 +16,26 @@ type TomlV1 struct {
 
 	// TODO migrate to properly-typed hooks
 	Hooks any `toml:"hooks"`
+
+	// Migration is a Lua script whose `migrate` function re-encodes the blobs
+	// of this type for `migrate-type`.
+	Migration string `toml:"migration,omitempty"`
+
+	// Schema is checked against the TOML blobs of this type when they are
+	// committed.
+	Schema Schema `toml:"schema,omitempty"`
+
+	// Template is a blob id, or the id of an object whose blob is used, that
+	// `new` expands as the blob of new objects of this type, with
+	// TemplateFields available to it.
+	Template       string            `toml:"template,omitempty"`
+	TemplateFields map[string]string `toml:"template-fields,omitempty"`
+
+	// DiffTool and MergeTool compare and merge the blobs of this type when
+	// they are not text, with `{old}` and `{new}`, and `{local}`, `{base}`,
+	// `{remote}`, and `{merged}` replaced by the paths of the blobs.
+	DiffTool  *script_config.ExternalTool `toml:"diff-tool,omitempty"`
+	MergeTool *script_config.ExternalTool `toml:"merge-tool,omitempty"`
 }
 
 func (blob *TomlV1) Reset() {
@@ -28,6 +48,12 @@ func (blob *TomlV1) Reset() {
 	blob.UTIGroups = reset.Map(blob.UTIGroups)
 	blob.Formatters = reset.Map(blob.Formatters)
 	blob.Hooks = nil
+	blob.Migration = ""
+	blob.Schema = reset.Map(blob.Schema)
+	blob.Template = ""
+	blob.TemplateFields = reset.Map(blob.TemplateFields)
+	blob.DiffTool = nil
+	blob.MergeTool = nil
 }
 
 func (blob *TomlV1) GetBinary() bool {
@@ -58,3 +84,27 @@ func (blob *TomlV1) GetStringLuaHooks() string {
 	hooks, _ := blob.Hooks.(string)
 	return hooks
 }
+
+func (blob *TomlV1) GetStringLuaMigration() string {
+	return blob.Migration
+}
+
+func (blob *TomlV1) GetSchema() Schema {
+	return blob.Schema
+}
+
+func (blob *TomlV1) GetTemplate() string {
+	return blob.Template
+}
+
+func (blob *TomlV1) GetTemplateFields() map[string]string {
+	return blob.TemplateFields
+}
+
+func (blob *TomlV1) GetDiffTool() *script_config.ExternalTool {
+	return blob.DiffTool
+}
+
+func (blob *TomlV1) GetMergeTool() *script_config.ExternalTool {
+	return blob.MergeTool
+}
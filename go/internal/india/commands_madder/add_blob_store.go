@@ -0,0 +1,203 @@
+package commands_madder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
+	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/flags"
+	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
+)
+
+func init() {
+	utility.AddCmd("add-blob-store", &AddBlobStore{})
+}
+
+// AddBlobStore writes the config of another blob store next to the existing
+// ones. Blob stores are found by their configs, so the new store is used by
+// every later command.
+type AddBlobStore struct {
+	command_components_madder.EnvBlobStore
+	command_components_madder.Init
+
+	BlobStoreId blob_store_id.Id
+}
+
+var _ interfaces.CommandComponentWriter = (*AddBlobStore)(nil)
+
+func (cmd *AddBlobStore) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.Var(
+		&cmd.BlobStoreId,
+		"id",
+		"the id of the new blob store. Defaults to its type, suffixed until it is unused",
+	)
+}
+
+func (cmd AddBlobStore) GetDescription() command.Description {
+	return command.Description{
+		Short: "add a blob store to an existing repo",
+		Long: "Usage: add-blob-store [-id <id>] <type> [<flags of the type>]. " +
+			"Writes the config of a new blob store of the given type, " +
+			"using the same flags as the matching init command. The " +
+			"config is validated and written atomically, and the new " +
+			"store may not take over as the default blob store. Types: " +
+			strings.Join(getBlobStoreTypeNames(), ", ") + ".",
+	}
+}
+
+func (cmd AddBlobStore) Run(req command.Request) {
+	typeName := req.PopArg("blob store type")
+
+	var blobStoreType blobStoreType
+
+	{
+		found := false
+
+		for _, candidate := range blobStoreTypes {
+			if candidate.name == typeName {
+				blobStoreType = candidate
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			errors.ContextCancelWithBadRequestf(
+				req,
+				"unsupported blob store type: %q. Expected one of %q",
+				typeName,
+				getBlobStoreTypeNames(),
+			)
+		}
+	}
+
+	config := blobStoreType.makeConfig()
+
+	{
+		flagSet := flags.NewFlagSet(
+			"add-blob-store "+typeName,
+			flags.ContinueOnError,
+		)
+
+		flagSet.SetOutput(io.Discard)
+		config.SetFlagDefinitions(flagSet)
+
+		if err := flagSet.Parse(req.PopArgs()); err != nil {
+			errors.ContextCancelWithBadRequestError(req, err)
+		}
+
+		if args := flagSet.Args(); len(args) > 0 {
+			errors.ContextCancelWithBadRequestf(
+				req,
+				"unexpected arguments after the flags of %q: %q",
+				typeName,
+				args,
+			)
+		}
+	}
+
+	envBlobStore := cmd.MakeEnvBlobStore(req)
+	blobStores := envBlobStore.GetBlobStores()
+
+	blobStoreId := cmd.BlobStoreId
+
+	if blobStoreId.IsEmpty() {
+		blobStoreId = cmd.makeBlobStoreId(envBlobStore, typeName)
+	}
+
+	if _, ok := blobStores[blobStoreId.String()]; ok {
+		errors.ContextCancelWithBadRequestf(
+			req,
+			"blob store %q already exists",
+			blobStoreId,
+		)
+	}
+
+	// the default blob store is the first one by id
+	if len(blobStores) > 0 {
+		defaultId := envBlobStore.GetDefaultBlobStore().Path.GetId()
+
+		if blobStoreId.String() < defaultId.String() {
+			errors.ContextCancelWithBadRequestf(
+				req,
+				"blob store id %q sorts before %q and would replace it as the default blob store. Choose an id after it",
+				blobStoreId,
+				defaultId,
+			)
+		}
+	}
+
+	for _, referencedId := range blob_store_configs.GetReferencedBlobStoreIds(
+		config,
+	) {
+		if _, ok := blobStores[referencedId.String()]; !ok {
+			errors.ContextCancelWithBadRequestf(
+				req,
+				"blob store %q refers to blob store %q, which does not exist",
+				blobStoreId,
+				referencedId,
+			)
+		}
+	}
+
+	tw := tap.NewWriter(os.Stdout)
+
+	path := cmd.AddBlobStore(
+		req,
+		envBlobStore,
+		blobStoreId,
+		&blob_store_configs.TypedConfig{
+			Type: blobStoreType.tipe,
+			Blob: config,
+		},
+	)
+
+	tw.Ok(fmt.Sprintf("add %s %s", blobStoreId, path.GetConfig()))
+	tw.Plan()
+}
+
+// makeBlobStoreId returns the first unused id of the form <type>, <type>-2,
+// and so on, in the default blob store's location, and prefixed with the
+// default blob store's name when it would otherwise sort before it.
+func (cmd AddBlobStore) makeBlobStoreId(
+	envBlobStore env_repo.BlobStoreEnv,
+	typeName string,
+) blob_store_id.Id {
+	blobStores := envBlobStore.GetBlobStores()
+
+	var location blob_store_id.LocationTypeGetter = blob_store_id.LocationTypeXDGUser
+
+	if len(blobStores) > 0 {
+		defaultId := envBlobStore.GetDefaultBlobStore().Path.GetId()
+		location = defaultId
+
+		if blob_store_id.MakeWithLocation(typeName, location).String() <
+			defaultId.String() {
+			typeName = defaultId.GetName() + "-" + typeName
+		}
+	}
+
+	for i := 1; ; i++ {
+		name := typeName
+
+		if i > 1 {
+			name = fmt.Sprintf("%s-%d", typeName, i)
+		}
+
+		id := blob_store_id.MakeWithLocation(name, location)
+
+		if _, ok := blobStores[id.String()]; !ok {
+			return id
+		}
+	}
+}
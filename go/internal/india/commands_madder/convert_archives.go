@@ -0,0 +1,150 @@
+package commands_madder
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"code.linenisgreat.com/dodder/go/internal/charlie/tap_diagnostics"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
+	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
+)
+
+func init() {
+	utility.AddCmd("convert-archives", &ConvertArchives{})
+}
+
+// ConvertArchives re-packs the v0 inventory archives of the given blob
+// stores, or all of them, as v1 archives. Stores still configured as v0
+// inventory archives have their config upgraded first.
+type ConvertArchives struct {
+	command_components_madder.EnvBlobStore
+	command_components_madder.BlobStore
+
+	Delta       bool
+	MaxPackSize ui.HumanReadableBytes
+	NoWait      bool
+}
+
+var _ interfaces.CommandComponentWriter = (*ConvertArchives)(nil)
+
+func (cmd *ConvertArchives) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.BoolVar(&cmd.Delta, "delta", false,
+		"delta-compress the converted archives even if the config disables deltas")
+	flagSet.BoolVar(&cmd.NoWait, "no-wait", false,
+		"fail instead of waiting when another process is packing the store")
+	flagSet.Var(&cmd.MaxPackSize, "max-pack-size",
+		"override max pack size (e.g. 100M, 1G, 0 = unlimited)",
+	)
+}
+
+func (cmd ConvertArchives) Run(req command.Request) {
+	envBlobStore := cmd.MakeEnvBlobStore(req)
+	blobStoreMap := cmd.MakeBlobStoresFromIdsOrAll(req, envBlobStore)
+
+	tw := tap.NewWriter(os.Stdout)
+
+	for storeId, blobStore := range blobStoreMap {
+		switch blobStore.Config.Blob.(type) {
+		// must precede ConfigInventoryArchive, which it also satisfies
+		case blob_store_configs.ConfigInventoryArchiveDelta:
+
+		case blob_store_configs.ConfigInventoryArchive:
+			var err error
+
+			if blobStore, err = cmd.upgradeConfig(
+				envBlobStore,
+				blobStore,
+			); err != nil {
+				tw.NotOk(
+					fmt.Sprintf("upgrade config %s", storeId),
+					tap_diagnostics.FromError(err),
+				)
+				req.Cancel(err)
+				return
+			}
+
+			tw.Ok(fmt.Sprintf("upgrade config %s", storeId))
+		}
+
+		convertible, ok := blobStore.BlobStore.(blob_stores.ConvertibleArchive)
+		if !ok {
+			tw.Skip(storeId, "not an inventory archive")
+			continue
+		}
+
+		if err := convertible.ConvertArchives(blob_stores.PackOptions{
+			Context:     req,
+			MaxPackSize: cmd.MaxPackSize.GetByteCount(),
+			Delta:       cmd.Delta,
+			NoWait:      cmd.NoWait,
+			TapWriter:   tw,
+		}); errors.Is(err, blob_stores.ErrStoreBusy{}) {
+			tw.Skip(storeId, err.Error())
+			continue
+		} else if err != nil {
+			tw.NotOk(
+				fmt.Sprintf("convert %s", storeId),
+				tap_diagnostics.FromError(err),
+			)
+			req.Cancel(err)
+			return
+		}
+
+		tw.Ok(fmt.Sprintf("convert %s", storeId))
+	}
+
+	tw.Plan()
+}
+
+// upgradeConfig rewrites the config of a v0 inventory archive store as the
+// latest inventory archive config and returns the store made from it. The
+// config is rewritten before any archive is converted, so that an interrupted
+// conversion is resumed by running the command again.
+func (cmd ConvertArchives) upgradeConfig(
+	envBlobStore env_repo.BlobStoreEnv,
+	blobStore blob_stores.BlobStoreInitialized,
+) (upgraded blob_stores.BlobStoreInitialized, err error) {
+	upgraded.ConfigNamed = blobStore.ConfigNamed
+
+	for {
+		configUpgradeable, ok := upgraded.Config.Blob.(blob_store_configs.ConfigUpgradeable)
+
+		if !ok {
+			break
+		}
+
+		upgraded.Config.Blob, upgraded.Config.Type = configUpgradeable.Upgrade()
+	}
+
+	if err = files.WriteAtomic(
+		upgraded.Path.GetConfig(),
+		func(writer io.Writer) (err error) {
+			_, err = blob_store_configs.Coder.EncodeTo(&upgraded.Config, writer)
+			return err
+		},
+	); err != nil {
+		return upgraded, err
+	}
+
+	if upgraded.BlobStore, err = blob_stores.MakeBlobStore(
+		envBlobStore,
+		upgraded.ConfigNamed,
+		envBlobStore.GetBlobStores(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return upgraded, err
+	}
+
+	return upgraded, err
+}
@@ -0,0 +1,104 @@
+package commands_madder
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/charlie/tap_diagnostics"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
+)
+
+func init() {
+	utility.AddCmd("dedup", &Dedup{})
+}
+
+// Dedup reports the blobs kept by more than one of the given blob stores, or
+// all of them, and with -link hard links the local copies of each to one
+// file.
+type Dedup struct {
+	command_components_madder.EnvBlobStore
+	command_components_madder.BlobStore
+
+	Link bool
+}
+
+var _ interfaces.CommandComponentWriter = (*Dedup)(nil)
+
+func (cmd *Dedup) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.BoolVar(&cmd.Link, "link", false,
+		"replace duplicate copies in local stores with hard links to one copy")
+}
+
+func (cmd Dedup) Run(req command.Request) {
+	envBlobStore := cmd.MakeEnvBlobStore(req)
+	blobStores := cmd.MakeBlobStoresFromIdsOrAll(req, envBlobStore)
+
+	duplicates, err := blob_stores.FindDuplicates(blobStores)
+	if err != nil {
+		envBlobStore.Cancel(err)
+		return
+	}
+
+	if !cmd.Link {
+		for _, duplicate := range duplicates {
+			ui.Out().Printf(
+				"%s %s",
+				duplicate.BlobId,
+				strings.Join(duplicate.StoreIds, " "),
+			)
+		}
+
+		ui.Err().Printf("%d blobs kept by more than one store", len(duplicates))
+
+		return
+	}
+
+	tw := tap.NewWriter(os.Stdout)
+
+	var relinked int
+	var saved int64
+
+	for _, duplicate := range duplicates {
+		for _, result := range blob_stores.Dedup(
+			envBlobStore,
+			blobStores,
+			duplicate,
+		) {
+			description := fmt.Sprintf(
+				"link %s %s to %s",
+				result.StoreId,
+				duplicate.BlobId,
+				result.KeptStoreId,
+			)
+
+			if result.Err != nil {
+				tw.NotOk(description, tap_diagnostics.FromError(result.Err))
+				continue
+			}
+
+			if result.Size == 0 {
+				tw.Skip(description, "already linked")
+				continue
+			}
+
+			relinked++
+			saved += result.Size
+			tw.Ok(description)
+		}
+	}
+
+	tw.Comment(fmt.Sprintf(
+		"relinked %d blobs, replacing %s",
+		relinked,
+		ui.GetHumanBytesStringOrError(saved),
+	))
+	tw.Plan()
+}
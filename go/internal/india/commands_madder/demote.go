@@ -0,0 +1,87 @@
+package commands_madder
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/charlie/tap_diagnostics"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
+)
+
+func init() {
+	utility.AddCmd("demote", &Demote{})
+}
+
+// Demote moves the archives of the given blob stores, or all of them, that
+// have not been read for their `tiering.demote-after-days` to their
+// `tiering.cold-path`.
+type Demote struct {
+	command_components_madder.EnvBlobStore
+	command_components_madder.BlobStore
+
+	DryRun bool
+}
+
+var _ interfaces.CommandComponentWriter = (*Demote)(nil)
+
+func (cmd *Demote) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.BoolVar(&cmd.DryRun, "dry-run", false,
+		"list the archives that would be demoted without moving them")
+}
+
+func (cmd Demote) Run(req command.Request) {
+	envBlobStore := cmd.MakeEnvBlobStore(req)
+	blobStoreMap := cmd.MakeBlobStoresFromIdsOrAll(req, envBlobStore)
+
+	tw := tap.NewWriter(os.Stdout)
+	now := time.Now()
+
+	for storeId, blobStore := range blobStoreMap {
+		tiered, ok := blobStore.BlobStore.(blob_stores.TieredArchive)
+		if !ok || !tiered.IsTiered() {
+			tw.Skip(storeId, "not tiered")
+			continue
+		}
+
+		demoted, err := tiered.DemoteArchives(now, cmd.DryRun)
+		if err != nil {
+			tw.NotOk(
+				fmt.Sprintf("demote %s", storeId),
+				tap_diagnostics.FromError(err),
+			)
+			req.Cancel(err)
+			return
+		}
+
+		for _, archive := range demoted {
+			tw.Comment(fmt.Sprintf(
+				"(blob_store: %s) %s %s, last read %s (%s, %d blobs of %s)",
+				storeId,
+				archive.Checksum,
+				archive.Path,
+				archive.LastRead.Format(time.DateOnly),
+				ui.GetHumanBytesStringOrError(archive.Size),
+				archive.Blobs,
+				ui.GetHumanBytesString(archive.BlobBytes),
+			))
+		}
+
+		verb := "demoted"
+
+		if cmd.DryRun {
+			verb = "would demote"
+		}
+
+		tw.Ok(fmt.Sprintf("demote %s: %s %d archives", storeId, verb, len(demoted)))
+	}
+
+	tw.Plan()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"sync/atomic"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
 	"code.linenisgreat.com/dodder/go/internal/golf/command"
 	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
 )
@@ -44,11 +46,14 @@ func (cmd Fsck) Run(req command.Request) {
 		if err := errors.RunChildContextWithPrintTicker(
 			envBlobStore,
 			func(ctx errors.Context) {
-				for digest, err := range blobStore.AllBlobs() {
+				for info, err := range fsckBlobInfos(blobStore) {
 					errors.ContextContinueOrPanic(ctx)
 
 					if err != nil {
-						tw.NotOk("(unknown blob)", tap_diagnostics.FromError(err))
+						tw.NotOk(
+							fsckBlobDescription(info),
+							fsckDiagnostics(info, tap_diagnostics.FromError(err)),
+						)
 						errorCount.Add(1)
 						count.Add(1)
 
@@ -57,20 +62,48 @@ func (cmd Fsck) Run(req command.Request) {
 
 					count.Add(1)
 
+					digest := &info.Id
+
 					if !blobStore.HasBlob(digest) {
-						tw.NotOk(fmt.Sprintf("%s", digest), map[string]string{"severity": "fail", "message": "blob missing"})
+						tw.NotOk(fmt.Sprintf("%s", digest), fsckDiagnostics(
+							info,
+							map[string]string{"severity": "fail", "message": "blob missing"},
+						))
 						errorCount.Add(1)
 
 						continue
 					}
 
+					var blobWriter env_ui.ProgressWriter
+
 					if err = blob_stores.VerifyBlob(
 						ctx,
 						blobStore,
 						digest,
-						io.MultiWriter(&progressWriter, io.Discard),
+						io.MultiWriter(&progressWriter, &blobWriter),
 					); err != nil {
-						tw.NotOk(fmt.Sprintf("%s", digest), tap_diagnostics.FromError(err))
+						tw.NotOk(
+							fmt.Sprintf("%s", digest),
+							fsckDiagnostics(info, tap_diagnostics.FromError(err)),
+						)
+						errorCount.Add(1)
+
+						continue
+					}
+
+					if info.EntryType != "" &&
+						uint64(blobWriter.GetWritten()) != info.Size {
+						tw.NotOk(fmt.Sprintf("%s", digest), fsckDiagnostics(
+							info,
+							map[string]string{
+								"severity": "fail",
+								"message": fmt.Sprintf(
+									"listed as %d bytes but read %d",
+									info.Size,
+									blobWriter.GetWritten(),
+								),
+							},
+						))
 						errorCount.Add(1)
 
 						continue
@@ -105,3 +138,60 @@ func (cmd Fsck) Run(req command.Request) {
 
 	tw.Plan()
 }
+
+// fsckBlobInfos lists the blobs of stores that describe how they keep them
+// with their location and size, and the blobs of all others by id alone, as
+// verifying reads each blob anyway.
+func fsckBlobInfos(
+	blobStore blob_stores.BlobStoreInitialized,
+) interfaces.SeqError[blob_stores.BlobInfo] {
+	if lister, ok := blobStore.BlobStore.(blob_stores.BlobInfoLister); ok {
+		return lister.AllBlobInfos()
+	}
+
+	return func(yield func(blob_stores.BlobInfo, error) bool) {
+		for id, err := range blobStore.AllBlobs() {
+			var info blob_stores.BlobInfo
+
+			if err == nil {
+				info.Id.ResetWithMarklId(id)
+			}
+
+			if !yield(info, err) {
+				return
+			}
+		}
+	}
+}
+
+func fsckBlobDescription(info blob_stores.BlobInfo) string {
+	if info.Id.IsNull() {
+		return "(unknown blob)"
+	}
+
+	return info.Id.String()
+}
+
+// fsckDiagnostics adds where a blob is kept, when known, to the diagnostics
+// of a failed check.
+func fsckDiagnostics(
+	info blob_stores.BlobInfo,
+	diag map[string]string,
+) map[string]string {
+	if info.EntryType == "" {
+		return diag
+	}
+
+	diag["entry_type"] = string(info.EntryType)
+
+	if info.Path != "" {
+		diag["path"] = info.Path
+	}
+
+	if info.ArchiveChecksum != "" ||
+		info.EntryType == blob_stores.BlobEntryTypeInline {
+		diag["offset"] = strconv.FormatUint(info.Offset, 10)
+	}
+
+	return diag
+}
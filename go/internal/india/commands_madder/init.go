@@ -15,72 +15,142 @@ import (
 	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
 )
 
-func init() {
-	utility.AddCmd("init", &Init{
+// blobStoreType is a type of blob store that can be created with init or
+// add-blob-store, along with the defaults of its config.
+type blobStoreType struct {
+	name       string
+	tipe       ids.TypeStruct
+	makeConfig func() blob_store_configs.ConfigMutable
+}
+
+var blobStoreTypes = []blobStoreType{
+	{
+		name: "local",
 		tipe: ids.GetOrPanic(ids.TypeTomlBlobStoreConfigVCurrent).TypeStruct,
-		blobStoreConfig: &blob_store_configs.DefaultType{
-			CompressionType:   compression_type.CompressionTypeDefault,
-			LockInternalFiles: true,
+		makeConfig: func() blob_store_configs.ConfigMutable {
+			return &blob_store_configs.DefaultType{
+				CompressionType:   compression_type.CompressionTypeDefault,
+				LockInternalFiles: true,
+			}
 		},
-	})
-
-	utility.AddCmd("init-pointer", &Init{
+	},
+	{
+		name: "pointer",
 		tipe: ids.GetOrPanic(
 			ids.TypeTomlBlobStoreConfigPointerV0,
 		).TypeStruct,
-		blobStoreConfig: &blob_store_configs.TomlPointerV0{},
-	})
-
-	utility.AddCmd("init-sftp-explicit", &Init{
+		makeConfig: func() blob_store_configs.ConfigMutable {
+			return &blob_store_configs.TomlPointerV0{}
+		},
+	},
+	{
+		name: "lazy-parent",
+		tipe: ids.GetOrPanic(
+			ids.TypeTomlBlobStoreConfigLazyParentV0,
+		).TypeStruct,
+		makeConfig: func() blob_store_configs.ConfigMutable {
+			return &blob_store_configs.TomlLazyParentV0{
+				CompressionType:   compression_type.CompressionTypeDefault,
+				LockInternalFiles: true,
+			}
+		},
+	},
+	{
+		name: "encrypted",
+		tipe: ids.GetOrPanic(
+			ids.TypeTomlBlobStoreConfigEncryptedV0,
+		).TypeStruct,
+		makeConfig: func() blob_store_configs.ConfigMutable {
+			return &blob_store_configs.TomlEncryptedV0{}
+		},
+	},
+	{
+		name: "sftp-explicit",
 		tipe: ids.GetOrPanic(
 			ids.TypeTomlBlobStoreConfigSftpExplicitV0,
 		).TypeStruct,
-		blobStoreConfig: &blob_store_configs.TomlSFTPV0{},
-	})
-
-	utility.AddCmd("init-sftp-ssh_config", &Init{
+		makeConfig: func() blob_store_configs.ConfigMutable {
+			return &blob_store_configs.TomlSFTPV0{}
+		},
+	},
+	{
+		name: "sftp-ssh_config",
 		tipe: ids.GetOrPanic(
 			ids.TypeTomlBlobStoreConfigSftpViaSSHConfigV0,
 		).TypeStruct,
-		blobStoreConfig: &blob_store_configs.TomlSFTPViaSSHConfigV0{},
-	})
-
-	utility.AddCmd("init-inventory-archive", &Init{
+		makeConfig: func() blob_store_configs.ConfigMutable {
+			return &blob_store_configs.TomlSFTPViaSSHConfigV0{}
+		},
+	},
+	{
+		name: "inventory-archive",
 		tipe: ids.GetOrPanic(
 			ids.TypeTomlBlobStoreConfigInventoryArchiveVCurrent,
 		).TypeStruct,
-		blobStoreConfig: &blob_store_configs.TomlInventoryArchiveV2{
-			Delta: blob_store_configs.DeltaConfig{
-				Enabled:     false,
-				Algorithm:   "bsdiff",
-				MinBlobSize: 256,
-				MaxBlobSize: 10485760,
-				SizeRatio:   2.0,
-			},
+		makeConfig: func() blob_store_configs.ConfigMutable {
+			return &blob_store_configs.TomlInventoryArchiveV2{
+				Delta: blob_store_configs.DeltaConfig{
+					Enabled:     false,
+					Algorithm:   "bsdiff",
+					Strategy:    "size",
+					MinBlobSize: 256,
+					MaxBlobSize: 10485760,
+					SizeRatio:   2.0,
+				},
+			}
 		},
-	})
-
-	utility.AddCmd("init-inventory-archive-v1", &Init{
+	},
+	{
+		name: "inventory-archive-v1",
 		tipe: ids.GetOrPanic(
 			ids.TypeTomlBlobStoreConfigInventoryArchiveV1,
 		).TypeStruct,
-		blobStoreConfig: &blob_store_configs.TomlInventoryArchiveV1{
-			Delta: blob_store_configs.DeltaConfig{
-				Enabled:     false,
-				Algorithm:   "bsdiff",
-				MinBlobSize: 256,
-				MaxBlobSize: 10485760,
-				SizeRatio:   2.0,
-			},
+		makeConfig: func() blob_store_configs.ConfigMutable {
+			return &blob_store_configs.TomlInventoryArchiveV1{
+				Delta: blob_store_configs.DeltaConfig{
+					Enabled:     false,
+					Algorithm:   "bsdiff",
+					Strategy:    "size",
+					MinBlobSize: 256,
+					MaxBlobSize: 10485760,
+					SizeRatio:   2.0,
+				},
+			}
 		},
-	})
-
-	utility.AddCmd("init-inventory-archive-v0", &Init{
+	},
+	{
+		name: "inventory-archive-v0",
 		tipe: ids.GetOrPanic(
 			ids.TypeTomlBlobStoreConfigInventoryArchiveV0,
 		).TypeStruct,
-		blobStoreConfig: &blob_store_configs.TomlInventoryArchiveV0{},
-	})
+		makeConfig: func() blob_store_configs.ConfigMutable {
+			return &blob_store_configs.TomlInventoryArchiveV0{}
+		},
+	},
+}
+
+func getBlobStoreTypeNames() (names []string) {
+	for _, blobStoreType := range blobStoreTypes {
+		names = append(names, blobStoreType.name)
+	}
+
+	return names
+}
+
+func init() {
+	for _, blobStoreType := range blobStoreTypes {
+		name := "init"
+
+		// the local store is the default of init
+		if blobStoreType.name != "local" {
+			name += "-" + blobStoreType.name
+		}
+
+		utility.AddCmd(name, &Init{
+			tipe:            blobStoreType.tipe,
+			blobStoreConfig: blobStoreType.makeConfig(),
+		})
+	}
 }
 
 type Init struct {
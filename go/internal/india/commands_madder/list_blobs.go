@@ -0,0 +1,152 @@
+package commands_madder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/env_local"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
+	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/collections_slice"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd("list-blobs", &ListBlobs{Format: "text"})
+}
+
+// ListBlobs lists the blobs of the given blob stores, or all of them, with
+// their size, stored size, entry type and where they are kept.
+type ListBlobs struct {
+	command_components_madder.EnvBlobStore
+	command_components_madder.BlobStore
+
+	Format string
+}
+
+var _ interfaces.CommandComponentWriter = (*ListBlobs)(nil)
+
+// listedBlob is a line of `list-blobs -format json`.
+type listedBlob struct {
+	Store string `json:"store"`
+	blob_stores.BlobInfo
+}
+
+func (cmd *ListBlobs) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.Func(
+		"format",
+		"output format: text or json, one object per line (default text)",
+		func(value string) (err error) {
+			switch value {
+			case "text", "json":
+				cmd.Format = value
+
+			default:
+				err = errors.BadRequestf(
+					"unsupported list-blobs format: %q, expected text or json",
+					value,
+				)
+			}
+
+			return err
+		},
+	)
+}
+
+func (cmd ListBlobs) Complete(
+	req command.Request,
+	envLocal env_local.Env,
+	commandLine command.CommandLineInput,
+) {
+	envBlobStore := cmd.MakeEnvBlobStore(req)
+	blobStores := envBlobStore.GetBlobStores()
+
+	for id, blobStore := range blobStores {
+		envLocal.GetOut().Printf("%s\t%s", id, blobStore.GetBlobStoreDescription())
+	}
+}
+
+func (cmd ListBlobs) Run(req command.Request) {
+	envBlobStore := cmd.MakeEnvBlobStore(req)
+	blobStores := cmd.MakeBlobStoresFromIdsOrAll(req, envBlobStore)
+
+	var blobErrors collections_slice.Slice[command_components_madder.BlobError]
+
+	for storeId, blobStore := range blobStores {
+		if err := cmd.runOne(
+			envBlobStore,
+			storeId,
+			blobStore,
+			&blobErrors,
+		); err != nil {
+			envBlobStore.Cancel(err)
+			return
+		}
+	}
+
+	command_components_madder.PrintBlobErrors(envBlobStore, blobErrors)
+}
+
+func (cmd ListBlobs) runOne(
+	envBlobStore env_repo.BlobStoreEnv,
+	storeId string,
+	blobStore blob_stores.BlobStoreInitialized,
+	blobErrors *collections_slice.Slice[command_components_madder.BlobError],
+) (err error) {
+	encoder := json.NewEncoder(envBlobStore.GetUIFile())
+
+	for info, err := range blob_stores.AllBlobInfos(blobStore.BlobStore) {
+		errors.ContextContinueOrPanic(envBlobStore)
+
+		if err != nil {
+			blobErrors.Append(
+				command_components_madder.BlobError{BlobId: &info.Id, Err: err},
+			)
+
+			continue
+		}
+
+		switch cmd.Format {
+		case "json":
+			if err = encoder.Encode(listedBlob{
+				Store:    storeId,
+				BlobInfo: info,
+			}); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+		default:
+			envBlobStore.GetUI().Printf(
+				"%s\t%s\t%d\t%d\t%s\t%s",
+				storeId,
+				info.Id,
+				info.Size,
+				info.StoredSize,
+				info.EntryType,
+				listedBlobLocation(info),
+			)
+		}
+	}
+
+	return err
+}
+
+// listedBlobLocation is a blob's path, followed by its offset when it is one
+// of the entries of an archive.
+func listedBlobLocation(info blob_stores.BlobInfo) string {
+	switch info.EntryType {
+	case blob_stores.BlobEntryTypeFull,
+		blob_stores.BlobEntryTypeDelta,
+		blob_stores.BlobEntryTypeInline:
+		return fmt.Sprintf("%s@%d", info.Path, info.Offset)
+
+	default:
+		return info.Path
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/golf/command"
 	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
 )
@@ -26,6 +27,7 @@ type Pack struct {
 	MaxPackSize      ui.HumanReadableBytes
 	SkipMissingBlobs bool
 	Delta            bool
+	NoWait           bool
 }
 
 var _ interfaces.CommandComponentWriter = (*Pack)(nil)
@@ -52,6 +54,8 @@ func (cmd *Pack) SetFlagDefinitions(
 		"skip unreadable loose blobs instead of aborting")
 	flagSet.BoolVar(&cmd.Delta, "delta", false,
 		"enable delta compression during packing")
+	flagSet.BoolVar(&cmd.NoWait, "no-wait", false,
+		"fail instead of waiting when another process is packing the store")
 	flagSet.Var(&cmd.MaxPackSize, "max-pack-size",
 		"override max pack size (e.g. 100M, 1G, 0 = unlimited)",
 	)
@@ -77,8 +81,12 @@ func (cmd Pack) Run(req command.Request) {
 			MaxPackSize:          cmd.MaxPackSize.GetByteCount(),
 			SkipMissingBlobs:     cmd.SkipMissingBlobs,
 			Delta:                cmd.Delta,
+			NoWait:               cmd.NoWait,
 			TapWriter:            tw,
-		}); err != nil {
+		}); errors.Is(err, blob_stores.ErrStoreBusy{}) {
+			tw.Skip(storeId, err.Error())
+			continue
+		} else if err != nil {
 			tw.NotOk(
 				fmt.Sprintf("pack %s", storeId),
 				tap_diagnostics.FromError(err),
@@ -29,6 +29,7 @@ type PackBlobs struct {
 	DeleteLoose bool
 	MaxPackSize ui.HumanReadableBytes
 	Delta       bool
+	NoWait      bool
 }
 
 var _ interfaces.CommandComponentWriter = (*PackBlobs)(nil)
@@ -53,6 +54,8 @@ func (cmd *PackBlobs) SetFlagDefinitions(
 		"validate archive then delete packed loose blobs")
 	flagSet.BoolVar(&cmd.Delta, "delta", false,
 		"enable delta compression during packing")
+	flagSet.BoolVar(&cmd.NoWait, "no-wait", false,
+		"fail instead of waiting when another process is packing the store")
 	flagSet.Var(&cmd.MaxPackSize, "max-pack-size",
 		"override max pack size (e.g. 100M, 1G, 0 = unlimited)",
 	)
@@ -135,6 +138,7 @@ func (cmd PackBlobs) Run(req command.Request) {
 		BlobFilter:           blobFilter,
 		MaxPackSize:          cmd.MaxPackSize.GetByteCount(),
 		Delta:                cmd.Delta,
+		NoWait:               cmd.NoWait,
 		TapWriter:            tw,
 	}); err != nil {
 		tw.NotOk(
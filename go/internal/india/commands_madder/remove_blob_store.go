@@ -0,0 +1,123 @@
+package commands_madder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
+)
+
+func init() {
+	utility.AddCmd("remove-blob-store", &RemoveBlobStore{})
+}
+
+// RemoveBlobStore deletes the config of a blob store so that later commands
+// no longer use it. Its blobs are left where they are.
+type RemoveBlobStore struct {
+	command_components_madder.EnvBlobStore
+
+	Force bool
+}
+
+var _ interfaces.CommandComponentWriter = (*RemoveBlobStore)(nil)
+
+func (cmd *RemoveBlobStore) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	flagSet.BoolVar(&cmd.Force, "force", false,
+		"remove the blob store even if it still has blobs")
+}
+
+func (cmd RemoveBlobStore) GetDescription() command.Description {
+	return command.Description{
+		Short: "remove a blob store from a repo",
+		Long: "Deletes the config of the given blob store, which must not " +
+			"be the default blob store or be read through by another " +
+			"blob store. Stores that still have blobs are only removed " +
+			"with -force. Blobs are never deleted: the store's directory " +
+			"is kept unless it is empty.",
+	}
+}
+
+func (cmd RemoveBlobStore) Run(req command.Request) {
+	var blobStoreId blob_store_id.Id
+
+	if err := blobStoreId.Set(req.PopArg("blob store id")); err != nil {
+		errors.ContextCancelWithBadRequestError(req, err)
+	}
+
+	req.AssertNoMoreArgs()
+
+	envBlobStore := cmd.MakeEnvBlobStore(req)
+	blobStore := envBlobStore.GetBlobStore(blobStoreId)
+	idString := blobStoreId.String()
+
+	if defaultId := envBlobStore.GetDefaultBlobStore().Path.GetId(); defaultId.String() == idString {
+		errors.ContextCancelWithBadRequestf(
+			req,
+			"blob store %q is the default blob store and cannot be removed",
+			blobStoreId,
+		)
+	}
+
+	for otherIdString, other := range envBlobStore.GetBlobStores() {
+		for _, referencedId := range blob_store_configs.GetReferencedBlobStoreIds(
+			other.Config.Blob,
+		) {
+			if referencedId.String() == idString {
+				errors.ContextCancelWithBadRequestf(
+					req,
+					"blob store %q is read through by blob store %q. Remove that one first",
+					blobStoreId,
+					otherIdString,
+				)
+			}
+		}
+	}
+
+	// pointers keep no blobs of their own
+	_, isPointer := blobStore.Config.Blob.(blob_store_configs.ConfigPointer)
+
+	if !cmd.Force && !isPointer {
+		for _, err := range blobStore.AllBlobs() {
+			if err != nil {
+				req.Cancel(errors.Wrap(err))
+				return
+			}
+
+			errors.ContextCancelWithBadRequestf(
+				req,
+				"blob store %q still has blobs. Copy them to another store with sync, or use -force to remove it anyway",
+				blobStoreId,
+			)
+		}
+	}
+
+	tw := tap.NewWriter(os.Stdout)
+
+	configPath := blobStore.Path.GetConfig()
+
+	if err := os.Remove(configPath); err != nil {
+		req.Cancel(errors.Wrap(err))
+		return
+	}
+
+	// fails, and keeps the blobs, unless the directory is empty
+	if err := os.Remove(filepath.Dir(configPath)); err != nil {
+		tw.Comment(fmt.Sprintf(
+			"(blob_store: %s) kept %s",
+			blobStoreId,
+			filepath.Dir(configPath),
+		))
+	}
+
+	tw.Ok(fmt.Sprintf("remove %s", blobStoreId))
+	tw.Plan()
+}
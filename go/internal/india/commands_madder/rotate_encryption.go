@@ -0,0 +1,260 @@
+package commands_madder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/_/blob_store_id"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/charlie/tap_diagnostics"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
+	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
+)
+
+func init() {
+	utility.AddCmd("rotate-encryption", &RotateEncryption{})
+}
+
+// RotateEncryption replaces the encryption keys of a blob store and
+// re-encrypts its blobs with the new ones. The old keys are kept in the
+// config as retired keys, which only decrypt, until every blob is rotated,
+// so that the store stays readable throughout and an interrupted rotation is
+// resumed by running the command again.
+type RotateEncryption struct {
+	command_components_madder.EnvBlobStore
+
+	Encryption  []markl.Id
+	KeepRetired bool
+}
+
+var _ interfaces.CommandComponentWriter = (*RotateEncryption)(nil)
+
+func (cmd *RotateEncryption) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	blob_store_configs.SetEncryptionKeysFlagDefinition(flagSet, &cmd.Encryption)
+
+	flagSet.BoolVar(&cmd.KeepRetired, "keep-retired", false,
+		"keep the retired keys in the config after every blob is rotated")
+}
+
+func (cmd RotateEncryption) GetDescription() command.Description {
+	return command.Description{
+		Short: "re-encrypt a blob store's blobs with new keys",
+		Long: "Usage: rotate-encryption [-encryption <key>]... <blob store id>. " +
+			"With -encryption, the store's keys are retired and replaced " +
+			"by the given ones (a key, a key file, or `generate`) before " +
+			"any blob is touched. Retired keys only decrypt. Every blob " +
+			"that a retired key can decrypt is then re-encrypted with the " +
+			"current keys, and once all are, the retired keys are dropped " +
+			"from the config unless -keep-retired is given. Run without " +
+			"-encryption to resume an interrupted rotation. Supports local, " +
+			"lazy-parent, and encrypted stores. Blobs of a local store's " +
+			"small-blob archive keep their old bytes in its data file.",
+	}
+}
+
+func (cmd RotateEncryption) Run(req command.Request) {
+	var blobStoreId blob_store_id.Id
+
+	if err := blobStoreId.Set(req.PopArg("blob store id")); err != nil {
+		errors.ContextCancelWithBadRequestError(req, err)
+	}
+
+	req.AssertNoMoreArgs()
+
+	envBlobStore := cmd.MakeEnvBlobStore(req)
+	blobStore := envBlobStore.GetBlobStore(blobStoreId)
+
+	configKeyRing, ok := blobStore.Config.Blob.(blob_store_configs.ConfigEncryptionKeyRingMutable)
+
+	if !ok {
+		errors.ContextCancelWithBadRequestf(
+			req,
+			"blob store %q (%s) does not support rotating its encryption keys",
+			blobStoreId,
+			blobStore.Config.Blob.GetBlobStoreType(),
+		)
+	}
+
+	tw := tap.NewWriter(os.Stdout)
+
+	if len(cmd.Encryption) > 0 {
+		ring := configKeyRing.GetEncryptionKeyRing()
+
+		if ring.EncryptionKeys.IsNull() {
+			errors.ContextCancelWithBadRequestf(
+				req,
+				"blob store %q is not encrypted",
+				blobStoreId,
+			)
+		}
+
+		if markl.IsNull(blob_store_configs.EncryptionKeys(cmd.Encryption)) {
+			errors.ContextCancelWithBadRequestf(
+				req,
+				"blob store %q needs at least one new encryption key",
+				blobStoreId,
+			)
+		}
+
+		configKeyRing.SetEncryptionKeyRing(ring.Rotate(cmd.Encryption))
+
+		var err error
+
+		if blobStore, err = cmd.writeConfig(envBlobStore, blobStore); err != nil {
+			tw.NotOk(
+				fmt.Sprintf("retire keys %s", blobStoreId),
+				tap_diagnostics.FromError(err),
+			)
+			req.Cancel(err)
+			return
+		}
+
+		tw.Ok(fmt.Sprintf("retire keys %s", blobStoreId))
+	}
+
+	if !configKeyRing.GetEncryptionKeyRing().HasRetired() {
+		tw.Skip(blobStoreId.String(), "no retired encryption keys")
+		tw.Plan()
+		return
+	}
+
+	rotator, ok := blobStore.BlobStore.(blob_stores.BlobEncryptionRotator)
+
+	if !ok {
+		errors.ContextCancelWithBadRequestf(
+			req,
+			"blob store %q cannot re-encrypt its blobs",
+			blobStoreId,
+		)
+	}
+
+	var count, rotatedCount, errorCount atomic.Uint32
+
+	if err := errors.RunChildContextWithPrintTicker(
+		envBlobStore,
+		func(ctx errors.Context) {
+			for id, err := range blobStore.AllBlobs() {
+				errors.ContextContinueOrPanic(ctx)
+
+				count.Add(1)
+
+				if err != nil {
+					tw.NotOk("(unknown blob)", tap_diagnostics.FromError(err))
+					errorCount.Add(1)
+					continue
+				}
+
+				rotated, err := rotator.RotateBlobEncryption(id)
+				if err != nil {
+					tw.NotOk(id.String(), tap_diagnostics.FromError(err))
+					errorCount.Add(1)
+					continue
+				}
+
+				if rotated {
+					rotatedCount.Add(1)
+					tw.Ok(fmt.Sprintf("rotate %s", id))
+				}
+			}
+		},
+		func(time time.Time) {
+			tw.Comment(fmt.Sprintf(
+				"(blob_store: %s) %d blobs checked, %d rotated, %d errors",
+				blobStoreId,
+				count.Load(),
+				rotatedCount.Load(),
+				errorCount.Load(),
+			))
+		},
+		3*time.Second,
+	); err != nil {
+		tw.BailOut(err.Error())
+		envBlobStore.Cancel(err)
+		return
+	}
+
+	tw.Comment(fmt.Sprintf(
+		"(blob_store: %s) blobs checked: %d, blobs rotated: %d",
+		blobStoreId,
+		count.Load(),
+		rotatedCount.Load(),
+	))
+
+	switch {
+	case errorCount.Load() > 0:
+		tw.Comment(fmt.Sprintf(
+			"(blob_store: %s) kept the retired keys. Run rotate-encryption again to resume",
+			blobStoreId,
+		))
+
+	case !cmd.KeepRetired && !envBlobStore.IsDryRun():
+		ring := configKeyRing.GetEncryptionKeyRing()
+		ring.Retired = nil
+		configKeyRing.SetEncryptionKeyRing(ring)
+
+		if _, err := cmd.writeConfig(envBlobStore, blobStore); err != nil {
+			tw.NotOk(
+				fmt.Sprintf("drop retired keys %s", blobStoreId),
+				tap_diagnostics.FromError(err),
+			)
+			req.Cancel(err)
+			return
+		}
+
+		tw.Ok(fmt.Sprintf("drop retired keys %s", blobStoreId))
+	}
+
+	tw.Plan()
+}
+
+// writeConfig writes the config of blobStore, unless this is a dry run, and
+// returns the store made from it.
+func (cmd RotateEncryption) writeConfig(
+	envBlobStore env_repo.BlobStoreEnv,
+	blobStore blob_stores.BlobStoreInitialized,
+) (rewritten blob_stores.BlobStoreInitialized, err error) {
+	rewritten.ConfigNamed = blobStore.ConfigNamed
+
+	if err = blob_store_configs.Validate(rewritten.Config.Blob); err != nil {
+		return rewritten, err
+	}
+
+	if !envBlobStore.IsDryRun() {
+		if err = files.WriteAtomic(
+			rewritten.Path.GetConfig(),
+			func(writer io.Writer) (err error) {
+				_, err = blob_store_configs.Coder.EncodeTo(
+					&rewritten.Config,
+					writer,
+				)
+
+				return err
+			},
+		); err != nil {
+			return rewritten, err
+		}
+	}
+
+	if rewritten.BlobStore, err = blob_stores.MakeBlobStore(
+		envBlobStore,
+		rewritten.ConfigNamed,
+		envBlobStore.GetBlobStores(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return rewritten, err
+	}
+
+	return rewritten, err
+}
@@ -0,0 +1,50 @@
+package commands_madder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/hotel/command_components_madder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd("storage-report", &StorageReport{})
+}
+
+// StorageReport prints the bytes each of the given blob stores, or all of
+// them, keeps against its budget, flagging stores past `warn-at-percent`.
+type StorageReport struct {
+	command_components_madder.EnvBlobStore
+	command_components_madder.BlobStore
+}
+
+var _ interfaces.CommandComponentWriter = (*StorageReport)(nil)
+
+func (cmd *StorageReport) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+}
+
+func (cmd StorageReport) Run(req command.Request) {
+	envBlobStore := cmd.MakeEnvBlobStore(req)
+	blobStores := cmd.MakeBlobStoresFromIdsOrAll(req, envBlobStore)
+
+	for _, entry := range blob_stores.StorageReport(blobStores) {
+		switch {
+		case entry.Err != nil:
+			ui.Err().Printf("%s: %s", entry.StoreId, entry.Err)
+
+		case entry.Usage.IsOverWarning():
+			ui.Out().Printf(
+				"%s: %s, past the %d%% warning",
+				entry.StoreId,
+				entry.Usage,
+				entry.Usage.WarnAtPercent,
+			)
+
+		default:
+			ui.Out().Printf("%s: %s", entry.StoreId, entry.Usage)
+		}
+	}
+}
@@ -27,8 +27,9 @@ type Sync struct {
 	command_components_madder.EnvBlobStore
 	command_components_madder.BlobStore
 
-	AllowRehashing bool
-	Limit          int
+	AllowRehashing    bool
+	Limit             int
+	MaxBytesPerSecond uint64
 }
 
 var _ interfaces.CommandComponentWriter = (*Sync)(nil)
@@ -49,6 +50,22 @@ func (cmd *Sync) SetFlagDefinitions(
 		0,
 		"number of blobs to sync before stopping. 0 means don't stop (full consent)",
 	)
+
+	flagSet.Func(
+		"max-bytes-per-second",
+		"cap the bytes per second this sync copies, in place of the global cap (e.g. 5M)",
+		func(value string) (err error) {
+			var bytes ui.HumanReadableBytes
+
+			if err = bytes.Set(value); err != nil {
+				return err
+			}
+
+			cmd.MaxBytesPerSecond = bytes.GetByteCount()
+
+			return err
+		},
+	)
 }
 
 // TODO add completion for blob store id's
@@ -56,6 +73,13 @@ func (cmd *Sync) SetFlagDefinitions(
 func (cmd Sync) Run(req command.Request) {
 	envBlobStore := cmd.MakeEnvBlobStore(req)
 
+	if cmd.MaxBytesPerSecond > 0 {
+		blob_stores.SetGlobalTransferLimits(
+			blob_stores.GetGlobalTransferLimiter().GetMaxParallel(),
+			cmd.MaxBytesPerSecond,
+		)
+	}
+
 	source, destinations := cmd.MakeSourceAndDestinationBlobStoresFromIdsOrAll(
 		req,
 		envBlobStore,
@@ -8,6 +8,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/internal/hotel/box_format"
+	"code.linenisgreat.com/dodder/go/internal/hotel/sku_lua"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
 	"code.linenisgreat.com/dodder/go/lib/delta/catgut"
@@ -24,22 +25,42 @@ type env struct {
 	envRepo      env_repo.Env
 	objectStore  sku.RepoStore
 	luaSkuFormat *box_format.BoxTransacted
+	limits       lua.Limits
 }
 
 func Make(
 	envRepo env_repo.Env,
 	objectStore sku.RepoStore,
 	luaSkuFormat *box_format.BoxTransacted,
+	limits lua.Limits,
 ) *env {
 	return &env{
 		envRepo:      envRepo,
 		objectStore:  objectStore,
 		luaSkuFormat: luaSkuFormat,
+		limits:       limits,
 	}
 }
 
 func (repo *env) MakeLuaVMPoolBuilder() *lua.VMPoolBuilder {
-	return (&lua.VMPoolBuilder{}).WithSearcher(repo.luaSearcher)
+	return (&lua.VMPoolBuilder{}).
+		WithSearcher(repo.luaSearcher).
+		WithPrepare(repo.luaPrepare).
+		WithLimits(repo.limits)
+}
+
+// luaPrepare gives the object tables of every VM `object:blob()`, reading
+// from the default blob store.
+func (repo *env) luaPrepare(vm *lua.VM) (err error) {
+	if err = sku_lua.RegisterObjectMetatable(
+		vm,
+		repo.envRepo.GetDefaultBlobStore().MakeBlobReader,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
 }
 
 func (s *env) luaSearcher(ls *lua.LState) int {
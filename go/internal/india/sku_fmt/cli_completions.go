@@ -1,6 +1,10 @@
 package sku_fmt
 
 import (
+	"slices"
+	"strings"
+	"time"
+
 	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/env_local"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
@@ -10,55 +14,72 @@ import (
 
 type GenreObjectIdCollectionMap = map[genres.Genre]interfaces.Collection[string]
 
+type CliCompletionOptions struct {
+	// only object ids starting with Prefix are printed
+	Prefix string
+
+	// only object ids of these genres are printed, or of all genres when empty
+	Genres []genres.Genre
+
+	// how long to look for matching object ids before giving up on the rest,
+	// or no limit when zero
+	Budget time.Duration
+}
+
+// how many object ids are checked between looks at the clock
+const cliCompletionsClockInterval = 256
+
+// OutputCliCompletions prints the matching object ids as `<id>\t<genre>`
+// lines, genre by genre in a stable order, stopping once the budget is used
+// up so that interactive completion stays responsive on large repos.
 func OutputCliCompletions(
 	envLocal env_local.Env,
 	genreObjectIdCollections GenreObjectIdCollectionMap,
+	options CliCompletionOptions,
 ) {
-	waitGroup := errors.MakeWaitGroupParallel()
+	var deadline time.Time
+
+	if options.Budget > 0 {
+		deadline = time.Now().Add(options.Budget)
+	}
+
 	bufferedWriter, repool := pool.GetBufferedWriter(envLocal.GetUIFile())
 	defer repool()
 
 	defer errors.ContextMustFlush(envLocal, bufferedWriter)
 
-	for genre, collection := range genreObjectIdCollections {
-		waitGroup.Do(
-			func() (err error) {
-				for objectIdString := range collection.All() {
-					bufferedWriter.WriteString(
-						objectIdString,
-					)
-					bufferedWriter.WriteByte('\t')
+	genresSorted := make([]genres.Genre, 0, len(genreObjectIdCollections))
 
-					bufferedWriter.WriteString(genre.String())
+	for genre := range genreObjectIdCollections {
+		if len(options.Genres) > 0 && !slices.Contains(options.Genres, genre) {
+			continue
+		}
 
-					// tipe := objectIdString.GetType().String()
+		genresSorted = append(genresSorted, genre)
+	}
 
-					// if tipe != "" {
-					// 	bufferedWriter.WriteString(": ")
-					// 	bufferedWriter.WriteString(
-					// 		objectIdString.GetType().String(),
-					// 	)
-					// }
+	slices.Sort(genresSorted)
 
-					// description :=
-					// objectIdString.GetMetadata().Description.String()
+	var checked int
 
-					// if description != "" {
-					// 	bufferedWriter.WriteString(" ")
-					// 	bufferedWriter.WriteString(
-					// 		objectIdString.GetMetadata().Description.String(),
-					// 	)
-					// }
+	for _, genre := range genresSorted {
+		for objectIdString := range genreObjectIdCollections[genre].All() {
+			checked++
 
-					bufferedWriter.WriteString("\n")
+			if !deadline.IsZero() &&
+				checked%cliCompletionsClockInterval == 0 &&
+				time.Now().After(deadline) {
+				return
+			}
 
-					return err
-				}
+			if !strings.HasPrefix(objectIdString, options.Prefix) {
+				continue
+			}
 
-				return err
-			},
-		)
+			bufferedWriter.WriteString(objectIdString)
+			bufferedWriter.WriteByte('\t')
+			bufferedWriter.WriteString(genre.String())
+			bufferedWriter.WriteByte('\n')
+		}
 	}
-
-	envLocal.Must(errors.MakeFuncContextFromFuncErr(waitGroup.GetError))
 }
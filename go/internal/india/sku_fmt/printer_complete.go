@@ -11,6 +11,10 @@ import (
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 )
 
+// pooledTransacted is returned to the pool by the printer's writer once it is
+// printed.
+//
+//repool:stored
 type pooledTransacted struct {
 	object *sku.Transacted
 	repool interfaces.FuncRepool
@@ -50,6 +50,7 @@ type binaryDecoder struct {
 	sigil         ids.Sigil
 	queryGroup    sku.PrimitiveQueryGroup
 	limitedReader io.LimitedReader
+	recordReader  bytes.Reader
 }
 
 // TODO transition to panic semantics
@@ -192,14 +193,34 @@ func (decoder *binaryDecoder) readFormatAndMatchSigil(
 			if (wantsHistory && wantsHidden) ||
 				(wantsHidden && isLatest) ||
 				(wantsHistory && !isHidden) ||
-				(isLatest && !isHidden) {
-				break
-			}
-
-			if query.ContainsObjectId(object.GetObjectId()) &&
-				(sigil.ContainsOneOf(ids.SigilHistory) ||
-					object.ContainsOneOf(ids.SigilLatest)) {
-				break
+				(isLatest && !isHidden) ||
+				(query.ContainsObjectId(object.GetObjectId()) &&
+					(sigil.ContainsOneOf(ids.SigilHistory) ||
+						object.ContainsOneOf(ids.SigilLatest))) {
+				prefilter, isPrefilter := query.(sku.QueryWithPrefilter)
+
+				if !isPrefilter {
+					break
+				}
+
+				var n3 int64
+
+				if n3, ok, err = decoder.readPrefiltered(
+					prefilter,
+					object.Transacted,
+				); err != nil {
+					err = errors.Wrapf(err, "Sku: %#v", object.Transacted)
+					return n, err
+				}
+
+				if ok {
+					n += n3
+					return n, err
+				}
+
+				sku.TransactedResetter.Reset(object.Transacted)
+
+				continue
 			}
 		}
 
@@ -229,6 +250,80 @@ func (decoder *binaryDecoder) readFormatAndMatchSigil(
 	return n, err
 }
 
+// readPrefiltered reads the rest of the current record into the decoder's
+// buffer and decodes its prefilter fields, which are all that query needs to
+// reject an object. The other fields are only decoded if query may contain
+// the object, so rejected objects cost neither their descriptions nor their
+// digests.
+func (decoder *binaryDecoder) readPrefiltered(
+	query sku.QueryWithPrefilter,
+	object *sku.Transacted,
+) (n int64, ok bool, err error) {
+	decoder.Buffer.Reset()
+
+	if n, err = io.Copy(&decoder.Buffer, &decoder.limitedReader); err != nil {
+		err = errors.Wrap(err)
+		return n, ok, err
+	}
+
+	if err = decoder.readBufferedFields(object, true); err != nil {
+		err = errors.Wrap(err)
+		return n, ok, err
+	}
+
+	if ok = query.MayContainSku(object); !ok {
+		return n, ok, err
+	}
+
+	if err = decoder.readBufferedFields(object, false); err != nil {
+		err = errors.Wrap(err)
+		return n, ok, err
+	}
+
+	return n, ok, err
+}
+
+// readBufferedFields decodes either the prefilter fields of the record in
+// the decoder's buffer, or all of the others.
+func (decoder *binaryDecoder) readBufferedFields(
+	object *sku.Transacted,
+	prefilterFields bool,
+) (err error) {
+	decoder.recordReader.Reset(decoder.Buffer.Bytes())
+
+	for decoder.recordReader.Len() > 0 {
+		if _, err = decoder.binaryField.ReadFrom(
+			&decoder.recordReader,
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if isPrefilterField(decoder.Key) != prefilterFields {
+			continue
+		}
+
+		if err = decoder.readFieldKey(object); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
+
+// isPrefilterField is true for the fields that queries match tags and types
+// against. Tags are also added to the tag paths, so they are among them.
+func isPrefilterField(key key_bytes.Binary) bool {
+	switch key {
+	case key_bytes.Tag, key_bytes.Type, key_bytes.CacheTags:
+		return true
+
+	default:
+		return false
+	}
+}
+
 var errExpectedSigil = newPkgError("expected sigil")
 
 func (decoder *binaryDecoder) readSigil(
@@ -299,6 +394,14 @@ func (decoder *binaryDecoder) readFieldKey(
 			return err
 		}
 
+	case key_bytes.Provenance:
+		if err = metadata.GetProvenanceMutable().Set(
+			decoder.Content.String(),
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
 	case key_bytes.Tag:
 		var tag ids.TagStruct
 
@@ -156,6 +156,18 @@ func (encoder *binaryEncoder) writeFieldKey(
 			return n, err
 		}
 
+	case key_bytes.Provenance:
+		provenance := metadata.GetProvenance()
+
+		if provenance.IsEmpty() {
+			return n, err
+		}
+
+		if n, err = encoder.writeFieldStringer(provenance); err != nil {
+			err = errors.Wrap(err)
+			return n, err
+		}
+
 	case key_bytes.Tag:
 		for _, tag := range quiter.SortedValues(object.AllTags()) {
 			if ids.TagIsVirtual(tag) {
@@ -21,6 +21,7 @@ var binaryFieldOrder = []key_bytes.Binary{
 	key_bytes.Tag,
 	key_bytes.Tai,
 	key_bytes.Type,
+	key_bytes.Provenance,
 	key_bytes.SigParentMetadataParentObjectId,
 	key_bytes.DigestMetadataParentObjectId,
 	key_bytes.DigestMetadataWithoutTai,
@@ -78,15 +79,16 @@ func (binaryField *binaryField) ReadFrom(r io.Reader) (n int64, err error) {
 		return n, err
 	}
 
-	binaryField.Content.Grow(int(binaryField.ContentLength))
 	binaryField.Content.Reset()
+	binaryField.Content.Grow(int(binaryField.ContentLength))
 
-	n2, err = io.CopyN(
-		&binaryField.Content,
-		r,
-		int64(binaryField.ContentLength),
-	)
-	n += n2
+	// read straight into the content's spare capacity, as io.CopyN allocates
+	// a reader for every field
+	content := binaryField.Content.AvailableBuffer()[:binaryField.ContentLength]
+
+	n1, err = io.ReadFull(r, content)
+	n += int64(n1)
+	binaryField.Content.Write(content[:n1])
 
 	if err != nil {
 		if err == io.EOF {
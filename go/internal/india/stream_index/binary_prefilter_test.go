@@ -0,0 +1,222 @@
+//go:build test
+
+package stream_index
+
+import (
+	"bytes"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/genesis_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/object_finalizer"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+// queryPrefilterTag may contain objects with its tag, and records whether
+// any object reached it with more than its prefilter fields decoded.
+type queryPrefilterTag struct {
+	tag         ids.TagStruct
+	sawDecoded  bool
+	prefiltered int
+}
+
+func (query *queryPrefilterTag) Get(
+	_ genres.Genre,
+) (sku.QueryWithSigilAndObjectId, bool) {
+	return query, true
+}
+
+func (query *queryPrefilterTag) GetSigil() ids.Sigil {
+	return ids.SigilLatest
+}
+
+func (query *queryPrefilterTag) HasHidden() bool {
+	return false
+}
+
+func (query *queryPrefilterTag) ContainsSku(sku.TransactedGetter) bool {
+	return true
+}
+
+func (query *queryPrefilterTag) ContainsObjectId(*ids.ObjectId) bool {
+	return false
+}
+
+func (query *queryPrefilterTag) String() string {
+	return query.tag.String()
+}
+
+func (query *queryPrefilterTag) MayContainSku(
+	objectGetter sku.TransactedGetter,
+) bool {
+	metadata := objectGetter.GetSku().GetMetadata()
+
+	query.prefiltered++
+
+	if !metadata.GetDescription().IsEmpty() {
+		query.sawDecoded = true
+	}
+
+	return metadata.GetTags().ContainsKey(query.tag.String())
+}
+
+func makeTestPrefilterObject(
+	t ui.T,
+	objectId string,
+	tag string,
+	description string,
+) *sku.Transacted {
+	object, _ := sku.GetTransactedPool().GetWithRepool()
+
+	t.AssertNoError(
+		object.GetObjectIdMutable().SetWithId(ids.MustZettelId(objectId)),
+	)
+
+	object.SetTai(ids.NowTai())
+
+	metadata := object.GetMetadataMutable()
+
+	t.AssertNoError(metadata.GetTypeMutable().SetType("!da-typ"))
+	t.AssertNoError(metadata.GetTypeLockMutable().GetValueMutable().GeneratePrivateKey(
+		nil,
+		markl.FormatIdNonceSec,
+		"",
+	))
+	t.AssertNoError(metadata.GetDescriptionMutable().Set(description))
+	t.AssertNoError(object.AddTag(ids.MustTag(tag)))
+
+	// sign it so that it carries the digests and signatures of a real object
+	{
+		config := genesis_configs.Default().Blob
+
+		t.AssertNoError(config.GetPrivateKeyMutable().GeneratePrivateKey(
+			nil,
+			markl.FormatIdEd25519Sec,
+			markl.PurposeRepoPrivateKeyV1,
+		))
+
+		t.AssertNoError(
+			object_finalizer.Make().FinalizeAndSignOverwrite(object, config),
+		)
+	}
+
+	return object
+}
+
+func TestBinaryPrefilterSkipsRejectedObjects(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	buffer := new(bytes.Buffer)
+	coder := binaryEncoder{Sigil: ids.SigilLatest}
+
+	objects := []*sku.Transacted{
+		makeTestPrefilterObject(t, "one/uno", "red", "the first"),
+		makeTestPrefilterObject(t, "two/dos", "blue", "the second"),
+		makeTestPrefilterObject(t, "three/tres", "red", "the third"),
+	}
+
+	for _, object := range objects {
+		_, err := coder.writeFormat(buffer, objectWithSigil{Transacted: object})
+		t.AssertNoError(err)
+	}
+
+	query := &queryPrefilterTag{tag: ids.MustTag("blue")}
+
+	var actual []string
+
+	for object, err := range makeSeqObjectFromReader(buffer, query) {
+		t.AssertNoError(err)
+
+		// the end of the stream is yielded as an empty object
+		if object.GetObjectId().String() == "" {
+			break
+		}
+
+		if !sku.TransactedEqualer.Equals(objects[1], object) {
+			t.Errorf(
+				"expected %q but got %q",
+				sku.String(objects[1]),
+				sku.String(object),
+			)
+		}
+
+		actual = append(actual, object.GetObjectId().String())
+	}
+
+	if len(actual) != 1 || actual[0] != "two/dos" {
+		t.Errorf("expected only two/dos, got %q", actual)
+	}
+
+	if query.prefiltered != len(objects) {
+		t.Errorf(
+			"expected %d prefiltered objects, got %d",
+			len(objects),
+			query.prefiltered,
+		)
+	}
+
+	if query.sawDecoded {
+		t.Errorf("expected descriptions to be decoded only after the prefilter")
+	}
+}
+
+func benchmarkBinaryPrefilter(
+	b *testing.B,
+	queryGroup func(*queryPrefilterTag) sku.PrimitiveQueryGroup,
+) {
+	t := ui.T{T: &testing.T{}}
+
+	buffer := new(bytes.Buffer)
+	coder := binaryEncoder{Sigil: ids.SigilLatest}
+
+	for range 100 {
+		object := makeTestPrefilterObject(t, "one/uno", "red", "a description")
+
+		if _, err := coder.writeFormat(
+			buffer,
+			objectWithSigil{Transacted: object},
+		); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	encoded := buffer.Bytes()
+	query := queryGroup(&queryPrefilterTag{tag: ids.MustTag("blue")})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for b.Loop() {
+		for _, err := range makeSeqObjectFromReader(
+			bytes.NewReader(encoded),
+			query,
+		) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkBinaryDecodeRejected decodes every object, as the executor did
+// before rejecting them.
+func BenchmarkBinaryDecodeRejected(b *testing.B) {
+	benchmarkBinaryPrefilter(
+		b,
+		func(*queryPrefilterTag) sku.PrimitiveQueryGroup {
+			return sku.MakePrimitiveQueryGroupWithSigils(ids.SigilLatest)
+		},
+	)
+}
+
+func BenchmarkBinaryPrefilterRejected(b *testing.B) {
+	benchmarkBinaryPrefilter(
+		b,
+		func(query *queryPrefilterTag) sku.PrimitiveQueryGroup {
+			return query
+		},
+	)
+}
@@ -0,0 +1,306 @@
+package stream_index
+
+import (
+	"fmt"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+var (
+	errUnflushedChanges = newPkgError(
+		"stream index has unflushed changes",
+	)
+
+	errRetentionUnset = newPkgError(
+		"stream index retention is unset, so every version is kept",
+	)
+)
+
+// CompactionOptions control which entries compaction drops and when Flush
+// compacts on its own.
+//
+// Garbage is every version of an object that was superseded by a later one
+// and is older than the retention horizon. The latest version of every object
+// is always kept, including dormant ones, and so are inventory lists, whose
+// object ids are never reused. Without a retention nothing is garbage and the
+// index is not compacted.
+type CompactionOptions struct {
+	// Retention is how long superseded versions are kept for history queries
+	// and reverts, if RetentionSet. Zero makes every superseded version
+	// garbage.
+	Retention    time.Duration
+	RetentionSet bool
+
+	// Threshold is the garbage ratio of the pages a flush rewrote past which
+	// the flush compacts the whole index. Zero turns that off.
+	Threshold float64
+}
+
+func (options CompactionOptions) horizon() ids.Tai {
+	return ids.TaiFromTime1(time.Now().Add(-options.Retention))
+}
+
+type CompactionStats struct {
+	Entries int
+	Garbage int
+}
+
+func (stats CompactionStats) Ratio() float64 {
+	if stats.Entries == 0 {
+		return 0
+	}
+
+	return float64(stats.Garbage) / float64(stats.Entries)
+}
+
+func (stats *CompactionStats) add(other CompactionStats) {
+	stats.Entries += other.Entries
+	stats.Garbage += other.Garbage
+}
+
+func (stats CompactionStats) String() string {
+	return fmt.Sprintf(
+		"%d of %d entries are garbage (%.1f%%)",
+		stats.Garbage,
+		stats.Entries,
+		stats.Ratio()*100,
+	)
+}
+
+// countGarbage counts an entry, and the entry it supersedes as garbage if
+// there is one and it is older than the horizon.
+func (stats *CompactionStats) countGarbage(
+	options CompactionOptions,
+	horizon ids.Tai,
+	superseded ids.Tai,
+	hasSuperseded bool,
+) {
+	stats.Entries++
+
+	if options.RetentionSet && hasSuperseded && superseded.Less(horizon) {
+		stats.Garbage++
+	}
+}
+
+func (index *Index) SetCompactionOptions(options CompactionOptions) {
+	index.compactionOptions = options
+}
+
+// pageCompaction drops the garbage of one page while it is rewritten, given
+// the position of the latest entry of every object in the page. Entries are
+// read back in the same order they were counted in.
+type pageCompaction struct {
+	horizon  ids.Tai
+	latest   map[string]int
+	position int
+}
+
+func (compaction *pageCompaction) dropGarbage(
+	object *sku.Transacted,
+) (err error) {
+	position := compaction.position
+	compaction.position++
+
+	if object.GetTai().Less(compaction.horizon) &&
+		compaction.latest[object.GetObjectId().String()] != position {
+		err = errors.MakeErrStopIteration()
+		return err
+	}
+
+	return err
+}
+
+// readCompaction reads a page and returns how much of it is garbage, along with
+// the compaction that drops that garbage.
+func (index *Index) readCompaction(
+	pageIndex PageIndex,
+	options CompactionOptions,
+	horizon ids.Tai,
+) (stats CompactionStats, compaction *pageCompaction, err error) {
+	pageReader, pageReaderClose := index.makeStreamPageReader(pageIndex)
+	defer errors.Deferred(&err, pageReaderClose)
+
+	compaction = &pageCompaction{
+		horizon: horizon,
+		latest:  make(map[string]int),
+	}
+
+	tais := make(map[string]ids.Tai)
+
+	seq := makeSeqObjectFromReader(
+		pageReader.bufferedReader,
+		sku.MakePrimitiveQueryGroup(),
+	)
+
+	for object, errIter := range seq {
+		if errIter != nil {
+			err = errors.Wrap(errIter)
+			return stats, compaction, err
+		}
+
+		objectIdString := object.GetObjectId().String()
+		superseded, hasSuperseded := tais[objectIdString]
+
+		stats.countGarbage(options, horizon, superseded, hasSuperseded)
+
+		// a later entry for an object id is its later version, as when the
+		// latest sigils are set
+		compaction.latest[objectIdString] = stats.Entries - 1
+		tais[objectIdString] = object.GetTai()
+	}
+
+	return stats, compaction, err
+}
+
+// ReadCompactionStats reads every page and returns how much of the index is
+// garbage under the given options.
+func (index *Index) ReadCompactionStats(
+	options CompactionOptions,
+) (stats CompactionStats, err error) {
+	horizon := options.horizon()
+
+	for n := range index.pages {
+		var pageStats CompactionStats
+
+		if pageStats, _, err = index.readCompaction(
+			PageIndex(n),
+			options,
+			horizon,
+		); err != nil {
+			err = errors.Wrap(err)
+			return stats, err
+		}
+
+		stats.add(pageStats)
+	}
+
+	return stats, err
+}
+
+// Compact rewrites every page without its garbage, if the garbage ratio of the
+// whole index is at least threshold. The probe index is rebuilt from the kept
+// entries, since rewriting a page moves them. Dropped versions stay in the
+// inventory lists, and `reindex` brings them back. It refuses to compact
+// without a retention.
+func (index *Index) Compact(
+	options CompactionOptions,
+	threshold float64,
+	printerHeader interfaces.FuncIter[string],
+) (stats CompactionStats, compacted bool, err error) {
+	if !options.RetentionSet {
+		err = errors.Wrap(errRetentionUnset)
+		return stats, compacted, err
+	}
+
+	for n := range index.pages {
+		if index.pages[n].hasChanges() {
+			err = errors.Wrapf(errUnflushedChanges, "page: %d", n)
+			return stats, compacted, err
+		}
+	}
+
+	horizon := options.horizon()
+
+	var compactions [PageCount]*pageCompaction
+
+	for n := range index.pages {
+		var pageStats CompactionStats
+
+		if pageStats, compactions[n], err = index.readCompaction(
+			PageIndex(n),
+			options,
+			horizon,
+		); err != nil {
+			err = errors.Wrap(err)
+			return stats, compacted, err
+		}
+
+		stats.add(pageStats)
+	}
+
+	if stats.Garbage == 0 || stats.Ratio() < threshold {
+		return stats, compacted, err
+	}
+
+	if err = printerHeader(
+		fmt.Sprintf("compacting index (%s)", stats),
+	); err != nil {
+		err = errors.Wrap(err)
+		return stats, compacted, err
+	}
+
+	if err = index.probeIndex.Reset(); err != nil {
+		err = errors.Wrap(err)
+		return stats, compacted, err
+	}
+
+	waitGroup := errors.MakeWaitGroupParallel()
+
+	for n := range index.pages {
+		waitGroup.Do(
+			index.makePageRewrite(PageIndex(n), true, compactions[n]),
+		)
+	}
+
+	waitGroup.DoAfter(index.index.Flush)
+
+	if err = waitGroup.GetError(); err != nil {
+		err = errors.Wrap(err)
+		return stats, compacted, err
+	}
+
+	compacted = true
+
+	// the rewrite counted the kept entries as if they were flushed
+	for n := range index.pages {
+		index.pages[n].flushStats = CompactionStats{}
+	}
+
+	if err = printerHeader(
+		fmt.Sprintf("compacted index (%d pages)", len(index.pages)),
+	); err != nil {
+		err = errors.Wrap(err)
+		return stats, compacted, err
+	}
+
+	return stats, compacted, err
+}
+
+// compactIfPastThreshold compacts the index after a flush if the pages the
+// flush rewrote were at least as much garbage as the threshold. Only those
+// pages are counted, since they were read in full anyway.
+func (index *Index) compactIfPastThreshold(
+	printerHeader interfaces.FuncIter[string],
+) (err error) {
+	var stats CompactionStats
+
+	for n := range index.pages {
+		stats.add(index.pages[n].flushStats)
+		index.pages[n].flushStats = CompactionStats{}
+	}
+
+	threshold := index.compactionOptions.Threshold
+
+	if threshold <= 0 || !index.compactionOptions.RetentionSet {
+		return err
+	}
+
+	if stats.Garbage == 0 || stats.Ratio() < threshold {
+		return err
+	}
+
+	if _, _, err = index.Compact(
+		index.compactionOptions,
+		threshold,
+		printerHeader,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
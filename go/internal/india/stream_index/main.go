@@ -29,17 +29,19 @@ const (
 )
 
 type Index struct {
-	hashType markl.FormatHash
-	envRepo  env_repo.Env
-	sunrise  ids.Tai
-	preWrite interfaces.FuncIter[*sku.Transacted]
-	path     string
+	hashType     markl.FormatHash
+	envRepo      env_repo.Env
+	sunrise      ids.Tai
+	makePreWrite func() interfaces.FuncIter[*sku.Transacted]
+	path         string
 	domain_interfaces.NamedBlobAccess
 
 	pages [PageCount]page
 
 	historicalChanges []string
 	probeIndex
+
+	compactionOptions CompactionOptions
 }
 
 var (
@@ -49,7 +51,7 @@ var (
 
 func MakeIndex(
 	envRepo env_repo.Env,
-	preWrite interfaces.FuncIter[*sku.Transacted],
+	makePreWrite func() interfaces.FuncIter[*sku.Transacted],
 	dir string,
 	sunrise ids.Tai,
 ) (index *Index, err error) {
@@ -57,7 +59,7 @@ func MakeIndex(
 		hashType:        markl.FormatHashSha256,
 		envRepo:         envRepo,
 		sunrise:         sunrise,
-		preWrite:        preWrite,
+		makePreWrite:    makePreWrite,
 		path:            dir,
 		NamedBlobAccess: envRepo,
 	}
@@ -142,6 +144,22 @@ func (index *Index) SetNeedsFlushHistory(changes []string) {
 	index.historicalChanges = changes
 }
 
+// HasChanges returns whether anything was added or changed since the last
+// flush.
+func (index *Index) HasChanges() bool {
+	if len(index.historicalChanges) > 0 {
+		return true
+	}
+
+	for n := range index.pages {
+		if index.pages[n].hasChanges() {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (index *Index) Flush(
 	printerHeader interfaces.FuncIter[string],
 ) (err error) {
@@ -157,6 +175,11 @@ func (index *Index) Flush(
 		}
 	}
 
+	if err = index.compactIfPastThreshold(printerHeader); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
 	return err
 }
 
@@ -343,6 +366,13 @@ func (index *Index) ReadPrimitiveQuery(
 	ch := make(chan struct{}, PageCount)
 	groupBuilder := errors.MakeGroupBuilder()
 	chDone := make(chan struct{})
+	var stopOnce sync.Once
+
+	// a FuncIter returning errors.MakeErrStopIteration ends the query for all
+	// pages rather than being reported as a failure
+	stop := func() {
+		stopOnce.Do(func() { close(chDone) })
+	}
 
 	isDone := func() bool {
 		select {
@@ -354,59 +384,93 @@ func (index *Index) ReadPrimitiveQuery(
 		}
 	}
 
-	// TODO switch to errors.MakeWaitGroupParallel()
-	for n := range index.pages {
-		waitGroup.Add(1)
+	readPage := func(pageIndex PageIndex, openFileCh chan struct{}) {
+		pageReader, pageReaderClose := index.makeStreamPageReader(pageIndex)
 
-		go func(pageIndex PageIndex, openFileCh chan struct{}) {
-			pageReader, pageReaderClose := index.makeStreamPageReader(pageIndex)
-			defer errors.Deferred(&err, pageReaderClose)
+		defer func() {
+			if err := pageReaderClose(); err != nil {
+				groupBuilder.Add(err)
+			}
+		}()
 
-			ui.Log().Printf(
-				"starting query on page %d: %q",
-				pageReader.pageId.Index,
+		ui.Log().Printf(
+			"starting query on page %d: %q",
+			pageReader.pageId.Index,
+			queryGroup,
+		)
+		defer func() {
+			openFileCh <- struct{}{}
+		}()
+
+		for !isDone() {
+			seq := pageReader.makeSeq(
 				queryGroup,
+				pageReadOptions{
+					includeAddedHistory: false,
+					includeAddedLatest:  false,
+				},
 			)
-			defer waitGroup.Done()
-			defer func() {
-				openFileCh <- struct{}{}
-			}()
-
-			for !isDone() {
-				seq := pageReader.makeSeq(
-					queryGroup,
-					pageReadOptions{
-						includeAddedHistory: false,
-						includeAddedLatest:  false,
-					},
-				)
-
-				for object, err1 := range seq {
-					if err1 != nil {
-						if isDone() {
-							break
-						}
-
-						switch {
-						case errors.IsTooManyOpenFiles(err1):
-							<-openFileCh
-							continue
-
-						default:
-							groupBuilder.Add(err1)
-						}
-
-						return
+
+			for object, err1 := range seq {
+				if err1 != nil {
+					if isDone() {
+						break
 					}
 
-					if err1 = funcIter(object); err1 != nil {
+					switch {
+					case errors.IsTooManyOpenFiles(err1):
+						<-openFileCh
+						continue
+
+					default:
 						groupBuilder.Add(err1)
 					}
+
+					return
+				}
+
+				if isDone() {
+					break
+				}
+
+				if err1 = funcIter(object); err1 != nil {
+					if errors.IsStopIteration(err1) {
+						stop()
+						break
+					}
+
+					groupBuilder.Add(err1)
 				}
+			}
 
-				break
+			break
+		}
+	}
+
+	// pages are handed out in order to a fixed number of workers, so a
+	// query holds at most that many page readers and their pooled buffers
+	pageIndexes := make(chan PageIndex, PageCount)
+
+	for n := range index.pages {
+		pageIndexes <- PageIndex(n)
+	}
+
+	close(pageIndexes)
+
+	for range queryJobs(queryGroup) {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			for pageIndex := range pageIndexes {
+				if isDone() {
+					return
+				}
+
+				readPage(pageIndex, ch)
 			}
-		}(PageIndex(n), ch)
+		}()
 	}
 
 	waitGroup.Wait()
@@ -417,3 +481,15 @@ func (index *Index) ReadPrimitiveQuery(
 
 	return err
 }
+
+// queryJobs is how many pages a query reads at once: the query group's jobs
+// when it sets them, and otherwise every page.
+func queryJobs(queryGroup sku.PrimitiveQueryGroup) int {
+	withJobs, ok := queryGroup.(sku.PrimitiveQueryGroupWithJobs)
+
+	if !ok || withJobs.GetJobs() <= 0 {
+		return PageCount
+	}
+
+	return min(withJobs.GetJobs(), PageCount)
+}
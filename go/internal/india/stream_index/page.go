@@ -16,6 +16,10 @@ type page struct {
 
 	additionsHistory additions
 	additionsLatest  additions
+
+	// flushStats counts the garbage seen by flushes since the last check for
+	// compaction
+	flushStats CompactionStats
 }
 
 func (page *page) initialize(
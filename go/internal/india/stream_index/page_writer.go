@@ -40,11 +40,26 @@ type pageWriter struct {
 	cursor ohio.Cursor
 
 	latestObjects ObjectIdToObject
+
+	compaction        *pageCompaction
+	compactionOptions CompactionOptions
+	horizon           ids.Tai
+	flushStats        CompactionStats
 }
 
 func (index *Index) makePageFlush(
 	pageIndex PageIndex,
 	changesAreHistorical bool,
+) errors.FuncErr {
+	return index.makePageRewrite(pageIndex, changesAreHistorical, nil)
+}
+
+// makePageRewrite is makePageFlush that also drops the garbage of the page if
+// compaction is not nil.
+func (index *Index) makePageRewrite(
+	pageIndex PageIndex,
+	changesAreHistorical bool,
+	compaction *pageCompaction,
 ) errors.FuncErr {
 	page := &index.pages[pageIndex]
 
@@ -68,9 +83,15 @@ func (index *Index) makePageFlush(
 			pageId:      page.pageId,
 			writtenPage: page,
 			pageReader:  pageReader,
-			preWrite:    index.preWrite,
+			preWrite:    index.makePreWrite(),
 			probeIndex:  &index.probeIndex,
 			path:        page.pageId.Path(),
+			compaction:  compaction,
+		}
+
+		if index.compactionOptions.Threshold > 0 {
+			pageWriter.compactionOptions = index.compactionOptions
+			pageWriter.horizon = index.compactionOptions.horizon()
 		}
 
 		if changesAreHistorical {
@@ -84,6 +105,7 @@ func (index *Index) makePageFlush(
 		}
 
 		page.forceFullWrite = false
+		page.flushStats.add(pageWriter.flushStats)
 
 		return err
 	}
@@ -168,7 +190,14 @@ func (pageWriter *pageWriter) flushBoth(
 ) (err error) {
 	ui.Log().Printf("flushing both: %s", pageWriter.path)
 
+	var dropGarbage interfaces.FuncIter[*sku.Transacted]
+
+	if pageWriter.compaction != nil {
+		dropGarbage = pageWriter.compaction.dropGarbage
+	}
+
 	chain := quiter.MakeChain(
+		dropGarbage,
 		pageWriter.preWrite,
 		pageWriter.makeWriteOne(bufferedWriter),
 	)
@@ -331,7 +360,17 @@ func (pageWriter *pageWriter) saveToLatestMap(
 	objectId := object.GetObjectId()
 	objectIdString := objectId.String()
 
-	objectOld := pageWriter.latestObjects[objectIdString]
+	objectOld, hasSuperseded := pageWriter.latestObjects[objectIdString]
+
+	// every entry of a flushed page passes through here, so counting its
+	// garbage is free
+	pageWriter.flushStats.countGarbage(
+		pageWriter.compactionOptions,
+		pageWriter.horizon,
+		objectOld.Tai,
+		hasSuperseded,
+	)
+
 	objectOld.Cursor = pageWriter.cursor
 	objectOld.Tai = object.GetTai()
 	objectOld.Sigil = sigil
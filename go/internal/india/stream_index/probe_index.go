@@ -26,6 +26,17 @@ func (index *Index) PrintAllProbes() (err error) {
 	return err
 }
 
+// WarmProbeIndex reads the probe index's pages through and checks them (see
+// object_probe_index.Index.Warm), returning how many rows it read.
+func (index *Index) WarmProbeIndex() (rows int64, err error) {
+	if rows, err = index.probeIndex.index.Warm(); err != nil {
+		err = errors.Wrap(err)
+		return rows, err
+	}
+
+	return rows, err
+}
+
 func (index *probeIndex) Initialize(
 	envRepo env_repo.Env,
 	hashType markl.FormatHash,
@@ -78,6 +89,17 @@ func (index *probeIndex) readOneMarklIdLoc(
 	return loc, err
 }
 
+func (index *probeIndex) readOneMarklIdLocs(
+	blobIds []domain_interfaces.MarklId,
+) (locs []object_probe_index.Loc, found []bool, err error) {
+	if locs, found, err = index.index.ReadOneBatch(blobIds); err != nil {
+		err = errors.Wrap(err)
+		return locs, found, err
+	}
+
+	return locs, found, err
+}
+
 func (index *probeIndex) readManyMarklIdLoc(
 	blobId domain_interfaces.MarklId,
 ) (locs []object_probe_index.Loc, err error) {
@@ -0,0 +1,39 @@
+package stream_index
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+)
+
+type queryGroupWithJobs struct {
+	sku.PrimitiveQueryGroup
+	jobs int
+}
+
+func (queryGroup queryGroupWithJobs) GetJobs() int {
+	return queryGroup.jobs
+}
+
+func TestQueryJobs(t *testing.T) {
+	for _, testCase := range []struct {
+		queryGroup sku.PrimitiveQueryGroup
+		expected   int
+	}{
+		{queryGroup: nil, expected: PageCount},
+		{queryGroup: queryGroupWithJobs{jobs: 0}, expected: PageCount},
+		{queryGroup: queryGroupWithJobs{jobs: -1}, expected: PageCount},
+		{queryGroup: queryGroupWithJobs{jobs: 1}, expected: 1},
+		{queryGroup: queryGroupWithJobs{jobs: 4}, expected: 4},
+		{queryGroup: queryGroupWithJobs{jobs: PageCount * 2}, expected: PageCount},
+	} {
+		if actual := queryJobs(testCase.queryGroup); actual != testCase.expected {
+			t.Errorf(
+				"%+v: expected %d jobs, got %d",
+				testCase.queryGroup,
+				testCase.expected,
+				actual,
+			)
+		}
+	}
+}
@@ -39,3 +39,9 @@ func (reindexer *Reindexer) ReadOneMarklId(
 ) (ok bool) {
 	return reindexer.index.ReadOneMarklId(marklId, object)
 }
+
+func (reindexer *Reindexer) ReadManyObjectIds(
+	objectIds []string,
+) ([]*sku.Transacted, error) {
+	return reindexer.index.ReadManyObjectIds(objectIds)
+}
@@ -18,6 +18,7 @@ import (
 type streamPageReader struct {
 	*page
 	blobReader      domain_interfaces.BlobReader
+	prefetchReader  *prefetchReader
 	bufferedReader  *bufio.Reader
 	namedBlobAccess domain_interfaces.NamedBlobAccess
 
@@ -44,8 +45,13 @@ func (index *Index) makeStreamPageReader(
 
 	var repool interfaces.FuncRepool
 
-	pageReader.bufferedReader, repool = pool.GetBufferedReader(
+	pageReader.prefetchReader = makePrefetchReader(
 		pageReader.blobReader,
+		PrefetchDepth,
+	)
+
+	pageReader.bufferedReader, repool = pool.GetBufferedReader(
+		pageReader.prefetchReader,
 	)
 
 	// Open overflow sidecar if it exists.
@@ -65,6 +71,8 @@ func (index *Index) makeStreamPageReader(
 
 	return pageReader, func() error {
 		repool()
+		// the worker reads the blob reader until stopped
+		pageReader.prefetchReader.Close()
 		overflowReader.Close()
 		return pageReader.blobReader.Close()
 	}
@@ -0,0 +1,139 @@
+package stream_index_fixed
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	// PrefetchBlockEntries is how many fixed-width entries are read per block.
+	PrefetchBlockEntries = 256
+
+	// PrefetchDepth is how many blocks are read ahead of the decoder.
+	PrefetchDepth = 4
+)
+
+var prefetchBlockPool = sync.Pool{
+	New: func() any {
+		buffer := make([]byte, PrefetchBlockEntries*EntryWidth)
+		return &buffer
+	},
+}
+
+type prefetchBlock struct {
+	buffer *[]byte
+	length int
+
+	// err is returned once the block is consumed: io.EOF for the last block
+	err error
+}
+
+// prefetchReader reads blocks of a page ahead of its consumer on a worker
+// goroutine, so that decoding one block overlaps with reading the next ones.
+// Blocks are pooled and returned as they are consumed. Close stops the worker
+// and must be called before closing the underlying reader.
+type prefetchReader struct {
+	blocks  chan prefetchBlock
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+
+	current prefetchBlock
+	offset  int
+}
+
+func makePrefetchReader(reader io.Reader, depth int) *prefetchReader {
+	if depth < 1 {
+		depth = 1
+	}
+
+	prefetchReader := &prefetchReader{
+		blocks:  make(chan prefetchBlock, depth),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go prefetchReader.fill(reader)
+
+	return prefetchReader
+}
+
+func (reader *prefetchReader) fill(source io.Reader) {
+	defer close(reader.stopped)
+	defer close(reader.blocks)
+
+	for {
+		buffer := prefetchBlockPool.Get().(*[]byte)
+
+		length, err := io.ReadFull(source, *buffer)
+
+		// a short last block is not an error
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+
+		select {
+		case reader.blocks <- prefetchBlock{buffer: buffer, length: length, err: err}:
+
+		case <-reader.done:
+			prefetchBlockPool.Put(buffer)
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (reader *prefetchReader) Read(p []byte) (n int, err error) {
+	for reader.offset == reader.current.length {
+		if reader.current.err != nil {
+			err = reader.current.err
+			return n, err
+		}
+
+		reader.release()
+
+		block, ok := <-reader.blocks
+
+		if !ok {
+			err = io.EOF
+			return n, err
+		}
+
+		reader.current = block
+		reader.offset = 0
+	}
+
+	n = copy(p, (*reader.current.buffer)[reader.offset:reader.current.length])
+	reader.offset += n
+
+	return n, err
+}
+
+func (reader *prefetchReader) release() {
+	if reader.current.buffer != nil {
+		prefetchBlockPool.Put(reader.current.buffer)
+	}
+
+	reader.current = prefetchBlock{}
+	reader.offset = 0
+}
+
+// Close stops the worker, waits for it to exit, and returns every block it
+// read to the pool. It does not close the underlying reader.
+func (reader *prefetchReader) Close() error {
+	reader.once.Do(func() {
+		close(reader.done)
+
+		for block := range reader.blocks {
+			prefetchBlockPool.Put(block.buffer)
+		}
+
+		<-reader.stopped
+		reader.release()
+	})
+
+	return nil
+}
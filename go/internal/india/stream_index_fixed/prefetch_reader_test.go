@@ -0,0 +1,79 @@
+//go:build test
+
+package stream_index_fixed
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func TestPrefetchReaderReadsEverything(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	// several blocks and a short last one
+	expected := make([]byte, PrefetchBlockEntries*EntryWidth*3+EntryWidth/2)
+
+	for i := range expected {
+		expected[i] = byte(i % 251)
+	}
+
+	reader := makePrefetchReader(
+		iotest.HalfReader(bytes.NewReader(expected)),
+		2,
+	)
+	defer reader.Close()
+
+	actual, err := io.ReadAll(iotest.OneByteReader(reader))
+	t.AssertNoError(err)
+
+	if !bytes.Equal(expected, actual) {
+		t.Fatalf("expected %d bytes to be read back, got %d", len(expected), len(actual))
+	}
+
+	n, err := reader.Read(make([]byte, 1))
+	t.AssertEOF(err)
+	t.AssertEqual(0, n)
+}
+
+func TestPrefetchReaderReturnsErrors(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	expectedErr := errors.New("disk on fire")
+
+	reader := makePrefetchReader(
+		io.MultiReader(
+			bytes.NewReader([]byte("entry")),
+			iotest.ErrReader(expectedErr),
+		),
+		PrefetchDepth,
+	)
+	defer reader.Close()
+
+	actual, err := io.ReadAll(reader)
+
+	if err != expectedErr {
+		t.Fatalf("expected %q, got %v", expectedErr, err)
+	}
+
+	t.AssertEqual("entry", string(actual))
+}
+
+func TestPrefetchReaderCloseBeforeReading(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	reader := makePrefetchReader(
+		bytes.NewReader(make([]byte, PrefetchBlockEntries*EntryWidth*10)),
+		1,
+	)
+
+	t.AssertNoError(reader.Close())
+	t.AssertNoError(reader.Close())
+
+	_, err := reader.Read(make([]byte, 1))
+	t.AssertEOF(err)
+}
@@ -69,6 +69,17 @@ func (index *probeIndex) readOneMarklIdLoc(
 	return loc, err
 }
 
+func (index *probeIndex) readOneMarklIdLocs(
+	blobIds []domain_interfaces.MarklId,
+) (locs []object_probe_index.Loc, found []bool, err error) {
+	if locs, found, err = index.index.ReadOneBatch(blobIds); err != nil {
+		err = errors.Wrap(err)
+		return locs, found, err
+	}
+
+	return locs, found, err
+}
+
 func (index *probeIndex) readManyMarklIdLoc(
 	blobId domain_interfaces.MarklId,
 ) (locs []object_probe_index.Loc, err error) {
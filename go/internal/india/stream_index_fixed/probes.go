@@ -1,6 +1,9 @@
 package stream_index_fixed
 
 import (
+	"cmp"
+	"slices"
+
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
 	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
@@ -129,6 +132,98 @@ func (index *Index) ReadOneObjectId(
 	return err
 }
 
+// ReadManyObjectIds is ReadOneObjectId for many object ids at once, returning
+// nil for the ones not found. Pending additions are checked first, as with
+// ReadOneMarklIdAdded, then the rest are looked up in the probe index in one
+// batch and read one page at a time, in the order they are in the page.
+func (index *Index) ReadManyObjectIds(
+	objectIds []string,
+) (objects []*sku.Transacted, err error) {
+	objects = make([]*sku.Transacted, len(objectIds))
+
+	var digests []domain_interfaces.MarklId
+	var positions []int
+
+	for position, objectIdString := range objectIds {
+		if objectIdString == "" {
+			panic("empty object id")
+		}
+
+		digest, repool := markl.FormatHashSha256.GetMarklIdForString(
+			objectIdString,
+		)
+		defer repool()
+
+		if additionObject, ok := index.additionProbes.Get(
+			string(digest.GetBytes()),
+		); ok {
+			object, _ := sku.GetTransactedPool().GetWithRepool()
+			sku.TransactedResetter.ResetWith(object, additionObject)
+			objects[position] = object
+
+			continue
+		}
+
+		digests = append(digests, digest)
+		positions = append(positions, position)
+	}
+
+	if len(digests) == 0 {
+		return objects, err
+	}
+
+	var locs []object_probe_index.Loc
+	var found []bool
+
+	if locs, found, err = index.readOneMarklIdLocs(digests); err != nil {
+		err = errors.Wrap(err)
+		return objects, err
+	}
+
+	order := make([]int, 0, len(digests))
+
+	for i := range digests {
+		if found[i] {
+			order = append(order, i)
+		}
+	}
+
+	slices.SortFunc(order, func(left, right int) int {
+		return cmp.Or(
+			cmp.Compare(locs[left].Page, locs[right].Page),
+			cmp.Compare(locs[left].Offset, locs[right].Offset),
+		)
+	})
+
+	var pageReader probePageReader
+	pageReaderClose := func() error { return nil }
+
+	defer func() {
+		errors.Deferred(&err, pageReaderClose)
+	}()
+
+	for n, i := range order {
+		loc := locs[i]
+
+		if n == 0 || loc.Page != locs[order[n-1]].Page {
+			if err = pageReaderClose(); err != nil {
+				err = errors.Wrap(err)
+				return objects, err
+			}
+
+			pageReader, pageReaderClose = index.makeProbePageReader(loc.Page)
+		}
+
+		object, _ := sku.GetTransactedPool().GetWithRepool()
+
+		if pageReader.readOneCursor(loc.Cursor, object) {
+			objects[positions[i]] = object
+		}
+	}
+
+	return objects, err
+}
+
 func (index *Index) ReadManyObjectId(
 	objectId ids.Id,
 ) (objects []*sku.Transacted, err error) {
@@ -0,0 +1,85 @@
+package tag_blobs
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/sku_lua"
+	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+// LuaVirtualTag matches the objects for which a function of a virtual tag
+// provider returns true. Providers are scripts that return a table of
+// functions named after the virtual tags they compute, without the `%`.
+type LuaVirtualTag struct {
+	vmPool sku_lua.LuaVMPoolV2
+	name   string
+}
+
+func MakeLuaVirtualTag(
+	vmPool sku_lua.LuaVMPoolV2,
+	name string,
+) LuaVirtualTag {
+	return LuaVirtualTag{
+		vmPool: vmPool,
+		name:   name,
+	}
+}
+
+// ProvidesLuaVirtualTag returns whether a provider has a function for a
+// virtual tag.
+func ProvidesLuaVirtualTag(vmPool sku_lua.LuaVMPoolV2, name string) bool {
+	vm, vmRepool := vmPool.GetWithRepool()
+	defer vmRepool()
+
+	table, err := vm.VM.GetTopTableOrError()
+	if err != nil {
+		return false
+	}
+
+	return vm.VM.GetField(table, name).Type() == lua.LTFunction
+}
+
+func (tag LuaVirtualTag) ContainsSku(tg sku.TransactedGetter) bool {
+	vm, vmRepool := tag.vmPool.GetWithRepool()
+	defer vmRepool()
+
+	table, err := vm.VM.GetTopTableOrError()
+	if err != nil {
+		ui.Err().Print(err)
+		return false
+	}
+
+	function, ok := vm.VM.GetField(table, tag.name).(*lua.LFunction)
+
+	if !ok {
+		ui.Err().Printf("virtual tag %%%s has no function", tag.name)
+		return false
+	}
+
+	tableObject, tableObjectRepool := vm.TablePool.GetWithRepool()
+	defer tableObjectRepool()
+
+	sku_lua.ToLuaTableV2(tg, vm.VM.LState, tableObject)
+
+	vm.VM.Push(function)
+	vm.VM.Push(tableObject.Transacted)
+
+	if err = vm.VM.PCall(1, 1, nil); err != nil {
+		ui.Err().Print(err)
+		return false
+	}
+
+	retval := vm.LState.Get(-1)
+	vm.Pop(1)
+
+	if retval.Type() != lua.LTBool {
+		ui.Err().Printf(
+			"virtual tag %%%s: expected bool but got %s",
+			tag.name,
+			retval.Type(),
+		)
+		return false
+	}
+
+	return bool(retval.(lua.LBool))
+}
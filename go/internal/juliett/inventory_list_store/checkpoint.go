@@ -0,0 +1,463 @@
+package inventory_list_store
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/file_lock"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/pool"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// DefaultCheckpointInterval is how many inventory lists are created between
+// checkpoints.
+const DefaultCheckpointInterval = 500
+
+// Checkpoint is a snapshot of the latest version of every object as of the
+// first ListCount inventory lists, stored as an inventory list blob. Replaying
+// the snapshot and the lists after it gives the same heads as replaying every
+// list, without reading the blobs of the lists it covers.
+//
+// Checkpoints are appended to their own log as lines of `list-count log-size
+// list-sig blob-id`, where log-size is the size of the inventory list log
+// after the last list covered, and list-sig is that list's object signature.
+type Checkpoint struct {
+	ListCount int
+	LogSize   int64
+	ListSig   string
+	BlobId    markl.Id
+}
+
+func (checkpoint Checkpoint) String() string {
+	return fmt.Sprintf(
+		"%d %d %s %s",
+		checkpoint.ListCount,
+		checkpoint.LogSize,
+		checkpoint.ListSig,
+		checkpoint.BlobId,
+	)
+}
+
+func (checkpoint *Checkpoint) Set(value string) (err error) {
+	fields := strings.Fields(value)
+
+	if len(fields) != 4 {
+		err = errors.Errorf("expected 4 fields in checkpoint, got %q", value)
+		return err
+	}
+
+	if checkpoint.ListCount, err = strconv.Atoi(fields[0]); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if checkpoint.LogSize, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	checkpoint.ListSig = fields[2]
+
+	if err = checkpoint.BlobId.Set(fields[3]); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// ReadCheckpoints returns the checkpoints in the order they were written.
+func (store *Store) ReadCheckpoints() (checkpoints []Checkpoint, err error) {
+	var file *os.File
+
+	if file, err = files.Open(
+		store.envRepo.FileInventoryListCheckpoints(),
+	); err != nil {
+		if errors.IsNotExist(err) {
+			err = nil
+			return checkpoints, err
+		}
+
+		err = errors.Wrap(err)
+		return checkpoints, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var checkpoint Checkpoint
+
+		if err = checkpoint.Set(scanner.Text()); err != nil {
+			err = errors.Wrap(err)
+			return checkpoints, err
+		}
+
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return checkpoints, err
+	}
+
+	return checkpoints, err
+}
+
+// LatestVerifiedCheckpoint returns the most recent checkpoint whose last list
+// is still at the same position in the inventory list log and whose snapshot
+// blob exists. ok is false if there is none.
+func (store *Store) LatestVerifiedCheckpoint() (
+	checkpoint Checkpoint,
+	ok bool,
+	err error,
+) {
+	var checkpoints []Checkpoint
+
+	if checkpoints, err = store.ReadCheckpoints(); err != nil {
+		err = errors.Wrap(err)
+		return checkpoint, ok, err
+	}
+
+	if len(checkpoints) == 0 {
+		return checkpoint, ok, err
+	}
+
+	// the signature of every list a checkpoint ends at
+	listSigs := make(map[int]string, len(checkpoints))
+
+	for _, checkpoint := range checkpoints {
+		listSigs[checkpoint.ListCount] = ""
+	}
+
+	var listCount int
+
+	for list, iterErr := range store.AllInventoryLists() {
+		if iterErr != nil {
+			err = errors.Wrap(iterErr)
+			return checkpoint, ok, err
+		}
+
+		listCount++
+
+		if _, wanted := listSigs[listCount]; wanted {
+			listSigs[listCount] = list.GetMetadata().GetObjectSig().String()
+		}
+	}
+
+	for _, candidate := range slices.Backward(checkpoints) {
+		if listSigs[candidate.ListCount] != candidate.ListSig {
+			ui.Err().Printf(
+				"skipping checkpoint at inventory list %d: list signature mismatch",
+				candidate.ListCount,
+			)
+
+			continue
+		}
+
+		if !store.blobBlobStore.HasBlob(candidate.BlobId) {
+			ui.Err().Printf(
+				"skipping checkpoint at inventory list %d: missing blob %s",
+				candidate.ListCount,
+				candidate.BlobId,
+			)
+
+			continue
+		}
+
+		checkpoint = candidate
+		ok = true
+
+		return checkpoint, ok, err
+	}
+
+	return checkpoint, ok, err
+}
+
+// AllInventoryListObjectsAndContentsSince is AllInventoryListObjectsAndContents
+// resuming from a checkpoint: every list object is yielded, but only the lists
+// after the checkpoint are read, and the checkpoint's objects are yielded in
+// place of the contents of the lists it covers, with the last of those lists
+// as their list. A zero checkpoint replays every list.
+func (store *Store) AllInventoryListObjectsAndContentsSince(
+	checkpoint Checkpoint,
+) interfaces.SeqError[sku.ObjectWithList] {
+	return func(yield func(sku.ObjectWithList, error) bool) {
+		var objectWithList sku.ObjectWithList
+		var listCount int
+
+		for listObject, iterErr := range store.AllInventoryLists() {
+			objectWithList.List = listObject
+			objectWithList.Object = listObject
+
+			if iterErr != nil {
+				if !yield(objectWithList, iterErr) {
+					return
+				}
+
+				continue
+			}
+
+			listCount++
+
+			if !yield(objectWithList, nil) {
+				return
+			}
+
+			var iter sku.Seq
+
+			switch {
+			case listCount < checkpoint.ListCount:
+				continue
+
+			case listCount == checkpoint.ListCount:
+				iter = store.AllInventoryListContents(&checkpoint.BlobId)
+
+			default:
+				iter = store.AllInventoryListContents(
+					listObject.GetBlobDigest(),
+				)
+			}
+
+			for object, iterErr := range iter {
+				objectWithList.Object = object
+
+				if !yield(objectWithList, iterErr) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// WriteCheckpoint writes a checkpoint covering every inventory list, built from
+// the latest verified checkpoint and the lists after it. If no list was
+// created since that checkpoint, it is returned instead.
+func (store *Store) WriteCheckpoint() (checkpoint Checkpoint, err error) {
+	if !store.lockSmith.IsAcquired() {
+		err = file_lock.ErrLockRequired{
+			Operation: "write inventory list checkpoint",
+		}
+
+		return checkpoint, err
+	}
+
+	var previous Checkpoint
+
+	if previous, _, err = store.LatestVerifiedCheckpoint(); err != nil {
+		err = errors.Wrap(err)
+		return checkpoint, err
+	}
+
+	heads := make(map[string]*sku.Transacted)
+
+	for objectWithList, iterErr := range store.AllInventoryListObjectsAndContentsSince(
+		previous,
+	) {
+		if iterErr != nil {
+			err = errors.Wrap(iterErr)
+			return checkpoint, err
+		}
+
+		if objectWithList.Object == objectWithList.List {
+			checkpoint.ListCount++
+			checkpoint.ListSig = objectWithList.List.GetMetadata().GetObjectSig().String()
+			continue
+		}
+
+		// lists are in the order they were created, so later versions replace
+		// earlier ones
+		heads[objectWithList.Object.GetObjectId().String()], _ = objectWithList.Object.CloneTransacted()
+	}
+
+	if checkpoint.ListCount == previous.ListCount {
+		checkpoint = previous
+		return checkpoint, err
+	}
+
+	if checkpoint.LogSize, err = store.logSize(); err != nil {
+		err = errors.Wrap(err)
+		return checkpoint, err
+	}
+
+	if err = store.writeCheckpointBlob(heads, &checkpoint); err != nil {
+		err = errors.Wrap(err)
+		return checkpoint, err
+	}
+
+	if err = store.appendCheckpoint(checkpoint); err != nil {
+		err = errors.Wrap(err)
+		return checkpoint, err
+	}
+
+	return checkpoint, err
+}
+
+func (store *Store) writeCheckpointBlob(
+	heads map[string]*sku.Transacted,
+	checkpoint *Checkpoint,
+) (err error) {
+	objectIds := make([]string, 0, len(heads))
+
+	for objectId := range heads {
+		objectIds = append(objectIds, objectId)
+	}
+
+	slices.Sort(objectIds)
+
+	var blobWriter domain_interfaces.BlobWriter
+
+	if blobWriter, err = store.blobBlobStore.MakeBlobWriter(nil); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, blobWriter)
+
+	bufferedWriter, repoolBufferedWriter := pool.GetBufferedWriter(blobWriter)
+	defer repoolBufferedWriter()
+
+	if _, err = store.GetInventoryListCoderCloset().WriteBlobToWriter(
+		store.envRepo,
+		store.getType(),
+		func(yield func(*sku.Transacted, error) bool) {
+			for _, objectId := range objectIds {
+				if !yield(heads[objectId], nil) {
+					return
+				}
+			}
+		},
+		bufferedWriter,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = bufferedWriter.Flush(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	checkpoint.BlobId.ResetWithMarklId(blobWriter.GetMarklId())
+
+	return err
+}
+
+func (store *Store) appendCheckpoint(checkpoint Checkpoint) (err error) {
+	var file *os.File
+
+	if file, err = files.OpenFile(
+		store.envRepo.FileInventoryListCheckpoints(),
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0o666,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+	defer errors.Deferred(&err, file.Sync)
+
+	if _, err = io.WriteString(file, checkpoint.String()+"\n"); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (store *Store) logSize() (size int64, err error) {
+	var info os.FileInfo
+
+	if info, err = os.Stat(store.envRepo.FileInventoryListLog()); err != nil {
+		err = errors.Wrap(err)
+		return size, err
+	}
+
+	size = info.Size()
+
+	return size, err
+}
+
+// writeCheckpointIfDue writes a checkpoint once CheckpointInterval lists were
+// created since the last one. Each list is one line of the inventory list log,
+// so only the lines after the last checkpoint are counted rather than decoded.
+func (store *Store) writeCheckpointIfDue() (err error) {
+	if store.CheckpointInterval <= 0 {
+		return err
+	}
+
+	var checkpoints []Checkpoint
+
+	if checkpoints, err = store.ReadCheckpoints(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var offset int64
+
+	if len(checkpoints) > 0 {
+		offset = checkpoints[len(checkpoints)-1].LogSize
+	}
+
+	var file *os.File
+
+	if file, err = files.OpenReadOnly(store.envRepo.FileInventoryListLog()); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var tail []byte
+
+	if tail, err = io.ReadAll(file); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if countListLines(tail) < store.CheckpointInterval {
+		return err
+	}
+
+	if _, err = store.WriteCheckpoint(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// countListLines counts the lines of the inventory list log that are list
+// objects rather than its header.
+func countListLines(log []byte) (count int) {
+	for line := range bytes.Lines(log) {
+		if bytes.HasPrefix(line, []byte("[")) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -38,6 +38,10 @@ type Store struct {
 	box *box_format.BoxTransacted
 
 	ui sku.UIStorePrinters
+
+	// CheckpointInterval is how many lists Create writes between checkpoints,
+	// or zero to only write them with WriteCheckpoint.
+	CheckpointInterval int
 }
 
 var _ sku.InventoryListStore = &Store{}
@@ -67,6 +71,9 @@ func (store *Store) Initialize(
 		storeVersion:  envRepo.GetStoreVersion(),
 		blobBlobStore: envRepo.GetDefaultBlobStore(),
 		clock:         clock,
+
+		CheckpointInterval: DefaultCheckpointInterval,
+
 		box: box_format.MakeBoxTransactedArchive(
 			envRepo,
 			options_print.Options{}.WithPrintTai(true),
@@ -180,6 +187,7 @@ func (store *Store) Create(
 	object.GetMetadataMutable().GetDescriptionMutable().ResetWith(
 		openList.GetDescription(),
 	)
+	*object.GetMetadataMutable().GetProvenanceMutable() = store.makeProvenance()
 
 	tai := store.GetTai()
 
@@ -229,6 +237,11 @@ func (store *Store) Create(
 		return object, err
 	}
 
+	// the list is already committed, and the next one retries the checkpoint
+	if err := store.writeCheckpointIfDue(); err != nil {
+		ui.Err().Printf("failed to write inventory list checkpoint: %s", err)
+	}
+
 	return object, err
 }
 
@@ -341,38 +354,7 @@ func (store *Store) ReadLast() (*sku.Transacted, error) {
 }
 
 func (store *Store) AllInventoryListObjectsAndContents() interfaces.SeqError[sku.ObjectWithList] {
-	return func(yield func(sku.ObjectWithList, error) bool) {
-		var objectWithList sku.ObjectWithList
-
-		for listObject, iterErr := range store.AllInventoryLists() {
-			objectWithList.List = listObject
-			objectWithList.Object = listObject
-
-			if iterErr != nil {
-				if !yield(objectWithList, iterErr) {
-					return
-				}
-
-				continue
-			}
-
-			if !yield(objectWithList, nil) {
-				return
-			}
-
-			iter := store.AllInventoryListContents(
-				listObject.GetBlobDigest(),
-			)
-
-			for object, iterErr := range iter {
-				objectWithList.Object = object
-
-				if !yield(objectWithList, iterErr) {
-					return
-				}
-			}
-		}
-	}
+	return store.AllInventoryListObjectsAndContentsSince(Checkpoint{})
 }
 
 func (store *Store) AllInventoryListsSorted() sku.Seq {
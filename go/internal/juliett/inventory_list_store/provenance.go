@@ -0,0 +1,25 @@
+package inventory_list_store
+
+import (
+	"os"
+	"runtime/debug"
+
+	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+)
+
+// makeProvenance returns where this store commits lists from: the repo's id,
+// the machine's hostname, and the version of the running dodder binary.
+// Fields that cannot be determined are left empty.
+func (store *Store) makeProvenance() (provenance objects.Provenance) {
+	provenance.RepoId = store.envRepo.GetConfigPublic().Blob.GetRepoId().String()
+
+	if hostname, err := os.Hostname(); err == nil {
+		provenance.Hostname = hostname
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		provenance.Version = buildInfo.Main.Version
+	}
+
+	return provenance
+}
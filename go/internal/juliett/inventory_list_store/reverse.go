@@ -0,0 +1,179 @@
+package inventory_list_store
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ohio"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+const listHeaderBoundary = "---"
+
+// AllInventoryListsReverse yields the lists of the log from the one appended
+// last to the one appended first. The log is read backwards, so reading the
+// latest lists costs the same however long the log is.
+//
+// Each list is logged as a typed blob, a `---` delimited header with its
+// type followed by its encoded object, so lines are collected up to a header
+// and decoded with the coder for its type.
+func (store *Store) AllInventoryListsReverse() sku.Seq {
+	return func(yield func(*sku.Transacted, error) bool) {
+		var file *os.File
+
+		{
+			var err error
+
+			if file, err = files.OpenReadOnly(
+				store.envRepo.FileInventoryListLog(),
+			); err != nil {
+				yield(nil, errors.Wrap(err))
+				return
+			}
+		}
+
+		defer errors.ContextMustClose(store.envRepo, file)
+
+		var size int64
+
+		{
+			fileInfo, err := file.Stat()
+			if err != nil {
+				yield(nil, errors.Wrap(err))
+				return
+			}
+
+			size = fileInfo.Size()
+		}
+
+		// the lines of the list being read, last first
+		var body []string
+		var tipe ids.TypeStruct
+		inHeader := false
+
+		for line, err := range ohio.MakeReverseLineSeqFromReaderAt(file, size) {
+			if err != nil {
+				yield(nil, errors.Wrap(err))
+				return
+			}
+
+			switch {
+			case line == listHeaderBoundary && !inHeader:
+				inHeader = true
+
+			case line == listHeaderBoundary:
+				inHeader = false
+
+				list, err := store.decodeReversedList(tipe, body)
+
+				if !yield(list, err) || err != nil {
+					return
+				}
+
+				body = body[:0]
+
+			case inHeader:
+				if value, ok := strings.CutPrefix(line, "! "); ok {
+					if err := tipe.Set(value); err != nil {
+						yield(nil, errors.Wrapf(err, "Line: %q", line))
+						return
+					}
+				}
+
+			case line != "":
+				body = append(body, line)
+			}
+		}
+	}
+}
+
+func (store *Store) decodeReversedList(
+	tipe ids.TypeStruct,
+	body []string,
+) (list *sku.Transacted, err error) {
+	var buffer bytes.Buffer
+
+	for _, line := range slices.Backward(body) {
+		buffer.WriteString(line)
+		buffer.WriteByte('\n')
+	}
+
+	if list, err = store.GetInventoryListCoderCloset().ReadInventoryListObject(
+		store.envRepo,
+		tipe,
+		bufio.NewReader(&buffer),
+	); err != nil {
+		err = errors.Wrapf(err, "Type: %q", tipe)
+		return list, err
+	}
+
+	if list == nil {
+		err = errors.Errorf("empty inventory list of type %q in log", tipe)
+		return list, err
+	}
+
+	return list, err
+}
+
+// CountInventoryListObjects counts the objects in the blob of list without
+// decoding them, as every format of the blob encodes each object on a line
+// of its own after the blob's header.
+func (store *Store) CountInventoryListObjects(
+	list *sku.Transacted,
+) (count int, err error) {
+	var readCloser domain_interfaces.BlobReader
+
+	if readCloser, err = store.blobBlobStore.MakeBlobReader(
+		list.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return count, err
+	}
+
+	defer errors.DeferredCloser(&err, readCloser)
+
+	bufferedReader := bufio.NewReader(readCloser)
+	boundaries := 0
+	lineStart := true
+
+	for {
+		var line []byte
+		line, err = bufferedReader.ReadSlice('\n')
+
+		if len(line) > 0 {
+			if lineStart {
+				switch {
+				case boundaries < 2 && string(bytes.TrimSpace(line)) == listHeaderBoundary:
+					boundaries++
+
+				case boundaries == 1, len(bytes.TrimSpace(line)) == 0:
+
+				default:
+					count++
+				}
+			}
+
+			lineStart = line[len(line)-1] == '\n'
+		}
+
+		if err == bufio.ErrBufferFull {
+			err = nil
+			continue
+		} else if errors.IsEOF(err) {
+			err = nil
+			break
+		} else if err != nil {
+			err = errors.Wrap(err)
+			return count, err
+		}
+	}
+
+	return count, err
+}
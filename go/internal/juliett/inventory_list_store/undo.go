@@ -0,0 +1,274 @@
+package inventory_list_store
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/file_lock"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+var ErrNoInventoryLists = newPkgError("no inventory lists")
+
+// Orphan is an inventory list removed from the log by RemoveLast. Orphans are
+// appended to their own log as lines of `tai list-sig blob-id`, so the blobs
+// of removed lists can be found and collected.
+type Orphan struct {
+	Tai     ids.Tai
+	ListSig string
+	BlobId  markl.Id
+}
+
+func (orphan Orphan) String() string {
+	return fmt.Sprintf("%s %s %s", orphan.Tai, orphan.ListSig, orphan.BlobId)
+}
+
+func (orphan *Orphan) Set(value string) (err error) {
+	fields := strings.Fields(value)
+
+	if len(fields) != 3 {
+		err = errors.Errorf("expected 3 fields in orphan, got %q", value)
+		return err
+	}
+
+	if err = orphan.Tai.Set(fields[0]); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	orphan.ListSig = fields[1]
+
+	if err = orphan.BlobId.Set(fields[2]); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// ReadOrphans returns the removed inventory lists in the order they were
+// removed.
+func (store *Store) ReadOrphans() (orphans []Orphan, err error) {
+	var file *os.File
+
+	if file, err = files.Open(
+		store.envRepo.FileInventoryListOrphans(),
+	); err != nil {
+		if errors.IsNotExist(err) {
+			err = nil
+			return orphans, err
+		}
+
+		err = errors.Wrap(err)
+		return orphans, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var orphan Orphan
+
+		if err = orphan.Set(scanner.Text()); err != nil {
+			err = errors.Wrap(err)
+			return orphans, err
+		}
+
+		orphans = append(orphans, orphan)
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return orphans, err
+	}
+
+	return orphans, err
+}
+
+// ReadLastInLog returns the list most recently appended to the inventory list
+// log. Unlike ReadLast, which compares tais, this is the list RemoveLast
+// removes, even if a list with a later tai was imported before it.
+func (store *Store) ReadLastInLog() (list *sku.Transacted, err error) {
+	for object, iterErr := range store.AllInventoryLists() {
+		if iterErr != nil {
+			err = errors.Wrap(iterErr)
+			return list, err
+		}
+
+		list, _ = object.CloneTransacted()
+	}
+
+	if list == nil {
+		err = errors.Wrap(ErrNoInventoryLists)
+		return list, err
+	}
+
+	return list, err
+}
+
+// RemoveLast truncates the inventory list log before its last list, which must
+// have the object signature expectedSig, and records the list as an orphan.
+// Checkpoints covering it are dropped, as they include its objects. Neither
+// the list's blob nor the blobs of its objects are removed.
+func (store *Store) RemoveLast(
+	expectedSig domain_interfaces.MarklId,
+) (orphan Orphan, err error) {
+	if !store.lockSmith.IsAcquired() {
+		err = file_lock.ErrLockRequired{
+			Operation: "remove inventory list",
+		}
+
+		return orphan, err
+	}
+
+	var list *sku.Transacted
+
+	if list, err = store.ReadLastInLog(); err != nil {
+		err = errors.Wrap(err)
+		return orphan, err
+	}
+
+	if !markl.Equals(list.GetMetadata().GetObjectSig(), expectedSig) {
+		err = errors.Errorf(
+			"last inventory list is %s, not %s",
+			list.GetMetadata().GetObjectSig(),
+			expectedSig,
+		)
+
+		return orphan, err
+	}
+
+	var log []byte
+
+	if log, err = os.ReadFile(store.envRepo.FileInventoryListLog()); err != nil {
+		err = errors.Wrap(err)
+		return orphan, err
+	}
+
+	offset := lastListOffset(log)
+
+	if offset < 0 {
+		err = errors.Wrap(ErrNoInventoryLists)
+		return orphan, err
+	}
+
+	orphan = Orphan{
+		Tai:     list.GetTai(),
+		ListSig: list.GetMetadata().GetObjectSig().String(),
+	}
+
+	orphan.BlobId.ResetWithMarklId(list.GetBlobDigest())
+
+	// the orphan is recorded first so an interrupted removal leaves a list
+	// that is both logged and orphaned rather than one that is neither
+	if err = store.appendOrphan(orphan); err != nil {
+		err = errors.Wrap(err)
+		return orphan, err
+	}
+
+	if err = store.dropCheckpointsAfter(offset); err != nil {
+		err = errors.Wrap(err)
+		return orphan, err
+	}
+
+	if err = os.Truncate(store.envRepo.FileInventoryListLog(), offset); err != nil {
+		err = errors.Wrap(err)
+		return orphan, err
+	}
+
+	return orphan, err
+}
+
+func (store *Store) appendOrphan(orphan Orphan) (err error) {
+	var file *os.File
+
+	if file, err = files.OpenFile(
+		store.envRepo.FileInventoryListOrphans(),
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0o666,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+	defer errors.Deferred(&err, file.Sync)
+
+	if _, err = io.WriteString(file, orphan.String()+"\n"); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// dropCheckpointsAfter rewrites the checkpoint log without the checkpoints
+// covering any of the inventory list log past logSize.
+func (store *Store) dropCheckpointsAfter(logSize int64) (err error) {
+	var checkpoints []Checkpoint
+
+	if checkpoints, err = store.ReadCheckpoints(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var kept strings.Builder
+	dropped := false
+
+	for _, checkpoint := range checkpoints {
+		if checkpoint.LogSize > logSize {
+			dropped = true
+			continue
+		}
+
+		kept.WriteString(checkpoint.String() + "\n")
+	}
+
+	if !dropped {
+		return err
+	}
+
+	// written to a temp file and renamed, so that an interrupted rewrite
+	// leaves the old checkpoints rather than a truncated log
+	if err = files.WriteFileAtomic(
+		store.envRepo.FileInventoryListCheckpoints(),
+		[]byte(kept.String()),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// lastListOffset returns the offset of the last line of the inventory list log
+// that is a list object, or -1 if there is none.
+func lastListOffset(log []byte) (offset int64) {
+	offset = -1
+
+	var position int64
+
+	for line := range bytes.Lines(log) {
+		if bytes.HasPrefix(line, []byte("[")) {
+			offset = position
+		}
+
+		position += int64(len(line))
+	}
+
+	return offset
+}
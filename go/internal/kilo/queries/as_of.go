@@ -0,0 +1,101 @@
+package queries
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/pool"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// primitiveAsOf reads every version of the genres of a query, so that the
+// executor can pick the one that was the latest at its AsOf.
+type primitiveAsOf struct {
+	primitive
+}
+
+func (qg primitiveAsOf) Get(g genres.Genre) (sku.QueryWithSigilAndObjectId, bool) {
+	query, ok := qg.primitive.Get(g)
+
+	if !ok {
+		return query, ok
+	}
+
+	return queryWithHistory{QueryWithSigilAndObjectId: query}, ok
+}
+
+func (qg primitiveAsOf) GetSigil() (sigil ids.Sigil) {
+	sigil = qg.primitive.GetSigil()
+	sigil.Add(ids.SigilHistory)
+	return sigil
+}
+
+type queryWithHistory struct {
+	sku.QueryWithSigilAndObjectId
+}
+
+func (query queryWithHistory) GetSigil() (sigil ids.Sigil) {
+	sigil = query.QueryWithSigilAndObjectId.GetSigil()
+	sigil.Add(ids.SigilHistory)
+	return sigil
+}
+
+// executeInternalQueryAsOf replays the stream index up to AsOf, keeping the
+// latest version of each object, and matches those against the query once
+// every page has been read, so that later versions matching it are ignored.
+// Objects are emitted ordered by genre and object id.
+func (e *Executor) executeInternalQueryAsOf(
+	out interfaces.FuncIter[*sku.Transacted],
+) (err error) {
+	var lock sync.Mutex
+	latest := make(map[string]*sku.Transacted)
+	var repools pool.Repools
+	defer repools.Repool()
+
+	if err = e.FuncPrimitiveQuery(
+		primitiveAsOf{primitive: primitive{e.Query}},
+		func(object *sku.Transacted) (err error) {
+			if object.GetTai().After(e.AsOf) {
+				return err
+			}
+
+			key := genres.Must(object).String() + " " + object.GetObjectId().String()
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			if existing, ok := latest[key]; !ok {
+				clone, repool := object.CloneTransacted()
+				latest[key] = clone
+				repools = append(repools, repool)
+			} else if existing.GetTai().Before(object.GetTai()) {
+				sku.TransactedResetter.ResetWith(existing, object)
+			}
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(latest)) {
+		object := latest[key]
+
+		if !e.containsSku(object) {
+			continue
+		}
+
+		if err = out(object); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,89 @@
+//go:build test
+
+package queries
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func TestExecuteAsOf(t1 *testing.T) {
+	t := &ui.T{T: t1}
+
+	type version struct {
+		objectId, tai string
+	}
+
+	versions := []version{
+		{"one/uno", "30"},
+		{"one/uno", "10"},
+		{"two/dos", "40"},
+		{"one/uno", "20"},
+	}
+
+	var asOf ids.Tai
+
+	if err := asOf.Set("25"); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	query := &Query{matchOnEmpty: true, AsOf: asOf}
+
+	executor := Executor{
+		primitive: primitive{query},
+		ExecutionInfo: ExecutionInfo{
+			FuncPrimitiveQuery: func(
+				group sku.PrimitiveQueryGroup,
+				out interfaces.FuncIter[*sku.Transacted],
+			) (err error) {
+				if !group.GetSigil().IncludesHistory() {
+					t.Errorf("expected every version to be read")
+				}
+
+				for _, version := range versions {
+					object, repool := sku.GetTransactedPool().GetWithRepool()
+					defer repool()
+
+					if err = object.ObjectId.Set(version.objectId); err != nil {
+						return err
+					}
+
+					if err = object.GetMetadataMutable().GetTaiMutable().Set(
+						version.tai,
+					); err != nil {
+						return err
+					}
+
+					if err = out(object); err != nil {
+						return err
+					}
+				}
+
+				return err
+			},
+		},
+	}
+
+	var emitted []string
+
+	if err := executor.ExecuteTransacted(
+		func(object *sku.Transacted) (err error) {
+			emitted = append(
+				emitted,
+				object.GetObjectId().String()+"@"+object.GetTai().String(),
+			)
+
+			return err
+		},
+	); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	if len(emitted) != 1 || emitted[0] != "one/uno@20.0" {
+		t.Errorf("expected only one/uno as of 25 but got %q", emitted)
+	}
+}
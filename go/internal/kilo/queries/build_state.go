@@ -1,6 +1,8 @@
 package queries
 
 import (
+	"strings"
+
 	"code.linenisgreat.com/dodder/go/internal/_/doddish"
 	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
@@ -29,6 +31,7 @@ type buildState struct {
 	luaVMPoolBuilder        *lua.VMPoolBuilder
 	pinnedObjectIds         []pinnedObjectId
 	pinnedExternalObjectIds []sku.ExternalObjectId
+	pendingTags             []pendingTag
 	workspaceStore          store_workspace.Store
 
 	workspaceStoreAcceptedQueryComponent bool
@@ -164,6 +167,11 @@ func (buildState *buildState) build(
 		}
 	}
 
+	if err = buildState.resolveTags(); err != nil {
+		err = errors.Wrap(err)
+		return err, latent
+	}
+
 	buildState.addDefaultsIfNecessary()
 
 	if err = buildState.group.reduce(buildState); err != nil {
@@ -354,16 +362,21 @@ LOOP:
 				stack.Last().Add(exp)
 
 			case genres.Tag:
-				var tag sku.Query
+				var tag ids.TagStruct
 
-				if tag, err = buildState.makeTagExp(&objectId); err != nil {
+				if err = tag.Set(objectId.GetObjectId().String()); err != nil {
 					err = errors.Wrap(err)
 					return err
 				}
 
-				exp := buildState.makeExp(isNegated, isExact, tag)
+				exp := buildState.makeExp(isNegated, isExact, &objectId)
 				stack.Last().Add(exp)
 
+				buildState.pendingTags = append(
+					buildState.pendingTags,
+					pendingTag{exp: exp, objectId: &objectId},
+				)
+
 			case genres.Type:
 				var tipe ids.SeqId
 
@@ -481,73 +494,118 @@ func (buildState *buildState) parseSigilsAndGenres(
 	return err
 }
 
+// pendingTag is a tag expression whose tag object is not read yet. Tags are
+// read in one batch once every token is parsed, and tags whose blobs are
+// queryable replace their object id with a CompoundMatch. Virtual tags are
+// resolved through the builder's VirtualTags instead, since they have no
+// objects.
+type pendingTag struct {
+	exp      *expTagsOrTypes
+	objectId *ObjectId
+}
+
 // TODO use new generic and typed blobs
-func (buildState *buildState) makeTagOrLuaTag(
-	objectId *ObjectId,
-) (exp sku.Query, err error) {
-	exp = objectId
+func (buildState *buildState) resolveTags() (err error) {
+	pendingTags := buildState.pendingTags
+	buildState.pendingTags = nil
 
-	if buildState.builder.objectProbeIndex == nil {
-		return exp, err
+	if pendingTags, err = buildState.resolveVirtualTags(pendingTags); err != nil {
+		err = errors.Wrap(err)
+		return err
 	}
 
-	object, objectRepool := sku.GetTransactedPool().GetWithRepool()
-	defer objectRepool()
+	if buildState.builder.objectProbeIndex == nil || len(pendingTags) == 0 {
+		return err
+	}
 
-	if err = buildState.builder.objectProbeIndex.ReadOneObjectId(
-		objectId,
-		object,
-	); err != nil {
-		if errors.IsErrNotFound(err) {
-			err = nil
-		} else {
-			err = errors.Wrap(err)
-		}
+	objectIds := make([]string, len(pendingTags))
 
-		return exp, err
+	for i, pending := range pendingTags {
+		objectIds[i] = pending.objectId.String()
 	}
 
-	var tagBlob tag_blobs.Blob
+	var objects []*sku.Transacted
 
-	if tagBlob, _, err = buildState.builder.typedBlobStore.Tag.GetBlob(
-		object,
+	if objects, err = buildState.builder.objectProbeIndex.ReadManyObjectIds(
+		objectIds,
 	); err != nil {
 		err = errors.Wrap(err)
-		return exp, err
+		return err
 	}
 
-	var matcherBlob sku.Queryable
+	for i, pending := range pendingTags {
+		object := objects[i]
+
+		if object == nil {
+			continue
+		}
+
+		var tagBlob tag_blobs.Blob
+
+		if tagBlob, _, err = buildState.builder.typedBlobStore.Tag.GetBlob(
+			object,
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
 
-	{
-		var ok bool
+		matcherBlob, ok := tagBlob.(sku.Queryable)
 
-		if matcherBlob, ok = tagBlob.(sku.Queryable); !ok {
-			return exp, err
+		if !ok {
+			continue
 		}
-	}
 
-	exp = &CompoundMatch{Queryable: matcherBlob, ObjectId: objectId}
+		pending.exp.Children[0] = &CompoundMatch{
+			Queryable: matcherBlob,
+			ObjectId:  pending.objectId,
+		}
+	}
 
-	return exp, err
+	return err
 }
 
-func (buildState *buildState) makeTagExp(
-	objectId *ObjectId,
-) (exp sku.Query, err error) {
-	// TODO use b.blobs to read tag blob and find filter if necessary
-	var tag ids.TagStruct
+// resolveVirtualTags replaces the object ids of virtual tags that have a
+// provider with its matcher, and returns the remaining tags.
+func (buildState *buildState) resolveVirtualTags(
+	pendingTags []pendingTag,
+) (remaining []pendingTag, err error) {
+	virtualTags := buildState.builder.virtualTags
 
-	if err = tag.Set(objectId.GetObjectId().String()); err != nil {
-		err = errors.Wrap(err)
-		return exp, err
-	}
+	for _, pending := range pendingTags {
+		var tag ids.TagStruct
 
-	if exp, err = buildState.makeTagOrLuaTag(objectId); err != nil {
-		err = errors.Wrap(err)
-		return exp, err
+		if err = tag.Set(pending.objectId.GetObjectId().String()); err != nil {
+			err = errors.Wrap(err)
+			return remaining, err
+		}
+
+		if !tag.IsVirtual() || virtualTags == nil {
+			remaining = append(remaining, pending)
+			continue
+		}
+
+		var matcher sku.Queryable
+
+		if matcher, err = virtualTags.GetVirtualTag(
+			strings.TrimPrefix(tag.String(), "%"),
+		); err != nil {
+			err = errors.Wrap(err)
+			return remaining, err
+		}
+
+		if matcher == nil {
+			continue
+		}
+
+		pending.exp.Children[0] = &CompoundMatch{
+			Queryable: matcher,
+			ObjectId:  pending.objectId,
+		}
+
+		buildState.group.virtualTagsActive = true
 	}
 
-	return exp, err
+	return remaining, err
 }
 
 func (buildState *buildState) makeExp(
@@ -10,7 +10,6 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/juliett/typed_blob_store"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
-	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 )
 
 func MakeBuilder(
@@ -47,7 +46,9 @@ type Builder struct {
 	debug                   bool
 	requireNonEmptyQuery    bool
 	defaultQuery            string
+	ignoreDefaultQuery      bool
 	workspaceEnabled        bool
+	virtualTags             VirtualTags
 
 	options options
 }
@@ -222,7 +223,7 @@ func (builder *Builder) build(state *buildState, values ...string) (err error) {
 		return err
 	}
 
-	if builder.defaultQuery == "" {
+	if builder.defaultQuery == "" || builder.ignoreDefaultQuery {
 		return err
 	}
 
@@ -237,7 +238,7 @@ func (builder *Builder) build(state *buildState, values ...string) (err error) {
 
 	state.group.defaultQuery = defaultQueryGroupState.group
 
-	ui.Log().Print(state.group.StringDebug())
+	logger.Debug("built query", "query", state.group.StringDebug())
 
 	return err
 }
@@ -153,6 +153,19 @@ func (option builderOptionHidden) Apply(builder *Builder) *Builder {
 	return builder
 }
 
+type builderOptionIgnoreDefaultQuery struct{}
+
+// BuilderOptionIgnoreDefaultQuery keeps the workspace's default query from
+// narrowing the query, regardless of the order the options are applied in.
+func BuilderOptionIgnoreDefaultQuery() builderOptionIgnoreDefaultQuery {
+	return builderOptionIgnoreDefaultQuery{}
+}
+
+func (option builderOptionIgnoreDefaultQuery) Apply(builder *Builder) *Builder {
+	builder.ignoreDefaultQuery = true
+	return builder
+}
+
 type builderOptionDoNotMatchEmpty struct{}
 
 func BuilderOptionDoNotMatchEmpty() builderOptionDoNotMatchEmpty {
@@ -211,3 +224,16 @@ func (option builderOptionExpanders) Apply(builder *Builder) *Builder {
 	builder.expanders = option.expanders
 	return builder
 }
+
+type builderOptionVirtualTags struct {
+	virtualTags VirtualTags
+}
+
+func BuilderOptionVirtualTags(virtualTags VirtualTags) builderOptionVirtualTags {
+	return builderOptionVirtualTags{virtualTags: virtualTags}
+}
+
+func (option builderOptionVirtualTags) Apply(builder *Builder) *Builder {
+	builder.virtualTags = option.virtualTags
+	return builder
+}
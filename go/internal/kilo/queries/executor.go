@@ -29,6 +29,9 @@ type (
 		WorkspaceStore
 		sku.FuncPrimitiveQuery
 		sku.FuncReadOneInto
+
+		// consulted by internal queries of latest versions when set
+		ResultCache *ResultCache
 	}
 )
 
@@ -44,6 +47,7 @@ func MakeExecutorWithExternalStore(
 	fpq sku.FuncPrimitiveQuery,
 	froi sku.FuncReadOneInto,
 	workspaceStore WorkspaceStore,
+	resultCache *ResultCache,
 ) Executor {
 	return Executor{
 		primitive: primitive{query},
@@ -51,6 +55,7 @@ func MakeExecutorWithExternalStore(
 			WorkspaceStore:     workspaceStore,
 			FuncPrimitiveQuery: fpq,
 			FuncReadOneInto:    froi,
+			ResultCache:        resultCache,
 		},
 	}
 }
@@ -185,17 +190,32 @@ func (e *Executor) ExecuteTransacted(
 		return err
 	}
 
+	if err = e.Pagination.Validate(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	out, flush, release := e.Pagination.MakeFuncIter(out)
+	defer release()
+
 	// TODO tease apart the reliance on dotOperatorActive here
-	if e.dotOperatorActive && e.WorkspaceStore != nil {
-		if err = e.executeExternalQuery(out); err != nil {
-			err = errors.Wrap(err)
-			return err
-		}
+	if !e.AsOf.IsEmpty() {
+		err = e.executeInternalQueryAsOf(out)
+	} else if e.dotOperatorActive && e.WorkspaceStore != nil {
+		err = e.executeExternalQuery(out)
 	} else {
-		if err = e.executeInternalQuery(out); err != nil {
-			err = errors.Wrap(err)
-			return err
-		}
+		err = e.executeInternalQueryCached(out)
+	}
+
+	// the paginator stops iteration once its window is full
+	if err != nil && !errors.IsStopIteration(err) {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = flush(); err != nil {
+		err = errors.Wrap(err)
+		return err
 	}
 
 	return err
@@ -263,9 +283,22 @@ func (e *Executor) executeExternalQuery(
 func (e *Executor) executeInternalQuerySkuType(
 	out interfaces.FuncIter[sku.SkuType],
 ) (err error) {
-	if err = e.FuncPrimitiveQuery(
-		primitive{Query: e.Query},
-		e.makeEmitSkuSigilLatestSkuType(out),
+	if err = e.executeInternalQueryCached(
+		func(internal *sku.Transacted) (err error) {
+			co, coRepool := sku.GetCheckedOutPool().GetWithRepool()
+			defer coRepool()
+
+			sku.TransactedResetter.ResetWith(co.GetSkuExternal(), internal)
+
+			co.SetState(checked_out_state.Internal)
+
+			if err = out(co); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			return err
+		},
 	); err != nil {
 		err = errors.Wrap(err)
 		return err
@@ -321,46 +354,6 @@ func (e *Executor) makeEmitSkuSigilLatest(
 	}
 }
 
-func (e *Executor) makeEmitSkuSigilLatestSkuType(
-	out interfaces.FuncIter[sku.SkuType],
-) interfaces.FuncIter[*sku.Transacted] {
-	return func(internal *sku.Transacted) (err error) {
-		g := genres.Must(internal.GetGenre())
-
-		if !e.containsSku(internal) {
-			return err
-		}
-
-		m, ok := e.Get(g)
-
-		if !ok {
-			return err
-		}
-
-		if m.GetSigil().IncludesExternal() {
-			// TODO update External
-			if err = e.UpdateTransacted(internal); err != nil {
-				err = errors.Wrap(err)
-				return err
-			}
-		}
-
-		co, coRepool := sku.GetCheckedOutPool().GetWithRepool()
-		defer coRepool()
-
-		sku.TransactedResetter.ResetWith(co.GetSkuExternal(), internal)
-
-		co.SetState(checked_out_state.Internal)
-
-		if err = out(co); err != nil {
-			err = errors.Wrap(err)
-			return err
-		}
-
-		return err
-	}
-}
-
 func (executor *Executor) applyDotOperatorIfNecessary() (err error) {
 	if !executor.isDotOperatorActive() {
 		return err
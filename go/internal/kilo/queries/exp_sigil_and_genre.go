@@ -9,7 +9,6 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
-	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 )
 
 type expSigilAndGenre struct {
@@ -325,6 +324,40 @@ func (expSigilAndGenre *expSigilAndGenre) ContainsSku(
 	return ok
 }
 
+// MayContainSku is ContainsSku for objects of which only the object id, tags
+// and type are decoded. It skips the hidden query, whose dormant counter must
+// see every object once, and is true whenever it cannot tell, like for blob
+// digests, virtual tags, or objects that are updated from a workspace before
+// they are matched.
+func (expSigilAndGenre *expSigilAndGenre) MayContainSku(
+	objectGetter sku.TransactedGetter,
+) (ok bool) {
+	if expSigilAndGenre.IncludesExternal() ||
+		!expSigilAndGenre.expTagsOrTypes.isPrefilterable() {
+		ok = true
+		return ok
+	}
+
+	object := objectGetter.GetSku()
+
+	if !expSigilAndGenre.Genre.ContainsOneOf(genres.Must(object)) {
+		return ok
+	}
+
+	if _, ok = expSigilAndGenre.expObjectIds.internal[object.GetObjectId().String()]; ok {
+		return ok
+	}
+
+	if len(expSigilAndGenre.expTagsOrTypes.Children) == 0 {
+		ok = len(expSigilAndGenre.expObjectIds.internal) == 0
+		return ok
+	}
+
+	ok = expSigilAndGenre.expTagsOrTypes.ContainsSku(objectGetter)
+
+	return ok
+}
+
 func (expSigilAndGenre *expSigilAndGenre) ContainsExternalSku(
 	el sku.ExternalLike,
 ) (ok bool) {
@@ -343,9 +376,13 @@ func (expSigilAndGenre *expSigilAndGenre) ContainsExternalSku(
 	}
 
 	externalObjectIdString := el.GetExternalObjectId().String()
-	ui.Log().Print(
+	logger.Debug(
+		"matching external object id",
+		"external_object_id",
 		externalObjectIdString,
+		"external",
 		expSigilAndGenre.expObjectIds.external,
+		"internal",
 		expSigilAndGenre.expObjectIds.internal,
 	)
 
@@ -227,6 +227,29 @@ func (expression *expTagsOrTypes) ContainsSku(
 	return ok
 }
 
+// isPrefilterable is true when every child matches on an object's id, tags
+// or type only, rather than its blob digests or a virtual tag's provider.
+func (expression *expTagsOrTypes) isPrefilterable() bool {
+	for _, child := range expression.Children {
+		switch child := child.(type) {
+		case *expTagsOrTypes:
+			if !child.isPrefilterable() {
+				return false
+			}
+
+		case *ObjectId:
+			if child.GetGenre() == genres.Blob {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
 func (expression *expTagsOrTypes) containsMatchableAnd(
 	tg sku.TransactedGetter,
 ) bool {
@@ -0,0 +1,5 @@
+package queries
+
+import "code.linenisgreat.com/dodder/go/lib/alfa/structured_log"
+
+var logger = structured_log.Component("query")
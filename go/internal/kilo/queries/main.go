@@ -14,17 +14,33 @@ import (
 type Query struct {
 	sku.ExternalQueryOptions
 
+	// applied by Executor.ExecuteTransacted
+	Pagination Pagination
+
+	// AsOf, when set, makes Executor.ExecuteTransacted match the version of
+	// each object that was the latest at that tai
+	AsOf ids.Tai
+
+	// Jobs bounds how many stream index pages are read and matched at once,
+	// 0 for all of them
+	Jobs int
+
 	hidden           sku.Query
 	optimizedQueries map[genres.Genre]*expSigilAndGenre
 	userQueries      map[ids.Genre]*expSigilAndGenre
 	types            interfaces.SetMutable[ids.TypeStruct]
 
 	dotOperatorActive bool
+	virtualTagsActive bool
 	matchOnEmpty      bool
 
 	defaultQuery *Query
 }
 
+func (query *Query) GetJobs() int {
+	return query.Jobs
+}
+
 func (query *Query) GetDefaultQuery() *Query {
 	return query.defaultQuery
 }
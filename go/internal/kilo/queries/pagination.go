@@ -0,0 +1,332 @@
+package queries
+
+import (
+	pkg_heap "container/heap"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+type SortKey string
+
+const (
+	SortKeyNone        = SortKey("")
+	SortKeyTai         = SortKey("tai")
+	SortKeyObjectId    = SortKey("object-id")
+	SortKeyDescription = SortKey("description")
+)
+
+// Sort is a flag value of the form `key` or `-key` (descending). Ties are
+// broken by Tai and then object id so that pages are stable.
+type Sort struct {
+	Key        SortKey
+	Descending bool
+}
+
+var _ interfaces.FlagValue = &Sort{}
+
+func (sort Sort) String() string {
+	if sort.Descending {
+		return "-" + string(sort.Key)
+	}
+
+	return string(sort.Key)
+}
+
+func (sort *Sort) Set(value string) (err error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	sort.Descending = strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	switch key := SortKey(value); key {
+	case SortKeyNone, SortKeyTai, SortKeyObjectId, SortKeyDescription:
+		sort.Key = key
+
+	default:
+		err = errors.BadRequestf(
+			"unsupported sort key: %q. Expected one of %q",
+			value,
+			[]SortKey{SortKeyTai, SortKeyObjectId, SortKeyDescription},
+		)
+
+		return err
+	}
+
+	return err
+}
+
+func (sort Sort) IsEmpty() bool {
+	return sort.Key == SortKeyNone
+}
+
+func (sort Sort) compare(left, right *sku.Transacted) (result int) {
+	switch sort.Key {
+	case SortKeyObjectId:
+		result = strings.Compare(
+			left.GetObjectId().String(),
+			right.GetObjectId().String(),
+		)
+
+	case SortKeyDescription:
+		result = strings.Compare(
+			left.GetMetadata().GetDescription().String(),
+			right.GetMetadata().GetDescription().String(),
+		)
+	}
+
+	if result == 0 {
+		result = taiCompare(left, right)
+	}
+
+	if result == 0 && sort.Key != SortKeyObjectId {
+		result = strings.Compare(
+			left.GetObjectId().String(),
+			right.GetObjectId().String(),
+		)
+	}
+
+	if sort.Descending {
+		result = -result
+	}
+
+	return result
+}
+
+func taiCompare(left, right *sku.Transacted) int {
+	switch result := left.GetTai().SortCompare(right.GetTai()); {
+	case result.IsLess():
+		return -1
+
+	case result.IsEqual():
+		return 0
+
+	default:
+		return 1
+	}
+}
+
+// Pagination restricts the output of a query to a window of matches. Without
+// a Sort, the first Limit matches are output in the order they are read. With
+// a Sort, only Offset+Limit matches are retained at any time, or every match
+// without a Limit.
+type Pagination struct {
+	Limit  int
+	Offset int
+	Sort   Sort
+}
+
+func (pagination Pagination) IsEmpty() bool {
+	return pagination.Limit <= 0 &&
+		pagination.Offset <= 0 &&
+		pagination.Sort.IsEmpty()
+}
+
+// Validate rejects windows that are not stable. Matches are read in no
+// particular order, so skipping some needs a Sort.
+func (pagination Pagination) Validate() (err error) {
+	switch {
+	case pagination.Limit < 0:
+		err = errors.BadRequestf("-limit must not be negative")
+
+	case pagination.Offset < 0:
+		err = errors.BadRequestf("-offset must not be negative")
+
+	case pagination.Offset > 0 && pagination.Sort.IsEmpty():
+		err = errors.BadRequestf(
+			"-offset requires -sort, as matches are read in no particular order",
+		)
+	}
+
+	return err
+}
+
+func (pagination Pagination) String() string {
+	return fmt.Sprintf(
+		"limit: %d, offset: %d, sort: %q",
+		pagination.Limit,
+		pagination.Offset,
+		pagination.Sort,
+	)
+}
+
+// MakeFuncIter wraps output so that it only receives the paginated window. The
+// returned flush must be called after the query completes, as sorted matches
+// are only emitted then. The returned release repools the matches that were
+// retained but not flushed, so it should be deferred to cover queries that
+// fail before flush. The returned FuncIter is safe for concurrent use. The
+// pagination is expected to have passed Validate.
+func (pagination Pagination) MakeFuncIter(
+	output interfaces.FuncIter[*sku.Transacted],
+) (
+	funcIter interfaces.FuncIter[*sku.Transacted],
+	flush errors.FuncErr,
+	release interfaces.FuncRepool,
+) {
+	if pagination.IsEmpty() {
+		return output, func() error { return nil }, func() {}
+	}
+
+	paginator := &paginator{
+		Pagination: pagination,
+		output:     output,
+	}
+
+	if pagination.Sort.IsEmpty() {
+		return paginator.emitInOrder, func() error { return nil }, func() {}
+	}
+
+	paginator.heap.sort = pagination.Sort
+
+	return paginator.retain, paginator.flush, paginator.release
+}
+
+type paginator struct {
+	Pagination
+
+	lock   sync.Mutex
+	output interfaces.FuncIter[*sku.Transacted]
+	seen   int
+	heap   paginatorHeap
+}
+
+func (paginator *paginator) emitInOrder(object *sku.Transacted) (err error) {
+	paginator.lock.Lock()
+	defer paginator.lock.Unlock()
+
+	idx := paginator.seen
+	paginator.seen++
+
+	if paginator.Limit > 0 && idx >= paginator.Limit {
+		err = errors.MakeErrStopIteration()
+		return err
+	}
+
+	if err = paginator.output(object); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (paginator *paginator) retain(object *sku.Transacted) (err error) {
+	paginator.lock.Lock()
+	defer paginator.lock.Unlock()
+
+	if paginator.Limit > 0 &&
+		paginator.heap.Len() == paginator.Offset+paginator.Limit {
+		worst := paginator.heap.elements[0]
+
+		if paginator.Sort.compare(object, worst.object) >= 0 {
+			return err
+		}
+
+		sku.TransactedResetter.ResetWith(worst.object, object)
+		pkg_heap.Fix(&paginator.heap, 0)
+
+		return err
+	}
+
+	clone, repool := sku.GetTransactedPool().GetWithRepool()
+	sku.TransactedResetter.ResetWith(clone, object)
+
+	pkg_heap.Push(
+		&paginator.heap,
+		paginatorElement{object: clone, repool: repool},
+	)
+
+	return err
+}
+
+func (paginator *paginator) flush() (err error) {
+	paginator.lock.Lock()
+	defer paginator.lock.Unlock()
+
+	elements := paginator.heap.elements
+	paginator.heap.elements = nil
+
+	defer func() {
+		for _, element := range elements {
+			element.repool()
+		}
+	}()
+
+	slices.SortFunc(
+		elements,
+		func(left, right paginatorElement) int {
+			return paginator.Sort.compare(left.object, right.object)
+		},
+	)
+
+	for idx, element := range elements {
+		if idx < paginator.Offset {
+			continue
+		}
+
+		if err = paginator.output(element.object); err != nil {
+			if errors.IsStopIteration(err) {
+				err = nil
+			} else {
+				err = errors.Wrap(err)
+			}
+
+			return err
+		}
+	}
+
+	return err
+}
+
+func (paginator *paginator) release() {
+	paginator.lock.Lock()
+	defer paginator.lock.Unlock()
+
+	for _, element := range paginator.heap.elements {
+		element.repool()
+	}
+
+	paginator.heap.elements = nil
+}
+
+type paginatorElement struct {
+	object *sku.Transacted
+	repool interfaces.FuncRepool
+}
+
+// paginatorHeap is a max-heap by sort order, so that the root is the worst
+// retained match and can be evicted in O(log n).
+type paginatorHeap struct {
+	sort     Sort
+	elements []paginatorElement
+}
+
+func (heap *paginatorHeap) Len() int {
+	return len(heap.elements)
+}
+
+func (heap *paginatorHeap) Less(left, right int) bool {
+	return heap.sort.compare(
+		heap.elements[left].object,
+		heap.elements[right].object,
+	) > 0
+}
+
+func (heap *paginatorHeap) Swap(left, right int) {
+	heap.elements[left], heap.elements[right] = heap.elements[right], heap.elements[left]
+}
+
+func (heap *paginatorHeap) Push(element any) {
+	heap.elements = append(heap.elements, element.(paginatorElement))
+}
+
+func (heap *paginatorHeap) Pop() any {
+	last := len(heap.elements) - 1
+	element := heap.elements[last]
+	heap.elements = heap.elements[:last]
+	return element
+}
@@ -0,0 +1,197 @@
+//go:build test
+
+package queries
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func makePaginationTestObjects(t *ui.T, count int) (objects []*sku.Transacted) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// reversed so that read order differs from tai order
+	for i := count - 1; i >= 0; i-- {
+		var object sku.Transacted
+
+		if err := object.ObjectId.Set(fmt.Sprintf("tag-%02d", i)); err != nil {
+			t.Fatalf("failed to set object id: %s", err)
+		}
+
+		object.SetTai(ids.TaiFromTime1(start.Add(time.Duration(i) * time.Second)))
+		objects = append(objects, &object)
+	}
+
+	return objects
+}
+
+func runPagination(
+	t *ui.T,
+	pagination Pagination,
+	objects []*sku.Transacted,
+) (actual []string) {
+	output := func(object *sku.Transacted) (err error) {
+		actual = append(actual, object.GetObjectId().String())
+		return err
+	}
+
+	funcIter, flush, release := pagination.MakeFuncIter(output)
+	defer release()
+
+	for _, object := range objects {
+		if err := funcIter(object); err != nil {
+			if errors.IsStopIteration(err) {
+				break
+			}
+
+			t.Fatalf("expected no error but got %s", err)
+		}
+	}
+
+	if err := flush(); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	return actual
+}
+
+func TestPagination(t1 *testing.T) {
+	t := &ui.T{T: t1}
+
+	objects := makePaginationTestObjects(t, 10)
+
+	type testCase struct {
+		pagination Pagination
+		expected   []string
+	}
+
+	for _, tc := range []testCase{
+		{
+			pagination: Pagination{Limit: 2},
+			expected:   []string{"tag-09", "tag-08"},
+		},
+		{
+			pagination: Pagination{
+				Limit: 3,
+				Sort:  Sort{Key: SortKeyTai},
+			},
+			expected: []string{"tag-00", "tag-01", "tag-02"},
+		},
+		{
+			pagination: Pagination{
+				Limit:  2,
+				Offset: 2,
+				Sort:   Sort{Key: SortKeyObjectId, Descending: true},
+			},
+			expected: []string{"tag-07", "tag-06"},
+		},
+		{
+			pagination: Pagination{
+				Limit:  5,
+				Offset: 8,
+				Sort:   Sort{Key: SortKeyTai},
+			},
+			expected: []string{"tag-08", "tag-09"},
+		},
+		{
+			pagination: Pagination{
+				Sort: Sort{Key: SortKeyObjectId},
+			},
+			expected: []string{
+				"tag-00", "tag-01", "tag-02", "tag-03", "tag-04",
+				"tag-05", "tag-06", "tag-07", "tag-08", "tag-09",
+			},
+		},
+		{
+			pagination: Pagination{
+				Offset: 7,
+				Sort:   Sort{Key: SortKeyTai, Descending: true},
+			},
+			expected: []string{"tag-02", "tag-01", "tag-00"},
+		},
+	} {
+		actual := runPagination(t, tc.pagination, objects)
+		t.AssertEqual(fmt.Sprint(tc.expected), fmt.Sprint(actual))
+	}
+}
+
+func TestPaginationReleaseWithoutFlush(t1 *testing.T) {
+	t := &ui.T{T: t1}
+
+	objects := makePaginationTestObjects(t, 3)
+
+	var actual []string
+
+	output := func(object *sku.Transacted) (err error) {
+		actual = append(actual, object.GetObjectId().String())
+		return err
+	}
+
+	funcIter, flush, release := Pagination{
+		Sort: Sort{Key: SortKeyTai},
+	}.MakeFuncIter(output)
+
+	for _, object := range objects {
+		if err := funcIter(object); err != nil {
+			t.Fatalf("expected no error but got %s", err)
+		}
+	}
+
+	// a query that fails skips flush, so only release runs
+	release()
+
+	if err := flush(); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	if len(actual) != 0 {
+		t.Errorf("expected released matches not to be output, got %q", actual)
+	}
+}
+
+func TestPaginationValidate(t1 *testing.T) {
+	t := &ui.T{T: t1}
+
+	for _, pagination := range []Pagination{
+		{},
+		{Limit: 2},
+		{Limit: 2, Offset: 1, Sort: Sort{Key: SortKeyTai}},
+		{Sort: Sort{Key: SortKeyTai}},
+		{Offset: 1, Sort: Sort{Key: SortKeyTai}},
+	} {
+		if err := pagination.Validate(); err != nil {
+			t.Errorf("expected %s to be valid but got %s", pagination, err)
+		}
+	}
+
+	for _, pagination := range []Pagination{
+		{Limit: -1},
+		{Limit: 2, Offset: 1},
+	} {
+		if err := pagination.Validate(); err == nil {
+			t.Errorf("expected %s to be invalid", pagination)
+		}
+	}
+}
+
+func TestSortSet(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	var sort Sort
+
+	if err := sort.Set("-description"); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	t.AssertEqual(Sort{Key: SortKeyDescription, Descending: true}, sort)
+
+	if err := sort.Set("size"); err == nil {
+		t.Errorf("expected error for unsupported sort key")
+	}
+}
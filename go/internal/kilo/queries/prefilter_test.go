@@ -0,0 +1,83 @@
+//go:build test
+
+package queries
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func getTestPrefilter(
+	t *ui.T,
+	queryGroup sku.PrimitiveQueryGroup,
+) (prefilter sku.QueryWithPrefilter, ok bool) {
+	query, ok := queryGroup.Get(genres.Zettel)
+
+	if !ok {
+		t.Fatalf("expected a zettel query")
+	}
+
+	prefilter, ok = query.(sku.QueryWithPrefilter)
+
+	return prefilter, ok
+}
+
+func TestMayContainSkuMatchesTagsBeforeDecoding(t1 *testing.T) {
+	t := &ui.T{T: t1}
+
+	builder := (&Builder{}).WithOptions(
+		BuilderOptions(
+			BuilderOptionDefaultGenres(genres.Zettel),
+			BuilderOptionVirtualTags(
+				virtualTagsFunc{
+					"stale": func(object *sku.Transacted) bool {
+						return object.GetMetadata().GetDescription().String() == "old"
+					},
+				},
+			),
+		),
+	)
+
+	query, err := builder.BuildQueryGroup("red:z")
+	t.AssertNoError(err)
+
+	prefilter, ok := getTestPrefilter(t, primitive{query})
+
+	if !ok {
+		t.Fatalf("expected tag queries to prefilter")
+	}
+
+	red := makeVirtualTagObject(t, "one/uno", "")
+	t.AssertNoError(red.AddTag(ids.MustTag("red")))
+
+	blue := makeVirtualTagObject(t, "one/dos", "")
+	t.AssertNoError(blue.AddTag(ids.MustTag("blue")))
+
+	if !prefilter.MayContainSku(red) {
+		t.Errorf("expected an object with the tag to pass the prefilter")
+	}
+
+	if prefilter.MayContainSku(blue) {
+		t.Errorf("expected an object without the tag to be rejected")
+	}
+
+	if _, ok := getTestPrefilter(
+		t,
+		primitiveAsOf{primitive: primitive{query}},
+	); ok {
+		t.Errorf("expected as-of queries, which read every version, not to prefilter")
+	}
+
+	virtual, err := builder.BuildQueryGroup("%stale:z")
+	t.AssertNoError(err)
+
+	prefilter, ok = getTestPrefilter(t, primitive{virtual})
+
+	if ok && !prefilter.MayContainSku(blue) {
+		t.Errorf("expected virtual tags, which read descriptions, to pass the prefilter")
+	}
+}
@@ -0,0 +1,227 @@
+package queries
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+)
+
+// ResultCache keeps the object ids that internal queries of latest versions
+// matched on disk, so that repeating a query reads its objects by id instead
+// of scanning the stream index. Every query's ids are a file named by the
+// digest of the normalized query and the generation, which must change
+// whenever anything a query reads does, so that files of earlier generations
+// are never read again.
+type ResultCache struct {
+	dir        string
+	generation string
+}
+
+func MakeResultCache(dir, generation string) *ResultCache {
+	return &ResultCache{
+		dir:        dir,
+		generation: generation,
+	}
+}
+
+// accepts returns whether the results of a query are object ids that can be
+// read back. Workspace and history queries are not, since their results are
+// checked out files and past versions, and neither are queries of virtual
+// tags, whose providers may compute them from more than the objects.
+func (cache *ResultCache) accepts(query *Query) bool {
+	if query.isDotOperatorActive() || query.virtualTagsActive {
+		return false
+	}
+
+	return !primitive{query}.GetSigil().ContainsOneOf(ids.SigilHistory)
+}
+
+// path returns the file of a query, whose digest covers everything besides
+// the indexes that the matched objects depend on.
+func (cache *ResultCache) path(query *Query) string {
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "generation: %s\n", cache.generation)
+	fmt.Fprintf(hash, "query: %s\n", query.StringOptimized())
+	fmt.Fprintf(hash, "match-on-empty: %t\n", query.matchOnEmpty)
+	fmt.Fprintf(hash, "hidden: %t\n", query.hidden != nil)
+
+	if query.defaultQuery != nil {
+		fmt.Fprintf(hash, "default: %s\n", query.defaultQuery.StringOptimized())
+	}
+
+	return filepath.Join(cache.dir, hex.EncodeToString(hash.Sum(nil)))
+}
+
+// read returns the object ids of a query as lines of `genre object-id`, and
+// false if it has none.
+func (cache *ResultCache) read(path string) (lines []string, ok bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return lines, ok
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		logger.Debug(
+			"failed to read query result cache",
+			"path",
+			path,
+			"error",
+			err,
+		)
+		return nil, ok
+	}
+
+	ok = true
+
+	return lines, ok
+}
+
+// write replaces the object ids of a query atomically, so that concurrent
+// readers see either every line or no file.
+func (cache *ResultCache) write(path string, lines []string) (err error) {
+	if err = os.MkdirAll(cache.dir, 0o755); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = files.WriteFileAtomic(
+		path,
+		[]byte(strings.Join(lines, "\n")),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// executeInternalQueryCached is executeInternalQuery through the result
+// cache, for queries it accepts.
+func (e *Executor) executeInternalQueryCached(
+	out interfaces.FuncIter[*sku.Transacted],
+) (err error) {
+	cache := e.ResultCache
+
+	if cache == nil || !cache.accepts(e.Query) {
+		return e.executeInternalQuery(out)
+	}
+
+	path := cache.path(e.Query)
+
+	if lines, ok := cache.read(path); ok {
+		if err = e.replayCachedResults(lines, out); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		return err
+	}
+
+	var lock sync.Mutex
+	var lines []string
+	var stopped bool
+
+	// pages are read concurrently, so results are appended in the order they
+	// are emitted
+	if err = e.executeInternalQuery(
+		func(object *sku.Transacted) (err error) {
+			lock.Lock()
+			lines = append(
+				lines,
+				genres.Must(object).String()+" "+object.GetObjectId().String(),
+			)
+			lock.Unlock()
+
+			if err = out(object); errors.IsStopIteration(err) {
+				lock.Lock()
+				stopped = true
+				lock.Unlock()
+			}
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// the stream index swallows the stop of an out that has seen enough, like
+	// a paginator's limit, leaving lines short of the query's results
+	if stopped {
+		return err
+	}
+
+	// the cache only saves rescanning, so failing to write it is not an error
+	if err := cache.write(path, lines); err != nil {
+		logger.Debug("failed to write query result cache", "error", err)
+	}
+
+	return err
+}
+
+func (e *Executor) replayCachedResults(
+	lines []string,
+	out interfaces.FuncIter[*sku.Transacted],
+) (err error) {
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		genreString, objectIdString, ok := strings.Cut(line, " ")
+
+		if !ok {
+			err = errors.Errorf("malformed query result cache line: %q", line)
+			return err
+		}
+
+		var objectId ids.ObjectId
+
+		if err = objectId.SetWithGenre(
+			objectIdString,
+			genres.MakeOrUnknown(genreString),
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		object, repool := sku.GetTransactedPool().GetWithRepool()
+
+		if err = e.FuncReadOneInto(&objectId, object); err != nil {
+			repool()
+			err = errors.Wrap(err)
+			return err
+		}
+
+		err = out(object)
+		repool()
+
+		if err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,201 @@
+//go:build test
+
+package queries
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func TestResultCacheRoundTrip(t1 *testing.T) {
+	t := &ui.T{T: t1}
+
+	dir := t.TempDir()
+	query := &Query{}
+	cache := MakeResultCache(dir, "generation-1")
+	path := cache.path(query)
+
+	if _, ok := cache.read(path); ok {
+		t.Fatalf("expected a miss before the first write")
+	}
+
+	expected := []string{"Zettel one/uno", "Tag tag-3"}
+
+	if err := cache.write(path, expected); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	actual, ok := cache.read(path)
+
+	if !ok || len(actual) != len(expected) {
+		t.Fatalf("expected %q but got %q", expected, actual)
+	}
+
+	if MakeResultCache(dir, "generation-2").path(query) == path {
+		t.Errorf("expected generations to have different files")
+	}
+
+	var read []string
+
+	executor := Executor{
+		primitive: primitive{query},
+		ExecutionInfo: ExecutionInfo{
+			FuncReadOneInto: func(
+				objectId domain_interfaces.ObjectId,
+				out *sku.Transacted,
+			) (err error) {
+				read = append(read, objectId.String())
+				return out.ObjectId.Set(objectId.String())
+			},
+		},
+	}
+
+	var emitted []string
+
+	if err := executor.replayCachedResults(
+		actual,
+		func(object *sku.Transacted) (err error) {
+			emitted = append(emitted, object.GetObjectId().String())
+			return err
+		},
+	); err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+
+	if len(read) != 2 || read[0] != "one/uno" || read[1] != "tag-3" {
+		t.Errorf("expected the cached ids to be read but got %q", read)
+	}
+
+	if len(emitted) != 2 || emitted[0] != "one/uno" || emitted[1] != "tag-3" {
+		t.Errorf("expected the read objects to be emitted but got %q", emitted)
+	}
+}
+
+func TestResultCacheSkipsStoppedIteration(t1 *testing.T) {
+	t := &ui.T{T: t1}
+
+	builder := (&Builder{}).WithOptions(
+		BuilderOptions(BuilderOptionDefaultGenres(genres.Zettel)),
+	)
+
+	query, err := builder.BuildQueryGroup(":z")
+	t.AssertNoError(err)
+
+	cache := MakeResultCache(t.TempDir(), "generation")
+
+	executor := Executor{
+		primitive: primitive{query},
+		ExecutionInfo: ExecutionInfo{
+			// like the stream index, which swallows the stop of its out
+			FuncPrimitiveQuery: func(
+				group sku.PrimitiveQueryGroup,
+				out interfaces.FuncIter[*sku.Transacted],
+			) (err error) {
+				for _, objectId := range []string{"one/uno", "one/dos", "two/uno"} {
+					object := makeVirtualTagObject(t, objectId, "")
+
+					if err = out(object); errors.IsStopIteration(err) {
+						return nil
+					} else if err != nil {
+						return err
+					}
+				}
+
+				return err
+			},
+			ResultCache: cache,
+		},
+	}
+
+	emitted := 0
+
+	t.AssertNoError(executor.executeInternalQueryCached(
+		func(object *sku.Transacted) (err error) {
+			emitted++
+			return errors.MakeErrStopIteration()
+		},
+	))
+
+	if emitted != 1 {
+		t.Fatalf("expected iteration to stop after one object, got %d", emitted)
+	}
+
+	if _, ok := cache.read(cache.path(query)); ok {
+		t.Errorf("expected results of a stopped iteration not to be cached")
+	}
+
+	t.AssertNoError(executor.executeInternalQueryCached(
+		func(object *sku.Transacted) (err error) {
+			return err
+		},
+	))
+
+	if lines, ok := cache.read(cache.path(query)); !ok || len(lines) != 3 {
+		t.Errorf("expected every result to be cached, got %q", lines)
+	}
+}
+
+func TestResultCacheSkuTypeQueries(t1 *testing.T) {
+	t := &ui.T{T: t1}
+
+	builder := (&Builder{}).WithOptions(
+		BuilderOptions(BuilderOptionDefaultGenres(genres.Zettel)),
+	)
+
+	query, err := builder.BuildQueryGroup(":z")
+	t.AssertNoError(err)
+
+	cache := MakeResultCache(t.TempDir(), "generation")
+	scans := 0
+
+	executor := MakeExecutorWithExternalStore(
+		query,
+		func(
+			group sku.PrimitiveQueryGroup,
+			out interfaces.FuncIter[*sku.Transacted],
+		) (err error) {
+			scans++
+
+			for _, objectId := range []string{"one/uno", "one/dos"} {
+				if err = out(makeVirtualTagObject(t, objectId, "")); err != nil {
+					return err
+				}
+			}
+
+			return err
+		},
+		func(
+			objectId domain_interfaces.ObjectId,
+			out *sku.Transacted,
+		) (err error) {
+			return out.ObjectId.Set(objectId.String())
+		},
+		nil,
+		cache,
+	)
+
+	for range 2 {
+		var read []string
+
+		t.AssertNoError(executor.ExecuteSkuType(
+			func(checkedOut sku.SkuType) (err error) {
+				read = append(read, checkedOut.GetSku().GetObjectId().String())
+				return err
+			},
+		))
+
+		if len(read) != 2 {
+			t.Errorf("expected two objects, got %q", read)
+		}
+	}
+
+	if scans != 1 {
+		t.Errorf("expected the second query to be read from the cache, got %d scans", scans)
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"code.linenisgreat.com/dodder/go/lib/bravo/collections_slice"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/ohio"
-	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 	"code.linenisgreat.com/dodder/go/lib/delta/catgut"
 	"code.linenisgreat.com/dodder/go/lib/delta/files"
 )
@@ -101,12 +100,12 @@ func (tags *Tags) Flush(
 	dryRun bool,
 ) (err error) {
 	if tags.changes.IsEmpty() {
-		ui.Log().Print("no tags changes")
+		logger.Debug("no tags changes")
 		return err
 	}
 
 	if dryRun {
-		ui.Log().Print("no tags flush, dry run")
+		logger.Debug("no tags flush, dry run")
 		return err
 	}
 
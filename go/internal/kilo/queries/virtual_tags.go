@@ -0,0 +1,12 @@
+package queries
+
+import "code.linenisgreat.com/dodder/go/internal/golf/sku"
+
+// VirtualTags computes `%name` tags of queries. Objects never carry virtual
+// tags, so a query of one matches the objects its matcher accepts, evaluated
+// lazily as the query runs.
+type VirtualTags interface {
+	// GetVirtualTag returns the matcher of a virtual tag, named without the
+	// `%`, or nil if nothing provides it.
+	GetVirtualTag(name string) (sku.Queryable, error)
+}
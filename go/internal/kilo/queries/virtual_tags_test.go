@@ -0,0 +1,84 @@
+//go:build test
+
+package queries
+
+import (
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+type virtualTagsFunc map[string]func(*sku.Transacted) bool
+
+func (virtualTags virtualTagsFunc) GetVirtualTag(
+	name string,
+) (matcher sku.Queryable, err error) {
+	if function, ok := virtualTags[name]; ok {
+		matcher = queryableFunc(function)
+	}
+
+	return matcher, err
+}
+
+type queryableFunc func(*sku.Transacted) bool
+
+func (function queryableFunc) ContainsSku(tg sku.TransactedGetter) bool {
+	return function(tg.GetSku())
+}
+
+func makeVirtualTagObject(t *ui.T, objectId, description string) *sku.Transacted {
+	object, repool := sku.GetTransactedPool().GetWithRepool()
+	t.Cleanup(repool)
+
+	t.AssertNoError(object.ObjectId.Set(objectId))
+	t.AssertNoError(
+		object.GetMetadataMutable().GetDescriptionMutable().Set(description),
+	)
+
+	return object
+}
+
+func TestVirtualTagsMatchThroughProvider(t1 *testing.T) {
+	t := &ui.T{T: t1}
+
+	builder := (&Builder{}).WithOptions(
+		BuilderOptions(
+			BuilderOptionDefaultGenres(genres.Zettel),
+			BuilderOptionVirtualTags(
+				virtualTagsFunc{
+					"stale": func(object *sku.Transacted) bool {
+						return object.GetMetadata().GetDescription().String() == "old"
+					},
+				},
+			),
+		),
+	)
+
+	query, err := builder.BuildQueryGroup("%stale:z")
+	t.AssertNoError(err)
+
+	if !query.virtualTagsActive {
+		t.Errorf("expected the query to use virtual tags")
+	}
+
+	if MakeResultCache(t.TempDir(), "generation").accepts(query) {
+		t.Errorf("expected virtual tag queries to bypass the result cache")
+	}
+
+	if !query.containsSku(makeVirtualTagObject(t, "one/uno", "old")) {
+		t.Errorf("expected the provider to match")
+	}
+
+	if query.containsSku(makeVirtualTagObject(t, "one/dos", "new")) {
+		t.Errorf("expected the provider not to match")
+	}
+
+	unprovided, err := builder.BuildQueryGroup("%unknown:z")
+	t.AssertNoError(err)
+
+	if unprovided.virtualTagsActive {
+		t.Errorf("expected tags without providers to match as plain tags")
+	}
+}
@@ -92,6 +92,17 @@ func (store *Store) checkoutOneForReal(
 		return err
 	}
 
+	if !item.Blob.IsEmpty() {
+		if err = store.recordManifest(
+			item.Blob.GetPath(),
+			nil,
+			checkedOut.GetSkuExternal().GetBlobDigest(),
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	return err
 }
 
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"runtime"
 	"strings"
 
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
 	"code.linenisgreat.com/dodder/go/internal/bravo/file_extensions"
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
@@ -27,11 +29,15 @@ type itemWithError struct {
 	*sku.FSItem
 }
 
-// TODO support globs and ignores
 type dirInfo struct {
 	root          string
 	rootProcessed bool
 
+	ignoreRules     IgnoreRules
+	ignoreRulesRead bool
+
+	manifest *manifest
+
 	fsOps          filesystem_ops.V0
 	fileExtensions file_extensions.Config
 	envRepo        env_repo.Env
@@ -58,6 +64,7 @@ func makeObjectsWithDir(
 	info.envRepo = envRepo
 	info.probablyCheckedOut = makeFSItemData()
 	info.definitelyNotCheckedOut = makeFSItemData()
+	info.manifest = &manifest{}
 	info.errors = collections_value.MakeMutableValueSet[itemWithError](nil)
 
 	return info
@@ -70,11 +77,30 @@ func makeObjectsWithDir(
 //     \_/\_/ \__,_|_|_|\_\_|_| |_|\__, |
 //                                 |___/
 
+// walkDir adds every file under dir matching pattern to cache. Hidden files,
+// nested workspaces, and, when useIgnoreRules is set, whatever the
+// workspace's ignore file excludes are skipped, though dir itself is always
+// walked. The files' blobs are written in parallel once the walk is done.
 func (dirInfo *dirInfo) walkDir(
 	cache dirInfoMap,
 	dir string,
 	pattern string,
+	useIgnoreRules bool,
 ) (err error) {
+	if useIgnoreRules {
+		if err = dirInfo.readIgnoreRulesIfNecessary(); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	if err = dirInfo.readManifestIfNecessary(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var entries []walkedEntry
+
 	if err = dirInfo.fsOps.WalkDir(
 		dir,
 		func(path string, dirEntry fs.DirEntry, in error) (err error) {
@@ -113,6 +139,15 @@ func (dirInfo *dirInfo) walkDir(
 				return err
 			}
 
+			if useIgnoreRules && path != dir &&
+				dirInfo.isIgnored(path, dirEntry.IsDir()) {
+				if dirEntry.IsDir() {
+					err = filepath.SkipDir
+				}
+
+				return err
+			}
+
 			if pattern != "" {
 				var matched bool
 
@@ -136,10 +171,7 @@ func (dirInfo *dirInfo) walkDir(
 				return err
 			}
 
-			if _, _, err = dirInfo.addPathAndDirEntry(cache, path, dirEntry); err != nil {
-				err = errors.Wrapf(err, "DirEntry: %s", dirEntry)
-				return err
-			}
+			entries = append(entries, walkedEntry{path: path, dirEntry: dirEntry})
 
 			return err
 		},
@@ -148,35 +180,155 @@ func (dirInfo *dirInfo) walkDir(
 		return err
 	}
 
+	if pattern == "" {
+		dirInfo.pruneManifest(dir, entries)
+	}
+
+	var fds []*fd.FD
+
+	if fds, err = dirInfo.makeFDsParallel(entries); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	for _, fdee := range fds {
+		if _, _, err = dirInfo.addFD(cache, fdee); err != nil {
+			err = errors.Wrapf(err, "FD: %s", fdee)
+			return err
+		}
+	}
+
 	return err
 }
 
-func (dirInfo *dirInfo) addPathAndDirEntry(
-	cache dirInfoMap,
-	path string,
-	dirEntry fs.DirEntry,
-) (key string, fds *sku.FSItem, err error) {
-	if dirEntry.IsDir() {
-		return key, fds, err
+func (dirInfo *dirInfo) readIgnoreRulesIfNecessary() (err error) {
+	if dirInfo.ignoreRulesRead {
+		return err
 	}
 
-	var fdee *fd.FD
+	if dirInfo.ignoreRules, err = ReadIgnoreRules(
+		dirInfo.fsOps,
+		dirInfo.root,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	dirInfo.ignoreRulesRead = true
+
+	return err
+}
+
+func (dirInfo *dirInfo) isIgnored(path string, isDir bool) bool {
+	if len(dirInfo.ignoreRules) == 0 {
+		return false
+	}
+
+	rel, err := filepath.Rel(dirInfo.root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	return dirInfo.ignoreRules.IsIgnored(filepath.ToSlash(rel), isDir)
+}
+
+type walkedEntry struct {
+	path     string
+	dirEntry fs.DirEntry
+}
+
+// makeFDsParallel writes the blobs of entries to the default blob store with
+// up to NumCPU workers, returning their file descriptors in the same order.
+// Files unchanged since the manifest recorded them are not read, and the
+// others are recorded once they are.
+func (dirInfo *dirInfo) makeFDsParallel(
+	entries []walkedEntry,
+) (fds []*fd.FD, err error) {
+	fds = make([]*fd.FD, len(entries))
+
+	if len(entries) == 0 {
+		return fds, err
+	}
+
+	numWorkers := min(runtime.NumCPU(), len(entries))
+	indices := make(chan int)
+	blobStore := dirInfo.envRepo.GetDefaultBlobStore()
+	waitGroup := errors.MakeWaitGroupParallel()
+
+	for range numWorkers {
+		waitGroup.Do(func() (err error) {
+			for index := range indices {
+				entry := entries[index]
+
+				if fds[index], err = dirInfo.makeFD(entry, blobStore); err != nil {
+					err = errors.Wrapf(err, "DirEntry: %s", entry.dirEntry)
+
+					// drain so the other workers and the producer finish
+					for range indices {
+					}
+
+					return err
+				}
+			}
+
+			return err
+		})
+	}
+
+	for index := range entries {
+		indices <- index
+	}
+
+	close(indices)
+
+	if err = waitGroup.GetError(); err != nil {
+		err = errors.Wrap(err)
+		return fds, err
+	}
+
+	return fds, err
+}
+
+func (dirInfo *dirInfo) makeFD(
+	entry walkedEntry,
+	blobStore domain_interfaces.BlobStore,
+) (fdee *fd.FD, err error) {
+	var fileInfo fs.FileInfo
+
+	if fileInfo, err = entry.dirEntry.Info(); err != nil {
+		err = errors.Wrap(err)
+		return fdee, err
+	}
+
+	blobId := dirInfo.lookupManifest(entry.path, fileInfo)
+
+	if blobId != nil && blobStore.HasBlob(blobId) {
+		return fd.MakeFromFileInfoWithDigest(
+			fileInfo,
+			filepath.Dir(entry.path),
+			blobId,
+		)
+	}
 
 	if fdee, err = fd.MakeFromPathAndDirEntry(
-		path,
-		dirEntry,
-		dirInfo.envRepo.GetDefaultBlobStore(),
+		entry.path,
+		entry.dirEntry,
+		blobStore,
 	); err != nil {
 		err = errors.Wrap(err)
-		return key, fds, err
+		return fdee, err
 	}
 
-	if key, fds, err = dirInfo.addFD(cache, fdee); err != nil {
+	if err = dirInfo.recordManifest(
+		entry.path,
+		fileInfo,
+		fdee.GetDigest(),
+	); err != nil {
 		err = errors.Wrap(err)
-		return key, fds, err
+		return fdee, err
 	}
 
-	return key, fds, err
+	return fdee, err
 }
 
 func (dirInfo *dirInfo) keyForFD(fdee *fd.FD) (key string, err error) {
@@ -286,7 +438,7 @@ func (dirInfo *dirInfo) processDir(
 
 	results = make([]*sku.FSItem, 0)
 
-	if err = dirInfo.walkDir(cache, path, ""); err != nil {
+	if err = dirInfo.walkDir(cache, path, "", true); err != nil {
 		err = errors.Wrap(err)
 		return results, err
 	}
@@ -312,7 +464,7 @@ func (dirInfo *dirInfo) processFDPattern(
 ) (fds []*sku.FSItem, err error) {
 	cache := make(dirInfoMap)
 
-	if err = dirInfo.walkDir(cache, dir, pattern); err != nil {
+	if err = dirInfo.walkDir(cache, dir, pattern, true); err != nil {
 		err = errors.Wrap(err)
 		return fds, err
 	}
@@ -352,7 +504,7 @@ func (dirInfo *dirInfo) processFD(
 	dir := filepath.Dir(fdee.GetPath())
 	pattern := filepath.Join(dir, fmt.Sprintf("%s*", fdee.FileNameSansExt()))
 
-	if err = dirInfo.walkDir(cache, dir, pattern); err != nil {
+	if err = dirInfo.walkDir(cache, dir, pattern, false); err != nil {
 		err = errors.Wrap(err)
 		return objectIdString, fds, err
 	}
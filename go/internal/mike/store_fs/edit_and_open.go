@@ -49,8 +49,9 @@ func (store *Store) openZettels(
 
 	var e editor.Editor
 
-	if e, err = editor.MakeEditorWithVimOptions(
+	if e, err = editor.MakeEditorWithUtilityAndVimOptions(
 		ph,
+		store.editor,
 		vim_cli_options_builder.New().
 			WithCursorLocation(2, 3).
 			WithFileType("dodder-object").
@@ -0,0 +1,141 @@
+package store_fs
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/charlie/filesystem_ops"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// ignoreRule is one line of a workspace's ignore file. Like gitignore, a
+// pattern without a slash matches the base name at any depth, a pattern with
+// one matches the path relative to the workspace root, a trailing slash only
+// matches directories, and a leading "!" re-includes what an earlier rule
+// ignored. Patterns use filepath.Match syntax, so "**" is not supported.
+type ignoreRule struct {
+	pattern  string
+	anchored bool
+	dirOnly  bool
+	negated  bool
+}
+
+// IgnoreRules are the rules of a workspace's ignore file, or of patterns
+// given on the command line.
+type IgnoreRules []ignoreRule
+
+// ReadIgnoreRules reads the ignore file at the workspace root, returning no
+// rules when there is none.
+func ReadIgnoreRules(
+	fsOps filesystem_ops.V0,
+	root string,
+) (rules IgnoreRules, err error) {
+	var reader io.ReadCloser
+
+	if reader, err = fsOps.Open(
+		filepath.Join(root, env_repo.FileIgnore),
+		filesystem_ops.OpenModeDefault,
+	); err != nil {
+		if errors.IsNotExist(err) {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return rules, err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	if rules, err = parseIgnoreRules(reader); err != nil {
+		err = errors.Wrapf(err, "reading %s", env_repo.FileIgnore)
+		return rules, err
+	}
+
+	return rules, err
+}
+
+func parseIgnoreRules(reader io.Reader) (rules IgnoreRules, err error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return rules, err
+	}
+
+	if rules, err = MakeIgnoreRules(lines...); err != nil {
+		err = errors.Wrap(err)
+		return rules, err
+	}
+
+	return rules, err
+}
+
+// MakeIgnoreRules parses one rule per line, skipping blank lines and "#"
+// comments.
+func MakeIgnoreRules(lines ...string) (rules IgnoreRules, err error) {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+
+		if rule.negated = strings.HasPrefix(line, "!"); rule.negated {
+			line = line[1:]
+		}
+
+		if rule.dirOnly = strings.HasSuffix(line, "/"); rule.dirOnly {
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		rule.anchored = strings.Contains(line, "/")
+		rule.pattern = strings.TrimPrefix(line, "/")
+
+		if _, err = path.Match(rule.pattern, ""); err != nil {
+			err = errors.Wrapf(err, "pattern: %q", line)
+			return rules, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, err
+}
+
+// IsIgnored reports whether rel, a slash-separated path relative to the
+// workspace root, is ignored. The last matching rule wins.
+func (rules IgnoreRules) IsIgnored(rel string, isDir bool) (ignored bool) {
+	base := path.Base(rel)
+
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		subject := base
+
+		if rule.anchored {
+			subject = rel
+		}
+
+		// patterns were validated when they were parsed
+		if matched, _ := path.Match(rule.pattern, subject); matched {
+			ignored = !rule.negated
+		}
+	}
+
+	return ignored
+}
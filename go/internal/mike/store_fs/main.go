@@ -77,6 +77,9 @@ type Store struct {
 	fileExtensions     file_extensions.Config
 	dir                string
 
+	// the editor command from the layered config, or empty for $EDITOR
+	editor string
+
 	dirInfo
 
 	deleteLock      sync.Mutex
@@ -84,6 +87,12 @@ type Store struct {
 	deletedInternal fd.MutableSet
 }
 
+// SetEditor sets the editor command that objects are opened with, or clears
+// it to use $EDITOR.
+func (store *Store) SetEditor(utility string) {
+	store.editor = utility
+}
+
 func (store *Store) GetExternalStoreLike() store_workspace.StoreLike {
 	return store
 }
@@ -132,6 +141,12 @@ func (store *Store) DeleteCheckedOutInternal(co *sku.CheckedOut) (err error) {
 }
 
 func (store *Store) Flush() (err error) {
+	for _, deleted := range []fd.MutableSet{store.deleted, store.deletedInternal} {
+		for fdee := range deleted.All() {
+			store.removeFromManifest(fdee.GetPath())
+		}
+	}
+
 	deleteOp := DeleteCheckout{}
 
 	if err = deleteOp.Run(
@@ -157,6 +172,13 @@ func (store *Store) Flush() (err error) {
 	store.deleted.Reset()
 	store.deletedInternal.Reset()
 
+	if !store.config.IsDryRun() {
+		if err = store.writeManifestIfNecessary(); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	return err
 }
 
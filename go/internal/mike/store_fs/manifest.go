@@ -0,0 +1,377 @@
+package store_fs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/charlie/filesystem_ops"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// entries modified this close to when the manifest was written may have
+// changed again within the same mtime, so they are read rather than trusted
+const manifestRacyWindow = 2 * time.Second
+
+// manifestEntry is the blob id of a checked out file as of its mtime and size.
+type manifestEntry struct {
+	blobId  markl.Id
+	modTime int64
+	size    int64
+}
+
+// matches is false for symlinks, as their mtime and size are not those of the
+// file they point to.
+func (entry manifestEntry) matches(fileInfo fs.FileInfo) bool {
+	return fileInfo.Mode()&fs.ModeSymlink == 0 &&
+		entry.modTime == fileInfo.ModTime().UnixNano() &&
+		entry.size == fileInfo.Size()
+}
+
+// manifest is the working tree index of a workspace: the blob ids of the
+// files checked out into it or read from it, keyed by their path relative to
+// the workspace root, so that files whose mtime and size have not changed
+// since are not read again to find out whether they changed. It is read
+// lazily and written on Flush.
+//
+// Each line is `blob-id mtime-unix-nanos size path`.
+type manifest struct {
+	lock sync.Mutex
+
+	read    bool
+	changed bool
+
+	// the mtime of the manifest file when it was read
+	writtenAt time.Time
+	entries   map[string]manifestEntry
+}
+
+func (dirInfo *dirInfo) readManifestIfNecessary() (err error) {
+	dirInfo.manifest.lock.Lock()
+	defer dirInfo.manifest.lock.Unlock()
+
+	if dirInfo.manifest.read || dirInfo.root == "" {
+		return err
+	}
+
+	dirInfo.manifest.read = true
+	dirInfo.manifest.entries = make(map[string]manifestEntry)
+
+	path := filepath.Join(dirInfo.root, env_repo.FileWorkspaceManifest)
+
+	var fileInfo fs.FileInfo
+
+	if fileInfo, err = dirInfo.fsOps.Lstat(path); err != nil {
+		if errors.IsNotExist(err) {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return err
+	}
+
+	dirInfo.manifest.writtenAt = fileInfo.ModTime()
+
+	var reader io.ReadCloser
+
+	if reader, err = dirInfo.fsOps.Open(
+		path,
+		filesystem_ops.OpenModeDefault,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, reader)
+
+	if err = dirInfo.manifest.parse(reader); err != nil {
+		err = errors.Wrapf(err, "reading %s", env_repo.FileWorkspaceManifest)
+		return err
+	}
+
+	return err
+}
+
+func (manifest *manifest) parse(reader io.Reader) (err error) {
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 4)
+
+		if len(fields) != 4 {
+			err = errors.Errorf("malformed line: %q", line)
+			return err
+		}
+
+		var entry manifestEntry
+
+		if err = entry.blobId.UnmarshalText([]byte(fields[0])); err != nil {
+			err = errors.Wrapf(err, "Line: %q", line)
+			return err
+		}
+
+		if entry.modTime, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+			err = errors.Wrapf(err, "Line: %q", line)
+			return err
+		}
+
+		if entry.size, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+			err = errors.Wrapf(err, "Line: %q", line)
+			return err
+		}
+
+		manifest.entries[fields[3]] = entry
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (dirInfo *dirInfo) manifestKey(path string) (key string, ok bool) {
+	if dirInfo.root == "" {
+		return key, ok
+	}
+
+	var err error
+
+	if path, err = filepath.Abs(path); err != nil {
+		return key, ok
+	}
+
+	if key, err = filepath.Rel(dirInfo.root, path); err != nil ||
+		strings.HasPrefix(key, "..") {
+		return key, ok
+	}
+
+	key = filepath.ToSlash(key)
+	ok = true
+
+	return key, ok
+}
+
+// lookupManifest returns the recorded blob id of the file at path, if it has
+// one and the file's mtime and size still match it.
+func (dirInfo *dirInfo) lookupManifest(
+	path string,
+	fileInfo fs.FileInfo,
+) (blobId domain_interfaces.MarklId) {
+	key, ok := dirInfo.manifestKey(path)
+
+	if !ok {
+		return blobId
+	}
+
+	dirInfo.manifest.lock.Lock()
+	defer dirInfo.manifest.lock.Unlock()
+
+	entry, ok := dirInfo.manifest.entries[key]
+
+	if !ok || !entry.matches(fileInfo) {
+		return blobId
+	}
+
+	racyAfter := dirInfo.manifest.writtenAt.Add(-manifestRacyWindow)
+
+	if !time.Unix(0, entry.modTime).Before(racyAfter) {
+		return blobId
+	}
+
+	blobId = entry.blobId
+
+	return blobId
+}
+
+// recordManifest records the blob id of the file at path as of the mtime and
+// size in fileInfo, or, if it is nil, its current ones. Callers that read the
+// file pass the fileInfo from before they read it, so that a change made while
+// it was read shows up as a changed mtime rather than being recorded under
+// the old blob id.
+func (dirInfo *dirInfo) recordManifest(
+	path string,
+	fileInfo fs.FileInfo,
+	blobId domain_interfaces.MarklId,
+) (err error) {
+	key, ok := dirInfo.manifestKey(path)
+
+	if !ok || blobId == nil || blobId.IsNull() {
+		return err
+	}
+
+	if err = dirInfo.readManifestIfNecessary(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if fileInfo == nil {
+		if fileInfo, err = dirInfo.fsOps.Lstat(path); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	entry := manifestEntry{
+		modTime: fileInfo.ModTime().UnixNano(),
+		size:    fileInfo.Size(),
+	}
+
+	entry.blobId.ResetWithMarklId(blobId)
+
+	dirInfo.manifest.lock.Lock()
+	defer dirInfo.manifest.lock.Unlock()
+
+	dirInfo.manifest.entries[key] = entry
+	dirInfo.manifest.changed = true
+
+	return err
+}
+
+func (dirInfo *dirInfo) removeFromManifest(path string) {
+	key, ok := dirInfo.manifestKey(path)
+
+	if !ok {
+		return
+	}
+
+	dirInfo.manifest.lock.Lock()
+	defer dirInfo.manifest.lock.Unlock()
+
+	if _, ok := dirInfo.manifest.entries[key]; ok {
+		delete(dirInfo.manifest.entries, key)
+		dirInfo.manifest.changed = true
+	}
+}
+
+// pruneManifest removes the entries for files under dir that a complete walk
+// of it did not find, as they were deleted, moved, or are now ignored.
+func (dirInfo *dirInfo) pruneManifest(dir string, walked []walkedEntry) {
+	prefix, ok := dirInfo.manifestKey(dir)
+
+	if !ok {
+		return
+	}
+
+	if prefix == "." {
+		prefix = ""
+	} else {
+		prefix += "/"
+	}
+
+	seen := make(map[string]struct{}, len(walked))
+
+	for _, entry := range walked {
+		if key, ok := dirInfo.manifestKey(entry.path); ok {
+			seen[key] = struct{}{}
+		}
+	}
+
+	dirInfo.manifest.lock.Lock()
+	defer dirInfo.manifest.lock.Unlock()
+
+	for key := range dirInfo.manifest.entries {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		delete(dirInfo.manifest.entries, key)
+		dirInfo.manifest.changed = true
+	}
+}
+
+// writeManifestIfNecessary replaces the manifest file with the entries if
+// they changed.
+func (dirInfo *dirInfo) writeManifestIfNecessary() (err error) {
+	dirInfo.manifest.lock.Lock()
+	defer dirInfo.manifest.lock.Unlock()
+
+	if !dirInfo.manifest.changed {
+		return err
+	}
+
+	var tempPath string
+	var writer io.WriteCloser
+
+	if tempPath, writer, err = dirInfo.fsOps.CreateTemp(
+		dirInfo.root,
+		env_repo.FileWorkspaceManifest+"-*",
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	bufferedWriter := bufio.NewWriter(writer)
+
+	for _, key := range slices.Sorted(maps.Keys(dirInfo.manifest.entries)) {
+		entry := dirInfo.manifest.entries[key]
+
+		var blobId []byte
+
+		if blobId, err = entry.blobId.MarshalText(); err != nil {
+			writer.Close()
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if _, err = fmt.Fprintf(
+			bufferedWriter,
+			"%s %d %d %s\n",
+			blobId,
+			entry.modTime,
+			entry.size,
+			key,
+		); err != nil {
+			writer.Close()
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	if err = bufferedWriter.Flush(); err != nil {
+		writer.Close()
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = dirInfo.fsOps.Rename(
+		tempPath,
+		filepath.Join(dirInfo.root, env_repo.FileWorkspaceManifest),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	dirInfo.manifest.changed = false
+	dirInfo.manifest.writtenAt = time.Now()
+
+	return err
+}
@@ -2,6 +2,7 @@ package store_fs
 
 import (
 	"io"
+	"io/fs"
 	"os"
 
 	"code.linenisgreat.com/dodder/go/internal/_/checkout_mode"
@@ -9,6 +10,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
 	"code.linenisgreat.com/dodder/go/internal/charlie/filesystem_ops"
 	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 )
@@ -134,11 +136,27 @@ func (store *Store) readOneExternalBlob(
 		internal.GetMetadataMutable(),
 	)
 
-	// TODO use cache
+	var fileInfo fs.FileInfo
+	var ok bool
+
+	if fileInfo, ok, err = store.readOneExternalBlobFromManifest(
+		external,
+		item,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	} else if ok {
+		return err
+	}
+
 	{
 		var writeCloser domain_interfaces.BlobWriter
 
-		if writeCloser, err = store.envRepo.GetDefaultBlobStore().MakeBlobWriter(nil); err != nil {
+		if writeCloser, err = blob_stores.MakeTypedBlobWriter(
+			store.envRepo.GetDefaultBlobStore(),
+			nil,
+			store.getBlobTypeString(external, item),
+		); err != nil {
 			err = errors.Wrap(err)
 			return err
 		}
@@ -168,5 +186,70 @@ func (store *Store) readOneExternalBlob(
 		)
 	}
 
+	if err = store.recordManifest(
+		item.Blob.GetPath(),
+		fileInfo,
+		external.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
 	return err
 }
+
+// readOneExternalBlobFromManifest sets the blob digest of external to the
+// one the manifest recorded for item's blob if the file has not changed
+// since, reporting whether it did, and returns the file's info as of before
+// it would be read.
+func (store *Store) readOneExternalBlobFromManifest(
+	external *sku.Transacted,
+	item *sku.FSItem,
+) (fileInfo fs.FileInfo, ok bool, err error) {
+	path := item.Blob.GetPath()
+
+	if fileInfo, err = store.fsOps.Lstat(path); err != nil {
+		err = errors.Wrap(err)
+		return fileInfo, ok, err
+	}
+
+	if err = store.readManifestIfNecessary(); err != nil {
+		err = errors.Wrap(err)
+		return fileInfo, ok, err
+	}
+
+	blobId := store.lookupManifest(path, fileInfo)
+
+	if blobId != nil &&
+		store.envRepo.GetDefaultBlobStore().HasBlob(blobId) {
+		external.GetMetadataMutable().GetBlobDigestMutable().ResetWithMarklId(
+			blobId,
+		)
+
+		ok = true
+	}
+
+	return fileInfo, ok, err
+}
+
+// getBlobTypeString returns the type of object, or, for objects without one,
+// the type of the extension of item's blob, which blob store routes match
+// against.
+func (store *Store) getBlobTypeString(
+	object *sku.Transacted,
+	item *sku.FSItem,
+) string {
+	if tipe := object.GetType(); !tipe.IsEmpty() {
+		return tipe.String()
+	}
+
+	ext := item.Blob.ExtSansDot()
+
+	if typeFromExtension := store.config.GetTypeStringFromExtension(
+		ext,
+	); typeFromExtension != "" {
+		return typeFromExtension
+	}
+
+	return ext
+}
@@ -0,0 +1,86 @@
+package env_workspace
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/delta/repo_configs"
+	"code.linenisgreat.com/dodder/go/internal/echo/workspace_config_blobs"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// setLayered resolves the layered config from the builtin values, the repo's
+// konfig, the workspace file at workspaceFile, the environment, and the
+// `-config` flags, in that order, and derives the defaults from it.
+func (env *env) setLayered(config Config, workspaceFile string) (err error) {
+	env.layered = repo_configs.MakeLayered()
+
+	if err = env.layered.SetBuiltin(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = env.layered.SetDefaults(
+		repo_configs.LayerRepo,
+		"konfig",
+		config.GetDefaults(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = env.layered.Set(
+		repo_configs.LayeredKeyEditor,
+		repo_configs.LayerRepo,
+		"konfig",
+		config.GetEditor(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if env.blob != nil {
+		if err = env.layered.SetDefaults(
+			repo_configs.LayerWorkspace,
+			workspaceFile,
+			env.blob.GetDefaults(),
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if blob, ok := env.blob.(workspace_config_blobs.ConfigWithEditor); ok {
+			if err = env.layered.Set(
+				repo_configs.LayeredKeyEditor,
+				repo_configs.LayerWorkspace,
+				workspaceFile,
+				blob.GetEditor(),
+			); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+		}
+	}
+
+	if err = env.layered.SetFromEnv(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = env.layered.SetFromFlags(config.GetConfigOverrides()); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if env.defaults, err = env.layered.GetDefaults(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (env *env) GetLayeredConfig() repo_configs.Layered {
+	return env.layered
+}
+
+func (env *env) GetEditor() string {
+	return env.layered.GetString(repo_configs.LayeredKeyEditor)
+}
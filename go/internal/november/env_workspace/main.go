@@ -32,6 +32,8 @@ type Env interface {
 	GetWorkspaceConfig() workspace_config_blobs.Config
 	GetWorkspaceConfigFilePath() string
 	GetDefaults() repo_configs.Defaults
+	GetEditor() string
+	GetLayeredConfig() repo_configs.Layered
 	CreateWorkspace(workspace_config_blobs.Config) (err error)
 	GetStore() *Store
 
@@ -48,6 +50,8 @@ type Config interface {
 	repo_configs.DefaultsGetter
 	sku.Config
 	file_extensions.ConfigGetter
+	GetEditor() string
+	GetConfigOverrides() []string
 }
 
 func Make(
@@ -93,26 +97,9 @@ func Make(
 		outputEnv.blob = object.Blob
 	}
 
-	defaults := outputEnv.configMutable.GetDefaults()
-
-	outputEnv.defaults = repo_configs.DefaultsV1{
-		Type: defaults.GetDefaultType(),
-		Tags: defaults.GetDefaultTags(),
-	}
-
-	if outputEnv.blob != nil {
-		defaults = outputEnv.blob.GetDefaults()
-
-		if newType := defaults.GetDefaultType(); !newType.IsEmpty() {
-			outputEnv.defaults.Type = newType
-		}
-
-		if newTags := defaults.GetDefaultTags(); newTags.Len() > 0 {
-			outputEnv.defaults.Tags = append(
-				outputEnv.defaults.Tags,
-				newTags...,
-			)
-		}
+	if err = outputEnv.setLayered(config, workspaceFile); err != nil {
+		err = errors.Wrap(err)
+		return outputEnv, err
 	}
 
 	if outputEnv.isTemporary {
@@ -140,6 +127,7 @@ func Make(
 		return outputEnv, err
 	}
 
+	outputEnv.storeFS.SetEditor(outputEnv.GetEditor())
 	outputEnv.store.StoreLike = outputEnv.storeFS
 
 	return outputEnv, err
@@ -159,6 +147,7 @@ type env struct {
 
 	configMutable repo_configs.DefaultsGetter
 	blob          workspace_config_blobs.Config
+	layered       repo_configs.Layered
 	defaults      repo_configs.DefaultsV1
 
 	storeFS *store_fs.Store
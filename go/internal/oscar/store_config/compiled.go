@@ -14,6 +14,7 @@ import (
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/comments"
+	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
 	"code.linenisgreat.com/dodder/go/lib/charlie/quiter"
 	"code.linenisgreat.com/dodder/go/lib/charlie/values"
 )
@@ -65,6 +66,42 @@ func (config Config) GetPrintOptions() options_print.Options {
 	return config.PrintOptions
 }
 
+func (config Config) IsAutoVivifyEnabled() bool {
+	return repo_configs.GetAutoVivify(config.configRepo)
+}
+
+func (config Config) GetStreamIndexOptions() repo_configs.StreamIndexV1 {
+	return repo_configs.GetStreamIndexOptions(config.configRepo)
+}
+
+func (config Config) GetLuaLimits() lua.Limits {
+	return repo_configs.GetLuaLimits(config.configRepo)
+}
+
+func (config Config) GetHooks() repo_configs.HooksV1 {
+	return repo_configs.GetHooks(config.configRepo)
+}
+
+func (config Config) GetVirtualTags() repo_configs.VirtualTagsV1 {
+	return repo_configs.GetVirtualTags(config.configRepo)
+}
+
+func (config Config) GetTrash() repo_configs.TrashV1 {
+	return repo_configs.GetTrash(config.configRepo)
+}
+
+func (config Config) GetEditor() string {
+	return repo_configs.GetEditor(config.configRepo)
+}
+
+func (config Config) GetMaintenance() repo_configs.MaintenanceV1 {
+	return repo_configs.GetMaintenance(config.configRepo)
+}
+
+func (config Config) GetBlobStoreRoutes() []repo_configs.BlobStoreRouteV1 {
+	return repo_configs.GetBlobStoreRoutes(config.configRepo)
+}
+
 func (compiled *compiled) GetSku() *sku.Transacted {
 	return &compiled.Sku
 }
@@ -6,6 +6,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/zettel_id_index"
 	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/blob_refcount_index"
 	"code.linenisgreat.com/dodder/go/internal/india/stream_index"
 	"code.linenisgreat.com/dodder/go/internal/juliett/inventory_list_store"
 	"code.linenisgreat.com/dodder/go/internal/juliett/typed_blob_store"
@@ -58,6 +59,10 @@ func (store *Store) GetStreamIndex() *stream_index.Index {
 	return store.streamIndex
 }
 
+func (store *Store) GetBlobRefcounts() *blob_refcount_index.Index {
+	return store.blobRefcounts
+}
+
 func (store *Store) GetConfigBlobCoder() interfaces.CoderReadWriter[*repo_configs.TypedBlob] {
 	return store.configBlobCoder
 }
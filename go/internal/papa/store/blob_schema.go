@@ -0,0 +1,78 @@
+package store
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/type_blobs"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// validateBlobSchema checks a changed blob against the schema declared by its
+// type object, unless the commit allows invalid blobs.
+func (store *Store) validateBlobSchema(
+	daughter *sku.Transacted,
+	mother *sku.Transacted,
+	options sku.CommitOptions,
+) (err error) {
+	if !options.Validate || options.AllowInvalidBlob {
+		return err
+	}
+
+	blobId := daughter.GetBlobDigest()
+
+	if blobId.IsNull() {
+		return err
+	}
+
+	if mother != nil && markl.Equals(blobId, mother.GetBlobDigest()) &&
+		daughter.GetType().String() == mother.GetType().String() {
+		return err
+	}
+
+	var typeObject *sku.Transacted
+
+	if typeObject, err = store.ReadObjectTypeAndLockIfNecessary(daughter); err != nil {
+		if errors.IsErrNotFound(err) {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return err
+	} else if typeObject == nil {
+		return err
+	}
+
+	typeBlobStore := store.GetTypedBlobStore().Type
+
+	var typeBlob type_blobs.Blob
+	var repool interfaces.FuncRepool
+
+	if typeBlob, repool, _, err = typeBlobStore.ParseTypedBlob(
+		typeObject.GetType(),
+		typeObject.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer repool()
+
+	withSchema, ok := typeBlob.(type_blobs.WithSchema)
+
+	if !ok {
+		return err
+	}
+
+	if err = typeBlobStore.ValidateBlob(
+		daughter.GetType(),
+		withSchema.GetSchema(),
+		blobId,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
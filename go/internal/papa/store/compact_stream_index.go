@@ -0,0 +1,64 @@
+package store
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/echo/file_lock"
+	"code.linenisgreat.com/dodder/go/internal/india/stream_index"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// GetStreamIndexCompactionOptions returns the `stream-index` options of the
+// repo config.
+func (store *Store) GetStreamIndexCompactionOptions() (
+	options stream_index.CompactionOptions,
+	err error,
+) {
+	config := store.GetConfigStore().GetConfig().GetStreamIndexOptions()
+
+	if options.Retention, options.RetentionSet, err = config.GetRetention(); err != nil {
+		err = errors.Wrap(err)
+		return options, err
+	}
+
+	options.Threshold = config.CompactionThreshold
+
+	return options, err
+}
+
+// CompactStreamIndex drops the superseded versions older than the retention of
+// options from the stream index, if at least threshold of it is garbage.
+func (store *Store) CompactStreamIndex(
+	options stream_index.CompactionOptions,
+	threshold float64,
+	printerHeader interfaces.FuncIter[string],
+) (stats stream_index.CompactionStats, compacted bool, err error) {
+	if !store.GetEnvRepo().GetLockSmith().IsAcquired() {
+		err = file_lock.ErrLockRequired{
+			Operation: "compact stream index",
+		}
+
+		return stats, compacted, err
+	}
+
+	if store.GetConfigStore().GetConfig().IsDryRun() {
+		if stats, err = store.streamIndex.ReadCompactionStats(
+			options,
+		); err != nil {
+			err = errors.Wrap(err)
+			return stats, compacted, err
+		}
+
+		return stats, compacted, err
+	}
+
+	if stats, compacted, err = store.streamIndex.Compact(
+		options,
+		threshold,
+		printerHeader,
+	); err != nil {
+		err = errors.Wrap(err)
+		return stats, compacted, err
+	}
+
+	return stats, compacted, err
+}
@@ -145,6 +145,7 @@ func (store *Store) RevertTo(
 func (store *Store) CreateOrUpdateCheckedOut(
 	object sku.SkuType,
 	updateCheckout bool,
+	allowInvalidBlob bool,
 ) (err error) {
 	external := object.GetSkuExternal()
 	internal := external.GetSku()
@@ -160,10 +161,10 @@ func (store *Store) CreateOrUpdateCheckedOut(
 		return err
 	}
 
-	if err = store.Commit(
-		external,
-		sku.CommitOptions{StoreOptions: sku.GetStoreOptionsCreate()},
-	); err != nil {
+	options := sku.CommitOptions{StoreOptions: sku.GetStoreOptionsCreate()}
+	options.AllowInvalidBlob = allowInvalidBlob
+
+	if err = store.Commit(external, options); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
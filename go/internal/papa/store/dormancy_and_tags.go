@@ -5,19 +5,34 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
 	"code.linenisgreat.com/dodder/go/internal/charlie/tag_paths"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/structured_log"
 	"code.linenisgreat.com/dodder/go/lib/bravo/collections_slice"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/expansion"
 	"code.linenisgreat.com/dodder/go/lib/charlie/quiter"
-	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 	"code.linenisgreat.com/dodder/go/lib/delta/catgut"
 )
 
+// makeApplyDormantAndRealizeTags is the stream index's preWrite, made once per
+// page flush so that tag expansions are shared by the objects of that page.
+func (store *Store) makeApplyDormantAndRealizeTags() interfaces.FuncIter[*sku.Transacted] {
+	tagExpansions := tag_paths.MakeExpansionCache()
+
+	return func(object *sku.Transacted) (err error) {
+		return store.applyDormantAndRealizeTags(object, tagExpansions)
+	}
+}
+
 // TODO extract into store_tags
 func (store *Store) applyDormantAndRealizeTags(
 	object *sku.Transacted,
+	tagExpansions *tag_paths.ExpansionCache,
 ) (err error) {
-	ui.Log().Print("applying konfig to:", object)
+	logger.Debug(
+		"applying konfig",
+		structured_log.ObjectId(object.GetObjectId()),
+	)
 	metadata := object.GetMetadataMutable()
 
 	genre := genres.Must(object.GetGenre())
@@ -56,12 +71,14 @@ func (store *Store) applyDormantAndRealizeTags(
 		return err
 	}
 
-	if err = store.addImplicitTags(object); err != nil {
+	if err = store.addImplicitTags(object, tagExpansions); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
-	object.SetDormant(store.dormantIndex.ContainsSku(object))
+	object.SetDormant(
+		store.dormantIndex.ContainsSku(object) || IsTrashed(object),
+	)
 
 	return err
 }
@@ -138,31 +155,49 @@ func (store *Store) addSuperTags(
 
 func (store *Store) addImplicitTags(
 	object *sku.Transacted,
+	tagExpansions *tag_paths.ExpansionCache,
 ) (err error) {
 	metadata := object.GetMetadataMutable()
 	tagSet := ids.MakeTagSetMutable()
 
 	tagPaths := object.GetMetadataMutable().GetIndexMutable().GetTagPaths()
 
+	config := store.storeConfig.GetConfig()
+
 	addImplicitTags := func(tag ids.Tag) (err error) {
-		tagPathWithType := tag_paths.MakePathWithType()
-		tagPathWithType.Type = tag_paths.TypeIndirect
-		tagStr, _ := catgut.MakeFromString(tag.String())
-		tagPathWithType.Add(tagStr)
+		expandImplicitTags := func(
+			tagString string,
+		) (paths tag_paths.PathsWithTypes) {
+			tagPathWithType := tag_paths.MakePathWithType()
+			tagPathWithType.Type = tag_paths.TypeIndirect
+			tagStr, _ := catgut.MakeFromString(tagString)
+			tagPathWithType.Add(tagStr)
+
+			implicitTags := config.GetImplicitTags(tag)
+
+			if implicitTags.Len() == 0 {
+				paths.AddPath(tagPathWithType)
+				return paths
+			}
 
-		implicitTags := store.storeConfig.GetConfig().GetImplicitTags(tag)
+			for implicitTag := range implicitTags.All() {
+				tagPathWithTypeClone := tagPathWithType.Clone()
+				implicitTagStr, _ := catgut.MakeFromString(implicitTag.String())
+				tagPathWithTypeClone.Add(implicitTagStr)
 
-		if implicitTags.Len() == 0 {
-			tagPaths.AddPathWithType(tagPathWithType)
-			return err
-		}
+				paths.AddPath(tagPathWithTypeClone)
+			}
 
-		for implicitTag := range implicitTags.All() {
-			tagPathWithTypeClone := tagPathWithType.Clone()
-			implicitTagStr, _ := catgut.MakeFromString(implicitTag.String())
-			tagPathWithTypeClone.Add(implicitTagStr)
+			return paths
+		}
 
-			tagPaths.AddPathWithType(tagPathWithTypeClone)
+		if err = tagExpansions.AddExpanded(
+			tagPaths,
+			tag.String(),
+			expandImplicitTags,
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
 		}
 
 		return err
@@ -175,7 +210,7 @@ func (store *Store) addImplicitTags(
 		}
 	}
 
-	typeObject := store.storeConfig.GetConfig().GetApproximatedType(
+	typeObject := config.GetApproximatedType(
 		metadata.GetType(),
 	).ApproximatedOrActual()
 
@@ -0,0 +1,265 @@
+package store
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/type_blobs"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+	"code.linenisgreat.com/dodder/go/lib/delta/script_config"
+)
+
+// ExternalTools are the diff and merge tools declared by a type object, nil
+// if it declares none, and the file extension blobs are given to them with.
+type ExternalTools struct {
+	Diff, Merge   *script_config.ExternalTool
+	FileExtension string
+}
+
+// ReadExternalTools reads the external tools declared by the type of object.
+func (store *Store) ReadExternalTools(
+	object *sku.Transacted,
+) (tools ExternalTools, err error) {
+	var typeObject *sku.Transacted
+
+	if typeObject, err = store.ReadObjectTypeAndLockIfNecessary(object); err != nil {
+		if errors.IsErrNotFound(err) {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return tools, err
+	} else if typeObject == nil {
+		return tools, err
+	}
+
+	var typeBlob type_blobs.Blob
+	var repool interfaces.FuncRepool
+
+	if typeBlob, repool, _, err = store.GetTypedBlobStore().Type.ParseTypedBlob(
+		typeObject.GetType(),
+		typeObject.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return tools, err
+	}
+
+	defer repool()
+
+	tools.FileExtension = typeBlob.GetFileExtension()
+
+	withExternalTools, ok := typeBlob.(type_blobs.WithExternalTools)
+
+	if !ok {
+		return tools, err
+	}
+
+	// the type blob is repooled, so the tools are copied out of it
+	cloneTool := func(tool *script_config.ExternalTool) *script_config.ExternalTool {
+		if tool == nil {
+			return nil
+		}
+
+		return &script_config.ExternalTool{
+			Command:    slices.Clone(tool.Command),
+			Timeout:    tool.Timeout,
+			InheritEnv: slices.Clone(tool.InheritEnv),
+		}
+	}
+
+	tools.Diff = cloneTool(withExternalTools.GetDiffTool())
+	tools.Merge = cloneTool(withExternalTools.GetMergeTool())
+
+	return tools, err
+}
+
+// DiffBlobsWithTool runs the diff tool of a type on the blobs of two versions
+// of an object, with a null blob given as an empty file, and returns its
+// output.
+func (store *Store) DiffBlobsWithTool(
+	tools ExternalTools,
+	before, after domain_interfaces.MarklId,
+) (diff string, err error) {
+	var dir string
+
+	if dir, err = store.envRepo.GetTempLocal().DirTempWithTemplate(
+		"diff-tool-*",
+	); err != nil {
+		err = errors.Wrap(err)
+		return diff, err
+	}
+
+	defer os.RemoveAll(dir)
+
+	var paths map[string]string
+
+	if paths, err = store.writeBlobsForTool(
+		dir,
+		tools.FileExtension,
+		map[string]domain_interfaces.MarklId{"old": before, "new": after},
+	); err != nil {
+		err = errors.Wrap(err)
+		return diff, err
+	}
+
+	var output []byte
+
+	// diff tools exit with 1 when their inputs differ
+	if output, err = tools.Diff.Run(store.envRepo, dir, paths, 1); err != nil {
+		err = errors.Wrap(err)
+		return diff, err
+	}
+
+	diff = string(output)
+
+	return diff, err
+}
+
+// MergeBlobsWithTool runs the merge tool of a type on the blobs of the local,
+// base, and remote versions of a conflicted object, and writes the file it
+// leaves at `{merged}` to the blob store. The merged file starts as a copy of
+// the local blob.
+func (store *Store) MergeBlobsWithTool(
+	tools ExternalTools,
+	conflicted sku.Conflicted,
+) (merged domain_interfaces.MarklId, err error) {
+	var dir string
+
+	if dir, err = store.envRepo.GetTempLocal().DirTempWithTemplate(
+		"merge-tool-*",
+	); err != nil {
+		err = errors.Wrap(err)
+		return merged, err
+	}
+
+	defer os.RemoveAll(dir)
+
+	var base domain_interfaces.MarklId
+
+	if conflicted.Base != nil {
+		base = conflicted.Base.GetBlobDigest()
+	}
+
+	var paths map[string]string
+
+	if paths, err = store.writeBlobsForTool(
+		dir,
+		tools.FileExtension,
+		map[string]domain_interfaces.MarklId{
+			"local":  conflicted.Local.GetBlobDigest(),
+			"base":   base,
+			"remote": conflicted.Remote.GetBlobDigest(),
+			"merged": conflicted.Local.GetBlobDigest(),
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return merged, err
+	}
+
+	if _, err = tools.Merge.Run(store.envRepo, dir, paths); err != nil {
+		err = errors.Wrap(err)
+		return merged, err
+	}
+
+	var file *os.File
+
+	if file, err = files.OpenReadOnly(paths["merged"]); err != nil {
+		err = errors.Wrap(err)
+		return merged, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	var blobWriter domain_interfaces.BlobWriter
+
+	if blobWriter, err = store.envRepo.GetDefaultBlobStore().MakeBlobWriter(
+		nil,
+	); err != nil {
+		err = errors.Wrap(err)
+		return merged, err
+	}
+
+	defer errors.DeferredCloser(&err, blobWriter)
+
+	if _, err = io.Copy(blobWriter, file); err != nil {
+		err = errors.Wrap(err)
+		return merged, err
+	}
+
+	var mergedId markl.Id
+	mergedId.ResetWithMarklId(blobWriter.GetMarklId())
+	merged = mergedId
+
+	return merged, err
+}
+
+// writeBlobsForTool writes each blob to a file in dir named after its
+// placeholder and the type's file extension, returning their paths.
+func (store *Store) writeBlobsForTool(
+	dir string,
+	fileExtension string,
+	blobIds map[string]domain_interfaces.MarklId,
+) (paths map[string]string, err error) {
+	paths = make(map[string]string, len(blobIds))
+
+	for name, blobId := range blobIds {
+		path := filepath.Join(dir, name)
+
+		if fileExtension != "" {
+			path += "." + fileExtension
+		}
+
+		if err = store.writeBlobForTool(path, blobId); err != nil {
+			err = errors.Wrap(err)
+			return paths, err
+		}
+
+		paths[name] = path
+	}
+
+	return paths, err
+}
+
+func (store *Store) writeBlobForTool(
+	path string,
+	blobId domain_interfaces.MarklId,
+) (err error) {
+	var file *os.File
+
+	if file, err = files.CreateExclusiveWriteOnly(path); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	if blobId == nil || blobId.IsNull() {
+		return err
+	}
+
+	var blobReader domain_interfaces.BlobReader
+
+	if blobReader, err = store.envRepo.GetDefaultBlobStore().MakeBlobReader(
+		blobId,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, blobReader)
+
+	if _, err = io.Copy(file, blobReader); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -1,9 +1,12 @@
 package store
 
 import (
+	"time"
+
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/internal/juliett/inventory_list_store"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/structured_log"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 )
@@ -19,7 +22,7 @@ func (store *Store) FlushInventoryList(
 		return err
 	}
 
-	ui.Log().Printf("saving inventory list")
+	start := time.Now()
 
 	var inventoryListSku *sku.Transacted
 
@@ -31,7 +34,7 @@ func (store *Store) FlushInventoryList(
 		store.workingList,
 	); err != nil {
 		if errors.Is(err, inventory_list_store.ErrEmptyInventoryList) {
-			ui.Log().Printf("inventory list was empty")
+			logger.Debug("inventory list was empty")
 			err = nil
 		} else {
 			err = errors.Wrap(err)
@@ -39,6 +42,7 @@ func (store *Store) FlushInventoryList(
 		}
 	} else {
 		// inventoryListSku ownership transfers to streamIndex.Add below
+		store.blobRefcounts.AddBlob(inventoryListSku.GetBlobDigest())
 	}
 
 	if inventoryListSku != nil {
@@ -70,7 +74,7 @@ func (store *Store) FlushInventoryList(
 		return err
 	}
 
-	ui.Log().Printf("done saving inventory list")
+	logger.Info("saved inventory list", structured_log.Since(start))
 
 	return err
 }
@@ -84,12 +88,26 @@ func (store *Store) Flush(
 	}
 
 	wg := errors.MakeWaitGroupParallel()
+	hadChanges := store.streamIndex.HasChanges()
 
 	if store.GetEnvRepo().GetLockSmith().IsAcquired() {
+		compactionOptions, errOptions := store.GetStreamIndexCompactionOptions()
+		if errOptions != nil {
+			// a bad config must not keep the lock from being released, so that
+			// it can still be fixed with edit-config
+			ui.Err().Printf(
+				"not compacting stream index after flush: %s",
+				errOptions,
+			)
+		} else {
+			store.streamIndex.SetCompactionOptions(compactionOptions)
+		}
+
 		wg.Do(func() error { return store.streamIndex.Flush(printerHeader) })
 		wg.Do(store.GetAbbrStore().Flush)
 		wg.Do(store.zettelIdIndex.Flush)
 		wg.Do(store.Abbr.Flush)
+		wg.Do(store.blobRefcounts.Flush)
 	}
 
 	if err = wg.GetError(); err != nil {
@@ -97,5 +115,9 @@ func (store *Store) Flush(
 		return err
 	}
 
+	if hadChanges {
+		store.clearQueryResultCache()
+	}
+
 	return err
 }
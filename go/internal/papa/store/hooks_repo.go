@@ -0,0 +1,267 @@
+package store
+
+import (
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/sku_lua"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+const (
+	repoHookPreCommit  = "on_pre_commit"
+	repoHookPostCommit = "on_post_commit"
+)
+
+// repoHooks are loaded once per process, the first time a commit runs hooks.
+// Their tables are exposed to their scripts as `Self`.
+type repoHooks struct {
+	once  sync.Once
+	hooks []luaScript
+	err   error
+}
+
+func (store *Store) getRepoHooks() (hooks []luaScript, err error) {
+	store.repoHooks.once.Do(func() {
+		store.repoHooks.hooks, store.repoHooks.err = store.loadLuaScripts(
+			"hooks.scripts",
+			store.GetConfigStore().GetConfig().GetHooks().Scripts,
+		)
+	})
+
+	return store.repoHooks.hooks, store.repoHooks.err
+}
+
+// tryRepoPreCommitHooks calls `on_pre_commit(object, mother)` of every repo
+// hook. A hook rejects the commit by returning a string, and otherwise the
+// tags and description it left on `object` are applied to the child.
+func (store *Store) tryRepoPreCommitHooks(
+	child *sku.Transacted,
+	mother *sku.Transacted,
+	options sku.CommitOptions,
+) (err error) {
+	if !options.RunHooks {
+		return err
+	}
+
+	var hooks []luaScript
+
+	if hooks, err = store.getRepoHooks(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	for _, hook := range hooks {
+		if err = store.tryRepoHook(
+			hook,
+			repoHookPreCommit,
+			child,
+			mother,
+			nil,
+		); err != nil {
+			err = errors.Wrapf(err, "Hook: %q", hook.object.GetObjectId())
+			return err
+		}
+	}
+
+	return err
+}
+
+// tryRepoPostCommitHooks calls `on_post_commit(object, mother)` of every repo
+// hook once the child is committed. Changes to `object` are discarded, and the
+// objects hooks pass to `dodder.create` are committed afterwards without
+// running hooks. The commit already happened, so failures are only printed.
+func (store *Store) tryRepoPostCommitHooks(
+	child *sku.Transacted,
+	mother *sku.Transacted,
+	options sku.CommitOptions,
+) {
+	if !options.RunHooks {
+		return
+	}
+
+	hooks, err := store.getRepoHooks()
+	if err != nil {
+		ui.Err().Printf("post-commit hooks failed to load: %s", err)
+		return
+	}
+
+	var created []*sku.Transacted
+
+	for _, hook := range hooks {
+		if err := store.tryRepoHook(
+			hook,
+			repoHookPostCommit,
+			child,
+			mother,
+			&created,
+		); err != nil {
+			ui.Err().Printf(
+				"post-commit hook %q failed: %s",
+				hook.object.GetObjectId(),
+				err,
+			)
+		}
+	}
+
+	for _, object := range created {
+		if err := store.Commit(
+			object,
+			sku.CommitOptions{
+				StoreOptions: sku.StoreOptions{
+					AddToInventoryList: true,
+					UpdateTai:          true,
+					Validate:           true,
+				},
+			},
+		); err != nil {
+			ui.Err().Printf("post-commit hook failed to create object: %s", err)
+		}
+	}
+}
+
+// tryRepoHook calls the named function of a hook's script table, if it has
+// one, with V2 tables of the child and mother. During the call the global
+// `dodder` table holds `notify`, and `create` when created is not nil.
+func (store *Store) tryRepoHook(
+	hook luaScript,
+	name string,
+	child *sku.Transacted,
+	mother *sku.Transacted,
+	created *[]*sku.Transacted,
+) (err error) {
+	vm, vmRepool := hook.vmPool.GetWithRepool()
+	defer vmRepool()
+
+	var top *lua.LTable
+
+	if top, err = vm.GetTopTableOrError(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	function := vm.GetField(top, name)
+
+	if function.Type() != lua.LTFunction {
+		return err
+	}
+
+	// VMs with a require function already have a `dodder` module
+	previous := vm.GetGlobal("dodder")
+	vm.SetGlobal("dodder", store.makeRepoHookModule(vm.VM, created))
+	defer vm.SetGlobal("dodder", previous)
+
+	tableChild, tableChildRepool := vm.TablePool.GetWithRepool()
+	defer tableChildRepool()
+
+	sku_lua.ToLuaTableV2(child, vm.LState, tableChild)
+
+	vm.Push(function)
+	vm.Push(tableChild.Transacted)
+
+	if mother != nil {
+		tableMother, tableMotherRepool := vm.TablePool.GetWithRepool()
+		defer tableMotherRepool()
+
+		sku_lua.ToLuaTableV2(mother, vm.LState, tableMother)
+		vm.Push(tableMother.Transacted)
+	} else {
+		vm.Push(lua.LNil)
+	}
+
+	if err = vm.PCall(2, 1, nil); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	retval := vm.LState.Get(-1)
+	vm.Pop(1)
+
+	if retval.Type() != lua.LTNil {
+		err = errors.ErrorWithStackf("rejected: %s", retval)
+		return err
+	}
+
+	if created != nil {
+		return err
+	}
+
+	if err = sku_lua.FromLuaTableV2(child, vm.LState, tableChild); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (store *Store) makeRepoHookModule(
+	vm *lua.VM,
+	created *[]*sku.Transacted,
+) *lua.LTable {
+	functions := map[string]lua.LGFunction{
+		"notify": func(state *lua.LState) int {
+			ui.Err().Print(state.CheckString(1))
+			return 0
+		},
+	}
+
+	if created != nil {
+		functions["create"] = func(state *lua.LState) int {
+			object, err := makeRepoHookObject(state, state.CheckTable(1))
+			if err != nil {
+				state.RaiseError("%s", err)
+				return 0
+			}
+
+			*created = append(*created, object)
+
+			return 0
+		}
+	}
+
+	return vm.SetFuncs(vm.NewTable(), functions)
+}
+
+// makeRepoHookObject reads a table passed to `dodder.create`, which has the
+// fields of an object table, all of them optional. Without an object id a new
+// zettel id is assigned on commit.
+func makeRepoHookObject(
+	state *lua.LState,
+	table *lua.LTable,
+) (object *sku.Transacted, err error) {
+	var repool interfaces.FuncRepool
+	object, repool = sku.GetTransactedPool().GetWithRepool()
+
+	if _, ok := state.GetField(table, "ObjectId").(lua.LString); !ok {
+		state.SetField(table, "ObjectId", lua.LString(""))
+	}
+
+	if _, ok := state.GetField(table, "Tags").(*lua.LTable); !ok {
+		state.SetField(table, "Tags", state.NewTable())
+	}
+
+	if err = sku_lua.FromLuaTableV2(
+		object,
+		state,
+		&sku_lua.LuaTableV2{Transacted: table},
+	); err != nil {
+		repool()
+		err = errors.Wrap(err)
+		return object, err
+	}
+
+	if tipe, ok := state.GetField(table, "Type").(lua.LString); ok {
+		if err = object.GetMetadataMutable().GetTypeMutable().SetType(
+			string(tipe),
+		); err != nil {
+			repool()
+			err = errors.Wrap(err)
+			return object, err
+		}
+	}
+
+	return object, err
+}
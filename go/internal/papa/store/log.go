@@ -0,0 +1,62 @@
+package store
+
+import (
+	"log/slog"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/lib/alfa/structured_log"
+)
+
+var logger = structured_log.Component("store")
+
+// logCommit is deferred by commit, after which the object id of a new object
+// is known.
+func logCommit(
+	object *sku.Transacted,
+	options sku.CommitOptions,
+	start time.Time,
+	err *error,
+) {
+	if *err != nil {
+		logger.Debug(
+			"commit failed",
+			structured_log.ObjectId(object.GetObjectId()),
+			structured_log.Since(start),
+			"error",
+			*err,
+		)
+
+		return
+	}
+
+	logger.Debug(
+		"committed",
+		structured_log.ObjectId(object.GetObjectId()),
+		structured_log.Since(start),
+		slog.Bool("inventory_list", options.AddToInventoryList),
+		slog.Bool(
+			"stream_index",
+			options.StreamIndexOptions.AddToStreamIndex,
+		),
+	)
+}
+
+// logQuery is deferred by QueryTransacted.
+func logQuery(query *queries.Query, start time.Time, err *error) {
+	if *err != nil {
+		logger.Info(
+			"query failed",
+			"query",
+			query.String(),
+			structured_log.Since(start),
+			"error",
+			*err,
+		)
+
+		return
+	}
+
+	logger.Info("query", "query", query.String(), structured_log.Since(start))
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 
 	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/internal/hotel/sku_lua"
 	"code.linenisgreat.com/dodder/go/internal/india/tag_blobs"
@@ -60,6 +61,26 @@ func (store *Store) MakeLuaVMPoolV1(
 	return vp, err
 }
 
+func (store *Store) MakeLuaVMPoolV2(
+	self *sku.Transacted,
+	script string,
+) (vmPool sku_lua.LuaVMPoolV2, err error) {
+	builder := store.envLua.MakeLuaVMPoolBuilder().
+		WithScript(script).
+		WithApply(tag_blobs.MakeLuaSelfApplyV2(self))
+
+	var luaVMPool *lua.VMPool
+
+	if luaVMPool, err = builder.Build(); err != nil {
+		err = errors.Wrap(err)
+		return vmPool, err
+	}
+
+	vmPool = sku_lua.MakeLuaVMPoolV2(luaVMPool, self)
+
+	return vmPool, err
+}
+
 func (store *Store) MakeLuaVMPoolWithReader(
 	selbst *sku.Transacted,
 	r io.Reader,
@@ -79,3 +100,86 @@ func (store *Store) MakeLuaVMPoolWithReader(
 
 	return vp, err
 }
+
+func (store *Store) MakeLuaVMPoolV2WithSku(
+	object *sku.Transacted,
+) (vmPool sku_lua.LuaVMPoolV2, err error) {
+	if object.GetType().String() != "!lua" {
+		err = errors.ErrorWithStackf(
+			"unsupported type: %s, Sku: %s",
+			object.GetType(),
+			object,
+		)
+		return vmPool, err
+	}
+
+	var readCloser domain_interfaces.BlobReader
+
+	if readCloser, err = store.GetEnvRepo().GetDefaultBlobStore().MakeBlobReader(
+		object.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return vmPool, err
+	}
+
+	defer errors.DeferredCloser(&err, readCloser)
+
+	builder := store.envLua.MakeLuaVMPoolBuilder().
+		WithReader(readCloser).
+		WithApply(tag_blobs.MakeLuaSelfApplyV2(object))
+
+	var luaVMPool *lua.VMPool
+
+	if luaVMPool, err = builder.Build(); err != nil {
+		err = errors.Wrap(err)
+		return vmPool, err
+	}
+
+	vmPool = sku_lua.MakeLuaVMPoolV2(luaVMPool, object)
+
+	return vmPool, err
+}
+
+// luaScript is a `!lua` object named in the repo config, along with the pooled
+// VMs that run its blob.
+type luaScript struct {
+	object *sku.Transacted
+	vmPool sku_lua.LuaVMPoolV2
+}
+
+// loadLuaScripts reads the `!lua` objects a repo config key names and builds
+// their VM pools.
+func (store *Store) loadLuaScripts(
+	key string,
+	objectIdStrings []string,
+) (scripts []luaScript, err error) {
+	for _, objectIdString := range objectIdStrings {
+		var objectId ids.ObjectId
+
+		if err = objectId.Set(objectIdString); err != nil {
+			err = errors.Wrapf(err, "%s: %q", key, objectIdString)
+			return scripts, err
+		}
+
+		var object *sku.Transacted
+
+		if object, err = store.ReadOneObjectId(&objectId); err != nil {
+			err = errors.Wrapf(err, "%s: %q", key, objectIdString)
+			return scripts, err
+		} else if object == nil {
+			err = errors.ErrorWithStackf("%s: %q: not found", key, objectIdString)
+			return scripts, err
+		}
+
+		var vmPool sku_lua.LuaVMPoolV2
+
+		if vmPool, err = store.MakeLuaVMPoolV2WithSku(object); err != nil {
+			err = errors.Wrapf(err, "%s: %q", key, objectIdString)
+			return scripts, err
+		}
+
+		scripts = append(scripts, luaScript{object: object, vmPool: vmPool})
+	}
+
+	return scripts, err
+}
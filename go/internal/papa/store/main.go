@@ -7,6 +7,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/zettel_id_index"
 	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/blob_refcount_index"
 	"code.linenisgreat.com/dodder/go/internal/hotel/box_format"
 	"code.linenisgreat.com/dodder/go/internal/hotel/dormant_index"
 	"code.linenisgreat.com/dodder/go/internal/hotel/object_finalizer"
@@ -40,6 +41,10 @@ type Store struct {
 	finalizer     object_finalizer.Finalizer
 	zettelIdIndex zettel_id_index.Index
 	dormantIndex  *dormant_index.Index
+	blobRefcounts *blob_refcount_index.Index
+	objectCache   *objectCache
+	repoHooks     repoHooks
+	virtualTags   virtualTagProviders
 
 	protoZettel  sku.Proto
 	queryBuilder *queries.Builder
@@ -98,7 +103,7 @@ func (store *Store) Initialize(
 
 	if store.streamIndex, err = stream_index.MakeIndex(
 		store.GetEnvRepo(),
-		store.applyDormantAndRealizeTags,
+		store.makeApplyDormantAndRealizeTags,
 		store.GetEnvRepo().DirIndexObjects(),
 		store.sunrise,
 	); err != nil {
@@ -106,7 +111,15 @@ func (store *Store) Initialize(
 		return err
 	}
 
+	if store.blobRefcounts, err = blob_refcount_index.Make(
+		store.GetEnvRepo().FileCacheBlobRefcounts(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
 	store.finalizer = object_finalizer.Make()
+	store.objectCache = makeObjectCache(ObjectCacheCapacity)
 
 	store.protoZettel = sku.MakeProto(
 		store.envWorkspace.GetDefaults(),
@@ -145,6 +158,9 @@ func (store *Store) ResetIndexes() (err error) {
 		return err
 	}
 
+	store.objectCache.reset()
+	store.clearQueryResultCache()
+
 	return err
 }
 
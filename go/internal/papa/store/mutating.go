@@ -1,6 +1,8 @@
 package store
 
 import (
+	"time"
+
 	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
 	"code.linenisgreat.com/dodder/go/internal/alfa/store_version"
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
@@ -10,9 +12,9 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/echo/file_lock"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/structured_log"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/expansion"
-	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 )
 
 func (store *Store) Commit(
@@ -38,9 +40,9 @@ type commitFacilitator struct {
 	index sku.Reindexer
 }
 
-// Saves the blob if necessary, applies the proto object, runs pre-commit hooks,
-// runs the new hook, validates the blob, then calculates the digest for the
-// object
+// Saves the blob if necessary, applies the proto object and the type's
+// template, runs the type, config, and repo pre-commit hooks, runs the new hook, then validates the blob against
+// the schema of its type
 func (commitFacilitator commitFacilitator) tryPrecommit(
 	daughter *sku.Transacted,
 	mother *sku.Transacted,
@@ -68,6 +70,13 @@ func (commitFacilitator commitFacilitator) tryPrecommit(
 		}
 	}
 
+	if mother == nil && options.ApplyTemplate {
+		if err = commitFacilitator.applyTemplate(daughter); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	// modify pre commit hooks to support import
 	if err = commitFacilitator.tryPreCommitHooks(daughter, mother, options); err != nil {
 		if commitFacilitator.storeConfig.GetConfig().IgnoreHookErrors {
@@ -78,6 +87,15 @@ func (commitFacilitator commitFacilitator) tryPrecommit(
 		}
 	}
 
+	if err = commitFacilitator.tryRepoPreCommitHooks(daughter, mother, options); err != nil {
+		if commitFacilitator.storeConfig.GetConfig().IgnoreHookErrors {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	// TODO just just mutter == nil
 	if mother == nil {
 		if err = commitFacilitator.tryNewHook(daughter, options); err != nil {
@@ -90,6 +108,11 @@ func (commitFacilitator commitFacilitator) tryPrecommit(
 		}
 	}
 
+	if err = commitFacilitator.validateBlobSchema(daughter, mother, options); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
 	return err
 }
 
@@ -103,7 +126,7 @@ func (commitFacilitator commitFacilitator) commit(
 		panic("empty daughter")
 	}
 
-	ui.Log().Printf("%s -> %s", options, daughter)
+	defer logCommit(daughter, options, time.Now(), &err)
 
 	// TODO remove this lock check and perform it when actually necessary (when
 	// persisting the changes on flush).
@@ -158,6 +181,16 @@ func (commitFacilitator commitFacilitator) commit(
 		return err
 	}
 
+	var missingTags []*ids.ObjectId
+
+	if missingTags, err = commitFacilitator.getMissingTags(
+		options,
+		daughter,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
 	{
 		if options.AddToInventoryList {
 			if err = commitFacilitator.addMissingTypes(
@@ -211,6 +244,7 @@ func (commitFacilitator commitFacilitator) commit(
 		// tags
 		if err = commitFacilitator.applyDormantAndRealizeTags(
 			daughter,
+			nil,
 		); err != nil {
 			err = errors.Wrap(err)
 			return err
@@ -219,7 +253,12 @@ func (commitFacilitator commitFacilitator) commit(
 		if daughter.GetGenre() == genres.Zettel {
 			if err = commitFacilitator.zettelIdIndex.AddZettelId(daughter.GetObjectIdMutable()); err != nil {
 				if errors.Is(err, zettel_id_provider.ErrDoesNotExist{}) {
-					ui.Log().Printf("object id does not contain value: %s", err)
+					logger.Debug(
+						"zettel id not in the id provider",
+						structured_log.ObjectId(daughter.GetObjectId()),
+						"error",
+						err,
+					)
 					err = nil
 				} else {
 					err = errors.Wrapf(err, "failed to write zettel to index: %s", daughter)
@@ -237,6 +276,13 @@ func (commitFacilitator commitFacilitator) commit(
 			err = errors.Wrapf(err, "Sku: %s", sku.String(daughter))
 			return err
 		}
+
+		// only once the daughter is validated and in the working list, so that
+		// a rejected daughter leaves no placeholders behind
+		if err = commitFacilitator.addMissingTags(missingTags); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
 	}
 
 	if options.AddToInventoryList ||
@@ -268,6 +314,8 @@ func (commitFacilitator commitFacilitator) commit(
 			return err
 		}
 
+		commitFacilitator.invalidateCachedObjects(daughter)
+
 		if mother == nil {
 			if daughter.GetGenre() == genres.Zettel {
 				// TODO if this is a local zettel (i.e., not a different repo
@@ -297,6 +345,9 @@ func (commitFacilitator commitFacilitator) commit(
 			}
 		}
 
+		if options.AddToInventoryList {
+			commitFacilitator.tryRepoPostCommitHooks(daughter, mother, options)
+		}
 	}
 
 	if options.MergeCheckedOut {
@@ -358,6 +409,8 @@ func (commitFacilitator commitFacilitator) commitTransacted(
 		return err
 	}
 
+	commitFacilitator.blobRefcounts.AddBlob(daughter.GetBlobDigest())
+
 	return err
 }
 
@@ -367,30 +420,32 @@ func (commitFacilitator commitFacilitator) handleUnchanged(
 	return commitFacilitator.ui.TransactedUnchanged(object)
 }
 
-func (commitFacilitator commitFacilitator) createType(
-	typeId *ids.ObjectId,
+// creates a minimal object for a type or tag that is referenced but has no
+// object of its own
+func (commitFacilitator commitFacilitator) createPlaceholder(
+	objectId *ids.ObjectId,
 ) (err error) {
-	typeObject, typeObjectRepool := sku.GetTransactedPool().GetWithRepool()
-	defer typeObjectRepool()
+	placeholder, placeholderRepool := sku.GetTransactedPool().GetWithRepool()
+	defer placeholderRepool()
 
-	switch typeId.GetGenre() {
+	switch genre := genres.Must(objectId.GetGenre()); genre {
 	default:
-		err = genres.MakeErrUnsupportedGenre(typeId.GetGenre())
+		err = genres.MakeErrUnsupportedGenre(objectId.GetGenre())
 		return err
 
-	case genres.Type:
-		typeObject.GetMetadataMutable().GetTypeMutable().ResetWithObjectId(
-			ids.DefaultOrPanic(genres.Type),
+	case genres.Type, genres.Tag:
+		placeholder.GetMetadataMutable().GetTypeMutable().ResetWithObjectId(
+			ids.DefaultOrPanic(genre),
 		)
 	}
 
-	if err = typeObject.GetObjectIdMutable().SetWithId(typeId); err != nil {
+	if err = placeholder.GetObjectIdMutable().SetWithId(objectId); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
 	if err = commitFacilitator.Commit(
-		typeObject,
+		placeholder,
 		sku.CommitOptions{
 			StoreOptions: sku.GetStoreOptionsUpdate(),
 		},
@@ -433,7 +488,7 @@ func (commitFacilitator commitFacilitator) addTypeIfNecessary(
 		return err
 	}
 
-	if err = commitFacilitator.createType(&typeObjectId); err != nil {
+	if err = commitFacilitator.createPlaceholder(&typeObjectId); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
@@ -484,3 +539,104 @@ func (commitFacilitator commitFacilitator) addMissingTypes(
 
 	return err
 }
+
+// returns the id of tag if it should get a placeholder object, or nil
+func (commitFacilitator commitFacilitator) getMissingTag(
+	tag ids.TagStruct,
+) (objectId *ids.ObjectId, err error) {
+	if tag.IsEmpty() || tag.IsVirtual() || tag.IsDodderTag() ||
+		tag.IsDependentLeaf() {
+		return objectId, err
+	}
+
+	objectId = &ids.ObjectId{}
+
+	if err = objectId.SetWithId(tag); err != nil {
+		err = errors.Wrap(err)
+		return nil, err
+	}
+
+	if err = commitFacilitator.index.ObjectExists(objectId); err == nil {
+		return nil, err
+	}
+
+	err = nil
+
+	return objectId, err
+}
+
+// Only runs when auto-vivification is enabled in the repo config, as unlike
+// types, tags are commonly used without ever having objects of their own. Only
+// the tags the object carries are returned, not the prefixes they imply, and
+// their placeholders are committed by addMissingTags once the object itself is
+// validated.
+func (commitFacilitator commitFacilitator) getMissingTags(
+	commitOptions sku.CommitOptions,
+	object *sku.Transacted,
+) (missing []*ids.ObjectId, err error) {
+	if !commitOptions.AddToInventoryList || commitOptions.DontAddMissingType ||
+		!commitFacilitator.storeConfig.GetConfig().IsAutoVivifyEnabled() {
+		return missing, err
+	}
+
+	var selfObjectIdString string
+
+	if object.GetGenre() == genres.Tag {
+		selfObjectIdString = object.GetObjectId().String()
+	}
+
+	for tag := range object.AllTags() {
+		if tag.String() == selfObjectIdString {
+			continue
+		}
+
+		var tagStruct ids.TagStruct
+
+		if tagStruct, err = ids.MakeTag(tag.String()); err != nil {
+			err = errors.Wrap(err)
+			return missing, err
+		}
+
+		var objectId *ids.ObjectId
+
+		if objectId, err = commitFacilitator.getMissingTag(tagStruct); err != nil {
+			err = errors.Wrap(err)
+			return missing, err
+		}
+
+		if objectId != nil {
+			missing = append(missing, objectId)
+		}
+	}
+
+	return missing, err
+}
+
+func (commitFacilitator commitFacilitator) addMissingTags(
+	missing []*ids.ObjectId,
+) (err error) {
+	for _, objectId := range missing {
+		if err = commitFacilitator.createPlaceholder(objectId); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
+
+// invalidateCachedObjects drops the cached versions of a committed object.
+// Tags, types, and the config are realized into every other object as
+// implicit tags and dormancy, so committing one of those drops every cached
+// object instead.
+func (commitFacilitator commitFacilitator) invalidateCachedObjects(
+	daughter *sku.Transacted,
+) {
+	if daughter.GetGenre() == genres.Zettel {
+		commitFacilitator.objectCache.invalidate(
+			daughter.GetObjectId().String(),
+		)
+	} else {
+		commitFacilitator.objectCache.reset()
+	}
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+)
+
+// ObjectCacheCapacity is how many decoded objects ReadOneObjectId keeps.
+const ObjectCacheCapacity = 1024
+
+// objectCacheLabel is the store label object cache hits and misses are
+// reported to the blob store observer with.
+const objectCacheLabel = "object cache"
+
+type objectCacheKey struct {
+	objectId string
+	tai      ids.Tai
+}
+
+type objectCacheEntry struct {
+	key    objectCacheKey
+	object *sku.Transacted
+	repool interfaces.FuncRepool
+}
+
+// objectCache is a size-bounded LRU of the latest versions read by
+// ReadOneObjectId, so that hot objects like type configs are decoded once per
+// process. Entries are keyed by object id and tai, and latest points every
+// cached object id at the version that was read last, so a cached version is
+// never returned for another. Commits invalidate the object ids they write.
+//
+// Objects are cloned in and out of the cache, since callers own the objects
+// they read.
+type objectCache struct {
+	lock     sync.Mutex
+	capacity int
+	entries  map[objectCacheKey]*list.Element
+	latest   map[string]ids.Tai
+	order    list.List
+}
+
+func makeObjectCache(capacity int) *objectCache {
+	return &objectCache{
+		capacity: capacity,
+		entries:  make(map[objectCacheKey]*list.Element, capacity),
+		latest:   make(map[string]ids.Tai, capacity),
+	}
+}
+
+func (cache *objectCache) get(objectId string) (object *sku.Transacted, ok bool) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	defer func() {
+		blob_stores.ObserveCache(objectCacheLabel, ok)
+	}()
+
+	var tai ids.Tai
+
+	if tai, ok = cache.latest[objectId]; !ok {
+		return object, ok
+	}
+
+	var element *list.Element
+
+	if element, ok = cache.entries[objectCacheKey{objectId, tai}]; !ok {
+		return object, ok
+	}
+
+	cache.order.MoveToFront(element)
+	object, _ = element.Value.(*objectCacheEntry).object.CloneTransacted()
+
+	return object, ok
+}
+
+func (cache *objectCache) add(object *sku.Transacted) {
+	if cache.capacity <= 0 {
+		return
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	objectId := object.GetObjectId().String()
+	key := objectCacheKey{objectId: objectId, tai: object.GetTai()}
+
+	cache.removeLocked(objectId)
+
+	entry := &objectCacheEntry{key: key}
+	entry.object, entry.repool = object.CloneTransacted()
+
+	cache.entries[key] = cache.order.PushFront(entry)
+	cache.latest[objectId] = key.tai
+
+	for cache.order.Len() > cache.capacity {
+		cache.removeElementLocked(cache.order.Back())
+	}
+}
+
+func (cache *objectCache) invalidate(objectId string) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.removeLocked(objectId)
+}
+
+func (cache *objectCache) reset() {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	for element := cache.order.Front(); element != nil; element = cache.order.Front() {
+		cache.removeElementLocked(element)
+	}
+}
+
+func (cache *objectCache) removeLocked(objectId string) {
+	tai, ok := cache.latest[objectId]
+
+	if !ok {
+		return
+	}
+
+	if element, ok := cache.entries[objectCacheKey{objectId, tai}]; ok {
+		cache.removeElementLocked(element)
+	} else {
+		delete(cache.latest, objectId)
+	}
+}
+
+func (cache *objectCache) removeElementLocked(element *list.Element) {
+	entry := cache.order.Remove(element).(*objectCacheEntry)
+
+	delete(cache.entries, entry.key)
+
+	if tai, ok := cache.latest[entry.key.objectId]; ok && tai == entry.key.tai {
+		delete(cache.latest, entry.key.objectId)
+	}
+
+	entry.repool()
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"slices"
 	"sync"
+	"time"
 
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
@@ -55,6 +56,8 @@ func (store *Store) QueryTransacted(
 	group *queries.Query,
 	funcIter interfaces.FuncIter[*sku.Transacted],
 ) (err error) {
+	defer logQuery(group, time.Now(), &err)
+
 	var executor queries.Executor
 
 	if executor, err = store.makeQueryExecutor(group); err != nil {
@@ -65,7 +68,8 @@ func (store *Store) QueryTransacted(
 	var object *sku.Transacted
 
 	switch {
-	case true:
+	// exactly one object id reads its latest version, not the one as of a tai
+	case group.AsOf.IsEmpty():
 		// TODO why does this not work with trying to read internal
 		if object, err = executor.ExecuteExactlyOneExternalObject(false); err != nil {
 			err = nil
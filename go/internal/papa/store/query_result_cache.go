@@ -0,0 +1,81 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// makeQueryResultCache returns the result cache of query executors, or nil
+// while the stream index has changes that are not flushed, since queries read
+// those but the generation does not cover them.
+func (store *Store) makeQueryResultCache() *queries.ResultCache {
+	if store.streamIndex.HasChanges() {
+		return nil
+	}
+
+	generation, err := store.queryResultGeneration()
+	if err != nil {
+		logger.Debug("not caching query results", "error", err)
+		return nil
+	}
+
+	return queries.MakeResultCache(
+		store.GetEnvRepo().DirCacheQueryResults(),
+		generation,
+	)
+}
+
+// queryResultGeneration identifies the state of everything query results are
+// read from: every commit appends to the inventory list log, dormancy changes
+// rewrite the dormant index, and flushes, compactions, and reindexes rename
+// stream index pages into its directory.
+func (store *Store) queryResultGeneration() (generation string, err error) {
+	envRepo := store.GetEnvRepo()
+
+	var sb strings.Builder
+
+	for _, path := range []string{
+		envRepo.FileInventoryListLog(),
+		envRepo.FileCacheDormant(),
+		envRepo.DirIndexObjects(),
+	} {
+		var info os.FileInfo
+
+		if info, err = os.Stat(path); err != nil {
+			if errors.IsNotExist(err) {
+				err = nil
+				fmt.Fprintf(&sb, "%s missing\n", path)
+				continue
+			}
+
+			err = errors.Wrap(err)
+			return generation, err
+		}
+
+		fmt.Fprintf(
+			&sb,
+			"%s %d %d\n",
+			path,
+			info.Size(),
+			info.ModTime().UnixNano(),
+		)
+	}
+
+	generation = sb.String()
+
+	return generation, err
+}
+
+// clearQueryResultCache removes the cached results of earlier generations,
+// which are never read again once their generation is gone.
+func (store *Store) clearQueryResultCache() {
+	if err := os.RemoveAll(
+		store.GetEnvRepo().DirCacheQueryResults(),
+	); err != nil {
+		logger.Warn("failed to clear query result cache", "error", err)
+	}
+}
@@ -96,6 +96,13 @@ func (store *Store) ReadOneObjectId(
 		return object, err
 	}
 
+	objectIdString := objectId.String()
+
+	if cached, ok := store.objectCache.get(objectIdString); ok {
+		object = cached
+		return object, err
+	}
+
 	object, _ = sku.GetTransactedPool().GetWithRepool()
 
 	if err = store.streamIndex.ReadOneObjectId(objectId, object); err != nil {
@@ -106,6 +113,8 @@ func (store *Store) ReadOneObjectId(
 		return object, err
 	}
 
+	store.objectCache.add(object)
+
 	return object, err
 }
 
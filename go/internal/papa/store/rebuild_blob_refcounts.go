@@ -0,0 +1,35 @@
+package store
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/echo/file_lock"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// RebuildBlobRefcounts recounts the blob references of every object version
+// in every inventory list, without touching the other indexes. If a list
+// cannot be read the index is invalidated rather than left undercounted.
+func (store *Store) RebuildBlobRefcounts() (err error) {
+	if !store.GetEnvRepo().GetLockSmith().IsAcquired() {
+		err = file_lock.ErrLockRequired{
+			Operation: "rebuild blob refcounts",
+		}
+
+		return err
+	}
+
+	store.blobRefcounts.Reset()
+
+	seq := store.GetInventoryListStore().AllInventoryListObjectsAndContents()
+
+	for objectWithList, iterErr := range seq {
+		if iterErr != nil {
+			store.blobRefcounts.Invalidate()
+			err = errors.Wrap(iterErr)
+			return err
+		}
+
+		store.blobRefcounts.AddBlob(objectWithList.Object.GetBlobDigest())
+	}
+
+	return err
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"code.linenisgreat.com/dodder/go/internal/echo/file_lock"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/juliett/inventory_list_store"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
@@ -10,6 +11,24 @@ import (
 
 // TODO-P2 add support for quiet reindexing
 func (store *Store) Reindex(context interfaces.ActiveContext) (err error) {
+	return store.reindex(context, false)
+}
+
+// ReindexFromCheckpoint is Reindex resuming from the latest verified
+// inventory list checkpoint, replaying only its objects and the lists after
+// it. Versions the checkpoint replaced are not indexed, and the blob refcount
+// index is invalidated, as it needs every version. Without a verified
+// checkpoint it replays every list.
+func (store *Store) ReindexFromCheckpoint(
+	context interfaces.ActiveContext,
+) (err error) {
+	return store.reindex(context, true)
+}
+
+func (store *Store) reindex(
+	context interfaces.ActiveContext,
+	fromCheckpoint bool,
+) (err error) {
 	if !store.GetEnvRepo().GetLockSmith().IsAcquired() {
 		err = file_lock.ErrLockRequired{
 			Operation: "reindex",
@@ -48,11 +67,44 @@ func (store *Store) Reindex(context interfaces.ActiveContext) (err error) {
 	objectsWithErrors := make(map[string]objectWithError)
 	unidentifiedErrors := make([]error, 0)
 
-	seq := store.GetInventoryListStore().AllInventoryListObjectsAndContents()
+	var checkpoint inventory_list_store.Checkpoint
+
+	if fromCheckpoint {
+		var ok bool
+
+		if checkpoint, ok, err = store.GetInventoryListStore().LatestVerifiedCheckpoint(); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if ok {
+			store.envRepo.GetUI().Printf(
+				"resuming from the checkpoint at inventory list %d",
+				checkpoint.ListCount,
+			)
+		} else {
+			store.envRepo.GetUI().Print(
+				"no verified checkpoint, replaying every inventory list",
+			)
+		}
+	}
+
+	seq := store.GetInventoryListStore().AllInventoryListObjectsAndContentsSince(
+		checkpoint,
+	)
+
+	store.blobRefcounts.Reset()
+
+	if checkpoint.ListCount > 0 {
+		store.blobRefcounts.Invalidate()
+	}
 
 	// TODO switch to reusing fsck command structure
 	for objectWithList, iterErr := range seq {
 		if iterErr != nil {
+			// an unreadable list leaves its blobs uncounted
+			store.blobRefcounts.Invalidate()
+
 			if objectWithList.List == nil {
 				unidentifiedErrors = append(unidentifiedErrors, iterErr)
 			} else {
@@ -73,6 +125,8 @@ func (store *Store) Reindex(context interfaces.ActiveContext) (err error) {
 			panic("empty object")
 		}
 
+		store.blobRefcounts.AddBlob(objectWithList.Object.GetBlobDigest())
+
 		if err = store.reindexOne(commitFacilitator, objectWithList); err != nil {
 			keyBytes := objectWithList.List.GetObjectDigest().GetBytes()
 
@@ -2,8 +2,10 @@ package store
 
 import (
 	"code.linenisgreat.com/dodder/go/internal/_/checkout_mode"
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/alfa/checkout_options"
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	pkg_query "code.linenisgreat.com/dodder/go/internal/kilo/queries"
 	"code.linenisgreat.com/dodder/go/internal/november/env_workspace"
@@ -152,6 +154,7 @@ func (store *Store) makeQueryExecutor(
 		store.streamIndex.ReadPrimitiveQuery,
 		store.ReadOneInto,
 		externalStore,
+		store.makeQueryResultCache(),
 	)
 
 	return executor, err
@@ -181,6 +184,15 @@ func (store *Store) RunMergeTool(
 		err = comments.Implement()
 
 	default:
+		var mergedBlobId domain_interfaces.MarklId
+
+		if mergedBlobId, err = store.mergeBlobsIfNecessary(
+			conflicted,
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
 		var checkedOut sku.SkuType
 
 		if checkedOut, err = store.envWorkspace.GetStoreFS().RunMergeTool(
@@ -193,7 +205,13 @@ func (store *Store) RunMergeTool(
 
 		defer store.PutCheckedOutLike(checkedOut)
 
-		if err = store.CreateOrUpdateCheckedOut(checkedOut, false); err != nil {
+		if mergedBlobId != nil {
+			checkedOut.GetSkuExternal().GetMetadataMutable().GetBlobDigestMutable().ResetWithMarklId(
+				mergedBlobId,
+			)
+		}
+
+		if err = store.CreateOrUpdateCheckedOut(checkedOut, false, false); err != nil {
 			err = errors.Wrap(err)
 			return err
 		}
@@ -202,6 +220,39 @@ func (store *Store) RunMergeTool(
 	return err
 }
 
+// mergeBlobsIfNecessary merges the differing blobs of a conflicted object
+// whose type is not inline with the merge tool its type declares, returning
+// nil if there is nothing to merge or no tool to merge it with.
+func (store *Store) mergeBlobsIfNecessary(
+	conflicted sku.Conflicted,
+) (merged domain_interfaces.MarklId, err error) {
+	if conflicted.IsAllInlineType(store.storeConfig.GetConfig()) ||
+		markl.Equals(
+			conflicted.Local.GetBlobDigest(),
+			conflicted.Remote.GetBlobDigest(),
+		) {
+		return merged, err
+	}
+
+	var tools ExternalTools
+
+	if tools, err = store.ReadExternalTools(conflicted.Local); err != nil {
+		err = errors.Wrap(err)
+		return merged, err
+	}
+
+	if tools.Merge == nil {
+		return merged, err
+	}
+
+	if merged, err = store.MergeBlobsWithTool(tools, conflicted); err != nil {
+		err = errors.Wrap(err)
+		return merged, err
+	}
+
+	return merged, err
+}
+
 func (store *Store) UpdateTransactedWithExternal(
 	repoId ids.RepoId,
 	z *sku.Transacted,
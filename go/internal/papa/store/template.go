@@ -0,0 +1,160 @@
+package store
+
+import (
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/object_templates"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/type_blobs"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// applyTemplate gives an object without a blob the expanded template declared
+// by its type object, if any.
+func (store *Store) applyTemplate(object *sku.Transacted) (err error) {
+	if !object.GetBlobDigest().IsNull() {
+		return err
+	}
+
+	var typeObject *sku.Transacted
+
+	if typeObject, err = store.ReadObjectTypeAndLockIfNecessary(object); err != nil {
+		if errors.IsErrNotFound(err) {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return err
+	} else if typeObject == nil {
+		return err
+	}
+
+	var typeBlob type_blobs.Blob
+	var repool interfaces.FuncRepool
+
+	if typeBlob, repool, _, err = store.GetTypedBlobStore().Type.ParseTypedBlob(
+		typeObject.GetType(),
+		typeObject.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer repool()
+
+	withTemplate, ok := typeBlob.(type_blobs.WithTemplate)
+
+	if !ok || withTemplate.GetTemplate() == "" {
+		return err
+	}
+
+	var templateBlobId domain_interfaces.MarklId
+
+	if templateBlobId, err = store.readTemplateBlobId(
+		withTemplate.GetTemplate(),
+	); err != nil {
+		err = errors.Wrapf(err, "type: %q", object.GetType())
+		return err
+	}
+
+	blobStore := store.GetEnvRepo().GetDefaultBlobStore()
+
+	var text string
+
+	{
+		var blobReader domain_interfaces.BlobReader
+
+		if blobReader, err = blobStore.MakeBlobReader(templateBlobId); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		defer errors.DeferredCloser(&err, blobReader)
+
+		var bites []byte
+
+		if bites, err = io.ReadAll(blobReader); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		text = string(bites)
+	}
+
+	var expanded string
+
+	if expanded, err = object_templates.Expand(
+		object.GetType().String(),
+		text,
+		object_templates.MakeView(
+			object.GetObjectId().String(),
+			object.GetMetadataMutable(),
+			withTemplate.GetTemplateFields(),
+		),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var blobWriter domain_interfaces.BlobWriter
+
+	if blobWriter, err = blobStore.MakeBlobWriter(nil); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, blobWriter)
+
+	if _, err = io.WriteString(blobWriter, expanded); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	object.GetMetadataMutable().GetBlobDigestMutable().ResetWithMarklId(
+		blobWriter.GetMarklId(),
+	)
+
+	return err
+}
+
+// readTemplateBlobId resolves the `template` of a type config, which is
+// either a blob id or the id of an object whose blob is the template.
+func (store *Store) readTemplateBlobId(
+	template string,
+) (blobId domain_interfaces.MarklId, err error) {
+	var id markl.Id
+
+	if err = id.Set(template); err == nil {
+		blobId = id
+		return blobId, err
+	}
+
+	var objectId ids.ObjectId
+
+	if err = objectId.Set(template); err != nil {
+		err = errors.BadRequestf(
+			"template %q is neither a blob id nor an object id",
+			template,
+		)
+		return blobId, err
+	}
+
+	var object *sku.Transacted
+
+	if object, err = store.ReadOneObjectId(&objectId); err != nil {
+		err = errors.Wrapf(err, "template: %q", template)
+		return blobId, err
+	} else if object == nil {
+		err = errors.BadRequestf("template object %q not found", template)
+		return blobId, err
+	}
+
+	blobId = object.GetBlobDigest()
+
+	return blobId, err
+}
@@ -0,0 +1,261 @@
+package store
+
+import (
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/file_lock"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/blob_refcount_index"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// TagTrash is the system tag of trashed objects. As a `dodder-` tag it gets no
+// tag object and cannot be typed in by users, and as a tag it is part of the
+// object's signed history, so trashing travels with pushes and pulls.
+const TagTrash = "dodder-trash"
+
+// IsTrashed reports whether the version of an object carries TagTrash. Trashed
+// objects are dormant.
+func IsTrashed(object sku.TransactedGetter) bool {
+	return object.GetSku().GetMetadata().GetTags().ContainsKey(TagTrash)
+}
+
+// trashMatcher is the matcher of the builtin `%trash` virtual tag.
+type trashMatcher struct{}
+
+func (trashMatcher) ContainsSku(object sku.TransactedGetter) bool {
+	return IsTrashed(object)
+}
+
+// GetTrashExpiry returns when a trashed object expires, which is retention
+// after its latest version, the one that trashed it unless it was edited in
+// the trash.
+func GetTrashExpiry(object *sku.Transacted, retention time.Duration) ids.Tai {
+	return ids.TaiFromTime1(object.GetTai().AsTime().GetTime().Add(retention))
+}
+
+// TrashObject commits a version of an object with TagTrash, reporting false if
+// it already was trashed.
+func (store *Store) TrashObject(object *sku.Transacted) (ok bool, err error) {
+	if !store.GetEnvRepo().GetLockSmith().IsAcquired() {
+		err = file_lock.ErrLockRequired{
+			Operation: "trash object",
+		}
+
+		return ok, err
+	}
+
+	if IsTrashed(object) {
+		return ok, err
+	}
+
+	if err = object.GetMetadataMutable().AddTagString(TagTrash); err != nil {
+		err = errors.Wrap(err)
+		return ok, err
+	}
+
+	if err = store.CreateOrUpdate(object, sku.CommitOptions{}); err != nil {
+		err = errors.Wrap(err)
+		return ok, err
+	}
+
+	ok = true
+
+	return ok, err
+}
+
+// RestoreObject commits a version of an object without TagTrash, reporting
+// false if it was not trashed.
+func (store *Store) RestoreObject(object *sku.Transacted) (ok bool, err error) {
+	if !store.GetEnvRepo().GetLockSmith().IsAcquired() {
+		err = file_lock.ErrLockRequired{
+			Operation: "restore object",
+		}
+
+		return ok, err
+	}
+
+	if !IsTrashed(object) {
+		return ok, err
+	}
+
+	metadata := object.GetMetadataMutable()
+
+	var tags []ids.Tag
+
+	for tag := range metadata.AllTags() {
+		if tag.String() != TagTrash {
+			tags = append(tags, tag)
+		}
+	}
+
+	metadata.ResetTags()
+
+	for _, tag := range tags {
+		if err = metadata.AddTagPtr(tag); err != nil {
+			err = errors.Wrap(err)
+			return ok, err
+		}
+	}
+
+	if err = store.CreateOrUpdate(object, sku.CommitOptions{}); err != nil {
+		err = errors.Wrap(err)
+		return ok, err
+	}
+
+	ok = true
+
+	return ok, err
+}
+
+// AllTrashed returns the latest versions of the trashed objects.
+func (store *Store) AllTrashed() (trashed []*sku.Transacted, err error) {
+	if err = store.QueryPrimitive(
+		sku.MakePrimitiveQueryGroupWithSigils(ids.SigilLatest, ids.SigilHidden),
+		func(object *sku.Transacted) (err error) {
+			if !IsTrashed(object) {
+				return err
+			}
+
+			cloned, _ := object.CloneTransacted()
+			trashed = append(trashed, cloned)
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return trashed, err
+	}
+
+	return trashed, err
+}
+
+// TrashPurge is what EmptyTrash removed, or would remove on dry runs.
+type TrashPurge struct {
+	// the latest versions of the trashed objects past the retention
+	Objects []*sku.Transacted
+
+	// the blobs referenced only by versions of those objects
+	Blobs []domain_interfaces.MarklId
+}
+
+// EmptyTrash permanently removes the contents of the objects that have been
+// trashed for longer than retention, by deleting from the default blob store
+// every blob that no other object version references. The versions
+// themselves stay in the signed inventory lists and stay trashed. Deciding
+// what else references a blob needs the blob refcount index. On dry runs,
+// nothing is deleted.
+func (store *Store) EmptyTrash(
+	retention time.Duration,
+) (purge TrashPurge, err error) {
+	if !store.GetEnvRepo().GetLockSmith().IsAcquired() {
+		err = file_lock.ErrLockRequired{
+			Operation: "empty trash",
+		}
+
+		return purge, err
+	}
+
+	if !store.blobRefcounts.IsBuilt() {
+		err = blob_refcount_index.ErrNotBuilt{
+			Path: store.GetEnvRepo().FileCacheBlobRefcounts(),
+		}
+
+		return purge, err
+	}
+
+	var trashed []*sku.Transacted
+
+	if trashed, err = store.AllTrashed(); err != nil {
+		err = errors.Wrap(err)
+		return purge, err
+	}
+
+	now := ids.NowTai()
+	expired := make(map[string]struct{})
+
+	for _, object := range trashed {
+		if now.Before(GetTrashExpiry(object, retention)) {
+			continue
+		}
+
+		purge.Objects = append(purge.Objects, object)
+		expired[object.GetObjectId().String()] = struct{}{}
+	}
+
+	if len(purge.Objects) == 0 {
+		return purge, err
+	}
+
+	// the refcount index counts every version in the inventory lists, so the
+	// versions of the expired objects are counted from the same source
+	references := make(map[string]uint64)
+	blobs := make(map[string]domain_interfaces.MarklId)
+
+	seq := store.GetInventoryListStore().AllInventoryListObjectsAndContents()
+
+	for objectWithList, iterErr := range seq {
+		if iterErr != nil {
+			err = errors.Wrap(iterErr)
+			return purge, err
+		}
+
+		object := objectWithList.Object
+
+		if _, ok := expired[object.GetObjectId().String()]; !ok {
+			continue
+		}
+
+		blobDigest := object.GetBlobDigest()
+
+		if markl.IsNull(blobDigest) {
+			continue
+		}
+
+		key := blobDigest.StringWithFormat()
+		references[key]++
+
+		if _, ok := blobs[key]; !ok {
+			blobs[key], _ = markl.Clone(blobDigest)
+		}
+	}
+
+	blobStore := store.GetEnvRepo().GetDefaultBlobStore()
+
+	for key, blobDigest := range blobs {
+		if store.blobRefcounts.GetCount(blobDigest) != references[key] ||
+			!blobStore.HasBlob(blobDigest) {
+			continue
+		}
+
+		purge.Blobs = append(purge.Blobs, blobDigest)
+	}
+
+	if store.GetConfigStore().GetConfig().IsDryRun() || len(purge.Blobs) == 0 {
+		return purge, err
+	}
+
+	deleter, ok := blobStore.GetBlobStore().(blob_stores.BlobDeleter)
+
+	if !ok {
+		err = errors.BadRequestf(
+			"blob store %q does not support deleting blobs",
+			blobStore.GetId(),
+		)
+
+		return purge, err
+	}
+
+	for _, blobDigest := range purge.Blobs {
+		if err = deleter.DeleteBlob(blobDigest); err != nil {
+			err = errors.Wrapf(err, "deleting blob %s", blobDigest)
+			return purge, err
+		}
+	}
+
+	return purge, err
+}
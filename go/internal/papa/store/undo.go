@@ -0,0 +1,110 @@
+package store
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/file_lock"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/juliett/inventory_list_store"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// ReadUndoableInventoryList returns the last inventory list in the log and
+// the latest version of each object in it. It fails if any of those objects
+// has a later version than the list's, as undoing the list would leave that
+// version's mother dangling.
+func (store *Store) ReadUndoableInventoryList() (
+	list *sku.Transacted,
+	objects []*sku.Transacted,
+	err error,
+) {
+	if list, err = store.GetInventoryListStore().ReadLastInLog(); err != nil {
+		err = errors.Wrap(err)
+		return list, objects, err
+	}
+
+	// a list may contain several versions of an object, the last of which is
+	// its head
+	heads := make(map[string]int)
+
+	for object, iterErr := range store.GetInventoryListStore().AllInventoryListContents(
+		list.GetBlobDigest(),
+	) {
+		if iterErr != nil {
+			err = errors.Wrap(iterErr)
+			return list, objects, err
+		}
+
+		cloned, _ := object.CloneTransacted()
+		objectId := object.GetObjectId().String()
+
+		if i, ok := heads[objectId]; ok {
+			objects[i] = cloned
+		} else {
+			heads[objectId] = len(objects)
+			objects = append(objects, cloned)
+		}
+	}
+
+	for _, object := range objects {
+		var latest *sku.Transacted
+
+		if latest, err = store.ReadOneObjectId(object.GetObjectId()); err != nil {
+			err = errors.Wrapf(err, "object: %s", object.GetObjectId())
+			return list, objects, err
+		}
+
+		if !markl.Equals(
+			latest.GetMetadata().GetObjectSig(),
+			object.GetMetadata().GetObjectSig(),
+		) {
+			err = errors.BadRequestf(
+				"cannot undo inventory list %s: %s has a later version (%s)",
+				list.GetTai(),
+				object.GetObjectId(),
+				latest.GetTai(),
+			)
+
+			return list, objects, err
+		}
+	}
+
+	return list, objects, err
+}
+
+// UndoInventoryList removes the list returned by ReadUndoableInventoryList
+// from the inventory list log, recording it as an orphan. If hard, the
+// indexes are rebuilt from the remaining lists, dropping the versions it
+// added and the blob references they held. Otherwise the indexes keep those
+// versions until the next reindex.
+func (store *Store) UndoInventoryList(
+	context interfaces.ActiveContext,
+	list *sku.Transacted,
+	hard bool,
+) (orphan inventory_list_store.Orphan, err error) {
+	if !store.GetEnvRepo().GetLockSmith().IsAcquired() {
+		err = file_lock.ErrLockRequired{
+			Operation: "undo inventory list",
+		}
+
+		return orphan, err
+	}
+
+	if orphan, err = store.GetInventoryListStore().RemoveLast(
+		list.GetMetadata().GetObjectSig(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return orphan, err
+	}
+
+	if !hard {
+		return orphan, err
+	}
+
+	if err = store.Reindex(context); err != nil {
+		err = errors.Wrap(err)
+		return orphan, err
+	}
+
+	return orphan, err
+}
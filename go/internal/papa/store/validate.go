@@ -20,8 +20,7 @@ func (store *Store) validateAndFinalize(
 	if err = store.finalizer.WriteLockfile(
 		daughter,
 		options.LockfileOptions,
-		store.streamIndex.ReadOneMarklIdAdded,
-		store.streamIndex.ReadOneMarklId,
+		store.streamIndex.ReadManyObjectIds,
 	); err != nil {
 		err = errors.Wrap(err)
 		return err
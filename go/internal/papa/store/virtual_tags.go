@@ -0,0 +1,56 @@
+package store
+
+import (
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/india/tag_blobs"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// VirtualTagTrash is the builtin virtual tag of the objects with TagTrash.
+const VirtualTagTrash = "trash"
+
+// virtualTagProviders are loaded once per process, the first time a query
+// uses a virtual tag. Their tables are exposed to their scripts as `Self`.
+type virtualTagProviders struct {
+	once      sync.Once
+	providers []luaScript
+	err       error
+}
+
+var _ queries.VirtualTags = &Store{}
+
+// GetVirtualTag returns the matcher of the first provider in
+// `virtual-tags.providers` with a function for the virtual tag. `%trash` is
+// builtin and matches the objects in the trash.
+func (store *Store) GetVirtualTag(
+	name string,
+) (matcher sku.Queryable, err error) {
+	if name == VirtualTagTrash {
+		matcher = trashMatcher{}
+		return matcher, err
+	}
+
+	store.virtualTags.once.Do(func() {
+		store.virtualTags.providers, store.virtualTags.err = store.loadLuaScripts(
+			"virtual-tags.providers",
+			store.GetConfigStore().GetConfig().GetVirtualTags().Providers,
+		)
+	})
+
+	if err = store.virtualTags.err; err != nil {
+		err = errors.Wrap(err)
+		return matcher, err
+	}
+
+	for _, provider := range store.virtualTags.providers {
+		if tag_blobs.ProvidesLuaVirtualTag(provider.vmPool, name) {
+			matcher = tag_blobs.MakeLuaVirtualTag(provider.vmPool, name)
+			return matcher, err
+		}
+	}
+
+	return matcher, err
+}
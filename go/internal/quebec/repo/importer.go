@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
 	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
@@ -40,6 +41,19 @@ type (
 		AllowMergeConflicts bool
 		OverwriteSignatures bool
 
+		// only used by pulls, which check that the blobs of pulled objects and
+		// of the konfig, type, and tag objects configuring them are all present
+		VerifyClosure bool
+
+		// repo pubkeys trusted for this transfer in addition to the repo's own
+		// pubkey and its trust store
+		TrustedRepoPubKeys []domain_interfaces.MarklId
+		// add the pubkey of the remote being pulled from to the trust store
+		// instead of refusing to pull from an untrusted remote
+		TrustRemoteRepoPubKey bool
+		// accept objects without signatures, which this repo then signs as new
+		AllowUnsignedObjects bool
+
 		DedupingFormatId   string
 		RemoteBlobStore    blob_stores.BlobStoreInitialized
 		BlobCopierDelegate interfaces.FuncIter[sku.BlobCopyResult]
@@ -82,6 +96,13 @@ func (options *ImporterOptions) SetFlagDefinitions(
 		"ignore merge conflicts and allow incompatible histories to coexist",
 	)
 
+	flagDefinitions.BoolVar(
+		&options.TrustRemoteRepoPubKey,
+		"trust",
+		false,
+		"add the pubkey of the repo pulled from to the trust store if it is not trusted yet",
+	)
+
 	flagDefinitions.Var(
 		&options.BlobGenres,
 		"blob-genres",
@@ -12,6 +12,10 @@ import (
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 )
 
+// importErrorLimit caps how many failed objects are retained for the error
+// summary; the rest are only counted.
+const importErrorLimit = 100
+
 // TODO create an open list and resolve the graph as necessary
 func (importer importer) ImportSeq(
 	ctx interfaces.ActiveContext,
@@ -35,7 +39,7 @@ func (importer importer) ImportSeq(
 		},
 	)
 
-	importErrors := errors.MakeGroupBuilder()
+	importErrors := errors.MakeMultiErrorBuilder(importErrorLimit)
 	missingBlobs := sku.MakeListCheckedOut()
 
 	for object, iterErr := range seq {
@@ -51,8 +55,7 @@ func (importer importer) ImportSeq(
 			object,
 			missingBlobs,
 		); err != nil {
-			err = errors.Wrapf(err, "Object: %s", sku.String(object))
-			importErrors.Add(err)
+			importErrors.Addf(errors.Wrap(err), "Object: %s", sku.String(object))
 			err = nil
 		}
 
@@ -77,19 +80,31 @@ func (importer importer) ImportSeq(
 		}
 	}
 
-	if hasConflicts {
-		importErrors.Add(ErrNeedsMerge)
-	}
-
-	if importErrors.Len() > 0 {
-		err = importErrors.GetError()
-	}
+	err = makeImportError(importErrors, hasConflicts)
 
 	ctx.Must(errors.MakeFuncContextFromFuncErr(local.Unlock))
 
 	return err
 }
 
+// makeImportError returns the collected per-object failures, joined with
+// ErrNeedsMerge if the import left conflicts. ErrNeedsMerge is kept out of the
+// builder because its limit would drop it once enough objects failed, and
+// callers rely on errors.Is to tell a pull that needs merging from one that
+// failed.
+func makeImportError(
+	importErrors *errors.MultiErrorBuilder,
+	hasConflicts bool,
+) (err error) {
+	err = importErrors.GetError()
+
+	if hasConflicts {
+		err = errors.Join(err, ErrNeedsMerge)
+	}
+
+	return err
+}
+
 func (importer importer) importOne(
 	repo repo.LocalRepo,
 	object *sku.Transacted,
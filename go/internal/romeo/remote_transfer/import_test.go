@@ -0,0 +1,56 @@
+//go:build test
+
+package remote_transfer
+
+import (
+	"fmt"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func TestMakeImportErrorKeepsNeedsMergePastLimit(t *testing.T) {
+	importErrors := errors.MakeMultiErrorBuilder(importErrorLimit)
+
+	for i := range importErrorLimit + 10 {
+		importErrors.Addf(
+			fmt.Errorf("failed"),
+			"Object: %d",
+			i,
+		)
+	}
+
+	err := makeImportError(importErrors, true)
+
+	if !errors.Is(err, ErrNeedsMerge) {
+		t.Fatalf("expected ErrNeedsMerge after %d failures, got: %s", importErrorLimit+10, err)
+	}
+
+	var multiError errors.MultiError
+
+	if !errors.As(err, &multiError) {
+		t.Fatalf("expected the object failures to be kept, got: %s", err)
+	}
+
+	if multiError.Len() != importErrorLimit+10 {
+		t.Errorf("expected %d failures, got %d", importErrorLimit+10, multiError.Len())
+	}
+}
+
+func TestMakeImportError(t *testing.T) {
+	importErrors := errors.MakeMultiErrorBuilder(importErrorLimit)
+
+	if err := makeImportError(importErrors, false); err != nil {
+		t.Errorf("expected no error, got: %s", err)
+	}
+
+	if err := makeImportError(importErrors, true); err != ErrNeedsMerge {
+		t.Errorf("expected ErrNeedsMerge, got: %s", err)
+	}
+
+	importErrors.Add("", fmt.Errorf("failed"))
+
+	if err := makeImportError(importErrors, false); errors.Is(err, ErrNeedsMerge) {
+		t.Errorf("expected no ErrNeedsMerge without conflicts, got: %s", err)
+	}
+}
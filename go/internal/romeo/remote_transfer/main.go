@@ -47,6 +47,21 @@ func Make(
 		parentNegotiator:            options.ParentNegotiator,
 		checkedOutPrinter:           options.CheckedOutPrinter,
 		storeOptions:                storeOptions,
+		allowUnsignedObjects:        options.AllowUnsignedObjects,
+	}
+
+	importer.trustedRepoPubKeys = object_finalizer.MakeTrustedRepoPubKeys(
+		options.TrustedRepoPubKeys...,
+	)
+
+	trusted, err := envRepo.GetTrustedRepoPubKeys()
+	if err != nil {
+		envRepo.Cancel(err)
+		return importer
+	}
+
+	for _, pubKey := range trusted {
+		importer.trustedRepoPubKeys.Add(pubKey)
 	}
 
 	importer.committer.initialize(options, envRepo, storeObject)
@@ -90,6 +105,8 @@ type importer struct {
 	allowMergeConflicts         bool
 	parentNegotiator            sku.ParentNegotiator
 	checkedOutPrinter           interfaces.FuncIter[*sku.CheckedOut]
+	trustedRepoPubKeys          object_finalizer.TrustedRepoPubKeys
+	allowUnsignedObjects        bool
 }
 
 func (importer importer) GetCheckedOutPrinter() interfaces.FuncIter[*sku.CheckedOut] {
@@ -204,10 +221,17 @@ func (importer importer) importLeaf(
 	checkedOut.GetSkuExternal().GetMetadataMutable().GetObjectDigestMutable().Reset()
 	configGenesis := importer.envRepo.GetConfigPrivate().Blob
 
-	// TODO confirm repo pub key
-
-	// TODO set this as an importer option
 	if checkedOut.GetSkuExternal().GetMetadata().GetObjectSig().IsNull() {
+		if !importer.allowUnsignedObjects &&
+			external.GetType().String() != ids.TypeInventoryListV1 {
+			err = errors.BadRequestf(
+				"object is not signed: %s",
+				sku.String(external),
+			)
+
+			return checkedOut, err
+		}
+
 		if err = importer.finalizer.FinalizeAndSignOverwrite(
 			checkedOut.GetSkuExternal(),
 			configGenesis,
@@ -223,6 +247,16 @@ func (importer importer) importLeaf(
 			err = errors.Wrap(err)
 			return checkedOut, err
 		}
+
+		// the object digest was just recalculated from the object's contents,
+		// so a valid signature also covers its blob digest
+		if err = importer.finalizer.VerifyTrusted(
+			checkedOut.GetSkuExternal(),
+			importer.trustedRepoPubKeys,
+		); err != nil {
+			err = errors.Wrap(err)
+			return checkedOut, err
+		}
 	}
 
 	if importer.index != nil {
@@ -0,0 +1,144 @@
+package repo_federation
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/quebec/repo"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// Member is one repo of a federation, qualified by its repo id. At most one
+// member, usually the local repo, may have an empty repo id, and its objects
+// are output unqualified.
+type Member struct {
+	RepoId ids.RepoId
+	Repo   repo.Repo
+}
+
+// Federation runs queries against several repos as if they were one, merging
+// their results and routing blob reads to the repo each object came from.
+type Federation struct {
+	members []Member
+}
+
+func Make(members ...Member) (federation *Federation, err error) {
+	seen := make(map[string]struct{}, len(members))
+
+	for _, member := range members {
+		key := member.RepoId.String()
+
+		if _, ok := seen[key]; ok {
+			err = errors.BadRequestf(
+				"repo %q is federated more than once",
+				member.RepoId.StringWithSlashPrefix(),
+			)
+
+			return federation, err
+		}
+
+		seen[key] = struct{}{}
+	}
+
+	federation = &Federation{members: members}
+
+	return federation, err
+}
+
+func (federation *Federation) GetMembers() []Member {
+	return federation.members
+}
+
+// Query runs the query against every member in parallel and outputs the
+// merged results in tai order, each with the repo id of the member it came
+// from. A member's error fails the whole query, wrapped with its repo id.
+func (federation *Federation) Query(
+	query *queries.Query,
+	output interfaces.FuncIter[*sku.Transacted],
+) (err error) {
+	merged := sku.MakeListTransacted()
+	waitGroup := errors.MakeWaitGroupParallel()
+
+	for _, member := range federation.members {
+		waitGroup.Do(func() (err error) {
+			var list *sku.HeapTransacted
+
+			if list, err = member.Repo.MakeInventoryList(query); err != nil {
+				err = errors.Wrapf(
+					err,
+					"querying repo %q",
+					member.RepoId.StringWithSlashPrefix(),
+				)
+
+				return err
+			}
+
+			for object := range list.All() {
+				object.SetRepoId(member.RepoId)
+				merged.Push(object)
+			}
+
+			return err
+		})
+	}
+
+	if err = waitGroup.GetError(); err != nil {
+		return err
+	}
+
+	for object := range merged.All() {
+		if err = output(object); err != nil {
+			if errors.IsStopIteration(err) {
+				err = nil
+				break
+			}
+
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
+
+// GetBlobStoreForRepoId returns the blob store of the member with the given
+// repo id.
+func (federation *Federation) GetBlobStoreForRepoId(
+	repoId ids.RepoId,
+) (blobStore domain_interfaces.BlobStore, ok bool) {
+	for _, member := range federation.members {
+		if member.RepoId.Equals(repoId) {
+			return member.Repo.GetBlobStore(), true
+		}
+	}
+
+	return blobStore, false
+}
+
+// MakeBlobReader reads the object's blob from the blob store of the member
+// it came from.
+func (federation *Federation) MakeBlobReader(
+	object *sku.Transacted,
+) (reader domain_interfaces.BlobReader, err error) {
+	blobStore, ok := federation.GetBlobStoreForRepoId(object.GetRepoId())
+
+	if !ok {
+		err = errors.BadRequestf(
+			"repo %q is not federated",
+			object.GetRepoId().StringWithSlashPrefix(),
+		)
+
+		return reader, err
+	}
+
+	if reader, err = blobStore.MakeBlobReader(
+		object.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return reader, err
+	}
+
+	return reader, err
+}
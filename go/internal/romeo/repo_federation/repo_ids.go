@@ -0,0 +1,43 @@
+package repo_federation
+
+import (
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// RepoIds is a flag value for the repo ids to federate, given comma-separated
+// or by repeating the flag.
+type RepoIds []ids.RepoId
+
+func (repoIds RepoIds) String() string {
+	values := make([]string, len(repoIds))
+
+	for i, repoId := range repoIds {
+		values[i] = repoId.String()
+	}
+
+	return strings.Join(values, ",")
+}
+
+func (repoIds *RepoIds) Set(value string) (err error) {
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		var repoId ids.RepoId
+
+		if err = repoId.Set(part); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		*repoIds = append(*repoIds, repoId)
+	}
+
+	return err
+}
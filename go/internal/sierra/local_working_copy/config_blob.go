@@ -0,0 +1,80 @@
+package local_working_copy
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/delta/repo_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// ReadConfigBlob decodes the blob of the konfig object.
+func (local *Repo) ReadConfigBlob() (typedBlob repo_configs.TypedBlob, err error) {
+	var object *sku.Transacted
+
+	if object, err = local.GetStore().ReadTransactedFromObjectId(
+		ids.Config,
+	); err != nil {
+		err = errors.Wrap(err)
+		return typedBlob, err
+	}
+
+	typedBlob.Type = object.GetType().ToType()
+
+	var blobReader domain_interfaces.BlobReader
+
+	if blobReader, err = local.GetEnvRepo().GetDefaultBlobStore().MakeBlobReader(
+		object.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return typedBlob, err
+	}
+
+	defer errors.DeferredCloser(&err, blobReader)
+
+	if _, err = local.GetStore().GetConfigBlobCoder().DecodeFrom(
+		&typedBlob,
+		blobReader,
+	); err != nil {
+		err = errors.Wrap(err)
+		return typedBlob, err
+	}
+
+	return typedBlob, err
+}
+
+// WriteConfigBlob writes typedBlob and commits it as the blob of the konfig
+// object. It requires the lock.
+func (local *Repo) WriteConfigBlob(
+	typedBlob repo_configs.TypedBlob,
+) (err error) {
+	var blobWriter domain_interfaces.BlobWriter
+
+	if blobWriter, err = local.GetEnvRepo().GetDefaultBlobStore().MakeBlobWriter(
+		nil,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if _, err = local.GetStore().GetConfigBlobCoder().EncodeTo(
+		&typedBlob,
+		blobWriter,
+	); err != nil {
+		blobWriter.Close()
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = blobWriter.Close(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if _, err = local.GetStore().UpdateKonfig(blobWriter.GetMarklId()); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,35 @@
+package local_working_copy
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+)
+
+// FederatedBlobStores looks up the blob store of the repo that an object of a
+// federated query came from.
+type FederatedBlobStores interface {
+	GetBlobStoreForRepoId(ids.RepoId) (domain_interfaces.BlobStore, bool)
+}
+
+// SetFederatedBlobStores makes the formats read the blobs of objects with a
+// repo id from that repo's blob store rather than the local one.
+func (local *Repo) SetFederatedBlobStores(blobStores FederatedBlobStores) {
+	local.federatedBlobStores = blobStores
+}
+
+// getBlobStoreForObject returns the blob store holding the object's blob: the
+// one of the federated repo it came from, or the default blob store.
+func (local *Repo) getBlobStoreForObject(
+	object *sku.Transacted,
+) domain_interfaces.BlobStore {
+	if local.federatedBlobStores != nil && !object.GetRepoId().IsEmpty() {
+		if blobStore, ok := local.federatedBlobStores.GetBlobStoreForRepoId(
+			object.GetRepoId(),
+		); ok {
+			return blobStore
+		}
+	}
+
+	return local.GetEnvRepo().GetDefaultBlobStore()
+}
@@ -35,10 +35,18 @@ type (
 		interfaces.WriterAndStringWriter,
 	) interfaces.FuncIter[*sku.Transacted]
 
+	// FlushingFormatFuncConstructor is for formats that have to write
+	// something after the last object, like the end of a JSON array.
+	FlushingFormatFuncConstructor func(
+		*Repo,
+		interfaces.WriterAndStringWriter,
+	) (interfaces.FuncIter[*sku.Transacted], errors.FuncErr)
+
 	FormatFuncConstructorEntry struct {
 		Name        string
 		description string
 		FormatFuncConstructor
+		FlushingFormatFuncConstructor
 	}
 
 	FormatFlag struct {
@@ -100,6 +108,22 @@ func (formatFlag *FormatFlag) Set(value string) (err error) {
 	var ok bool
 	var entry FormatFuncConstructorEntry
 
+	if templateText, isTemplate := strings.CutPrefix(
+		value,
+		formatTemplatePrefix,
+	); isTemplate {
+		if entry, err = makeTemplateFormatEntry(templateText); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		formatFlag.wasSet = true
+		entry.Name = value
+		formatFlag.formatter = entry
+
+		return err
+	}
+
 	if entry, ok = formatters[value]; !ok {
 		err = flags.ErrInvalidValue{
 			Actual:   value,
@@ -116,22 +140,31 @@ func (formatFlag *FormatFlag) Set(value string) (err error) {
 	return err
 }
 
+// MakeFormatFunc returns the format's FuncIter along with the flush that has
+// to be called once every object was passed to it.
 func (formatFlag *FormatFlag) MakeFormatFunc(
 	repo *Repo,
 	writer interfaces.WriterAndStringWriter,
-) interfaces.FuncIter[*sku.Transacted] {
+) (interfaces.FuncIter[*sku.Transacted], errors.FuncErr) {
+	entry := formatFlag.formatter
+
 	if formatFlag.formatter.Name == "" &&
 		formatFlag.DefaultFormatter.Name == "" {
 		errors.ContextCancelWithErrorf(
 			repo,
 			"neither format flag nor default were set",
 		)
-		return nil
+		return nil, nil
 	} else if formatFlag.formatter.Name == "" {
-		return formatFlag.DefaultFormatter.FormatFuncConstructor(repo, writer)
+		entry = formatFlag.DefaultFormatter
+	}
+
+	if entry.FlushingFormatFuncConstructor != nil {
+		return entry.FlushingFormatFuncConstructor(repo, writer)
 	}
 
-	return formatFlag.formatter.FormatFuncConstructor(repo, writer)
+	return entry.FormatFuncConstructor(repo, writer),
+		func() error { return nil }
 }
 
 func GetFormatFuncConstructorEntry(name string) FormatFuncConstructorEntry {
@@ -605,7 +638,11 @@ var formatters = map[string]FormatFuncConstructorEntry{
 			return repo.PrinterTransacted()
 		},
 	},
-	"json": {
+	"json":             makeJsonArrayFormatEntry(false),
+	"json-with-blob":   makeJsonArrayFormatEntry(true),
+	"ndjson":           makeNdjsonFormatEntry(false),
+	"ndjson-with-blob": makeNdjsonFormatEntry(true),
+	"json-transacted": {
 		FormatFuncConstructor: func(
 			repo *Repo,
 			writer interfaces.WriterAndStringWriter,
@@ -617,7 +654,7 @@ var formatters = map[string]FormatFuncConstructorEntry{
 
 				if err = jsonRep.FromTransacted(
 					object,
-					repo.GetStore().GetEnvRepo().GetDefaultBlobStore(),
+					repo.getBlobStoreForObject(object),
 				); err != nil {
 					err = errors.Wrap(err)
 					return err
@@ -649,7 +686,7 @@ var formatters = map[string]FormatFuncConstructorEntry{
 
 				if err = jsonRep.FromTransacted(
 					object,
-					repo.GetStore().GetEnvRepo().GetDefaultBlobStore(),
+					repo.getBlobStoreForObject(object),
 				); err != nil {
 					err = errors.Wrap(err)
 					return err
@@ -697,7 +734,7 @@ var formatters = map[string]FormatFuncConstructorEntry{
 			return func(object *sku.Transacted) (err error) {
 				var readCloser domain_interfaces.BlobReader
 
-				if readCloser, err = repo.GetStore().GetEnvRepo().GetDefaultBlobStore().MakeBlobReader(
+				if readCloser, err = repo.getBlobStoreForObject(object).MakeBlobReader(
 					object.GetBlobDigest(),
 				); err != nil {
 					err = errors.Wrap(err)
@@ -733,7 +770,7 @@ var formatters = map[string]FormatFuncConstructorEntry{
 				if repo.GetConfig().IsInlineType(object.GetType()) {
 					var readCloser domain_interfaces.BlobReader
 
-					if readCloser, err = repo.GetStore().GetEnvRepo().GetDefaultBlobStore().MakeBlobReader(
+					if readCloser, err = repo.getBlobStoreForObject(object).MakeBlobReader(
 						object.GetBlobDigest(),
 					); err != nil {
 						err = errors.Wrap(err)
@@ -773,7 +810,7 @@ var formatters = map[string]FormatFuncConstructorEntry{
 			return func(object *sku.Transacted) (err error) {
 				var readCloser domain_interfaces.BlobReader
 
-				if readCloser, err = repo.GetStore().GetEnvRepo().GetDefaultBlobStore().MakeBlobReader(
+				if readCloser, err = repo.getBlobStoreForObject(object).MakeBlobReader(
 					object.GetBlobDigest(),
 				); err != nil {
 					err = errors.Wrap(err)
@@ -1092,7 +1129,7 @@ var formatters = map[string]FormatFuncConstructorEntry{
 
 				var readCloser domain_interfaces.BlobReader
 
-				if readCloser, err = repo.GetStore().GetEnvRepo().GetDefaultBlobStore().MakeBlobReader(
+				if readCloser, err = repo.getBlobStoreForObject(object).MakeBlobReader(
 					object.GetBlobDigest(),
 				); err != nil {
 					err = errors.Wrap(err)
@@ -1132,7 +1169,7 @@ var formatters = map[string]FormatFuncConstructorEntry{
 
 				var readCloser domain_interfaces.BlobReader
 
-				if readCloser, err = repo.GetStore().GetEnvRepo().GetDefaultBlobStore().MakeBlobReader(
+				if readCloser, err = repo.getBlobStoreForObject(object).MakeBlobReader(
 					object.GetBlobDigest(),
 				); err != nil {
 					err = errors.Wrap(err)
@@ -0,0 +1,144 @@
+package local_working_copy
+
+import (
+	"encoding/json"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/sku_json_fmt"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// makeJsonObjectFunc returns a func that converts objects to the stable
+// sku_json_fmt.Object schema, inlining blobs if inlineBlob is set.
+func makeJsonObjectFunc(
+	repo *Repo,
+	inlineBlob bool,
+) func(*sku.Transacted) (sku_json_fmt.Object, error) {
+	return func(object *sku.Transacted) (jsonRep sku_json_fmt.Object, err error) {
+		var blobStore domain_interfaces.BlobStore
+
+		if inlineBlob {
+			blobStore = repo.getBlobStoreForObject(object)
+		}
+
+		if err = jsonRep.FromTransacted(object, blobStore); err != nil {
+			err = errors.Wrap(err)
+			return jsonRep, err
+		}
+
+		return jsonRep, err
+	}
+}
+
+// makeNdjsonFormatEntry writes one JSON object per line.
+func makeNdjsonFormatEntry(inlineBlob bool) FormatFuncConstructorEntry {
+	description := "one JSON object per line"
+
+	if inlineBlob {
+		description += ", with blobs inlined"
+	}
+
+	return FormatFuncConstructorEntry{
+		description: description,
+		FormatFuncConstructor: func(
+			repo *Repo,
+			writer interfaces.WriterAndStringWriter,
+		) interfaces.FuncIter[*sku.Transacted] {
+			enc := json.NewEncoder(writer)
+			toJson := makeJsonObjectFunc(repo, inlineBlob)
+
+			return func(object *sku.Transacted) (err error) {
+				var jsonRep sku_json_fmt.Object
+
+				if jsonRep, err = toJson(object); err != nil {
+					err = errors.Wrap(err)
+					return err
+				}
+
+				if err = enc.Encode(jsonRep); err != nil {
+					err = errors.Wrap(err)
+					return err
+				}
+
+				return err
+			}
+		},
+	}
+}
+
+// makeJsonArrayFormatEntry writes a single JSON array with one object per
+// line, closed by the flush so that no objects still yields `[]`.
+func makeJsonArrayFormatEntry(inlineBlob bool) FormatFuncConstructorEntry {
+	description := "a JSON array of objects"
+
+	if inlineBlob {
+		description += ", with blobs inlined"
+	}
+
+	return FormatFuncConstructorEntry{
+		description: description,
+		FlushingFormatFuncConstructor: func(
+			repo *Repo,
+			writer interfaces.WriterAndStringWriter,
+		) (interfaces.FuncIter[*sku.Transacted], errors.FuncErr) {
+			toJson := makeJsonObjectFunc(repo, inlineBlob)
+			count := 0
+
+			output := func(object *sku.Transacted) (err error) {
+				var jsonRep sku_json_fmt.Object
+
+				if jsonRep, err = toJson(object); err != nil {
+					err = errors.Wrap(err)
+					return err
+				}
+
+				var jsonBytes []byte
+
+				if jsonBytes, err = json.Marshal(jsonRep); err != nil {
+					err = errors.Wrap(err)
+					return err
+				}
+
+				separator := ",\n"
+
+				if count == 0 {
+					separator = "[\n"
+				}
+
+				count++
+
+				if _, err = io.WriteString(writer, separator); err != nil {
+					err = errors.Wrap(err)
+					return err
+				}
+
+				if _, err = writer.Write(jsonBytes); err != nil {
+					err = errors.Wrap(err)
+					return err
+				}
+
+				return err
+			}
+
+			flush := func() (err error) {
+				closing := "\n]\n"
+
+				if count == 0 {
+					closing = "[]\n"
+				}
+
+				if _, err = io.WriteString(writer, closing); err != nil {
+					err = errors.Wrap(err)
+					return err
+				}
+
+				return err
+			}
+
+			return output, flush
+		},
+	}
+}
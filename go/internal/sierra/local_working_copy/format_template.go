@@ -0,0 +1,56 @@
+package local_working_copy
+
+import (
+	"io"
+	"text/template"
+
+	"code.linenisgreat.com/dodder/go/internal/echo/object_metadata_fmt"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// formatTemplatePrefix marks a format flag value as a text/template over
+// object_metadata_fmt.TemplateView, e.g. `template={{.ObjectId}}`.
+const formatTemplatePrefix = "template="
+
+// makeTemplateFormatEntry parses templateText up front, so that a broken
+// template fails while parsing flags rather than on the first object. Every
+// object is rendered on its own line.
+func makeTemplateFormatEntry(
+	templateText string,
+) (entry FormatFuncConstructorEntry, err error) {
+	var tmpl *template.Template
+
+	if tmpl, err = object_metadata_fmt.ParseTemplate(templateText); err != nil {
+		err = errors.Wrap(err)
+		return entry, err
+	}
+
+	entry.description = "a text/template rendered for every object"
+	entry.FormatFuncConstructor = func(
+		repo *Repo,
+		writer interfaces.WriterAndStringWriter,
+	) interfaces.FuncIter[*sku.Transacted] {
+		return func(object *sku.Transacted) (err error) {
+			view := object_metadata_fmt.MakeTemplateView(
+				object.GetObjectId().String(),
+				object.GetMetadataMutable(),
+			)
+
+			if err = tmpl.Execute(writer, view); err != nil {
+				err = errors.Wrapf(err, "Object: %s", sku.String(object))
+				return err
+			}
+
+			if _, err = io.WriteString(writer, "\n"); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			return err
+		}
+	}
+
+	return entry, err
+}
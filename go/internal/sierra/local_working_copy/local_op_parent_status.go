@@ -0,0 +1,109 @@
+package local_working_copy
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// ParentStatus is how a workspace's blob store has diverged from the parent
+// blob store it replaced.
+type ParentStatus struct {
+	// every blob in the workspace's blob store that is missing from the
+	// parent, all of which push-to-parent copies
+	UnpushedBlobs []domain_interfaces.MarklId
+
+	// objects whose blob was written in the workspace and is missing from the
+	// parent
+	ModifiedLocally []*sku.Transacted
+
+	// objects whose blob is in the parent but not in the workspace, as when
+	// they were updated outside the workspace after it was created. Lazy
+	// stores fetch these on demand.
+	UpdatedInParent []*sku.Transacted
+
+	// objects whose blob is in neither blob store
+	MissingBlobs []*sku.Transacted
+}
+
+func (status ParentStatus) IsEmpty() bool {
+	return len(status.UnpushedBlobs) == 0 &&
+		len(status.UpdatedInParent) == 0 &&
+		len(status.MissingBlobs) == 0
+}
+
+// ParentStatus compares the blobs of the heads matching query, and every blob
+// written in the workspace, against the workspace's blob store and its parent.
+// Workspaces share the repo's inventory lists and index, so blobs are the only
+// state that can diverge from the parent; a pointer store never diverges.
+func (local *Repo) ParentStatus(
+	query *queries.Query,
+) (status ParentStatus, err error) {
+	workspace, parent, ok := local.GetEnvRepo().GetWorkspaceBlobStores()
+
+	if !ok {
+		err = errors.BadRequestf(
+			"not in a workspace with its own blob store",
+		)
+
+		return status, err
+	}
+
+	if _, isPointer := workspace.Config.Blob.(blob_store_configs.ConfigPointer); isPointer {
+		return status, err
+	}
+
+	// AllBlobs only lists what the workspace holds itself, even for lazy
+	// stores whose HasBlob also asks the parent
+	inWorkspace := make(map[string]struct{})
+
+	for blobId, errIter := range workspace.AllBlobs() {
+		if errIter != nil {
+			err = errors.Wrap(errIter)
+			return status, err
+		}
+
+		inWorkspace[blobId.String()] = struct{}{}
+
+		if parent.HasBlob(blobId) {
+			continue
+		}
+
+		clonedId, _ := markl.Clone(blobId)
+		status.UnpushedBlobs = append(status.UnpushedBlobs, clonedId)
+	}
+
+	var heads *sku.HeapTransacted
+
+	if heads, err = local.MakeInventoryList(query); err != nil {
+		err = errors.Wrap(err)
+		return status, err
+	}
+
+	for object := range heads.All() {
+		blobId := object.GetBlobDigest()
+
+		if blobId.IsNull() {
+			continue
+		}
+
+		_, isInWorkspace := inWorkspace[blobId.String()]
+		isInParent := parent.HasBlob(blobId)
+
+		switch {
+		case isInWorkspace && !isInParent:
+			status.ModifiedLocally = append(status.ModifiedLocally, object)
+
+		case !isInWorkspace && isInParent:
+			status.UpdatedInParent = append(status.UpdatedInParent, object)
+
+		case !isInWorkspace && !isInParent:
+			status.MissingBlobs = append(status.MissingBlobs, object)
+		}
+	}
+
+	return status, err
+}
@@ -45,6 +45,16 @@ func (local *Repo) pullQueryGroupFromWorkingCopy(
 		importerOptions.RemoteBlobStore = remoteBlobStore
 	}
 
+	// everything pulled, including what the remote received from other repos,
+	// must be signed by a pubkey in this repo's trust store
+	if err = local.trustRemoteRepoPubKey(
+		remote,
+		importerOptions.TrustRemoteRepoPubKey,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
 	importerOptions.ParentNegotiator = ParentNegotiatorFirstAncestor{
 		Local:  local,
 		Remote: remote,
@@ -60,7 +70,9 @@ func (local *Repo) pullQueryGroupFromWorkingCopy(
 		importer,
 	); err != nil {
 		if errors.Is(err, remote_transfer.ErrNeedsMerge) {
-			err = errors.WithoutStack(err)
+			err = errors.WithoutStack(
+				errors.Errorf("%w (source: %s)", err, describeRemote(remote)),
+			)
 		} else {
 			err = errors.Wrap(err)
 		}
@@ -68,5 +80,89 @@ func (local *Repo) pullQueryGroupFromWorkingCopy(
 		return err
 	}
 
+	if importerOptions.ExcludeBlobs {
+		return err
+	}
+
+	var closure pullClosure
+
+	if closure, err = local.makePullClosure(remote, list); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = local.copyPullClosure(remote, closure, importerOptions); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if importerOptions.VerifyClosure {
+		if err = local.verifyPullClosure(closure); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	return err
 }
+
+// trustRemoteRepoPubKey refuses remotes whose repo pubkey is not trusted,
+// unless trust is set, in which case their pubkey is added to the trust store.
+func (local *Repo) trustRemoteRepoPubKey(
+	remote repo.Repo,
+	trust bool,
+) (err error) {
+	envRepo := local.GetEnvRepo()
+	pubKey := remote.GetImmutableConfigPublic().GetPublicKey()
+
+	var trusted bool
+
+	if trusted, err = envRepo.IsTrustedRepoPubKey(pubKey); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if trusted {
+		return err
+	}
+
+	if !trust {
+		err = errors.BadRequestf(
+			"%s is not trusted. Pass -trust to add its pubkey %s to the trust store, or add it with trust-repo-pubkey",
+			describeRemote(remote),
+			pubKey.StringWithFormat(),
+		)
+
+		return err
+	}
+
+	if err = local.Lock(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if _, err = envRepo.AddTrustedRepoPubKeys(pubKey); err != nil {
+		local.Unlock()
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = local.Unlock(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// describeRemote names a remote in messages by its repo id, or by its public
+// key if it has none.
+func describeRemote(remote repo.Repo) string {
+	config := remote.GetImmutableConfigPublic()
+
+	if repoId := config.GetRepoId(); !repoId.IsEmpty() {
+		return "repo=" + repoId.String()
+	}
+
+	return "repo-pub_key=" + config.GetPublicKey().String()
+}
@@ -0,0 +1,229 @@
+package local_working_copy
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/blob_transfers"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/quebec/repo"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/expansion"
+)
+
+// pullClosure is the set of blobs that pulled objects need in order to be
+// usable locally: their own blobs, plus those of the konfig and of the type
+// and tag objects that configure them, even when those objects were not part
+// of the pulled query.
+type pullClosure struct {
+	types map[string]struct{}
+	tags  map[string]struct{}
+
+	blobs      map[string]pullClosureBlob
+	blobsOrder []string
+}
+
+type pullClosureBlob struct {
+	digest   markl.Id
+	referrer string
+}
+
+func makePullClosure() pullClosure {
+	return pullClosure{
+		types: make(map[string]struct{}),
+		tags:  make(map[string]struct{}),
+		blobs: make(map[string]pullClosureBlob),
+	}
+}
+
+func (closure *pullClosure) addBlob(
+	digest domain_interfaces.MarklId,
+	referrer *sku.Transacted,
+) {
+	if digest == nil || digest.IsNull() {
+		return
+	}
+
+	key := digest.String()
+
+	if _, ok := closure.blobs[key]; ok {
+		return
+	}
+
+	blob := pullClosureBlob{referrer: sku.String(referrer)}
+	blob.digest.ResetWithMarklId(digest)
+
+	closure.blobs[key] = blob
+	closure.blobsOrder = append(closure.blobsOrder, key)
+}
+
+func (closure *pullClosure) addObject(object *sku.Transacted) {
+	closure.addBlob(object.GetBlobDigest(), object)
+
+	tipe := object.GetType()
+
+	if !tipe.IsEmpty() && !ids.IsBuiltin(tipe) {
+		for expanded := range expansion.ExpanderRight.Expand(tipe.String()) {
+			closure.types[expanded] = struct{}{}
+		}
+	}
+
+	for tag := range object.AllTags() {
+		for expanded := range expansion.ExpanderRight.Expand(tag.String()) {
+			closure.tags[expanded] = struct{}{}
+		}
+	}
+}
+
+// adds the blobs of the konfig and of every referenced type and tag object
+// that exists in remote
+func (closure *pullClosure) addConfigObjectsFrom(
+	local *Repo,
+	remote repo.Repo,
+) (err error) {
+	var queryGroup *queries.Query
+
+	if queryGroup, err = local.MakeExternalQueryGroup(
+		queries.BuilderOptions(),
+		sku.ExternalQueryOptions{},
+		":t,e,konfig",
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var list *sku.HeapTransacted
+
+	if list, err = remote.MakeInventoryList(queryGroup); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	for object := range list.All() {
+		objectId := object.GetObjectId().String()
+
+		switch object.GetGenre() {
+		case genres.Config:
+			closure.addBlob(object.GetBlobDigest(), object)
+
+		case genres.Type:
+			if _, ok := closure.types[objectId]; ok {
+				closure.addBlob(object.GetBlobDigest(), object)
+			}
+
+		case genres.Tag:
+			if _, ok := closure.tags[objectId]; ok {
+				closure.addBlob(object.GetBlobDigest(), object)
+			}
+		}
+	}
+
+	return err
+}
+
+func (local *Repo) makePullClosure(
+	remote repo.Repo,
+	list *sku.HeapTransacted,
+) (closure pullClosure, err error) {
+	closure = makePullClosure()
+
+	for object := range list.All() {
+		closure.addObject(object)
+
+		if object.GetGenre() != genres.InventoryList {
+			continue
+		}
+
+		// the list's blob was imported along with it, so its objects can be
+		// read locally
+		seq := local.GetInventoryListCoderCloset().StreamInventoryListBlobSkus(
+			object,
+		)
+
+		for listObject, errIter := range seq {
+			if errIter != nil {
+				err = errors.Wrapf(errIter, "InventoryList: %s", sku.String(object))
+				return closure, err
+			}
+
+			closure.addObject(listObject)
+		}
+	}
+
+	if err = closure.addConfigObjectsFrom(local, remote); err != nil {
+		err = errors.Wrap(err)
+		return closure, err
+	}
+
+	return closure, err
+}
+
+func (local *Repo) copyPullClosure(
+	remote repo.Repo,
+	closure pullClosure,
+	options repo.ImporterOptions,
+) (err error) {
+	blobImporter := blob_transfers.MakeBlobImporter(
+		local.GetEnvRepo().GetEnvBlobStore(),
+		remote.GetBlobStore(),
+		blob_stores.MakeBlobStoreMap(local.GetEnvRepo().GetDefaultBlobStore()),
+	)
+
+	blobImporter.CopierDelegate = options.BlobCopierDelegate
+
+	if blobImporter.CopierDelegate == nil && options.PrintCopies {
+		blobImporter.CopierDelegate = sku.MakeBlobCopierDelegate(
+			local.GetEnvRepo().GetUI(),
+			false,
+		)
+	}
+
+	for _, key := range closure.blobsOrder {
+		blob := closure.blobs[key]
+
+		if err = blobImporter.ImportBlobIfNecessary(blob.digest, nil); err != nil {
+			err = errors.Wrapf(err, "Referrer: %s", blob.referrer)
+			return err
+		}
+	}
+
+	return err
+}
+
+// reports every blob in the closure that is still missing from the local
+// default blob store
+func (local *Repo) verifyPullClosure(closure pullClosure) (err error) {
+	blobStore := local.GetEnvRepo().GetDefaultBlobStore()
+	missing := errors.MakeMultiErrorBuilder(0)
+
+	for _, key := range closure.blobsOrder {
+		blob := closure.blobs[key]
+
+		if blobStore.HasBlob(blob.digest) {
+			continue
+		}
+
+		missing.Addf(
+			env_dir.ErrBlobMissing{BlobId: blob.digest},
+			"Referrer: %s",
+			blob.referrer,
+		)
+	}
+
+	local.GetEnvRepo().GetUI().Printf(
+		"verified pull closure: %d blobs, %d missing",
+		len(closure.blobsOrder),
+		missing.Len(),
+	)
+
+	if err = missing.GetError(); err != nil {
+		err = errors.WithoutStack(err)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,68 @@
+package local_working_copy
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/blob_transfers"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// PushToParent copies every blob in the workspace's blob store that is missing
+// from the parent blob store it replaced, while holding the repo lock.
+// Workspaces share the repo's inventory lists and index, so blobs are the only
+// state that can diverge from the parent.
+func (local *Repo) PushToParent(
+	printCopies bool,
+) (counts blob_transfers.Counts, err error) {
+	workspace, parent, ok := local.GetEnvRepo().GetWorkspaceBlobStores()
+
+	if !ok {
+		err = errors.BadRequestf(
+			"not in a workspace with its own blob store",
+		)
+
+		return counts, err
+	}
+
+	// a pointer store reads and writes the parent directly
+	if _, isPointer := workspace.Config.Blob.(blob_store_configs.ConfigPointer); isPointer {
+		return counts, err
+	}
+
+	if err = local.Lock(); err != nil {
+		err = errors.Wrap(err)
+		return counts, err
+	}
+
+	defer errors.Deferred(&err, local.Unlock)
+
+	blobImporter := blob_transfers.MakeBlobImporter(
+		local.GetEnvRepo().GetEnvBlobStore(),
+		workspace,
+		blob_stores.MakeBlobStoreMap(parent),
+	)
+
+	if printCopies {
+		blobImporter.CopierDelegate = sku.MakeBlobCopierDelegate(
+			local.GetEnvRepo().GetUI(),
+			false,
+		)
+	}
+
+	for blobId, errIter := range workspace.AllBlobs() {
+		if errIter != nil {
+			err = errors.Wrap(errIter)
+			return counts, err
+		}
+
+		if err = blobImporter.ImportBlobIfNecessary(blobId, nil); err != nil {
+			err = errors.Wrap(err)
+			return counts, err
+		}
+	}
+
+	counts = blobImporter.Counts
+
+	return counts, err
+}
@@ -0,0 +1,125 @@
+package local_working_copy
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/quebec/repo"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+type SyncResult struct {
+	Pulled int
+	Pushed int
+}
+
+// syncDelta presents a repo as only containing the objects in list when
+// queried with queryGroup, so that the regular pull and push paths transfer
+// just the delta. Any other query (e.g., for the pull closure's konfig, types,
+// and tags) goes to the underlying repo.
+type syncDelta struct {
+	repo.Repo
+
+	queryGroup *queries.Query
+	list       *sku.HeapTransacted
+}
+
+func (delta syncDelta) MakeInventoryList(
+	queryGroup *queries.Query,
+) (list *sku.HeapTransacted, err error) {
+	if queryGroup == delta.queryGroup {
+		list = delta.list
+		return list, err
+	}
+
+	return delta.Repo.MakeInventoryList(queryGroup)
+}
+
+func getSyncKey(object *sku.Transacted) string {
+	return object.GetObjectId().String() + object.GetTai().String()
+}
+
+// returns the objects in from that are not in to
+func makeSyncDelta(from, to *sku.HeapTransacted) *sku.HeapTransacted {
+	keys := make(map[string]struct{}, to.Len())
+
+	for object := range to.All() {
+		keys[getSyncKey(object)] = struct{}{}
+	}
+
+	delta := sku.MakeListTransacted()
+
+	for object := range from.All() {
+		if _, ok := keys[getSyncKey(object)]; ok {
+			continue
+		}
+
+		delta.Add(object)
+	}
+
+	return delta
+}
+
+// Sync exchanges the objects matching queryGroup between local and remote in
+// both directions. Both sides' heads are compared first and only the objects
+// missing on each side are transferred, pulling before pushing so that any
+// merges happen locally. Blob copies are skipped when the destination store
+// already has the blob.
+func (local *Repo) Sync(
+	remote repo.Repo,
+	queryGroup *queries.Query,
+	options repo.ImporterOptions,
+) (result SyncResult, err error) {
+	var localHeads, remoteHeads *sku.HeapTransacted
+
+	if localHeads, err = local.MakeInventoryList(queryGroup); err != nil {
+		err = errors.Wrap(err)
+		return result, err
+	}
+
+	if remoteHeads, err = remote.MakeInventoryList(queryGroup); err != nil {
+		err = errors.Wrap(err)
+		return result, err
+	}
+
+	if missingLocally := makeSyncDelta(remoteHeads, localHeads); missingLocally.Len() > 0 {
+		if err = local.PullQueryGroupFromRemote(
+			syncDelta{
+				Repo:       remote,
+				queryGroup: queryGroup,
+				list:       missingLocally,
+			},
+			queryGroup,
+			options,
+		); err != nil {
+			err = errors.Wrap(err)
+			return result, err
+		}
+
+		result.Pulled = missingLocally.Len()
+
+		// the pull may have created merges that the remote is now missing
+		if localHeads, err = local.MakeInventoryList(queryGroup); err != nil {
+			err = errors.Wrap(err)
+			return result, err
+		}
+	}
+
+	if missingRemotely := makeSyncDelta(localHeads, remoteHeads); missingRemotely.Len() > 0 {
+		if err = remote.PullQueryGroupFromRemote(
+			syncDelta{
+				Repo:       local,
+				queryGroup: queryGroup,
+				list:       missingRemotely,
+			},
+			queryGroup,
+			options,
+		); err != nil {
+			err = errors.Wrap(err)
+			return result, err
+		}
+
+		result.Pushed = missingRemotely.Len()
+	}
+
+	return result, err
+}
@@ -1,12 +1,90 @@
 package local_working_copy
 
 import (
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/internal/echo/file_lock"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 )
 
+// lockSharedAccess takes the access lock shared for as long as the repo is
+// open, so that writes by other processes wait for it to be done reading.
+func (local *Repo) lockSharedAccess() (err error) {
+	accessLock := local.envRepo.GetAccessLock()
+
+	if accessLock == nil {
+		return err
+	}
+
+	if err = accessLock.Lock(file_lock.AccessShared); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	local.sharedAccess = true
+
+	return err
+}
+
+// RunWithoutSharedAccess runs f, like an editor or a utility run on checked
+// out files, without the shared access lock, so that other processes may
+// write while it runs. The exclusive access lock taken by Lock is kept.
+func (local *Repo) RunWithoutSharedAccess(f func() error) (err error) {
+	accessLock := local.envRepo.GetAccessLock()
+
+	if accessLock == nil || !local.sharedAccess {
+		if err = f(); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		return err
+	}
+
+	if err = accessLock.Unlock(file_lock.AccessShared); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	local.sharedAccess = false
+
+	if err = f(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = local.lockSharedAccess(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// Lock takes the access lock exclusively and the repo lock ahead of writes,
+// and fails with env_dir.ErrReadOnly under -read-only, before any write is
+// attempted. Writes by other processes are waited for, and reads by them
+// finished, for up to file_lock.EnvAccessLockTimeout.
 func (local *Repo) Lock() (err error) {
+	if local.envRepo.IsReadOnly() {
+		err = errors.Wrap(env_dir.ErrReadOnly{})
+		return err
+	}
+
+	accessLock := local.envRepo.GetAccessLock()
+
+	if accessLock != nil {
+		if err = accessLock.Lock(file_lock.AccessExclusive); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	if err = local.envRepo.GetLockSmith().Lock(); err != nil {
+		if accessLock != nil {
+			accessLock.Unlock(file_lock.AccessExclusive)
+		}
+
 		err = errors.Wrap(err)
 		return err
 	}
@@ -85,6 +163,12 @@ func (local *Repo) Unlock() (err error) {
 			err = errors.Wrap(err)
 			return err
 		}
+
+		ui.Log().Print("will run due maintenance")
+		if err = local.runDueMaintenance(); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
 	}
 
 	// explicitly do not unlock if there was an error to encourage user
@@ -95,5 +179,12 @@ func (local *Repo) Unlock() (err error) {
 		return err
 	}
 
+	if accessLock := local.envRepo.GetAccessLock(); accessLock != nil {
+		if err = accessLock.Unlock(file_lock.AccessExclusive); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	return err
 }
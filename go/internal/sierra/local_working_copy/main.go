@@ -46,6 +46,7 @@ type Repo struct {
 	dormantIndex dormant_index.Index
 
 	storesInitialized bool
+	sharedAccess      bool
 	typedBlobStore    typed_blob_store.Stores
 	store             store.Store
 
@@ -54,6 +55,8 @@ type Repo struct {
 
 	DormantCounter queries.DormantCounter
 
+	federatedBlobStores FederatedBlobStores
+
 	envLua env_lua.Env
 }
 
@@ -99,6 +102,12 @@ func MakeWithEnvRepo(
 
 	repo.config.Reset()
 
+	if !options.GetWithoutSharedAccess() {
+		if err := repo.lockSharedAccess(); err != nil {
+			repo.Cancel(err)
+		}
+	}
+
 	if err := repo.initialize(options); err != nil {
 		repo.Cancel(err)
 	}
@@ -151,6 +160,14 @@ func (local *Repo) initialize(
 		}
 	}
 
+	// a route to a missing blob store must not stop edit-config from fixing it
+	if err = local.envRepo.SetBlobStoreRoutes(
+		local.config.GetConfig().GetBlobStoreRoutes(),
+	); err != nil {
+		ui.Err().Printf("ignoring the blob store routes: %s", err)
+		err = nil
+	}
+
 	if local.envWorkspace, err = env_workspace.Make(
 		local.envRepo,
 		local.config.GetConfig(),
@@ -180,6 +197,7 @@ func (local *Repo) initialize(
 		local.envRepo,
 		local.GetStore(),
 		local.SkuFormatBoxTransactedNoColor(),
+		local.config.GetConfig().GetLuaLimits(),
 	)
 
 	// for _, rb := range u.GetConfig().Recipients {
@@ -1,8 +1,12 @@
 package local_working_copy
 
 import (
+	"os"
+
 	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
 	"code.linenisgreat.com/dodder/go/internal/bravo/checked_out_state"
+	"code.linenisgreat.com/dodder/go/internal/charlie/fd"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/internal/november/env_workspace"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
@@ -14,6 +18,7 @@ func (local *Repo) Checkin(
 	proto sku.Proto,
 	delete bool,
 	refreshCheckout bool,
+	allowInvalidBlob bool,
 ) (processed sku.TransactedMutableSet, err error) {
 	local.Must(errors.MakeFuncContextFromFuncErr(local.Lock))
 
@@ -25,6 +30,11 @@ func (local *Repo) Checkin(
 		},
 	)
 
+	if err = local.checkFreeSpaceForCheckin(sortedResults); err != nil {
+		err = errors.Wrap(err)
+		return processed, err
+	}
+
 	for _, co := range sortedResults {
 		if refreshCheckout {
 			if err = local.GetEnvWorkspace().GetStoreFS().RefreshCheckedOut(
@@ -62,6 +72,9 @@ func (local *Repo) Checkin(
 			if err = local.GetStore().CreateOrUpdate(
 				external,
 				sku.CommitOptions{
+					StoreOptions: sku.StoreOptions{
+						AllowInvalidBlob: allowInvalidBlob,
+					},
 					Proto: proto,
 				},
 			); err != nil {
@@ -72,6 +85,7 @@ func (local *Repo) Checkin(
 			if err = local.GetStore().CreateOrUpdateCheckedOut(
 				co,
 				!delete,
+				allowInvalidBlob,
 			); err != nil {
 				err = errors.Wrapf(err, "CheckedOut: %s", co)
 				return processed, err
@@ -98,3 +112,42 @@ func (local *Repo) Checkin(
 
 	return processed, err
 }
+
+// checkFreeSpaceForCheckin fails with files.ErrInsufficientSpace, before
+// anything is committed, when the checked out files of checkedOut would not
+// fit in the default blob store as blobs. Objects that are not checked out to
+// the filesystem are not counted.
+func (local *Repo) checkFreeSpaceForCheckin(
+	checkedOut []sku.SkuType,
+) (err error) {
+	storeFS := local.GetEnvWorkspace().GetStoreFS()
+
+	var requiredBytes uint64
+
+	for _, co := range checkedOut {
+		item, readErr := storeFS.ReadFSItemFromExternal(co.GetSkuExternal())
+		if readErr != nil {
+			continue
+		}
+
+		for _, fdee := range []*fd.FD{&item.Object, &item.Blob} {
+			if fdee.IsEmpty() {
+				continue
+			}
+
+			if info, statErr := os.Stat(fdee.GetPath()); statErr == nil {
+				requiredBytes += uint64(info.Size())
+			}
+		}
+	}
+
+	if err = blob_stores.CheckFreeSpace(
+		local.GetEnvRepo().GetDefaultBlobStore(),
+		requiredBytes,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,28 @@
+package local_working_copy
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/india/stream_index"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func (local *Repo) CompactStreamIndex(
+	options stream_index.CompactionOptions,
+	threshold float64,
+) (stats stream_index.CompactionStats, compacted bool) {
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Lock))
+
+	var err error
+
+	if stats, compacted, err = local.GetStore().CompactStreamIndex(
+		options,
+		threshold,
+		local.PrinterHeader(),
+	); err != nil {
+		local.Cancel(err)
+		return stats, compacted
+	}
+
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Unlock))
+
+	return stats, compacted
+}
@@ -0,0 +1,26 @@
+package local_working_copy
+
+import (
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/papa/store"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func (local *Repo) EmptyTrash(
+	retention time.Duration,
+) (purge store.TrashPurge) {
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Lock))
+	local.Must(errors.MakeFuncContextFromFuncErr(local.config.Reset))
+
+	local.Must(
+		errors.MakeFuncContextFromFuncErr(func() (err error) {
+			purge, err = local.GetStore().EmptyTrash(retention)
+			return err
+		}),
+	)
+
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Unlock))
+
+	return purge
+}
@@ -0,0 +1,120 @@
+package local_working_copy
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/hotel/maintenance"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+const (
+	maintenanceTaskArchiveCaches = "archive-caches"
+	maintenanceTaskSmallPack     = "small-pack"
+)
+
+// runDueMaintenance runs the `maintenance` tasks of the repo config that are
+// due, if `maintenance.auto` is set. It is called at the end of write
+// commands while the repo lock is still held. Failing tasks are reported but
+// do not fail the command.
+func (local *Repo) runDueMaintenance() (err error) {
+	config := local.GetConfig()
+	maintenanceConfig := config.GetMaintenance()
+
+	if !maintenanceConfig.Auto || config.IsDryRun() {
+		return err
+	}
+
+	var options maintenance.Options
+
+	if options.Interval, err = maintenanceConfig.GetInterval(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if options.Budget, err = maintenanceConfig.GetBudget(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var coordinator *maintenance.Coordinator
+
+	if coordinator, err = maintenance.Make(
+		local.GetEnvRepo().GetXDG().Cache.MakePath("maintenance").String(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var results []maintenance.Result
+
+	if results, err = coordinator.RunDue(
+		options,
+		maintenance.Task{
+			Name: maintenanceTaskArchiveCaches,
+			Run:  local.rebuildArchiveCaches,
+		},
+		maintenance.Task{
+			Name: maintenanceTaskSmallPack,
+			Run: func() error {
+				return local.packSmall(maintenanceConfig.GetPackMaxBlobs())
+			},
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			ui.Err().Printf("maintenance %s failed: %s", result.Name, result.Err)
+		} else {
+			ui.Log().Printf("maintenance %s took %s", result.Name, result.Elapsed)
+		}
+	}
+
+	return err
+}
+
+// rebuildArchiveCaches rebuilds the index caches of archive blob stores that
+// are stale or fail validation.
+func (local *Repo) rebuildArchiveCaches() (err error) {
+	for _, blobStore := range local.GetEnvRepo().GetBlobStoresSorted() {
+		if _, _, err = blob_stores.WarmBlobStore(
+			blobStore.BlobStore,
+			blob_stores.WarmOptions{},
+		); err != nil {
+			err = errors.Wrapf(err, "blob store %s", blobStore.Path.GetId())
+			return err
+		}
+	}
+
+	return err
+}
+
+// packSmall packs up to maxBlobs loose blobs of each archive blob store,
+// deleting them once they are packed. Stores that another process is packing
+// are skipped.
+func (local *Repo) packSmall(maxBlobs int) (err error) {
+	for _, blobStore := range local.GetEnvRepo().GetBlobStoresSorted() {
+		packable, ok := blobStore.BlobStore.(blob_stores.PackableArchive)
+		if !ok {
+			continue
+		}
+
+		if err = packable.Pack(blob_stores.PackOptions{
+			Context:              local.GetEnvRepo(),
+			DeleteLoose:          true,
+			DeletionPrecondition: blob_stores.NopDeletionPrecondition(),
+			MaxBlobs:             maxBlobs,
+			NoWait:               true,
+		}); errors.Is(err, blob_stores.ErrStoreBusy{}) {
+			err = nil
+			continue
+		} else if err != nil {
+			err = errors.Wrapf(err, "blob store %s", blobStore.Path.GetId())
+			return err
+		}
+	}
+
+	return err
+}
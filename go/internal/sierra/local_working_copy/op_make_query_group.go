@@ -38,6 +38,8 @@ func (local *Repo) makeQueryBuilder() *queries.Builder {
 		local.GetStore().GetStreamIndex(),
 		local.envLua.MakeLuaVMPoolBuilder(),
 		local,
+	).WithOptions(
+		queries.BuilderOptionVirtualTags(local.GetStore()),
 	)
 }
 
@@ -0,0 +1,11 @@
+package local_working_copy
+
+import "code.linenisgreat.com/dodder/go/lib/bravo/errors"
+
+func (local *Repo) RebuildBlobRefcounts() {
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Lock))
+	local.Must(
+		errors.MakeFuncContextFromFuncErr(local.GetStore().RebuildBlobRefcounts),
+	)
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Unlock))
+}
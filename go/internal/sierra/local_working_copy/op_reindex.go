@@ -8,3 +8,10 @@ func (local *Repo) Reindex() {
 	local.Must(local.GetStore().Reindex)
 	local.Must(errors.MakeFuncContextFromFuncErr(local.Unlock))
 }
+
+func (local *Repo) ReindexFromCheckpoint() {
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Lock))
+	local.Must(errors.MakeFuncContextFromFuncErr(local.config.Reset))
+	local.Must(local.GetStore().ReindexFromCheckpoint)
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Unlock))
+}
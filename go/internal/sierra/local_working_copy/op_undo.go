@@ -0,0 +1,30 @@
+package local_working_copy
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/juliett/inventory_list_store"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func (local *Repo) UndoInventoryList(
+	list *sku.Transacted,
+	hard bool,
+) (orphan inventory_list_store.Orphan) {
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Lock))
+
+	if hard {
+		local.Must(errors.MakeFuncContextFromFuncErr(local.config.Reset))
+	}
+
+	local.Must(
+		func(context interfaces.ActiveContext) (err error) {
+			orphan, err = local.GetStore().UndoInventoryList(context, list, hard)
+			return err
+		},
+	)
+
+	local.Must(errors.MakeFuncContextFromFuncErr(local.Unlock))
+
+	return orphan
+}
@@ -10,8 +10,16 @@ type (
 const (
 	OptionsEmpty                = Options(iota)
 	OptionsAllowConfigReadError = Options(1 << iota)
+	// OptionsWithoutSharedAccess is for long running commands, like servers,
+	// which would otherwise keep other processes from writing for as long as
+	// they run. They still take the access lock exclusively to write.
+	OptionsWithoutSharedAccess
 )
 
 func (o Options) GetAllowConfigReadError() bool {
 	return o&OptionsAllowConfigReadError != 0
 }
+
+func (o Options) GetWithoutSharedAccess() bool {
+	return o&OptionsWithoutSharedAccess != 0
+}
@@ -0,0 +1,567 @@
+package language_server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/alfa/object_links"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/sku_json_fmt"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/jsonrpc"
+)
+
+// maxCompletionItems caps completion lists, which are marked incomplete when
+// there are more matches so that the editor asks again as the prefix grows.
+const maxCompletionItems = 200
+
+// Server answers language server protocol requests over a JSON-RPC stream,
+// treating `[object-id]` in open documents as links to the repo's objects.
+// Messages are handled in order, so that requests see the document changes
+// sent before them.
+type Server struct {
+	Repo *local_working_copy.Repo
+
+	documents      map[string]TextDocumentItem
+	exited         bool
+	readCheckedOut bool
+}
+
+type ResolvedLink struct {
+	Object sku_json_fmt.Object `json:"object"`
+
+	// Path is the checked out file of the object in the workspace, if any.
+	Path string `json:"path,omitempty"`
+}
+
+// Run serves requests from reader until the client exits or closes the
+// stream.
+func (server *Server) Run(
+	ctx context.Context,
+	reader io.Reader,
+	writer io.Writer,
+) (err error) {
+	server.documents = make(map[string]TextDocumentItem)
+
+	// the stream wraps EOF, so it is checked for between messages. The stream
+	// reuses a bufio.Reader instead of buffering it again, so nothing it has
+	// read ahead is missed.
+	bufferedReader := bufio.NewReader(reader)
+	stream := jsonrpc.NewStream(bufferedReader, writer)
+
+	for !server.exited && ctx.Err() == nil {
+		if _, err = bufferedReader.Peek(1); err != nil {
+			if errors.IsEOF(err) {
+				err = nil
+				return err
+			}
+
+			err = errors.Wrap(err)
+			return err
+		}
+
+		var message *jsonrpc.Message
+
+		if message, err = stream.Read(); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if message.IsResponse() {
+			continue
+		}
+
+		var response *jsonrpc.Message
+
+		if response, err = server.handle(message); err != nil {
+			if !message.IsRequest() {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			if response, err = jsonrpc.NewErrorResponse(
+				*message.ID,
+				jsonrpc.InternalError,
+				err.Error(),
+				nil,
+			); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+		}
+
+		if response == nil {
+			continue
+		}
+
+		if err = stream.Write(response); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
+
+func (server *Server) handle(
+	message *jsonrpc.Message,
+) (response *jsonrpc.Message, err error) {
+	var result any
+
+	switch message.Method {
+	case "initialize":
+		result = map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   1,
+				"hoverProvider":      true,
+				"definitionProvider": true,
+				"completionProvider": map[string]any{
+					"triggerCharacters": []string{"["},
+				},
+			},
+			"serverInfo": map[string]any{"name": "dodder"},
+		}
+
+	case "shutdown":
+		result = nil
+
+	case "exit":
+		server.exited = true
+		return response, err
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+
+		if err = json.Unmarshal(message.Params, &params); err != nil {
+			break
+		}
+
+		server.documents[params.TextDocument.URI] = params.TextDocument
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+
+		if err = json.Unmarshal(message.Params, &params); err != nil {
+			break
+		}
+
+		server.didChange(params)
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+
+		if err = json.Unmarshal(message.Params, &params); err != nil {
+			break
+		}
+
+		delete(server.documents, params.TextDocument.URI)
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+
+		if err = json.Unmarshal(message.Params, &params); err != nil {
+			break
+		}
+
+		var hover *Hover
+
+		if hover, err = server.hover(params); err != nil {
+			err = errors.Wrap(err)
+			return response, err
+		}
+
+		result = nullable(hover)
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+
+		if err = json.Unmarshal(message.Params, &params); err != nil {
+			break
+		}
+
+		result = server.completion(params)
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+
+		if err = json.Unmarshal(message.Params, &params); err != nil {
+			break
+		}
+
+		var location *Location
+
+		if location, err = server.definition(params); err != nil {
+			err = errors.Wrap(err)
+			return response, err
+		}
+
+		result = nullable(location)
+
+	case "dodder/resolveLink":
+		var params ResolveLinkParams
+
+		if err = json.Unmarshal(message.Params, &params); err != nil {
+			break
+		}
+
+		var resolved *ResolvedLink
+
+		if resolved, err = server.resolveLink(params); err != nil {
+			err = errors.Wrap(err)
+			return response, err
+		}
+
+		result = nullable(resolved)
+
+	default:
+		if message.IsRequest() {
+			return jsonrpc.NewErrorResponse(
+				*message.ID,
+				jsonrpc.MethodNotFound,
+				"method not found: "+message.Method,
+				nil,
+			)
+		}
+
+		return response, err
+	}
+
+	// the only errors left are from decoding params, and errors from
+	// notifications are dropped
+	if !message.IsRequest() {
+		err = nil
+		return response, err
+	}
+
+	if err != nil {
+		return jsonrpc.NewErrorResponse(
+			*message.ID,
+			jsonrpc.InvalidParams,
+			err.Error(),
+			nil,
+		)
+	}
+
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+
+	return jsonrpc.NewResponse(*message.ID, result)
+}
+
+// nullable keeps a nil pointer result from becoming a non-nil interface, so
+// that it is sent as `null`.
+func nullable[T any](value *T) any {
+	if value == nil {
+		return nil
+	}
+
+	return value
+}
+
+func (server *Server) didChange(params DidChangeTextDocumentParams) {
+	document, ok := server.documents[params.TextDocument.URI]
+
+	if !ok || len(params.ContentChanges) == 0 ||
+		params.TextDocument.Version < document.Version {
+		return
+	}
+
+	document.Version = params.TextDocument.Version
+	document.Text = params.ContentChanges[len(params.ContentChanges)-1].Text
+	server.documents[params.TextDocument.URI] = document
+}
+
+func (server *Server) linkAt(
+	params TextDocumentPositionParams,
+) (link object_links.Link, ok bool) {
+	document, ok := server.documents[params.TextDocument.URI]
+
+	if !ok {
+		return link, ok
+	}
+
+	return object_links.At(
+		document.Text,
+		params.Position.Line,
+		params.Position.Character,
+	)
+}
+
+// readObject returns nil without an error for ids that are not objects in the
+// repo, as links in notes may point at objects that do not exist yet.
+func (server *Server) readObject(
+	objectIdString string,
+) (object *sku.Transacted, err error) {
+	var objectId ids.ObjectId
+
+	if err = objectId.Set(objectIdString); err != nil {
+		err = nil
+		return object, err
+	}
+
+	if object, err = server.Repo.GetStore().ReadOneObjectId(
+		&objectId,
+	); err != nil {
+		if errors.IsErrNotFound(err) {
+			err = nil
+			return object, err
+		}
+
+		err = errors.Wrap(err)
+		return object, err
+	}
+
+	return object, err
+}
+
+func (server *Server) hover(
+	params TextDocumentPositionParams,
+) (hover *Hover, err error) {
+	link, ok := server.linkAt(params)
+
+	if !ok {
+		return hover, err
+	}
+
+	var object *sku.Transacted
+
+	if object, err = server.readObject(link.ObjectId); err != nil {
+		err = errors.Wrap(err)
+		return hover, err
+	}
+
+	if object == nil {
+		return hover, err
+	}
+
+	var jsonObject sku_json_fmt.Object
+
+	if err = jsonObject.FromTransacted(object, nil); err != nil {
+		err = errors.Wrap(err)
+		return hover, err
+	}
+
+	var value strings.Builder
+
+	value.WriteString("**" + jsonObject.ObjectId + "**")
+
+	if jsonObject.Description != "" {
+		value.WriteString(" " + jsonObject.Description)
+	}
+
+	value.WriteString("\n\ntype: `" + jsonObject.Type + "`")
+
+	if len(jsonObject.Tags) > 0 {
+		value.WriteString("\n\ntags: `" + strings.Join(jsonObject.Tags, "` `") + "`")
+	}
+
+	hover = &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: value.String()},
+		Range: Range{
+			Start: Position{Line: link.Line, Character: link.Start},
+			End:   Position{Line: link.Line, Character: link.End},
+		},
+	}
+
+	return hover, err
+}
+
+// completion offers the ids seen by the abbreviation index, which, unlike the
+// probe index, can be searched by prefix.
+func (server *Server) completion(
+	params TextDocumentPositionParams,
+) (list CompletionList) {
+	list.Items = []CompletionItem{}
+
+	document, ok := server.documents[params.TextDocument.URI]
+
+	if !ok {
+		return list
+	}
+
+	prefix, start, ok := object_links.CompletionPrefixAt(
+		document.Text,
+		params.Position.Line,
+		params.Position.Character,
+	)
+
+	if !ok {
+		return list
+	}
+
+	replace := Range{
+		Start: Position{Line: params.Position.Line, Character: start},
+		End:   params.Position,
+	}
+
+	for genre, collection := range server.Repo.GetAbbr().GetSeenIds() {
+		kind := CompletionItemKindFile
+
+		switch genre {
+		case genres.Tag:
+			kind = CompletionItemKindKeyword
+
+		case genres.Type:
+			kind = CompletionItemKindClass
+		}
+
+		for objectId := range collection.All() {
+			if !strings.HasPrefix(objectId, prefix) {
+				continue
+			}
+
+			if len(list.Items) == maxCompletionItems {
+				list.IsIncomplete = true
+				break
+			}
+
+			list.Items = append(list.Items, CompletionItem{
+				Label:    objectId,
+				Kind:     kind,
+				Detail:   genre.String(),
+				TextEdit: TextEdit{Range: replace, NewText: objectId},
+			})
+		}
+	}
+
+	slices.SortFunc(list.Items, func(left, right CompletionItem) int {
+		return strings.Compare(left.Label, right.Label)
+	})
+
+	return list
+}
+
+// checkedOutPath returns the path of the object's checked out file in the
+// workspace, if it is checked out.
+func (server *Server) checkedOutPath(
+	object *sku.Transacted,
+) (path string, err error) {
+	envWorkspace := server.Repo.GetEnvWorkspace()
+
+	if envWorkspace.IsTemporary() {
+		return path, err
+	}
+
+	storeFS := envWorkspace.GetStoreFS()
+
+	if !server.readCheckedOut {
+		if err = storeFS.ReadAllExternalItems(); err != nil {
+			err = errors.Wrap(err)
+			return path, err
+		}
+
+		server.readCheckedOut = true
+	}
+
+	item, ok := storeFS.Get(object.GetObjectId())
+
+	if !ok {
+		return path, err
+	}
+
+	if !item.Object.IsEmpty() {
+		path = item.Object.GetPath()
+	} else if !item.Blob.IsEmpty() {
+		path = item.Blob.GetPath()
+	}
+
+	if path != "" {
+		if path, err = filepath.Abs(path); err != nil {
+			err = errors.Wrap(err)
+			return path, err
+		}
+	}
+
+	return path, err
+}
+
+func (server *Server) definition(
+	params TextDocumentPositionParams,
+) (location *Location, err error) {
+	link, ok := server.linkAt(params)
+
+	if !ok {
+		return location, err
+	}
+
+	var object *sku.Transacted
+
+	if object, err = server.readObject(link.ObjectId); err != nil {
+		err = errors.Wrap(err)
+		return location, err
+	}
+
+	if object == nil {
+		return location, err
+	}
+
+	var path string
+
+	if path, err = server.checkedOutPath(object); err != nil {
+		err = errors.Wrap(err)
+		return location, err
+	}
+
+	if path == "" {
+		return location, err
+	}
+
+	location = &Location{
+		URI: (&url.URL{Scheme: "file", Path: path}).String(),
+	}
+
+	return location, err
+}
+
+// resolveLink resolves the link at a position, or the given object id, to the
+// object's metadata and checked out path.
+func (server *Server) resolveLink(
+	params ResolveLinkParams,
+) (resolved *ResolvedLink, err error) {
+	objectId := params.ObjectId
+
+	if objectId == "" {
+		link, ok := server.linkAt(params.TextDocumentPositionParams)
+
+		if !ok {
+			return resolved, err
+		}
+
+		objectId = link.ObjectId
+	}
+
+	var object *sku.Transacted
+
+	if object, err = server.readObject(objectId); err != nil {
+		err = errors.Wrap(err)
+		return resolved, err
+	}
+
+	if object == nil {
+		return resolved, err
+	}
+
+	resolved = &ResolvedLink{}
+
+	if err = resolved.Object.FromTransacted(object, nil); err != nil {
+		err = errors.Wrap(err)
+		return resolved, err
+	}
+
+	if resolved.Path, err = server.checkedOutPath(object); err != nil {
+		err = errors.Wrap(err)
+		return resolved, err
+	}
+
+	return resolved, err
+}
@@ -47,6 +47,10 @@ type Server struct {
 	Repo      *local_working_copy.Repo
 	blobCache serverBlobCache
 
+	// ReadOnly serves only the routes that read the repo, without requiring
+	// requests to be signed, for browsing it from web frontends and editors.
+	ReadOnly bool
+
 	GetCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
 }
 
@@ -170,16 +174,48 @@ func (server *Server) makeRouter(
 			"GET",
 		)
 
+	router.HandleFunc(
+		"/blobs/{blob_id}",
+		makeHandler(server.handleBlobsHeadOrGet),
+	).
+		Methods(
+			"HEAD",
+			"GET",
+		)
+
 	{
+		router.HandleFunc("/objects", makeHandler(server.handleGetObjects)).
+			Methods("GET")
+
+		// registered first so that ids with unescaped slashes still match
 		router.HandleFunc(
-			"/blobs/{blob_id}",
-			makeHandler(server.handleBlobsHeadOrGet),
+			"/objects/{object_id:.+}/blob",
+			makeHandler(server.handleGetObjectBlob),
+		).
+			Methods(
+				"GET",
+			)
+
+		router.HandleFunc(
+			"/objects/{object_id:.+}",
+			makeHandler(server.handleGetObject),
 		).
 			Methods(
-				"HEAD",
 				"GET",
 			)
+	}
 
+	if server.ReadOnly {
+		if server.Repo.GetEnv().GetCLIConfig().GetVerbose() {
+			router.Use(server.loggerMiddleware)
+		}
+
+		router.Use(server.panicHandlingMiddleware)
+
+		return router
+	}
+
+	{
 		router.HandleFunc(
 			"/blobs/{blob_id}",
 			makeHandler(server.handleBlobsPost),
@@ -0,0 +1,257 @@
+package remote_http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/echo/env_dir"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/sku_json_fmt"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ohio"
+)
+
+// setETagOrNotModified sets the ETag and reports whether the client already
+// has this version.
+func setETagOrNotModified(
+	request Request,
+	response *Response,
+	etag string,
+) (notModified bool) {
+	response.Headers().Set("ETag", etag)
+
+	for candidate := range strings.SplitSeq(
+		request.Headers.Get("If-None-Match"),
+		",",
+	) {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+
+		if candidate == etag || candidate == "*" {
+			response.StatusCode = http.StatusNotModified
+			return true
+		}
+	}
+
+	return false
+}
+
+func (server *Server) writeJSON(response *Response, value any) {
+	var buffer bytes.Buffer
+
+	enc := json.NewEncoder(&buffer)
+
+	if err := enc.Encode(value); err != nil {
+		response.Error(err)
+		return
+	}
+
+	response.Headers().Set("Content-Type", "application/json")
+	response.Body = ohio.NopCloser(&buffer)
+}
+
+// handleGetObjects runs the `q` query parameter, split on whitespace into the
+// same arguments the CLI takes, and responds with the matching objects
+// ordered by object id.
+func (server *Server) handleGetObjects(request Request) (response Response) {
+	args := strings.Fields(request.request.URL.Query().Get("q"))
+
+	var query *queries.Query
+
+	{
+		var err error
+
+		if query, err = server.Repo.MakeExternalQueryGroup(
+			queries.BuilderOptions(
+				queries.BuilderOptionDefaultGenres(genres.Zettel),
+			),
+			sku.ExternalQueryOptions{},
+			args...,
+		); err != nil {
+			response.ErrorWithStatus(http.StatusBadRequest, err)
+			return response
+		}
+	}
+
+	var lock sync.Mutex
+	var objects []sku_json_fmt.Object
+	var objectDigests []string
+
+	if err := server.Repo.GetStore().QueryTransacted(
+		query,
+		func(object *sku.Transacted) (err error) {
+			var jsonObject sku_json_fmt.Object
+
+			if err = jsonObject.FromTransacted(object, nil); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			objects = append(objects, jsonObject)
+			objectDigests = append(
+				objectDigests,
+				jsonObject.ObjectId+" "+object.GetObjectDigest().String(),
+			)
+
+			return err
+		},
+	); err != nil {
+		response.Error(err)
+		return response
+	}
+
+	slices.SortFunc(objects, func(left, right sku_json_fmt.Object) int {
+		return strings.Compare(left.ObjectId, right.ObjectId)
+	})
+
+	slices.Sort(objectDigests)
+
+	hash := sha256.New()
+
+	for _, objectDigest := range objectDigests {
+		hash.Write([]byte(objectDigest))
+		hash.Write([]byte{'\n'})
+	}
+
+	if setETagOrNotModified(
+		request,
+		&response,
+		`"`+hex.EncodeToString(hash.Sum(nil))+`"`,
+	) {
+		return response
+	}
+
+	if objects == nil {
+		objects = []sku_json_fmt.Object{}
+	}
+
+	server.writeJSON(&response, objects)
+
+	return response
+}
+
+func (server *Server) readObjectFromVars(
+	request Request,
+	response *Response,
+) (object *sku.Transacted, ok bool) {
+	objectIdString, err := url.PathUnescape(request.Vars()["object_id"])
+	if err != nil {
+		response.ErrorWithStatus(http.StatusBadRequest, err)
+		return object, ok
+	}
+
+	var objectId ids.ObjectId
+
+	if err = objectId.Set(objectIdString); err != nil {
+		response.ErrorWithStatus(http.StatusBadRequest, err)
+		return object, ok
+	}
+
+	if object, err = server.Repo.GetStore().ReadOneObjectId(
+		&objectId,
+	); err != nil {
+		if errors.IsErrNotFound(err) {
+			response.ErrorWithStatus(http.StatusNotFound, err)
+		} else {
+			response.Error(err)
+		}
+
+		return object, ok
+	}
+
+	ok = true
+
+	return object, ok
+}
+
+// handleGetObject responds with one object's metadata, tagged with its
+// object digest.
+func (server *Server) handleGetObject(request Request) (response Response) {
+	object, ok := server.readObjectFromVars(request, &response)
+	if !ok {
+		return response
+	}
+
+	if setETagOrNotModified(
+		request,
+		&response,
+		`"`+object.GetObjectDigest().String()+`"`,
+	) {
+		return response
+	}
+
+	var jsonObject sku_json_fmt.Object
+
+	if err := jsonObject.FromTransacted(object, nil); err != nil {
+		response.Error(err)
+		return response
+	}
+
+	server.writeJSON(&response, jsonObject)
+
+	return response
+}
+
+// handleGetObjectBlob responds with one object's blob, tagged with its blob
+// id. Blobs of inline types are served as text.
+func (server *Server) handleGetObjectBlob(
+	request Request,
+) (response Response) {
+	object, ok := server.readObjectFromVars(request, &response)
+	if !ok {
+		return response
+	}
+
+	blobId := object.GetBlobDigest()
+
+	if setETagOrNotModified(
+		request,
+		&response,
+		`"`+blobId.String()+`"`,
+	) {
+		return response
+	}
+
+	contentType := "application/octet-stream"
+
+	if server.Repo.GetConfig().IsInlineType(object.GetType()) {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	response.Headers().Set("Content-Type", contentType)
+
+	var blobReader domain_interfaces.BlobReader
+
+	{
+		var err error
+
+		if blobReader, err = server.Repo.GetBlobStore().MakeBlobReader(
+			blobId,
+		); err != nil {
+			if env_dir.IsErrBlobMissing(err) {
+				response.ErrorWithStatus(http.StatusNotFound, err)
+			} else {
+				response.Error(err)
+			}
+
+			return response
+		}
+	}
+
+	response.Body = blobReader
+
+	return response
+}
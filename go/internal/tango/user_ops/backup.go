@@ -0,0 +1,50 @@
+package user_ops
+
+import (
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/echo/snapshot_tar"
+)
+
+// Backup writes a snapshot of the repo's inventory lists, indexes, blob stores
+// and configs (the roots of env_repo.Env.GetBackupRoots) as a tarball ending
+// with a manifest of checksums. It holds the repo lock throughout, so the
+// snapshot is consistent.
+type Backup struct {
+	*local_working_copy.Repo
+}
+
+func (op Backup) Run(
+	writer io.Writer,
+) (manifest snapshot_tar.Manifest, err error) {
+	envRepo := op.GetEnvRepo()
+	lockSmith := envRepo.GetLockSmith()
+
+	if err = lockSmith.Lock(); err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	defer errors.Deferred(&err, lockSmith.Unlock)
+
+	snapshotWriter := snapshot_tar.NewWriter(writer)
+
+	for _, root := range envRepo.GetBackupRoots() {
+		if err = snapshotWriter.AddRoot(
+			root,
+			envRepo.IsSkippedInBackup,
+		); err != nil {
+			err = errors.Wrap(err)
+			return manifest, err
+		}
+	}
+
+	if manifest, err = snapshotWriter.Close(); err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	return manifest, err
+}
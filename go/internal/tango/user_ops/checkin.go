@@ -26,15 +26,32 @@ type Checkin struct {
 	CheckoutBlobAndRun string
 	OpenBlob           bool
 	Edit               bool // TODO add support back for this
+
+	// Force commits blobs that do not match the schema of their type.
+	Force bool
 }
 
 func (op Checkin) Run(
 	repo *local_working_copy.Repo,
 	query *queries.Query,
 ) (err error) {
+	if _, err = op.RunAndGetResults(repo, query); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+// RunAndGetResults is Run for callers that report what was checked in. The
+// external objects of the results carry the ids they were committed under.
+func (op Checkin) RunAndGetResults(
+	repo *local_working_copy.Repo,
+	query *queries.Query,
+) (results sku.SkuTypeSetMutable, err error) {
 	var lock sync.Mutex
 
-	results := sku.MakeSkuTypeSetMutable()
+	results = sku.MakeSkuTypeSetMutable()
 
 	if err = repo.GetStore().QuerySkuType(
 		query,
@@ -47,13 +64,13 @@ func (op Checkin) Run(
 		},
 	); err != nil {
 		err = errors.Wrap(err)
-		return err
+		return results, err
 	}
 
 	if op.Organize {
 		if err = op.runOrganize(repo, query, results); err != nil {
 			err = errors.Wrap(err)
-			return err
+			return results, err
 		}
 
 		objects.Resetter.Reset(&op.Proto.Metadata)
@@ -66,17 +83,18 @@ func (op Checkin) Run(
 		op.Proto,
 		op.Delete,
 		op.RefreshCheckout,
+		op.Force,
 	); err != nil {
 		err = errors.Wrap(err)
-		return err
+		return results, err
 	}
 
 	if err = op.openBlobIfNecessary(repo, processed); err != nil {
 		err = errors.Wrap(err)
-		return err
+		return results, err
 	}
 
-	return err
+	return results, err
 }
 
 func (op Checkin) runOrganize(
@@ -120,11 +120,15 @@ func (op Checkout) RunQuery(
 	}
 
 	if op.Open || op.Edit {
-		if err = op.GetStore().Open(
-			query.RepoId,
-			op.CheckoutMode,
-			op.PrinterHeader(),
-			checkedOut,
+		if err = op.RunWithoutSharedAccess(
+			func() error {
+				return op.GetStore().Open(
+					query.RepoId,
+					op.CheckoutMode,
+					op.PrinterHeader(),
+					checkedOut,
+				)
+			},
 		); err != nil {
 			err = errors.Wrap(err)
 			return checkedOut, err
@@ -142,6 +146,7 @@ func (op Checkout) RunQuery(
 			sku.Proto{},
 			false,
 			op.RefreshCheckout,
+			false,
 		); err != nil {
 			err = errors.Wrap(err)
 			return checkedOut, err
@@ -59,7 +59,7 @@ func (c EachBlob) Run(
 	cmd.Stdin = c.GetInFile()
 	cmd.Stderr = c.GetErrFile()
 
-	if err = cmd.Run(); err != nil {
+	if err = c.RunWithoutSharedAccess(cmd.Run); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
@@ -0,0 +1,269 @@
+package user_ops
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/object_metadata_fmt_triple_hyphen"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/object_diff"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+// EditInTempFiles edits objects without a workspace. Each object is written to
+// a temp file in the text format and the editor is opened on all of them.
+// Only the fields changed in each file are committed, on top of the latest
+// version of the object, so that changes made while the editor was open are
+// kept. An object changed in the same fields as the edit is not committed,
+// and its temp file is left in place.
+type EditInTempFiles struct {
+	*local_working_copy.Repo
+}
+
+// editInTempFile holds a clone of the object being edited, repooled once
+// RunQuery is done with it.
+//
+//repool:stored
+type editInTempFile struct {
+	base       *sku.Transacted
+	repoolBase interfaces.FuncRepool
+	path       string
+}
+
+func (op EditInTempFiles) RunQuery(query *queries.Query) (err error) {
+	var edits []*editInTempFile
+	var lock sync.Mutex
+
+	if err = op.GetStore().QueryTransacted(
+		query,
+		func(object *sku.Transacted) (err error) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			base, repoolBase := object.CloneTransacted()
+
+			edits = append(
+				edits,
+				&editInTempFile{base: base, repoolBase: repoolBase},
+			)
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer func() {
+		for _, edit := range edits {
+			edit.repoolBase()
+		}
+	}()
+
+	if len(edits) == 0 {
+		ui.Err().Print("nothing to edit")
+		return err
+	}
+
+	slices.SortFunc(edits, func(left, right *editInTempFile) int {
+		return strings.Compare(
+			left.base.GetObjectId().String(),
+			right.base.GetObjectId().String(),
+		)
+	})
+
+	format := op.makeFormat()
+	paths := make([]string, len(edits))
+
+	for i, edit := range edits {
+		if err = op.writeTempFile(format, edit); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		paths[i] = edit.path
+	}
+
+	if err = (OpenEditor{}).Run(op.Repo, paths...); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	// pick up objects committed while the editor was open
+	if err = op.Reset(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	format = op.makeFormat()
+	diffs := make([]object_diff.Diff, len(edits))
+
+	for i, edit := range edits {
+		if diffs[i], err = op.readTempFile(format, edit); err != nil {
+			err = errors.Wrapf(err, "path: %q", edit.path)
+			return err
+		}
+	}
+
+	if err = op.Lock(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var conflicts error
+
+	for i, edit := range edits {
+		if diffs[i].IsEmpty() {
+			continue
+		}
+
+		if err = op.commitDiff(edit, diffs[i]); err != nil {
+			if !errors.Is(err, object_diff.ErrConflict{}) {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			conflicts = errors.Join(
+				conflicts,
+				errors.Wrapf(err, "edit kept in %q", edit.path),
+			)
+
+			err = nil
+		}
+	}
+
+	if err = op.Unlock(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if conflicts != nil {
+		err = conflicts
+		return err
+	}
+
+	return err
+}
+
+func (op EditInTempFiles) makeFormat() object_metadata_fmt_triple_hyphen.Format {
+	return object_metadata_fmt_triple_hyphen.Factory{
+		EnvDir:    op.GetEnvRepo(),
+		BlobStore: op.GetEnvRepo().GetDefaultBlobStore(),
+	}.Make()
+}
+
+func (op EditInTempFiles) writeTempFile(
+	format object_metadata_fmt_triple_hyphen.Format,
+	edit *editInTempFile,
+) (err error) {
+	object := edit.base
+
+	formatter := format.MetadataOnly
+
+	if op.GetConfig().IsInlineType(object.GetType()) {
+		formatter = format.InlineBlob
+	}
+
+	var file *os.File
+
+	if file, err = op.GetEnvRepo().GetTempLocal().FileTempWithTemplate(
+		strings.ReplaceAll(object.GetObjectId().String(), "/", "-") +
+			"-*." +
+			op.GetConfig().GetFileExtensions().GetFileExtensionForGenre(object),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	edit.path = file.Name()
+
+	if _, err = formatter.FormatMetadata(
+		file,
+		object_metadata_fmt_triple_hyphen.FormatterContext{
+			EncoderContext: object,
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (op EditInTempFiles) readTempFile(
+	format object_metadata_fmt_triple_hyphen.Format,
+	edit *editInTempFile,
+) (diff object_diff.Diff, err error) {
+	edited, repoolEdited := edit.base.CloneTransacted()
+	defer repoolEdited()
+
+	var file *os.File
+
+	if file, err = os.Open(edit.path); err != nil {
+		err = errors.Wrap(err)
+		return diff, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	if _, err = format.ParseMetadata(file, edited); err != nil {
+		err = errors.Wrap(err)
+		return diff, err
+	}
+
+	diff = object_diff.Make(edit.base, edited)
+
+	return diff, err
+}
+
+// commitDiff applies diff to the latest version of the edited object, failing
+// with object_diff.ErrConflict if that version changed the same fields.
+func (op EditInTempFiles) commitDiff(
+	edit *editInTempFile,
+	diff object_diff.Diff,
+) (err error) {
+	current, repoolCurrent := sku.GetTransactedPool().GetWithRepool()
+	defer repoolCurrent()
+
+	if err = op.GetStore().ReadOneInto(
+		edit.base.GetObjectId(),
+		current,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if fields := diff.GetConflicts(
+		object_diff.Make(edit.base, current),
+	); len(fields) > 0 {
+		err = object_diff.ErrConflict{
+			ObjectId: edit.base.GetObjectId().String(),
+			Fields:   fields,
+		}
+
+		return err
+	}
+
+	if err = diff.Apply(current); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = op.GetStore().CreateOrUpdate(
+		current,
+		sku.CommitOptions{},
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,147 @@
+package user_ops
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/markdown_vault"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// ExportMarkdown writes the objects matching a query as markdown notes under
+// a directory, laid out by type, by tag, or flat. Each note's front matter
+// carries the object's id, description, tags, type, blob id, and tai, so that
+// ImportVault of the directory updates the same objects. Blobs of inline types
+// become the note's body, and other blobs are left out.
+type ExportMarkdown struct {
+	*local_working_copy.Repo
+
+	// Layout is one of markdown_vault.LayoutType, LayoutTag, or LayoutFlat.
+	Layout string
+}
+
+// ExportedNote is one entry of the export report.
+type ExportedNote struct {
+	ObjectId string `json:"object-id"`
+	Path     string `json:"path"`
+}
+
+func (op ExportMarkdown) Run(
+	query *queries.Query,
+	root string,
+) (report []ExportedNote, err error) {
+	var lock sync.Mutex
+
+	if err = op.GetStore().QueryTransacted(
+		query,
+		func(object *sku.Transacted) (err error) {
+			var exported ExportedNote
+
+			if exported, err = op.exportObject(object, root); err != nil {
+				err = errors.Wrapf(err, "object: %q", object.GetObjectId())
+				return err
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			report = append(report, exported)
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return report, err
+	}
+
+	slices.SortFunc(report, func(left, right ExportedNote) int {
+		return strings.Compare(left.Path, right.Path)
+	})
+
+	return report, err
+}
+
+func (op ExportMarkdown) exportObject(
+	object *sku.Transacted,
+	root string,
+) (exported ExportedNote, err error) {
+	metadata := object.GetMetadata()
+	blobId := metadata.GetBlobDigest()
+
+	note := markdown_vault.Note{
+		ObjectId: object.GetObjectId().String(),
+		Title:    metadata.GetDescription().String(),
+		Type:     strings.TrimPrefix(metadata.GetType().String(), "!"),
+		Tai:      object.GetTai().String(),
+	}
+
+	for tag := range metadata.GetTags().All() {
+		note.Tags = append(note.Tags, tag.String())
+	}
+
+	slices.Sort(note.Tags)
+
+	if !blobId.IsNull() {
+		note.BlobId = blobId.String()
+
+		if op.GetConfig().IsInlineType(object.GetType()) {
+			if note.Body, err = op.readBlob(blobId); err != nil {
+				err = errors.Wrap(err)
+				return exported, err
+			}
+		}
+	}
+
+	exported = ExportedNote{
+		ObjectId: note.ObjectId,
+		Path:     note.ExportPath(op.Layout, note.ObjectId),
+	}
+
+	path := filepath.Join(root, filepath.FromSlash(exported.Path))
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		err = errors.Wrap(err)
+		return exported, err
+	}
+
+	if err = os.WriteFile(path, []byte(note.Format()), 0o644); err != nil {
+		err = errors.Wrap(err)
+		return exported, err
+	}
+
+	return exported, err
+}
+
+func (op ExportMarkdown) readBlob(
+	blobId domain_interfaces.MarklId,
+) (body string, err error) {
+	var blobReader domain_interfaces.BlobReader
+
+	if blobReader, err = op.GetEnvRepo().GetDefaultBlobStore().MakeBlobReader(
+		blobId,
+	); err != nil {
+		err = errors.Wrap(err)
+		return body, err
+	}
+
+	defer errors.DeferredCloser(&err, blobReader)
+
+	var content []byte
+
+	if content, err = io.ReadAll(blobReader); err != nil {
+		err = errors.Wrap(err)
+		return body, err
+	}
+
+	body = string(content)
+
+	return body, err
+}
@@ -0,0 +1,234 @@
+package user_ops
+
+import (
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/markdown_vault"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const DefaultImportVaultBatchSize = 100
+
+// ImportVault imports the markdown notes of an Obsidian-style vault as
+// zettels. Each note's front matter becomes its description, tags, and type,
+// its body becomes its blob, and its wiki-links become object links to the
+// zettels the linked notes were imported as. Notes with a `dodder-object-id`,
+// like those written by ExportMarkdown, update that object instead. Every
+// BatchSize notes are committed as one inventory list.
+type ImportVault struct {
+	*local_working_copy.Repo
+
+	BatchSize int
+
+	// DefaultType is used for notes without a `type` in their front matter.
+	DefaultType string
+}
+
+// ImportedNote is one entry of the mapping report.
+type ImportedNote struct {
+	Path     string `json:"path"`
+	ObjectId string `json:"object-id"`
+
+	// UnresolvedLinks are the wiki-link targets that are not notes in the
+	// vault, left in the body as they are.
+	UnresolvedLinks []string `json:"unresolved-links,omitempty"`
+
+	// SkippedTags are front matter tags and types with nothing left after
+	// normalizing them.
+	SkippedTags []string `json:"skipped-tags,omitempty"`
+}
+
+func (op ImportVault) Run(root string) (report []ImportedNote, err error) {
+	var paths []string
+
+	if paths, err = markdown_vault.Walk(root); err != nil {
+		err = errors.Wrap(err)
+		return report, err
+	}
+
+	notes := make([]markdown_vault.Note, len(paths))
+
+	for i, path := range paths {
+		if notes[i], err = markdown_vault.ReadNote(root, path); err != nil {
+			err = errors.Wrap(err)
+			return report, err
+		}
+	}
+
+	if len(notes) == 0 {
+		return report, err
+	}
+
+	batchSize := op.BatchSize
+
+	if batchSize <= 0 {
+		batchSize = DefaultImportVaultBatchSize
+	}
+
+	if err = op.Lock(); err != nil {
+		err = errors.Wrap(err)
+		return report, err
+	}
+
+	// ids are assigned up front so that links to notes later in the vault
+	// resolve
+	var resolver markdown_vault.Resolver
+
+	report = make([]ImportedNote, len(notes))
+
+	for i, note := range notes {
+		objectId := note.ObjectId
+
+		if objectId == "" {
+			var zettelId *ids.ZettelId
+
+			if zettelId, err = op.GetStore().GetZettelIdIndex().CreateZettelId(); err != nil {
+				err = errors.Wrap(err)
+				return report, err
+			}
+
+			objectId = zettelId.String()
+		}
+
+		report[i] = ImportedNote{Path: note.Path, ObjectId: objectId}
+		resolver.Add(note, objectId)
+	}
+
+	for i, note := range notes {
+		if i > 0 && i%batchSize == 0 {
+			if err = op.Unlock(); err != nil {
+				err = errors.Wrap(err)
+				return report, err
+			}
+
+			if err = op.Lock(); err != nil {
+				err = errors.Wrap(err)
+				return report, err
+			}
+		}
+
+		if err = op.importNote(note, &resolver, &report[i]); err != nil {
+			err = errors.Wrapf(err, "path: %q", note.Path)
+			return report, err
+		}
+	}
+
+	if err = op.Unlock(); err != nil {
+		err = errors.Wrap(err)
+		return report, err
+	}
+
+	return report, err
+}
+
+func (op ImportVault) importNote(
+	note markdown_vault.Note,
+	resolver *markdown_vault.Resolver,
+	imported *ImportedNote,
+) (err error) {
+	object, repool := sku.GetTransactedPool().GetWithRepool()
+	defer repool()
+
+	if err = object.GetObjectIdMutable().Set(imported.ObjectId); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	metadata := object.GetMetadataMutable()
+
+	if err = metadata.GetDescriptionMutable().Set(note.Title); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	for _, value := range note.Tags {
+		tag, ok := markdown_vault.NormalizeTag(value)
+
+		if !ok {
+			imported.SkippedTags = append(imported.SkippedTags, value)
+			continue
+		}
+
+		if err = metadata.AddTagString(tag); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	tipe := op.DefaultType
+
+	if note.Type != "" {
+		if normalized, ok := markdown_vault.NormalizeTag(note.Type); ok {
+			tipe = normalized
+		} else {
+			imported.SkippedTags = append(imported.SkippedTags, note.Type)
+		}
+	}
+
+	if err = metadata.GetTypeMutable().SetType(tipe); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	body, unresolved := markdown_vault.ReplaceWikiLinks(
+		note.Body,
+		resolver.Resolve,
+	)
+
+	imported.UnresolvedLinks = unresolved
+
+	// notes exported without a body keep their blob
+	if body == "" && note.BlobId != "" {
+		if err = metadata.GetBlobDigestMutable().Set(note.BlobId); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	} else {
+		var blobId domain_interfaces.MarklId
+
+		if blobId, err = op.writeBlob(body); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		metadata.GetBlobDigestMutable().ResetWithMarklId(blobId)
+	}
+
+	if err = op.GetStore().CreateOrUpdate(
+		object,
+		sku.CommitOptions{},
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (op ImportVault) writeBlob(
+	body string,
+) (blobId domain_interfaces.MarklId, err error) {
+	var blobWriter domain_interfaces.BlobWriter
+
+	if blobWriter, err = op.GetEnvRepo().GetDefaultBlobStore().MakeBlobWriter(
+		nil,
+	); err != nil {
+		err = errors.Wrap(err)
+		return blobId, err
+	}
+
+	defer errors.DeferredCloser(&err, blobWriter)
+
+	if _, err = io.WriteString(blobWriter, body); err != nil {
+		err = errors.Wrap(err)
+		return blobId, err
+	}
+
+	blobId = blobWriter.GetMarklId()
+
+	return blobId, err
+}
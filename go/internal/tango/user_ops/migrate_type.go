@@ -0,0 +1,468 @@
+package user_ops
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/sku_lua"
+	"code.linenisgreat.com/dodder/go/internal/hotel/type_blobs"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/pool"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/lua"
+)
+
+const DefaultMigrateTypeBatchSize = 100
+
+// MigrateType re-encodes the blobs of every object of a type. Deprecated
+// builtin types use their type_blobs.BuiltinMigration. Other types use the
+// `migrate` function of the Lua `migration` script of their type object,
+// which is called with each object's V2 table and returns its new blob, or
+// nil to leave it as it is, and optionally a new type. Every BatchSize
+// migrated objects are committed as one inventory list.
+type MigrateType struct {
+	*local_working_copy.Repo
+
+	BatchSize int
+
+	// DryRun runs the migration without writing blobs or committing.
+	DryRun bool
+
+	// Progress is called after every batch with the number of objects
+	// processed so far.
+	Progress func(done, total int)
+}
+
+// MigratedObject is one entry of the migration report.
+type MigratedObject struct {
+	ObjectId string `json:"object-id"`
+	Type     string `json:"type"`
+}
+
+// objectMigrator sets the migrated blob and type of an object, and returns
+// false if the object is left as it is.
+type objectMigrator func(object *sku.Transacted) (changed bool, err error)
+
+func (op MigrateType) Run(
+	typeString string,
+) (report []MigratedObject, err error) {
+	var tipe ids.TypeStruct
+
+	if tipe, err = ids.MakeTypeStruct(typeString); err != nil {
+		err = errors.Wrap(err)
+		return report, err
+	}
+
+	var migrator objectMigrator
+	var repool interfaces.FuncRepool
+
+	if migrator, repool, err = op.makeMigrator(tipe); err != nil {
+		err = errors.Wrapf(err, "type: %q", tipe)
+		return report, err
+	}
+
+	defer repool()
+
+	var objects []*sku.Transacted
+	var objectsRepool interfaces.FuncRepool
+
+	if objects, objectsRepool, err = op.readObjectsOfType(tipe); err != nil {
+		err = errors.Wrap(err)
+		return report, err
+	}
+
+	defer objectsRepool()
+
+	batchSize := op.BatchSize
+
+	if batchSize <= 0 {
+		batchSize = DefaultMigrateTypeBatchSize
+	}
+
+	if !op.DryRun {
+		if err = op.Lock(); err != nil {
+			err = errors.Wrap(err)
+			return report, err
+		}
+	}
+
+	for i, object := range objects {
+		if i > 0 && i%batchSize == 0 {
+			op.progress(i, len(objects))
+
+			if !op.DryRun {
+				if err = op.Unlock(); err != nil {
+					err = errors.Wrap(err)
+					return report, err
+				}
+
+				if err = op.Lock(); err != nil {
+					err = errors.Wrap(err)
+					return report, err
+				}
+			}
+		}
+
+		var changed bool
+
+		if changed, err = migrator(object); err != nil {
+			err = errors.Wrapf(err, "object: %q", object.GetObjectId())
+			return report, err
+		}
+
+		if !changed {
+			continue
+		}
+
+		report = append(
+			report,
+			MigratedObject{
+				ObjectId: object.GetObjectId().String(),
+				Type:     object.GetType().String(),
+			},
+		)
+
+		if op.DryRun {
+			continue
+		}
+
+		if err = op.GetStore().Commit(
+			object,
+			sku.CommitOptions{
+				StoreOptions: sku.StoreOptions{
+					AddToInventoryList: true,
+					UpdateTai:          true,
+					Validate:           true,
+				},
+			},
+		); err != nil {
+			err = errors.Wrapf(err, "object: %q", object.GetObjectId())
+			return report, err
+		}
+	}
+
+	op.progress(len(objects), len(objects))
+
+	if !op.DryRun {
+		if err = op.Unlock(); err != nil {
+			err = errors.Wrap(err)
+			return report, err
+		}
+	}
+
+	return report, err
+}
+
+func (op MigrateType) progress(done, total int) {
+	if op.Progress != nil {
+		op.Progress(done, total)
+	}
+}
+
+func (op MigrateType) readObjectsOfType(
+	tipe ids.TypeStruct,
+) (objects []*sku.Transacted, repool interfaces.FuncRepool, err error) {
+	var repools pool.Repools
+	repool = repools.Repool
+
+	var query *queries.Query
+
+	if query, err = op.MakeQueryBuilder(
+		ids.MakeGenre(genres.All()...),
+		nil,
+	).BuildQueryGroup(); err != nil {
+		err = errors.Wrap(err)
+		return objects, repool, err
+	}
+
+	var lock sync.Mutex
+
+	if err = op.GetStore().QueryTransacted(
+		query,
+		func(object *sku.Transacted) (err error) {
+			if object.GetType().String() != tipe.String() {
+				return err
+			}
+
+			cloned, clonedRepool := object.CloneTransacted()
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			objects = append(objects, cloned)
+			repools = append(repools, clonedRepool)
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return objects, repool, err
+	}
+
+	slices.SortFunc(objects, func(left, right *sku.Transacted) int {
+		return strings.Compare(
+			left.GetObjectId().String(),
+			right.GetObjectId().String(),
+		)
+	})
+
+	return objects, repool, err
+}
+
+func (op MigrateType) makeMigrator(
+	tipe ids.TypeStruct,
+) (migrator objectMigrator, repool interfaces.FuncRepool, err error) {
+	repool = func() {}
+
+	if migration, ok := type_blobs.GetBuiltinMigration(tipe.String()); ok {
+		migrator = op.makeBuiltinMigrator(migration)
+		return migrator, repool, err
+	}
+
+	var objectId ids.ObjectId
+
+	if err = objectId.Set(tipe.String()); err != nil {
+		err = errors.Wrap(err)
+		return migrator, repool, err
+	}
+
+	var typeObject *sku.Transacted
+
+	if typeObject, err = op.GetStore().ReadOneObjectId(&objectId); err != nil {
+		err = errors.Wrap(err)
+		return migrator, repool, err
+	} else if typeObject == nil {
+		err = errors.BadRequestf("type object %q not found", tipe)
+		return migrator, repool, err
+	}
+
+	var typeBlob type_blobs.Blob
+
+	if typeBlob, repool, _, err = op.GetStore().GetTypedBlobStore().Type.ParseTypedBlob(
+		typeObject.GetType(),
+		typeObject.GetBlobDigest(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return migrator, repool, err
+	}
+
+	var script string
+
+	if withMigration, ok := typeBlob.(type_blobs.WithStringLuaMigration); ok {
+		script = withMigration.GetStringLuaMigration()
+	}
+
+	if script == "" {
+		err = errors.BadRequestf("type %q declares no migration", tipe)
+		return migrator, repool, err
+	}
+
+	var vmPool sku_lua.LuaVMPoolV2
+
+	if vmPool, err = op.GetStore().MakeLuaVMPoolV2(typeObject, script); err != nil {
+		err = errors.Wrap(err)
+		return migrator, repool, err
+	}
+
+	migrator = op.makeLuaMigrator(vmPool)
+
+	return migrator, repool, err
+}
+
+func (op MigrateType) makeBuiltinMigrator(
+	migration type_blobs.BuiltinMigration,
+) objectMigrator {
+	return func(object *sku.Transacted) (changed bool, err error) {
+		var digest domain_interfaces.MarklId
+
+		if digest, err = op.GetStore().GetTypedBlobStore().Type.MigrateBlob(
+			migration,
+			object.GetBlobDigest(),
+			!op.DryRun,
+		); err != nil {
+			err = errors.Wrap(err)
+			return changed, err
+		}
+
+		if _, err = setType(object, migration.To); err != nil {
+			err = errors.Wrap(err)
+			return changed, err
+		}
+
+		if !op.DryRun {
+			object.GetMetadataMutable().GetBlobDigestMutable().ResetWithMarklId(
+				digest,
+			)
+		}
+
+		changed = true
+
+		return changed, err
+	}
+}
+
+func (op MigrateType) makeLuaMigrator(
+	vmPool sku_lua.LuaVMPoolV2,
+) objectMigrator {
+	return func(object *sku.Transacted) (changed bool, err error) {
+		vm, vmRepool := vmPool.GetWithRepool()
+		defer vmRepool()
+
+		var table *lua.LTable
+
+		if table, err = vm.VM.GetTopTableOrError(); err != nil {
+			err = errors.Wrap(err)
+			return changed, err
+		}
+
+		function, ok := vm.VM.GetField(table, "migrate").(*lua.LFunction)
+
+		if !ok {
+			err = errors.BadRequestf("migration has no `migrate` function")
+			return changed, err
+		}
+
+		tableObject, tableObjectRepool := vm.TablePool.GetWithRepool()
+		defer tableObjectRepool()
+
+		sku_lua.ToLuaTableV2(object, vm.VM.LState, tableObject)
+
+		vm.VM.Push(function)
+		vm.VM.Push(tableObject.Transacted)
+
+		if err = vm.VM.PCall(1, 2, nil); err != nil {
+			err = errors.Wrap(err)
+			return changed, err
+		}
+
+		blob, tipe := vm.LState.Get(-2), vm.LState.Get(-1)
+		vm.Pop(2)
+
+		if tipe.Type() == lua.LTString {
+			if changed, err = setType(object, tipe.String()); err != nil {
+				err = errors.Wrap(err)
+				return changed, err
+			}
+		} else if tipe.Type() != lua.LTNil {
+			err = errors.BadRequestf(
+				"migrate returned a %s as the type",
+				tipe.Type(),
+			)
+			return changed, err
+		}
+
+		switch blob.Type() {
+		case lua.LTNil:
+			return changed, err
+
+		case lua.LTString:
+
+		default:
+			err = errors.BadRequestf(
+				"migrate returned a %s as the blob",
+				blob.Type(),
+			)
+			return changed, err
+		}
+
+		var blobChanged bool
+
+		if blobChanged, err = op.setBlob(object, blob.String()); err != nil {
+			err = errors.Wrap(err)
+			return changed, err
+		}
+
+		changed = changed || blobChanged
+
+		return changed, err
+	}
+}
+
+// setType sets the type of an object and drops its type lock if the type
+// changed, so that the commit locks the new type.
+func setType(object *sku.Transacted, tipe string) (changed bool, err error) {
+	metadata := object.GetMetadataMutable()
+	previous := metadata.GetType().String()
+
+	if err = metadata.GetTypeMutable().SetType(tipe); err != nil {
+		err = errors.Wrap(err)
+		return changed, err
+	}
+
+	if metadata.GetType().String() == previous {
+		return changed, err
+	}
+
+	changed = true
+	metadata.GetTypeLockMutable().GetValueMutable().Reset()
+
+	return changed, err
+}
+
+// setBlob sets the blob of an object to the migrated blob, writing it unless
+// this is a dry run, and returns whether it differs from the current blob.
+func (op MigrateType) setBlob(
+	object *sku.Transacted,
+	blob string,
+) (changed bool, err error) {
+	blobStore := op.GetEnvRepo().GetDefaultBlobStore()
+	currentBlobId := object.GetBlobDigest()
+
+	if !currentBlobId.IsNull() {
+		var blobReader domain_interfaces.BlobReader
+
+		if blobReader, err = blobStore.MakeBlobReader(currentBlobId); err != nil {
+			err = errors.Wrap(err)
+			return changed, err
+		}
+
+		defer errors.DeferredCloser(&err, blobReader)
+
+		var current []byte
+
+		if current, err = io.ReadAll(blobReader); err != nil {
+			err = errors.Wrap(err)
+			return changed, err
+		}
+
+		if string(current) == blob {
+			return changed, err
+		}
+	} else if blob == "" {
+		return changed, err
+	}
+
+	changed = true
+
+	if op.DryRun {
+		return changed, err
+	}
+
+	var blobWriter domain_interfaces.BlobWriter
+
+	if blobWriter, err = blobStore.MakeBlobWriter(nil); err != nil {
+		err = errors.Wrap(err)
+		return changed, err
+	}
+
+	defer errors.DeferredCloser(&err, blobWriter)
+
+	if _, err = io.WriteString(blobWriter, blob); err != nil {
+		err = errors.Wrap(err)
+		return changed, err
+	}
+
+	object.GetMetadataMutable().GetBlobDigestMutable().ResetWithMarklId(
+		blobWriter.GetMarklId(),
+	)
+
+	return changed, err
+}
@@ -16,15 +16,18 @@ func (c OpenEditor) Run(
 ) (err error) {
 	var e editor.Editor
 
-	if e, err = editor.MakeEditorWithVimOptions(
+	if e, err = editor.MakeEditorWithUtilityAndVimOptions(
 		u.PrinterHeader(),
+		u.GetEnvWorkspace().GetEditor(),
 		c.VimOptions,
 	); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
 
-	if err = e.Run(args); err != nil {
+	if err = u.RunWithoutSharedAccess(
+		func() error { return e.Run(args) },
+	); err != nil {
 		err = errors.Wrap(err)
 		return err
 	}
@@ -0,0 +1,117 @@
+package user_ops
+
+import (
+	"sync"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/checkout_options"
+	"code.linenisgreat.com/dodder/go/internal/bravo/checked_out_state"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// RefreshWorkspace re-evaluates the query defining a sparse workspace,
+// checking out newly matching objects and updating unchanged checkouts of
+// objects that changed since. With Evict, unchanged checkouts of objects
+// that no longer match are removed.
+type RefreshWorkspace struct {
+	*local_working_copy.Repo
+	checkout_options.Options
+	Evict bool
+}
+
+type RefreshWorkspaceResults struct {
+	CheckedOut int
+	Evicted    int
+
+	// checkouts that no longer match but have local changes, and so were
+	// kept
+	Kept []*sku.CheckedOut
+}
+
+// Run checks out the objects matching sparseQuery and, with Evict, removes
+// the checkouts matching checkedOutQuery that sparseQuery did not match.
+// checkedOutQuery must ignore the workspace's default query, as otherwise it
+// would only match what sparseQuery does.
+func (op RefreshWorkspace) Run(
+	sparseQuery *queries.Query,
+	checkedOutQuery *queries.Query,
+) (results RefreshWorkspaceResults, err error) {
+	var lock sync.Mutex
+	matching := make(map[string]struct{})
+
+	if err = op.GetStore().CheckoutQuery(
+		op.Options,
+		sparseQuery,
+		func(checkedOut sku.SkuType) (err error) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			matching[checkedOut.GetSku().GetObjectId().String()] = struct{}{}
+			results.CheckedOut++
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return results, err
+	}
+
+	if !op.Evict {
+		return results, err
+	}
+
+	var toEvict []*sku.CheckedOut
+
+	if err = op.GetStore().QuerySkuType(
+		checkedOutQuery,
+		func(checkedOut sku.SkuType) (err error) {
+			if checkedOut.GetState() != checked_out_state.CheckedOut {
+				return err
+			}
+
+			if _, ok := matching[checkedOut.GetSku().GetObjectId().String()]; ok {
+				return err
+			}
+
+			cloned, _ := checkedOut.Clone()
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			if sku.InternalAndExternalEqualsWithoutTai(checkedOut) || op.Force {
+				toEvict = append(toEvict, cloned)
+			} else {
+				results.Kept = append(results.Kept, cloned)
+			}
+
+			return err
+		},
+	); err != nil {
+		err = errors.Wrap(err)
+		return results, err
+	}
+
+	if len(toEvict) == 0 {
+		return results, err
+	}
+
+	if err = op.Lock(); err != nil {
+		err = errors.Wrap(err)
+		return results, err
+	}
+
+	defer errors.Deferred(&err, op.Unlock)
+
+	for _, checkedOut := range toEvict {
+		if err = op.GetStore().DeleteCheckedOut(checkedOut); err != nil {
+			err = errors.Wrap(err)
+			return results, err
+		}
+
+		results.Evicted++
+	}
+
+	return results, err
+}
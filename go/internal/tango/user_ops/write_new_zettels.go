@@ -73,7 +73,8 @@ func (c WriteNewZettels) runOneAlreadyLocked(
 	if err = c.GetStore().CreateOrUpdateDefaultProto(
 		object,
 		sku.StoreOptions{
-			ApplyProto: true,
+			ApplyProto:    true,
+			ApplyTemplate: true,
 		},
 	); err != nil {
 		err = errors.Wrap(err)
@@ -1,6 +1,8 @@
 package command_components_dodder
 
 import (
+	"time"
+
 	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
 	"code.linenisgreat.com/dodder/go/internal/delta/objects"
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/env_local"
@@ -13,6 +15,10 @@ import (
 	"code.linenisgreat.com/dodder/go/lib/charlie/values"
 )
 
+// CompletionBudget bounds how long completing object ids may take, so that
+// the shell stays responsive on large repos.
+const CompletionBudget = 50 * time.Millisecond
+
 type Complete struct {
 	ObjectMetadata
 	Query
@@ -30,11 +36,7 @@ func (cmd Complete) GetFlagValueMetadataTags(
 		) {
 			local := LocalWorkingCopy{}.MakeLocalWorkingCopy(req)
 
-			cmd.CompleteObjects(
-				req,
-				local,
-				pkg_query.BuilderOptionDefaultGenres(genres.Tag),
-			)
+			cmd.completeObjectIds(local, commandLine, genres.Tag)
 		},
 	}
 }
@@ -51,11 +53,7 @@ func (cmd Complete) GetFlagValueStringTags(
 		) {
 			local := LocalWorkingCopy{}.MakeLocalWorkingCopy(req)
 
-			cmd.CompleteObjects(
-				req,
-				local,
-				pkg_query.BuilderOptionDefaultGenres(genres.Tag),
-			)
+			cmd.completeObjectIds(local, commandLine, genres.Tag)
 		},
 	}
 }
@@ -72,11 +70,7 @@ func (cmd Complete) GetFlagValueMetadataType(
 		) {
 			local := LocalWorkingCopy{}.MakeLocalWorkingCopy(req)
 
-			cmd.CompleteObjects(
-				req,
-				local,
-				pkg_query.BuilderOptionDefaultGenres(genres.Type),
-			)
+			cmd.completeObjectIds(local, commandLine, genres.Type)
 		},
 	}
 }
@@ -108,13 +102,11 @@ func (cmd Complete) SetFlagsProto(
 func (cmd Complete) CompleteObjectsIncludingWorkspace(
 	req command.Request,
 	local *local_working_copy.Repo,
+	commandLine command.CommandLineInput,
 	queryBuilderOptions pkg_query.BuilderOption,
 	args ...string,
 ) {
-	sku_fmt.OutputCliCompletions(
-		local.GetEnvRepo().Env,
-		local.GetAbbr().GetSeenIds(),
-	)
+	cmd.completeObjectIds(local, commandLine)
 	// printerCompletions := sku_fmt.MakePrinterComplete(local)
 
 	// query := cmd.MakeQueryIncludingWorkspace(
@@ -135,13 +127,11 @@ func (cmd Complete) CompleteObjectsIncludingWorkspace(
 func (cmd Complete) CompleteObjects(
 	req command.Request,
 	local *local_working_copy.Repo,
+	commandLine command.CommandLineInput,
 	queryBuilderOptions pkg_query.BuilderOption,
 	args ...string,
 ) {
-	sku_fmt.OutputCliCompletions(
-		local.GetEnvRepo().Env,
-		local.GetAbbr().GetSeenIds(),
-	)
+	cmd.completeObjectIds(local, commandLine)
 	// printerCompletions := sku_fmt.MakePrinterComplete(local)
 
 	// query := cmd.MakeQuery(
@@ -158,3 +148,22 @@ func (cmd Complete) CompleteObjects(
 	// 	local.Cancel(err)
 	// }
 }
+
+// completeObjectIds prints the seen object ids of the abbreviation index that
+// start with the word in progress, of any genre when none are given. The
+// probe index only has digests of object ids, so it cannot list them.
+func (cmd Complete) completeObjectIds(
+	local *local_working_copy.Repo,
+	commandLine command.CommandLineInput,
+	genresToComplete ...genres.Genre,
+) {
+	sku_fmt.OutputCliCompletions(
+		local.GetEnvRepo().Env,
+		local.GetAbbr().GetSeenIds(),
+		sku_fmt.CliCompletionOptions{
+			Prefix: commandLine.InProgress,
+			Genres: genresToComplete,
+			Budget: CompletionBudget,
+		},
+	)
+}
@@ -28,6 +28,9 @@ func (cmd *Env) MakeEnvWithOptions(
 		config.Debug,
 	)
 
+	layout.SetReadOnly(config.IsReadOnly())
+	layout.SetDryRun(config.IsDryRun())
+
 	return env_local.Make(
 		env_ui.Make(
 			req,
@@ -51,6 +54,9 @@ func (cmd *Env) MakeEnvWithXDGLayoutAndOptions(
 		xdgDotenvPath,
 	)
 
+	dir.SetReadOnly(config.IsReadOnly())
+	dir.SetDryRun(config.IsDryRun())
+
 	ui := env_ui.Make(
 		req,
 		config,
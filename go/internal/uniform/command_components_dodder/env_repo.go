@@ -24,6 +24,9 @@ func (cmd EnvRepo) MakeEnvRepo(
 		config.Debug,
 	)
 
+	dir.SetReadOnly(config.IsReadOnly())
+	dir.SetDryRun(config.IsDryRun())
+
 	envUI := env_ui.Make(
 		req,
 		config,
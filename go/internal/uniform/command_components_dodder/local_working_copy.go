@@ -62,10 +62,11 @@ func (cmd LocalWorkingCopy) MakeLocalWorkingCopyFromConfigAndXDGDotenvPath(
 
 func (cmd LocalWorkingCopy) MakeLocalWorkingCopyFromEnvLocal(
 	envLocal env_local.Env,
+	options local_working_copy.Options,
 ) (local *local_working_copy.Repo) {
 	local = local_working_copy.Make(
 		envLocal,
-		local_working_copy.OptionsEmpty,
+		options,
 	)
 
 	return local
@@ -11,6 +11,8 @@ import (
 
 type Query struct {
 	sku.ExternalQueryOptions
+
+	Jobs int
 }
 
 var _ interfaces.CommandComponentWriter = (*Query)(nil)
@@ -20,6 +22,13 @@ func (cmd *Query) SetFlagDefinitions(flagSet interfaces.CLIFlagDefinitions) {
 	flagSet.Var(&cmd.RepoId, "kasten", "none or Browser")
 	flagSet.BoolVar(&cmd.ExcludeUntracked, "exclude-untracked", false, "")
 	flagSet.BoolVar(&cmd.ExcludeRecognized, "exclude-recognized", false, "")
+
+	flagSet.IntVar(
+		&cmd.Jobs,
+		"jobs",
+		0,
+		"read and match at most this many stream index pages at once (0 for all of them)",
+	)
 }
 
 func (cmd Query) MakeQueryIncludingWorkspace(
@@ -57,5 +66,7 @@ func (cmd Query) MakeQuery(
 		req.Cancel(err)
 	}
 
+	query.Jobs = cmd.Jobs
+
 	return query
 }
@@ -230,7 +230,10 @@ func (cmd *Remote) MakeRemoteHTTPFromXDGDotenvPath(
 		options,
 	)
 
-	remote := cmd.MakeLocalWorkingCopyFromEnvLocal(envLocal)
+	remote := cmd.MakeLocalWorkingCopyFromEnvLocal(
+		envLocal,
+		local_working_copy.OptionsEmpty,
+	)
 
 	server := &remote_http.Server{
 		EnvLocal: envLocal,
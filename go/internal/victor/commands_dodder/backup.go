@@ -0,0 +1,100 @@
+package commands_dodder
+
+import (
+	"os"
+	"path/filepath"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/internal/tango/user_ops"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	"code.linenisgreat.com/dodder/go/lib/echo/snapshot_tar"
+)
+
+func init() {
+	utility.AddCmd("backup", &Backup{})
+}
+
+type Backup struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+var _ interfaces.CommandComponentWriter = (*Backup)(nil)
+
+func (cmd Backup) GetDescription() command.Description {
+	return command.Description{
+		Short: "write a verified snapshot of the repo to a tarball",
+		Long: "Writes the repo's inventory lists, indexes, blob stores, and " +
+			"configs to a tarball that ends with a manifest of checksums, " +
+			"holding the repo lock while reading them. The tarball only " +
+			"appears at the given path once complete, and an existing file " +
+			"is never overwritten. Use restore-backup to restore it.",
+	}
+}
+
+func (cmd Backup) Run(req command.Request) {
+	backupPath := req.PopArg("backup path")
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	manifest, err := cmd.writeBackup(localWorkingCopy, backupPath)
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	ui.Out().Printf(
+		"backed up %d files (%s) to %s",
+		len(manifest.Entries),
+		ui.GetHumanBytesStringOrError(manifest.GetTotalSize()),
+		backupPath,
+	)
+}
+
+// writeBackup writes the backup to a temp file next to backupPath and links
+// it into place once synced, which fails rather than overwrite an existing
+// file.
+func (cmd Backup) writeBackup(
+	localWorkingCopy *local_working_copy.Repo,
+	backupPath string,
+) (manifest snapshot_tar.Manifest, err error) {
+	var tempFile *os.File
+
+	if tempFile, err = os.CreateTemp(
+		filepath.Dir(backupPath),
+		".backup-*.tmp",
+	); err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	if manifest, err = (user_ops.Backup{Repo: localWorkingCopy}).Run(
+		tempFile,
+	); err != nil {
+		return manifest, err
+	}
+
+	if err = tempFile.Sync(); err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	if err = os.Link(tempFile.Name(), backupPath); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			err = errors.BadRequestf("%s already exists", backupPath)
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return manifest, err
+	}
+
+	return manifest, err
+}
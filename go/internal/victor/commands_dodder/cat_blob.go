@@ -0,0 +1,115 @@
+package commands_dodder
+
+import (
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd("cat-blob", &CatBlob{})
+}
+
+type CatBlob struct {
+	command_components_dodder.LocalWorkingCopy
+
+	Offset int
+	Length int
+}
+
+var _ interfaces.CommandComponentWriter = (*CatBlob)(nil)
+
+func (cmd CatBlob) GetDescription() command.Description {
+	return command.Description{
+		Short: "write a blob's raw content, or a range of it, to stdout",
+	}
+}
+
+func (cmd *CatBlob) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.IntVar(
+		&cmd.Offset,
+		"offset",
+		0,
+		"skip this many bytes of the blob",
+	)
+
+	flagSet.IntVar(
+		&cmd.Length,
+		"length",
+		-1,
+		"write at most this many bytes (negative for the rest of the blob)",
+	)
+}
+
+func (cmd CatBlob) Run(req command.Request) {
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	args := req.PopArgs()
+
+	if len(args) != 1 {
+		errors.ContextCancelWithErrorf(
+			req,
+			"cat-blob expects exactly one blob id or object id, got %d",
+			len(args),
+		)
+	}
+
+	var blobId domain_interfaces.MarklId
+
+	{
+		var id markl.Id
+
+		if err := id.Set(args[0]); err == nil {
+			blobId = id
+		} else {
+			var object *sku.Transacted
+
+			if object, err = localWorkingCopy.GetObjectFromObjectId(
+				args[0],
+			); err != nil {
+				localWorkingCopy.Cancel(err)
+			}
+
+			blobId = object.GetBlobDigest()
+		}
+	}
+
+	envRepo := localWorkingCopy.GetEnvRepo()
+	blobStore, remaining := envRepo.GetDefaultBlobStoreAndRemaining()
+
+	if !blobStore.HasBlob(blobId) {
+		for _, candidate := range remaining {
+			if candidate.HasBlob(blobId) {
+				blobStore = candidate
+				break
+			}
+		}
+	}
+
+	reader, err := blob_stores.MakeBlobRangeReader(
+		blobStore.GetBlobStore(),
+		blobId,
+		int64(cmd.Offset),
+		int64(cmd.Length),
+	)
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+	}
+
+	defer errors.ContextMustClose(localWorkingCopy, reader)
+
+	if _, err = io.Copy(localWorkingCopy.GetUIFile(), reader); err != nil {
+		localWorkingCopy.Cancel(err)
+	}
+}
@@ -37,6 +37,7 @@ type Checkin struct {
 
 	CheckoutBlobAndRun string
 	OpenBlob           bool
+	Force              bool
 }
 
 var _ interfaces.CommandComponentWriter = (*Checkin)(nil)
@@ -53,6 +54,13 @@ func (cmd *Checkin) SetFlagDefinitions(
 		"do not change the blob",
 	)
 
+	flagSet.BoolVar(
+		&cmd.Force,
+		"force",
+		false,
+		"commit blobs that do not match the schema of their type",
+	)
+
 	flagSet.StringVar(
 		&cmd.CheckoutBlobAndRun,
 		"each-blob",
@@ -134,6 +142,7 @@ func (cmd Checkin) Run(dep command.Request) {
 		Proto:              cmd.Proto,
 		CheckoutBlobAndRun: cmd.CheckoutBlobAndRun,
 		OpenBlob:           cmd.OpenBlob,
+		Force:              cmd.Force,
 	}
 
 	// TODO add auto dot operator
@@ -0,0 +1,49 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd("checkpoint-inventory-lists", &CheckpointInventoryLists{})
+}
+
+type CheckpointInventoryLists struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+func (cmd CheckpointInventoryLists) GetDescription() command.Description {
+	return command.Description{
+		Short: "snapshot the latest object versions for reindex -from-checkpoint",
+	}
+}
+
+func (cmd CheckpointInventoryLists) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	localWorkingCopy.Must(errors.MakeFuncContextFromFuncErr(localWorkingCopy.Lock))
+
+	checkpoint, err := localWorkingCopy.GetStore().GetInventoryListStore().WriteCheckpoint()
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	localWorkingCopy.Must(errors.MakeFuncContextFromFuncErr(localWorkingCopy.Unlock))
+
+	if checkpoint.ListCount == 0 {
+		ui.Out().Print("no inventory lists to checkpoint")
+		return
+	}
+
+	ui.Out().Printf(
+		"checkpoint at inventory list %d: %s",
+		checkpoint.ListCount,
+		checkpoint.BlobId,
+	)
+}
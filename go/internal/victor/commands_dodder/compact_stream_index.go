@@ -0,0 +1,117 @@
+package commands_dodder
+
+import (
+	"strconv"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd("compact-stream-index", &CompactStreamIndex{})
+}
+
+type CompactStreamIndex struct {
+	command_components_dodder.LocalWorkingCopy
+
+	Retention    time.Duration
+	RetentionSet bool
+	Threshold    float64
+}
+
+var _ interfaces.CommandComponentWriter = (*CompactStreamIndex)(nil)
+
+func (cmd CompactStreamIndex) GetDescription() command.Description {
+	return command.Description{
+		Short: "drop superseded object versions from the index",
+		Long: "Rewrites the stream index without the object versions that " +
+			"were superseded before the retention horizon. The latest version " +
+			"of every object is kept. The retention is required, since " +
+			"without one every version is kept. Dropped versions are no longer found by " +
+			"history queries and reverts until the next reindex, which reads " +
+			"them back from the inventory lists. Flushes also compact once the " +
+			"pages they rewrite are past the `stream-index.compaction-threshold` " +
+			"of the repo config.",
+	}
+}
+
+func (cmd *CompactStreamIndex) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.Func(
+		"retention",
+		"keep superseded versions newer than this Go duration, like 720h (default: `stream-index.retention` of the repo config, required if that is unset)",
+		func(value string) (err error) {
+			if cmd.Retention, err = time.ParseDuration(value); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			cmd.RetentionSet = true
+
+			return err
+		},
+	)
+
+	flagSet.Func(
+		"threshold",
+		"only compact if at least this ratio of the index is garbage, like 0.25 (default: any garbage)",
+		func(value string) (err error) {
+			if cmd.Threshold, err = strconv.ParseFloat(value, 64); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			return err
+		},
+	)
+}
+
+func (cmd CompactStreamIndex) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	options, err := localWorkingCopy.GetStore().GetStreamIndexCompactionOptions()
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	if cmd.RetentionSet {
+		options.Retention = cmd.Retention
+		options.RetentionSet = true
+	}
+
+	if !options.RetentionSet {
+		errors.ContextCancelWithBadRequestf(
+			localWorkingCopy,
+			"no retention: pass -retention or set stream-index.retention in the repo config",
+		)
+
+		return
+	}
+
+	stats, compacted := localWorkingCopy.CompactStreamIndex(
+		options,
+		cmd.Threshold,
+	)
+
+	switch {
+	case compacted:
+		ui.Out().Printf(
+			"dropped %d of %d entries",
+			stats.Garbage,
+			stats.Entries,
+		)
+
+	default:
+		ui.Out().Printf("not compacted: %s", stats)
+	}
+}
@@ -19,6 +19,8 @@ func init() {
 	utility.AddCmd(
 		"complete",
 		&Complete{})
+
+	utility.AddCmd(command.CompleteCmdName, &Complete{})
 }
 
 type Complete struct {
@@ -113,6 +115,10 @@ func (cmd Complete) completeSubcommands(
 	utility command.Utility,
 ) {
 	for name, subcmd := range utility.AllCmds() {
+		if command.IsHiddenCmdName(name) {
+			continue
+		}
+
 		cmd.completeSubcommand(envLocal, name, subcmd)
 	}
 }
@@ -0,0 +1,52 @@
+package commands_dodder
+
+import (
+	"fmt"
+	"os"
+
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/env_local"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+)
+
+func init() {
+	utility.AddCmd("completion", &Completion{})
+}
+
+type Completion struct{}
+
+var _ command.Completer = Completion{}
+
+func (cmd Completion) GetDescription() command.Description {
+	return command.Description{
+		Short: "print a shell completion script",
+		Long: "Prints the completion script for bash, zsh, or fish. The script " +
+			"completes commands and flags, and object ids, tags, and types " +
+			"from the abbreviation index, by calling the hidden `__complete` " +
+			"command, which gives up on ids after a few milliseconds on large " +
+			"repos. Load it with `source <(dodder completion bash)`, or write " +
+			"it to the shell's completion directory.",
+	}
+}
+
+func (cmd Completion) Complete(
+	req command.Request,
+	envLocal env_local.Env,
+	commandLine command.CommandLineInput,
+) {
+	for _, shell := range command.CompletionShells {
+		envLocal.GetUI().Print(shell)
+	}
+}
+
+func (cmd Completion) Run(req command.Request) {
+	shell := req.PopArg(fmt.Sprintf("shell, one of %q", command.CompletionShells))
+	req.AssertNoMoreArgs()
+
+	if err := command.WriteCompletionScript(
+		os.Stdout,
+		shell,
+		req.Utility.GetName(),
+	); err != nil {
+		req.Cancel(err)
+	}
+}
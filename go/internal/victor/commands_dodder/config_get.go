@@ -0,0 +1,80 @@
+package commands_dodder
+
+import (
+	"slices"
+
+	"code.linenisgreat.com/dodder/go/internal/delta/repo_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+)
+
+func init() {
+	utility.AddCmd("config-get", &ConfigGet{})
+}
+
+type ConfigGet struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+func (cmd ConfigGet) GetDescription() command.Description {
+	return command.Description{
+		Short: "print values of the konfig",
+		Long: "Prints the value of a key of the konfig, the repo's config " +
+			"object, named by the dotted path of its toml tables, like " +
+			"`defaults.type`. Without a key, prints every key of the " +
+			"konfig's version with its value. With -verbose, also prints " +
+			"the kind of value each key takes. Lists are comma-separated.",
+	}
+}
+
+func (cmd ConfigGet) Run(req command.Request) {
+	repo := cmd.MakeLocalWorkingCopy(req)
+	keys := req.PopArgs()
+
+	typedBlob, err := repo.ReadConfigBlob()
+	if err != nil {
+		repo.Cancel(err)
+		return
+	}
+
+	schemaKeys := repo_configs.GetSchemaKeys(typedBlob.Blob)
+
+	if len(keys) == 0 {
+		for _, schemaKey := range schemaKeys {
+			keys = append(keys, schemaKey.Key)
+		}
+	}
+
+	verbose := req.Utility.GetConfig().GetVerbose()
+
+	for _, key := range keys {
+		value, err := repo_configs.GetKey(typedBlob.Blob, key)
+		if err != nil {
+			repo.Cancel(err)
+			return
+		}
+
+		switch {
+		case verbose:
+			index := slices.IndexFunc(
+				schemaKeys,
+				func(schemaKey repo_configs.SchemaKey) bool {
+					return schemaKey.Key == key
+				},
+			)
+
+			repo.GetUI().Printf(
+				"%s = %s (%s)",
+				key,
+				value,
+				schemaKeys[index].Type,
+			)
+
+		case len(keys) == 1:
+			repo.GetUI().Print(value)
+
+		default:
+			repo.GetUI().Printf("%s = %s", key, value)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/delta/repo_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd("config-set", &ConfigSet{})
+}
+
+type ConfigSet struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+func (cmd ConfigSet) GetDescription() command.Description {
+	return command.Description{
+		Short: "set a value of the konfig",
+		Long: "Sets a key of the konfig, named as in `config-get`, to a " +
+			"value parsed as the kind of value the key takes, with lists " +
+			"comma-separated. The konfig is validated like " +
+			"`config-validate` before it is committed, so an invalid " +
+			"value leaves it unchanged.",
+	}
+}
+
+func (cmd ConfigSet) Run(req command.Request) {
+	key := req.PopArg("config key")
+	value := req.PopArg("config value")
+	req.AssertNoMoreArgs()
+
+	repo := cmd.MakeLocalWorkingCopy(req)
+
+	updateConfigBlob(
+		repo,
+		func(config repo_configs.ConfigOverlay) (repo_configs.ConfigOverlay, error) {
+			return repo_configs.SetKey(config, key, value)
+		},
+	)
+}
+
+// updateConfigBlob commits the konfig returned by update, unless it fails
+// validation.
+func updateConfigBlob(
+	repo *local_working_copy.Repo,
+	update func(repo_configs.ConfigOverlay) (repo_configs.ConfigOverlay, error),
+) {
+	typedBlob, err := repo.ReadConfigBlob()
+	if err != nil {
+		repo.Cancel(err)
+		return
+	}
+
+	if typedBlob.Blob, err = update(typedBlob.Blob); err != nil {
+		repo.Cancel(err)
+		return
+	}
+
+	if err = repo_configs.Validate(typedBlob.Blob); err != nil {
+		repo.Cancel(errors.BadRequest(err))
+		return
+	}
+
+	repo.Must(errors.MakeFuncContextFromFuncErr(repo.Lock))
+
+	if err = repo.WriteConfigBlob(typedBlob); err != nil {
+		repo.Cancel(err)
+		return
+	}
+
+	repo.Must(errors.MakeFuncContextFromFuncErr(repo.Unlock))
+}
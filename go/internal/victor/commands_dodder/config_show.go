@@ -0,0 +1,71 @@
+package commands_dodder
+
+import (
+	"slices"
+
+	"code.linenisgreat.com/dodder/go/internal/delta/repo_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd("config-show", &ConfigShow{})
+}
+
+type ConfigShow struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+func (cmd ConfigShow) GetDescription() command.Description {
+	return command.Description{
+		Short: "print the layered config and where its values came from",
+		Long: "Prints the effective value of each layered config key, or " +
+			"of the given keys, as resolved from the builtin values, the " +
+			"repo's konfig, the workspace, the environment, and the " +
+			"-config flags, in that order. With -verbose, every layer " +
+			"setting a key is printed below it with its source.",
+	}
+}
+
+func (cmd ConfigShow) Run(req command.Request) {
+	repo := cmd.MakeLocalWorkingCopy(req)
+	keys := req.PopArgs()
+
+	if len(keys) == 0 {
+		keys = repo_configs.LayeredKeys
+	}
+
+	layered := repo.GetEnvWorkspace().GetLayeredConfig()
+	verbose := req.Utility.GetConfig().GetVerbose()
+
+	for _, key := range keys {
+		if !slices.Contains(repo_configs.LayeredKeys, key) {
+			errors.ContextCancelWithBadRequestf(
+				repo,
+				"unsupported config key: %q. Expected one of %q",
+				key,
+				repo_configs.LayeredKeys,
+			)
+		}
+
+		repo.GetUI().Printf("%s = %s", key, layered.GetString(key))
+
+		if !verbose {
+			continue
+		}
+
+		values := layered.GetAll(key)
+
+		for i := len(values) - 1; i >= 0; i-- {
+			value := values[i]
+
+			repo.GetUI().Printf(
+				"  %-9s %s (%s)",
+				value.Layer,
+				value.Value,
+				value.Source,
+			)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/delta/repo_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+)
+
+func init() {
+	utility.AddCmd("config-unset", &ConfigUnset{})
+}
+
+type ConfigUnset struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+func (cmd ConfigUnset) GetDescription() command.Description {
+	return command.Description{
+		Short: "unset a value of the konfig",
+		Long: "Resets a key of the konfig, named as in `config-get`, to " +
+			"its zero value, which the repo treats as its default.",
+	}
+}
+
+func (cmd ConfigUnset) Run(req command.Request) {
+	key := req.PopArg("config key")
+	req.AssertNoMoreArgs()
+
+	repo := cmd.MakeLocalWorkingCopy(req)
+
+	updateConfigBlob(
+		repo,
+		func(config repo_configs.ConfigOverlay) (repo_configs.ConfigOverlay, error) {
+			return repo_configs.UnsetKey(config, key)
+		},
+	)
+}
@@ -0,0 +1,141 @@
+package commands_dodder
+
+import (
+	"fmt"
+	"os"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/charlie/tap_diagnostics"
+	"code.linenisgreat.com/dodder/go/internal/delta/blob_store_configs"
+	"code.linenisgreat.com/dodder/go/internal/delta/repo_configs"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
+)
+
+func init() {
+	utility.AddCmd("config-validate", &ConfigValidate{})
+}
+
+type ConfigValidate struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+func (cmd ConfigValidate) GetDescription() command.Description {
+	return command.Description{
+		Short: "check the blob store configs, type objects, and konfig",
+		Long: "Checks the values of every blob store config, the blob of " +
+			"every type object, and the konfig that are otherwise only " +
+			"parsed when they are used, like durations, hash types, and " +
+			"the konfig's default type and blob store, and reports them " +
+			"as TAP. Configs that fail to decode stop every command, " +
+			"including this one, before it runs.",
+	}
+}
+
+func (cmd ConfigValidate) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	repo := cmd.MakeLocalWorkingCopy(req)
+
+	tw := tap.NewWriter(os.Stdout)
+
+	for _, blobStore := range repo.GetEnvRepo().GetBlobStoresSorted() {
+		description := fmt.Sprintf("blob_store %s", blobStore.Path.GetId())
+
+		if err := blob_store_configs.Validate(
+			blobStore.Config.Blob,
+		); err != nil {
+			tw.NotOk(description, tap_diagnostics.FromError(err))
+			continue
+		}
+
+		tw.Ok(description)
+	}
+
+	for typeObject := range repo.GetConfig().Types.All() {
+		description := fmt.Sprintf("type %s", typeObject.GetObjectId())
+
+		_, repool, _, err := repo.GetStore().GetTypedBlobStore().Type.ParseTypedBlob(
+			typeObject.GetType(),
+			typeObject.GetBlobDigest(),
+		)
+		if err != nil {
+			tw.NotOk(description, tap_diagnostics.FromError(err))
+			continue
+		}
+
+		repool()
+		tw.Ok(description)
+	}
+
+	cmd.validateKonfig(repo, tw)
+
+	tw.Plan()
+}
+
+func (cmd ConfigValidate) validateKonfig(
+	repo *local_working_copy.Repo,
+	tw *tap.Writer,
+) {
+	typedBlob, err := repo.ReadConfigBlob()
+	if err != nil {
+		tw.NotOk("konfig", tap_diagnostics.FromError(err))
+		return
+	}
+
+	if err = repo_configs.Validate(typedBlob.Blob); err != nil {
+		tw.NotOk("konfig", tap_diagnostics.FromError(err))
+	} else {
+		tw.Ok("konfig")
+	}
+
+	if defaultType := typedBlob.Blob.GetDefaults().GetDefaultType(); !defaultType.IsEmpty() &&
+		!ids.IsBuiltin(defaultType) {
+		description := fmt.Sprintf("konfig defaults.type %s", defaultType)
+
+		if _, err = repo.GetStore().ReadTransactedFromObjectId(
+			defaultType,
+		); err != nil {
+			tw.NotOk(description, tap_diagnostics.FromError(err))
+		} else {
+			tw.Ok(description)
+		}
+	}
+
+	if config, ok := typedBlob.Blob.(repo_configs.ConfigOverlay2); ok &&
+		!config.GetDefaultBlobStoreId().IsEmpty() {
+		blobStoreId := config.GetDefaultBlobStoreId()
+		description := fmt.Sprintf("konfig default-blob_store %s", blobStoreId)
+
+		if _, ok := repo.GetEnvRepo().GetBlobStores()[blobStoreId.String()]; !ok {
+			tw.NotOk(
+				description,
+				tap_diagnostics.FromError(
+					errors.BadRequestf("blob store not found: %q", blobStoreId),
+				),
+			)
+		} else {
+			tw.Ok(description)
+		}
+	}
+	for _, route := range repo_configs.GetBlobStoreRoutes(typedBlob.Blob) {
+		description := fmt.Sprintf(
+			"konfig blob_store-routes %s",
+			route.BlobStoreId,
+		)
+
+		if _, ok := repo.GetEnvRepo().GetBlobStores()[route.BlobStoreId.String()]; !ok {
+			tw.NotOk(
+				description,
+				tap_diagnostics.FromError(
+					errors.BadRequestf("blob store not found: %q", route.BlobStoreId),
+				),
+			)
+		} else {
+			tw.Ok(description)
+		}
+	}
+}
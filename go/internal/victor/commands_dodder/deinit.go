@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
 	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/charlie/comments"
@@ -91,6 +92,17 @@ func (cmd Deinit) Run(req command.Request) {
 				workspaceConfigFilePath,
 			)
 		}
+
+		workspaceBlobStoreDir := env_repo.MakeWorkspaceBlobStorePath(
+			repo.GetEnvWorkspace().GetWorkspaceDir(),
+		).GetBase()
+
+		if files.Exists(workspaceBlobStoreDir) {
+			filesAndDirectories = append(
+				filesAndDirectories,
+				workspaceBlobStoreDir,
+			)
+		}
 	}
 
 	// TODO decide whether the workspace directory should be deleted too
@@ -0,0 +1,57 @@
+package commands_dodder
+
+import (
+	"fmt"
+	"os"
+
+	"code.linenisgreat.com/dodder/go/internal/charlie/tap_diagnostics"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
+)
+
+func init() {
+	utility.AddCmd("doctor", &Doctor{})
+}
+
+type Doctor struct {
+	command_components_dodder.EnvRepo
+}
+
+func (cmd Doctor) GetDescription() command.Description {
+	return command.Description{
+		Short: "check that every blob store is reachable and consistent",
+	}
+}
+
+func (cmd Doctor) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	env := cmd.MakeEnvRepo(req, false)
+
+	tw := tap.NewWriter(os.Stdout)
+
+	for _, blobStore := range env.GetBlobStoresSorted() {
+		storeId := blobStore.Path.GetId().String()
+
+		checks, ok := blob_stores.CheckHealth(blobStore.BlobStore)
+		if !ok {
+			tw.Skip(storeId, "no health check")
+			continue
+		}
+
+		for _, check := range checks {
+			description := fmt.Sprintf("%s %s", storeId, check.Description)
+
+			if check.Err != nil {
+				tw.NotOk(description, tap_diagnostics.FromError(check.Err))
+				continue
+			}
+
+			tw.Ok(description)
+		}
+	}
+
+	tw.Plan()
+}
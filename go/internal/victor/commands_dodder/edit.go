@@ -8,6 +8,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/env_local"
 	"code.linenisgreat.com/dodder/go/internal/golf/command"
 	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
 	"code.linenisgreat.com/dodder/go/internal/tango/user_ops"
 	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
@@ -29,6 +30,8 @@ type Edit struct {
 	// TODO-P3 add force
 	command_components_dodder.Checkout
 	CheckoutMode checkout_mode.Mode
+
+	Temp bool
 }
 
 var _ interfaces.CommandComponentWriter = (*Edit)(nil)
@@ -39,6 +42,13 @@ func (cmd *Edit) SetFlagDefinitions(flagSet interfaces.CLIFlagDefinitions) {
 	cmd.Checkout.SetFlagDefinitions(flagSet)
 
 	flagSet.Var(&cmd.CheckoutMode, "mode", "mode for checking out the object")
+
+	flagSet.BoolVar(
+		&cmd.Temp,
+		"temp",
+		false,
+		"edit in temp files instead of the workspace, committing only the changed fields",
+	)
 }
 
 func (cmd Edit) CompletionGenres() ids.Genre {
@@ -66,6 +76,7 @@ func (cmd *Edit) Complete(
 	cmd.complete.CompleteObjectsIncludingWorkspace(
 		req,
 		localWorkingCopy,
+		commandLine,
 		queries.BuilderOptionDefaultGenres(genres.Zettel),
 		args...,
 	)
@@ -73,6 +84,12 @@ func (cmd *Edit) Complete(
 
 func (cmd Edit) Run(req command.Request) {
 	repo := cmd.MakeLocalWorkingCopy(req)
+
+	if cmd.Temp {
+		cmd.runTemp(req, repo)
+		return
+	}
+
 	envWorkspace := repo.GetEnvWorkspace()
 
 	// TODO eventually remove this once temporary edits work correctly
@@ -108,3 +125,28 @@ func (cmd Edit) Run(req command.Request) {
 		repo.Cancel(err)
 	}
 }
+
+func (cmd Edit) runTemp(
+	req command.Request,
+	repo *local_working_copy.Repo,
+) {
+	query := cmd.MakeQuery(
+		req,
+		queries.BuilderOptionDefaultGenres(
+			genres.Tag,
+			genres.Zettel,
+			genres.Type,
+			genres.Repo,
+		),
+		repo,
+		req.PopArgs(),
+	)
+
+	opEdit := user_ops.EditInTempFiles{
+		Repo: repo,
+	}
+
+	if err := opEdit.RunQuery(query); err != nil {
+		repo.Cancel(err)
+	}
+}
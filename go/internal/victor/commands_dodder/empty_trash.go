@@ -0,0 +1,89 @@
+package commands_dodder
+
+import (
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd("empty-trash", &EmptyTrash{})
+}
+
+type EmptyTrash struct {
+	command_components_dodder.LocalWorkingCopy
+
+	Retention string
+}
+
+var _ interfaces.CommandComponentWriter = (*EmptyTrash)(nil)
+
+func (cmd EmptyTrash) GetDescription() command.Description {
+	return command.Description{
+		Short: "permanently remove the contents of objects trashed past the retention period",
+		Long: "Deletes the blobs of the objects that have been in the " +
+			"trash for longer than the `trash.retention` of the repo config " +
+			"(30 days by default) from the default blob store, unless an " +
+			"object version outside the trash references them. The objects " +
+			"stay in the trash, as their versions are part of the signed " +
+			"inventory lists. Needs a built blob refcount index. With " +
+			"-dry-run, the objects and blobs that would be purged are listed.",
+	}
+}
+
+func (cmd *EmptyTrash) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.StringVar(
+		&cmd.Retention,
+		"retention",
+		"",
+		"purge objects trashed longer ago than this Go duration, like `0s` "+
+			"for all of them, instead of the repo config's `trash.retention`",
+	)
+}
+
+func (cmd EmptyTrash) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	var retention time.Duration
+	var err error
+
+	if cmd.Retention == "" {
+		retention, err = localWorkingCopy.GetConfig().GetTrash().GetRetention()
+	} else if retention, err = time.ParseDuration(cmd.Retention); err != nil {
+		err = errors.BadRequest(err)
+	}
+
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	purge := localWorkingCopy.EmptyTrash(retention)
+
+	verb := "purged"
+
+	if localWorkingCopy.GetConfig().IsDryRun() {
+		verb = "would purge"
+	}
+
+	for _, object := range purge.Objects {
+		ui.Out().Printf("%s %s", verb, object.GetObjectId())
+	}
+
+	ui.Err().Printf(
+		"%s %d objects, deleting %d blobs",
+		verb,
+		len(purge.Objects),
+		len(purge.Blobs),
+	)
+}
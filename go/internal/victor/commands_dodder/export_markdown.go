@@ -0,0 +1,90 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/alfa/markdown_vault"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/tango/user_ops"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd(
+		"export-markdown",
+		&ExportMarkdown{Layout: markdown_vault.LayoutType},
+	)
+}
+
+type ExportMarkdown struct {
+	command_components_dodder.LocalWorkingCopy
+	command_components_dodder.Query
+
+	Layout string
+}
+
+var _ interfaces.CommandComponentWriter = (*ExportMarkdown)(nil)
+
+func (cmd ExportMarkdown) GetDescription() command.Description {
+	return command.Description{
+		Short: "write objects as markdown notes with front matter to a directory",
+	}
+}
+
+func (cmd *ExportMarkdown) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.Func(
+		"layout",
+		"directory layout: type, tag, or flat (default type)",
+		func(value string) (err error) {
+			switch value {
+			case markdown_vault.LayoutType,
+				markdown_vault.LayoutTag,
+				markdown_vault.LayoutFlat:
+				cmd.Layout = value
+
+			default:
+				err = errors.BadRequestf(
+					"unsupported layout: %q, expected type, tag, or flat",
+					value,
+				)
+			}
+
+			return err
+		},
+	)
+}
+
+func (cmd ExportMarkdown) Run(req command.Request) {
+	directory := req.PopArg("directory")
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	query := cmd.MakeQuery(
+		req,
+		queries.BuilderOptions(
+			queries.BuilderOptionDefaultGenres(genres.Zettel),
+		),
+		localWorkingCopy,
+		req.PopArgs(),
+	)
+
+	report, err := user_ops.ExportMarkdown{
+		Repo:   localWorkingCopy,
+		Layout: cmd.Layout,
+	}.Run(query, directory)
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	for _, exported := range report {
+		ui.Out().Printf("%s -> %s", exported.ObjectId, exported.Path)
+	}
+}
@@ -67,6 +67,7 @@ func (cmd *FormatBlob) Complete(
 	cmd.complete.CompleteObjects(
 		req,
 		localWorkingCopy,
+		commandLine,
 		queries.BuilderOptionDefaultGenres(genres.Zettel),
 		args...,
 	)
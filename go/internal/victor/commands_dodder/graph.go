@@ -0,0 +1,124 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/hotel/graph"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd(
+		"graph",
+		&Graph{
+			Format: "dot",
+			Options: graph.Options{
+				Tags:  true,
+				Types: true,
+			},
+		},
+	)
+}
+
+type Graph struct {
+	command_components_dodder.LocalWorkingCopy
+	command_components_dodder.Query
+
+	Format string
+	graph.Options
+}
+
+var _ interfaces.CommandComponentWriter = (*Graph)(nil)
+
+func (cmd Graph) GetDescription() command.Description {
+	return command.Description{
+		Short: "export objects, tags, types, blobs, and versions as a graph",
+	}
+}
+
+func (cmd *Graph) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.Func(
+		"format",
+		"output format: dot or json (default dot)",
+		func(value string) (err error) {
+			switch value {
+			case "dot", "json":
+				cmd.Format = value
+
+			default:
+				err = errors.BadRequestf(
+					"unsupported graph format: %q, expected dot or json",
+					value,
+				)
+			}
+
+			return err
+		},
+	)
+
+	flagSet.BoolVar(
+		&cmd.Tags,
+		"tags",
+		true,
+		"include tags and the tag hierarchy",
+	)
+
+	flagSet.BoolVar(&cmd.Types, "types", true, "include types")
+	flagSet.BoolVar(&cmd.Blobs, "blobs", false, "include blobs")
+
+	flagSet.BoolVar(
+		&cmd.Mothers,
+		"mothers",
+		false,
+		"include each object's chain of previous versions",
+	)
+}
+
+func (cmd Graph) Run(req command.Request) {
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	query := cmd.MakeQuery(
+		req,
+		queries.BuilderOptions(
+			queries.BuilderOptionDefaultGenres(genres.Zettel),
+		),
+		localWorkingCopy,
+		req.PopArgs(),
+	)
+
+	builder := graph.Builder{
+		Options:    cmd.Options,
+		ReadMother: localWorkingCopy.GetStore().GetStreamIndex().ReadOneMarklId,
+	}
+
+	if err := localWorkingCopy.GetStore().QueryTransacted(
+		query,
+		builder.Add,
+	); err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	output := builder.Graph()
+
+	var err error
+
+	switch cmd.Format {
+	case "json":
+		err = output.WriteJSON(localWorkingCopy.GetUIFile())
+
+	default:
+		err = output.WriteDOT(localWorkingCopy.GetUIFile())
+	}
+
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+	}
+}
@@ -0,0 +1,265 @@
+package commands_dodder
+
+import (
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/hotel/object_history"
+	"code.linenisgreat.com/dodder/go/internal/papa/store"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd(
+		"history",
+		&History{
+			Format:     "text",
+			Blobs:      true,
+			Provenance: true,
+		},
+	)
+}
+
+type History struct {
+	command_components_dodder.LocalWorkingCopy
+
+	Format     string
+	Blobs      bool
+	Provenance bool
+}
+
+var _ interfaces.CommandComponentWriter = (*History)(nil)
+
+func (cmd History) GetDescription() command.Description {
+	return command.Description{
+		Short: "show every version of an object and what changed",
+		Long: "Follows the chain of mother signatures from the latest version " +
+			"of an object, printing each version's tai and the metadata fields " +
+			"that differ from the version before it, newest first. Blobs of " +
+			"inline (text) types are shown as a unified diff. Each version " +
+			"also shows where the inventory list that committed it " +
+			"originated: its repo id, hostname, and dodder version. Types " +
+			"that declare a diff-tool have their blobs diffed by it instead.",
+	}
+}
+
+func (cmd *History) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.Func(
+		"format",
+		"output format: text or json (default text)",
+		func(value string) (err error) {
+			switch value {
+			case "text", "json":
+				cmd.Format = value
+
+			default:
+				err = errors.BadRequestf(
+					"unsupported history format: %q, expected text or json",
+					value,
+				)
+			}
+
+			return err
+		},
+	)
+
+	flagSet.BoolVar(
+		&cmd.Blobs,
+		"blobs",
+		true,
+		"include a diff of the blobs of inline types",
+	)
+
+	flagSet.BoolVar(
+		&cmd.Provenance,
+		"provenance",
+		true,
+		"include the provenance of the inventory list that committed each version",
+	)
+}
+
+func (cmd History) Run(req command.Request) {
+	objectIdString := req.PopArg("object-id")
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	var objectId ids.ObjectId
+
+	if err := objectId.Set(objectIdString); err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	object, err := localWorkingCopy.GetStore().ReadOneObjectId(&objectId)
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	} else if object == nil {
+		localWorkingCopy.Cancel(
+			errors.BadRequestf("object %q not found", objectIdString),
+		)
+		return
+	}
+
+	reader := object_history.Reader{
+		ReadMother: localWorkingCopy.GetStore().GetStreamIndex().ReadOneMarklId,
+	}
+
+	if cmd.Blobs {
+		reader.DiffBlobs = cmd.makeDiffBlobs(localWorkingCopy)
+		reader.ReadBlob = cmd.makeReadBlob(localWorkingCopy)
+	}
+
+	if cmd.Provenance {
+		reader.ReadProvenance = cmd.makeReadProvenance(
+			localWorkingCopy,
+			object.GetObjectId().String(),
+		)
+	}
+
+	history, err := reader.Read(object)
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	switch cmd.Format {
+	case "json":
+		err = history.WriteJSON(localWorkingCopy.GetUIFile())
+
+	default:
+		err = history.WriteText(localWorkingCopy.GetUIFile())
+	}
+
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+	}
+}
+
+// makeDiffBlobs diffs the blobs of versions whose type declares a diff tool
+// with it.
+func (cmd History) makeDiffBlobs(
+	repo *local_working_copy.Repo,
+) object_history.FuncDiffBlobs {
+	return func(
+		mother, daughter *sku.Transacted,
+	) (diff string, ok bool, err error) {
+		var tools store.ExternalTools
+
+		if tools, err = repo.GetStore().ReadExternalTools(daughter); err != nil {
+			err = errors.Wrap(err)
+			return diff, ok, err
+		}
+
+		if tools.Diff == nil {
+			return diff, ok, err
+		}
+
+		ok = true
+
+		var before domain_interfaces.MarklId
+
+		if mother != nil {
+			before = mother.GetBlobDigest()
+		}
+
+		if diff, err = repo.GetStore().DiffBlobsWithTool(
+			tools,
+			before,
+			daughter.GetBlobDigest(),
+		); err != nil {
+			err = errors.Wrap(err)
+			return diff, ok, err
+		}
+
+		return diff, ok, err
+	}
+}
+
+func (cmd History) makeReadBlob(
+	repo *local_working_copy.Repo,
+) object_history.FuncReadBlob {
+	return func(object *sku.Transacted) (text string, ok bool, err error) {
+		if !repo.GetConfig().IsInlineType(object.GetType()) {
+			return text, ok, err
+		}
+
+		ok = true
+
+		if object.GetBlobDigest().IsNull() {
+			return text, ok, err
+		}
+
+		var blobReader domain_interfaces.BlobReader
+
+		if blobReader, err = repo.GetEnvRepo().GetDefaultBlobStore().MakeBlobReader(
+			object.GetBlobDigest(),
+		); err != nil {
+			err = errors.Wrap(err)
+			return text, ok, err
+		}
+
+		defer errors.DeferredCloser(&err, blobReader)
+
+		var bites []byte
+
+		if bites, err = io.ReadAll(blobReader); err != nil {
+			err = errors.Wrap(err)
+			return text, ok, err
+		}
+
+		text = string(bites)
+
+		return text, ok, err
+	}
+}
+
+// makeReadProvenance reads the inventory lists the first time it is called,
+// mapping the signature of every version of objectId that they contain to the
+// provenance of its list.
+func (cmd History) makeReadProvenance(
+	repo *local_working_copy.Repo,
+	objectId string,
+) object_history.FuncReadProvenance {
+	var provenances map[string]objects.Provenance
+
+	return func(
+		object *sku.Transacted,
+	) (provenance objects.Provenance, err error) {
+		if provenances == nil {
+			provenances = make(map[string]objects.Provenance)
+
+			for objectWithList, iterErr := range repo.GetStore().GetInventoryListStore().AllInventoryListObjectsAndContents() {
+				if iterErr != nil {
+					err = errors.Wrap(iterErr)
+					return provenance, err
+				}
+
+				listProvenance := objectWithList.List.GetMetadata().GetProvenance()
+
+				if listProvenance.IsEmpty() ||
+					objectWithList.Object.GetObjectId().String() != objectId {
+					continue
+				}
+
+				provenances[objectWithList.Object.GetMetadata().GetObjectSig().String()] = listProvenance
+			}
+		}
+
+		provenance = provenances[object.GetMetadata().GetObjectSig().String()]
+
+		return provenance, err
+	}
+}
@@ -60,6 +60,10 @@ func (cmd Import) Run(req command.Request) {
 	cmd.DedupingFormatId = markl.PurposeV5MetadataDigestWithoutTai
 	cmd.CheckedOutPrinter = local.PrinterCheckedOutConflictsForRemoteTransfers()
 
+	// exported lists may omit signatures, in which case this repo signs the
+	// objects as new. Signed objects must still verify against a trusted pubkey.
+	cmd.AllowUnsignedObjects = true
+
 	if !cmd.BlobStoreId.IsEmpty() {
 		cmd.RemoteBlobStore = local.GetEnvRepo().GetEnvBlobStore().GetBlobStore(
 			cmd.BlobStoreId,
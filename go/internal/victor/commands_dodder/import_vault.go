@@ -0,0 +1,135 @@
+package commands_dodder
+
+import (
+	"encoding/json"
+	"os"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/tango/user_ops"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd("import-vault", &ImportVault{})
+}
+
+type ImportVault struct {
+	command_components_dodder.LocalWorkingCopy
+
+	BatchSize  int
+	Type       string
+	ReportPath string
+}
+
+var _ interfaces.CommandComponentWriter = (*ImportVault)(nil)
+
+func (cmd ImportVault) GetDescription() command.Description {
+	return command.Description{
+		Short: "import the notes of a markdown or Obsidian vault as zettels",
+	}
+}
+
+func (cmd *ImportVault) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.IntVar(
+		&cmd.BatchSize,
+		"batch-size",
+		user_ops.DefaultImportVaultBatchSize,
+		"how many notes to commit per inventory list",
+	)
+
+	flagSet.StringVar(
+		&cmd.Type,
+		"type",
+		"",
+		"type of notes without a type in their front matter (default the workspace's default type, or md)",
+	)
+
+	flagSet.StringVar(
+		&cmd.ReportPath,
+		"report",
+		"",
+		"write the mapping of note paths to object ids, with unresolved links and skipped tags, as JSON to this path",
+	)
+}
+
+func (cmd ImportVault) Run(req command.Request) {
+	vaultPath := req.PopArg("vault-path")
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	tipe := cmd.Type
+
+	if tipe == "" {
+		tipe = localWorkingCopy.GetEnvWorkspace().GetDefaults().GetDefaultType().String()
+	}
+
+	if tipe == "" {
+		tipe = "md"
+	}
+
+	report, err := user_ops.ImportVault{
+		Repo:        localWorkingCopy,
+		BatchSize:   cmd.BatchSize,
+		DefaultType: tipe,
+	}.Run(vaultPath)
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	if len(report) == 0 {
+		ui.Err().Printf("no notes found in %q", vaultPath)
+		return
+	}
+
+	for _, imported := range report {
+		ui.Err().Printf("%s -> %s", imported.Path, imported.ObjectId)
+
+		for _, target := range imported.UnresolvedLinks {
+			ui.Err().Printf("%s: unresolved link: [[%s]]", imported.Path, target)
+		}
+
+		for _, tag := range imported.SkippedTags {
+			ui.Err().Printf("%s: skipped tag: %q", imported.Path, tag)
+		}
+	}
+
+	if cmd.ReportPath == "" {
+		return
+	}
+
+	if err = cmd.writeReport(report); err != nil {
+		localWorkingCopy.Cancel(err)
+	}
+}
+
+func (cmd ImportVault) writeReport(
+	report []user_ops.ImportedNote,
+) (err error) {
+	var file *os.File
+
+	if file, err = os.Create(cmd.ReportPath); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err = encoder.Encode(report); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
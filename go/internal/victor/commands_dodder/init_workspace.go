@@ -10,10 +10,12 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/echo/workspace_config_blobs"
 	"code.linenisgreat.com/dodder/go/internal/foxtrot/env_local"
 	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/internal/hotel/command_components"
 	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/charlie/values"
 	"code.linenisgreat.com/dodder/go/lib/delta/files"
 )
@@ -21,7 +23,9 @@ import (
 func init() {
 	utility.AddCmd(
 		"init-workspace",
-		&InitWorkspace{})
+		&InitWorkspace{
+			BlobStore: env_repo.WorkspaceBlobStorePointer,
+		})
 }
 
 type InitWorkspace struct {
@@ -32,6 +36,8 @@ type InitWorkspace struct {
 
 	DefaultQueryGroup values.String
 	Proto             sku.Proto
+	BlobStore         env_repo.WorkspaceBlobStoreType
+	Sparse            bool
 }
 
 var _ interfaces.CommandComponentWriter = (*InitWorkspace)(nil)
@@ -59,6 +65,19 @@ func (cmd *InitWorkspace) SetFlagDefinitions(
 		"query",
 		"default query for `show`",
 	)
+
+	flagSet.Var(
+		&cmd.BlobStore,
+		"blob-store",
+		"blob store for the workspace relative to the repo's default blob store: pointer, lazy, or copy",
+	)
+
+	flagSet.BoolVar(
+		&cmd.Sparse,
+		"sparse",
+		false,
+		"make -query define the objects checked out in the workspace, as kept up to date by `refresh-workspace`",
+	)
 }
 
 func (cmd InitWorkspace) Complete(
@@ -123,19 +142,36 @@ func (cmd InitWorkspace) Run(req command.Request) {
 
 	req.AssertNoMoreArgs()
 
+	if cmd.Sparse && cmd.DefaultQueryGroup.String() == "" {
+		errors.ContextCancelWithBadRequestf(
+			req,
+			"-sparse requires a -query",
+		)
+	}
+
 	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
 
 	blob := &workspace_config_blobs.V0{
-		Query: cmd.DefaultQueryGroup.String(),
+		Query:  cmd.DefaultQueryGroup.String(),
+		Sparse: cmd.Sparse,
 		Defaults: repo_configs.DefaultsV1OmitEmpty{
 			Type: cmd.Proto.Metadata.GetType().ToType(),
 			Tags: slices.Collect(ids.ITagSeqToTagStructSeq(cmd.Proto.Metadata.AllTags())),
 		},
 	}
 
-	if err := localWorkingCopy.GetEnvWorkspace().CreateWorkspace(
-		blob,
+	envWorkspace := localWorkingCopy.GetEnvWorkspace()
+
+	if err := envWorkspace.CreateWorkspace(blob); err != nil {
+		req.Cancel(err)
+		return
+	}
+
+	if err := localWorkingCopy.GetEnvRepo().CreateWorkspaceBlobStore(
+		envWorkspace.GetWorkspaceDir(),
+		cmd.BlobStore,
 	); err != nil {
 		req.Cancel(err)
+		return
 	}
 }
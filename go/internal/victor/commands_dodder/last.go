@@ -80,11 +80,12 @@ func (cmd Last) runLocalWorkingCopy(localWorkingCopy *local_working_copy.Repo) {
 	skus := sku.MakeTransactedMutableSet()
 
 	var funcIter interfaces.FuncIter[*sku.Transacted]
+	flushFormat := func() error { return nil }
 
 	if cmd.Organize || cmd.Edit {
 		funcIter = skus.Add
 	} else {
-		funcIter = cmd.Format.MakeFormatFunc(
+		funcIter, flushFormat = cmd.Format.MakeFormatFunc(
 			localWorkingCopy,
 			localWorkingCopy.GetUIFile(),
 		)
@@ -101,6 +102,11 @@ func (cmd Last) runLocalWorkingCopy(localWorkingCopy *local_working_copy.Repo) {
 		return
 	}
 
+	if err := flushFormat(); err != nil {
+		localWorkingCopy.GetEnvRepo().Cancel(err)
+		return
+	}
+
 	if cmd.Organize {
 		opOrganize := user_ops.Organize{
 			Repo: localWorkingCopy,
@@ -0,0 +1,207 @@
+package commands_dodder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/delta/objects"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd(
+		"log",
+		&Log{
+			Format: "text",
+		},
+	)
+}
+
+type Log struct {
+	command_components_dodder.LocalWorkingCopy
+
+	Limit  int
+	Since  ids.Tai
+	Format string
+}
+
+type logEntry struct {
+	Tai         string              `json:"tai"`
+	ObjectSig   string              `json:"object-sig,omitempty"`
+	BlobId      string              `json:"blob-id,omitempty"`
+	ObjectCount int                 `json:"object-count"`
+	Description string              `json:"description,omitempty"`
+	Provenance  *objects.Provenance `json:"provenance,omitempty"`
+	RepoPubKey  string              `json:"repo-pub_key,omitempty"`
+}
+
+var _ interfaces.CommandComponentWriter = (*Log)(nil)
+
+func (cmd Log) GetDescription() command.Description {
+	return command.Description{
+		Short: "list the inventory lists of the repo, newest first",
+		Long: "Prints one line per inventory list, starting from the one " +
+			"committed last: its tai, the number of objects it contains, " +
+			"where it originated (its provenance, or the public key of the " +
+			"repo that signed it), and its description. The inventory list " +
+			"log is read backwards, so -limit and -since only read as much " +
+			"of it as they print.",
+	}
+}
+
+func (cmd *Log) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.IntVar(
+		&cmd.Limit,
+		"limit",
+		0,
+		"print at most this many inventory lists (0 for all)",
+	)
+
+	flagSet.Var(
+		(*ids.TaiOrTimeValue)(&cmd.Since),
+		"since",
+		"stop at the first inventory list older than a tai, RFC3339 timestamp, or date",
+	)
+
+	flagSet.Func(
+		"format",
+		"output format: text or json (default text)",
+		func(value string) (err error) {
+			switch value {
+			case "text", "json":
+				cmd.Format = value
+
+			default:
+				err = errors.BadRequestf(
+					"unsupported log format: %q, expected text or json",
+					value,
+				)
+			}
+
+			return err
+		},
+	)
+}
+
+func (cmd Log) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+	inventoryListStore := localWorkingCopy.GetStore().GetInventoryListStore()
+
+	entries := make([]logEntry, 0)
+
+	for list, err := range inventoryListStore.AllInventoryListsReverse() {
+		if err != nil {
+			localWorkingCopy.Cancel(err)
+			return
+		}
+
+		if cmd.Limit > 0 && len(entries) >= cmd.Limit {
+			break
+		}
+
+		if !cmd.Since.IsEmpty() && list.GetTai().Before(cmd.Since) {
+			break
+		}
+
+		entry, err := cmd.makeEntry(list)
+		if err != nil {
+			localWorkingCopy.Cancel(err)
+			return
+		}
+
+		if entry.ObjectCount, err = inventoryListStore.CountInventoryListObjects(
+			list,
+		); err != nil {
+			localWorkingCopy.Cancel(err)
+			return
+		}
+
+		entries = append(entries, entry)
+	}
+
+	var err error
+
+	switch cmd.Format {
+	case "json":
+		err = cmd.writeJSON(localWorkingCopy.GetUIFile(), entries)
+
+	default:
+		err = cmd.writeText(localWorkingCopy.GetUIFile(), entries)
+	}
+
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+	}
+}
+
+func (cmd Log) makeEntry(list *sku.Transacted) (entry logEntry, err error) {
+	metadata := list.GetMetadata()
+
+	entry = logEntry{
+		Tai:         list.GetTai().String(),
+		ObjectSig:   metadata.GetObjectSig().String(),
+		BlobId:      list.GetBlobDigest().String(),
+		Description: metadata.GetDescription().String(),
+		RepoPubKey:  metadata.GetRepoPubKey().StringWithFormat(),
+	}
+
+	if provenance := metadata.GetProvenance(); !provenance.IsEmpty() {
+		entry.Provenance = &provenance
+	}
+
+	return entry, err
+}
+
+func (cmd Log) writeText(
+	writer io.Writer,
+	entries []logEntry,
+) (err error) {
+	for _, entry := range entries {
+		origin := entry.RepoPubKey
+
+		if entry.Provenance != nil {
+			origin = entry.Provenance.String()
+		}
+
+		if _, err = fmt.Fprintf(
+			writer,
+			"%s %d %s %q\n",
+			entry.Tai,
+			entry.ObjectCount,
+			origin,
+			entry.Description,
+		); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
+	return err
+}
+
+func (cmd Log) writeJSON(
+	writer io.Writer,
+	entries []logEntry,
+) (err error) {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	if err = encoder.Encode(entries); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
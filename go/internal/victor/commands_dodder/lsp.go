@@ -0,0 +1,60 @@
+package commands_dodder
+
+import (
+	"os"
+
+	"code.linenisgreat.com/dodder/go/internal/delta/env_ui"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/hotel/command_components"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/internal/tango/language_server"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+)
+
+func init() {
+	utility.AddCmd("lsp", &Lsp{})
+}
+
+type Lsp struct {
+	command_components.Env
+	command_components_dodder.LocalWorkingCopy
+}
+
+var _ interfaces.CommandComponentWriter = (*Lsp)(nil)
+
+func (cmd Lsp) GetDescription() command.Description {
+	return command.Description{
+		Short: "serve object links to editors over the language server protocol",
+	}
+}
+
+func (cmd *Lsp) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+}
+
+func (cmd Lsp) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	// stdout carries the protocol
+	envLocal := cmd.MakeEnvWithOptions(
+		req,
+		env_ui.Options{
+			UIFileIsStderr: true,
+			IgnoreTtyState: true,
+		},
+	)
+
+	server := language_server.Server{
+		Repo: cmd.MakeLocalWorkingCopyFromEnvLocal(
+			envLocal,
+			local_working_copy.OptionsWithoutSharedAccess,
+		),
+	}
+
+	if err := server.Run(req, os.Stdin, os.Stdout); err != nil {
+		req.Cancel(err)
+	}
+}
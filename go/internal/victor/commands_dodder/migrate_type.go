@@ -0,0 +1,80 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/tango/user_ops"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd("migrate-type", &MigrateType{})
+}
+
+type MigrateType struct {
+	command_components_dodder.LocalWorkingCopy
+
+	BatchSize int
+}
+
+var _ interfaces.CommandComponentWriter = (*MigrateType)(nil)
+
+func (cmd MigrateType) GetDescription() command.Description {
+	return command.Description{
+		Short: "re-encode the blobs of every object of a type",
+		Long: "Deprecated builtin types, like !toml-type-v0, are re-encoded " +
+			"as the builtin type that replaced them. Other types are migrated " +
+			"by the `migrate` function of the Lua `migration` script of their " +
+			"type object, which is called with each object and returns its " +
+			"new blob, or nil to leave it as it is, and optionally a new type. " +
+			"With -dry-run, the objects that would change are listed without " +
+			"writing blobs or committing.",
+	}
+}
+
+func (cmd *MigrateType) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.IntVar(
+		&cmd.BatchSize,
+		"batch-size",
+		user_ops.DefaultMigrateTypeBatchSize,
+		"how many objects to commit per inventory list",
+	)
+}
+
+func (cmd MigrateType) Run(req command.Request) {
+	tipe := req.PopArg("type")
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+	dryRun := localWorkingCopy.GetConfig().IsDryRun()
+
+	report, err := user_ops.MigrateType{
+		Repo:      localWorkingCopy,
+		BatchSize: cmd.BatchSize,
+		DryRun:    dryRun,
+		Progress: func(done, total int) {
+			ui.Err().Printf("processed %d of %d objects", done, total)
+		},
+	}.Run(tipe)
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	verb := "migrated"
+
+	if dryRun {
+		verb = "would migrate"
+	}
+
+	for _, migrated := range report {
+		ui.Out().Printf("%s %s %s", verb, migrated.ObjectId, migrated.Type)
+	}
+
+	ui.Err().Printf("%s %d objects", verb, len(report))
+}
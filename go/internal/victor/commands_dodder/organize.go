@@ -85,6 +85,7 @@ func (cmd Organize) Complete(
 	cmd.complete.CompleteObjects(
 		req,
 		localWorkingCopy,
+		commandLine,
 		queries.BuilderOptionDefaultGenres(
 			genres.Tag,
 			genres.Type,
@@ -26,6 +26,13 @@ func (cmd *Pull) SetFlagDefinitions(f interfaces.CLIFlagDefinitions) {
 	cmd.RemoteTransfer.SetFlagDefinitions(f)
 	cmd.Query.SetFlagDefinitions(f)
 	cmd.LocalWorkingCopy.SetFlagDefinitions(f)
+
+	f.BoolVar(
+		&cmd.VerifyClosure,
+		"verify-closure",
+		false,
+		"after pulling, report any blob missing from the closure of pulled objects, the konfig, and their type and tag objects",
+	)
 }
 
 func (cmd Pull) Run(req command.Request) {
@@ -0,0 +1,58 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/hotel/blob_transfers"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+)
+
+func init() {
+	utility.AddCmd("push-to-parent", &PushToParent{})
+}
+
+type PushToParent struct {
+	command_components_dodder.LocalWorkingCopy
+
+	PrintCopies bool
+}
+
+var _ interfaces.CommandComponentWriter = (*PushToParent)(nil)
+
+func (cmd *PushToParent) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.BoolVar(
+		&cmd.PrintCopies,
+		"print-copies",
+		true,
+		"print each blob copied to the parent blob store",
+	)
+}
+
+func (cmd PushToParent) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	var counts blob_transfers.Counts
+
+	{
+		var err error
+
+		if counts, err = localWorkingCopy.PushToParent(
+			cmd.PrintCopies,
+		); err != nil {
+			localWorkingCopy.Cancel(err)
+			return
+		}
+	}
+
+	localWorkingCopy.GetUI().Printf(
+		"pushed %d blobs to parent (%d already present)",
+		counts.Succeeded,
+		counts.Ignored,
+	)
+}
@@ -0,0 +1,28 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+)
+
+func init() {
+	utility.AddCmd("rebuild-blob-refcounts", &RebuildBlobRefcounts{})
+}
+
+type RebuildBlobRefcounts struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+func (cmd RebuildBlobRefcounts) GetDescription() command.Description {
+	return command.Description{
+		Short: "recount the object versions referencing each blob",
+	}
+}
+
+func (cmd RebuildBlobRefcounts) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	localWorkingCopy.RebuildBlobRefcounts()
+}
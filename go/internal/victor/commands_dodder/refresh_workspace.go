@@ -0,0 +1,141 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/checkout_mode"
+	"code.linenisgreat.com/dodder/go/internal/alfa/checkout_options"
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/echo/workspace_config_blobs"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/tango/user_ops"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd(
+		"refresh-workspace",
+		&RefreshWorkspace{
+			CheckoutOptions: checkout_options.Options{
+				CheckoutMode: checkout_mode.Make(checkout_mode.Default),
+			},
+		})
+}
+
+type RefreshWorkspace struct {
+	command_components_dodder.LocalWorkingCopyWithQueryGroup
+
+	CheckoutOptions checkout_options.Options
+	Evict           bool
+}
+
+var _ interfaces.CommandComponentWriter = (*RefreshWorkspace)(nil)
+
+func (cmd *RefreshWorkspace) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopyWithQueryGroup.SetFlagDefinitions(flagSet)
+	cmd.CheckoutOptions.SetFlagDefinitions(flagSet)
+
+	flagSet.BoolVar(
+		&cmd.Evict,
+		"evict",
+		false,
+		"remove unchanged checkouts of objects that no longer match the workspace query (always on for sparse workspaces)",
+	)
+}
+
+func (cmd RefreshWorkspace) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	repo := cmd.MakeLocalWorkingCopy(req)
+	envWorkspace := repo.GetEnvWorkspace()
+	envWorkspace.AssertNotTemporary(req)
+
+	evict := cmd.Evict
+
+	switch config := envWorkspace.GetWorkspaceConfig().(type) {
+	case workspace_config_blobs.ConfigSparse:
+		evict = evict || config.IsSparse()
+
+		if config.GetDefaultQueryString() == "" {
+			errors.ContextCancelWithBadRequestf(
+				repo,
+				"workspace has no query to refresh",
+			)
+		}
+
+	case workspace_config_blobs.ConfigWithDefaultQueryString:
+		if config.GetDefaultQueryString() == "" {
+			errors.ContextCancelWithBadRequestf(
+				repo,
+				"workspace has no query to refresh",
+			)
+		}
+
+	default:
+		errors.ContextCancelWithBadRequestf(
+			repo,
+			"workspace does not support default queries",
+		)
+	}
+
+	// the workspace's default query narrows this down to the objects it
+	// defines
+	sparseQuery := cmd.MakeQueryIncludingWorkspace(
+		req,
+		queries.BuilderOptions(
+			queries.BuilderOptionPermittedSigil(ids.SigilLatest),
+			queries.BuilderOptionDefaultGenres(genres.Zettel),
+		),
+		repo,
+		nil,
+	)
+
+	checkedOutQuery := cmd.MakeQueryIncludingWorkspace(
+		req,
+		queries.BuilderOptions(
+			queries.BuilderOptionDefaultGenres(genres.All()...),
+			queries.BuilderOptionDefaultSigil(ids.SigilExternal),
+			queries.BuilderOptionHidden(nil),
+			queries.BuilderOptionIgnoreDefaultQuery(),
+		),
+		repo,
+		nil,
+	)
+
+	checkedOutQuery.ExcludeUntracked = true
+
+	opRefresh := user_ops.RefreshWorkspace{
+		Repo:    repo,
+		Options: cmd.CheckoutOptions,
+		Evict:   evict,
+	}
+
+	var results user_ops.RefreshWorkspaceResults
+
+	{
+		var err error
+
+		if results, err = opRefresh.Run(sparseQuery, checkedOutQuery); err != nil {
+			repo.Cancel(err)
+			return
+		}
+	}
+
+	for _, checkedOut := range results.Kept {
+		repo.GetUI().Printf(
+			"%s (no longer matches, kept as it has changes)",
+			checkedOut.GetSku().GetObjectId(),
+		)
+	}
+
+	repo.GetUI().Printf(
+		"refreshed: %d objects checked out, %d evicted, %d kept",
+		results.CheckedOut,
+		results.Evicted,
+		len(results.Kept),
+	)
+}
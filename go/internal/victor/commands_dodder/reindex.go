@@ -5,6 +5,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/golf/command"
 	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
 	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 )
 
@@ -14,6 +15,23 @@ func init() {
 
 type Reindex struct {
 	command_components_dodder.LocalWorkingCopy
+
+	FromCheckpoint bool
+}
+
+var _ interfaces.CommandComponentWriter = (*Reindex)(nil)
+
+func (cmd *Reindex) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.BoolVar(
+		&cmd.FromCheckpoint,
+		"from-checkpoint",
+		false,
+		"resume from the latest verified inventory list checkpoint, without indexing the versions it replaced",
+	)
 }
 
 func (cmd Reindex) Run(req command.Request) {
@@ -32,5 +50,9 @@ func (cmd Reindex) Run(req command.Request) {
 		local_working_copy.OptionsAllowConfigReadError,
 	)
 
-	localWorkingCopy.Reindex()
+	if cmd.FromCheckpoint {
+		localWorkingCopy.ReindexFromCheckpoint()
+	} else {
+		localWorkingCopy.Reindex()
+	}
 }
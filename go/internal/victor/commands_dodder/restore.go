@@ -0,0 +1,81 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd("restore", &Restore{})
+}
+
+type Restore struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+var _ interfaces.CommandComponentWriter = (*Restore)(nil)
+
+func (cmd Restore) GetDescription() command.Description {
+	return command.Description{
+		Short: "take objects out of the trash",
+		Long: "Takes the objects with the given object ids out of the trash " +
+			"by committing a version of each without the `dodder-trash` " +
+			"tag, so they show up in default queries again. Blobs already " +
+			"deleted by `empty-trash` are not brought back.",
+	}
+}
+
+func (cmd Restore) Run(req command.Request) {
+	if req.RemainingArgCount() == 0 {
+		errors.ContextCancelWithBadRequestf(
+			req,
+			"restore requires the object ids to take out of the trash",
+		)
+	}
+
+	objectIdStrings := req.PopArgs()
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	localWorkingCopy.Must(
+		errors.MakeFuncContextFromFuncErr(localWorkingCopy.Lock),
+	)
+
+	for _, objectIdString := range objectIdStrings {
+		var objectId ids.ObjectId
+
+		if err := objectId.Set(objectIdString); err != nil {
+			localWorkingCopy.Cancel(err)
+			return
+		}
+
+		object, err := localWorkingCopy.GetStore().ReadOneObjectId(&objectId)
+		if err != nil {
+			localWorkingCopy.Cancel(err)
+			return
+		}
+
+		ok, err := localWorkingCopy.GetStore().RestoreObject(object)
+		if err != nil {
+			localWorkingCopy.Cancel(err)
+			return
+		}
+
+		if !ok {
+			localWorkingCopy.Cancel(
+				errors.BadRequestf("%s is not in the trash", objectIdString),
+			)
+			return
+		}
+
+		ui.Out().Printf("restored %s", sku.String(object))
+	}
+
+	localWorkingCopy.Must(
+		errors.MakeFuncContextFromFuncErr(localWorkingCopy.Unlock),
+	)
+}
@@ -0,0 +1,92 @@
+package commands_dodder
+
+import (
+	"io"
+	"os"
+
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	"code.linenisgreat.com/dodder/go/lib/delta/files"
+	"code.linenisgreat.com/dodder/go/lib/echo/snapshot_tar"
+)
+
+func init() {
+	utility.AddCmd("restore-backup", &RestoreBackup{})
+}
+
+type RestoreBackup struct {
+	command_components_dodder.EnvRepo
+}
+
+func (cmd RestoreBackup) GetDescription() command.Description {
+	return command.Description{
+		Short: "restore a repo from a tarball written by backup",
+		Long: "Verifies every file of a tarball written by backup against " +
+			"its manifest of checksums, and only then unpacks it into the " +
+			"repo's directories. Refuses to restore over an existing repo.",
+	}
+}
+
+func (cmd RestoreBackup) Run(req command.Request) {
+	backupPath := req.PopArg("backup path")
+	req.AssertNoMoreArgs()
+
+	envRepo := cmd.MakeEnvRepo(req, true)
+
+	if configPath := envRepo.GetPathConfigSeed().String(); files.Exists(
+		configPath,
+	) {
+		errors.ContextCancelWithBadRequestf(
+			req,
+			"a repo already exists at %s, deinit it before restoring",
+			configPath,
+		)
+	}
+
+	manifest, err := readBackup(backupPath, snapshot_tar.Verify)
+	if err != nil {
+		envRepo.Cancel(errors.Wrapf(err, "verifying %s", backupPath))
+		return
+	}
+
+	roots := make(map[string]string)
+
+	for _, root := range envRepo.GetBackupRoots() {
+		roots[root.Name] = root.Path
+	}
+
+	if _, err = readBackup(
+		backupPath,
+		func(reader io.Reader) (snapshot_tar.Manifest, error) {
+			return manifest, snapshot_tar.Extract(reader, manifest, roots)
+		},
+	); err != nil {
+		envRepo.Cancel(errors.Wrapf(err, "restoring %s", backupPath))
+		return
+	}
+
+	ui.Out().Printf(
+		"restored %d files (%s) from %s",
+		len(manifest.Entries),
+		ui.GetHumanBytesStringOrError(manifest.GetTotalSize()),
+		backupPath,
+	)
+}
+
+func readBackup(
+	backupPath string,
+	read func(io.Reader) (snapshot_tar.Manifest, error),
+) (manifest snapshot_tar.Manifest, err error) {
+	var file *os.File
+
+	if file, err = os.Open(backupPath); err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	return read(file)
+}
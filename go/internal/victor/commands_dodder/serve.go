@@ -6,6 +6,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/delta/env_ui"
 	"code.linenisgreat.com/dodder/go/internal/golf/command"
 	"code.linenisgreat.com/dodder/go/internal/hotel/command_components"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
 	"code.linenisgreat.com/dodder/go/internal/tango/remote_http"
 	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
@@ -53,11 +54,16 @@ func (cmd Serve) Run(req command.Request) {
 		},
 	)
 
-	repo := cmd.MakeLocalWorkingCopyFromEnvLocal(envLocal)
+	repo := cmd.MakeLocalWorkingCopyFromEnvLocal(
+		envLocal,
+		local_working_copy.OptionsWithoutSharedAccess,
+	)
 
 	server := remote_http.Server{
 		EnvLocal: envLocal,
 		Repo:     repo,
+		// the global -read-only also limits the server to the read routes
+		ReadOnly: envLocal.IsReadOnly(),
 	}
 
 	if cmd.TailscaleTLS {
@@ -8,6 +8,7 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	pkg_query "code.linenisgreat.com/dodder/go/internal/kilo/queries"
 	"code.linenisgreat.com/dodder/go/internal/quebec/repo"
+	"code.linenisgreat.com/dodder/go/internal/romeo/repo_federation"
 	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
 	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
@@ -34,8 +35,11 @@ type Show struct {
 	complete command_components_dodder.Complete
 
 	After      ids.Tai
+	AsOf       ids.Tai
 	Before     ids.Tai
+	Federate   repo_federation.RepoIds
 	Format     local_working_copy.FormatFlag
+	Pagination pkg_query.Pagination
 	RemoteRepo ids.RepoId
 }
 
@@ -53,7 +57,40 @@ func (cmd *Show) SetFlagDefinitions(flagSet interfaces.CLIFlagDefinitions) {
 
 	flagSet.Var((*ids.TaiRFC3339Value)(&cmd.Before), "before", "")
 	flagSet.Var((*ids.TaiRFC3339Value)(&cmd.After), "after", "")
+
+	flagSet.Var(
+		(*ids.TaiOrTimeValue)(&cmd.AsOf),
+		"as-of",
+		"show objects as they were at a tai, RFC3339 timestamp, or date",
+	)
+
 	flagSet.Var(&cmd.RemoteRepo, "repo", "the remote repo to query")
+
+	flagSet.Var(
+		&cmd.Federate,
+		"federate",
+		"also query these remote repos, comma-separated or repeated, merging their objects with the local ones qualified by repo id",
+	)
+
+	flagSet.IntVar(
+		&cmd.Pagination.Limit,
+		"limit",
+		0,
+		"output at most this many objects (0 for no limit)",
+	)
+
+	flagSet.IntVar(
+		&cmd.Pagination.Offset,
+		"offset",
+		0,
+		"skip this many objects before outputting (requires -sort)",
+	)
+
+	flagSet.Var(
+		&cmd.Pagination.Sort,
+		"sort",
+		"sort by `tai`, `object-id`, or `description`, prefixed by `-` for descending",
+	)
 }
 
 func (cmd Show) Complete(
@@ -72,6 +109,7 @@ func (cmd Show) Complete(
 	cmd.complete.CompleteObjects(
 		req,
 		repo,
+		commandLine,
 		pkg_query.BuilderOptionDefaultGenres(genres.Tag),
 		args...,
 	)
@@ -92,6 +130,8 @@ func (cmd Show) Run(req command.Request) {
 		args,
 	)
 
+	query.AsOf = cmd.AsOf
+
 	cmd.runWithLocalWorkingCopyAndQuery(req, repo, query)
 }
 
@@ -102,8 +142,40 @@ func (cmd Show) runWithLocalWorkingCopyAndQuery(
 ) {
 	var remoteObject *sku.Transacted
 	var remoteWorkingCopy repo.Repo
+	var federation *repo_federation.Federation
+
+	if err := cmd.Pagination.Validate(); err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	if !cmd.RemoteRepo.IsEmpty() && len(cmd.Federate) > 0 {
+		localWorkingCopy.Cancel(
+			errors.BadRequestf("-repo and -federate cannot be combined"),
+		)
+		return
+	}
+
+	if len(cmd.Federate) > 0 {
+		if !query.AsOf.IsEmpty() {
+			localWorkingCopy.Cancel(
+				errors.BadRequestf("-as-of is not supported with -federate"),
+			)
+			return
+		}
+
+		federation = cmd.makeFederation(req, localWorkingCopy)
+		localWorkingCopy.SetFederatedBlobStores(federation)
+	}
 
 	if !cmd.RemoteRepo.IsEmpty() {
+		if !query.AsOf.IsEmpty() {
+			localWorkingCopy.Cancel(
+				errors.BadRequestf("-as-of is not supported with -repo"),
+			)
+			return
+		}
+
 		var err error
 
 		if remoteObject, err = localWorkingCopy.GetObjectFromObjectId(
@@ -123,7 +195,7 @@ func (cmd Show) runWithLocalWorkingCopyAndQuery(
 		// }
 	}
 
-	output := cmd.Format.MakeFormatFunc(
+	output, flushFormat := cmd.Format.MakeFormatFunc(
 		localWorkingCopy,
 		localWorkingCopy.GetUIFile(),
 	)
@@ -162,7 +234,16 @@ func (cmd Show) runWithLocalWorkingCopyAndQuery(
 		}
 	}
 
-	if remoteWorkingCopy != nil {
+	// paginated after the -before and -after filters so that the window only
+	// counts objects that would be output
+	output, flush, release := cmd.Pagination.MakeFuncIter(output)
+	defer release()
+
+	if federation != nil {
+		if err := federation.Query(query, output); err != nil {
+			localWorkingCopy.Cancel(err)
+		}
+	} else if remoteWorkingCopy != nil {
 		var list *sku.HeapTransacted
 
 		{
@@ -175,6 +256,10 @@ func (cmd Show) runWithLocalWorkingCopyAndQuery(
 
 		for object := range list.All() {
 			if err := output(object); err != nil {
+				if errors.IsStopIteration(err) {
+					break
+				}
+
 				localWorkingCopy.Cancel(err)
 			}
 		}
@@ -182,8 +267,67 @@ func (cmd Show) runWithLocalWorkingCopyAndQuery(
 		if err := localWorkingCopy.GetStore().QueryTransacted(
 			query,
 			quiter.MakeSyncSerializer(output),
-		); err != nil {
+		); err != nil && !errors.IsStopIteration(err) {
+			localWorkingCopy.Cancel(err)
+		}
+	}
+
+	if err := flush(); err != nil {
+		localWorkingCopy.Cancel(err)
+	}
+
+	if err := flushFormat(); err != nil {
+		localWorkingCopy.Cancel(err)
+	}
+}
+
+// makeFederation federates the local repo, qualified by its own repo id if it
+// has one, with each of the remote repos given to -federate.
+func (cmd Show) makeFederation(
+	req command.Request,
+	localWorkingCopy *local_working_copy.Repo,
+) (federation *repo_federation.Federation) {
+	members := []repo_federation.Member{
+		{
+			RepoId: localWorkingCopy.GetImmutableConfigPublic().GetRepoId(),
+			Repo:   localWorkingCopy,
+		},
+	}
+
+	for _, repoId := range cmd.Federate {
+		remoteObject, err := localWorkingCopy.GetObjectFromObjectId(
+			repoId.StringWithSlashPrefix(),
+		)
+		if err != nil {
 			localWorkingCopy.Cancel(err)
 		}
+
+		remoteRepo, ok := cmd.MakeRemote(
+			req,
+			localWorkingCopy,
+			remoteObject,
+		).(repo.Repo)
+
+		if !ok {
+			localWorkingCopy.Cancel(
+				errors.BadRequestf(
+					"repo %q cannot be queried",
+					repoId.StringWithSlashPrefix(),
+				),
+			)
+		}
+
+		members = append(
+			members,
+			repo_federation.Member{RepoId: repoId, Repo: remoteRepo},
+		)
+	}
+
+	var err error
+
+	if federation, err = repo_federation.Make(members...); err != nil {
+		localWorkingCopy.Cancel(err)
 	}
+
+	return federation
 }
@@ -7,7 +7,9 @@ import (
 	"code.linenisgreat.com/dodder/go/internal/golf/sku"
 	"code.linenisgreat.com/dodder/go/internal/hotel/box_format"
 	pkg_query "code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
 	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 )
 
@@ -17,12 +19,29 @@ func init() {
 
 type Status struct {
 	command_components_dodder.LocalWorkingCopyWithQueryGroup
+
+	Parent bool
+}
+
+var _ interfaces.CommandComponentWriter = (*Status)(nil)
+
+func (cmd *Status) SetFlagDefinitions(flagSet interfaces.CLIFlagDefinitions) {
+	cmd.LocalWorkingCopyWithQueryGroup.SetFlagDefinitions(flagSet)
+
+	flagSet.BoolVar(
+		&cmd.Parent,
+		"parent",
+		false,
+		"also compare the workspace's blob store against the parent blob store it replaced",
+	)
 }
 
 func (cmd Status) Run(req command.Request) {
 	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
 	localWorkingCopy.GetEnvWorkspace().AssertNotTemporary(req)
 
+	args := req.PopArgs()
+
 	query := cmd.MakeQueryIncludingWorkspace(
 		req,
 		pkg_query.BuilderOptions(
@@ -31,7 +50,7 @@ func (cmd Status) Run(req command.Request) {
 			pkg_query.BuilderOptionHidden(nil),
 		),
 		localWorkingCopy,
-		req.PopArgs(),
+		args,
 	)
 
 	printer := localWorkingCopy.PrinterCheckedOut(
@@ -51,4 +70,65 @@ func (cmd Status) Run(req command.Request) {
 	); err != nil {
 		localWorkingCopy.Cancel(err)
 	}
+
+	if cmd.Parent {
+		cmd.printParentStatus(req, localWorkingCopy, args)
+	}
+}
+
+func (cmd Status) printParentStatus(
+	req command.Request,
+	localWorkingCopy *local_working_copy.Repo,
+	args []string,
+) {
+	query := cmd.MakeQueryIncludingWorkspace(
+		req,
+		pkg_query.BuilderOptions(
+			pkg_query.BuilderOptionWorkspace(localWorkingCopy),
+			pkg_query.BuilderOptionDefaultGenres(genres.Zettel),
+		),
+		localWorkingCopy,
+		args,
+	)
+
+	var status local_working_copy.ParentStatus
+
+	{
+		var err error
+
+		if status, err = localWorkingCopy.ParentStatus(query); err != nil {
+			localWorkingCopy.Cancel(err)
+			return
+		}
+	}
+
+	ui := localWorkingCopy.GetUI()
+
+	for _, object := range status.ModifiedLocally {
+		ui.Printf("%s (modified locally)", object.GetObjectId())
+	}
+
+	for _, object := range status.UpdatedInParent {
+		ui.Printf("%s (updated in parent)", object.GetObjectId())
+	}
+
+	for _, object := range status.MissingBlobs {
+		ui.Printf(
+			"%s (blob missing: %s)",
+			object.GetObjectId(),
+			object.GetBlobDigest(),
+		)
+	}
+
+	if status.IsEmpty() {
+		ui.Print("workspace is in sync with its parent")
+		return
+	}
+
+	ui.Printf(
+		"%d blobs to push-to-parent, %d objects updated in parent, %d objects with missing blobs",
+		len(status.UnpushedBlobs),
+		len(status.UpdatedInParent),
+		len(status.MissingBlobs),
+	)
 }
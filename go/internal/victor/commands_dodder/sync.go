@@ -0,0 +1,82 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+)
+
+func init() {
+	utility.AddCmd("sync", &Sync{})
+}
+
+type Sync struct {
+	command_components_dodder.LocalWorkingCopy
+	command_components_dodder.RemoteTransfer
+	command_components_dodder.Query
+}
+
+var _ interfaces.CommandComponentWriter = (*Sync)(nil)
+
+func (cmd *Sync) SetFlagDefinitions(flagSet interfaces.CLIFlagDefinitions) {
+	cmd.RemoteTransfer.SetFlagDefinitions(flagSet)
+	cmd.Query.SetFlagDefinitions(flagSet)
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+}
+
+func (cmd Sync) Run(req command.Request) {
+	local := cmd.MakeLocalWorkingCopy(req)
+
+	var remoteObject *sku.Transacted
+
+	{
+		var err error
+
+		if remoteObject, err = local.GetObjectFromObjectId(
+			req.PopArg("repo-id"),
+		); err != nil {
+			local.Cancel(err)
+		}
+	}
+
+	remote := cmd.MakeRemote(req, local, remoteObject)
+
+	queryGroup := cmd.MakeQueryIncludingWorkspace(
+		req,
+		queries.BuilderOptions(
+			queries.BuilderOptionDefaultSigil(
+				ids.SigilHistory,
+				ids.SigilHidden,
+			),
+			queries.BuilderOptionDefaultGenres(genres.InventoryList),
+		),
+		local,
+		req.PopArgs(),
+	)
+
+	var result local_working_copy.SyncResult
+
+	{
+		var err error
+
+		if result, err = local.Sync(
+			remote,
+			queryGroup,
+			cmd.WithPrintCopies(true),
+		); err != nil {
+			local.Cancel(err)
+			return
+		}
+	}
+
+	local.GetUI().Printf(
+		"synced: %d objects pulled, %d objects pushed",
+		result.Pulled,
+		result.Pushed,
+	)
+}
@@ -0,0 +1,125 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/papa/store"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd("trash", &Trash{})
+}
+
+type Trash struct {
+	command_components_dodder.LocalWorkingCopyWithQueryGroup
+}
+
+var _ interfaces.CommandComponentWriter = (*Trash)(nil)
+
+func (cmd Trash) GetDescription() command.Description {
+	return command.Description{
+		Short: "move objects to the trash",
+		Long: "Moves the objects matching the query to the trash by " +
+			"committing a version of each with the `dodder-trash` tag. " +
+			"Trashed objects are dormant, so they are hidden from default " +
+			"queries until they are restored with `restore`, and " +
+			"`empty-trash` deletes their blobs once the `trash.retention` " +
+			"of the repo config has passed. Without arguments, lists the " +
+			"objects in the trash, when they were trashed, and when they " +
+			"expire. `show %trash:?z` queries them like any other objects.",
+	}
+}
+
+func (cmd Trash) CompletionGenres() ids.Genre {
+	return ids.MakeGenre(
+		genres.Zettel,
+		genres.Tag,
+		genres.Type,
+		genres.Repo,
+	)
+}
+
+func (cmd Trash) Run(req command.Request) {
+	if req.RemainingArgCount() == 0 {
+		cmd.runList(cmd.MakeLocalWorkingCopy(req))
+		return
+	}
+
+	localWorkingCopy, queryGroup := cmd.MakeLocalWorkingCopyAndQueryGroup(
+		req,
+		queries.BuilderOptions(
+			queries.BuilderOptionDefaultGenres(genres.Zettel),
+		),
+	)
+
+	localWorkingCopy.Must(
+		errors.MakeFuncContextFromFuncErr(localWorkingCopy.Lock),
+	)
+
+	if err := localWorkingCopy.GetStore().QueryTransacted(
+		queryGroup,
+		func(object *sku.Transacted) (err error) {
+			switch object.GetGenre() {
+			case genres.Config, genres.InventoryList:
+				err = errors.BadRequestf(
+					"%s objects cannot be trashed: %s",
+					object.GetGenre(),
+					object.GetObjectId(),
+				)
+
+				return err
+			}
+
+			var ok bool
+
+			if ok, err = localWorkingCopy.GetStore().TrashObject(object); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			if ok {
+				ui.Out().Printf("trashed %s", sku.String(object))
+			}
+
+			return err
+		},
+	); err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	localWorkingCopy.Must(
+		errors.MakeFuncContextFromFuncErr(localWorkingCopy.Unlock),
+	)
+}
+
+func (cmd Trash) runList(repo *local_working_copy.Repo) {
+	retention, err := repo.GetConfig().GetTrash().GetRetention()
+	if err != nil {
+		repo.Cancel(err)
+		return
+	}
+
+	trashed, err := repo.GetStore().AllTrashed()
+	if err != nil {
+		repo.Cancel(err)
+		return
+	}
+
+	for _, object := range trashed {
+		ui.Out().Printf(
+			"%s trashed %s, expires %s",
+			object.GetObjectId(),
+			object.GetTai().StringBoxFormat(),
+			store.GetTrashExpiry(object, retention).StringBoxFormat(),
+		)
+	}
+}
@@ -0,0 +1,88 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/_/domain_interfaces"
+	"code.linenisgreat.com/dodder/go/internal/bravo/markl"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd("trust-repo-pubkey", &TrustRepoPubKey{})
+}
+
+// TrustRepoPubKey adds repo pubkeys to the trust store consulted when pulling,
+// syncing, and importing signed objects. Without arguments, it prints the
+// trusted pubkeys, starting with this repo's own.
+type TrustRepoPubKey struct {
+	command_components_dodder.LocalWorkingCopy
+}
+
+func (cmd TrustRepoPubKey) Run(req command.Request) {
+	args := req.PopArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+	envRepo := localWorkingCopy.GetEnvRepo()
+
+	if len(args) == 0 {
+		var trusted []markl.Id
+
+		{
+			var err error
+
+			if trusted, err = envRepo.GetTrustedRepoPubKeys(); err != nil {
+				localWorkingCopy.Cancel(err)
+				return
+			}
+		}
+
+		for _, pubKey := range trusted {
+			localWorkingCopy.GetUI().Print(pubKey.StringWithFormat())
+		}
+
+		return
+	}
+
+	pubKeys := make([]domain_interfaces.MarklId, len(args))
+
+	for i, arg := range args {
+		var pubKey markl.Id
+
+		if err := pubKey.Set(arg); err != nil {
+			errors.ContextCancelWithBadRequestf(
+				localWorkingCopy,
+				"invalid repo pubkey %q: %s",
+				arg,
+				err,
+			)
+
+			return
+		}
+
+		pubKeys[i] = pubKey
+	}
+
+	if err := localWorkingCopy.Lock(); err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	var added int
+
+	{
+		var err error
+
+		if added, err = envRepo.AddTrustedRepoPubKeys(pubKeys...); err != nil {
+			localWorkingCopy.Cancel(err)
+			return
+		}
+	}
+
+	if err := localWorkingCopy.Unlock(); err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	localWorkingCopy.GetUI().Printf("trusted %d new repo pubkeys", added)
+}
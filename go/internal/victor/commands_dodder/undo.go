@@ -0,0 +1,101 @@
+package commands_dodder
+
+import (
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func init() {
+	utility.AddCmd("undo", &Undo{})
+}
+
+type Undo struct {
+	command_components_dodder.LocalWorkingCopy
+
+	Hard bool
+	Soft bool
+}
+
+var _ interfaces.CommandComponentWriter = (*Undo)(nil)
+
+func (cmd Undo) GetDescription() command.Description {
+	return command.Description{
+		Short: "remove the most recent inventory list",
+		Long: "Removes the last inventory list from the inventory list log, " +
+			"provided none of its objects has a later version, and records " +
+			"it as an orphan so its blob can be collected. With -hard, the " +
+			"default, the indexes are rebuilt so the versions it added are " +
+			"gone. With -soft, only the log is rewound, and the indexes keep " +
+			"those versions until the next reindex. With -dry-run, the list " +
+			"and its objects are printed without removing anything.",
+	}
+}
+
+func (cmd *Undo) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.BoolVar(
+		&cmd.Hard,
+		"hard",
+		false,
+		"rebuild the indexes without the versions the list added (default)",
+	)
+
+	flagSet.BoolVar(
+		&cmd.Soft,
+		"soft",
+		false,
+		"only rewind the inventory list log, keeping the indexes",
+	)
+}
+
+func (cmd Undo) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	if cmd.Hard && cmd.Soft {
+		errors.ContextCancelWithBadRequestf(
+			req,
+			"-hard and -soft cannot be used together",
+		)
+	}
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	list, objects, err := localWorkingCopy.GetStore().ReadUndoableInventoryList()
+	if err != nil {
+		localWorkingCopy.Cancel(err)
+		return
+	}
+
+	verb := "undid"
+
+	if localWorkingCopy.GetConfig().IsDryRun() {
+		verb = "would undo"
+	} else {
+		localWorkingCopy.UndoInventoryList(list, !cmd.Soft)
+	}
+
+	for _, object := range objects {
+		ui.Out().Printf("%s %s", verb, sku.String(object))
+	}
+
+	ui.Err().Printf(
+		"%s inventory list %s with %d objects",
+		verb,
+		list.GetTai(),
+		len(objects),
+	)
+
+	if cmd.Soft && !localWorkingCopy.GetConfig().IsDryRun() {
+		ui.Err().Print(
+			"the indexes still have the versions the list added; " +
+				"run `dodder reindex` to drop them",
+		)
+	}
+}
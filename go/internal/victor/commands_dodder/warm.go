@@ -0,0 +1,112 @@
+package commands_dodder
+
+import (
+	"fmt"
+	"os"
+
+	"code.linenisgreat.com/dodder/go/internal/charlie/tap_diagnostics"
+	"code.linenisgreat.com/dodder/go/internal/foxtrot/blob_stores"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+	tap "github.com/amarbel-llc/purse-first/packages/tap-dancer/go"
+)
+
+func init() {
+	utility.AddCmd("warm", &Warm{})
+}
+
+type Warm struct {
+	command_components_dodder.LocalWorkingCopy
+
+	Archives bool
+}
+
+var _ interfaces.CommandComponentWriter = (*Warm)(nil)
+
+func (cmd Warm) GetDescription() command.Description {
+	return command.Description{
+		Short: "preload the indexes that the first query would load",
+		Long: "Makes sure every archive blob store has a valid index cache, " +
+			"rebuilding a stale or corrupt one, and reads the probe index " +
+			"through, checking its pages, so that the next command finds both " +
+			"in the page cache. With -archives, the hot archives are read into " +
+			"the page cache too, which does not count as reading them for " +
+			"demotion. Meant for login scripts and cron.",
+	}
+}
+
+func (cmd *Warm) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.BoolVar(
+		&cmd.Archives,
+		"archives",
+		false,
+		"also read hot archives into the page cache",
+	)
+}
+
+func (cmd Warm) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopy(req)
+
+	tw := tap.NewWriter(os.Stdout)
+
+	for _, blobStore := range localWorkingCopy.GetEnvRepo().GetBlobStoresSorted() {
+		storeId := blobStore.Path.GetId().String()
+
+		report, ok, err := blob_stores.WarmBlobStore(
+			blobStore.BlobStore,
+			blob_stores.WarmOptions{PageCache: cmd.Archives},
+		)
+
+		if !ok {
+			tw.Skip(storeId, "no archives")
+			continue
+		}
+
+		if err != nil {
+			tw.NotOk(
+				fmt.Sprintf("%s index cache", storeId),
+				tap_diagnostics.FromError(err),
+			)
+
+			continue
+		}
+
+		description := fmt.Sprintf(
+			"%s index cache, %d entries",
+			storeId,
+			report.IndexEntries,
+		)
+
+		if report.CacheRebuilt {
+			description += ", rebuilt"
+		}
+
+		tw.Ok(description)
+
+		if cmd.Archives {
+			tw.Ok(fmt.Sprintf(
+				"%s page cache, %d files, %s",
+				storeId,
+				report.FilesRead,
+				ui.GetHumanBytesString(uint64(report.BytesRead)),
+			))
+		}
+	}
+
+	rows, err := localWorkingCopy.GetStore().GetStreamIndex().WarmProbeIndex()
+	if err != nil {
+		tw.NotOk("probe index", tap_diagnostics.FromError(err))
+	} else {
+		tw.Ok(fmt.Sprintf("probe index, %d rows", rows))
+	}
+
+	tw.Plan()
+}
@@ -0,0 +1,390 @@
+package commands_dodder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/internal/alfa/fs_watcher"
+	"code.linenisgreat.com/dodder/go/internal/alfa/genres"
+	"code.linenisgreat.com/dodder/go/internal/bravo/ids"
+	"code.linenisgreat.com/dodder/go/internal/delta/env_ui"
+	"code.linenisgreat.com/dodder/go/internal/golf/command"
+	"code.linenisgreat.com/dodder/go/internal/golf/env_repo"
+	"code.linenisgreat.com/dodder/go/internal/golf/sku"
+	"code.linenisgreat.com/dodder/go/internal/kilo/queries"
+	"code.linenisgreat.com/dodder/go/internal/mike/store_fs"
+	"code.linenisgreat.com/dodder/go/internal/sierra/local_working_copy"
+	"code.linenisgreat.com/dodder/go/internal/tango/user_ops"
+	"code.linenisgreat.com/dodder/go/internal/uniform/command_components_dodder"
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+func init() {
+	utility.AddCmd("watch", &Watch{})
+}
+
+const fileWatchLog = ".dodder-watch.log"
+
+type Watch struct {
+	command_components_dodder.LocalWorkingCopy
+
+	PollInterval  time.Duration
+	Debounce      time.Duration
+	MaxBatchDelay time.Duration
+	Excludes      []string
+	LogPath       string
+	MaxBatches    int
+}
+
+var _ interfaces.CommandComponentWriter = (*Watch)(nil)
+
+func (cmd Watch) GetDescription() command.Description {
+	return command.Description{
+		Short: "check in workspace changes as they are saved",
+	}
+}
+
+func (cmd *Watch) SetFlagDefinitions(
+	flagSet interfaces.CLIFlagDefinitions,
+) {
+	cmd.LocalWorkingCopy.SetFlagDefinitions(flagSet)
+
+	flagSet.Func(
+		"poll",
+		"how often to scan the workspace for changes (default 500ms)",
+		durationFlagFunc(&cmd.PollInterval),
+	)
+
+	flagSet.Func(
+		"debounce",
+		"how long the workspace has to stay unchanged before checking in (default 1s)",
+		durationFlagFunc(&cmd.Debounce),
+	)
+
+	flagSet.Func(
+		"max-batch-delay",
+		"check in at the latest this long after the first pending change (default 30s)",
+		durationFlagFunc(&cmd.MaxBatchDelay),
+	)
+
+	flagSet.Func(
+		"exclude",
+		"glob of paths not to check in, in .dodderignore syntax (repeatable)",
+		func(value string) (err error) {
+			cmd.Excludes = append(cmd.Excludes, value)
+			return err
+		},
+	)
+
+	flagSet.StringVar(
+		&cmd.LogPath,
+		"log",
+		"",
+		"file to append one JSON line per checkin to (default "+fileWatchLog+" in the workspace)",
+	)
+
+	flagSet.IntVar(
+		&cmd.MaxBatches,
+		"max-batches",
+		0,
+		"stop after this many checkins (0 to watch until interrupted)",
+	)
+}
+
+func durationFlagFunc(duration *time.Duration) func(string) error {
+	return func(value string) (err error) {
+		if *duration, err = time.ParseDuration(value); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		return err
+	}
+}
+
+// watchLogEntry is one line of the watch log, written once a batch of
+// changes has been checked in, or has failed to be.
+type watchLogEntry struct {
+	Time    string   `json:"time"`
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed,omitempty"`
+	Objects []string `json:"objects"`
+	Error   string   `json:"error,omitempty"`
+}
+
+func (cmd Watch) Run(req command.Request) {
+	req.AssertNoMoreArgs()
+
+	localWorkingCopy := cmd.MakeLocalWorkingCopyWithOptions(
+		req,
+		env_ui.Options{},
+		local_working_copy.OptionsWithoutSharedAccess,
+	)
+	envWorkspace := localWorkingCopy.GetEnvWorkspace()
+	envWorkspace.AssertNotTemporary(req)
+
+	workspaceDir := envWorkspace.GetWorkspaceDir()
+
+	var exclude func(string, bool) bool
+
+	{
+		var err error
+
+		if exclude, err = cmd.makeExclude(
+			envWorkspace.GetStoreFS(),
+			workspaceDir,
+		); err != nil {
+			req.Cancel(err)
+			return
+		}
+	}
+
+	var watcher *fs_watcher.Watcher
+
+	{
+		var err error
+
+		if watcher, err = fs_watcher.Make(fs_watcher.Options{
+			Root:          workspaceDir,
+			PollInterval:  cmd.PollInterval,
+			Debounce:      cmd.Debounce,
+			MaxBatchDelay: cmd.MaxBatchDelay,
+			Exclude:       exclude,
+		}); err != nil {
+			req.Cancel(err)
+			return
+		}
+	}
+
+	logPath := cmd.LogPath
+
+	if logPath == "" {
+		logPath = filepath.Join(workspaceDir, fileWatchLog)
+	}
+
+	logFile, err := os.OpenFile(
+		logPath,
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0o666,
+	)
+	if err != nil {
+		req.Cancel(errors.Wrap(err))
+		return
+	}
+
+	defer errors.ContextMustClose(req, logFile)
+
+	batches := 0
+
+	if err := watcher.Watch(
+		req,
+		func(batch fs_watcher.Batch) (err error) {
+			if err = cmd.checkinBatch(
+				localWorkingCopy,
+				workspaceDir,
+				logFile,
+				batch,
+			); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+			batches++
+
+			if cmd.MaxBatches > 0 && batches >= cmd.MaxBatches {
+				err = errors.MakeErrStopIteration()
+				return err
+			}
+
+			return err
+		},
+	); err != nil && !errors.IsStopIteration(err) {
+		req.Cancel(err)
+		return
+	}
+}
+
+// makeExclude skips nested workspaces, the workspace's ignore rules, and the
+// -exclude globs.
+func (cmd Watch) makeExclude(
+	storeFS *store_fs.Store,
+	workspaceDir string,
+) (exclude func(string, bool) bool, err error) {
+	var ignoreRules, excludeRules store_fs.IgnoreRules
+
+	if ignoreRules, err = store_fs.ReadIgnoreRules(
+		storeFS.GetFsOps(),
+		workspaceDir,
+	); err != nil {
+		err = errors.Wrap(err)
+		return exclude, err
+	}
+
+	if excludeRules, err = store_fs.MakeIgnoreRules(cmd.Excludes...); err != nil {
+		err = errors.Wrap(err)
+		return exclude, err
+	}
+
+	exclude = func(rel string, isDir bool) bool {
+		if isDir {
+			nestedWorkspace := filepath.Join(
+				workspaceDir,
+				filepath.FromSlash(rel),
+				env_repo.FileWorkspace,
+			)
+
+			if _, err := os.Stat(nestedWorkspace); err == nil {
+				return true
+			}
+		}
+
+		return ignoreRules.IsIgnored(rel, isDir) ||
+			excludeRules.IsIgnored(rel, isDir)
+	}
+
+	return exclude, err
+}
+
+// checkinBatch checks in the changed files of batch as one transaction and
+// logs the result. Removed files are logged but their objects are kept. The
+// repo is reset first so that it sees the workspace as it is now.
+func (cmd Watch) checkinBatch(
+	repo *local_working_copy.Repo,
+	workspaceDir string,
+	logFile *os.File,
+	batch fs_watcher.Batch,
+) (err error) {
+	entry := watchLogEntry{
+		Changed: batch.Changed,
+		Removed: batch.Removed,
+		Objects: []string{},
+	}
+
+	if entry.Changed == nil {
+		entry.Changed = []string{}
+	}
+
+	if len(batch.Changed) > 0 {
+		var objects []string
+
+		if objects, err = cmd.checkinPaths(
+			repo,
+			workspaceDir,
+			batch.Changed,
+		); err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.Objects = objects
+		}
+	}
+
+	entry.Time = time.Now().UTC().Format(time.RFC3339Nano)
+
+	if logErr := cmd.writeLogEntry(logFile, entry); logErr != nil {
+		err = errors.Join(err, logErr)
+	}
+
+	if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (cmd Watch) checkinPaths(
+	repo *local_working_copy.Repo,
+	workspaceDir string,
+	paths []string,
+) (objects []string, err error) {
+	if err = repo.Reset(); err != nil {
+		err = errors.Wrap(err)
+		return objects, err
+	}
+
+	cwd := repo.GetEnvWorkspace().GetStoreFS().GetFsOps().GetCwd()
+	args := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		var arg string
+
+		if arg, err = filepath.Rel(
+			cwd,
+			filepath.Join(workspaceDir, filepath.FromSlash(path)),
+		); err != nil {
+			err = errors.Wrap(err)
+			return objects, err
+		}
+
+		args = append(args, arg)
+	}
+
+	var query *queries.Query
+
+	if query, err = repo.MakeExternalQueryGroup(
+		queries.BuilderOptions(
+			queries.BuilderOptionDefaultSigil(ids.SigilExternal),
+			queries.BuilderOptionDefaultGenres(genres.All()...),
+			queries.BuilderOptionWorkspace(repo),
+		),
+		sku.ExternalQueryOptions{},
+		args...,
+	); err != nil {
+		err = errors.Wrap(err)
+		return objects, err
+	}
+
+	op := user_ops.Checkin{
+		Proto: sku.MakeProto(repo.GetEnvWorkspace().GetDefaults()),
+	}
+
+	var results sku.SkuTypeSetMutable
+
+	if results, err = op.RunAndGetResults(repo, query); err != nil {
+		err = errors.Wrap(err)
+		return objects, err
+	}
+
+	objects = make([]string, 0, results.Len())
+
+	for checkedOut := range results.All() {
+		objects = append(
+			objects,
+			checkedOut.GetSkuExternal().GetObjectId().String(),
+		)
+	}
+
+	slices.Sort(objects)
+
+	return objects, err
+}
+
+func (cmd Watch) writeLogEntry(
+	logFile *os.File,
+	entry watchLogEntry,
+) (err error) {
+	var line []byte
+
+	if line, err = json.Marshal(entry); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	line = append(line, '\n')
+
+	if _, err = logFile.Write(line); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = logFile.Sync(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
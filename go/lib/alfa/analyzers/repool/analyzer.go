@@ -9,6 +9,17 @@
 // once when the caller is done with the pooled object, or the object
 // will leak. Discarding the repool function with a blank identifier
 // is reported unless suppressed with a //repool:owned comment.
+//
+// Calls to any function that returns a FuncRepool are checked like
+// GetWithRepool. Functions annotated with //repool:transfers in their doc
+// comment also hand ownership to their caller when they return a struct
+// holding a FuncRepool field, which the caller must then call. The annotation
+// is exported as a fact so callers in other packages are also checked. No
+// function in this module returns such a struct yet, so nothing is annotated.
+//
+// Storing a repool function into a struct field, slice, or map also transfers
+// ownership when the receiving type is annotated with //repool:stored. With
+// -require-stored-marker=false, every such store does.
 package repool
 
 import (
@@ -22,13 +33,47 @@ import (
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
 	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/types/typeutil"
 )
 
 const (
 	funcRepoolTypeName  = "FuncRepool"
 	funcRepoolPkgSuffix = "interfaces"
+
+	annotationOwned     = "//repool:owned"
+	annotationTransfers = "//repool:transfers"
+	annotationStored    = "//repool:stored"
 )
 
+var requireStoredMarker bool
+
+func init() {
+	Analyzer.Flags.BoolVar(
+		&requireStoredMarker,
+		"require-stored-marker",
+		true,
+		"only treat storing a repool function into a field or collection as an ownership transfer when the receiving type is annotated with "+annotationStored,
+	)
+}
+
+// transfersFact marks a function annotated with //repool:transfers.
+type transfersFact struct{}
+
+func (*transfersFact) AFact() {}
+
+func (*transfersFact) String() string {
+	return "repool:transfers"
+}
+
+// storedFact marks a type annotated with //repool:stored.
+type storedFact struct{}
+
+func (*storedFact) AFact() {}
+
+func (*storedFact) String() string {
+	return "repool:stored"
+}
+
 var Analyzer = &analysis.Analyzer{
 	Name: "repool",
 	Doc:  "check FuncRepool returned by GetWithRepool is called",
@@ -37,6 +82,10 @@ var Analyzer = &analysis.Analyzer{
 		inspect.Analyzer,
 		ctrlflow.Analyzer,
 	},
+	FactTypes: []analysis.Fact{
+		new(transfersFact),
+		new(storedFact),
+	},
 }
 
 func run(pass *analysis.Pass) (any, error) {
@@ -47,6 +96,23 @@ func run(pass *analysis.Pass) (any, error) {
 	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
 
+	// facts must be exported before any function body is checked so that
+	// calls to annotated functions later in the same package are tracked
+	factNodeTypes := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.GenDecl)(nil),
+	}
+
+	ins.Preorder(factNodeTypes, func(n ast.Node) {
+		switch decl := n.(type) {
+		case *ast.FuncDecl:
+			exportTransfersFact(pass, decl)
+
+		case *ast.GenDecl:
+			exportStoredFacts(pass, decl)
+		}
+	})
+
 	nodeTypes := []ast.Node{
 		(*ast.FuncDecl)(nil),
 		(*ast.FuncLit)(nil),
@@ -99,7 +165,7 @@ func checkAssign(pass *analysis.Pass, cfgs *ctrlflow.CFGs, funcNode ast.Node, st
 		return
 	}
 
-	idx := repoolResultIndex(pass, call)
+	idx, field := ownedResultIndex(pass, call)
 	if idx < 0 || idx >= len(stmt.Lhs) {
 		return
 	}
@@ -110,13 +176,7 @@ func checkAssign(pass *analysis.Pass, cfgs *ctrlflow.CFGs, funcNode ast.Node, st
 	}
 
 	if id.Name == "_" {
-		if hasRepoolOwnedComment(pass, stmt) {
-			return
-		}
-
-		pass.ReportRangef(id,
-			"the repool function returned by %s should be called, not discarded, to avoid a pool leak",
-			callName(call))
+		reportDiscarded(pass, stmt, id, call)
 		return
 	}
 
@@ -128,7 +188,7 @@ func checkAssign(pass *analysis.Pass, cfgs *ctrlflow.CFGs, funcNode ast.Node, st
 		}
 	}
 
-	checkVarUsedOnAllPaths(pass, cfgs, funcNode, stmt, v)
+	checkVarUsedOnAllPaths(pass, cfgs, funcNode, stmt, v, field)
 }
 
 func checkValueSpec(pass *analysis.Pass, cfgs *ctrlflow.CFGs, funcNode ast.Node, spec *ast.ValueSpec) {
@@ -141,7 +201,7 @@ func checkValueSpec(pass *analysis.Pass, cfgs *ctrlflow.CFGs, funcNode ast.Node,
 		return
 	}
 
-	idx := repoolResultIndex(pass, call)
+	idx, field := ownedResultIndex(pass, call)
 	if idx < 0 || idx >= len(spec.Names) {
 		return
 	}
@@ -149,22 +209,121 @@ func checkValueSpec(pass *analysis.Pass, cfgs *ctrlflow.CFGs, funcNode ast.Node,
 	id := spec.Names[idx]
 
 	if id.Name == "_" {
-		if hasRepoolOwnedComment(pass, spec) {
-			return
+		reportDiscarded(pass, spec, id, call)
+		return
+	}
+
+	v, ok := pass.TypesInfo.Defs[id].(*types.Var)
+	if !ok {
+		return
+	}
+
+	checkVarUsedOnAllPaths(pass, cfgs, funcNode, spec, v, field)
+}
+
+func exportStoredFacts(pass *analysis.Pass, decl *ast.GenDecl) {
+	if decl.Tok != token.TYPE {
+		return
+	}
+
+	for _, spec := range decl.Specs {
+		typeSpec := spec.(*ast.TypeSpec)
+
+		doc := typeSpec.Doc
+
+		if doc == nil && len(decl.Specs) == 1 {
+			doc = decl.Doc
+		}
+
+		if doc == nil || !commentGroupContains(doc, annotationStored) {
+			continue
+		}
+
+		if typeName, ok := pass.TypesInfo.Defs[typeSpec.Name].(*types.TypeName); ok {
+			pass.ExportObjectFact(typeName, new(storedFact))
 		}
+	}
+}
 
-		pass.ReportRangef(id,
-			"the repool function returned by %s should be called, not discarded, to avoid a pool leak",
-			callName(call))
+func reportDiscarded(pass *analysis.Pass, node ast.Node, id *ast.Ident, call *ast.CallExpr) {
+	if hasAnnotationComment(pass, node, annotationOwned) {
 		return
 	}
 
-	v, ok := pass.TypesInfo.Defs[id].(*types.Var)
+	pass.ReportRangef(id,
+		"the repool function returned by %s should be called, not discarded, to avoid a pool leak",
+		callName(call))
+}
+
+// ownedResultIndex returns the index of the call result that the caller
+// becomes responsible for repooling, or -1. If the repool function is held in
+// a struct field of that result, the field is also returned.
+func ownedResultIndex(pass *analysis.Pass, call *ast.CallExpr) (int, *types.Var) {
+	if idx := repoolResultIndex(pass, call); idx >= 0 {
+		return idx, nil
+	}
+
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || !pass.ImportObjectFact(fn, new(transfersFact)) {
+		return -1, nil
+	}
+
+	results := fn.Signature().Results()
+
+	for i := range results.Len() {
+		if field := funcRepoolField(results.At(i).Type()); field != nil {
+			return i, field
+		}
+	}
+
+	return -1, nil
+}
+
+// funcRepoolField returns the first FuncRepool field of a struct or pointer
+// to struct type.
+func funcRepoolField(t types.Type) *types.Var {
+	if pointer, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+
+	structType, ok := t.Underlying().(*types.Struct)
 	if !ok {
+		return nil
+	}
+
+	for i := range structType.NumFields() {
+		if field := structType.Field(i); isFuncRepoolType(field.Type()) {
+			return field
+		}
+	}
+
+	return nil
+}
+
+func exportTransfersFact(pass *analysis.Pass, decl *ast.FuncDecl) {
+	if decl.Doc == nil || !commentGroupContains(decl.Doc, annotationTransfers) {
 		return
 	}
 
-	checkVarUsedOnAllPaths(pass, cfgs, funcNode, spec, v)
+	fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+	if !ok {
+		return
+	}
+
+	results := fn.Signature().Results()
+
+	for i := range results.Len() {
+		resultType := results.At(i).Type()
+
+		if isFuncRepoolType(resultType) || funcRepoolField(resultType) != nil {
+			pass.ExportObjectFact(fn, new(transfersFact))
+			return
+		}
+	}
+
+	pass.ReportRangef(decl.Name,
+		"%s is annotated with %s but does not return a FuncRepool or a struct containing one",
+		decl.Name.Name, annotationTransfers)
 }
 
 func checkVarUsedOnAllPaths(
@@ -173,6 +332,7 @@ func checkVarUsedOnAllPaths(
 	funcNode ast.Node,
 	defStmt ast.Node,
 	v *types.Var,
+	field *types.Var,
 ) {
 	var g *cfg.CFG
 
@@ -207,7 +367,7 @@ outer:
 	}
 
 	// Is v used in the rest of its defining block?
-	if usesVar(pass, v, rest) {
+	if usesVar(pass, v, field, rest) {
 		return
 	}
 
@@ -220,20 +380,26 @@ outer:
 
 	// Search depth-first for a path to return without using v.
 	seen := make(map[*cfg.Block]bool)
-	if ret := searchUnused(pass, v, defblock.Succs, seen); ret != nil {
+	if ret := searchUnused(pass, v, field, defblock.Succs, seen); ret != nil {
 		pass.ReportRangef(defStmt,
 			"the repool function is not called on all paths (possible pool leak)")
 	}
 }
 
-func searchUnused(pass *analysis.Pass, v *types.Var, blocks []*cfg.Block, seen map[*cfg.Block]bool) *cfg.Block {
+func searchUnused(
+	pass *analysis.Pass,
+	v *types.Var,
+	field *types.Var,
+	blocks []*cfg.Block,
+	seen map[*cfg.Block]bool,
+) *cfg.Block {
 	for _, b := range blocks {
 		if seen[b] {
 			continue
 		}
 		seen[b] = true
 
-		if blockUsesVar(pass, v, b) {
+		if blockUsesVar(pass, v, field, b) {
 			continue
 		}
 
@@ -243,7 +409,7 @@ func searchUnused(pass *analysis.Pass, v *types.Var, blocks []*cfg.Block, seen m
 			return b
 		}
 
-		if found := searchUnused(pass, v, b.Succs, seen); found != nil {
+		if found := searchUnused(pass, v, field, b.Succs, seen); found != nil {
 			return found
 		}
 	}
@@ -251,29 +417,181 @@ func searchUnused(pass *analysis.Pass, v *types.Var, blocks []*cfg.Block, seen m
 	return nil
 }
 
-func usesVar(pass *analysis.Pass, v *types.Var, stmts []ast.Node) bool {
+// usesVar reports whether v is handled in stmts: called, deferred, passed to
+// a function, returned, or stored somewhere that takes ownership. If field is
+// non-nil, v holds the repool function in that field, and selecting the field
+// also counts.
+func usesVar(pass *analysis.Pass, v *types.Var, field *types.Var, stmts []ast.Node) bool {
 	for _, stmt := range stmts {
 		found := false
+		var stack []ast.Node
+
 		ast.Inspect(stmt, func(n ast.Node) bool {
 			if found {
 				return false
 			}
-			if id, ok := n.(*ast.Ident); ok {
-				if pass.TypesInfo.Uses[id] == v {
-					found = true
+
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return false
+			}
+
+			switch node := n.(type) {
+			case *ast.SelectorExpr:
+				if id, ok := node.X.(*ast.Ident); ok && field != nil && pass.TypesInfo.Uses[id] == v {
+					found = pass.TypesInfo.Uses[node.Sel] == field
+					return false
+				}
+
+			case *ast.Ident:
+				if pass.TypesInfo.Uses[node] == v {
+					found = handlesUse(pass, node, stack)
 				}
+
+				return false
 			}
-			return !found
+
+			stack = append(stack, n)
+
+			return true
 		})
+
 		if found {
 			return true
 		}
 	}
+
 	return false
 }
 
-func blockUsesVar(pass *analysis.Pass, v *types.Var, b *cfg.Block) bool {
-	return usesVar(pass, v, b.Nodes)
+// handlesUse reports whether the use of a repool variable at expr, whose
+// ancestors are stack, takes care of calling it or hands it off to something
+// that will.
+func handlesUse(pass *analysis.Pass, expr ast.Expr, stack []ast.Node) bool {
+	for len(stack) > 0 {
+		paren, ok := stack[len(stack)-1].(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+
+		expr = paren
+		stack = stack[:len(stack)-1]
+	}
+
+	if len(stack) == 0 {
+		return true
+	}
+
+	switch parent := stack[len(stack)-1].(type) {
+	case *ast.CallExpr:
+		if parent.Fun == expr {
+			return true
+		}
+
+		if isBuiltinAppend(pass, parent) && len(parent.Args) > 0 && parent.Args[0] != expr {
+			return storesInto(pass, parent.Args[0])
+		}
+
+		return true
+
+	case *ast.CompositeLit:
+		return storesInto(pass, parent)
+
+	case *ast.KeyValueExpr:
+		if parent.Value != expr {
+			return false
+		}
+
+		if len(stack) > 1 {
+			if literal, ok := stack[len(stack)-2].(*ast.CompositeLit); ok {
+				return storesInto(pass, literal)
+			}
+		}
+
+		return true
+
+	case *ast.AssignStmt:
+		for i, rhs := range parent.Rhs {
+			if rhs != expr || len(parent.Lhs) != len(parent.Rhs) {
+				continue
+			}
+
+			switch lhs := parent.Lhs[i].(type) {
+			case *ast.Ident:
+				return lhs.Name != "_"
+
+			case *ast.SelectorExpr, *ast.IndexExpr:
+				return storesInto(pass, lhs)
+			}
+
+			return true
+		}
+
+		// reassigning the variable itself does not handle it
+		return false
+
+	case *ast.BinaryExpr:
+		return false
+	}
+
+	return true
+}
+
+func isBuiltinAppend(pass *analysis.Pass, call *ast.CallExpr) bool {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	builtin, ok := pass.TypesInfo.Uses[id].(*types.Builtin)
+
+	return ok && builtin.Name() == "append"
+}
+
+// storesInto reports whether storing a repool function into destination
+// transfers ownership: the struct owning the field, or the collection type
+// itself, must be annotated with //repool:stored, unless
+// -require-stored-marker=false, in which case every store does.
+func storesInto(pass *analysis.Pass, destination ast.Expr) bool {
+	if !requireStoredMarker {
+		return true
+	}
+
+	var receiver types.Type
+
+	switch expr := destination.(type) {
+	case *ast.SelectorExpr:
+		if selection, ok := pass.TypesInfo.Selections[expr]; ok && selection.Kind() == types.FieldVal {
+			receiver = selection.Recv()
+		} else {
+			receiver = pass.TypesInfo.TypeOf(expr)
+		}
+
+	case *ast.IndexExpr:
+		return storesInto(pass, expr.X)
+
+	default:
+		receiver = pass.TypesInfo.TypeOf(expr)
+	}
+
+	if receiver == nil {
+		return false
+	}
+
+	if pointer, ok := types.Unalias(receiver).(*types.Pointer); ok {
+		receiver = pointer.Elem()
+	}
+
+	named, ok := types.Unalias(receiver).(*types.Named)
+	if !ok {
+		return false
+	}
+
+	return pass.ImportObjectFact(named.Origin().Obj(), new(storedFact))
+}
+
+func blockUsesVar(pass *analysis.Pass, v *types.Var, field *types.Var, b *cfg.Block) bool {
+	return usesVar(pass, v, field, b.Nodes)
 }
 
 // repoolResultIndex returns the index within the result tuple that is
@@ -321,23 +639,40 @@ func isFuncRepoolType(t types.Type) bool {
 	return strings.HasSuffix(pkg.Path(), funcRepoolPkgSuffix)
 }
 
+// importsFuncRepool reports whether pkg depends on FuncRepool, directly or via
+// an imported package whose functions may transfer repool ownership.
 func importsFuncRepool(pkg *types.Package) bool {
+	return importsFuncRepoolVisited(pkg, make(map[*types.Package]bool))
+}
+
+func importsFuncRepoolVisited(pkg *types.Package, seen map[*types.Package]bool) bool {
+	if seen[pkg] {
+		return false
+	}
+
+	seen[pkg] = true
+
 	for _, imp := range pkg.Imports() {
 		if strings.HasSuffix(imp.Path(), funcRepoolPkgSuffix) {
 			return true
 		}
+
+		if importsFuncRepoolVisited(imp, seen) {
+			return true
+		}
 	}
 	return false
 }
 
-func hasRepoolOwnedComment(pass *analysis.Pass, node ast.Node) bool {
+func hasAnnotationComment(pass *analysis.Pass, node ast.Node, annotation string) bool {
 	pos := pass.Fset.Position(node.Pos())
 
 	for _, cg := range pass.Files {
 		for _, c := range cg.Comments {
 			for _, comment := range c.List {
 				cpos := pass.Fset.Position(comment.Pos())
-				if cpos.Line == pos.Line && strings.Contains(comment.Text, "//repool:owned") {
+				if cpos.Filename == pos.Filename && cpos.Line == pos.Line &&
+					strings.Contains(comment.Text, annotation) {
 					return true
 				}
 			}
@@ -347,6 +682,16 @@ func hasRepoolOwnedComment(pass *analysis.Pass, node ast.Node) bool {
 	return false
 }
 
+func commentGroupContains(group *ast.CommentGroup, annotation string) bool {
+	for _, comment := range group.List {
+		if strings.HasPrefix(comment.Text, annotation) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func callName(call *ast.CallExpr) string {
 	switch fn := call.Fun.(type) {
 	case *ast.SelectorExpr:
@@ -9,5 +9,16 @@ import (
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
-	analysistest.Run(t, testdata, repool.Analyzer, "a")
+	analysistest.Run(t, testdata, repool.Analyzer, "a", "b", "c")
+}
+
+func TestWithoutStoredMarker(t *testing.T) {
+	if err := repool.Analyzer.Flags.Set("require-stored-marker", "false"); err != nil {
+		t.Fatal(err)
+	}
+
+	defer repool.Analyzer.Flags.Set("require-stored-marker", "true")
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, repool.Analyzer, "d")
 }
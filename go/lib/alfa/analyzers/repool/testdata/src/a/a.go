@@ -44,11 +44,12 @@ func consume(fn interfaces.FuncRepool) {
 	fn()
 }
 
-func assignedToStruct() {
-	type holder struct {
-		fn interfaces.FuncRepool
-	}
+//repool:stored
+type holder struct { // want holder:"repool:stored"
+	fn interfaces.FuncRepool
+}
 
+func assignedToStruct() {
 	_, repool := pool.GetWithRepool()
 	h := holder{fn: repool}
 	_ = h
@@ -62,3 +63,77 @@ func multipleReturns() {
 	}
 	repool()
 }
+
+//repool:stored
+type Borrowed struct { // want Borrowed:"repool:stored"
+	Value  string
+	Repool interfaces.FuncRepool
+}
+
+// GetBorrowed hands the repool function to the caller via a struct field.
+//
+//repool:transfers
+func GetBorrowed() Borrowed { // want GetBorrowed:"repool:transfers"
+	value, repool := pool.GetWithRepool()
+	return Borrowed{Value: value, Repool: repool}
+}
+
+//repool:transfers
+func notTransferring() string { // want "notTransferring is annotated with //repool:transfers but does not return a FuncRepool or a struct containing one"
+	return ""
+}
+
+func transferredCalled() {
+	borrowed := GetBorrowed()
+	defer borrowed.Repool()
+}
+
+func transferredOnlyValueRead() string {
+	borrowed := GetBorrowed() // want "the repool function is not called on all paths"
+	return borrowed.Value
+}
+
+func transferredPassedOn() {
+	borrowed := GetBorrowed()
+	consumeBorrowed(borrowed)
+}
+
+func transferredDiscarded() {
+	_ = GetBorrowed() // want "the repool function returned by GetBorrowed should be called, not discarded, to avoid a pool leak"
+}
+
+func consumeBorrowed(borrowed Borrowed) {
+	borrowed.Repool()
+}
+
+//repool:stored
+type cleanups struct { // want cleanups:"repool:stored"
+	funcs  []interfaces.FuncRepool
+	byName map[string]interfaces.FuncRepool
+	single interfaces.FuncRepool
+}
+
+func appendedToSlice(c *cleanups) {
+	_, repool := pool.GetWithRepool()
+	c.funcs = append(c.funcs, repool)
+}
+
+func storedInMap(c *cleanups) {
+	_, repool := pool.GetWithRepool()
+	c.byName["x"] = repool
+}
+
+func storedInField(c *cleanups) {
+	_, repool := pool.GetWithRepool()
+	c.single = repool
+}
+
+func comparedOnly() {
+	_, repool := pool.GetWithRepool() // want "the repool function is not called on all paths"
+	_ = repool != nil
+}
+
+func assignedToBlank() {
+	_, repool := pool.GetWithRepool() // want "the repool function is not called on all paths"
+	_ = repool
+}
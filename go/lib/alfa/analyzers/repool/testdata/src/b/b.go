@@ -0,0 +1,13 @@
+package b
+
+import "a"
+
+func importedTransferCalled() {
+	borrowed := a.GetBorrowed()
+	borrowed.Repool()
+}
+
+func importedTransferLeaked() string {
+	borrowed := a.GetBorrowed() // want "the repool function is not called on all paths"
+	return borrowed.Value
+}
@@ -0,0 +1,52 @@
+package c
+
+import "a/interfaces"
+
+type fakePool struct{}
+
+func (fakePool) GetWithRepool() (string, interfaces.FuncRepool) {
+	return "", func() {}
+}
+
+var pool fakePool
+
+// closer releases everything it holds when closed.
+//
+//repool:stored
+type closer struct { // want closer:"repool:stored"
+	funcs []interfaces.FuncRepool
+}
+
+type unmarked struct {
+	funcs []interfaces.FuncRepool
+	fn    interfaces.FuncRepool
+}
+
+func appendedToMarked(c *closer) {
+	_, repool := pool.GetWithRepool()
+	c.funcs = append(c.funcs, repool)
+}
+
+func appendedToUnmarked(u *unmarked) {
+	_, repool := pool.GetWithRepool() // want "the repool function is not called on all paths"
+	u.funcs = append(u.funcs, repool)
+}
+
+func storedInUnmarkedField(u *unmarked) {
+	_, repool := pool.GetWithRepool() // want "the repool function is not called on all paths"
+	u.fn = repool
+}
+
+func literalOfUnmarked() unmarked {
+	_, repool := pool.GetWithRepool() // want "the repool function is not called on all paths"
+	return unmarked{fn: repool}
+}
+
+func passedStillTransfers() {
+	_, repool := pool.GetWithRepool()
+	consume(repool)
+}
+
+func consume(fn interfaces.FuncRepool) {
+	fn()
+}
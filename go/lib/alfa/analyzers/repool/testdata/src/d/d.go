@@ -0,0 +1,44 @@
+package d
+
+import "a/interfaces"
+
+type fakePool struct{}
+
+func (fakePool) GetWithRepool() (string, interfaces.FuncRepool) {
+	return "", func() {}
+}
+
+var pool fakePool
+
+type unmarked struct {
+	funcs []interfaces.FuncRepool
+	fn    interfaces.FuncRepool
+}
+
+func appendedToUnmarked(u *unmarked) {
+	_, repool := pool.GetWithRepool()
+	u.funcs = append(u.funcs, repool)
+}
+
+func storedInUnmarkedField(u *unmarked) {
+	_, repool := pool.GetWithRepool()
+	u.fn = repool
+}
+
+func literalOfUnmarked() unmarked {
+	_, repool := pool.GetWithRepool()
+	return unmarked{fn: repool}
+}
+
+func appendedToLocal() {
+	_, repool := pool.GetWithRepool()
+
+	var repools []interfaces.FuncRepool
+	repools = append(repools, repool)
+	_ = repools
+}
+
+func comparedOnly() {
+	_, repool := pool.GetWithRepool() // want "the repool function is not called on all paths"
+	_ = repool != nil
+}
@@ -0,0 +1,121 @@
+package pool
+
+import (
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+)
+
+type BoundedPolicy int
+
+const (
+	// BoundedPolicyAllocate allocates a fresh element when the pool is empty
+	// and drops returned elements once the pool is at capacity.
+	BoundedPolicyAllocate = BoundedPolicy(iota)
+
+	// BoundedPolicyBlock never allocates more than capacity elements; Get
+	// blocks until an element is returned.
+	BoundedPolicyBlock
+)
+
+type bounded[SWIMMER any, SWIMMER_PTR interfaces.Ptr[SWIMMER]] struct {
+	elements chan SWIMMER_PTR
+	policy   BoundedPolicy
+	new      func() SWIMMER_PTR
+	reset    func(SWIMMER_PTR)
+	counters *counters
+}
+
+var _ interfaces.PoolPtr[string, *string] = bounded[string, *string]{}
+
+// MakeBounded creates a pool that retains at most capacity elements. Unlike
+// the sync.Pool-backed pools, retained elements are never released by the
+// garbage collector.
+func MakeBounded[SWIMMER any, SWIMMER_PTR interfaces.Ptr[SWIMMER]](
+	capacity int,
+	policy BoundedPolicy,
+	New func() SWIMMER_PTR,
+	Reset func(SWIMMER_PTR),
+) *bounded[SWIMMER, SWIMMER_PTR] {
+	if capacity <= 0 {
+		panic("bounded pool capacity must be greater than zero")
+	}
+
+	pool := &bounded[SWIMMER, SWIMMER_PTR]{
+		elements: make(chan SWIMMER_PTR, capacity),
+		policy:   policy,
+		new:      New,
+		reset:    Reset,
+		counters: &counters{},
+	}
+
+	if pool.new == nil {
+		pool.new = func() SWIMMER_PTR {
+			return SWIMMER_PTR(new(SWIMMER))
+		}
+	}
+
+	if policy == BoundedPolicyBlock {
+		for range capacity {
+			pool.elements <- pool.allocate()
+		}
+	}
+
+	return pool
+}
+
+func (pool bounded[SWIMMER, SWIMMER_PTR]) Stats() Stats {
+	return pool.counters.Stats()
+}
+
+func (pool bounded[SWIMMER, SWIMMER_PTR]) Capacity() int {
+	return cap(pool.elements)
+}
+
+func (pool bounded[SWIMMER, SWIMMER_PTR]) allocate() SWIMMER_PTR {
+	pool.counters.news.Add(1)
+	return pool.new()
+}
+
+func (pool bounded[SWIMMER, SWIMMER_PTR]) get() (swimmer SWIMMER_PTR) {
+	pool.counters.gets.Add(1)
+
+	switch pool.policy {
+	case BoundedPolicyBlock:
+		swimmer = <-pool.elements
+
+	default:
+		select {
+		case swimmer = <-pool.elements:
+		default:
+			swimmer = pool.allocate()
+		}
+	}
+
+	return swimmer
+}
+
+func (pool bounded[SWIMMER, SWIMMER_PTR]) GetWithRepool() (SWIMMER_PTR, interfaces.FuncRepool) {
+	element := pool.get()
+
+	return element, wrapRepoolDebug(func() {
+		pool.put(element)
+	})
+}
+
+func (pool bounded[SWIMMER, SWIMMER_PTR]) put(swimmer SWIMMER_PTR) {
+	if pool.reset != nil {
+		pool.reset(swimmer)
+	}
+
+	pool.counters.puts.Add(1)
+
+	switch pool.policy {
+	case BoundedPolicyBlock:
+		pool.elements <- swimmer
+
+	default:
+		select {
+		case pool.elements <- swimmer:
+		default:
+		}
+	}
+}
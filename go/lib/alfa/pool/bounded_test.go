@@ -0,0 +1,97 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoundedAllocateDropsOverCapacity(t *testing.T) {
+	pool := MakeBounded[int](2, BoundedPolicyAllocate, nil, nil)
+
+	var repools Repools
+
+	for range 3 {
+		_, repool := pool.GetWithRepool()
+		repools = append(repools, repool)
+	}
+
+	if stats := pool.Stats(); stats.News != 3 || stats.InFlight != 3 {
+		t.Fatalf("unexpected stats after gets: %+v", stats)
+	}
+
+	repools.Repool()
+
+	if retained := len(pool.elements); retained != 2 {
+		t.Errorf("expected 2 retained elements, but got %d", retained)
+	}
+
+	_, repool := pool.GetWithRepool()
+	repool()
+
+	stats := pool.Stats()
+
+	if stats.News != 3 {
+		t.Errorf("expected no new allocation, but got %d news", stats.News)
+	}
+
+	if stats.InFlight != 0 {
+		t.Errorf("expected nothing in flight, but got %d", stats.InFlight)
+	}
+}
+
+func TestBoundedBlockWaitsForRepool(t *testing.T) {
+	pool := MakeBounded[int](1, BoundedPolicyBlock, nil, nil)
+
+	first, repool := pool.GetWithRepool()
+	acquired := make(chan *int)
+
+	go func() {
+		second, secondRepool := pool.GetWithRepool()
+		defer secondRepool()
+		acquired <- second
+	}()
+
+	blocked := !receivesWithin(acquired, 10*time.Millisecond)
+	repool()
+
+	if !blocked {
+		t.Fatal("expected get to block while pool is exhausted")
+	}
+
+	if second := <-acquired; second != first {
+		t.Errorf("expected the returned element to be reused")
+	}
+
+	if stats := pool.Stats(); stats.News != 1 {
+		t.Errorf("expected exactly 1 allocation, but got %d", stats.News)
+	}
+}
+
+func receivesWithin[T any](channel <-chan T, timeout time.Duration) bool {
+	select {
+	case <-channel:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	pool := Make[int](nil, nil)
+
+	_, repool := pool.GetWithRepool()
+	stats, ok := GetStats(pool)
+	repool()
+
+	if !ok {
+		t.Fatal("expected pool to provide stats")
+	}
+
+	if stats.Gets != 1 || stats.InFlight != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	if stats := pool.Stats(); stats.Puts != 1 || stats.InFlight != 0 {
+		t.Errorf("unexpected stats after repool: %+v", stats)
+	}
+}
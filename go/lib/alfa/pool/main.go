@@ -7,8 +7,9 @@ import (
 )
 
 type pool[SWIMMER any, SWIMMER_PTR interfaces.Ptr[SWIMMER]] struct {
-	inner *sync.Pool
-	reset func(SWIMMER_PTR)
+	inner    *sync.Pool
+	reset    func(SWIMMER_PTR)
+	counters *counters
 }
 
 var _ interfaces.PoolPtr[string, *string] = pool[string, *string]{}
@@ -23,10 +24,15 @@ func Make[SWIMMER any, SWIMMER_PTR interfaces.Ptr[SWIMMER]](
 	New func() SWIMMER_PTR,
 	Reset func(SWIMMER_PTR),
 ) *pool[SWIMMER, SWIMMER_PTR] {
+	counters := &counters{}
+
 	return &pool[SWIMMER, SWIMMER_PTR]{
-		reset: Reset,
+		reset:    Reset,
+		counters: counters,
 		inner: &sync.Pool{
 			New: func() (swimmer any) {
+				counters.news.Add(1)
+
 				if New == nil {
 					swimmer = new(SWIMMER)
 				} else {
@@ -39,7 +45,12 @@ func Make[SWIMMER any, SWIMMER_PTR interfaces.Ptr[SWIMMER]](
 	}
 }
 
+func (pool pool[SWIMMER, SWIMMER_PTR]) Stats() Stats {
+	return pool.counters.Stats()
+}
+
 func (pool pool[SWIMMER, SWIMMER_PTR]) get() SWIMMER_PTR {
+	pool.counters.gets.Add(1)
 	return pool.inner.Get().(SWIMMER_PTR)
 }
 
@@ -60,5 +71,6 @@ func (pool pool[SWIMMER, SWIMMER_PTR]) put(swimmer SWIMMER_PTR) {
 		pool.reset(swimmer)
 	}
 
+	pool.counters.puts.Add(1)
 	pool.inner.Put(swimmer)
 }
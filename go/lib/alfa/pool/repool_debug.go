@@ -4,31 +4,124 @@ package pool
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"runtime"
+	"strings"
+	"sync"
 	"sync/atomic"
 
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
 )
 
-var outstandingBorrows atomic.Int64
+const envVarLeakDetection = "DODDER_POOL_LEAK_DETECTION"
+
+var (
+	outstandingBorrows atomic.Int64
+	detectedLeaks      atomic.Int64
+	leakDetection      atomic.Bool
+
+	leakWriterLock sync.Mutex
+	leakWriter     io.Writer = os.Stderr
+)
+
+func init() {
+	if _, ok := os.LookupEnv(envVarLeakDetection); ok {
+		leakDetection.Store(true)
+	}
+}
+
+type borrow struct {
+	called  atomic.Bool
+	cleanup runtime.Cleanup
+	tracked bool
+}
 
 func wrapRepoolDebug(repool interfaces.FuncRepool) interfaces.FuncRepool {
 	outstandingBorrows.Add(1)
 
-	var called atomic.Bool
+	borrow := &borrow{}
 	_, file, line, _ := runtime.Caller(1)
 	caller := fmt.Sprintf("%s:%d", file, line)
 
+	if leakDetection.Load() {
+		// the cleanup argument must not reference the borrow, otherwise the
+		// borrow would never become unreachable
+		borrow.cleanup = runtime.AddCleanup(borrow, reportLeak, captureStack())
+		borrow.tracked = true
+	}
+
 	return func() {
-		if !called.CompareAndSwap(false, true) {
+		if !borrow.called.CompareAndSwap(false, true) {
 			panic(fmt.Sprintf("repool: double-repool detected (originally borrowed at %s)", caller))
 		}
 
+		if borrow.tracked {
+			borrow.cleanup.Stop()
+		}
+
 		outstandingBorrows.Add(-1)
 		repool()
 	}
 }
 
+func captureStack() string {
+	programCounters := make([]uintptr, 32)
+	// skip runtime.Callers, captureStack, wrapRepoolDebug, and GetWithRepool
+	count := runtime.Callers(4, programCounters)
+	frames := runtime.CallersFrames(programCounters[:count])
+
+	var stack strings.Builder
+
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&stack, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+
+		if !more {
+			break
+		}
+	}
+
+	return stack.String()
+}
+
+func reportLeak(stack string) {
+	detectedLeaks.Add(1)
+
+	leakWriterLock.Lock()
+	defer leakWriterLock.Unlock()
+
+	fmt.Fprintf(
+		leakWriter,
+		"repool: pooled element garbage collected without repool, acquired at:\n%s",
+		stack,
+	)
+}
+
 func OutstandingBorrows() int64 {
 	return outstandingBorrows.Load()
 }
+
+// SetLeakDetection toggles capturing the acquisition stack of every borrow
+// and reporting borrows whose repool was garbage collected without being
+// called. Also enabled by setting DODDER_POOL_LEAK_DETECTION.
+func SetLeakDetection(enabled bool) {
+	leakDetection.Store(enabled)
+}
+
+// SetLeakWriter redirects leak reports. A nil writer restores the default of
+// stderr.
+func SetLeakWriter(writer io.Writer) {
+	leakWriterLock.Lock()
+	defer leakWriterLock.Unlock()
+
+	if writer == nil {
+		writer = os.Stderr
+	}
+
+	leakWriter = writer
+}
+
+func DetectedLeaks() int64 {
+	return detectedLeaks.Load()
+}
@@ -0,0 +1,50 @@
+//go:build debug
+
+package pool
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func leakBorrow(pool *pool[int, *int]) {
+	pool.GetWithRepool() //repool:owned
+}
+
+func TestLeakDetectionReportsAcquisitionStack(t *testing.T) {
+	var output bytes.Buffer
+
+	SetLeakDetection(true)
+	SetLeakWriter(&output)
+
+	defer SetLeakDetection(false)
+	defer SetLeakWriter(nil)
+
+	pool := Make[int](nil, nil)
+	before := DetectedLeaks()
+
+	leakBorrow(pool)
+
+	_, repool := pool.GetWithRepool()
+	repool()
+
+	deadline := time.Now().Add(time.Second)
+
+	for DetectedLeaks() == before && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+
+	if leaks := DetectedLeaks() - before; leaks != 1 {
+		t.Fatalf("expected 1 detected leak, but got %d", leaks)
+	}
+
+	SetLeakWriter(nil)
+
+	if report := output.String(); !strings.Contains(report, "leakBorrow") {
+		t.Errorf("expected acquisition stack to include leakBorrow, but got:\n%s", report)
+	}
+}
@@ -2,7 +2,11 @@
 
 package pool
 
-import "code.linenisgreat.com/dodder/go/lib/_/interfaces"
+import (
+	"io"
+
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+)
 
 func wrapRepoolDebug(repool interfaces.FuncRepool) interfaces.FuncRepool {
 	return repool
@@ -11,3 +15,11 @@ func wrapRepoolDebug(repool interfaces.FuncRepool) interfaces.FuncRepool {
 func OutstandingBorrows() int64 {
 	return 0
 }
+
+func SetLeakDetection(enabled bool) {}
+
+func SetLeakWriter(writer io.Writer) {}
+
+func DetectedLeaks() int64 {
+	return 0
+}
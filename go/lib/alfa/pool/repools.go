@@ -0,0 +1,18 @@
+package pool
+
+import "code.linenisgreat.com/dodder/go/lib/_/interfaces"
+
+// Repools collects the repool funcs of objects that are held until the end of
+// an operation, so that they can be returned together.
+//
+//repool:stored
+type Repools []interfaces.FuncRepool
+
+// Repool calls every collected repool func and empties the collection.
+func (repools *Repools) Repool() {
+	for _, repool := range *repools {
+		repool()
+	}
+
+	*repools = (*repools)[:0]
+}
@@ -0,0 +1,45 @@
+package pool
+
+import "sync/atomic"
+
+// Stats is a point-in-time snapshot of a pool's counters.
+type Stats struct {
+	Gets     int64 // elements handed out via GetWithRepool
+	Puts     int64 // elements returned via repool
+	News     int64 // elements allocated because the pool was empty
+	InFlight int64 // elements handed out but not yet returned
+}
+
+type StatsProvider interface {
+	Stats() Stats
+}
+
+type counters struct {
+	gets atomic.Int64
+	puts atomic.Int64
+	news atomic.Int64
+}
+
+func (counters *counters) Stats() Stats {
+	gets := counters.gets.Load()
+	puts := counters.puts.Load()
+
+	return Stats{
+		Gets:     gets,
+		Puts:     puts,
+		News:     counters.news.Load(),
+		InFlight: gets - puts,
+	}
+}
+
+// GetStats returns the counters for pools that track them and false for pools
+// that do not (bespoke and fake pools).
+func GetStats(pool any) (Stats, bool) {
+	provider, ok := pool.(StatsProvider)
+
+	if !ok {
+		return Stats{}, false
+	}
+
+	return provider.Stats(), true
+}
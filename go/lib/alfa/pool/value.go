@@ -7,8 +7,9 @@ import (
 )
 
 type value[SWIMMER any] struct {
-	inner *sync.Pool
-	reset func(SWIMMER)
+	inner    *sync.Pool
+	reset    func(SWIMMER)
+	counters *counters
 }
 
 var _ interfaces.Pool[string] = value[string]{}
@@ -17,10 +18,15 @@ func MakeValue[SWIMMER any](
 	New func() SWIMMER,
 	Reset func(SWIMMER),
 ) *value[SWIMMER] {
+	counters := &counters{}
+
 	return &value[SWIMMER]{
-		reset: Reset,
+		reset:    Reset,
+		counters: counters,
 		inner: &sync.Pool{
 			New: func() (swimmer any) {
+				counters.news.Add(1)
+
 				if New == nil {
 					var element SWIMMER
 					swimmer = element
@@ -34,7 +40,12 @@ func MakeValue[SWIMMER any](
 	}
 }
 
+func (pool value[SWIMMER]) Stats() Stats {
+	return pool.counters.Stats()
+}
+
 func (pool value[SWIMMER]) get() SWIMMER {
+	pool.counters.gets.Add(1)
 	return pool.inner.Get().(SWIMMER)
 }
 
@@ -51,5 +62,6 @@ func (pool value[SWIMMER]) put(swimmer SWIMMER) {
 		pool.reset(swimmer)
 	}
 
+	pool.counters.puts.Add(1)
 	pool.inner.Put(swimmer)
 }
@@ -0,0 +1,160 @@
+package structured_log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// EnvVarFormat selects the format of log records: `json` for one JSON object
+// per line, anything else for slog's `key=value` text.
+const EnvVarFormat = "DODDER_LOG"
+
+const (
+	KeyComponent = "component"
+	KeyObjectId  = "object_id"
+	KeyBlobId    = "blob_id"
+	KeyDuration  = "duration"
+)
+
+// Verbosity is the number of `-v`s: none logs only warnings and errors, one
+// adds info records, and two add debug records.
+type Verbosity int
+
+const (
+	VerbosityQuiet Verbosity = iota
+	VerbosityInfo
+	VerbosityDebug
+)
+
+func (verbosity Verbosity) GetLevel() slog.Level {
+	switch {
+	case verbosity >= VerbosityDebug:
+		return slog.LevelDebug
+
+	case verbosity == VerbosityInfo:
+		return slog.LevelInfo
+
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// atomic.Value requires every stored value to have the same concrete type
+type handlerBox struct {
+	handler slog.Handler
+}
+
+var current atomic.Value
+
+func init() {
+	current.Store(handlerBox{slog.DiscardHandler})
+}
+
+// Configure replaces the handler that every component logger writes through,
+// including loggers made before the call. format is the value of
+// EnvVarFormat.
+func Configure(writer io.Writer, verbosity Verbosity, format string) {
+	options := &slog.HandlerOptions{Level: verbosity.GetLevel()}
+
+	var handler slog.Handler
+
+	if format == "json" {
+		handler = slog.NewJSONHandler(writer, options)
+	} else {
+		handler = slog.NewTextHandler(writer, options)
+	}
+
+	current.Store(handlerBox{handler})
+}
+
+// Disable discards all records, which is the default.
+func Disable() {
+	current.Store(handlerBox{slog.DiscardHandler})
+}
+
+func getHandler() slog.Handler {
+	return current.Load().(handlerBox).handler
+}
+
+// Component returns a logger whose records carry the component field. It is
+// meant to be made once per package and kept in a package variable, so it
+// forwards to whatever handler Configure installed last.
+func Component(name string) *slog.Logger {
+	return slog.New(componentHandler{}.WithAttrs(
+		[]slog.Attr{slog.String(KeyComponent, name)},
+	))
+}
+
+func ObjectId(id fmt.Stringer) slog.Attr {
+	return slog.String(KeyObjectId, id.String())
+}
+
+func BlobId(id fmt.Stringer) slog.Attr {
+	return slog.String(KeyBlobId, id.String())
+}
+
+func Duration(duration time.Duration) slog.Attr {
+	return slog.Duration(KeyDuration, duration)
+}
+
+// Since is the Duration since start, for records logged at the end of an
+// operation.
+func Since(start time.Time) slog.Attr {
+	return Duration(time.Since(start))
+}
+
+// componentHandler applies its attributes and groups to the current handler
+// for every record, rather than once, so that Configure reaches loggers made
+// before it.
+type componentHandler struct {
+	wrappers []func(slog.Handler) slog.Handler
+}
+
+func (handler componentHandler) resolve() (resolved slog.Handler) {
+	resolved = getHandler()
+
+	for _, wrapper := range handler.wrappers {
+		resolved = wrapper(resolved)
+	}
+
+	return resolved
+}
+
+func (handler componentHandler) with(
+	wrapper func(slog.Handler) slog.Handler,
+) componentHandler {
+	wrappers := make([]func(slog.Handler) slog.Handler, len(handler.wrappers), len(handler.wrappers)+1)
+	copy(wrappers, handler.wrappers)
+
+	return componentHandler{wrappers: append(wrappers, wrapper)}
+}
+
+func (handler componentHandler) Enabled(
+	ctx context.Context,
+	level slog.Level,
+) bool {
+	return getHandler().Enabled(ctx, level)
+}
+
+func (handler componentHandler) Handle(
+	ctx context.Context,
+	record slog.Record,
+) error {
+	return handler.resolve().Handle(ctx, record)
+}
+
+func (handler componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return handler.with(func(resolved slog.Handler) slog.Handler {
+		return resolved.WithAttrs(attrs)
+	})
+}
+
+func (handler componentHandler) WithGroup(name string) slog.Handler {
+	return handler.with(func(resolved slog.Handler) slog.Handler {
+		return resolved.WithGroup(name)
+	})
+}
@@ -0,0 +1,73 @@
+package structured_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stringer string
+
+func (value stringer) String() string { return string(value) }
+
+func TestComponentLoggerFollowsConfigure(t *testing.T) {
+	t.Cleanup(Disable)
+
+	logger := Component("store")
+
+	var buffer bytes.Buffer
+
+	Configure(&buffer, VerbosityInfo, "json")
+
+	logger.Debug("hidden below info")
+	logger.Info(
+		"commit",
+		ObjectId(stringer("one/uno")),
+		Duration(3*time.Millisecond),
+	)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+
+	if len(lines) != 1 {
+		t.Fatalf("expected one record, got %q", buffer.String())
+	}
+
+	var record map[string]any
+
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record[KeyComponent] != "store" || record[KeyObjectId] != "one/uno" {
+		t.Errorf("unexpected record: %v", record)
+	}
+
+	if record["msg"] != "commit" {
+		t.Errorf("unexpected message: %v", record["msg"])
+	}
+}
+
+func TestVerbosityLevels(t *testing.T) {
+	t.Cleanup(Disable)
+
+	logger := Component("query")
+
+	var buffer bytes.Buffer
+
+	Configure(&buffer, VerbosityQuiet, "")
+	logger.Info("quiet")
+
+	if buffer.Len() != 0 {
+		t.Errorf("expected no info records without -v, got %q", buffer.String())
+	}
+
+	Configure(&buffer, VerbosityDebug, "")
+	logger.WithGroup("cache").Debug("miss", BlobId(stringer("sha256-abc")))
+
+	if !strings.Contains(buffer.String(), "component=query") ||
+		!strings.Contains(buffer.String(), "cache.blob_id=sha256-abc") {
+		t.Errorf("unexpected text record: %q", buffer.String())
+	}
+}
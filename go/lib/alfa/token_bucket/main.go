@@ -0,0 +1,143 @@
+package token_bucket
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// maxReadSize bounds each paced read so a large buffer does not turn into
+// one long wait.
+const maxReadSize = 64 << 10
+
+// Bucket paces a stream of tokens (typically bytes) to a rate per second. It
+// holds at most one second of tokens, so an idle bucket lets up to a second's
+// worth through at once before pacing starts. A nil *Bucket never waits, and
+// one Bucket can be shared by any number of goroutines.
+type Bucket struct {
+	lock   sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// Make returns a Bucket that refills ratePerSecond tokens every second, or nil
+// (unlimited) when ratePerSecond is 0.
+func Make(ratePerSecond uint64) *Bucket {
+	return makeWithClock(ratePerSecond, time.Now)
+}
+
+func makeWithClock(ratePerSecond uint64, now func() time.Time) *Bucket {
+	if ratePerSecond == 0 {
+		return nil
+	}
+
+	return &Bucket{
+		rate:   float64(ratePerSecond),
+		tokens: float64(ratePerSecond),
+		last:   now(),
+		now:    now,
+	}
+}
+
+func (bucket *Bucket) GetRate() uint64 {
+	if bucket == nil {
+		return 0
+	}
+
+	return uint64(bucket.rate)
+}
+
+// take removes n tokens and returns how long the caller has to wait before
+// they are earned. The balance may go negative, so a take larger than the
+// bucket is paced rather than refused, and later takes queue behind it.
+func (bucket *Bucket) take(n int64) time.Duration {
+	bucket.lock.Lock()
+	defer bucket.lock.Unlock()
+
+	now := bucket.now()
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * bucket.rate
+	bucket.tokens = min(bucket.tokens, bucket.rate)
+	bucket.last = now
+
+	bucket.tokens -= float64(n)
+
+	if bucket.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+}
+
+// Wait takes n tokens, sleeping until they are earned or ctx ends.
+func (bucket *Bucket) Wait(ctx context.Context, n int64) (err error) {
+	if bucket == nil || n <= 0 {
+		return err
+	}
+
+	delay := bucket.take(n)
+
+	if delay <= 0 {
+		return err
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		err = context.Cause(ctx)
+
+	case <-timer.C:
+	}
+
+	return err
+}
+
+// MakeReader returns a reader that paces reads from reader through every one
+// of buckets, so it moves no faster than the slowest. Nil buckets are
+// skipped, and with none left reader is returned as is.
+func MakeReader(
+	ctx context.Context,
+	reader io.Reader,
+	buckets ...*Bucket,
+) io.Reader {
+	paced := pacedReader{ctx: ctx, reader: reader}
+
+	for _, bucket := range buckets {
+		if bucket != nil {
+			paced.buckets = append(paced.buckets, bucket)
+		}
+	}
+
+	if len(paced.buckets) == 0 {
+		return reader
+	}
+
+	return paced
+}
+
+type pacedReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	buckets []*Bucket
+}
+
+func (reader pacedReader) Read(p []byte) (n int, err error) {
+	if len(p) > maxReadSize {
+		p = p[:maxReadSize]
+	}
+
+	n, err = reader.reader.Read(p)
+
+	for _, bucket := range reader.buckets {
+		if waitErr := bucket.Wait(reader.ctx, int64(n)); waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
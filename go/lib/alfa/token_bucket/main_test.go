@@ -0,0 +1,112 @@
+package token_bucket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	current time.Time
+}
+
+func (clock *fakeClock) now() time.Time {
+	return clock.current
+}
+
+func TestMakeZeroRateIsUnlimited(t *testing.T) {
+	bucket := Make(0)
+
+	if bucket != nil {
+		t.Fatal("expected a zero rate to make a nil bucket")
+	}
+
+	if err := bucket.Wait(context.Background(), 1<<30); err != nil {
+		t.Fatalf("expected a nil bucket not to wait: %v", err)
+	}
+}
+
+func TestTakeWithinBurstDoesNotWait(t *testing.T) {
+	clock := &fakeClock{current: time.Unix(0, 0)}
+	bucket := makeWithClock(1000, clock.now)
+
+	if delay := bucket.take(1000); delay != 0 {
+		t.Errorf("expected a full bucket to cover its burst, waited %s", delay)
+	}
+
+	if delay := bucket.take(500); delay != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms for an empty bucket, got %s", delay)
+	}
+}
+
+func TestTakeRefillsWithTime(t *testing.T) {
+	clock := &fakeClock{current: time.Unix(0, 0)}
+	bucket := makeWithClock(1000, clock.now)
+
+	bucket.take(1000)
+	clock.current = clock.current.Add(250 * time.Millisecond)
+
+	if delay := bucket.take(250); delay != 0 {
+		t.Errorf("expected 250ms to earn 250 tokens, waited %s", delay)
+	}
+
+	// an idle bucket holds at most one second of tokens
+	clock.current = clock.current.Add(time.Hour)
+
+	if delay := bucket.take(2000); delay != time.Second {
+		t.Errorf("expected a capped refill to leave a 1s wait, got %s", delay)
+	}
+}
+
+func TestTakeLargerThanBurstQueuesLaterTakes(t *testing.T) {
+	clock := &fakeClock{current: time.Unix(0, 0)}
+	bucket := makeWithClock(100, clock.now)
+
+	if delay := bucket.take(300); delay != 2*time.Second {
+		t.Errorf("expected to wait 2s for 300 tokens, got %s", delay)
+	}
+
+	if delay := bucket.take(100); delay != 3*time.Second {
+		t.Errorf("expected a later take to queue behind, got %s", delay)
+	}
+}
+
+func TestWaitReturnsWhenContextEnds(t *testing.T) {
+	bucket := Make(1)
+	bucket.take(1)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cause := errors.New("stopped")
+	cancel(cause)
+
+	if err := bucket.Wait(ctx, 3600); !errors.Is(err, cause) {
+		t.Errorf("expected the context's cause, got %v", err)
+	}
+}
+
+func TestMakeReaderSkipsNilBuckets(t *testing.T) {
+	reader := bytes.NewReader([]byte("unpaced"))
+
+	if paced := MakeReader(context.Background(), reader, nil, nil); paced != io.Reader(reader) {
+		t.Error("expected the reader back when every bucket is nil")
+	}
+}
+
+func TestMakeReaderPassesContentThrough(t *testing.T) {
+	content := bytes.Repeat([]byte("paced "), maxReadSize)
+	bucket := Make(uint64(len(content)) * 16)
+
+	actual, err := io.ReadAll(
+		MakeReader(context.Background(), bytes.NewReader(content), bucket),
+	)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if !bytes.Equal(actual, content) {
+		t.Error("expected paced reads to return the content unchanged")
+	}
+}
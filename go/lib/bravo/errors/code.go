@@ -0,0 +1,152 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+
+	hs "code.linenisgreat.com/dodder/go/lib/_/http_statuses"
+)
+
+// Code is a stable, machine-readable identifier for a class of failure.
+// Scripts should branch on codes rather than on error text. Codes must never
+// be renamed once released.
+type Code string
+
+const (
+	CodeUnknown        = Code("DODDER_ERROR")
+	CodeBadRequest     = Code("DODDER_BAD_REQUEST")
+	CodeNotFound       = Code("DODDER_NOT_FOUND")
+	CodeExists         = Code("DODDER_EXISTS")
+	CodeConflict       = Code("DODDER_CONFLICT")
+	CodeNotImpl        = Code("DODDER_NOT_IMPLEMENTED")
+	CodeInterrupted    = Code("DODDER_INTERRUPTED")
+	CodeBlobMissing    = Code("DODDER_BLOB_MISSING")
+	CodeLockConflict   = Code("DODDER_LOCK_CONFLICT")
+	CodeLockRequired   = Code("DODDER_LOCK_REQUIRED")
+	CodeInvalidBlob    = Code("DODDER_INVALID_BLOB")
+	CodeBudgetExceeded = Code("DODDER_BUDGET_EXCEEDED")
+	CodeReadOnly       = Code("DODDER_READ_ONLY")
+
+	CodeInsufficientSpace = Code("DODDER_INSUFFICIENT_SPACE")
+)
+
+// ErrorCoder is implemented by errors that carry their own Code.
+type ErrorCoder interface {
+	error
+	GetErrorCode() Code
+}
+
+type withCode struct {
+	underlying error
+	code       Code
+}
+
+func (err withCode) Error() string {
+	return err.underlying.Error()
+}
+
+func (err withCode) Unwrap() error {
+	return err.underlying
+}
+
+func (err withCode) ShouldHideUnwrap() bool {
+	return true
+}
+
+func (err withCode) GetErrorCode() Code {
+	return err.code
+}
+
+// WithCode attaches code to err without changing its message.
+func WithCode(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return withCode{underlying: err, code: code}
+}
+
+// GetCode returns the outermost code attached to err, falling back to codes
+// derived from well-known sentinels, and CodeUnknown otherwise.
+func GetCode(err error) Code {
+	var coder ErrorCoder
+
+	if As(err, &coder) {
+		return coder.GetErrorCode()
+	}
+
+	var signal Signal
+
+	switch {
+	case As(err, &signal), Is499ClientClosedRequest(err):
+		return CodeInterrupted
+
+	case IsErrNotFound(err), IsNotExist(err):
+		return CodeNotFound
+
+	case Is(err, ErrExists), IsExist(err):
+		return CodeExists
+
+	case Is400BadRequest(err):
+		return CodeBadRequest
+
+	case IsHTTPError(err, hs.Code409Conflict):
+		return CodeConflict
+
+	case IsHTTPError(err, hs.Code501NotImplemented):
+		return CodeNotImpl
+	}
+
+	return CodeUnknown
+}
+
+// ErrorEnvelope is the JSON document written in place of the error tree when
+// machine-readable error output is requested.
+type ErrorEnvelope struct {
+	Error ErrorEnvelopeBody `json:"error"`
+}
+
+type ErrorEnvelopeBody struct {
+	Code    Code                `json:"code"`
+	Message string              `json:"message"`
+	Causes  []ErrorEnvelopeBody `json:"causes,omitempty"`
+
+	// the cause and recovery lines of a Helpful error
+	Cause    []string `json:"cause,omitempty"`
+	Recovery []string `json:"recovery,omitempty"`
+}
+
+func MakeErrorEnvelope(err error) (envelope ErrorEnvelope) {
+	envelope.Error = makeErrorEnvelopeBody(err)
+
+	var helpful Helpful
+
+	if As(err, &helpful) {
+		envelope.Error.Cause = helpful.GetErrorCause()
+		envelope.Error.Recovery = helpful.GetErrorRecovery()
+	}
+
+	return envelope
+}
+
+func makeErrorEnvelopeBody(err error) (body ErrorEnvelopeBody) {
+	body.Code = GetCode(err)
+	body.Message = err.Error()
+
+	var many UnwrapMany
+
+	if As(err, &many) {
+		for _, child := range many.Unwrap() {
+			if child != nil {
+				body.Causes = append(body.Causes, makeErrorEnvelopeBody(child))
+			}
+		}
+	}
+
+	return body
+}
+
+func EncodeErrorEnvelope(writer io.Writer, err error) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(MakeErrorEnvelope(err))
+}
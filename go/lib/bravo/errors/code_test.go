@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGetCode(t *testing.T) {
+	type testCase struct {
+		name     string
+		err      error
+		expected Code
+	}
+
+	for _, testCase := range []testCase{
+		{"plain", errors.New("plain"), CodeUnknown},
+		{"attached", Wrap(WithCode(CodeLockConflict, errors.New("locked"))), CodeLockConflict},
+		{"outermost wins", WithCode(CodeBlobMissing, WithCode(CodeLockConflict, errors.New("x"))), CodeBlobMissing},
+		{"not found", Wrap(MakeErrNotFoundString("x")), CodeNotFound},
+		{"bad request", BadRequestf("bad"), CodeBadRequest},
+		{"conflict", Err409Conflict.Errorf("conflict"), CodeConflict},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := GetCode(testCase.err); actual != testCase.expected {
+				t.Errorf("expected %s, but got %s", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	builder := MakeMultiErrorBuilder(0)
+	builder.Add("one", WithCode(CodeBlobMissing, errors.New("missing")))
+	builder.Add("two", errors.New("other"))
+
+	var output strings.Builder
+
+	if err := EncodeErrorEnvelope(&output, builder.GetError()); err != nil {
+		t.Fatal(err)
+	}
+
+	var envelope ErrorEnvelope
+
+	if err := json.Unmarshal([]byte(output.String()), &envelope); err != nil {
+		t.Fatalf("invalid json %q: %s", output.String(), err)
+	}
+
+	if envelope.Error.Message != "2 errors" || len(envelope.Error.Causes) != 2 {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+
+	if actual := envelope.Error.Causes[0].Code; actual != CodeBlobMissing {
+		t.Errorf("expected first cause to be %s, but got %s", CodeBlobMissing, actual)
+	}
+}
@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MultiErrorItem is one failure collected by a MultiErrorBuilder along with
+// the item it occurred on (an object id, blob digest, path, etc).
+type MultiErrorItem struct {
+	Context string
+	Err     error
+}
+
+func (item MultiErrorItem) Error() string {
+	if item.Context == "" {
+		return item.Err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", item.Context, item.Err)
+}
+
+// Is and As are implemented instead of Unwrap so that error trees render the
+// item as a single line that includes its context.
+func (item MultiErrorItem) Is(target error) bool {
+	return errors.Is(item.Err, target)
+}
+
+func (item MultiErrorItem) As(target any) bool {
+	return errors.As(item.Err, target)
+}
+
+// MultiError is the result of an operation that continued past individual
+// failures. At most the builder's limit of items are retained; the rest are
+// only counted in Omitted.
+type MultiError struct {
+	Items   []MultiErrorItem
+	Omitted int
+}
+
+func (multiError MultiError) Error() string {
+	if multiError.Omitted == 0 {
+		return fmt.Sprintf("%d errors", multiError.Len())
+	}
+
+	return fmt.Sprintf(
+		"%d errors (%d omitted)",
+		multiError.Len(),
+		multiError.Omitted,
+	)
+}
+
+// Len returns the total number of failures, including omitted ones.
+func (multiError MultiError) Len() int {
+	return len(multiError.Items) + multiError.Omitted
+}
+
+func (multiError MultiError) Unwrap() []error {
+	errs := make([]error, len(multiError.Items))
+
+	for i, item := range multiError.Items {
+		errs[i] = item
+	}
+
+	return errs
+}
+
+type MultiErrorBuilder struct {
+	lock       sync.Mutex
+	limit      int
+	multiError MultiError
+}
+
+// MakeMultiErrorBuilder collects failures from operations that should keep
+// going after individual errors (pack, fsck, replication, import). A limit of
+// zero or less retains every item.
+func MakeMultiErrorBuilder(limit int) *MultiErrorBuilder {
+	return &MultiErrorBuilder{
+		limit: limit,
+	}
+}
+
+func (builder *MultiErrorBuilder) Add(context string, err error) {
+	if err == nil {
+		return
+	}
+
+	if builder == nil {
+		panic("trying to add to nil multi error builder")
+	}
+
+	builder.lock.Lock()
+	defer builder.lock.Unlock()
+
+	if builder.limit > 0 && len(builder.multiError.Items) >= builder.limit {
+		builder.multiError.Omitted++
+		return
+	}
+
+	builder.multiError.Items = append(
+		builder.multiError.Items,
+		MultiErrorItem{Context: context, Err: err},
+	)
+}
+
+func (builder *MultiErrorBuilder) Addf(
+	err error,
+	format string,
+	args ...any,
+) {
+	if err == nil {
+		return
+	}
+
+	builder.Add(fmt.Sprintf(format, args...), err)
+}
+
+func (builder *MultiErrorBuilder) Len() int {
+	builder.lock.Lock()
+	defer builder.lock.Unlock()
+
+	return builder.multiError.Len()
+}
+
+func (builder *MultiErrorBuilder) Empty() bool {
+	return builder.Len() == 0
+}
+
+// GetError returns a MultiError snapshot of the collected failures, or nil if
+// there were none.
+func (builder *MultiErrorBuilder) GetError() error {
+	builder.lock.Lock()
+	defer builder.lock.Unlock()
+
+	if builder.multiError.Len() == 0 {
+		return nil
+	}
+
+	multiError := MultiError{
+		Items:   make([]MultiErrorItem, len(builder.multiError.Items)),
+		Omitted: builder.multiError.Omitted,
+	}
+
+	copy(multiError.Items, builder.multiError.Items)
+
+	return multiError
+}
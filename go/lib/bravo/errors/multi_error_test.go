@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestMultiErrorLimitAndOmitted(t *testing.T) {
+	builder := MakeMultiErrorBuilder(2)
+
+	builder.Add("one", errors.New("first"))
+	builder.Add("two", nil)
+	builder.Add("three", errors.New("third"))
+	builder.Add("four", errors.New("fourth"))
+
+	if actual := builder.Len(); actual != 3 {
+		t.Errorf("expected 3 errors, but got %d", actual)
+	}
+
+	var multiError MultiError
+
+	if !errors.As(builder.GetError(), &multiError) {
+		t.Fatalf("expected MultiError, but got %T", builder.GetError())
+	}
+
+	if len(multiError.Items) != 2 || multiError.Omitted != 1 {
+		t.Errorf("unexpected items: %+v", multiError)
+	}
+
+	if expected, actual := "3 errors (1 omitted)", multiError.Error(); actual != expected {
+		t.Errorf("expected %q, but got %q", expected, actual)
+	}
+
+	if expected, actual := "three: third", multiError.Items[1].Error(); actual != expected {
+		t.Errorf("expected %q, but got %q", expected, actual)
+	}
+}
+
+func TestMultiErrorIsAndAsAcrossMembers(t *testing.T) {
+	builder := MakeMultiErrorBuilder(0)
+
+	builder.Add("blob", errors.New("unrelated"))
+	builder.Add("path", &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist})
+
+	err := builder.GetError()
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected errors.Is to find fs.ErrNotExist")
+	}
+
+	var pathError *fs.PathError
+
+	if !errors.As(err, &pathError) || pathError.Path != "x" {
+		t.Errorf("expected errors.As to find *fs.PathError")
+	}
+}
+
+func TestMultiErrorEmptyIsNil(t *testing.T) {
+	builder := MakeMultiErrorBuilder(1)
+
+	if err := builder.GetError(); err != nil {
+		t.Errorf("expected nil, but got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package errors
+
+import (
+	ConTeXT "context"
+	"syscall"
+	"time"
+)
+
+type retryable struct {
+	underlying error
+}
+
+func (err retryable) Error() string {
+	return err.underlying.Error()
+}
+
+func (err retryable) Unwrap() error {
+	return err.underlying
+}
+
+func (err retryable) ShouldHideUnwrap() bool {
+	return true
+}
+
+// MarkRetryable marks err as transient so that callers (or Retry) may attempt
+// the operation again.
+func MarkRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return retryable{underlying: err}
+}
+
+// IsRetryable reports whether err was marked via MarkRetryable or is a network
+// failure that is transient by nature (timeouts, resets).
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var marked retryable
+
+	if As(err, &marked) {
+		return true
+	}
+
+	return IsNetTimeout(err) ||
+		IsErrno(err, syscall.ECONNRESET, syscall.ETIMEDOUT, syscall.EAGAIN)
+}
+
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt. Zero or less means one attempt.
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+}
+
+var RetryPolicyDefault = RetryPolicy{
+	MaxAttempts:  4,
+	InitialDelay: 250 * time.Millisecond,
+	MaxDelay:     5 * time.Second,
+	Multiplier:   2,
+}
+
+func (policy RetryPolicy) delayForAttempt(attempt int) time.Duration {
+	delay := float64(policy.InitialDelay)
+	multiplier := policy.Multiplier
+
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	for range attempt {
+		delay *= multiplier
+
+		if policy.MaxDelay > 0 && delay >= float64(policy.MaxDelay) {
+			return policy.MaxDelay
+		}
+	}
+
+	return time.Duration(delay)
+}
+
+// Retry calls funcTry until it succeeds, returns an error that is not
+// retryable, the policy's attempts are exhausted, or ctx is done. Delays
+// between attempts back off exponentially. The last error is returned as-is.
+func Retry(
+	ctx ConTeXT.Context,
+	policy RetryPolicy,
+	funcTry FuncErr,
+) (err error) {
+	for attempt := 0; ; attempt++ {
+		if err = funcTry(); err == nil || !IsRetryable(err) {
+			return err
+		}
+
+		if attempt+1 >= policy.MaxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(policy.delayForAttempt(attempt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package errors
+
+import (
+	ConTeXT "context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var retryPolicyTest = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: time.Millisecond,
+	MaxDelay:     2 * time.Millisecond,
+	Multiplier:   2,
+}
+
+func TestIsRetryable(t *testing.T) {
+	plain := errors.New("plain")
+
+	if IsRetryable(plain) {
+		t.Errorf("expected plain error to not be retryable")
+	}
+
+	if !IsRetryable(Wrap(MarkRetryable(plain))) {
+		t.Errorf("expected marked error to be retryable through wrapping")
+	}
+
+	if !Is(MarkRetryable(plain), plain) {
+		t.Errorf("expected marked error to unwrap to the original")
+	}
+
+	if !IsRetryable(Wrap(syscall.ECONNRESET)) {
+		t.Errorf("expected connection reset to be retryable")
+	}
+}
+
+func TestRetryStopsOnSuccess(t *testing.T) {
+	var attempts int
+
+	err := Retry(ConTeXT.Background(), retryPolicyTest, func() error {
+		attempts++
+
+		if attempts < 2 {
+			return MarkRetryable(errors.New("transient"))
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected success, but got %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, but got %d", attempts)
+	}
+}
+
+func TestRetryExhaustsAttempts(t *testing.T) {
+	var attempts int
+
+	err := Retry(ConTeXT.Background(), retryPolicyTest, func() error {
+		attempts++
+		return MarkRetryable(errors.New("transient"))
+	})
+
+	if !IsRetryable(err) {
+		t.Errorf("expected the last retryable error, but got %v", err)
+	}
+
+	if attempts != retryPolicyTest.MaxAttempts {
+		t.Errorf("expected %d attempts, but got %d", retryPolicyTest.MaxAttempts, attempts)
+	}
+}
+
+func TestRetrySkipsPermanentErrors(t *testing.T) {
+	var attempts int
+
+	Retry(ConTeXT.Background(), retryPolicyTest, func() error {
+		attempts++
+		return errors.New("permanent")
+	})
+
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, but got %d", attempts)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     3 * time.Second,
+		Multiplier:   2,
+	}
+
+	for attempt, expected := range []time.Duration{
+		time.Second,
+		2 * time.Second,
+		3 * time.Second,
+		3 * time.Second,
+	} {
+		if actual := policy.delayForAttempt(attempt); actual != expected {
+			t.Errorf("attempt %d: expected %s, but got %s", attempt, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package lua
+
+import (
+	"context"
+	"fmt"
+	"runtime/metrics"
+	"time"
+)
+
+const (
+	// DefaultInstructionBudget is how many instructions one call may run.
+	DefaultInstructionBudget = 100_000_000
+
+	// memoryCheckInterval is how many instructions run between reads of the
+	// heap size, which is too slow to read on every instruction.
+	memoryCheckInterval = 1 << 14
+
+	heapMetric = "/memory/classes/heap/objects:bytes"
+)
+
+// Limits bound every call into a VM, so that runaway scripts fail with
+// ErrScriptBudgetExceeded instead of hanging. A zero limit is no limit.
+//
+// The memory cap is on the growth of the process heap while the call runs,
+// since gopher-lua does not account for the memory of a single VM. Anything
+// else allocating concurrently, including other scripts, counts against it,
+// so it has no default and is only meant to be opted into by repos whose
+// scripts run alone.
+type Limits struct {
+	Instructions uint64
+	Memory       uint64
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		Instructions: DefaultInstructionBudget,
+	}
+}
+
+func (limits Limits) IsEmpty() bool {
+	return limits.Instructions == 0 && limits.Memory == 0
+}
+
+type ErrScriptBudgetExceeded struct {
+	Budget string
+	Limit  uint64
+}
+
+func (err ErrScriptBudgetExceeded) Error() string {
+	return fmt.Sprintf("lua script exceeded its %s budget of %d", err.Budget, err.Limit)
+}
+
+func (err ErrScriptBudgetExceeded) Is(target error) bool {
+	_, ok := target.(ErrScriptBudgetExceeded)
+	return ok
+}
+
+var closedDone = func() chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}()
+
+// budget is the context of a VM during a call. gopher-lua checks Done before
+// every instruction, so counting calls to Done counts instructions.
+type budget struct {
+	limits       Limits
+	instructions uint64
+	heapStart    uint64
+	sample       []metrics.Sample
+	err          error
+}
+
+func makeBudget(limits Limits) *budget {
+	budget := &budget{
+		limits: limits,
+		sample: []metrics.Sample{{Name: heapMetric}},
+	}
+
+	budget.heapStart = budget.readHeap()
+
+	return budget
+}
+
+func (budget *budget) readHeap() uint64 {
+	metrics.Read(budget.sample)
+
+	if budget.sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+
+	return budget.sample[0].Value.Uint64()
+}
+
+func (budget *budget) Done() <-chan struct{} {
+	if budget.err != nil {
+		return closedDone
+	}
+
+	budget.instructions++
+
+	if budget.limits.Instructions > 0 &&
+		budget.instructions > budget.limits.Instructions {
+		budget.err = ErrScriptBudgetExceeded{
+			Budget: "instruction",
+			Limit:  budget.limits.Instructions,
+		}
+
+		return closedDone
+	}
+
+	if budget.limits.Memory > 0 &&
+		budget.instructions%memoryCheckInterval == 0 {
+		if heap := budget.readHeap(); heap > budget.heapStart &&
+			heap-budget.heapStart > budget.limits.Memory {
+			budget.err = ErrScriptBudgetExceeded{
+				Budget: "memory",
+				Limit:  budget.limits.Memory,
+			}
+
+			return closedDone
+		}
+	}
+
+	return nil
+}
+
+func (budget *budget) Err() error {
+	return budget.err
+}
+
+func (budget *budget) Deadline() (deadline time.Time, ok bool) {
+	return deadline, ok
+}
+
+func (budget *budget) Value(key any) any {
+	return nil
+}
+
+var _ context.Context = &budget{}
@@ -0,0 +1,108 @@
+package lua
+
+import (
+	"errors"
+	"testing"
+)
+
+func makeLimitedVM(t *testing.T, script string, limits Limits) *VM {
+	t.Helper()
+
+	vmPool, err := (&VMPoolBuilder{}).
+		WithScript(script).
+		WithLimits(limits).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build vm pool: %s", err)
+	}
+
+	vm, repool := vmPool.GetWithRepool()
+	t.Cleanup(repool)
+
+	return vm
+}
+
+func callField(vm *VM, name string) error {
+	vm.Push(vm.GetField(vm.Top, name))
+	return vm.PCall(0, 0, nil)
+}
+
+func TestLimitsStopRunawayLoop(t *testing.T) {
+	vm := makeLimitedVM(
+		t,
+		`return { spin = function() while true do end end }`,
+		Limits{Instructions: 10_000},
+	)
+
+	err := callField(vm, "spin")
+
+	var budgetErr ErrScriptBudgetExceeded
+
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected ErrScriptBudgetExceeded, got %v", err)
+	}
+
+	if budgetErr.Budget != "instruction" || budgetErr.Limit != 10_000 {
+		t.Errorf("unexpected budget: %#v", budgetErr)
+	}
+}
+
+func TestLimitsStopRunawayAllocation(t *testing.T) {
+	vm := makeLimitedVM(
+		t,
+		`return {
+			grow = function()
+				local t = {}
+				local i = 0
+				while true do
+					i = i + 1
+					t[i] = string.rep("x", 1024) .. i
+				end
+			end,
+		}`,
+		Limits{Memory: 16 << 20},
+	)
+
+	err := callField(vm, "grow")
+
+	var budgetErr ErrScriptBudgetExceeded
+
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected ErrScriptBudgetExceeded, got %v", err)
+	}
+
+	if budgetErr.Budget != "memory" {
+		t.Errorf("unexpected budget: %#v", budgetErr)
+	}
+}
+
+func TestLimitsResetEveryCall(t *testing.T) {
+	vm := makeLimitedVM(
+		t,
+		`return {
+			count = function()
+				local n = 0
+				for i = 1, 1000 do n = n + i end
+				return n
+			end,
+		}`,
+		Limits{Instructions: 10_000},
+	)
+
+	for range 20 {
+		if err := callField(vm, "count"); err != nil {
+			t.Fatalf("expected call within budget, got %s", err)
+		}
+	}
+}
+
+func TestLimitsFailScriptChunk(t *testing.T) {
+	_, err := (&VMPoolBuilder{}).
+		WithScript(`while true do end`).
+		WithLimits(Limits{Instructions: 10_000}).
+		Build()
+
+	if !errors.Is(err, ErrScriptBudgetExceeded{}) {
+		t.Fatalf("expected ErrScriptBudgetExceeded, got %v", err)
+	}
+}
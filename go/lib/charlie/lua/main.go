@@ -9,6 +9,7 @@ const (
 	LTFunction = lua.LTFunction
 	LTTable    = lua.LTTable
 	LTBool     = lua.LTBool
+	LTString   = lua.LTString
 	MultRet    = lua.MultRet
 )
 
@@ -19,6 +20,7 @@ type (
 	LFunction     = lua.LFunction
 	LString       = lua.LString
 	LBool         = lua.LBool
+	LNumber       = lua.LNumber
 	FunctionProto = lua.FunctionProto
 	LGFunction    = lua.LGFunction
 )
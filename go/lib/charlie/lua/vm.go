@@ -10,6 +10,37 @@ type VM struct {
 	*lua.LState
 	Top lua.LValue
 	interfaces.PoolPtr[LTable, *LTable]
+
+	Limits Limits
+	budget *budget
+}
+
+// PCall is LState.PCall within the VM's limits, which are reset for every
+// call. Calls made while another one runs, like those of functions a script
+// calls, share its budget. A call that runs out of budget returns
+// ErrScriptBudgetExceeded.
+func (vm *VM) PCall(nargs, nret int, errfunc *LFunction) (err error) {
+	if vm.budget != nil || vm.Limits.IsEmpty() {
+		return vm.LState.PCall(nargs, nret, errfunc)
+	}
+
+	vm.budget = makeBudget(vm.Limits)
+	vm.SetContext(vm.budget)
+
+	defer func() {
+		vm.RemoveContext()
+		vm.budget = nil
+	}()
+
+	if err = vm.LState.PCall(nargs, nret, errfunc); err != nil {
+		if vm.budget.err != nil {
+			err = errors.Wrap(vm.budget.err)
+		}
+
+		return err
+	}
+
+	return err
 }
 
 func (vm *VM) GetTopFunctionOrFunctionNamedError(
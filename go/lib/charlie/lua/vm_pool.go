@@ -13,6 +13,12 @@ type VMPool struct {
 	interfaces.PoolPtr[VM, *VM]
 	Require  LGFunction
 	Searcher LGFunction
+
+	// Prepare runs on every new VM before the apply of the pool, so that
+	// environments can install globals and metatables for every script
+	Prepare interfaces.FuncIter[*VM]
+	Limits  Limits
+
 	compiled *lua.FunctionProto
 }
 
@@ -20,6 +26,7 @@ func (sp *VMPool) PrepareVM(
 	vm *VM,
 	apply interfaces.FuncIter[*VM],
 ) (err error) {
+	vm.Limits = sp.Limits
 	vm.PoolPtr = pool.Make(
 		func() (t *lua.LTable) {
 			t = vm.NewTable()
@@ -85,6 +92,13 @@ func (sp *VMPool) PrepareVM(
 		}
 	}
 
+	if sp.Prepare != nil {
+		if err = sp.Prepare(vm); err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+	}
+
 	if apply != nil {
 		if err = apply(vm); err != nil {
 			err = errors.Wrap(err)
@@ -32,6 +32,18 @@ func (vpb *VMPoolBuilder) WithSearcher(v LGFunction) *VMPoolBuilder {
 	return vpb
 }
 
+func (vpb *VMPoolBuilder) WithPrepare(
+	v interfaces.FuncIter[*VM],
+) *VMPoolBuilder {
+	vpb.proto.Prepare = v
+	return vpb
+}
+
+func (vpb *VMPoolBuilder) WithLimits(v Limits) *VMPoolBuilder {
+	vpb.proto.Limits = v
+	return vpb
+}
+
 func (sp *VMPoolBuilder) WithScript(
 	script string,
 ) *VMPoolBuilder {
@@ -64,6 +76,8 @@ func (vpb *VMPoolBuilder) Build() (vmp *VMPool, err error) {
 	vmp = &VMPool{
 		Require:  vpb.proto.Require,
 		Searcher: vpb.proto.Searcher,
+		Prepare:  vpb.proto.Prepare,
+		Limits:   vpb.proto.Limits,
 	}
 
 	if vpb.scriptReader == nil && vpb.compiled == nil {
@@ -83,9 +97,15 @@ func (vpb *VMPoolBuilder) Build() (vmp *VMPool, err error) {
 		}
 	}
 
-	// try initializing a lua vm to make sure there are no errors
-	vm, repool := vmp.GetWithRepool()
-	defer repool()
+	// try initializing a lua vm to make sure there are no errors. The pool
+	// panics on errors, so the vm is prepared outside of it.
+	vm := &VM{LState: lua.NewState()}
+	defer vm.Close()
+
+	if err = vmp.PrepareVM(vm, vpb.apply); err != nil {
+		err = errors.Wrap(err)
+		return vmp, err
+	}
 
 	if _, err = vm.GetTopTableOrError(); err != nil {
 		err = errors.Wrap(err)
@@ -0,0 +1,74 @@
+package ohio
+
+import (
+	"bytes"
+	"io"
+
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const reverseLineSeqBlockSize = 64 * 1024
+
+// MakeReverseLineSeqFromReaderAt yields the lines of the first size bytes of
+// readerAt from the last to the first, without their newlines. It reads
+// backwards a block at a time, so stopping after the last few lines only
+// reads the end of the input.
+func MakeReverseLineSeqFromReaderAt(
+	readerAt io.ReaderAt,
+	size int64,
+) interfaces.SeqError[string] {
+	return func(yield func(string, error) bool) {
+		position := size
+		// the start of the earliest line read so far, whose beginning is in a
+		// block not yet read
+		var rest []byte
+		trailingNewline := true
+
+		for position > 0 {
+			blockSize := min(int64(reverseLineSeqBlockSize), position)
+			position -= blockSize
+
+			block := make([]byte, blockSize, blockSize+int64(len(rest)))
+
+			if _, err := readerAt.ReadAt(block, position); err != nil &&
+				!errors.IsEOF(err) {
+				yield("", errors.Wrap(err))
+				return
+			}
+
+			buffer := append(block, rest...)
+			end := len(buffer)
+
+			// the last line of the input only ends in a newline if the input
+			// does
+			if trailingNewline {
+				trailingNewline = false
+
+				if end > 0 && buffer[end-1] == '\n' {
+					end--
+				}
+			}
+
+			for {
+				index := bytes.LastIndexByte(buffer[:end], '\n')
+
+				if index < 0 {
+					break
+				}
+
+				if !yield(string(buffer[index+1:end]), nil) {
+					return
+				}
+
+				end = index
+			}
+
+			rest = buffer[:end]
+		}
+
+		if size > 0 {
+			yield(string(rest), nil)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ohio
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func collectReverseLines(t *testing.T, input string) (lines []string) {
+	t.Helper()
+
+	for line, err := range MakeReverseLineSeqFromReaderAt(
+		strings.NewReader(input),
+		int64(len(input)),
+	) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func TestReverseLineSeq(t *testing.T) {
+	for _, testCase := range []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{"one\n", []string{"one"}},
+		{"one", []string{"one"}},
+		{"one\ntwo\n", []string{"two", "one"}},
+		{"one\n\nthree", []string{"three", "", "one"}},
+	} {
+		actual := collectReverseLines(t, testCase.input)
+
+		if !slices.Equal(actual, testCase.expected) {
+			t.Errorf(
+				"%q: expected %q but got %q",
+				testCase.input,
+				testCase.expected,
+				actual,
+			)
+		}
+	}
+}
+
+func TestReverseLineSeqAcrossBlocks(t *testing.T) {
+	var input strings.Builder
+	var expected []string
+
+	// lines of varying lengths, some longer than a block
+	for i := range 64 {
+		line := fmt.Sprintf("%d:%s", i, strings.Repeat("x", i*i*37))
+		input.WriteString(line + "\n")
+		expected = append(expected, line)
+	}
+
+	slices.Reverse(expected)
+
+	if actual := collectReverseLines(t, input.String()); !slices.Equal(
+		actual,
+		expected,
+	) {
+		t.Errorf("expected %d lines in reverse, got %d", len(expected), len(actual))
+	}
+}
@@ -159,6 +159,22 @@ func testCLITreeForwards(t *TestContext) {
 			},
 			expected: "only\n",
 		},
+		{
+			TestCaseInfo: MakeTestCaseInfo(
+				"multi error with context and omitted items",
+			),
+			input: errors.MultiError{
+				Items: []errors.MultiErrorItem{
+					{Context: "one", Err: newPkgError("failed")},
+					{Context: "two", Err: newPkgError("failed")},
+				},
+				Omitted: 3,
+			},
+			expected: `5 errors (3 omitted)
+├── one: failed
+└── two: failed
+`,
+		},
 		// TODO figure out how to include stack info stabley
 		// {
 		// 	TestCaseInfo: MakeTestCaseInfo(
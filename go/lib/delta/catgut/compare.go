@@ -0,0 +1,63 @@
+package catgut
+
+import (
+	"encoding/binary"
+	"unicode/utf8"
+
+	"code.linenisgreat.com/dodder/go/lib/alfa/cmp"
+)
+
+// compareBytes is cmp.CompareUTF8Bytes without decoding the prefix left and
+// right share. Byte order is code point order for UTF-8, so only the runes
+// from the first one that differs need decoding, and the common prefix is
+// found eight bytes at a time.
+func compareBytes(left, right []byte, partial bool) cmp.Result {
+	prefix := commonPrefixLen(left, right)
+
+	if prefix == 0 {
+		return cmp.CompareUTF8Bytes(left, right, partial)
+	}
+
+	left, right = left[prefix:], right[prefix:]
+
+	switch {
+	case len(left) == 0 && len(right) == 0:
+		return cmp.Equal
+
+	case len(left) == 0:
+		return cmp.Less
+
+	case len(right) == 0:
+		if partial {
+			return cmp.Equal
+		} else {
+			return cmp.Greater
+		}
+	}
+
+	return cmp.CompareUTF8Bytes(left, right, partial)
+}
+
+// commonPrefixLen is the length of the prefix left and right share, backed
+// up to the start of the rune they differ in.
+func commonPrefixLen(left, right []byte) (prefix int) {
+	shortest := min(len(left), len(right))
+
+	for prefix+8 <= shortest &&
+		binary.LittleEndian.Uint64(left[prefix:]) ==
+			binary.LittleEndian.Uint64(right[prefix:]) {
+		prefix += 8
+	}
+
+	for prefix < shortest && left[prefix] == right[prefix] {
+		prefix++
+	}
+
+	for prefix > 0 &&
+		((prefix < len(left) && !utf8.RuneStart(left[prefix])) ||
+			(prefix < len(right) && !utf8.RuneStart(right[prefix]))) {
+		prefix--
+	}
+
+	return prefix
+}
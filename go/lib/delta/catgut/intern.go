@@ -0,0 +1,72 @@
+package catgut
+
+import "sync"
+
+const (
+	// internMaxEntries bounds the intern table, so that interning the tags of
+	// a large repo does not keep all of them alive.
+	internMaxEntries = 1 << 12
+
+	// internMaxBytes keeps long values, which are unlikely to repeat, out of
+	// the intern table.
+	internMaxBytes = 64
+)
+
+var internTable = struct {
+	sync.RWMutex
+	strings map[string]*String
+}{
+	strings: make(map[string]*String),
+}
+
+// Intern returns the String holding v that every caller of Intern shares, for
+// frequent values like tags and keys. Interned Strings must never be written
+// to or repooled. Once the intern table is full, or for values longer than
+// internMaxBytes, Intern returns a new String instead.
+func Intern(v string) *String {
+	internTable.RLock()
+	str, ok := internTable.strings[v]
+	internTable.RUnlock()
+
+	if ok {
+		return str
+	}
+
+	return internNew(v)
+}
+
+// InternBytes is Intern for bytes, and does not allocate for values already
+// interned.
+func InternBytes(b []byte) *String {
+	internTable.RLock()
+	str, ok := internTable.strings[string(b)]
+	internTable.RUnlock()
+
+	if ok {
+		return str
+	}
+
+	return internNew(string(b))
+}
+
+func internNew(v string) (str *String) {
+	str = &String{}
+	str.Set(v)
+
+	if len(v) > internMaxBytes {
+		return str
+	}
+
+	internTable.Lock()
+	defer internTable.Unlock()
+
+	if existing, ok := internTable.strings[v]; ok {
+		return existing
+	}
+
+	if len(internTable.strings) < internMaxEntries {
+		internTable.strings[v] = str
+	}
+
+	return str
+}
@@ -0,0 +1,94 @@
+package catgut
+
+import (
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/alfa/cmp"
+)
+
+func TestInternSharesStrings(t *testing.T) {
+	a := Intern("interned-tag")
+	b := InternBytes([]byte("interned-tag"))
+
+	if a != b {
+		t.Errorf("expected one shared String for a value")
+	}
+
+	if a.String() != "interned-tag" {
+		t.Errorf("expected %q, got %q", "interned-tag", a.String())
+	}
+
+	if !a.Equals(b) || a.Compare(b) != cmp.Equal {
+		t.Errorf("expected a shared String to equal itself")
+	}
+
+	long := strings.Repeat("x", internMaxBytes+1)
+
+	if Intern(long) == Intern(long) {
+		t.Errorf("expected values past internMaxBytes not to be interned")
+	}
+
+	if Intern(long).String() != long {
+		t.Errorf("expected an uninterned String to hold its value")
+	}
+}
+
+func TestSharedRepoolsOnLastRelease(t *testing.T) {
+	shared := MakeSharedFromBytes([]byte("shared"))
+	shared.Retain()
+
+	shared.Release()
+
+	if shared.String.String() != "shared" {
+		t.Fatalf("expected a retained Shared to keep its value")
+	}
+
+	shared.Release()
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected releasing too often to panic")
+		}
+	}()
+
+	shared.Release()
+}
+
+func BenchmarkMakeFromString(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		MakeFromString("project-dodder")
+	}
+}
+
+func BenchmarkIntern(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		Intern("project-dodder")
+	}
+}
+
+func BenchmarkCompareLongPrefix(b *testing.B) {
+	left, _ := MakeFromString("project-dodder-stream-index-pages-a")
+	right, _ := MakeFromString("project-dodder-stream-index-pages-b")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		left.Compare(right)
+	}
+}
+
+func BenchmarkCompareLongPrefixRunes(b *testing.B) {
+	left := []byte("project-dodder-stream-index-pages-a")
+	right := []byte("project-dodder-stream-index-pages-b")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		cmp.CompareUTF8Bytes(left, right, false)
+	}
+}
@@ -0,0 +1,72 @@
+package catgut
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/pool"
+)
+
+// Shared is a pooled String with a reference count, for values handed to
+// several owners that each release it. It goes back to its pool when the last
+// owner releases it, and must not be used after that.
+type Shared struct {
+	String
+	references atomic.Int64
+	repool     interfaces.FuncRepool
+}
+
+var (
+	sharedPool     interfaces.PoolPtr[Shared, *Shared]
+	sharedPoolOnce sync.Once
+)
+
+func getSharedPool() interfaces.PoolPtr[Shared, *Shared] {
+	sharedPoolOnce.Do(
+		func() {
+			sharedPool = pool.Make[Shared, *Shared](
+				nil,
+				func(shared *Shared) {
+					shared.Reset()
+					shared.references.Store(0)
+					shared.repool = nil
+				},
+			)
+		},
+	)
+
+	return sharedPool
+}
+
+// MakeSharedFromBytes returns a Shared holding a copy of b, with one
+// reference for the caller.
+func MakeSharedFromBytes(b []byte) *Shared {
+	shared, repool := getSharedPool().GetWithRepool()
+	shared.repool = repool
+	shared.references.Store(1)
+	shared.SetBytes(b)
+
+	return shared
+}
+
+// Retain adds a reference for another owner.
+func (shared *Shared) Retain() *Shared {
+	if shared.references.Add(1) <= 1 {
+		panic("catgut: retained a released Shared")
+	}
+
+	return shared
+}
+
+// Release drops a reference, repooling the Shared when it was the last.
+func (shared *Shared) Release() {
+	switch references := shared.references.Add(-1); {
+	case references == 0:
+		shared.repool()
+
+	case references < 0:
+		panic(fmt.Sprintf("catgut: released a Shared %d times too often", -references))
+	}
+}
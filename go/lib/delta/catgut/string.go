@@ -109,11 +109,19 @@ func (a *String) ComparePartialOffset(b *String, offset int) cmp.Result {
 }
 
 func (a *String) ComparePartial(b *String) cmp.Result {
-	return cmp.CompareUTF8Bytes(a.Bytes(), b.Bytes(), true)
+	if a == b {
+		return cmp.Equal
+	}
+
+	return compareBytes(a.Bytes(), b.Bytes(), true)
 }
 
 func (a *String) Compare(b *String) cmp.Result {
-	return cmp.CompareUTF8Bytes(a.Bytes(), b.Bytes(), false)
+	if a == b {
+		return cmp.Equal
+	}
+
+	return compareBytes(a.Bytes(), b.Bytes(), false)
 }
 
 func (str *String) String() string {
@@ -136,6 +144,10 @@ func (str *String) IsEmpty() bool {
 }
 
 func (a *String) Equals(b *String) bool {
+	if a == b {
+		return true
+	}
+
 	return bytes.Equal(a.Bytes(), b.Bytes())
 }
 
@@ -35,6 +35,31 @@ func getTestCasesCompare() []testCaseCompare {
 			b:        "test",
 			expected: cmp.Less,
 		},
+		{
+			a:        "a-long-shared-prefix-then-b",
+			b:        "a-long-shared-prefix-then-a",
+			expected: cmp.Greater,
+		},
+		{
+			a:        "a-long-shared-prefix",
+			b:        "a-long-shared-prefix-and-more",
+			expected: cmp.Less,
+		},
+		{
+			a:        "a-long-shared-prefix-and-more",
+			b:        "a-long-shared-prefix",
+			expected: cmp.Greater,
+		},
+		{
+			a:        "prefix-é",
+			b:        "prefix-ê",
+			expected: cmp.Less,
+		},
+		{
+			a:        "prefix-ÿ",
+			b:        "prefix-Ā",
+			expected: cmp.Less,
+		},
 	}
 }
 
@@ -80,6 +105,16 @@ func getTestCasesComparePartial() []testCaseCompare {
 			b:        "test",
 			expected: cmp.Less,
 		},
+		{
+			a:        "a-long-shared-prefix-and-more",
+			b:        "a-long-shared-prefix",
+			expected: cmp.Equal,
+		},
+		{
+			a:        "a-long-shared-prefix",
+			b:        "a-long-shared-prefix-and-more",
+			expected: cmp.Less,
+		},
 	}
 }
 
@@ -0,0 +1,144 @@
+package compression_type
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+)
+
+const (
+	DictionaryMaxSizeDefault = 64 * 1024
+
+	// zstd refuses raw content dictionaries shorter than this
+	dictionaryMinSize = 8
+
+	dictionaryKmerSize    = 8
+	dictionarySegmentSize = 64
+)
+
+// DictionaryId identifies a trained dictionary by the leading bytes of its
+// sha256 digest. Zero is reserved for "no dictionary".
+func DictionaryId(dictionary []byte) uint32 {
+	if len(dictionary) == 0 {
+		return 0
+	}
+
+	digest := sha256.Sum256(dictionary)
+	id := binary.BigEndian.Uint32(digest[:4])
+
+	if id == 0 {
+		id = 1
+	}
+
+	return id
+}
+
+type dictionarySegment struct {
+	data  []byte
+	score int
+}
+
+// TrainDictionary builds a raw content zstd dictionary of at most maxSize
+// bytes from samples. It is a simplified form of zstd's COVER trainer: every
+// 8-byte k-mer is scored by how many samples contain it, fixed-size segments
+// of the samples are ranked by the scores of the k-mers they cover, and the
+// best segments are kept greedily, with k-mers that an earlier segment
+// already covers no longer counting. The best segments are placed last since
+// zstd reaches the end of a dictionary with the cheapest offsets.
+//
+// Returns nil when the samples share no content worth a dictionary.
+func TrainDictionary(samples [][]byte, maxSize int) (dictionary []byte) {
+	if maxSize <= 0 {
+		maxSize = DictionaryMaxSizeDefault
+	}
+
+	kmerSampleCounts := make(map[uint64]int)
+
+	for _, sample := range samples {
+		for kmer := range sampleKmers(sample) {
+			kmerSampleCounts[kmer]++
+		}
+	}
+
+	var segments []dictionarySegment
+
+	for _, sample := range samples {
+		for start := 0; start < len(sample); start += dictionarySegmentSize {
+			end := min(start+dictionarySegmentSize, len(sample))
+
+			segment := dictionarySegment{data: sample[start:end]}
+
+			for kmer := range sampleKmers(segment.data) {
+				if count := kmerSampleCounts[kmer]; count > 1 {
+					segment.score += count
+				}
+			}
+
+			if segment.score > 0 {
+				segments = append(segments, segment)
+			}
+		}
+	}
+
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].score > segments[j].score
+	})
+
+	covered := make(map[uint64]struct{})
+
+	var chosen [][]byte
+	var size int
+
+	for _, segment := range segments {
+		if size+len(segment.data) > maxSize {
+			continue
+		}
+
+		var score int
+
+		kmers := sampleKmers(segment.data)
+
+		for kmer := range kmers {
+			if _, ok := covered[kmer]; ok {
+				continue
+			}
+
+			if count := kmerSampleCounts[kmer]; count > 1 {
+				score += count
+			}
+		}
+
+		if score == 0 {
+			continue
+		}
+
+		for kmer := range kmers {
+			covered[kmer] = struct{}{}
+		}
+
+		chosen = append(chosen, segment.data)
+		size += len(segment.data)
+	}
+
+	if size < dictionaryMinSize {
+		return nil
+	}
+
+	dictionary = make([]byte, 0, size)
+
+	for i := len(chosen) - 1; i >= 0; i-- {
+		dictionary = append(dictionary, chosen[i]...)
+	}
+
+	return dictionary
+}
+
+func sampleKmers(sample []byte) map[uint64]struct{} {
+	kmers := make(map[uint64]struct{})
+
+	for i := 0; i+dictionaryKmerSize <= len(sample); i++ {
+		kmers[binary.LittleEndian.Uint64(sample[i:i+dictionaryKmerSize])] = struct{}{}
+	}
+
+	return kmers
+}
@@ -0,0 +1,104 @@
+package compression_type
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/DataDog/zstd"
+)
+
+const (
+	// ZstdLevelDefault defers to the zstd library's default level.
+	ZstdLevelDefault = 0
+	ZstdLevelMin     = 1
+	ZstdLevelMax     = 22
+)
+
+type ErrUnsupportedZstdLevel int
+
+func (err ErrUnsupportedZstdLevel) Error() string {
+	return fmt.Sprintf(
+		"unsupported zstd level: %d (expected %d-%d, or %d for the default)",
+		int(err),
+		ZstdLevelMin,
+		ZstdLevelMax,
+		ZstdLevelDefault,
+	)
+}
+
+func (ErrUnsupportedZstdLevel) Is(target error) (ok bool) {
+	_, ok = target.(ErrUnsupportedZstdLevel)
+	return ok
+}
+
+func ValidateZstdLevel(level int) (err error) {
+	if level == ZstdLevelDefault {
+		return err
+	}
+
+	if level < ZstdLevelMin || level > ZstdLevelMax {
+		err = ErrUnsupportedZstdLevel(level)
+	}
+
+	return err
+}
+
+// Params extends a CompressionType with the zstd level and the optional
+// dictionary to compress with. Both are ignored by the other compression
+// types. Data compressed with a dictionary can only be read with Params that
+// carry the same dictionary.
+type Params struct {
+	Type       CompressionType
+	Level      int
+	Dictionary []byte
+}
+
+func (params Params) GetZstdLevel() int {
+	if params.Level == ZstdLevelDefault {
+		return zstd.DefaultCompression
+	}
+
+	return params.Level
+}
+
+func (params Params) HasDictionary() bool {
+	return params.Type == CompressionTypeZstd && len(params.Dictionary) > 0
+}
+
+func (params Params) WrapReader(
+	readerIn io.Reader,
+) (readerOut io.ReadCloser, err error) {
+	if !params.HasDictionary() {
+		return params.Type.WrapReader(readerIn)
+	}
+
+	readerOut = zstd.NewReaderDict(readerIn, params.Dictionary)
+
+	return readerOut, err
+}
+
+func (params Params) WrapWriter(
+	writerIn io.Writer,
+) (writerOut io.WriteCloser, err error) {
+	if params.Type != CompressionTypeZstd {
+		return params.Type.WrapWriter(writerIn)
+	}
+
+	if err = ValidateZstdLevel(params.Level); err != nil {
+		return writerOut, err
+	}
+
+	var dictionary []byte
+
+	if params.HasDictionary() {
+		dictionary = params.Dictionary
+	}
+
+	writerOut = zstd.NewWriterLevelDict(
+		writerIn,
+		params.GetZstdLevel(),
+		dictionary,
+	)
+
+	return writerOut, err
+}
@@ -0,0 +1,108 @@
+package files
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// CommitTemp makes a finished temp file durable and moves it into place: the
+// file is fsynced and closed, renamed to path, and the directory is fsynced so
+// that the rename survives a crash. The temp file is always closed, and
+// removed if it could not be committed. Temp files must be created in the
+// destination directory so that the rename is atomic.
+func CommitTemp(tmpFile *os.File, path string) (err error) {
+	tmpPath := tmpFile.Name()
+
+	defer func() {
+		if err != nil {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err = tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		err = errors.Wrapf(err, "syncing temp file %s", tmpPath)
+		return err
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		err = errors.Wrapf(err, "closing temp file %s", tmpPath)
+		return err
+	}
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		err = errors.Wrapf(err, "renaming temp file to %s", path)
+		return err
+	}
+
+	if err = SyncDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	return err
+}
+
+func SyncDir(dir string) (err error) {
+	var dirFile *os.File
+
+	if dirFile, err = os.Open(dir); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, dirFile)
+
+	if err = dirFile.Sync(); err != nil {
+		err = errors.Wrapf(err, "syncing directory %s", dir)
+		return err
+	}
+
+	return err
+}
+
+// WriteAtomic streams the output of write into a temp file next to path and
+// commits it with CommitTemp, so that readers never see a partial file: after
+// a crash path holds either its old contents or the new ones.
+func WriteAtomic(
+	path string,
+	write func(writer io.Writer) error,
+) (err error) {
+	dir := filepath.Dir(path)
+
+	var tmpFile *os.File
+
+	if tmpFile, err = os.CreateTemp(dir, filepath.Base(path)+"-*.tmp"); err != nil {
+		err = errors.Wrapf(err, "creating temp file in %s", dir)
+		return err
+	}
+
+	bufferedWriter := bufio.NewWriter(tmpFile)
+
+	if err = write(bufferedWriter); err == nil {
+		err = bufferedWriter.Flush()
+	}
+
+	if err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		err = errors.Wrapf(err, "writing %s", path)
+		return err
+	}
+
+	return CommitTemp(tmpFile, path)
+}
+
+// WriteFileAtomic is WriteAtomic for contents already in memory.
+func WriteFileAtomic(path string, data []byte) (err error) {
+	return WriteAtomic(
+		path,
+		func(writer io.Writer) (err error) {
+			_, err = writer.Write(data)
+			return err
+		},
+	)
+}
@@ -0,0 +1,71 @@
+package files
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteAtomicReplacesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(contents) != "new" {
+		t.Errorf("expected %q, got %q", "new", contents)
+	}
+}
+
+func TestWriteAtomicFailureKeepsOldFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache")
+
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("interrupted")
+
+	err := WriteAtomic(
+		path,
+		func(writer io.Writer) error {
+			writer.Write([]byte("partial"))
+			return failure
+		},
+	)
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(contents) != "old" {
+		t.Errorf("expected %q, got %q", "old", contents)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("expected temp file to be removed, found %d entries", len(entries))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
 )
 
 type (
@@ -38,3 +39,37 @@ func (err ErrNotDirectory) Error() string {
 func (err ErrNotDirectory) GetErrorType() pkgErrDisamb {
 	return pkgErrDisamb{}
 }
+
+func IsErrInsufficientSpace(err error) bool {
+	return errors.Is(err, ErrInsufficientSpace{})
+}
+
+// ErrInsufficientSpace is returned by writes that were estimated not to fit
+// in the free space of the filesystem holding Path, before any was made.
+type ErrInsufficientSpace struct {
+	Path           string
+	RequiredBytes  uint64
+	AvailableBytes uint64
+}
+
+func (err ErrInsufficientSpace) Error() string {
+	return fmt.Sprintf(
+		"insufficient space in %q: %s required, %s available",
+		err.Path,
+		ui.GetHumanBytesString(err.RequiredBytes),
+		ui.GetHumanBytesString(err.AvailableBytes),
+	)
+}
+
+func (err ErrInsufficientSpace) Is(target error) bool {
+	_, ok := target.(ErrInsufficientSpace)
+	return ok
+}
+
+func (err ErrInsufficientSpace) GetErrorCode() errors.Code {
+	return errors.CodeInsufficientSpace
+}
+
+func (err ErrInsufficientSpace) GetErrorType() pkgErrDisamb {
+	return pkgErrDisamb{}
+}
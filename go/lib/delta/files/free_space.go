@@ -0,0 +1,61 @@
+package files
+
+import (
+	"path/filepath"
+	"syscall"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// GetAvailableBytes returns the bytes an unprivileged user can still write to
+// the filesystem holding path. A path that does not exist yet is measured at
+// its closest existing parent.
+func GetAvailableBytes(path string) (available uint64, err error) {
+	var stat syscall.Statfs_t
+
+	for {
+		if err = syscall.Statfs(path, &stat); err == nil {
+			break
+		}
+
+		parent := filepath.Dir(path)
+
+		if !errors.IsNotExist(err) || parent == path {
+			err = errors.Wrapf(err, "Path: %q", path)
+			return available, err
+		}
+
+		path = parent
+	}
+
+	available = uint64(stat.Bavail) * uint64(stat.Bsize)
+
+	return available, err
+}
+
+// CheckAvailableBytes fails with ErrInsufficientSpace when fewer than
+// required bytes are free on the filesystem holding path.
+func CheckAvailableBytes(path string, required uint64) (err error) {
+	if required == 0 {
+		return err
+	}
+
+	var available uint64
+
+	if available, err = GetAvailableBytes(path); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if available < required {
+		err = errors.Wrap(ErrInsufficientSpace{
+			Path:           path,
+			RequiredBytes:  required,
+			AvailableBytes: available,
+		})
+
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,43 @@
+package files
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetAvailableBytesOfMissingPath(t *testing.T) {
+	dir := t.TempDir()
+
+	expected, err := GetAvailableBytes(dir)
+	if err != nil {
+		t.Fatalf("GetAvailableBytes: %v", err)
+	}
+
+	if expected == 0 {
+		t.Skip("no free space in the temp dir")
+	}
+
+	actual, err := GetAvailableBytes(filepath.Join(dir, "missing", "child"))
+	if err != nil {
+		t.Fatalf("GetAvailableBytes of a missing path: %v", err)
+	}
+
+	if actual == 0 {
+		t.Errorf("expected the parent's free space, got none")
+	}
+}
+
+func TestCheckAvailableBytes(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := CheckAvailableBytes(dir, 1); err != nil {
+		t.Fatalf("CheckAvailableBytes: %v", err)
+	}
+
+	err := CheckAvailableBytes(dir, math.MaxUint64)
+
+	if !IsErrInsufficientSpace(err) {
+		t.Fatalf("expected ErrInsufficientSpace, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package script_config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/lib/_/equality"
+	"code.linenisgreat.com/dodder/go/lib/_/reset"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const ExternalToolDefaultTimeout = time.Minute
+
+var externalToolPlaceholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// ExternalTool is a command run on files written to a scratch directory, like
+// a diff or merge tool for the blobs of a binary type. Every `{name}` in
+// Command is replaced by the path of the file with that name.
+//
+// Tools run sandboxed: in the scratch directory, with HOME and TMPDIR pointing
+// at it, without stdin, and with only PATH and the variables named in
+// InheritEnv from the environment. They are killed after Timeout.
+type ExternalTool struct {
+	Command    []string `toml:"command"`
+	Timeout    string   `toml:"timeout,omitempty"`
+	InheritEnv []string `toml:"inherit-env,omitempty"`
+}
+
+func (tool *ExternalTool) Reset() {
+	tool.Command = reset.Slice(tool.Command)
+	tool.Timeout = ""
+	tool.InheritEnv = reset.Slice(tool.InheritEnv)
+}
+
+func (tool ExternalTool) Equals(other ExternalTool) bool {
+	return equality.SliceOrdered(tool.Command, other.Command) &&
+		tool.Timeout == other.Timeout &&
+		equality.SliceOrdered(tool.InheritEnv, other.InheritEnv)
+}
+
+func (tool ExternalTool) GetTimeout() (timeout time.Duration, err error) {
+	if tool.Timeout == "" {
+		timeout = ExternalToolDefaultTimeout
+		return timeout, err
+	}
+
+	if timeout, err = time.ParseDuration(tool.Timeout); err != nil {
+		err = errors.BadRequestf("invalid tool timeout %q: %s", tool.Timeout, err)
+		return timeout, err
+	}
+
+	if timeout <= 0 {
+		err = errors.BadRequestf("tool timeout must be positive: %q", tool.Timeout)
+		return timeout, err
+	}
+
+	return timeout, err
+}
+
+// Args replaces the placeholders in Command with the paths they name,
+// failing on placeholders without a path so that a misspelled one is not
+// passed to the tool as is.
+func (tool ExternalTool) Args(paths map[string]string) (args []string, err error) {
+	if len(tool.Command) == 0 {
+		err = errors.BadRequestf("tool has no command")
+		return args, err
+	}
+
+	args = make([]string, len(tool.Command))
+
+	for i, arg := range tool.Command {
+		args[i] = externalToolPlaceholder.ReplaceAllStringFunc(
+			arg,
+			func(placeholder string) string {
+				name := placeholder[1 : len(placeholder)-1]
+				path, ok := paths[name]
+
+				if !ok && err == nil {
+					err = errors.BadRequestf(
+						"unknown placeholder %q in tool command %q",
+						placeholder,
+						tool.Command,
+					)
+				}
+
+				return path
+			},
+		)
+	}
+
+	return args, err
+}
+
+// Run runs the tool in dir, returning its output. Exit codes in okExitCodes
+// are not errors, as diff tools exit with 1 when their inputs differ.
+func (tool ExternalTool) Run(
+	ctx context.Context,
+	dir string,
+	paths map[string]string,
+	okExitCodes ...int,
+) (output []byte, err error) {
+	var args []string
+
+	if args, err = tool.Args(paths); err != nil {
+		err = errors.Wrap(err)
+		return output, err
+	}
+
+	var timeout time.Duration
+
+	if timeout, err = tool.GetTimeout(); err != nil {
+		err = errors.Wrap(err)
+		return output, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = tool.environ(dir)
+	cmd.WaitDelay = time.Second
+
+	output, err = cmd.Output()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		err = errors.Errorf("tool %q timed out after %s", args[0], timeout)
+		return output, err
+	}
+
+	var exitError *exec.ExitError
+
+	if errors.As(err, &exitError) {
+		for _, code := range okExitCodes {
+			if exitError.ExitCode() == code {
+				err = nil
+				return output, err
+			}
+		}
+
+		err = errors.Errorf(
+			"tool %q failed: %s: %s",
+			args[0],
+			err,
+			exitError.Stderr,
+		)
+
+		return output, err
+	} else if err != nil {
+		err = errors.Wrapf(err, "Cmd: %q", args)
+		return output, err
+	}
+
+	return output, err
+}
+
+func (tool ExternalTool) environ(dir string) (environ []string) {
+	environ = append(
+		environ,
+		fmt.Sprintf("PATH=%s", os.Getenv("PATH")),
+		fmt.Sprintf("HOME=%s", dir),
+		fmt.Sprintf("TMPDIR=%s", dir),
+	)
+
+	for _, key := range tool.InheritEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			environ = append(environ, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+
+	return environ
+}
@@ -0,0 +1,88 @@
+//go:build test
+
+package script_config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"code.linenisgreat.com/dodder/go/lib/charlie/ui"
+)
+
+func TestExternalToolArgs(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	tool := ExternalTool{
+		Command: []string{"diff-pdf", "--output={new}.diff", "{old}", "{new}"},
+	}
+
+	args, err := tool.Args(map[string]string{"old": "/a", "new": "/b"})
+	t.AssertNoError(err)
+
+	expected := []string{"diff-pdf", "--output=/b.diff", "/a", "/b"}
+
+	if strings.Join(args, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %q but got %q", expected, args)
+	}
+
+	if _, err := tool.Args(map[string]string{"old": "/a"}); err == nil {
+		t.Errorf("expected an error for an unknown placeholder")
+	}
+}
+
+func TestExternalToolTimeout(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	if timeout, err := (ExternalTool{}).GetTimeout(); err != nil ||
+		timeout != ExternalToolDefaultTimeout {
+		t.Errorf("expected the default timeout but got %s, %v", timeout, err)
+	}
+
+	for _, invalid := range []string{"soon", "-1s"} {
+		if _, err := (ExternalTool{Timeout: invalid}).GetTimeout(); err == nil {
+			t.Errorf("expected an error for timeout %q", invalid)
+		}
+	}
+
+	tool := ExternalTool{
+		Command: []string{"sleep", "5"},
+		Timeout: "10ms",
+	}
+
+	if _, err := tool.Run(context.Background(), t1.TempDir(), nil); err == nil ||
+		!strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout but got %v", err)
+	}
+}
+
+func TestExternalToolRunSandboxed(t1 *testing.T) {
+	t := ui.T{T: t1}
+
+	t1.Setenv("DODDER_TEST_SECRET", "secret")
+	t1.Setenv("DODDER_TEST_INHERITED", "inherited")
+
+	dir := t1.TempDir()
+
+	tool := ExternalTool{
+		Command: []string{
+			"sh",
+			"-c",
+			`echo "$PWD $HOME $DODDER_TEST_SECRET $DODDER_TEST_INHERITED"; exit 1`,
+		},
+		InheritEnv: []string{"DODDER_TEST_INHERITED"},
+	}
+
+	if _, err := tool.Run(context.Background(), dir, nil); err == nil {
+		t.Errorf("expected exit code 1 to fail")
+	}
+
+	output, err := tool.Run(context.Background(), dir, nil, 1)
+	t.AssertNoError(err)
+
+	expected := dir + " " + dir + "  inherited\n"
+
+	if string(output) != expected {
+		t.Errorf("expected %q but got %q", expected, output)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 type (
 	StrictMissingError = toml.StrictMissingError
+	DecodeError        = toml.DecodeError
 
 	pkgErrDisamb struct{}
 	pkgError     = errors.Typed[pkgErrDisamb]
@@ -8,3 +8,9 @@ var (
 	NewDecoder = toml.NewDecoder
 	NewEncoder = toml.NewEncoder
 )
+
+type (
+	LocalDate     = toml.LocalDate
+	LocalDateTime = toml.LocalDateTime
+	LocalTime     = toml.LocalTime
+)
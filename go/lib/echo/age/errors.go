@@ -8,6 +8,6 @@ import (
 type NoIdentityMatchError = age.NoIdentityMatchError
 
 func IsNoIdentityMatchError(err error) bool {
-	_, ok := errors.Unwrap(err).(*NoIdentityMatchError)
-	return ok
+	var noIdentityMatch *NoIdentityMatchError
+	return errors.As(err, &noIdentityMatch)
 }
@@ -10,12 +10,60 @@ import (
 
 type MultiIdentity struct {
 	identities []Identity
+
+	// only decrypt: the identities of keys that are being rotated out
+	retired []Identity
 }
 
 func MakeMultiIdentity(identities []Identity) MultiIdentity {
 	return MultiIdentity{identities: identities}
 }
 
+// MakeMultiIdentityWithRetired returns a MultiIdentity that encrypts to
+// identities and decrypts with both identities and retired.
+func MakeMultiIdentityWithRetired(
+	identities []Identity,
+	retired []Identity,
+) MultiIdentity {
+	return MultiIdentity{identities: identities, retired: retired}
+}
+
+func getAgeIdentities(
+	identitySlices ...[]Identity,
+) (ageIdentities []age.Identity) {
+	for _, identities := range identitySlices {
+		for i := range identities {
+			ageIdentities = append(ageIdentities, &identities[i])
+		}
+	}
+
+	return ageIdentities
+}
+
+// IsEncryptedToRetired reports whether one of the retired identities can
+// decrypt src. Only the header of src is read.
+func (mi *MultiIdentity) IsEncryptedToRetired(
+	src io.Reader,
+) (ok bool, err error) {
+	if len(mi.retired) == 0 {
+		return ok, err
+	}
+
+	if _, err = age.Decrypt(src, getAgeIdentities(mi.retired)...); err != nil {
+		if IsNoIdentityMatchError(err) {
+			err = nil
+		} else {
+			err = errors.Wrap(err)
+		}
+
+		return ok, err
+	}
+
+	ok = true
+
+	return ok, err
+}
+
 func (mi *MultiIdentity) WrapReader(
 	src io.Reader,
 ) (out io.ReadCloser, err error) {
@@ -24,15 +72,12 @@ func (mi *MultiIdentity) WrapReader(
 		return out, err
 	}
 
-	ageIdentities := make([]age.Identity, len(mi.identities))
-
-	for i := range mi.identities {
-		ageIdentities[i] = &mi.identities[i]
-	}
-
 	var reader io.Reader
 
-	if reader, err = age.Decrypt(src, ageIdentities...); err != nil {
+	if reader, err = age.Decrypt(
+		src,
+		getAgeIdentities(mi.identities, mi.retired)...,
+	); err != nil {
 		err = errors.Wrap(err)
 		return out, err
 	}
@@ -42,8 +42,19 @@ func MakeEditorWithVimOptions(
 	funcUI interfaces.FuncIter[string],
 	options []string,
 ) (Editor, error) {
-	return MakeEditor(
+	return MakeEditorWithUtilityAndVimOptions(funcUI, "", options)
+}
+
+// MakeEditorWithUtilityAndVimOptions is MakeEditorWithVimOptions running
+// utility instead of the editor of $EDITOR, unless utility is empty.
+func MakeEditorWithUtilityAndVimOptions(
+	funcUI interfaces.FuncIter[string],
+	utility string,
+	options []string,
+) (Editor, error) {
+	return MakeEditorWithUtility(
 		funcUI,
+		utility,
 		map[Type][]string{
 			TypeVim: options,
 		},
@@ -54,7 +65,22 @@ func MakeEditor(
 	funcUI interfaces.FuncIter[string],
 	options map[Type][]string,
 ) (editor Editor, err error) {
-	editor.utility = getEditorUtility()
+	return MakeEditorWithUtility(funcUI, "", options)
+}
+
+// MakeEditorWithUtility is MakeEditor running utility instead of the editor
+// of $EDITOR, unless utility is empty.
+func MakeEditorWithUtility(
+	funcUI interfaces.FuncIter[string],
+	utilityString string,
+	options map[Type][]string,
+) (editor Editor, err error) {
+	editor.utility = utilityString
+
+	if editor.utility == "" {
+		editor.utility = getEditorUtility()
+	}
+
 	editor.ui = funcUI
 
 	var utility []string
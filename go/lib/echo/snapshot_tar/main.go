@@ -0,0 +1,321 @@
+// Package snapshot_tar writes tarballs of named directory roots that end with
+// a manifest of every file's size and sha256, and reads them back, so that a
+// snapshot can be verified in full before any of it is unpacked.
+package snapshot_tar
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+const (
+	// ManifestName is the name of the tarball's last entry, which lists the
+	// files before it.
+	ManifestName = "MANIFEST"
+
+	manifestHeader = "snapshot_tar-v1"
+)
+
+// Root is a directory included in a snapshot. Its files are stored under
+// Name, which restores map back to a directory.
+type Root struct {
+	Name string
+	Path string
+}
+
+type ManifestEntry struct {
+	// Path is the slash-separated name of the file in the tarball, starting
+	// with its root's name.
+	Path   string
+	Size   int64
+	Digest []byte
+}
+
+type Manifest struct {
+	Entries []ManifestEntry
+}
+
+func (manifest Manifest) GetTotalSize() (size int64) {
+	for _, entry := range manifest.Entries {
+		size += entry.Size
+	}
+
+	return size
+}
+
+func (manifest Manifest) byPath() map[string]ManifestEntry {
+	entries := make(map[string]ManifestEntry, len(manifest.Entries))
+
+	for _, entry := range manifest.Entries {
+		entries[entry.Path] = entry
+	}
+
+	return entries
+}
+
+func (manifest Manifest) WriteTo(writer io.Writer) (n int64, err error) {
+	bufferedWriter := bufio.NewWriter(writer)
+
+	var written int
+
+	if written, err = fmt.Fprintln(bufferedWriter, manifestHeader); err != nil {
+		err = errors.Wrap(err)
+		return n, err
+	}
+
+	n += int64(written)
+
+	for _, entry := range manifest.Entries {
+		if written, err = fmt.Fprintf(
+			bufferedWriter,
+			"%x %d %s\n",
+			entry.Digest,
+			entry.Size,
+			entry.Path,
+		); err != nil {
+			err = errors.Wrap(err)
+			return n, err
+		}
+
+		n += int64(written)
+	}
+
+	if err = bufferedWriter.Flush(); err != nil {
+		err = errors.Wrap(err)
+		return n, err
+	}
+
+	return n, err
+}
+
+func ReadManifest(reader io.Reader) (manifest Manifest, err error) {
+	scanner := bufio.NewScanner(reader)
+
+	if !scanner.Scan() || scanner.Text() != manifestHeader {
+		err = errors.Errorf("not a %s manifest", manifestHeader)
+		return manifest, err
+	}
+
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 3)
+
+		if len(fields) != 3 {
+			err = errors.Errorf("malformed manifest line: %q", scanner.Text())
+			return manifest, err
+		}
+
+		var entry ManifestEntry
+
+		if entry.Digest, err = hex.DecodeString(fields[0]); err != nil {
+			err = errors.Wrapf(err, "manifest line: %q", scanner.Text())
+			return manifest, err
+		}
+
+		if entry.Size, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+			err = errors.Wrapf(err, "manifest line: %q", scanner.Text())
+			return manifest, err
+		}
+
+		entry.Path = fields[2]
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	return manifest, err
+}
+
+// Writer writes the files of each root added to it to a tarball, and the
+// manifest of them on Close.
+type Writer struct {
+	tarWriter *tar.Writer
+	manifest  Manifest
+}
+
+func NewWriter(writer io.Writer) *Writer {
+	return &Writer{tarWriter: tar.NewWriter(writer)}
+}
+
+// AddRoot adds the directories and regular files under root, in lexical order,
+// leaving out those skip reports true for (skipping a directory skips all of
+// it). Other kinds of files, like sockets and symlinks, are left out. A root
+// that does not exist adds nothing.
+func (writer *Writer) AddRoot(
+	root Root,
+	skip func(path string) bool,
+) (err error) {
+	if root.Name == "" || root.Name == ManifestName ||
+		strings.ContainsAny(root.Name, "\n") {
+		err = errors.Errorf("invalid root name: %q", root.Name)
+		return err
+	}
+
+	if _, err = os.Stat(root.Path); errors.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = filepath.WalkDir(
+		root.Path,
+		func(filePath string, dirEntry fs.DirEntry, err error) error {
+			if err != nil {
+				return errors.Wrap(err)
+			}
+
+			if skip != nil && skip(filePath) {
+				if dirEntry.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			rel, err := filepath.Rel(root.Path, filePath)
+			if err != nil {
+				return errors.Wrap(err)
+			}
+
+			name := path.Join(root.Name, filepath.ToSlash(rel))
+
+			if strings.Contains(name, "\n") {
+				return errors.Errorf("file name contains a newline: %q", filePath)
+			}
+
+			switch {
+			case dirEntry.IsDir():
+				return writer.addDir(filePath, name)
+
+			case dirEntry.Type().IsRegular():
+				return writer.addFile(filePath, name)
+
+			default:
+				return nil
+			}
+		},
+	); err != nil {
+		err = errors.Wrapf(err, "root: %q", root.Name)
+		return err
+	}
+
+	return err
+}
+
+func (writer *Writer) addDir(filePath, name string) (err error) {
+	var info fs.FileInfo
+
+	if info, err = os.Stat(filePath); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = writer.tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name + "/",
+		Mode:     int64(info.Mode().Perm()),
+		ModTime:  info.ModTime(),
+	}); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
+
+func (writer *Writer) addFile(filePath, name string) (err error) {
+	var file *os.File
+
+	if file, err = os.Open(filePath); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	defer errors.DeferredCloser(&err, file)
+
+	var info fs.FileInfo
+
+	if info, err = file.Stat(); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = writer.tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(info.Mode().Perm()),
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+	}); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	hash := sha256.New()
+
+	if _, err = io.Copy(
+		io.MultiWriter(writer.tarWriter, hash),
+		file,
+	); err != nil {
+		err = errors.Wrapf(err, "file: %q", filePath)
+		return err
+	}
+
+	writer.manifest.Entries = append(writer.manifest.Entries, ManifestEntry{
+		Path:   name,
+		Size:   info.Size(),
+		Digest: hash.Sum(nil),
+	})
+
+	return err
+}
+
+// Close writes the manifest as the tarball's last entry and finishes the
+// tarball, without closing the underlying writer.
+func (writer *Writer) Close() (manifest Manifest, err error) {
+	var manifestBuffer bytes.Buffer
+
+	if _, err = writer.manifest.WriteTo(&manifestBuffer); err != nil {
+		return manifest, err
+	}
+
+	if err = writer.tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     ManifestName,
+		Mode:     0o644,
+		Size:     int64(manifestBuffer.Len()),
+		ModTime:  time.Now(),
+	}); err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	if _, err = manifestBuffer.WriteTo(writer.tarWriter); err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	if err = writer.tarWriter.Close(); err != nil {
+		err = errors.Wrap(err)
+		return manifest, err
+	}
+
+	return writer.manifest, err
+}
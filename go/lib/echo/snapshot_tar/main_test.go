@@ -0,0 +1,156 @@
+package snapshot_tar
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(contents), 0o444); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestSnapshot(t *testing.T) (snapshot []byte, manifest Manifest) {
+	t.Helper()
+
+	data := t.TempDir()
+	config := t.TempDir()
+
+	writeTestFile(t, filepath.Join(data, "index", "objects"), "object index")
+	writeTestFile(t, filepath.Join(data, "inventory_lists_log"), "lists")
+	writeTestFile(t, filepath.Join(data, "lock"), "")
+	writeTestFile(t, filepath.Join(config, "config-mutable"), "config")
+
+	var buffer bytes.Buffer
+
+	writer := NewWriter(&buffer)
+
+	if err := writer.AddRoot(
+		Root{Name: "data", Path: data},
+		func(path string) bool {
+			return path == filepath.Join(data, "lock")
+		},
+	); err != nil {
+		t.Fatalf("AddRoot: %v", err)
+	}
+
+	if err := writer.AddRoot(Root{Name: "config", Path: config}, nil); err != nil {
+		t.Fatalf("AddRoot: %v", err)
+	}
+
+	if err := writer.AddRoot(
+		Root{Name: "missing", Path: filepath.Join(config, "missing")},
+		nil,
+	); err != nil {
+		t.Fatalf("AddRoot: %v", err)
+	}
+
+	manifest, err := writer.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	return buffer.Bytes(), manifest
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snapshot, written := writeTestSnapshot(t)
+
+	if len(written.Entries) != 3 {
+		t.Fatalf("expected 3 manifest entries, got %+v", written.Entries)
+	}
+
+	manifest, err := Verify(bytes.NewReader(snapshot))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if manifest.GetTotalSize() != written.GetTotalSize() {
+		t.Errorf(
+			"expected %d bytes, got %d",
+			written.GetTotalSize(),
+			manifest.GetTotalSize(),
+		)
+	}
+
+	data := filepath.Join(t.TempDir(), "data")
+	config := filepath.Join(t.TempDir(), "config")
+
+	if err := Extract(
+		bytes.NewReader(snapshot),
+		manifest,
+		map[string]string{"data": data, "config": config},
+	); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	contents, err := os.ReadFile(filepath.Join(data, "index", "objects"))
+	if err != nil || string(contents) != "object index" {
+		t.Errorf("expected the object index, got %q (%v)", contents, err)
+	}
+
+	info, err := os.Stat(filepath.Join(config, "config-mutable"))
+	if err != nil || info.Mode().Perm() != 0o444 {
+		t.Errorf("expected a read-only config, got %v (%v)", info, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(data, "lock")); !os.IsNotExist(err) {
+		t.Errorf("expected the skipped lock to be left out: %v", err)
+	}
+
+	// extracting again never overwrites
+	if err := Extract(
+		bytes.NewReader(snapshot),
+		manifest,
+		map[string]string{"data": data, "config": config},
+	); err == nil {
+		t.Error("expected extracting over existing files to fail")
+	}
+}
+
+func TestVerifyRejectsCorruptSnapshots(t *testing.T) {
+	snapshot, _ := writeTestSnapshot(t)
+
+	corrupt := bytes.Replace(
+		bytes.Clone(snapshot),
+		[]byte("object index"),
+		[]byte("object inDex"),
+		1,
+	)
+
+	if _, err := Verify(bytes.NewReader(corrupt)); err == nil {
+		t.Error("expected a digest mismatch")
+	}
+
+	withoutManifest := snapshot[:bytes.LastIndex(snapshot, []byte(ManifestName))]
+
+	if _, err := Verify(bytes.NewReader(withoutManifest)); err == nil {
+		t.Error("expected a truncated snapshot to fail")
+	}
+}
+
+func TestExtractRejectsUnknownRoots(t *testing.T) {
+	snapshot, manifest := writeTestSnapshot(t)
+	data := t.TempDir()
+
+	if err := Extract(
+		bytes.NewReader(snapshot),
+		manifest,
+		map[string]string{"data": data},
+	); err == nil {
+		t.Error("expected a root without a destination to fail")
+	}
+
+	if dirEntries, _ := os.ReadDir(data); len(dirEntries) != 0 {
+		t.Errorf("expected nothing to be extracted, got %v", dirEntries)
+	}
+}
@@ -0,0 +1,269 @@
+package snapshot_tar
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
+)
+
+// Verify reads a whole tarball and checks that its files are exactly those of
+// its manifest, with the sizes and digests it lists.
+func Verify(reader io.Reader) (manifest Manifest, err error) {
+	tarReader := tar.NewReader(reader)
+	actual := make(map[string]ManifestEntry)
+
+	var hasManifest bool
+
+	for {
+		var header *tar.Header
+
+		if header, err = tarReader.Next(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			err = errors.Wrap(err)
+			return manifest, err
+		}
+
+		if header.Name == ManifestName {
+			if manifest, err = ReadManifest(tarReader); err != nil {
+				return manifest, err
+			}
+
+			hasManifest = true
+
+			continue
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		hash := sha256.New()
+
+		var size int64
+
+		if size, err = io.Copy(hash, tarReader); err != nil {
+			err = errors.Wrapf(err, "file: %q", header.Name)
+			return manifest, err
+		}
+
+		actual[header.Name] = ManifestEntry{
+			Path:   header.Name,
+			Size:   size,
+			Digest: hash.Sum(nil),
+		}
+	}
+
+	if !hasManifest {
+		err = errors.Errorf("snapshot has no %s", ManifestName)
+		return manifest, err
+	}
+
+	for _, expected := range manifest.Entries {
+		entry, ok := actual[expected.Path]
+
+		if !ok {
+			err = errors.Errorf("missing from snapshot: %q", expected.Path)
+			return manifest, err
+		}
+
+		if err = checkEntry(expected, entry); err != nil {
+			return manifest, err
+		}
+
+		delete(actual, expected.Path)
+	}
+
+	for path := range actual {
+		err = errors.Errorf("not in the manifest: %q", path)
+		return manifest, err
+	}
+
+	return manifest, err
+}
+
+func checkEntry(expected, actual ManifestEntry) (err error) {
+	if actual.Size != expected.Size {
+		err = errors.Errorf(
+			"size mismatch for %q: manifest has %d bytes, snapshot %d",
+			expected.Path,
+			expected.Size,
+			actual.Size,
+		)
+
+		return err
+	}
+
+	if !bytes.Equal(actual.Digest, expected.Digest) {
+		err = errors.Errorf(
+			"digest mismatch for %q: manifest has %x, snapshot %x",
+			expected.Path,
+			expected.Digest,
+			actual.Digest,
+		)
+
+		return err
+	}
+
+	return err
+}
+
+// Extract writes the files of a tarball that Verify accepted to the
+// directories roots maps their root names to. It fails before writing
+// anything when a file of the manifest has no destination. Files are checked
+// against manifest again as they are written, and existing files are never
+// overwritten.
+func Extract(
+	reader io.Reader,
+	manifest Manifest,
+	roots map[string]string,
+) (err error) {
+	for _, entry := range manifest.Entries {
+		if _, err = destinationPath(entry.Path, roots); err != nil {
+			return err
+		}
+	}
+
+	tarReader := tar.NewReader(reader)
+	expected := manifest.byPath()
+
+	for {
+		var header *tar.Header
+
+		if header, err = tarReader.Next(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			err = errors.Wrap(err)
+			return err
+		}
+
+		if header.Name == ManifestName {
+			continue
+		}
+
+		var destination string
+
+		if destination, err = destinationPath(header.Name, roots); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(
+				destination,
+				os.FileMode(header.Mode).Perm()|0o700,
+			); err != nil {
+				err = errors.Wrap(err)
+				return err
+			}
+
+		case tar.TypeReg:
+			entry, ok := expected[header.Name]
+
+			if !ok {
+				err = errors.Errorf("not in the manifest: %q", header.Name)
+				return err
+			}
+
+			if err = extractFile(tarReader, header, entry, destination); err != nil {
+				return err
+			}
+		}
+	}
+
+	return err
+}
+
+func destinationPath(
+	name string,
+	roots map[string]string,
+) (destination string, err error) {
+	rootName, rel, _ := strings.Cut(strings.TrimSuffix(name, "/"), "/")
+
+	rootPath, ok := roots[rootName]
+
+	if !ok {
+		err = errors.Errorf("no destination for root %q of %q", rootName, name)
+		return destination, err
+	}
+
+	if rel == "" {
+		return rootPath, err
+	}
+
+	rel = filepath.FromSlash(rel)
+
+	if !filepath.IsLocal(rel) {
+		err = errors.Errorf("path escapes its root: %q", name)
+		return destination, err
+	}
+
+	return filepath.Join(rootPath, rel), err
+}
+
+func extractFile(
+	reader io.Reader,
+	header *tar.Header,
+	expected ManifestEntry,
+	destination string,
+) (err error) {
+	if err = os.MkdirAll(filepath.Dir(destination), 0o700); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	var file *os.File
+
+	if file, err = os.OpenFile(
+		destination,
+		os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+		os.FileMode(header.Mode).Perm()|0o200,
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	hash := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(file, hash), reader)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		err = errors.Wrapf(err, "file: %q", destination)
+		return err
+	}
+
+	if err = checkEntry(expected, ManifestEntry{
+		Path:   header.Name,
+		Size:   size,
+		Digest: hash.Sum(nil),
+	}); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(
+		destination,
+		os.FileMode(header.Mode).Perm(),
+	); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	if err = os.Chtimes(destination, header.ModTime, header.ModTime); err != nil {
+		err = errors.Wrap(err)
+		return err
+	}
+
+	return err
+}
@@ -4,16 +4,36 @@ import (
 	"io"
 
 	"code.linenisgreat.com/dodder/go/lib/_/interfaces"
+	"code.linenisgreat.com/dodder/go/lib/alfa/structured_log"
+	"code.linenisgreat.com/dodder/go/lib/bravo/errors"
 	"code.linenisgreat.com/dodder/go/lib/delta/cli"
 	"code.linenisgreat.com/dodder/go/lib/echo/debug"
 )
 
+const (
+	ErrorFormatText = "text"
+	ErrorFormatJSON = "json"
+)
+
 type Config struct {
 	Debug   debug.Options
 	Verbose bool
 	Quiet   bool
-	Todo    bool
-	dryRun  bool
+
+	// -v and -vv, the verbosity of structured logs
+	LogInfo  bool
+	LogDebug bool
+	Todo     bool
+	dryRun   bool
+
+	// ReadOnly makes writes to the repo and its blob stores fail with
+	// env_dir.ErrReadOnly instead of deep in a write path.
+	ReadOnly bool
+
+	// ErrorFormat selects how fatal errors are written to stderr:
+	// ErrorFormatText (the default error tree) or ErrorFormatJSON (an
+	// errors.ErrorEnvelope).
+	ErrorFormat string
 
 	// CustomOut and CustomErr override os.Stdout/os.Stderr when set.
 	// Used by MCP handlers to capture command output into buffers.
@@ -27,8 +47,29 @@ func (config *Config) SetFlagDefinitions(flagSet interfaces.CLIFlagDefinitions)
 	cli.FlagSetVarWithCompletion(flagSet, &config.Debug, "debug")
 	flagSet.BoolVar(&config.Todo, "todo", false, "")
 	flagSet.BoolVar(&config.dryRun, "dry-run", false, "")
+	flagSet.BoolVar(
+		&config.ReadOnly,
+		"read-only",
+		false,
+		"refuse writes to the repo and its blob stores",
+	)
 	flagSet.BoolVar(&config.Verbose, "verbose", false, "")
 	flagSet.BoolVar(&config.Quiet, "quiet", false, "")
+	flagSet.BoolVar(&config.LogInfo, "v", false, "log store decisions")
+	flagSet.BoolVar(
+		&config.LogDebug,
+		"vv",
+		false,
+		"log store decisions in detail, also implied by -verbose",
+	)
+
+	// commands like show and log define a -format of their own, so the error
+	// format gets a flag that no command shadows
+	flagSet.Func(
+		"error-format",
+		"format for fatal errors on stderr: text or json (default text)",
+		config.SetErrorFormat,
+	)
 }
 
 func Default() (config *Config) {
@@ -43,6 +84,10 @@ func (config *Config) SetDryRun(v bool) {
 	config.dryRun = v
 }
 
+func (config Config) IsReadOnly() bool {
+	return config.ReadOnly
+}
+
 func (config Config) GetConfigCLI() Config {
 	return config
 }
@@ -51,10 +96,47 @@ func (config Config) GetVerbose() bool {
 	return config.Verbose
 }
 
+// GetLogVerbosity returns the structured_log.Verbosity of -v and -vv. The
+// ui.Log prints that structured logs replaced were shown by -verbose, so it
+// implies -vv.
+func (config Config) GetLogVerbosity() int {
+	switch {
+	case config.LogDebug || config.Verbose:
+		return int(structured_log.VerbosityDebug)
+
+	case config.LogInfo:
+		return int(structured_log.VerbosityInfo)
+
+	default:
+		return int(structured_log.VerbosityQuiet)
+	}
+}
+
 func (config Config) GetQuiet() bool {
 	return config.Quiet
 }
 
+func (config *Config) SetErrorFormat(value string) (err error) {
+	switch value {
+	case ErrorFormatText, ErrorFormatJSON:
+		config.ErrorFormat = value
+
+	default:
+		err = errors.BadRequestf(
+			"unsupported error format: %q, expected %s or %s",
+			value,
+			ErrorFormatText,
+			ErrorFormatJSON,
+		)
+	}
+
+	return err
+}
+
+func (config Config) IsErrorFormatJSON() bool {
+	return config.ErrorFormat == ErrorFormatJSON
+}
+
 func (config Config) GetTodo() bool {
 	return config.Todo
 }
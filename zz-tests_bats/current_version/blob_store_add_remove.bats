@@ -0,0 +1,64 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+}
+
+teardown() {
+	teardown_repo
+}
+
+# bats file_tags=user_story:blob_store
+
+function blob_store_add_and_remove { # @test
+	run_dodder_init_disable_age
+	assert_success
+
+	run_dodder blob_store-add-blob-store local -encryption none -lock-internal-files=false
+	assert_success
+	assert_output --regexp 'ok 1 - add .local .*/dodder-blob_store-config'
+
+	run_dodder blob_store-list
+	assert_success
+	assert_output --partial '.local: '
+
+	run_dodder blob_store-add-blob-store -id .local local -encryption none
+	assert_failure
+	assert_output --partial 'already exists'
+
+	run_dodder blob_store-write .local <(echo added)
+	assert_success
+
+	run_dodder blob_store-remove-blob-store .local
+	assert_failure
+	assert_output --partial 'still has blobs'
+
+	run_dodder blob_store-remove-blob-store -force .local
+	assert_success
+	assert_output --partial 'ok 1 - remove .local'
+
+	run_dodder blob_store-list
+	assert_success
+	refute_output --partial '.local: '
+}
+
+function blob_store_add_rejects_default_takeover { # @test
+	run_dodder_init_disable_age
+	assert_success
+
+	run_dodder blob_store-add-blob-store -id .aaa local -encryption none
+	assert_failure
+	assert_output --partial 'would replace'
+}
+
+function blob_store_remove_default { # @test
+	run_dodder_init_disable_age
+	assert_success
+
+	run_dodder blob_store-remove-blob-store .default
+	assert_failure
+	assert_output --partial 'is the default blob store'
+}
@@ -85,6 +85,25 @@ function info_repo_archive_max_pack_size { # @test
 	assert_output '0'
 }
 
+function info_repo_archive_compression_dictionary { # @test
+	run_dodder_init_disable_age
+	assert_success
+
+	run_dodder blob_store-init-inventory-archive \
+		-compression-level 19 \
+		-compression-dictionary \
+		.archive
+	assert_success
+
+	run_dodder blob_store-info-repo .archive compression.level
+	assert_success
+	assert_output '19'
+
+	run_dodder blob_store-info-repo .archive compression.dictionary
+	assert_success
+	assert_output 'true'
+}
+
 function info_repo_unknown_key_fails { # @test
 	run_dodder_init_disable_age
 	assert_success
@@ -92,3 +111,16 @@ function info_repo_unknown_key_fails { # @test
 	run_dodder blob_store-info-repo nonexistent-key
 	assert_failure
 }
+
+function info_repo_archive_delta_strategy { # @test
+	run_dodder_init_disable_age
+	assert_success
+
+	run_dodder blob_store-init-inventory-archive \
+		-delta -delta-strategy similarity .archive
+	assert_success
+
+	run_dodder blob_store-info-repo .archive delta.strategy
+	assert_success
+	assert_output 'similarity'
+}
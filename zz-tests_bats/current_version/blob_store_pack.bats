@@ -35,6 +35,37 @@ function pack_inventory_archive_with_blob { # @test
 	refute_output --partial 'not ok'
 }
 
+function pack_inventory_archive_writes_footer_index { # @test
+	run_dodder_init_disable_age
+	assert_success
+
+	run_dodder blob_store-init-inventory-archive .archive
+	assert_success
+
+	run_dodder blob_store-info-repo .archive footer-index
+	assert_success
+	assert_output 'true'
+
+	run_dodder blob_store-write .archive <(echo footer-index-content)
+	assert_success
+
+	run_dodder blob_store-pack .archive
+	assert_success
+	refute_output --partial 'not ok'
+
+	run find . -name '*.inventory_archive-v2' -type f
+	assert_success
+	assert_output
+
+	run find . -name '*.inventory_archive_index-v1' -type f
+	assert_success
+	refute_output
+
+	run find . -name '*.inventory_archive_bloom-v1' -type f
+	assert_success
+	assert_output
+}
+
 function pack_with_blob_store_id_filters_other_stores { # @test
 	run_dodder_init_disable_age
 	assert_success
@@ -0,0 +1,54 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+}
+
+teardown() {
+	teardown_repo
+}
+
+# bats file_tags=user_story:blob_store,user_story:age_encryption
+
+function blob_store_rotate_encryption { # @test
+	run_dodder_init_disable_age
+	assert_success
+
+	run_dodder blob_store-add-blob-store -id .local local -encryption generate
+	assert_success
+
+	run_dodder blob_store-write .local <(echo rotated)
+	assert_success
+
+	run_dodder blob_store-rotate-encryption -keep-retired -encryption generate .local
+	assert_success
+	assert_output --partial 'ok 1 - retire keys .local'
+	assert_output --regexp 'ok 2 - rotate '
+	refute_output --partial 'drop retired keys'
+
+	run_dodder blob_store-info-repo .local retired-encryption
+	assert_success
+
+	run_dodder blob_store-rotate-encryption .local
+	assert_success
+	assert_output --partial 'blobs rotated: 0'
+	assert_output --partial 'ok 1 - drop retired keys .local'
+
+	run_dodder blob_store-fsck .local
+	assert_success
+}
+
+function blob_store_rotate_encryption_unencrypted { # @test
+	run_dodder_init_disable_age
+	assert_success
+
+	run_dodder blob_store-add-blob-store -id .local local -encryption none
+	assert_success
+
+	run_dodder blob_store-rotate-encryption -encryption generate .local
+	assert_failure
+	assert_output --partial 'is not encrypted'
+}
@@ -0,0 +1,42 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	copy_from_version "$DIR"
+}
+
+teardown() {
+	chflags_nouchg
+}
+
+function cat_blob_by_object_and_blob_id { # @test
+	run_dodder show -format blob one/uno
+	assert_success
+	expected="$output"
+
+	run_dodder cat-blob one/uno
+	assert_success
+	assert_output "$expected"
+
+	run_dodder cat-blob blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd
+	assert_success
+	assert_output "$expected"
+}
+
+function cat_blob_range { # @test
+	run_dodder show -format blob one/uno
+	assert_success
+	expected="$output"
+
+	run_dodder cat-blob -offset 2 -length 3 one/uno
+	assert_success
+	assert_output "${expected:2:3}"
+
+	run_dodder cat-blob -offset 2 one/uno
+	assert_success
+	assert_output "${expected:2}"
+}
@@ -156,6 +156,21 @@ function checkin_simple_all_dry_run { # @test
 	EOM
 }
 
+function checkin_delete_dry_run { # @test
+  run_dodder checkin -dry-run -delete one/uno.zettel
+  assert_success
+  assert_output --partial 'would delete [one/uno.zettel'
+
+  run test -f one/uno.zettel
+  assert_success
+
+  run_dodder show -format log one/uno
+  assert_success
+  assert_output - <<-EOM
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4]
+	EOM
+}
+
 function checkin_simple_typ { # @test
   run_dodder checkin .t
   assert_success
@@ -292,6 +307,60 @@ function checkin_dot_untracked_fs_blob() { # @test
 	EOM
 }
 
+# bats test_tags=user_story:fs_blobs
+function checkin_dir_dodderignore() { # @test
+  cat >.dodderignore <<-EOM
+		# build output
+		*.log
+		build/
+	EOM
+
+  mkdir -p batch/build
+
+  cat >batch/keep.md <<-EOM
+		newest body
+	EOM
+
+  cat >batch/skip.log <<-EOM
+		ignored by extension
+	EOM
+
+  cat >batch/build/skip.md <<-EOM
+		ignored by directory
+	EOM
+
+  run_dodder checkin batch
+  assert_success
+  assert_output - <<-EOM
+		[two/uno @blake2b256-k87yyah5da3c8h9j4ugf44edeurrqztn7zddh7ksc88pfg4zzx0smqmuf9 !md "keep"]
+	EOM
+}
+
+# bats test_tags=user_story:fs_blobs
+function checkin_dir_records_workspace_manifest() { # @test
+  mkdir -p batch
+
+  cat >batch/keep.md <<-EOM
+		newest body
+	EOM
+
+  run_dodder checkin batch
+  assert_success
+
+  run cat .dodder-workspace-manifest
+  assert_success
+  assert_output --regexp 'blake2b256-k87yyah5da3c8h9j4ugf44edeurrqztn7zddh7ksc88pfg4zzx0smqmuf9 [0-9]+ [0-9]+ batch/keep.md$'
+
+  rm batch/keep.md
+
+  run_dodder status batch
+  assert_success
+
+  run cat .dodder-workspace-manifest
+  assert_success
+  refute_output --partial 'batch/keep.md'
+}
+
 # bats test_tags=user_story:fs_blobs, user_story:external_ids
 function checkin_explicit_untracked_fs_blob() { # @test
   cat >test.md <<-EOM
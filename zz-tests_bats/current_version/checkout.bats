@@ -122,6 +122,24 @@ function checkout_simple_zettel_blob_only { # @test
 	EOM
 }
 
+function checkout_blob_records_manifest { # @test
+  run_dodder checkout -mode blob one/uno
+  assert_success
+
+  run cat .dodder-workspace-manifest
+  assert_success
+  assert_output --regexp 'blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd [0-9]+ [0-9]+ one/uno.md$'
+
+  echo "changed body" >one/uno.md
+
+  run_dodder checkin one/uno.md
+  assert_success
+
+  run_dodder show -format blob one/uno
+  assert_success
+  assert_output "changed body"
+}
+
 function checkout_zettel_several { # @test
   run_dodder checkout one/uno one/dos
   assert_success
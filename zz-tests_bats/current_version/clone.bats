@@ -55,6 +55,7 @@ function bootstrap {
 
 function run_clone_default_with() {
 	run_dodder clone \
+		-trust \
 		-encryption none \
 		-yin <(cat_yin) \
 		-yang <(cat_yang) \
@@ -0,0 +1,99 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	copy_from_version "$DIR"
+}
+
+teardown() {
+	chflags_nouchg
+}
+
+function compact_stream_index_requires_retention { # @test
+	run_dodder compact-stream-index
+	assert_failure
+	assert_output --partial 'no retention: pass -retention'
+
+	run_dodder show one/uno+
+	assert_success
+	assert_output_unsorted - <<-EOM
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4]
+		[one/uno @blake2b256-c5xgv9eyuv6g49mcwqks24gd3dh39w8220l0kl60qxt60rnt60lsc8fqv0 !md "wow ok" tag-1 tag-2]
+	EOM
+}
+
+function compact_stream_index_drops_superseded_versions { # @test
+	run_dodder compact-stream-index -retention 0s
+	assert_success
+	assert_output --regexp 'dropped [1-9][0-9]* of [0-9]+ entries'
+
+	run_dodder show one/uno+
+	assert_success
+	assert_output - <<-EOM
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4]
+	EOM
+
+	run_dodder show one/dos
+	assert_success
+	assert_output - <<-EOM
+		[one/dos @blake2b256-z3zpdf6uhqd3tx6nehjtvyjsjqelgyxfjkx46pq04l6qryxz4efs37xhkd !md "wow ok again" tag-3 tag-4]
+	EOM
+
+	run_dodder compact-stream-index -retention 0s
+	assert_success
+	assert_output --regexp 'not compacted: 0 of [0-9]+ entries are garbage'
+
+	run_dodder reindex
+	assert_success
+
+	run_dodder show one/uno+
+	assert_success
+	assert_output_unsorted - <<-EOM
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4]
+		[one/uno @blake2b256-c5xgv9eyuv6g49mcwqks24gd3dh39w8220l0kl60qxt60rnt60lsc8fqv0 !md "wow ok" tag-1 tag-2]
+	EOM
+}
+
+function compact_stream_index_keeps_versions_within_retention { # @test
+	run_dodder compact-stream-index -retention 876000h
+	assert_success
+	assert_output --regexp 'not compacted: 0 of [0-9]+ entries are garbage'
+
+	run_dodder show one/uno+
+	assert_success
+	assert_output_unsorted - <<-EOM
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4]
+		[one/uno @blake2b256-c5xgv9eyuv6g49mcwqks24gd3dh39w8220l0kl60qxt60rnt60lsc8fqv0 !md "wow ok" tag-1 tag-2]
+	EOM
+}
+
+function compact_stream_index_below_threshold { # @test
+	run_dodder compact-stream-index -retention 0s -threshold 1
+	assert_success
+	assert_output --regexp 'not compacted: [1-9][0-9]* of [0-9]+ entries are garbage'
+
+	run_dodder show one/uno+
+	assert_success
+	assert_output_unsorted - <<-EOM
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4]
+		[one/uno @blake2b256-c5xgv9eyuv6g49mcwqks24gd3dh39w8220l0kl60qxt60rnt60lsc8fqv0 !md "wow ok" tag-1 tag-2]
+	EOM
+}
+
+function compact_stream_index_then_commit { # @test
+	run_dodder compact-stream-index -retention 0s
+	assert_success
+
+	run_dodder new -edit=false -description "after compaction"
+	assert_success
+
+	run_dodder show one/uno
+	assert_success
+	assert_output - <<-EOM
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4]
+	EOM
+}
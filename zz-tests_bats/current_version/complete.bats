@@ -95,6 +95,8 @@ function complete_subcmd { # @test
 		blob_store-info-repo
 		blob_store-init
 		blob_store-init-from
+		blob_store-init-encrypted
+		blob_store-init-lazy-parent
 		blob_store-init-pointer
 		blob_store-init-sftp-explicit
 		blob_store-init-inventory-archive
@@ -111,16 +113,21 @@ function complete_subcmd { # @test
 		blob_store-sync
 		blob_store-write
 		cat-alfred
+		cat-blob.*write a blob's raw content, or a range of it, to stdout
 		checkin
 		checkin-blob
 		checkin-json
 		checkout
+		checkpoint-inventory-lists.*snapshot the latest object versions for reindex -from-checkpoint
 		clean
 		clone
+		compact-stream-index.*drop superseded object versions from the index
 		complete.*complete a command-line
+		completion.*print a shell completion script
 		debug-print-probe-index
 		deinit
 		diff
+		doctor.*check that every blob store is reachable and consistent
 		dormant-add
 		dormant-edit
 		dormant-remove
@@ -128,6 +135,7 @@ function complete_subcmd { # @test
 		edit-config
 		exec
 		export
+		export-markdown.*write objects as markdown notes with front matter to a directory
 		find-missing
 		format-blob
 		format-object
@@ -135,7 +143,9 @@ function complete_subcmd { # @test
 		fsck
 		gen
 		generate-zettel-id-components
+		graph.*export objects, tags, types, blobs, and versions as a graph
 		import
+		import-vault.*import the notes of a markdown or Obsidian vault as zettels
 		info
 		info-pivy_agent
 		info-ssh_agent
@@ -144,6 +154,7 @@ function complete_subcmd { # @test
 		init
 		init-workspace
 		last
+		lsp.*serve object links to editors over the language server protocol
 		merge-tool
 		migrate-zettel-ids
 		new
@@ -152,6 +163,9 @@ function complete_subcmd { # @test
 		pull
 		pull-blob-store
 		push
+		push-to-parent
+		rebuild-blob-refcounts.*recount the object versions referencing each blob
+		refresh-workspace
 		reindex
 		remote-add
 		repo-fsck
@@ -160,10 +174,31 @@ function complete_subcmd { # @test
 		serve
 		show
 		status
+		sync
+		trust-repo-pubkey
 		update
+		watch.*check in workspace changes as they are saved
 	EOM
 }
 
+function complete_hidden_complete { # @test
+	run_dodder __complete -in-progress=sho -- sho
+	assert_success
+	assert_output --partial 'show'
+	refute_output --partial '__complete'
+}
+
+function completion_scripts { # @test
+	for shell in bash zsh fish; do
+		run_dodder completion "$shell"
+		assert_success
+		assert_output --partial 'dodder __complete -in-progress='
+	done
+
+	run_dodder completion tcsh
+	assert_failure
+}
+
 function complete_complete { # @test
 	run_dodder complete complete
 	assert_success
@@ -183,6 +218,8 @@ function complete_init_workspace { # @test
 	assert_output --regexp -- '-tags.*tags added for new objects in `checkin`, `new`, `organize`'
 	# shellcheck disable=SC2016
 	assert_output --regexp -- '-type.*type used for new objects in `new` and `organize`'
+	assert_output --regexp -- '-blob-store.*blob store for the workspace'
+	assert_output --regexp -- '-sparse.*objects checked out in the workspace'
 
 	skip # TODO add back support
 	run_dodder complete init-workspace -tags
@@ -0,0 +1,46 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+}
+
+teardown() {
+	chflags_nouchg
+}
+
+function doctor_healthy_repo { # @test
+	run_dodder_init_disable_age
+
+	run_dodder doctor
+	assert_success
+	assert_output --partial "TAP version 14"
+	assert_output --partial "base path"
+	refute_output --partial "not ok"
+}
+
+function doctor_reports_corrupt_archive { # @test
+	run_dodder_init_disable_age
+
+	run_dodder blob_store-init-inventory-archive .archive
+	assert_success
+
+	run_dodder blob_store-write .archive <(echo doctor-content)
+	assert_success
+
+	run_dodder blob_store-pack .archive
+	assert_success
+
+	run_dodder doctor
+	assert_success
+	assert_output --partial "read archive header"
+	refute_output --partial "not ok"
+
+	find . -name '*.inventory_archive-v2' -type f -exec sh -c 'echo corrupt >"$1"' _ {} \;
+
+	run_dodder doctor
+	assert_output --partial "not ok"
+	assert_output --partial "read archive header"
+}
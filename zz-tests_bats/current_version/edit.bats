@@ -113,3 +113,62 @@ function edit_and_format_no_workspace { # @test
 		last time
 	EOM
 }
+
+function edit_temp_commits_changed_fields { # @test
+  export EDITOR="/bin/bash -c 'sed -i \"s/^- tag-3\$/- tag-5/\" \"\$0\"'"
+  run_dodder edit -temp one/uno
+  assert_success
+  assert_output - <<-EOM
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-4 tag-5]
+	EOM
+
+  run_dodder show -format blob one/uno
+  assert_success
+  assert_output - <<-EOM
+		last time
+	EOM
+}
+
+function edit_temp_unchanged_commits_nothing { # @test
+  export EDITOR="true"
+  run_dodder edit -temp one/uno
+  assert_success
+  assert_output ''
+}
+
+function edit_temp_merges_and_detects_conflicts { # @test
+  # the editor first edits one/uno in a nested edit, as if it changed while
+  # the editor was open, and then makes its own change
+  cat >editor.bash <<-'EOM'
+		#! /bin/bash -e
+		if [[ -n $NESTED ]]; then
+		  sed -i "$THEIRS" "$1"
+		  exit 0
+		fi
+		#shellcheck disable=SC2086
+		NESTED=1 "$DODDER_BIN" edit $DODDER_FLAGS -temp one/uno
+		sed -i "$MINE" "$1"
+	EOM
+
+  DODDER_FLAGS="${cmd_dodder_def[*]}"
+  export DODDER_FLAGS DODDER_BIN
+  export EDITOR="/bin/bash $PWD/editor.bash"
+
+  export THEIRS='s/^# wow the first$/# theirs/;s/^- tag-4$/- tag-6/'
+  export MINE='s/^- tag-3$/- tag-5/'
+  run_dodder edit -temp one/uno
+  assert_success
+  assert_output --partial - <<-EOM
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "theirs" tag-5 tag-6]
+	EOM
+
+  export THEIRS='s/^# theirs$/# also theirs/'
+  export MINE='s/^# theirs$/# mine/'
+  run_dodder edit -temp one/uno
+  assert_failure
+  assert_output --partial 'conflicting fields: description'
+
+  run_dodder show -format text one/uno
+  assert_success
+  assert_output --partial '# also theirs'
+}
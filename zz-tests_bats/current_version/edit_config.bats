@@ -30,3 +30,44 @@ function edit_config_and_dont_change { # @test
 	assert_success
 	assert_output ''
 }
+
+function config_get_set_unset { # @test
+	run_dodder config-get defaults.type
+	assert_success
+	assert_output '!md'
+
+	run_dodder config-set trash.retention 168h
+	assert_success
+	assert_output --partial '[konfig @'
+
+	run_dodder config-get trash.retention
+	assert_success
+	assert_output '168h'
+
+	run_dodder config-set trash.retention 'a while'
+	assert_failure
+	assert_output --partial 'trash.retention'
+
+	run_dodder config-set auto-vivify maybe
+	assert_failure
+	assert_output --partial 'auto-vivify'
+
+	run_dodder config-set no-such-key value
+	assert_failure
+	assert_output --partial 'unsupported config key'
+
+	run_dodder config-unset trash.retention
+	assert_success
+
+	run_dodder config-get trash.retention
+	assert_success
+	assert_output ''
+}
+
+function config_validate { # @test
+	run_dodder config-validate
+	assert_success
+	assert_output --partial 'ok'
+	assert_output --partial 'konfig'
+	refute_output --partial 'not ok'
+}
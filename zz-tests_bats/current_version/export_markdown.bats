@@ -0,0 +1,61 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	setup_repo
+}
+
+teardown() {
+	teardown_repo
+}
+
+# bats file_tags=user_story:export_markdown
+
+function export_markdown_writes_front_matter_and_body { # @test
+	run_dodder export-markdown out one/uno
+	assert_success
+	assert_output 'one/uno -> md/one-uno.md'
+
+	run cat out/md/one-uno.md
+	assert_success
+	assert_output --partial 'dodder-object-id: one/uno'
+	assert_output --partial 'title: wow the first'
+	assert_output --partial 'tags: [tag-3, tag-4]'
+	assert_output --partial 'type: md'
+	assert_output --partial 'dodder-blob-id: blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd'
+	assert_line 'last time'
+}
+
+function export_markdown_layouts { # @test
+	run_dodder export-markdown -layout tag out one/uno
+	assert_success
+	assert_output 'one/uno -> tag/3/one-uno.md'
+
+	run_dodder export-markdown -layout flat out one/uno
+	assert_success
+	assert_output 'one/uno -> one-uno.md'
+
+	run_dodder export-markdown -layout nested out one/uno
+	assert_failure
+}
+
+function export_markdown_round_trips_through_import_vault { # @test
+	run_dodder export-markdown out one/uno
+	assert_success
+
+	sed -i 's/^title: .*/title: wow the second/' out/md/one-uno.md
+
+	run_dodder import-vault out
+	assert_success
+	assert_output --regexp '\[one/uno @.* !md "wow the second" tag-3 tag-4\]'
+	assert_output --partial 'md/one-uno.md -> one/uno'
+	refute_output --partial 'two/uno'
+
+	run_dodder show -format blob one/uno
+	assert_success
+	assert_output 'last time'
+}
@@ -0,0 +1,48 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	setup_repo
+}
+
+teardown() {
+	teardown_repo
+}
+
+# bats file_tags=user_story:graph
+
+function graph_dot_tags_and_types { # @test
+	run_dodder graph one/uno
+	assert_success
+	assert_line 'digraph dodder {'
+	assert_line --partial '"one/uno" [label="one/uno\nwow the first", shape=note];'
+	assert_line --partial '"one/uno" -> "!md" [label="type", style=dotted];'
+	assert_line --partial '"one/uno" -> "tag-3" [label="tag", style=solid];'
+	assert_line --partial '"tag-3" -> "tag" [label="parent", style=solid];'
+	assert_line '}'
+}
+
+function graph_json_blobs_without_tags { # @test
+	run_dodder graph -format json -tags=false -types=false -blobs one/uno
+	assert_success
+	assert_output --partial '{"id":"one/uno","kind":"zettel","label":"wow the first"}'
+	assert_output --partial '{"from":"one/uno","to":"blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd","kind":"blob"}'
+	refute_output --partial '"kind":"tag"'
+}
+
+function graph_mothers { # @test
+	run_dodder graph -mothers -tags=false -types=false one/uno
+	assert_success
+	assert_output --regexp '"one/uno" -> ".+" \[label="mother", style=bold\];'
+	assert_output --regexp 'label=".+\\none/uno .+", shape=box\];'
+}
+
+function graph_bad_format { # @test
+	run_dodder graph -format svg one/uno
+	assert_failure
+	assert_output --partial 'unsupported graph format'
+}
@@ -0,0 +1,57 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	copy_from_version "$DIR"
+}
+
+teardown() {
+	chflags_nouchg
+}
+
+function history_type_diff_tool { # @test
+	run_dodder_init_workspace
+	run_dodder checkout !md:t
+	assert_success
+
+	cat >md.type <<-'EOM'
+		---
+		! toml-type-v1
+		---
+
+		file-extension = "md"
+		vim-syntax-type = "markdown"
+
+		[diff-tool]
+		command = ["sh", "-c", 'echo external: $(basename "$1") $(basename "$2")', "sh", "{old}", "{new}"]
+		timeout = "10s"
+	EOM
+
+	run_dodder checkin -delete md.type
+	assert_success
+
+	run_dodder checkout one/uno
+	assert_success
+
+	cat >one/uno.zettel <<-EOM
+		---
+		# wow the first
+		- tag-3
+		- tag-4
+		! md
+		---
+
+		a changed body
+	EOM
+
+	run_dodder checkin one/uno.zettel
+	assert_success
+
+	run_dodder history one/uno
+	assert_success
+	assert_output --partial "external: old.md new.md"
+}
@@ -32,6 +32,18 @@ teardown() {
   chflags_nouchg
 }
 
+# exported lists are signed by the outer repo, which the inner repo must trust
+function trust_outer_pubkey_in_inner {
+  run_dodder info-repo pubkey
+  assert_success
+  outer_pubkey="$output"
+
+  pushd inner || exit 1
+  run_dodder trust-repo-pubkey "$outer_pubkey"
+  assert_success
+  popd || exit 1
+}
+
 function import { # @test
   (
     mkdir inner
@@ -43,6 +55,7 @@ function import { # @test
   run_dodder export -print-time=true +z,e,t
   assert_success
   echo "$output" >list
+  trust_outer_pubkey_in_inner
 
   list="$(realpath list)"
   pushd inner || exit 1
@@ -240,6 +253,7 @@ function import_one_tai_same { # @test
   run_dodder export -print-time=true one/uno [tag ^tag-1 ^tag-2]:e
   assert_success
   echo "$output" >list
+  trust_outer_pubkey_in_inner
 
   list="$(realpath list)"
   pushd inner || exit 1
@@ -275,6 +289,7 @@ function import_twice_no_dupes_one_zettel { # @test
   run_dodder export -print-time=true one/uno+
   assert_success
   echo "$output" >list
+  trust_outer_pubkey_in_inner
 
   list="$(realpath list)"
   pushd inner || exit 1
@@ -318,6 +333,7 @@ function import_conflict { # @test
   run_dodder export -print-time=true one/uno+
   assert_success
   echo "$output" >list
+  trust_outer_pubkey_in_inner
 
   list="$(realpath list)"
   pushd inner || exit 1
@@ -361,6 +377,7 @@ function import_twice_no_dupes { # @test
   run_dodder export -print-time=true +z,e,t
   assert_success
   echo "$output" >list
+  trust_outer_pubkey_in_inner
 
   list="$(realpath list)"
   pushd inner || exit 1
@@ -422,6 +439,7 @@ function import_inventory_lists { # @test
   run_dodder export -print-time=true
   assert_success
   echo "$output" >list
+  trust_outer_pubkey_in_inner
 
   list="$(realpath list)"
   pushd inner || exit 1
@@ -443,3 +461,56 @@ function import_inventory_lists { # @test
 		[one/uno @blake2b256-c5xgv9eyuv6g49mcwqks24gd3dh39w8220l0kl60qxt60rnt60lsc8fqv0 !md "wow ok" tag-1 tag-2]
 	EOM
 }
+
+function import_untrusted_pubkey { # @test
+  (
+    mkdir inner
+    pushd inner || exit 1
+    run_dodder_init
+  )
+
+  run_dodder export -print-time=true one/uno+
+  assert_success
+  echo "$output" >list
+
+  list="$(realpath list)"
+  pushd inner || exit 1
+
+  run_dodder import \
+    -blob_store-id shared \
+    "$list"
+  assert_failure
+  assert_output --partial 'signed by untrusted repo pubkey'
+}
+
+function trust_repo_pubkey { # @test
+  run_dodder info-repo pubkey
+  assert_success
+  outer_pubkey="$output"
+
+  mkdir inner
+  pushd inner || exit 1
+  run_dodder_init
+
+  run_dodder info-repo pubkey
+  assert_success
+  inner_pubkey="$output"
+
+  run_dodder trust-repo-pubkey "$outer_pubkey"
+  assert_success
+  assert_output 'trusted 1 new repo pubkeys'
+
+  run_dodder trust-repo-pubkey "$outer_pubkey" "$inner_pubkey"
+  assert_success
+  assert_output 'trusted 0 new repo pubkeys'
+
+  run_dodder trust-repo-pubkey
+  assert_success
+  assert_output - <<-EOM
+		$inner_pubkey
+		$outer_pubkey
+	EOM
+
+  run_dodder trust-repo-pubkey not-a-pubkey
+  assert_failure
+}
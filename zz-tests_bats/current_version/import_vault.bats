@@ -0,0 +1,80 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	setup_repo
+}
+
+teardown() {
+	teardown_repo
+}
+
+function make_vault {
+	mkdir -p vault/.obsidian vault/Projects
+
+	cat >vault/Projects/Garden.md <<-EOM
+		---
+		title: Spring garden
+		tags: [Project/Active, "🌱"]
+		---
+		see [[Seeds|the seeds]] and [[Missing]]
+	EOM
+
+	cat >vault/Seeds.md <<-EOM
+		---
+		type: recipe
+		aliases:
+		  - seed list
+		---
+		back to [[garden]], [[seed list#Tomatoes]]
+	EOM
+
+	echo '[[Seeds]]' >vault/.obsidian/workspace.md
+}
+
+# bats file_tags=user_story:import_vault
+
+function import_vault_maps_front_matter_and_links { # @test
+	make_vault
+
+	run_dodder import-vault -report report.json vault
+	assert_success
+	assert_output --regexp '\[two/uno @.* !md "Spring garden" project-active\]'
+	assert_output --regexp '\[one/tres @.* !recipe "Seeds"\]'
+	assert_output --partial 'Projects/Garden.md -> two/uno'
+	assert_output --partial 'Seeds.md -> one/tres'
+	assert_output --partial 'Projects/Garden.md: unresolved link: [[Missing]]'
+	assert_output --partial 'Projects/Garden.md: skipped tag: "🌱"'
+	refute_output --partial 'workspace.md'
+
+	run_dodder show -format blob two/uno
+	assert_success
+	assert_output 'see [one/tres the seeds] and [[Missing]]'
+
+	run_dodder show -format blob one/tres
+	assert_success
+	assert_output 'back to [two/uno garden], [one/tres seed list#Tomatoes]'
+
+	run cat report.json
+	assert_output --partial '"object-id": "two/uno"'
+	assert_output --partial '"unresolved-links": ['
+}
+
+function import_vault_batches_inventory_lists { # @test
+	make_vault
+
+	run_dodder show :b
+	assert_success
+	lists_before="${#lines[@]}"
+
+	run_dodder import-vault -batch-size 1 vault
+	assert_success
+
+	run_dodder show :b
+	assert_success
+	assert_equal "${#lines[@]}" "$((lists_before + 2))"
+}
@@ -70,3 +70,83 @@ function init_workspace { # @test
 	assert_success
 	assert_output 'due'
 }
+
+function init_workspace_config_show_layers { # @test
+	run_dodder init-workspace -tags today -type task
+	assert_success
+
+	export EDITOR=vi
+	export DODDER_DEFAULT_TAGS=tomorrow
+
+	run_dodder config-show
+	assert_success
+	assert_output --partial 'defaults.type = !task'
+	assert_output --partial 'defaults.tags = today,tomorrow'
+	assert_output --partial 'editor = vi'
+
+	run_dodder config-show -verbose -config editor=nano editor
+	assert_success
+	assert_output --partial 'editor = nano'
+	assert_output --partial 'flags     nano (-config)'
+	assert_output --partial 'builtin   vi ($EDITOR)'
+
+	run_dodder config-show -config blob_store=other
+	assert_failure
+	assert_output --partial 'unsupported config key'
+}
+
+function init_workspace_blob_store_default_pointer { # @test
+	run_dodder init-workspace
+	assert_success
+	assert_output ''
+
+	run cat .dodder-workspace-blob_store/dodder-blob_store-config
+	assert_success
+	assert_output --partial '!toml-blob_store_config-pointer-v0'
+}
+
+function init_workspace_blob_store_lazy { # @test
+	run_dodder init-workspace -blob-store lazy
+	assert_success
+	assert_output ''
+
+	run cat .dodder-workspace-blob_store/dodder-blob_store-config
+	assert_success
+	assert_output --partial '!toml-blob_store_config-lazy_parent-v0'
+}
+
+function init_workspace_blob_store_unsupported { # @test
+	run_dodder init-workspace -blob-store symlink
+	assert_failure
+	assert_output --partial 'unsupported workspace blob store type'
+}
+
+function push_to_parent_pointer { # @test
+	run_dodder init-workspace
+	assert_success
+
+	run_dodder push-to-parent
+	assert_success
+	assert_output 'pushed 0 blobs to parent (0 already present)'
+}
+
+function push_to_parent_not_in_workspace { # @test
+	run_dodder push-to-parent
+	assert_failure
+	assert_output --partial 'not in a workspace with its own blob store'
+}
+
+function init_workspace_sparse_requires_query { # @test
+	run_dodder init-workspace -sparse
+	assert_failure
+	assert_output --partial -- '-sparse requires a -query'
+}
+
+function refresh_workspace_without_query { # @test
+	run_dodder init-workspace
+	assert_success
+
+	run_dodder refresh-workspace
+	assert_failure
+	assert_output --partial 'workspace has no query to refresh'
+}
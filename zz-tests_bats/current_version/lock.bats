@@ -0,0 +1,47 @@
+#! /usr/bin/env bats
+
+setup() {
+  load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+  # for shellcheck SC2154
+  export output
+
+  copy_from_version "$DIR"
+}
+
+teardown() {
+  chflags_nouchg
+}
+
+# bats file_tags=user_story:lock
+
+function lock_invalid_timeout { # @test
+  export DODDER_LOCK_TIMEOUT=soon
+  run_dodder show one/uno
+  assert_failure
+  assert_output --partial 'invalid DODDER_LOCK_TIMEOUT: "soon"'
+}
+
+function lock_concurrent_writers_queue { # @test
+  # writers that stalled on each other would only finish at the timeout
+  export DODDER_LOCK_TIMEOUT=20s
+  SECONDS=0
+  pids=()
+
+  for _ in 1 2 3; do
+    #shellcheck disable=SC2068
+    "$DODDER_BIN" new ${cmd_dodder_def[@]} -edit=false >/dev/null 2>&1 &
+    pids+=("$!")
+  done
+
+  for pid in "${pids[@]}"; do
+    run wait "$pid"
+    assert_success
+  done
+
+  assert [ "$SECONDS" -lt 10 ]
+
+  run_dodder show :?z
+  assert_success
+  assert_equal "${#lines[@]}" 5
+}
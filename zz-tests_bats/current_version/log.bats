@@ -0,0 +1,51 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	setup_repo
+}
+
+teardown() {
+	teardown_repo
+}
+
+# bats file_tags=user_story:inventory_list
+
+function log_newest_first { # @test
+	run_dodder show -format tai :b
+	assert_success
+	expected="$(echo "$output" | sort -rn)"
+
+	run_dodder log
+	assert_success
+	assert_equal "$(echo "$output" | cut -d' ' -f1)" "$expected"
+}
+
+function log_limit { # @test
+	run_dodder new -edit=false -description logged
+	assert_success
+
+	run_dodder log -limit 1
+	assert_success
+	assert_output --regexp '^[0-9]+\.[0-9]+ 1 .+ ".*"$'
+}
+
+function log_since_excludes_older_lists { # @test
+	run_dodder log -since 2099-01-01
+	assert_success
+	assert_output ''
+}
+
+function log_json { # @test
+	run_dodder new -edit=false -description logged
+	assert_success
+
+	run_dodder log -limit 1 -format json
+	assert_success
+	assert_output --partial '"object-count": 1'
+	assert_output --partial "\"hostname\": \"$(hostname)\""
+}
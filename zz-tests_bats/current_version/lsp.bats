@@ -0,0 +1,44 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	setup_repo
+}
+
+teardown() {
+	teardown_repo
+}
+
+# lsp_message <json> frames a message with its Content-Length header
+function lsp_message {
+	printf 'Content-Length: %d\r\n\r\n%s' "${#1}" "$1"
+}
+
+function lsp_session {
+	lsp_message '{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}'
+	lsp_message '{"jsonrpc":"2.0","method":"initialized","params":{}}'
+	lsp_message '{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///note.md","version":1,"text":"see [one/uno] and [one/nope]\n[tag-"}}}'
+	lsp_message '{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///note.md"},"position":{"line":0,"character":6}}}'
+	lsp_message '{"jsonrpc":"2.0","id":3,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///note.md"},"position":{"line":0,"character":20}}}'
+	lsp_message '{"jsonrpc":"2.0","id":4,"method":"textDocument/completion","params":{"textDocument":{"uri":"file:///note.md"},"position":{"line":1,"character":5}}}'
+	lsp_message '{"jsonrpc":"2.0","id":5,"method":"dodder/resolveLink","params":{"textDocument":{"uri":"file:///note.md"},"position":{"line":0,"character":0},"objectId":"one/uno"}}'
+	lsp_message '{"jsonrpc":"2.0","id":6,"method":"shutdown"}'
+	lsp_message '{"jsonrpc":"2.0","method":"exit"}'
+}
+
+# bats file_tags=user_story:lsp
+
+function lsp_hover_completion_and_resolve { # @test
+	run_dodder lsp < <(lsp_session)
+	assert_success
+	assert_output --partial '"hoverProvider":true'
+	assert_output --partial '"id":2,"result":{"contents":{"kind":"markdown","value":"**one/uno** wow the first'
+	assert_output --partial '"id":3,"result":null'
+	assert_output --regexp '"id":4,"result":\{"isIncomplete":false,"items":\[.*"label":"tag-3".*"label":"tag-4"'
+	assert_output --partial '"id":5,"result":{"object":{"object-id":"one/uno"'
+	assert_output --partial '"id":6,"result":null'
+}
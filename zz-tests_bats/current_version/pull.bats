@@ -110,7 +110,7 @@ function pull_history_zettel_type_tag_no_conflicts { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder pull /them +zettel,typ,etikett
+	run_dodder pull -trust /them +zettel,typ,etikett
 
 	assert_success
 	assert_output_unsorted - <<-EOM
@@ -125,6 +125,38 @@ function pull_history_zettel_type_tag_no_conflicts { # @test
 	try_add_new_after_pull
 }
 
+function pull_untrusted_remote_refused { # @test
+	them="them"
+	bootstrap_repo "$them"
+
+	pushd "$BATS_TEST_TMPDIR" || exit 1
+
+	run_dodder_init_disable_age
+
+	run_dodder remote-add \
+		toml-repo-local_override_path-v0 \
+		"$(realpath them)" \
+		them
+	assert_success
+
+	run_dodder pull /them +zettel
+	assert_failure
+	assert_output --partial 'is not trusted. Pass -trust'
+
+	run_dodder show one/uno
+	assert_failure
+
+	run_dodder pull -trust /them +zettel
+	assert_success
+
+	run_dodder trust-repo-pubkey
+	assert_success
+	assert_equal "${#lines[@]}" 2
+
+	run_dodder pull /them +zettel
+	assert_success
+}
+
 function pull_history_zettel_type_tag_no_conflicts_stdio_local { # @test
 	bootstrap_repo_at_dir_with_name them
 
@@ -141,7 +173,7 @@ function pull_history_zettel_type_tag_no_conflicts_stdio_local { # @test
 	EOM
 
 	# TODO make this actually use a socket
-	run_dodder pull /them +zettel,typ,etikett
+	run_dodder pull -trust /them +zettel,typ,etikett
 
 	assert_success
 	assert_output_unsorted --partial - <<-EOM
@@ -190,7 +222,7 @@ function pull_history_zettel_type_tag_yes_conflicts_remote_second { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder pull /them +zettel,typ,etikett
+	run_dodder pull -trust /them +zettel,typ,etikett
 
 	assert_failure
 	assert_output_unsorted --partial - <<-EOM
@@ -304,7 +336,7 @@ function pull_history_zettel_type_tag_yes_conflicts_allowed_remote_first { # @te
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder pull -allow-merge-conflicts /them +zettel,typ,etikett
+	run_dodder pull -trust -allow-merge-conflicts /them +zettel,typ,etikett
 	assert_success
 	# TODO address the bandaid of two `[tag]` objects
 	assert_output_unsorted - <<-EOM
@@ -376,7 +408,7 @@ function pull_history_zettel_type_tag_yes_conflicts_remote_first { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder pull /them +zettel,typ,etikett
+	run_dodder pull -trust /them +zettel,typ,etikett
 
 	assert_failure
 	assert_output_unsorted --partial - <<-EOM
@@ -445,7 +477,7 @@ function pull_history_default_no_conflict { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder pull /them
+	run_dodder pull -trust /them
 	assert_success
 
 	run_dodder show +?z,t,e
@@ -497,7 +529,7 @@ function pull_history_zettel_one_abbr { # @test
 		\[/them @[0-9a-z]+ !toml-repo-dotenv_xdg-v0]
 	EOM
 
-	run_dodder pull -include-blobs=false /them o/u+
+	run_dodder pull -trust -include-blobs=false /them o/u+
 
 	assert_success
 	assert_output_unsorted - <<-EOM
@@ -529,7 +561,7 @@ function pull_history_zettels_no_conflict_no_blobs { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder pull -exclude-blobs /them +zettel
+	run_dodder pull -trust -exclude-blobs /them +zettel
 
 	assert_success
 	assert_output_unsorted - <<-EOM
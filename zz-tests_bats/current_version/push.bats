@@ -106,7 +106,7 @@ function push_history_zettel_type_tag_no_conflicts { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder push /them:k +zettel,typ,etikett
+	run_dodder push -trust /them:k +zettel,typ,etikett
 
 	assert_success
 	assert_output_unsorted - <<-EOM
@@ -139,7 +139,7 @@ function push_history_zettel_type_tag_yes_conflicts { # @test
 		"$(realpath them)" \
 		them
 
-	run_dodder push /them +zettel,typ,etikett
+	run_dodder push -trust /them +zettel,typ,etikett
 
 	assert_failure
 	assert_output_unsorted - <<-EOM
@@ -187,7 +187,7 @@ function push_history_default { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder push /them
+	run_dodder push -trust /them
 
 	assert_success
 
@@ -223,7 +223,7 @@ function push_history_default_only_blobs { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder push -exclude-objects /them
+	run_dodder push -trust -exclude-objects /them
 
 	assert_success
 	assert_output_unsorted --regexp - <<-'EOM'
@@ -268,7 +268,7 @@ function push_default_stdio_local_once { # @test
 	# 	assert_success
 	# 	assert_output ''
 
-	run_dodder push /them
+	run_dodder push -trust /them
 	assert_success
 	assert_output_unsorted --regexp - <<-'EOM'
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
@@ -296,7 +296,7 @@ function push_history_default_stdio_local_twice { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder push /them :z
+	run_dodder push -trust /them :z
 	assert_success
 	assert_output_unsorted --partial - <<-EOM
 		copied Blob blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd (10 B)
@@ -314,7 +314,7 @@ function push_history_default_stdio_local_twice { # @test
 	EOM
 	popd || exit 1
 
-	run_dodder push /them :z
+	run_dodder push -trust /them :z
 
 	assert_success
 	assert_output_unsorted - <<-EOM
@@ -334,7 +334,7 @@ function push_history_default_stdio_twice { # @test
 		\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]
 	EOM
 
-	run_dodder push /them
+	run_dodder push -trust /them
 
 	assert_success
 	assert_line --regexp '\[/them @blake2b256-.+ !toml-repo-local_override_path-v0]'
@@ -360,7 +360,7 @@ function push_history_default_stdio_twice { # @test
 	EOM
 	popd || exit 1
 
-	run_dodder push /them
+	run_dodder push -trust /them
 	assert_success
 	assert_output_unsorted ''
 }
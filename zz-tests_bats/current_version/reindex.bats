@@ -113,3 +113,45 @@ function reindex_after_changes { # @test
 
 	verify
 }
+
+function rebuild_blob_refcounts { # @test
+	run_dodder rebuild-blob-refcounts
+	assert_success
+
+	run find . -name blob_refcounts -type f
+	assert_success
+	assert_output --regexp 'blob_refcounts'
+
+	# shellcheck disable=SC2046
+	run cat $(find . -name blob_refcounts -type f)
+	assert_success
+	assert_output --regexp '[0-9]+ blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd'
+	assert_output --regexp '[0-9]+ blake2b256-c5xgv9eyuv6g49mcwqks24gd3dh39w8220l0kl60qxt60rnt60lsc8fqv0'
+}
+
+function reindex_from_checkpoint { # @test
+	run_dodder reindex -from-checkpoint
+	assert_success
+	assert_output --partial 'no verified checkpoint, replaying every inventory list'
+
+	run_dodder checkpoint-inventory-lists
+	assert_success
+	assert_output --regexp '^checkpoint at inventory list [0-9]+: blake2b256-'
+
+	run_dodder new -edit=false -description "after the checkpoint"
+	assert_success
+
+	run_dodder reindex -from-checkpoint
+	assert_success
+	assert_output --partial 'resuming from the checkpoint at inventory list'
+
+	run_dodder show +e,t,z,konfig
+	assert_success
+	assert_output_unsorted --regexp - <<-EOM
+		\[!md @$(get_type_blob_sha) !toml-type-v1\]
+		\[konfig @$(get_konfig_sha) !toml-config-v2\]
+		\[one/dos @blake2b256-z3zpdf6uhqd3tx6nehjtvyjsjqelgyxfjkx46pq04l6qryxz4efs37xhkd !md "wow ok again" tag-3 tag-4\]
+		\[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4\]
+		\[[a-z]+/[a-z]+( @blake2b256-[a-z0-9]+)? !md "after the checkpoint"\]
+	EOM
+}
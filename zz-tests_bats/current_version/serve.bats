@@ -0,0 +1,84 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	setup_repo
+}
+
+teardown() {
+	teardown_repo
+}
+
+# starts a read-only server in the background and waits for its port
+function start_read_only_server {
+	#shellcheck disable=SC2068
+	timeout --preserve-status 10s "$DODDER_BIN" serve ${cmd_dodder_def[@]} \
+		-read-only tcp :0 >.serve.out 2>/dev/null &
+	server_pid=$!
+
+	for _ in $(seq 50); do
+		if [[ $(cat .serve.out) =~ port:\ \"([0-9]+)\" ]]; then
+			port="${BASH_REMATCH[1]}"
+			return
+		fi
+
+		sleep 0.1
+	done
+
+	fail "server did not start: $(cat .serve.out)"
+}
+
+# http_get <path> [<if-none-match>] prints the raw response without curl
+function http_get {
+	exec 3<>"/dev/tcp/localhost/$port"
+
+	{
+		printf 'GET %s HTTP/1.0\r\n' "$1"
+
+		if [[ -n $2 ]]; then
+			printf 'If-None-Match: %s\r\n' "$2"
+		fi
+
+		printf '\r\n'
+	} >&3
+
+	tr -d '\r' <&3
+	exec 3<&-
+}
+
+# bats file_tags=user_story:serve
+
+function serve_read_only_objects { # @test
+	start_read_only_server
+
+	run http_get '/objects?q=one/uno'
+	assert_output --partial 'HTTP/1.0 200 OK'
+	assert_output --regexp '\[\{"object-id":"one/uno","type":"!?md","tags":\["tag-3","tag-4"\],"description":"wow the first"'
+
+	run http_get '/objects/one%2Funo'
+	assert_output --partial 'Content-Type: application/json'
+	assert_output --partial '"blob-id":"blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd"'
+
+	run http_get '/objects/one/uno/blob'
+	assert_output --partial 'Etag: "blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd"'
+	assert_line 'last time'
+
+	run http_get '/objects/one/uno/blob' \
+		'"blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd"'
+	assert_output --partial 'HTTP/1.0 304 Not Modified'
+	refute_line 'last time'
+
+	run http_get '/objects/one/nope'
+	assert_output --partial 'HTTP/1.0 404 Not Found'
+
+	# the write routes are not served
+	run http_get '/inventory_lists'
+	assert_output --partial 'HTTP/1.0 404 Not Found'
+
+	kill "$server_pid"
+	wait "$server_pid" || true
+}
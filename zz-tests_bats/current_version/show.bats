@@ -29,6 +29,51 @@ function show_simple_one_zettel { # @test
 	EOM
 }
 
+function show_simple_one_zettel_ndjson { # @test
+	run_dodder show -format ndjson one/uno
+	assert_success
+	assert_output --regexp '^\{"object-id":"one/uno","type":"!?md","tags":\["tag-3","tag-4"\],"description":"wow the first","tai":"[0-9.]+","blob-id":"blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd"\}$'
+
+	run_dodder show -format ndjson-with-blob one/uno
+	assert_success
+	assert_output --regexp '"blob-id":"blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd","blob":"[^"]*"\}$'
+}
+
+function show_json_array { # @test
+	run_dodder show -format json :z
+	assert_success
+	assert_line --index 0 '['
+	assert_line --regexp '^\{"object-id":"one/dos",.*\},?$'
+	assert_line --regexp '^\{"object-id":"one/uno",.*\},?$'
+	assert_line --index 3 ']'
+
+	run_dodder show -format json -before 2000-01-01T00:00:00Z :z
+	assert_success
+	assert_output '[]'
+}
+
+function show_template { # @test
+	run_dodder show -format 'template={{.ObjectId}} {{join .Tags ","}} {{.Description}}' :z
+	assert_success
+	assert_output_unsorted - <<-EOM
+		one/dos tag-3,tag-4 wow ok again
+		one/uno tag-3,tag-4 wow the first
+	EOM
+
+	run_dodder show -format 'template={{.ObjectId' :z
+	assert_failure
+}
+
+function show_error_format_json { # @test
+	run_dodder show -error-format json -format 'template={{.ObjectId' :z
+	assert_failure
+	assert_line --regexp '^\{"error":\{"code":"DODDER_[A-Z_]+","message":".+"'
+
+	run_dodder show -error-format yaml :z
+	assert_failure
+	assert_output --partial 'unsupported error format: "yaml"'
+}
+
 function show_simple_one_zettel_with_description_with_quotes { # @test
 	run_dodder init-workspace
 	assert_success
@@ -125,6 +170,22 @@ function show_simple_one_zettel_hidden { # @test
 	EOM
 }
 
+function show_simple_one_zettel_jobs { # @test
+	run_dodder show -jobs 1 :?z
+	assert_success
+	assert_output_unsorted - <<-EOM
+		[one/dos @blake2b256-z3zpdf6uhqd3tx6nehjtvyjsjqelgyxfjkx46pq04l6qryxz4efs37xhkd !md "wow ok again" tag-3 tag-4]
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4]
+	EOM
+
+	run_dodder show -jobs 0 :?z
+	assert_success
+	assert_output_unsorted - <<-EOM
+		[one/dos @blake2b256-z3zpdf6uhqd3tx6nehjtvyjsjqelgyxfjkx46pq04l6qryxz4efs37xhkd !md "wow ok again" tag-3 tag-4]
+		[one/uno @blake2b256-9ft3m74l5t2ppwjrvfg3wp380jqj2zfrm6zevxqx34sdethvey0s5vm9gd !md "wow the first" tag-3 tag-4]
+	EOM
+}
+
 function show_simple_one_zettel_hidden_past { # @test
 	run_dodder dormant-add tag-1
 	assert_success
@@ -567,6 +628,20 @@ function show_inventory_lists { # @test
 	EOM
 }
 
+function show_inventory_list_provenance { # @test
+	run_dodder new -edit=false -description provenance
+	assert_success
+	object_id="$(echo "$output" | sed -E 's/^\[([^ ]+) .*/\1/')"
+
+	run_dodder show -format json :b
+	assert_success
+	assert_output --partial "\"hostname\":\"$(hostname)\""
+
+	run_dodder history -format json "$object_id"
+	assert_success
+	assert_output --partial "\"provenance\":{"
+}
+
 function show_inventory_list_blob_sort_correct { # @test
 	function assert_sorted_tais() {
 		echo -n "$1" | run sort -n -c -
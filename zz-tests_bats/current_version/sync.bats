@@ -0,0 +1,38 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	copy_from_version "$DIR"
+}
+
+teardown() {
+	chflags_nouchg
+}
+
+# bats file_tags=user_story:sync,user_story:repo,user_store:xdg,user_story:remote
+
+function sync_pushes_then_noop { # @test
+	(
+		mkdir -p them
+		pushd them || exit 1
+		run_dodder_init
+	)
+
+	run_dodder remote-add \
+		toml-repo-local_override_path-v0 \
+		"$(realpath them)" \
+		them
+	assert_success
+
+	run_dodder sync -trust /them +zettel
+	assert_success
+	assert_output --partial 'synced: 0 objects pulled, 3 objects pushed'
+
+	run_dodder sync -trust /them +zettel
+	assert_success
+	assert_output 'synced: 0 objects pulled, 0 objects pushed'
+}
@@ -0,0 +1,64 @@
+#! /usr/bin/env bats
+
+setup() {
+	load "$(dirname "$BATS_TEST_FILE")/../lib/common.bash"
+
+	# for shellcheck SC2154
+	export output
+
+	copy_from_version "$DIR"
+
+	run_dodder_init_workspace
+
+	run_dodder checkout one/uno
+	assert_success
+}
+
+teardown() {
+	chflags_nouchg
+}
+
+# bats test_tags=user_story:workspace
+function watch_checks_in_saved_changes { # @test
+	cat >.dodderignore <<-EOM
+		*.log
+	EOM
+
+	# the output is hidden so that the watch does not check it in
+	#shellcheck disable=SC2068
+	timeout --preserve-status 10s "$DODDER_BIN" watch ${cmd_dodder_def[@]} \
+		-poll 50ms -debounce 200ms -max-batches 1 >.watch.out 2>&1 &
+	watch_pid=$!
+
+	# let the watch take its initial scan before editing
+	sleep 1
+
+	cat >ignored.log <<-EOM
+		not checked in
+	EOM
+
+	cat >one/uno.zettel <<-EOM
+		---
+		# wildly different
+		- etikett-one
+		! md
+		---
+
+		newest body
+	EOM
+
+	wait "$watch_pid"
+
+	run cat .watch.out
+	assert_output --partial - <<-EOM
+		[one/uno @blake2b256-k87yyah5da3c8h9j4ugf44edeurrqztn7zddh7ksc88pfg4zzx0smqmuf9 !md "wildly different" etikett-one]
+	EOM
+
+	run cat .dodder-watch.log
+	assert_success
+	assert_output --regexp '"changed":\["one/uno.zettel"\],"objects":\["one/uno"\]'
+
+	run_dodder show -format text one/uno
+	assert_success
+	assert_output --partial 'newest body'
+}